@@ -1,5 +1,7 @@
 package broker
 
+import "context"
+
 var (
 	NotConnected = "not-connected"
 )
@@ -24,3 +26,21 @@ type Handler interface {
 	CloseConnection()
 	ConnectedEndpoints() []string //To get the IP addresses of connected endpoints
 }
+
+// Drainer is implemented by the Handlers able to drain their connection before closing it, see Close.
+type Drainer interface {
+	// CloseWithDrain stops accepting new messages, waits for the in-flight messages to be handled
+	// and for the pending publishes to be flushed, then disconnects.
+	// If the context is done before, the connection is closed immediately and an error is returned.
+	CloseWithDrain(ctx context.Context) error
+}
+
+// Close drains the connection of the handler then closes it if the handler is a Drainer,
+// otherwise the connection is closed immediately.
+func Close(ctx context.Context, h Handler) error {
+	if d, ok := h.(Drainer); ok {
+		return d.CloseWithDrain(ctx)
+	}
+	h.CloseConnection()
+	return nil
+}
@@ -0,0 +1,61 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type closingHandler struct {
+	Handler
+	closed bool
+}
+
+func (h *closingHandler) CloseConnection() {
+	h.closed = true
+}
+
+// drainingHandler drains the messages in flight, each taking a while to be handled, before closing.
+type drainingHandler struct {
+	closingHandler
+	inFlight chan *Message
+	handled  int
+}
+
+func (h *drainingHandler) CloseWithDrain(ctx context.Context) error {
+	close(h.inFlight)
+	for range h.inFlight {
+		select {
+		case <-ctx.Done():
+			h.CloseConnection()
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+			h.handled++
+		}
+	}
+	h.CloseConnection()
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	h := &closingHandler{}
+	if err := Close(context.Background(), h); err != nil || !h.closed {
+		t.Errorf("expected the connection to be closed, got %v", err)
+	}
+
+	d := &drainingHandler{inFlight: make(chan *Message, 3)}
+	for i := 0; i < 3; i++ {
+		d.inFlight <- &Message{}
+	}
+	if err := Close(context.Background(), d); err != nil || d.handled != 3 || !d.closed {
+		t.Errorf("expected the messages in flight to be handled before closing, handled %d, %v", d.handled, err)
+	}
+
+	d = &drainingHandler{inFlight: make(chan *Message, 3)}
+	d.inFlight <- &Message{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Close(ctx, d); err == nil || !d.closed {
+		t.Errorf("expected the connection to be closed once the context is done, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package broker
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultDedupSize is the number of message IDs remembered by a Deduplicator unless configured otherwise.
+	DefaultDedupSize = 10000
+	// DefaultDedupTTL is the duration message IDs are remembered by a Deduplicator unless configured otherwise.
+	DefaultDedupTTL = 10 * time.Minute
+)
+
+// NewMessageID returns a new, random, message ID, as assigned by the producers to Message.ID.
+func NewMessageID() string {
+	return uuid.NewString()
+}
+
+// DedupOptions configures the window of a Deduplicator: an ID is remembered until TTL elapsed or Size more recent IDs
+// have been seen. The window has to cover the messages redelivered after a reconnect, e.g. a resync of MeshSync.
+type DedupOptions struct {
+	// Size is the maximum number of IDs remembered, DefaultDedupSize if zero.
+	Size int
+	// TTL is the duration an ID is remembered, DefaultDedupTTL if zero.
+	TTL time.Duration
+}
+
+// Deduplicator drops the messages whose IDs were already seen in its window, so that the messages redelivered to a
+// consumer, e.g. after a reconnect, are handled once. Messages without ID are never dropped. It is safe for concurrent use.
+type Deduplicator struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List
+	seen  map[string]*list.Element
+	now   func() time.Time
+}
+
+type dedupEntry struct {
+	id   string
+	seen time.Time
+}
+
+// NewDeduplicator returns a Deduplicator with the window of the options.
+func NewDeduplicator(opts DedupOptions) *Deduplicator {
+	if opts.Size <= 0 {
+		opts.Size = DefaultDedupSize
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultDedupTTL
+	}
+	return &Deduplicator{size: opts.Size, ttl: opts.TTL, order: list.New(), seen: make(map[string]*list.Element), now: time.Now}
+}
+
+// Duplicate reports whether the message is a duplicate of a message seen in the window, and remembers its ID otherwise.
+// The window of a duplicate is not extended, so that an ID redelivered continuously is eventually handled again.
+func (d *Deduplicator) Duplicate(msg *Message) bool {
+	if msg == nil || msg.ID == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	d.expire(now)
+	if _, ok := d.seen[msg.ID]; ok {
+		return true
+	}
+	d.seen[msg.ID] = d.order.PushBack(dedupEntry{id: msg.ID, seen: now})
+	if d.order.Len() > d.size {
+		d.remove(d.order.Front())
+	}
+	return false
+}
+
+// expire forgets the IDs seen before the TTL, the oldest being at the front.
+func (d *Deduplicator) expire(now time.Time) {
+	for e := d.order.Front(); e != nil && now.Sub(e.Value.(dedupEntry).seen) >= d.ttl; e = d.order.Front() {
+		d.remove(e)
+	}
+}
+
+func (d *Deduplicator) remove(e *list.Element) {
+	delete(d.seen, e.Value.(dedupEntry).id)
+	d.order.Remove(e)
+}
+
+// Len returns the number of IDs remembered.
+func (d *Deduplicator) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.expire(d.now())
+	return d.order.Len()
+}
@@ -0,0 +1,34 @@
+package broker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeduplicator(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := NewDeduplicator(DedupOptions{Size: 2, TTL: time.Minute})
+	d.now = func() time.Time { return now }
+
+	if d.Duplicate(&Message{ID: "a"}) || !d.Duplicate(&Message{ID: "a"}) {
+		t.Error("expected the redelivered message to be a duplicate")
+	}
+	if d.Duplicate(&Message{}) || d.Duplicate(&Message{}) {
+		t.Error("expected messages without ID to never be duplicates")
+	}
+
+	// b and c push a out of the window
+	d.Duplicate(&Message{ID: "b"})
+	d.Duplicate(&Message{ID: "c"})
+	if d.Len() != 2 || d.Duplicate(&Message{ID: "a"}) {
+		t.Errorf("expected the oldest ID to be forgotten, %d IDs remembered", d.Len())
+	}
+
+	now = now.Add(time.Minute)
+	if d.Duplicate(&Message{ID: "c"}) {
+		t.Error("expected the IDs to expire after the TTL")
+	}
+	if NewMessageID() == NewMessageID() {
+		t.Error("expected unique message IDs")
+	}
+}
@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+// DispatchOptions configures how the messages of a subscription are handled by Dispatch.
+type DispatchOptions struct {
+	// MaxConcurrency is the maximum number of concurrent invocations of the handler, 1 if zero, handling the messages in order.
+	MaxConcurrency int
+	// OrderKey, if set, keys the messages whose relative order is kept, e.g. ObjectUIDKey for the events of a Kubernetes
+	// object: the messages of the same key are handled one at a time in the order received, the messages of different
+	// keys concurrently. Messages with an empty key are handled in any order. The messages are unordered if nil.
+	OrderKey func(*Message) string
+}
+
+// ObjectUIDKey keys the messages by the UID of their object, a Kubernetes object as sent by MeshSync, e.g. to handle
+// the events of an object in order. Messages of other objects have an empty key.
+func ObjectUIDKey(msg *Message) string {
+	object, _ := msg.Object.(map[string]interface{})
+	metadata, _ := object["metadata"].(map[string]interface{})
+	uid, _ := metadata["uid"].(string)
+	return uid
+}
+
+// Dispatch calls the handler with the messages received on the channel, e.g. of SubscribeWithChannel, with the
+// concurrency and the ordering of the options. It returns once the channel is closed or the context done,
+// and the messages received are handled, the messages not received yet being left in the channel.
+func Dispatch(ctx context.Context, msgch <-chan *Message, opts DispatchOptions, handle func(*Message)) {
+	workers := opts.MaxConcurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers == 1 {
+		// the messages are handled one at a time in the order received, keyed or not
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgch:
+				if !ok {
+					return
+				}
+				handle(msg)
+			}
+		}
+	}
+	// each worker handles the messages of its keys in order, and shares the messages without key
+	shared := make(chan *Message)
+	keyed := make([]chan *Message, workers)
+	var wg sync.WaitGroup
+	for i := range keyed {
+		keyed[i] = make(chan *Message, 1)
+		wg.Add(1)
+		go func(own chan *Message) {
+			defer wg.Done()
+			for {
+				select {
+				case msg, ok := <-own:
+					if !ok {
+						// the shared channel is closed with the keyed ones
+						for msg := range shared {
+							handle(msg)
+						}
+						return
+					}
+					handle(msg)
+				case msg, ok := <-shared:
+					if !ok {
+						for msg := range own {
+							handle(msg)
+						}
+						return
+					}
+					handle(msg)
+				}
+			}
+		}(keyed[i])
+	}
+	defer func() {
+		close(shared)
+		for _, own := range keyed {
+			close(own)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgch:
+			if !ok {
+				return
+			}
+			queue := shared
+			if opts.OrderKey != nil {
+				if key := opts.OrderKey(msg); key != "" {
+					queue = keyed[partition(key, workers)]
+				}
+			}
+			queue <- msg
+		}
+	}
+}
+
+// partition returns the worker of the key, among n.
+func partition(key string, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
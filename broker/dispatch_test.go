@@ -0,0 +1,97 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatchOrdered(t *testing.T) {
+	msgch := make(chan *Message)
+	var mu sync.Mutex
+	handled := map[string][]int{}
+	var running, maxRunning int32
+	done := make(chan struct{})
+	go func() {
+		Dispatch(context.Background(), msgch, DispatchOptions{MaxConcurrency: 4, OrderKey: ObjectUIDKey}, func(msg *Message) {
+			if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&maxRunning) {
+				atomic.StoreInt32(&maxRunning, n)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			object := msg.Object.(map[string]interface{})
+			uid := ObjectUIDKey(msg)
+			mu.Lock()
+			handled[uid] = append(handled[uid], object["generation"].(int))
+			mu.Unlock()
+		})
+		close(done)
+	}()
+	for generation := 0; generation < 20; generation++ {
+		for uid := 0; uid < 8; uid++ {
+			msgch <- &Message{Object: map[string]interface{}{
+				"metadata":   map[string]interface{}{"uid": fmt.Sprint(uid)},
+				"generation": generation,
+			}}
+		}
+	}
+	close(msgch)
+	<-done
+
+	if len(handled) != 8 {
+		t.Fatalf("expected the messages of 8 objects, got %d", len(handled))
+	}
+	for uid, generations := range handled {
+		for i, generation := range generations {
+			if generation != i {
+				t.Fatalf("expected the events of object %s in order, got %v", uid, generations)
+			}
+		}
+	}
+	if maxRunning > 4 {
+		t.Errorf("expected at most 4 concurrent handlers, got %d", maxRunning)
+	}
+}
+
+func TestDispatchCanceled(t *testing.T) {
+	msgch := make(chan *Message, 2)
+	msgch <- &Message{}
+	ctx, cancel := context.WithCancel(context.Background())
+	var handled int32
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	Dispatch(ctx, msgch, DispatchOptions{}, func(*Message) { atomic.AddInt32(&handled, 1) })
+	if handled != 1 {
+		t.Errorf("expected the message received to be handled, got %d", handled)
+	}
+}
+
+func TestDispatchSerial(t *testing.T) {
+	msgch := make(chan *Message, 100)
+	for i := 0; i < 100; i++ {
+		// the messages of objects and without object are interleaved
+		object := map[string]interface{}{"generation": i}
+		if i%2 == 0 {
+			object["metadata"] = map[string]interface{}{"uid": fmt.Sprint(i % 3)}
+		}
+		msgch <- &Message{Object: object}
+	}
+	close(msgch)
+	var handled []int
+	Dispatch(context.Background(), msgch, DispatchOptions{MaxConcurrency: 1, OrderKey: ObjectUIDKey}, func(msg *Message) {
+		handled = append(handled, msg.Object.(map[string]interface{})["generation"].(int))
+	})
+	for i, generation := range handled {
+		if generation != i {
+			t.Fatalf("expected the messages in the order received, got %v", handled)
+		}
+	}
+	if len(handled) != 100 {
+		t.Errorf("expected 100 messages, got %d", len(handled))
+	}
+}
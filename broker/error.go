@@ -0,0 +1,20 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrInvalidSubjectCode        = "meshkit-11283"
+	ErrInvalidSubjectRewriteCode = "meshkit-11306"
+)
+
+func ErrInvalidSubject(subject string, reason string) error {
+	return errors.New(ErrInvalidSubjectCode, errors.Alert, []string{"Invalid broker subject"}, []string{fmt.Sprintf("subject %q is invalid: %s", subject, reason)}, []string{"The subject is not built following the subject naming convention"}, []string{"Build the subject using broker.Subject, with a component and tokens free of whitespace, '.', '*' and '>'"})
+}
+
+func ErrInvalidSubjectRewrite(from, to string, reason string) error {
+	return errors.New(ErrInvalidSubjectRewriteCode, errors.Alert, []string{"Invalid subject rewrite"}, []string{fmt.Sprintf("rewriting subject %q to %q is invalid: %s", from, to, reason)}, []string{"The replacement of a subject with wildcards doesn't contain the same wildcards in the same order"}, []string{"Use the same wildcards in the subject and its replacement, e.g. legacy.*.pod to meshsync.*.pod, or none in the replacement"})
+}
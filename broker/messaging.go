@@ -1,5 +1,7 @@
 package broker
 
+import "context"
+
 var (
 	Request          ObjectType = "request-payload"
 	MeshSync         ObjectType = "meshsync-data"
@@ -9,6 +11,7 @@ var (
 	ExecInputObject  ObjectType = "exec-input"
 	ExecOutputObject ObjectType = "exec-output"
 	ActiveExecObject ObjectType = "active-exec"
+	EventObject      ObjectType = "event"
 
 	Add        EventType = "ADDED"
 	Update     EventType = "MODIFIED"
@@ -27,6 +30,15 @@ type EventType string
 type RequestEntity string
 
 type Message struct {
+	// ID identifies the message, assigned by its producer, e.g. with NewMessageID, so that the consumers drop
+	// the messages redelivered, see Deduplicator. Messages without ID are not deduplicated.
+	ID string `json:",omitempty"`
+	// Subject is the subject the message was received on, set by the subscriptions, e.g. to tell the subjects
+	// matched by a wildcard subscription apart. It is not sent.
+	Subject string `json:"-"`
+	// Context carries the trace context of the message if the broker traces messages: the span of a published message
+	// is a child of the span of its Context, and the Context of a received message carries its consumer span. It is not sent.
+	Context    context.Context `json:"-"`
 	ObjectType ObjectType
 	EventType  EventType
 	Request    *RequestObject
@@ -10,6 +10,7 @@ const (
 	ErrPublishCode        = "meshkit-11120"
 	ErrPublishRequestCode = "meshkit-11121"
 	ErrQueueSubscribeCode = "meshkit-11122"
+	ErrDrainCode          = "meshkit-11284"
 )
 
 func ErrConnect(err error) error {
@@ -27,3 +28,6 @@ func ErrPublishRequest(err error) error {
 func ErrQueueSubscribe(err error) error {
 	return errors.New(ErrQueueSubscribeCode, errors.Alert, []string{"Subscription failed"}, []string{err.Error()}, []string{"NATS is unhealthy"}, []string{"Make sure NATS is up and running"})
 }
+func ErrDrain(err error) error {
+	return errors.New(ErrDrainCode, errors.Alert, []string{"Draining the connection to the broker failed"}, []string{err.Error()}, []string{"In-flight messages were not handled before the deadline", "NATS is unhealthy"}, []string{"Increase the shutdown deadline, e.g. the termination grace period of the pod", "Make sure NATS is up and running"})
+}
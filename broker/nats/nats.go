@@ -1,6 +1,7 @@
 package nats
 
 import (
+	"context"
 	"log"
 	"strings"
 	"sync"
@@ -21,16 +22,46 @@ type Options struct {
 	Password       string
 	ReconnectWait  time.Duration
 	MaxReconnect   int
+	// SubjectRewrites maps subjects to the subjects replacing them when publishing and subscribing,
+	// e.g. to bridge legacy subject names during a migration, see broker.NewSubjectRewriter.
+	SubjectRewrites map[string]string
+	// Tracing enables the tracing of the messages published and received, and the propagation of their trace context
+	// in the W3C Trace Context headers, see broker.Message.Context. Messages are not traced if nil.
+	Tracing *TracingOptions
+	// AssignMessageIDs assigns a new ID to the messages published without one, see broker.Message.ID.
+	AssignMessageIDs bool
+	// Deduplication, if set, drops the messages received whose IDs were already received by the same subscription
+	// in the window of the options, e.g. MeshSync events redelivered after a reconnect. Messages are not deduplicated if nil.
+	Deduplication *broker.DedupOptions
 }
 
 // Nats will implement Nats subscribe and publish functionality
 type Nats struct {
 	ec *nats.EncodedConn
 	wg *sync.WaitGroup
+	// closed is closed once the connection is closed
+	closed chan struct{}
+	// rewriter rewrites the subjects published and subscribed to, nil if there are no rewrites
+	rewriter *broker.SubjectRewriter
+	// tracer traces the messages published and received, nil if tracing is disabled
+	tracer *tracer
+	// assignIDs assigns IDs to the messages published without one
+	assignIDs bool
+	// dedup configures the deduplication of the messages received by each subscription, nil if disabled
+	dedup *broker.DedupOptions
 }
 
 // New - constructor
 func New(opts Options) (broker.Handler, error) {
+	var rewriter *broker.SubjectRewriter
+	if len(opts.SubjectRewrites) > 0 {
+		var err error
+		if rewriter, err = broker.NewSubjectRewriter(opts.SubjectRewrites); err != nil {
+			return nil, err
+		}
+	}
+	closed := make(chan struct{})
+	var closeOnce sync.Once
 	nc, err := nats.Connect(strings.Join(opts.URLS, ","),
 		nats.Name(opts.ConnectionName),
 		nats.ReconnectWait(opts.ReconnectWait),
@@ -44,6 +75,7 @@ func New(opts Options) (broker.Handler, error) {
 		}),
 		nats.ClosedHandler(func(_ *nats.Conn) {
 			log.Printf("client closed")
+			closeOnce.Do(func() { close(closed) })
 		}),
 		nats.DiscoveredServersHandler(func(nc *nats.Conn) {
 			log.Printf("Known servers: %v\n", nc.Servers())
@@ -62,7 +94,11 @@ func New(opts Options) (broker.Handler, error) {
 		return nil, ErrEncodedConn(err)
 	}
 
-	return &Nats{ec: ec}, nil
+	var t *tracer
+	if opts.Tracing != nil {
+		t = newTracer(*opts.Tracing)
+	}
+	return &Nats{ec: ec, closed: closed, rewriter: rewriter, tracer: t, assignIDs: opts.AssignMessageIDs, dedup: opts.Deduplication}, nil
 }
 func (n *Nats) ConnectedEndpoints() (endpoints []string) {
 	for _, server := range n.ec.Conn.Servers() {
@@ -82,8 +118,43 @@ func (n *Nats) CloseConnection() {
 	n.ec.Close()
 }
 
+// CloseWithDrain drains the subscriptions and pending publishes of the connection, then closes it.
+// Once started, publishing and subscribing fail. If the context is done before the connection is drained,
+// the connection is closed immediately.
+func (n *Nats) CloseWithDrain(ctx context.Context) error {
+	if n.ec == nil || n.ec.Conn == nil || n.ec.Conn.IsClosed() {
+		return nil
+	}
+	if err := n.ec.Drain(); err != nil {
+		if err == nats.ErrConnectionClosed {
+			return nil
+		}
+		n.ec.Close()
+		return ErrDrain(err)
+	}
+	select {
+	case <-n.closed:
+		if err := n.ec.Conn.LastError(); err == nats.ErrDrainTimeout {
+			return ErrDrain(err)
+		}
+		return nil
+	case <-ctx.Done():
+		n.ec.Close()
+		return ErrDrain(ctx.Err())
+	}
+}
+
 // Publish - to publish messages
+// If tracing is enabled, the message is published in a producer span, a child of the span of message.Context.
+// If IDs are assigned, the ID assigned is set on the message.
 func (n *Nats) Publish(subject string, message *broker.Message) error {
+	subject = n.rewriter.Rewrite(subject)
+	if n.assignIDs && message != nil && message.ID == "" {
+		message.ID = broker.NewMessageID()
+	}
+	if n.tracer != nil {
+		return n.publishTraced(subject, message)
+	}
 	err := n.ec.Publish(subject, message)
 	if err != nil {
 		return ErrPublish(err)
@@ -91,9 +162,34 @@ func (n *Nats) Publish(subject string, message *broker.Message) error {
 	return nil
 }
 
+// publishTraced publishes the message with the trace context of its producer span in the headers.
+func (n *Nats) publishTraced(subject string, message *broker.Message) error {
+	data, err := n.ec.Enc.Encode(subject, message)
+	if err != nil {
+		return ErrPublish(err)
+	}
+	ctx := context.Background()
+	if message != nil && message.Context != nil {
+		ctx = message.Context
+	}
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	span := n.tracer.startPublish(ctx, subject, msg.Header)
+	err = n.ec.Conn.PublishMsg(msg)
+	endSpan(span, err)
+	if err != nil {
+		return ErrPublish(err)
+	}
+	return nil
+}
+
 // PublishWithChannel - to publish messages with channel
 func (n *Nats) PublishWithChannel(subject string, msgch chan *broker.Message) error {
-	err := n.ec.BindSendChan(subject, msgch)
+	if n.tracer != nil || n.assignIDs {
+		go n.publishChannel(subject, msgch)
+		return nil
+	}
+	err := n.ec.BindSendChan(n.rewriter.Rewrite(subject), msgch)
 	if err != nil {
 		return ErrPublish(err)
 	}
@@ -101,11 +197,12 @@ func (n *Nats) PublishWithChannel(subject string, msgch chan *broker.Message) er
 }
 
 // Subscribe - for subscribing messages
+// The subject can contain wildcards, e.g. meshery.> or meshsync.*.pod.
 // TODO Ques: Do we want to unsubscribe
 // TODO will the method-user just subsribe, how will it handle the received messages?
 func (n *Nats) Subscribe(subject, queue string, message []byte) error {
 	n.wg.Add(1)
-	_, err := n.ec.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
+	_, err := n.ec.QueueSubscribe(n.rewriter.Rewrite(subject), queue, func(msg *nats.Msg) {
 		message = msg.Data
 		n.wg.Done()
 	})
@@ -118,8 +215,25 @@ func (n *Nats) Subscribe(subject, queue string, message []byte) error {
 }
 
 // SubscribeWithChannel will publish all the messages received to the given channel
+// The subject can contain wildcards, e.g. meshery.> or meshsync.*.pod, the Subject of the messages is the one they were received on.
+// If tracing is enabled, the messages are sent to the channel in consumer spans, carried by their Context.
+// If deduplication is enabled, the duplicates of the messages received by the subscription are dropped.
+// The messages are sent in the order received, use broker.Dispatch to handle them concurrently, e.g. ordered by object.
 func (n *Nats) SubscribeWithChannel(subject, queue string, msgch chan *broker.Message) error {
-	_, err := n.ec.BindRecvQueueChan(subject, queue, msgch)
+	var dedup *broker.Deduplicator
+	if n.dedup != nil {
+		dedup = broker.NewDeduplicator(*n.dedup)
+	}
+	if n.tracer != nil {
+		return n.subscribeTraced(n.rewriter.Rewrite(subject), queue, msgch, dedup)
+	}
+	_, err := n.ec.QueueSubscribe(n.rewriter.Rewrite(subject), queue, func(subject string, msg *broker.Message) {
+		if dedup != nil && dedup.Duplicate(msg) {
+			return
+		}
+		msg.Subject = subject
+		msgch <- msg
+	})
 	if err != nil {
 		return ErrQueueSubscribe(err)
 	}
@@ -127,6 +241,48 @@ func (n *Nats) SubscribeWithChannel(subject, queue string, msgch chan *broker.Me
 	return nil
 }
 
+// publishChannel publishes the messages of the channel until it is closed, as BindSendChan does, but traced and with IDs.
+func (n *Nats) publishChannel(subject string, msgch chan *broker.Message) {
+	for {
+		select {
+		case msg, ok := <-msgch:
+			if !ok {
+				return
+			}
+			if err := n.Publish(subject, msg); err != nil {
+				log.Printf("Error: %v", err)
+			}
+		case <-n.closed:
+			return
+		}
+	}
+}
+
+// subscribeTraced subscribes to the subject, extracting the trace context of the messages received from their headers.
+func (n *Nats) subscribeTraced(subject, queue string, msgch chan *broker.Message, dedup *broker.Deduplicator) error {
+	_, err := n.ec.Conn.QueueSubscribe(subject, queue, func(m *nats.Msg) {
+		ctx, span := n.tracer.startReceive(m.Subject, m.Header)
+		msg := &broker.Message{}
+		if err := n.ec.Enc.Decode(m.Subject, m.Data, msg); err != nil {
+			endSpan(span, err)
+			log.Printf("Error: %v", err)
+			return
+		}
+		if dedup != nil && dedup.Duplicate(msg) {
+			endSpan(span, nil)
+			return
+		}
+		msg.Subject = m.Subject
+		msg.Context = ctx
+		msgch <- msg
+		endSpan(span, nil)
+	})
+	if err != nil {
+		return ErrQueueSubscribe(err)
+	}
+	return nil
+}
+
 // DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Nats) DeepCopyInto(out broker.Handler) {
 	*out.(*Nats) = *in
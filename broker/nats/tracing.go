@@ -0,0 +1,106 @@
+package nats
+
+import (
+	"context"
+	"strings"
+
+	nats "github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/layer5io/meshkit/broker/nats"
+
+// TracingOptions configures the tracing of the messages published and received, see Options.Tracing.
+// The trace context is propagated in the headers of the messages, which requires NATS 2.2 or later.
+type TracingOptions struct {
+	// TracerProvider creates the spans, the global TracerProvider of OpenTelemetry if nil.
+	TracerProvider trace.TracerProvider
+	// Propagator injects and extracts the trace context, W3C Trace Context (the traceparent and tracestate headers) if nil.
+	Propagator propagation.TextMapPropagator
+}
+
+// tracer creates the producer and consumer spans of messages, and propagates their trace context.
+type tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func newTracer(opts TracingOptions) *tracer {
+	provider := opts.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	propagator := opts.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return &tracer{tracer: provider.Tracer(tracerName), propagator: propagator}
+}
+
+// startPublish starts the producer span of a message published to the subject, as a child of the span of ctx,
+// and injects its trace context into the header. The span has to be ended once the message is published.
+func (t *tracer) startPublish(ctx context.Context, subject string, header nats.Header) trace.Span {
+	ctx, span := t.tracer.Start(ctx, subject+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(subject, "publish")...))
+	t.propagator.Inject(ctx, headerCarrier(header))
+	return span
+}
+
+// startReceive extracts the trace context of a message received on the subject, and starts its consumer span as a child of it.
+// The returned context carries the span, so that the handling of the message can be traced as part of the same trace.
+func (t *tracer) startReceive(subject string, header nats.Header) (context.Context, trace.Span) {
+	ctx := t.propagator.Extract(context.Background(), headerCarrier(header))
+	return t.tracer.Start(ctx, subject+" receive",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingAttributes(subject, "receive")...))
+}
+
+func messagingAttributes(subject, operation string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.destination.name", subject),
+		attribute.String("messaging.operation", operation),
+	}
+}
+
+// endSpan ends the span, with an error status if err is not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// headerCarrier adapts the headers of NATS messages to propagators. Keys are looked up case-insensitively,
+// as the header of HTTP requests, since other clients may write them in another case.
+type headerCarrier nats.Header
+
+func (h headerCarrier) Get(key string) string {
+	if values := h[key]; len(values) > 0 {
+		return values[0]
+	}
+	for k, values := range h {
+		if strings.EqualFold(k, key) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = []string{value}
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
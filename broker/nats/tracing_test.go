@@ -0,0 +1,66 @@
+package nats
+
+import (
+	"context"
+	"testing"
+
+	nats "github.com/nats-io/nats.go"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracerPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := newTracer(TracingOptions{TracerProvider: provider})
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "deploy")
+	header := nats.Header{}
+	endSpan(tr.startPublish(ctx, "meshery.deploy", header), nil)
+	parent.End()
+	if header.Get("traceparent") == "" {
+		t.Fatalf("expected a traceparent header, got %v", header)
+	}
+
+	// other clients may write the headers in another case
+	received := nats.Header{"Traceparent": header["traceparent"]}
+	ctx, span := tr.startReceive("meshery.deploy", received)
+	endSpan(span, nil)
+	if trace.SpanFromContext(ctx) != span {
+		t.Error("expected the context to carry the consumer span")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	producer, consumer := spans[0], spans[2]
+	if producer.Name() != "meshery.deploy publish" || producer.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("unexpected producer span %s of kind %s", producer.Name(), producer.SpanKind())
+	}
+	if consumer.Name() != "meshery.deploy receive" || consumer.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("unexpected consumer span %s of kind %s", consumer.Name(), consumer.SpanKind())
+	}
+	if producer.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the producer span to be a child of the span of the context")
+	}
+	if consumer.Parent().SpanID() != producer.SpanContext().SpanID() || consumer.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Error("expected the consumer span to be a child of the producer span")
+	}
+}
+
+func TestTracerWithoutTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tr := newTracer(TracingOptions{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))})
+
+	_, span := tr.startReceive("meshsync.pod", nil)
+	endSpan(span, nats.ErrBadSubject)
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Parent().IsValid() {
+		t.Fatalf("expected a root span, got %v", spans)
+	}
+	if len(spans[0].Events()) != 1 || spans[0].Status().Description != nats.ErrBadSubject.Error() {
+		t.Errorf("expected the error to be recorded, got %+v", spans[0].Status())
+	}
+}
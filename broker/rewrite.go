@@ -0,0 +1,128 @@
+package broker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatchSubject reports whether the subject matches the pattern, a subject which may contain wildcards:
+// Wildcard matches any single token, FullWildcard one or more remaining tokens.
+// Subjects are not required to follow the naming convention, e.g. legacy subjects can be matched.
+func MatchSubject(pattern, subject string) bool {
+	_, ok := matchTokens(strings.Split(pattern, subjectSeparator), strings.Split(subject, subjectSeparator))
+	return ok
+}
+
+// matchTokens returns the tokens of the subject matched by the wildcards of the pattern, in order.
+// The tokens matched by FullWildcard are joined into one.
+func matchTokens(pattern, subject []string) ([]string, bool) {
+	var captured []string
+	for i, token := range pattern {
+		if token == FullWildcard && i == len(pattern)-1 {
+			if len(subject) <= i {
+				return nil, false
+			}
+			return append(captured, strings.Join(subject[i:], subjectSeparator)), true
+		}
+		if i >= len(subject) {
+			return nil, false
+		}
+		switch token {
+		case Wildcard:
+			captured = append(captured, subject[i])
+		case subject[i]:
+		default:
+			return nil, false
+		}
+	}
+	return captured, len(pattern) == len(subject)
+}
+
+type subjectRewrite struct {
+	from, to  []string
+	wildcards int
+}
+
+// SubjectRewriter rewrites subjects, e.g. to bridge legacy subject names during a migration:
+// the components publishing and subscribing to legacy subjects use the new ones transparently.
+// A nil SubjectRewriter leaves subjects unchanged.
+type SubjectRewriter struct {
+	exact    map[string]string
+	patterns []subjectRewrite
+}
+
+// NewSubjectRewriter returns a SubjectRewriter of the rewrites, a map of subjects to the subjects replacing them.
+//
+// Subjects can contain wildcards, e.g. "legacy.*.pod" to "meshsync.*.pod": the tokens matched by the wildcards
+// of a subject are substituted for the wildcards of its replacement, in order. The replacement must contain the same
+// wildcards as the subject, or none. Subjects without wildcards take precedence, then the subjects with the fewest wildcards.
+func NewSubjectRewriter(rewrites map[string]string) (*SubjectRewriter, error) {
+	r := &SubjectRewriter{exact: make(map[string]string)}
+	for from, to := range rewrites {
+		if from == "" || to == "" {
+			return nil, ErrInvalidSubjectRewrite(from, to, "subjects can't be empty")
+		}
+		fromTokens := strings.Split(from, subjectSeparator)
+		toTokens := strings.Split(to, subjectSeparator)
+		fromWildcards, toWildcards := wildcards(fromTokens), wildcards(toTokens)
+		if fromWildcards == "" {
+			r.exact[from] = to
+			continue
+		}
+		if toWildcards != "" && toWildcards != fromWildcards {
+			return nil, ErrInvalidSubjectRewrite(from, to, fmt.Sprintf("the replacement has the wildcards %q instead of %q", toWildcards, fromWildcards))
+		}
+		r.patterns = append(r.patterns, subjectRewrite{from: fromTokens, to: toTokens, wildcards: len(fromWildcards)})
+	}
+	sort.Slice(r.patterns, func(i, j int) bool {
+		a, b := r.patterns[i], r.patterns[j]
+		if a.wildcards != b.wildcards {
+			return a.wildcards < b.wildcards
+		}
+		if len(a.from) != len(b.from) {
+			return len(a.from) > len(b.from)
+		}
+		return strings.Join(a.from, subjectSeparator) < strings.Join(b.from, subjectSeparator)
+	})
+	return r, nil
+}
+
+// wildcards returns the wildcards among the tokens, in order, e.g. "*>" for meshsync.*.event.>.
+func wildcards(tokens []string) string {
+	var w strings.Builder
+	for _, token := range tokens {
+		if token == Wildcard || token == FullWildcard {
+			w.WriteString(token)
+		}
+	}
+	return w.String()
+}
+
+// Rewrite returns the replacement of the subject, or the subject if no rewrite applies.
+func (r *SubjectRewriter) Rewrite(subject string) string {
+	if r == nil {
+		return subject
+	}
+	if to, ok := r.exact[subject]; ok {
+		return to
+	}
+	tokens := strings.Split(subject, subjectSeparator)
+	for _, p := range r.patterns {
+		captured, ok := matchTokens(p.from, tokens)
+		if !ok {
+			continue
+		}
+		rewritten := make([]string, len(p.to))
+		next := 0
+		for i, token := range p.to {
+			if (token == Wildcard || token == FullWildcard) && next < len(captured) {
+				token = captured[next]
+				next++
+			}
+			rewritten[i] = token
+		}
+		return strings.Join(rewritten, subjectSeparator)
+	}
+	return subject
+}
@@ -0,0 +1,66 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"meshery.>", "meshery.c1.event.add", true},
+		{"meshery.>", "meshery", false},
+		{"meshsync.*.pod", "meshsync.c1.pod", true},
+		{"meshsync.*.pod", "meshsync.c1.pod.add", false},
+		{"meshsync.*.pod", "meshsync.pod", false},
+		{"meshsync.c1", "meshsync.c1", true},
+		{"*.*", "legacy.subject", true},
+		{"meshsync.*.>", "meshsync.c1", false},
+	}
+	for _, tt := range tests {
+		if got := MatchSubject(tt.pattern, tt.subject); got != tt.want {
+			t.Errorf("MatchSubject(%q, %q) = %v; want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestSubjectRewriter(t *testing.T) {
+	r, err := NewSubjectRewriter(map[string]string{
+		"meshsync.legacy":    "meshsync.c1.meshsync-data.publish",
+		"legacy.*.pod":       "meshsync.*.pod",
+		"legacy.*.>":         "meshsync.*.event.>",
+		"legacy.c1.pod":      "meshsync.c1.pod.update",
+		"operator.*.request": "operator.all.request",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"meshsync.legacy":      "meshsync.c1.meshsync-data.publish",
+		"legacy.c2.pod":        "meshsync.c2.pod",
+		"legacy.c1.pod":        "meshsync.c1.pod.update",
+		"legacy.c2.pod.add":    "meshsync.c2.event.pod.add",
+		"legacy.*.pod":         "meshsync.*.pod",
+		"legacy.*.>":           "meshsync.*.event.>",
+		"operator.c3.request":  "operator.all.request",
+		"meshery.c1.event.add": "meshery.c1.event.add",
+	}
+	for subject, want := range tests {
+		if got := r.Rewrite(subject); got != want {
+			t.Errorf("Rewrite(%q) = %q; want %q", subject, got, want)
+		}
+	}
+	var none *SubjectRewriter
+	if got := none.Rewrite("meshsync.legacy"); got != "meshsync.legacy" {
+		t.Errorf("nil Rewrite() = %q", got)
+	}
+
+	for _, invalid := range []map[string]string{{"legacy.*.>": "meshsync.>.*"}, {"legacy.*": "meshsync.*.*"}, {"": "meshsync"}} {
+		if _, err := NewSubjectRewriter(invalid); err == nil || errors.GetCode(err) != ErrInvalidSubjectRewriteCode {
+			t.Errorf("NewSubjectRewriter(%v) = %v; want an invalid rewrite error", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package broker
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Components publishing or subscribing to subjects.
+const (
+	MesheryComponent  = "meshery"
+	MeshSyncComponent = "meshsync"
+	AdapterComponent  = "adapter"
+	OperatorComponent = "operator"
+)
+
+// Resources of subjects.
+const (
+	ResourceRequest  = "request"
+	ResourceEvent    = "event"
+	ResourceLog      = "log"
+	ResourceExec     = "exec"
+	ResourceMeshSync = "meshsync-data"
+)
+
+// Verbs of subjects.
+const (
+	VerbAdd     = "add"
+	VerbUpdate  = "update"
+	VerbDelete  = "delete"
+	VerbReSync  = "resync"
+	VerbPublish = "publish"
+)
+
+// Wildcards can be used as tokens of subjects of subscriptions.
+// Wildcard matches a single token, FullWildcard all remaining tokens and can only be the last one.
+const (
+	Wildcard     = "*"
+	FullWildcard = ">"
+)
+
+const subjectSeparator = "."
+
+// Subject is a subject following the naming convention <component>.<cluster>.<resource>.<verb>, e.g. meshsync.3d6f1e6a.event.add.
+//
+// Only the component is required. The trailing tokens can be omitted, to address all clusters, resources or verbs of a component,
+// but there can't be gaps: use Wildcard to match any value of a token in the middle.
+type Subject struct {
+	Component string
+	Cluster   string
+	Resource  string
+	Verb      string
+}
+
+// String returns the subject as used by the broker. It does not validate the subject, use Validate for that.
+func (s Subject) String() string {
+	return strings.Join(s.tokens(), subjectSeparator)
+}
+
+func (s Subject) tokens() []string {
+	tokens := []string{s.Component, s.Cluster, s.Resource, s.Verb}
+	for len(tokens) > 0 && tokens[len(tokens)-1] == "" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// Validate returns an error if the subject does not follow the naming convention.
+func (s Subject) Validate() error {
+	tokens := s.tokens()
+	if len(tokens) == 0 {
+		return ErrInvalidSubject(s.String(), "the component is missing")
+	}
+	names := []string{"component", "cluster", "resource", "verb"}
+	for i, token := range tokens {
+		if err := validateToken(token, i == len(tokens)-1); err != nil {
+			return ErrInvalidSubject(s.String(), fmt.Sprintf("%s %s", names[i], err))
+		}
+	}
+	return nil
+}
+
+// IsWildcard reports whether the subject contains wildcards, i.e. can only be subscribed to, not published to.
+func (s Subject) IsWildcard() bool {
+	for _, token := range s.tokens() {
+		if token == Wildcard || token == FullWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSubject parses and validates a subject following the naming convention.
+func ParseSubject(subject string) (Subject, error) {
+	if subject == "" {
+		return Subject{}, ErrInvalidSubject(subject, "the component is missing")
+	}
+	tokens := strings.Split(subject, subjectSeparator)
+	if len(tokens) > 4 {
+		return Subject{}, ErrInvalidSubject(subject, fmt.Sprintf("found %d tokens, at most 4 are allowed", len(tokens)))
+	}
+	parts := make([]string, 4)
+	copy(parts, tokens)
+	s := Subject{Component: parts[0], Cluster: parts[1], Resource: parts[2], Verb: parts[3]}
+	// gaps are detected by validating the trailing tokens, as String() removes only trailing empty tokens
+	if len(s.tokens()) != len(tokens) {
+		return Subject{}, ErrInvalidSubject(subject, "the last token is empty")
+	}
+	if err := s.Validate(); err != nil {
+		return Subject{}, err
+	}
+	return s, nil
+}
+
+func validateToken(token string, last bool) error {
+	switch {
+	case token == "":
+		return fmt.Errorf("is empty")
+	case token == Wildcard:
+		return nil
+	case token == FullWildcard:
+		if !last {
+			return fmt.Errorf("is %q, which is only allowed as last token", FullWildcard)
+		}
+		return nil
+	}
+	for _, r := range token {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(".*>", r) {
+			return fmt.Errorf("%q contains the invalid character %q", token, r)
+		}
+	}
+	return nil
+}
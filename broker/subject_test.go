@@ -0,0 +1,59 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestSubject(t *testing.T) {
+	tests := []struct {
+		subject  Subject
+		want     string
+		valid    bool
+		wildcard bool
+	}{
+		{Subject{Component: MeshSyncComponent, Cluster: "3d6f1e6a", Resource: ResourceEvent, Verb: VerbAdd}, "meshsync.3d6f1e6a.event.add", true, false},
+		{Subject{Component: MesheryComponent, Cluster: "c1"}, "meshery.c1", true, false},
+		{Subject{Component: MeshSyncComponent, Cluster: Wildcard, Resource: ResourceEvent}, "meshsync.*.event", true, true},
+		{Subject{Component: MeshSyncComponent, Cluster: FullWildcard}, "meshsync.>", true, true},
+		{Subject{Component: MeshSyncComponent, Cluster: FullWildcard, Resource: ResourceEvent}, "meshsync.>.event", false, true},
+		{Subject{Component: MeshSyncComponent, Resource: ResourceEvent}, "meshsync..event", false, false},
+		{Subject{Component: "mesh sync"}, "mesh sync", false, false},
+		{Subject{Component: MeshSyncComponent, Cluster: "a.b"}, "meshsync.a.b", false, false},
+		{Subject{}, "", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.subject.String(); got != tt.want {
+			t.Errorf("String() = %q; want %q", got, tt.want)
+		}
+		err := tt.subject.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) = %v; want valid %v", tt.want, err, tt.valid)
+		}
+		if err != nil && errors.GetCode(err) != ErrInvalidSubjectCode {
+			t.Errorf("Validate(%q) returned error code %q", tt.want, errors.GetCode(err))
+		}
+		if got := tt.subject.IsWildcard(); got != tt.wildcard {
+			t.Errorf("IsWildcard(%q) = %v; want %v", tt.want, got, tt.wildcard)
+		}
+	}
+}
+
+func TestParseSubject(t *testing.T) {
+	s, err := ParseSubject("meshsync.3d6f1e6a.event.add")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Subject{Component: "meshsync", Cluster: "3d6f1e6a", Resource: "event", Verb: "add"}); s != want {
+		t.Errorf("ParseSubject() = %+v; want %+v", s, want)
+	}
+	if s, err := ParseSubject("meshery.c1"); err != nil || s.Resource != "" || s.Cluster != "c1" {
+		t.Errorf("ParseSubject() = %+v, %v", s, err)
+	}
+	for _, invalid := range []string{"", "meshsync.", "meshsync..event", "a.b.c.d.e", "meshsync.>.event", ".meshsync"} {
+		if _, err := ParseSubject(invalid); err == nil {
+			t.Errorf("ParseSubject(%q) succeeded; want an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package coder
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersLocations are the locations of the CODEOWNERS file relative to the root of a repository,
+// in the order GitHub looks them up.
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeOwners maps the files of a repository to their owners, the users and teams of the CODEOWNERS file,
+// see https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/customizing-your-repository/about-code-owners.
+type codeOwners struct {
+	root  string
+	rules []codeOwnersRule
+}
+
+// loadCodeOwners reads the CODEOWNERS file of the repository at root, or the file if it is not empty.
+// The returned codeOwners are nil if no CODEOWNERS file was found at root.
+func loadCodeOwners(root, file string) (*codeOwners, error) {
+	if file == "" {
+		for _, location := range codeOwnersLocations {
+			if _, err := os.Stat(filepath.Join(root, location)); err == nil {
+				file = filepath.Join(root, location)
+				break
+			}
+		}
+		if file == "" {
+			return nil, nil
+		}
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	co := &codeOwners{root: root}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := regexp.Compile(codeOwnersPattern(fields[0]))
+		if err != nil {
+			return nil, err
+		}
+		co.rules = append(co.rules, codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return co, scanner.Err()
+}
+
+// codeOwnersPattern returns the regular expression of the CODEOWNERS pattern, which follows the rules of gitignore files:
+// patterns starting with or containing a / are relative to the root, others match at any depth,
+// and patterns matching a directory match the files it contains.
+func codeOwnersPattern(pattern string) string {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return b.String()
+}
+
+// owners returns the owners of the file, empty if no rule matches the file or if the last matching rule has no owners.
+func (co *codeOwners) owners(file string) []string {
+	if co == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(co.root, file)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].pattern.MatchString(rel) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package coder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	root := t.TempDir()
+	content := `# default owners
+*                       @meshery/maintainers
+/models/                @meshery/models-team
+utils/**/error.go       @meshery/utils-team @alice
+errors.go               @bob
+/cmd/errorutil/internal # no owners
+`
+	if err := os.MkdirAll(filepath.Join(root, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	co, err := loadCodeOwners(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][]string{
+		"error.go":                       {"@meshery/maintainers"},
+		"models/oci/error.go":            {"@meshery/models-team"},
+		"utils/error.go":                 {"@meshery/utils-team", "@alice"},
+		"utils/kubernetes/helm/error.go": {"@meshery/utils-team", "@alice"},
+		"utils/kubernetes/helm/helm.go":  {"@meshery/maintainers"},
+		"generators/github/errors.go":    {"@bob"},
+		"cmd/errorutil/internal/a.go":    {},
+		"cmd/errorutil/main.go":          {"@meshery/maintainers"},
+	}
+	for file, expected := range tests {
+		if got := co.owners(filepath.Join(root, file)); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v, got %v", file, expected, got)
+		}
+	}
+
+	if co, err := loadCodeOwners(t.TempDir(), ""); err != nil || co != nil || co.owners("error.go") != nil {
+		t.Errorf("expected no owners without CODEOWNERS file, got %v, %v", co, err)
+	}
+}
@@ -1,7 +1,9 @@
 package coder
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -19,12 +21,30 @@ const (
 	outDirCmdFlag              = "out-dir"
 	infoDirCmdFlag             = "info-dir"
 	forceUpdateAllCodesCmdFlag = "force"
+	perModuleCmdFlag           = "per-module"
+	exportFormatCmdFlag        = "export-format"
+	similarityThresholdCmdFlag = "similarity-threshold"
+	codeOwnersCmdFlag          = "codeowners"
+	formatCmdFlag              = "format"
+	componentTypesCmdFlag      = "component-types"
+)
+
+// Formats of the analysis
+const (
+	formatJSON  = "json"
+	formatJSONL = "jsonl"
 )
 
 type globalFlags struct {
 	verbose                  bool
 	rootDir, outDir, infoDir string
 	skipDirs                 []string
+	perModule                bool
+	exportFormat             string
+	similarityThreshold      float64
+	codeOwners               string
+	format                   string
+	componentTypes           []string
 }
 
 func defaultIfEmpty(value, defaultValue string) string {
@@ -51,6 +71,27 @@ func getGlobalFlags(cmd *cobra.Command) (globalFlags, error) {
 		return flags, err
 	}
 	flags.skipDirs = skipDirs
+	perModule, err := cmd.Flags().GetBool(perModuleCmdFlag)
+	if err != nil {
+		return flags, err
+	}
+	flags.perModule = perModule
+	exportFormat, err := cmd.Flags().GetString(exportFormatCmdFlag)
+	if err != nil {
+		return flags, err
+	}
+	flags.exportFormat, err = mesherr.ParseFormat(exportFormat)
+	if err != nil {
+		return flags, err
+	}
+	similarityThreshold, err := cmd.Flags().GetFloat64(similarityThresholdCmdFlag)
+	if err != nil {
+		return flags, err
+	}
+	if similarityThreshold < 0 || similarityThreshold > 1 {
+		return flags, fmt.Errorf("invalid --%s '%v', use a value between 0 and 1", similarityThresholdCmdFlag, similarityThreshold)
+	}
+	flags.similarityThreshold = similarityThreshold
 	outDir, err := cmd.Flags().GetString(outDirCmdFlag)
 	if err != nil {
 		return flags, err
@@ -61,58 +102,134 @@ func getGlobalFlags(cmd *cobra.Command) (globalFlags, error) {
 		return flags, err
 	}
 	flags.infoDir = defaultIfEmpty(infoDir, rootDir) // if infoDir is an empty string, rootDir is the default value
+	codeOwners, err := cmd.Flags().GetString(codeOwnersCmdFlag)
+	if err != nil {
+		return flags, err
+	}
+	flags.codeOwners = codeOwners
+	format, err := cmd.Flags().GetString(formatCmdFlag)
+	if err != nil {
+		return flags, err
+	}
+	if format != formatJSON && format != formatJSONL {
+		return flags, fmt.Errorf("invalid --%s '%s', use '%s' or '%s'", formatCmdFlag, format, formatJSON, formatJSONL)
+	}
+	flags.format = format
+	componentTypes, err := cmd.Flags().GetStringSlice(componentTypesCmdFlag)
+	if err != nil {
+		return flags, err
+	}
+	flags.componentTypes = componentTypes
 	return flags, nil
 }
 
-func walkSummarizeExport(globalFlags globalFlags, update bool, updateAll bool) error {
-	config.Logging(globalFlags.verbose)
-	errorsInfo := mesherr.NewInfoAll()
-	err := walk(globalFlags, update, updateAll, errorsInfo)
+// loadComponent reads the component_info.json file of the info directory and validates the type of the component.
+func loadComponent(globalFlags globalFlags) (*component.Info, error) {
+	comp, err := component.New(globalFlags.infoDir)
 	if err != nil {
-		return err
+		return comp, err
 	}
-	// if it was an update, carry out a second pass to get latest state
+	return comp, comp.Validate(globalFlags.componentTypes)
+}
+
+func walkSummarizeExport(globalFlags globalFlags, update bool, updateAll bool) error {
+	config.Logging(globalFlags.verbose)
+	var errorsInfo *mesherr.InfoAll
+	var err error
+	// if it is an update, the codes are updated in a first pass, and the analysis is the second pass to get latest state
 	if update {
-		errorsInfo = mesherr.NewInfoAll()
-		err = walk(globalFlags, false, false, errorsInfo)
+		err = walk(globalFlags, update, updateAll, mesherr.NewInfoAll())
 		if err != nil {
 			return err
 		}
 	}
-	jsn, err := json.MarshalIndent(errorsInfo, "", "  ")
+	componentInfo, err := loadComponent(globalFlags)
 	if err != nil {
 		return err
 	}
-	fname := filepath.Join(globalFlags.outDir, config.App+"_analyze_errors.json")
-	err = os.WriteFile(fname, jsn, 0600)
+	summaryOpts := mesherr.SummaryOptions{SimilarityThreshold: globalFlags.similarityThreshold}
+	if globalFlags.format == formatJSONL {
+		// the summary is computed while the tree is walked, the records are not kept for it
+		summary := mesherr.NewSummary(componentInfo, summaryOpts)
+		errorsInfo, err = walkStream(globalFlags, summary)
+		if err == nil {
+			err = summary.Write(globalFlags.outDir)
+		}
+	} else {
+		errorsInfo = mesherr.NewInfoAll()
+		err = walk(globalFlags, false, false, errorsInfo)
+		if err == nil {
+			err = writeAnalysis(globalFlags, errorsInfo)
+		}
+		if err == nil {
+			err = mesherr.SummarizeAnalysis(componentInfo, errorsInfo, globalFlags.outDir, summaryOpts)
+		}
+	}
 	if err != nil {
 		return err
 	}
-	componentInfo, err := component.New(globalFlags.infoDir)
+	return mesherr.Export(componentInfo, errorsInfo, globalFlags.outDir, mesherr.ExportOptions{
+		PerModule: globalFlags.perModule,
+		Format:    globalFlags.exportFormat,
+	})
+}
+
+func writeAnalysis(globalFlags globalFlags, errorsInfo *mesherr.InfoAll) error {
+	jsn, err := json.MarshalIndent(errorsInfo, "", "  ")
 	if err != nil {
 		return err
 	}
-	err = mesherr.SummarizeAnalysis(componentInfo, errorsInfo, globalFlags.outDir)
+	fname := filepath.Join(globalFlags.outDir, config.App+"_analyze_errors.json")
+	return os.WriteFile(fname, jsn, 0600)
+}
+
+// walkStream walks the tree and writes the entries, errors and files of the analysis as JSON Lines while they are found,
+// adding them to the summary. Only the entries and errors needed for the export are kept in the returned analysis.
+func walkStream(globalFlags globalFlags, summary *mesherr.Summary) (*mesherr.InfoAll, error) {
+	fname := filepath.Join(globalFlags.outDir, config.App+"_analyze_errors.jsonl")
+	f, err := os.OpenFile(fname, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	errorsInfo := mesherr.NewInfoAll()
+	errorsInfo.Stream = mesherr.NewStream(w)
+	errorsInfo.Summary = summary
+	if err := walk(globalFlags, false, false, errorsInfo); err != nil {
+		return nil, err
 	}
-	return mesherr.Export(componentInfo, errorsInfo, globalFlags.outDir)
+	if err := errorsInfo.Stream.Err(); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return errorsInfo, f.Close()
 }
 
 func commandAnalyze() *cobra.Command {
-	return &cobra.Command{
+	var reposFile string
+	var parallel int
+	cmd := &cobra.Command{
 		Use:   "analyze",
 		Short: "Analyze a directory tree",
-		Long:  `analyze analyzes a directory tree for error codes`,
+		Long:  `analyze analyzes a directory tree for error codes, or the repositories listed by --repos`,
 		Args:  cobra.MinimumNArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			gFlags, err := getGlobalFlags(cmd)
 			if err != nil {
 				return err
 			}
+			if reposFile != "" {
+				return analyzeRepos(gFlags, reposFile, parallel)
+			}
 			return walkSummarizeExport(gFlags, false, false)
 		},
 	}
+	cmd.Flags().StringVar(&reposFile, "repos", "", "YAML file listing the repositories to clone or update and analyze, instead of the root directory")
+	cmd.Flags().IntVar(&parallel, "parallel", 0, "number of repositories analyzed at the same time, the number of CPUs if 0")
+	return cmd
 }
 
 func commandUpdate() *cobra.Command {
@@ -138,6 +255,43 @@ func commandUpdate() *cobra.Command {
 	return cmd
 }
 
+func commandPredicates() *cobra.Command {
+	var exportFile, pkgName, output string
+	cmd := &cobra.Command{
+		Use:   "predicates",
+		Short: "Generate predicates of the exported errors",
+		Long:  "predicates generates a Go file with an IsErr... predicate function for each error of an export, to branch on specific MeshKit errors",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gFlags, err := getGlobalFlags(cmd)
+			if err != nil {
+				return err
+			}
+			config.Logging(gFlags.verbose)
+			if exportFile == "" {
+				exportFile = filepath.Join(gFlags.outDir, config.App+"_errors_export."+gFlags.exportFormat)
+			}
+			componentName, errs, err := mesherr.ReadExport(exportFile)
+			if err != nil {
+				return err
+			}
+			src, err := mesherr.GeneratePredicates(componentName, errs, pkgName)
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				_, err = cmd.OutOrStdout().Write(src)
+				return err
+			}
+			return os.WriteFile(output, src, 0600)
+		},
+	}
+	cmd.Flags().StringVar(&exportFile, "export", "", "export to generate the predicates of, the export in the output directory if empty")
+	cmd.Flags().StringVar(&pkgName, "package", "meshkiterrors", "name of the Go package of the generated file")
+	cmd.Flags().StringVar(&output, "output", "", "file the predicates are written to, stdout if empty")
+	return cmd
+}
+
 func commandDoc() *cobra.Command {
 	return &cobra.Command{
 		Use:   "doc",
@@ -153,12 +307,16 @@ A MeshKit compatible error consist of
   - The initial value of the code is a placeholder string, e.g. "replace_me", set by the developer.
   - The final value of the code is an integer, set by this tool, as part of a CI workflow.
 - Error details defined using the function errors.New(code, severity, sdescription, ldescription, probablecause, remedy) from MeshKit.
+  Alternatively, the builder errors.NewBuilder(code).Severity(...).ShortDescription(...)...Build() can be used.
  - The first parameter, 'code', has to be passed as the error code constant (or variable), not a string literal.
  - The second parameter, 'severity', has its own type; consult its Go-doc for further details.
  - The remaining parameters are string arrays for short and long description, probable cause, and suggested remediation.
  - Use string literals in these string arrays, not constants or variables, for any static texts.
  - Capitalize the first letter of each statement.
  - Call expressions can be used but will be ignored by the tool when exporting error details for the documentation.
+ - Statements can be templates like "Failed to apply manifest {{.Name}}", rendered using errors.New(...).WithParameters(...).
+   The template text is exported for the documentation. Append dynamic texts like err.Error() with WithCauses(err),
+   or the Cause(err) method of the builder, so that they are not rendered as templates.
  - Do not concatenate strings using the '+' operator, just add multiple elements to the string array.
 
 Additionally, the following conventions apply:
@@ -170,13 +328,58 @@ Additionally, the following conventions apply:
 
 This tool produces three files:
 - errorutil_analyze_errors.json: raw data with all errors and some metadata
+  With --format jsonl, the raw data is written as errorutil_analyze_errors.jsonl instead, one JSON record per line,
+  streamed while the tree is walked: {"kind":"entry","entry":{...}}, {"kind":"error","error":{...}}
+  or {"kind":"deprecated_new_default","path":"..."}. This reduces the memory used for very large trees: the summary is
+  computed while the tree is walked, and only the entries with literal codes and the error details are kept for the export.
 - errorutil_analyze_summary.json: summary of raw data, also used for validation and troubleshooting
+  It lists errors of different codes whose short or long descriptions are identical or near-identical, e.g. copied and not adapted.
+  Descriptions are compared in lower case, without punctuation and templates; --similarity-threshold sets the similarity
+  from which they are reported, 1 for identical descriptions only and 0 to disable the detection.
 - errorutil_errors_export.json: export of errors which can be used to create the error code reference on the Meshery website
+  With --export-format yaml, the export is written as errorutil_errors_export.yaml, e.g. to commit it into the _data directory of a Jekyll site.
+  With --per-module, one export per Go module of the tree is written instead, e.g. errorutil_errors_export_github.com_layer5io_meshkit.json.
+
+If the root directory has a CODEOWNERS file, in the .github, root or docs directory, or if one is set using --codeowners,
+the owners of the file defining each error code are included in the analysis and the export, so that issues can be routed to them.
+The paths of the CODEOWNERS file are relative to the root directory, the last matching pattern takes precedence.
 
 Typically, the 'analyze' command of the tool is used by the developer to verify errors, i.e. that there are no duplicate names or details.
 A CI workflow is used to replace the placeholder code strings with integer code, and export errors. Using this export, the workflow updates 
 the error code reference documentation in the Meshery repository.
 
+With --repos, 'analyze' clones or updates each repository of the file, e.g. the repositories of the error code reference,
+analyzes them concurrently (--parallel), and writes the outputs of each repository to <out-dir>/<name>, as well as
+errorutil_errors_export_all.json, with the exports of all of them, and errorutil_repos_summary.json, with the outcome of each:
+  workspace: .errorutil-repos   # where the repositories are cloned, relative to the file
+  repositories:
+    - name: meshkit
+      url: https://github.com/meshery/meshkit
+      branch: master
+      info_dir: helpers          # directory of component_info.json, the root of the repository by default
+      skip_dirs: [vendor]
+      token_env: GITHUB_TOKEN    # environment variable holding a token, for private repositories
+    - name: local
+      path: ../meshery-adapter   # a local checkout is analyzed in place
+A repository failing does not stop the others.
+
+The 'hook' command analyzes only the files passed as arguments, or read from stdin, one per line, e.g. the staged files of a commit.
+It prints concise findings, e.g. duplicate codes or usages of NewDefault, and exits with status 1 if any of them is an error.
+An example .pre-commit-config.yaml:
+  repos:
+    - repo: local
+      hooks:
+        - id: errorutil
+          name: errorutil
+          entry: go run github.com/layer5io/meshkit/cmd/errorutil hook
+          language: system
+          types: [go]
+
+The 'predicates' command generates a Go file with a predicate function for each error of an export, e.g.
+  func IsErrApplyManifest(err error) bool { return errors.HasCode(err, "meshkit-11000") }
+so that downstream code can branch on specific MeshKit errors without comparing raw code strings:
+  errorutil predicates --export errorutil_errors_export.json --package meshkiterrors --output predicates.go
+
 Meshery components and this tool:
 - Meshery components have a name and a type.
 - An example of a component is MeshKit with 'meshkit' as name, and 'library' as type.
@@ -188,6 +391,9 @@ Meshery components and this tool:
     "type": "library",
     "next_error_code": 1014
   }
+- type is one of library, adapter, server or client, the types of the error pages of the documentation.
+  The tool fails if the type is not allowed, --component-types sets other allowed types.
+  The type is included in the export and the summary.
 - next_error_code is the value used by the tool to replace the error code placeholder string with the next integer.
 - The tool updates next_error_code. 
 `)
@@ -202,8 +408,16 @@ func RootCommand() *cobra.Command {
 	cmd.PersistentFlags().StringP(outDirCmdFlag, "o", "", "output directory")
 	cmd.PersistentFlags().StringP(infoDirCmdFlag, "i", "", "directory containing the component_info.json file")
 	cmd.PersistentFlags().StringSlice(skipDirsCmdFlag, []string{}, "directories to skip (comma-separated list, repeatable argument)")
+	cmd.PersistentFlags().Bool(perModuleCmdFlag, false, "namespace the exports per Go module, writing one export per module")
+	cmd.PersistentFlags().String(exportFormatCmdFlag, mesherr.FormatJSON, "format of the export, json or yaml")
+	cmd.PersistentFlags().Float64(similarityThresholdCmdFlag, mesherr.DefaultSimilarityThreshold, "similarity from which descriptions of different error codes are reported as duplicates, between 0 and 1, 0 to disable")
+	cmd.PersistentFlags().String(codeOwnersCmdFlag, "", "CODEOWNERS file mapping the files to their owners, looked up in .github, the root and docs directories if empty")
+	cmd.PersistentFlags().String(formatCmdFlag, formatJSON, "format of the analysis, json or jsonl to stream the results as they are found")
+	cmd.PersistentFlags().StringSlice(componentTypesCmdFlag, component.DefaultTypes, "allowed types of the component in component_info.json (comma-separated list, repeatable argument)")
 	cmd.AddCommand(commandAnalyze())
 	cmd.AddCommand(commandUpdate())
 	cmd.AddCommand(commandDoc())
+	cmd.AddCommand(commandHook())
+	cmd.AddCommand(commandPredicates())
 	return cmd
 }
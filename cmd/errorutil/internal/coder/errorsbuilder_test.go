@@ -0,0 +1,88 @@
+package coder
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	errutilerr "github.com/layer5io/meshkit/cmd/errorutil/internal/error"
+	mesherr "github.com/layer5io/meshkit/errors"
+)
+
+var ErrTestBuilderCode1 = "builder-one"   //nolint:unused
+var ErrTestBuilderCode2 = "builder-two"   //nolint:unused
+var ErrTestBuilderCode3 = "builder-three" //nolint:unused
+var builderTestFile = "errorsbuilder_test.go"
+
+//nolint:unused
+func ErrBuilderTestOne(err error) error {
+	return mesherr.NewBuilder(ErrTestBuilderCode1).Build()
+}
+
+//nolint:unused
+func ErrBuilderTestTwo(err error) error {
+	return mesherr.NewBuilder(ErrTestBuilderCode2).
+		Severity(mesherr.Fatal).
+		ShortDescription("line11").
+		LongDescription("line21", err.Error()).
+		LongDescription("line22").
+		Remedy("line41").
+		Build()
+}
+
+//nolint:unused
+func ErrBuilderTestThree(name string) error {
+	return mesherr.NewBuilder(ErrTestBuilderCode3).
+		ShortDescription("Failed to apply manifest {{.Name}}").
+		Parameters(mesherr.Parameters{"Name": name}).
+		Build()
+}
+
+func TestDetectBuilder(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), builderTestFile, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("err = %v; want 'nil'", err)
+	}
+	errors := map[string]*errutilerr.Error{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if error, ok := isBuilderCallExpr(n); ok {
+			errors[error.Name] = error
+			return false
+		}
+		return true
+	})
+	if len(errors) != 3 {
+		t.Fatalf("found %d builder chains; want 3", len(errors))
+	}
+	if e := errors["ErrTestBuilderCode1"]; e == nil || e.Severity != "None" || e.ShortDescription != "" || e.LongDescription != "" {
+		t.Errorf("invalid error found: %v", e)
+	}
+	e := errors["ErrTestBuilderCode2"]
+	if e == nil || !(e.Severity == "Fatal" &&
+		e.ShortDescription == "line11" &&
+		e.LongDescription == "line21\nline22" &&
+		e.ProbableCause == "" &&
+		e.SuggestedRemediation == "line41") {
+		t.Errorf("invalid error found: %v", e)
+	}
+	// the static template text is exported, not the rendered statement
+	if e := errors["ErrTestBuilderCode3"]; e == nil || e.ShortDescription != "Failed to apply manifest {{.Name}}" {
+		t.Errorf("invalid error found: %v", e)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	b := mesherr.NewBuilder(ErrTestBuilderCode2).Severity(mesherr.Alert).ShortDescription("short")
+	first := b.Build()
+	second := b.LongDescription("long").Build()
+	if mesherr.GetCode(first) != ErrTestBuilderCode2 || mesherr.GetSeverity(first) != mesherr.Alert {
+		t.Errorf("got code %s and severity %v", mesherr.GetCode(first), mesherr.GetSeverity(first))
+	}
+	if len(first.LongDescription) != 0 || first.Error() != "" {
+		t.Errorf("first error changed by the builder: %v", first.LongDescription)
+	}
+	if second.Error() != "long" {
+		t.Errorf("Error() = %q; want %q", second.Error(), "long")
+	}
+}
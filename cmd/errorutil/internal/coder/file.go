@@ -15,8 +15,8 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func handleFile(path string, update bool, updateAll bool, infoAll *errutilerr.InfoAll, comp *component.Info) error {
-	logger := logrus.WithFields(logrus.Fields{"path": path})
+func handleFile(path string, pkg goPackage, update bool, updateAll bool, infoAll *errutilerr.InfoAll, comp *component.Info) error {
+	logger := logrus.WithFields(logrus.Fields{"path": path, "package": pkg.importPath})
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
 	if err != nil {
@@ -27,25 +27,24 @@ func handleFile(path string, update bool, updateAll bool, infoAll *errutilerr.In
 	ast.Inspect(file, func(n ast.Node) bool {
 		if pgkid, ok := isNewDefaultCallExpr(n); ok {
 			logger.Warnf("Usage of deprecated function %s.NewDefault detected.", pgkid)
-			if !contains(infoAll.DeprecatedNewDefault, path) {
-				infoAll.DeprecatedNewDefault = append(infoAll.DeprecatedNewDefault, path)
-			}
+			infoAll.AddDeprecatedNewDefault(path)
 			// If a NewDefault call expression is detected, child-nodes are not inspected.
 			// This would lead to duplicates detections in case of dot-import.
 			return false
 		}
-		if newErr, ok := isNewCallExpr(n); ok {
+		newErr, ok := isNewCallExpr(n)
+		if !ok {
+			newErr, ok = isBuilderCallExpr(n)
+		}
+		if ok {
 			name := newErr.Name
-			logger.Infof("New.Error(...) call detected, error code name: '%s'", name)
-			_, ok := infoAll.Errors[name]
-			if !ok {
-				infoAll.Errors[name] = []errutilerr.Error{}
-			}
-			infoAll.Errors[name] = append(infoAll.Errors[name], *newErr)
+			logger.Infof("errors.New(...) or errors.NewBuilder(...) call detected, error code name: '%s'", name)
+			newErr.Package = pkg.importPath
+			infoAll.AddError(*newErr)
 			// If a New call expression is detected, child-nodes are not inspected:
 			return false
 		}
-		if handleValueSpec(n, update, updateAll, comp, logger, path, infoAll) {
+		if handleValueSpec(n, update, updateAll, comp, logger, path, pkg, infoAll) {
 			anyValueChanged = true
 		}
 		return true
@@ -0,0 +1,173 @@
+package coder
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/cmd/errorutil/internal/component"
+	"github.com/layer5io/meshkit/cmd/errorutil/internal/config"
+	mesherr "github.com/layer5io/meshkit/cmd/errorutil/internal/error"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// finding is a problem found in the staged files by the hook command.
+type finding struct {
+	path     string
+	severity string
+	message  string
+}
+
+func (f finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.path, f.severity, f.message)
+}
+
+// readPaths returns the paths read from r, separated by newlines or NUL characters as e.g. 'git diff --cached --name-only -z' does.
+func readPaths(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexAny(data, "\n\x00"); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	var paths []string
+	for scanner.Scan() {
+		if path := strings.TrimSpace(scanner.Text()); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// analyzeFiles analyzes the Go files among the paths, test files and deleted files are skipped.
+func analyzeFiles(paths []string, comp *component.Info) (*mesherr.InfoAll, error) {
+	infoAll := mesherr.NewInfoAll()
+	modules := newModuleResolver()
+	for _, path := range paths {
+		if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		if err := handleFile(path, modules.resolve(path), false, false, infoAll, comp); err != nil {
+			return nil, err
+		}
+	}
+	return infoAll, nil
+}
+
+// hookFindings returns the problems of the analyzed files, sorted by path.
+func hookFindings(infoAll *mesherr.InfoAll, similarityThreshold float64) []finding {
+	var findings []finding
+	paths := make(map[string]string) // name -> path of the code
+	for _, e := range infoAll.Entries {
+		paths[e.Name] = e.Path
+		if !isErrorGoFile(e.Path) {
+			findings = append(findings, finding{e.Path, severityWarning, fmt.Sprintf("error code '%s' is defined outside of error.go, its code is not updated", e.Name)})
+		}
+	}
+	for _, e := range infoAll.CallExprCodes {
+		findings = append(findings, finding{e.Path, severityWarning, fmt.Sprintf("error code '%s' is set by a call expression instead of a literal, it is not exported", e.Name)})
+	}
+	for code, infos := range infoAll.LiteralCodes {
+		// placeholders are replaced by integer codes when updating
+		if len(infos) < 2 || !infos[0].CodeIsInt {
+			continue
+		}
+		names := make([]string, 0, len(infos))
+		for _, info := range infos {
+			names = append(names, info.Name)
+		}
+		for _, info := range infos {
+			findings = append(findings, finding{info.Path, severityError, fmt.Sprintf("duplicate error code '%s', used by %s", code, strings.Join(names, ", "))})
+		}
+	}
+	for name, details := range infoAll.Errors {
+		packages := make(map[string]int)
+		for _, d := range details {
+			packages[d.Package]++
+		}
+		for pkg, count := range packages {
+			if count > 1 {
+				findings = append(findings, finding{defaultIfEmpty(paths[name], pkg), severityError, fmt.Sprintf("duplicate error details for error name '%s'", name)})
+			}
+		}
+	}
+	for _, path := range infoAll.DeprecatedNewDefault {
+		findings = append(findings, finding{path, severityError, "usage of deprecated function NewDefault, use errors.New instead"})
+	}
+	for _, d := range mesherr.FindDuplicateDescriptions(infoAll, similarityThreshold) {
+		findings = append(findings, finding{paths[d.Names[1]], severityWarning, fmt.Sprintf("%s of '%s' is similar to the one of '%s' (similarity %.2f)", d.Field, d.Names[1], d.Names[0], d.Similarity)})
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].path != findings[j].path {
+			return findings[i].path < findings[j].path
+		}
+		return findings[i].message < findings[j].message
+	})
+	return findings
+}
+
+func commandHook() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hook [files...]",
+		Short: "Analyze staged files, e.g. in a pre-commit hook",
+		Long: `hook analyzes the errors of the files passed as arguments, or read from stdin, one per line, if there are none.
+Findings are printed one per line, and the exit status is 1 if any of them is an error.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gFlags, err := getGlobalFlags(cmd)
+			if err != nil {
+				return err
+			}
+			config.Logging(gFlags.verbose)
+			if !gFlags.verbose {
+				logrus.SetLevel(logrus.ErrorLevel)
+			}
+			paths := args
+			if len(paths) == 0 {
+				paths, err = readPaths(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+			}
+			// the component info is optional, it is used to strip the component name from codes
+			comp, err := loadComponent(gFlags)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			infoAll, err := analyzeFiles(paths, comp)
+			if err != nil {
+				return err
+			}
+			errs := 0
+			for _, f := range hookFindings(infoAll, gFlags.similarityThreshold) {
+				fmt.Fprintln(cmd.OutOrStdout(), f)
+				if f.severity == severityError {
+					errs++
+				}
+			}
+			if errs > 0 {
+				return fmt.Errorf("%d error(s) found in the errors of the staged files", errs)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package coder
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPaths(t *testing.T) {
+	paths, err := readPaths(strings.NewReader("a/error.go\n\nb/error.go\x00c/main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a/error.go", "b/error.go", "c/main.go"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestHook(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/repo\n",
+		"a/error.go": `package a
+
+import "github.com/layer5io/meshkit/errors"
+
+const ErrApplyCode = "1001"
+
+func ErrApply(err error) error {
+	return errors.New(ErrApplyCode, errors.Alert, []string{"Failed to apply"}, []string{err.Error()}, []string{}, []string{})
+}
+`,
+		"b/error.go": `package b
+
+const ErrDeleteCode = "1001"
+`,
+		"b/b_test.go": `package b
+
+const ErrTestCode = "1001"
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdin := strings.Join([]string{
+		filepath.Join(root, "a/error.go"),
+		filepath.Join(root, "b/error.go"),
+		filepath.Join(root, "b/b_test.go"),
+		filepath.Join(root, "deleted/error.go"),
+	}, "\n")
+	cmd := RootCommand()
+	out := new(bytes.Buffer)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"hook", "-i", root})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "2 error(s)") {
+		t.Errorf("expected 2 errors, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 findings, got %q", out.String())
+	}
+	for i, file := range []string{"a/error.go", "b/error.go"} {
+		expected := filepath.Join(root, file) + ": error: duplicate error code '1001', used by ErrApplyCode, ErrDeleteCode"
+		if lines[i] != expected {
+			t.Errorf("expected %q, got %q", expected, lines[i])
+		}
+	}
+
+	cmd = RootCommand()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"hook", "-i", root, filepath.Join(root, "a/error.go")})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("expected no errors, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package coder
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// goPackage identifies the Go module and package of a file.
+type goPackage struct {
+	module     string // the module path, e.g. "github.com/layer5io/meshkit"
+	importPath string // the full import path of the package, e.g. "github.com/layer5io/meshkit/utils/kubernetes"
+}
+
+type goModule struct {
+	path string
+	dir  string
+}
+
+// moduleResolver finds the Go module, i.e. the closest go.mod file, of the files walked.
+// Modules are looked up once per directory.
+type moduleResolver struct {
+	modules map[string]*goModule // directory -> module, nil if the directory is in no module
+}
+
+func newModuleResolver() *moduleResolver {
+	return &moduleResolver{modules: make(map[string]*goModule)}
+}
+
+// resolve returns the module and package of the file, both empty if the file is in no module.
+func (r *moduleResolver) resolve(file string) goPackage {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return goPackage{}
+	}
+	mod := r.module(dir)
+	if mod == nil {
+		return goPackage{}
+	}
+	rel, err := filepath.Rel(mod.dir, dir)
+	if err != nil {
+		return goPackage{module: mod.path}
+	}
+	return goPackage{module: mod.path, importPath: path.Join(mod.path, filepath.ToSlash(rel))}
+}
+
+func (r *moduleResolver) module(dir string) *goModule {
+	if mod, ok := r.modules[dir]; ok {
+		return mod
+	}
+	var mod *goModule
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		if modPath := modfile.ModulePath(data); modPath != "" {
+			mod = &goModule{path: modPath, dir: dir}
+		}
+	}
+	if mod == nil {
+		if parent := filepath.Dir(dir); parent != dir {
+			mod = r.module(parent)
+		}
+	}
+	r.modules[dir] = mod
+	return mod
+}
@@ -0,0 +1,44 @@
+package coder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModuleResolver(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                   "module example.com/repo\n",
+		"pkg/a/error.go":           "package a",
+		"tools/go.mod":             "module example.com/repo/tools\n\ngo 1.21\n",
+		"tools/cmd/lint/error.go":  "package main",
+		"tools/error.go":           "package tools",
+		"error.go":                 "package repo",
+		"testdata/nomod/error.go":  "package nomod",
+		"testdata/nomod/README.md": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := newModuleResolver()
+	tests := map[string]goPackage{
+		"error.go":                {module: "example.com/repo", importPath: "example.com/repo"},
+		"pkg/a/error.go":          {module: "example.com/repo", importPath: "example.com/repo/pkg/a"},
+		"tools/error.go":          {module: "example.com/repo/tools", importPath: "example.com/repo/tools"},
+		"tools/cmd/lint/error.go": {module: "example.com/repo/tools", importPath: "example.com/repo/tools/cmd/lint"},
+		"testdata/nomod/error.go": {module: "example.com/repo", importPath: "example.com/repo/testdata/nomod"},
+	}
+	for file, expected := range tests {
+		if got := r.resolve(filepath.Join(root, file)); got != expected {
+			t.Errorf("%s: expected %+v, got %+v", file, expected, got)
+		}
+	}
+}
@@ -116,9 +116,86 @@ func isNewCallExpr(node ast.Node) (*errutilerr.Error, bool) {
 	return empty, false
 }
 
+// builderSetters maps the methods of errors.Builder to the details they set.
+var builderSetters = map[string]func(e *errutilerr.Error, value string){
+	"ShortDescription": func(e *errutilerr.Error, value string) {
+		e.ShortDescription = joinStatements(e.ShortDescription, value)
+	},
+	"LongDescription": func(e *errutilerr.Error, value string) { e.LongDescription = joinStatements(e.LongDescription, value) },
+	"ProbableCause":   func(e *errutilerr.Error, value string) { e.ProbableCause = joinStatements(e.ProbableCause, value) },
+	"Remedy": func(e *errutilerr.Error, value string) {
+		e.SuggestedRemediation = joinStatements(e.SuggestedRemediation, value)
+	},
+}
+
+func joinStatements(existing, value string) string {
+	if existing == "" {
+		return value
+	}
+	return existing + "\n" + value
+}
+
+// isBuilderCallExpr checks whether node is a errors.NewBuilder(...)...Build() call chain and returns the error information if so.
+// As for errors.New(...), only string literals are exported, other arguments are ignored.
+func isBuilderCallExpr(node ast.Node) (*errutilerr.Error, bool) {
+	empty := &errutilerr.Error{}
+	ce, ok := node.(*ast.CallExpr)
+	if !ok {
+		return empty, false
+	}
+	sel, ok := ce.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Build" {
+		return empty, false
+	}
+	// the chain is walked from Build() back to NewBuilder(...), the setters are applied in source order afterwards
+	var calls []*ast.CallExpr
+	expr := sel.X
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return empty, false
+		}
+		if _, name, ok := isSelectorOrIdent(call.Fun); ok && name == "NewBuilder" {
+			if len(call.Args) != 1 {
+				return empty, false
+			}
+			_, codeName, ok := isSelectorOrIdent(call.Args[0])
+			if !ok {
+				return empty, false
+			}
+			newErr := &errutilerr.Error{Name: codeName, Severity: "None"}
+			for i := len(calls) - 1; i >= 0; i-- {
+				method := calls[i].Fun.(*ast.SelectorExpr).Sel.Name
+				if method == "Severity" && len(calls[i].Args) == 1 {
+					if _, severityName, ok := isSelectorOrIdent(calls[i].Args[0]); ok {
+						newErr.Severity = severityName
+					}
+					continue
+				}
+				set, ok := builderSetters[method]
+				if !ok {
+					continue
+				}
+				for _, arg := range calls[i].Args {
+					if lit, ok := arg.(*ast.BasicLit); ok {
+						set(newErr, strings.Trim(lit.Value, "\""))
+					}
+				}
+			}
+			return newErr, true
+		}
+		method, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return empty, false
+		}
+		calls = append(calls, call)
+		expr = method.X
+	}
+}
+
 // handleValueSpec inspects node n if it is a ValueSpec, analyzes and updates it (depending on update and updateAll).
 // Returns true if any value was changed.
-func handleValueSpec(n ast.Node, update bool, updateAll bool, comp *component.Info, logger *logrus.Entry, path string, infoAll *errutilerr.InfoAll) bool {
+func handleValueSpec(n ast.Node, update bool, updateAll bool, comp *component.Info, logger *logrus.Entry, path string, pkg goPackage, infoAll *errutilerr.InfoAll) bool {
 	anyValueChanged := false
 	spec, ok := n.(*ast.ValueSpec)
 	if ok {
@@ -153,21 +230,10 @@ func handleValueSpec(n ast.Node, update bool, updateAll bool, comp *component.In
 					CodeIsLiteral: isLiteral,
 					CodeIsInt:     isInteger,
 					Path:          path,
+					Package:       pkg.importPath,
+					Module:        pkg.module,
 				}
-				infoAll.Entries = append(infoAll.Entries, *ec)
-				if isLiteral {
-					key := oldValue
-					if oldValue == "" {
-						key = "no_code"
-					}
-					_, ok := infoAll.LiteralCodes[key]
-					if !ok {
-						infoAll.LiteralCodes[key] = []errutilerr.Info{}
-					}
-					infoAll.LiteralCodes[key] = append(infoAll.LiteralCodes[key], *ec)
-				} else {
-					infoAll.CallExprCodes = append(infoAll.CallExprCodes, *ec)
-				}
+				infoAll.AddEntry(*ec)
 			}
 		}
 	}
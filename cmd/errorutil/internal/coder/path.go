@@ -2,13 +2,10 @@ package coder
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"strings"
-
-	"github.com/layer5io/meshkit/cmd/errorutil/internal/component"
 
 	mesherr "github.com/layer5io/meshkit/cmd/errorutil/internal/error"
+	"github.com/layer5io/meshkit/utils/walker"
 	"github.com/sirupsen/logrus"
 )
 
@@ -27,37 +24,40 @@ func walk(globalFlags globalFlags, update bool, updateAll bool, errorsInfo *mesh
 	logrus.Info(fmt.Sprintf("output directory: %s", globalFlags.outDir))
 	logrus.Info(fmt.Sprintf("info directory: %s", globalFlags.infoDir))
 	logrus.Info(fmt.Sprintf("subdirs to skip: %v", subDirsToSkip))
-	comp, err := component.New(globalFlags.infoDir)
+	comp, err := loadComponent(globalFlags)
 	if err != nil {
 		return err
 	}
 
-	err = filepath.Walk(globalFlags.rootDir, func(path string, info os.FileInfo, err error) error {
-		logger := logrus.WithFields(logrus.Fields{"path": path})
-		if err != nil {
-			logger.WithFields(logrus.Fields{"error": fmt.Sprintf("%v", err)}).Warn("failure accessing path")
-			return err
-		}
-		if info.IsDir() && contains(subDirsToSkip, info.Name()) {
-			logger.Infof("skipping directory %s", info.Name())
-			return filepath.SkipDir
-		}
-		if info.IsDir() {
-			logger.Debug("handling dir")
-		} else {
-			if includeFile(path) {
-				isErrorsGoFile := isErrorGoFile(path)
-				logger.WithFields(logrus.Fields{"iserrorsfile": fmt.Sprintf("%v", isErrorsGoFile)}).Debug("handling Go file")
-				err := handleFile(path, update && isErrorsGoFile, updateAll, errorsInfo, comp)
-				if err != nil {
-					return err
-				}
-			} else {
-				logger.Debug("skipping file")
-			}
-		}
-		return nil
-	})
+	owners, err := loadCodeOwners(globalFlags.rootDir, globalFlags.codeOwners)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"error": fmt.Sprintf("%v", err)}).Warn("failure reading the CODEOWNERS file")
+		return err
+	}
+	errorsInfo.Owners = owners.owners
+
+	modules := newModuleResolver()
+	// errorsInfo is not safe for concurrent use, the files are handled one at a time
+	err = walker.NewLocal().
+		Root(globalFlags.rootDir).
+		Include("*.go").
+		Exclude("*_test.go").
+		Exclude(subDirsToSkip...).
+		RegisterDirInterceptor(func(d walker.Directory) error {
+			logrus.WithFields(logrus.Fields{"path": d.Path}).Debug("handling dir")
+			return nil
+		}).
+		RegisterFileInterceptor(func(f walker.File) error {
+			logger := logrus.WithFields(logrus.Fields{"path": f.Path})
+			isErrorsGoFile := isErrorGoFile(f.Path)
+			logger.WithFields(logrus.Fields{"iserrorsfile": fmt.Sprintf("%v", isErrorsGoFile)}).Debug("handling Go file")
+			return handleFile(f.Path, modules.resolve(f.Path), update && isErrorsGoFile, updateAll, errorsInfo, comp)
+		}).
+		Walk()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"error": fmt.Sprintf("%v", err)}).Warn("failure walking the root directory")
+		return err
+	}
 	if update {
 		err = comp.Write()
 	}
@@ -68,13 +68,3 @@ func isErrorGoFile(path string) bool {
 	_, file := filepath.Split(path)
 	return file == "error.go"
 }
-
-func includeFile(path string) bool {
-	if strings.HasSuffix(path, "_test.go") {
-		return false
-	}
-	if filepath.Ext(path) == ".go" {
-		return true
-	}
-	return false
-}
@@ -0,0 +1,194 @@
+package coder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/layer5io/meshkit/cmd/errorutil/internal/config"
+	mesherr "github.com/layer5io/meshkit/cmd/errorutil/internal/error"
+	"github.com/layer5io/meshkit/utils/pool"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// reposFile lists the repositories analyzed by 'analyze --repos'.
+type reposFile struct {
+	// Workspace is the directory the repositories are cloned into, relative to the file, .errorutil-repos by default
+	Workspace    string       `yaml:"workspace"`
+	Repositories []repository `yaml:"repositories"`
+}
+
+type repository struct {
+	Name string `yaml:"name"` // the name of the directories of the clone and of the outputs, e.g. "meshkit"
+	URL  string `yaml:"url"`  // the URL cloned, e.g. "https://github.com/meshery/meshkit"
+	// Path is a local checkout analyzed in place instead of a clone, relative to the file
+	Path     string   `yaml:"path"`
+	Branch   string   `yaml:"branch"`    // the branch cloned, the default branch if empty
+	InfoDir  string   `yaml:"info_dir"`  // the directory of component_info.json, relative to the root of the repository
+	SkipDirs []string `yaml:"skip_dirs"` // directories to skip, in addition to the ones of --skip-dirs
+	TokenEnv string   `yaml:"token_env"` // the environment variable holding the token to clone private repositories
+}
+
+// repoResult is the outcome of the analysis of a repository, written to the consolidated summary.
+type repoResult struct {
+	Name   string `yaml:"name" json:"name"`
+	URL    string `yaml:"url,omitempty" json:"url,omitempty"`
+	Dir    string `yaml:"dir" json:"dir"`         // the directory analyzed
+	OutDir string `yaml:"out_dir" json:"out_dir"` // the directory of the outputs of the repository
+	Error  string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+var repositoryName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+func readReposFile(fname string) (*reposFile, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var repos reposFile
+	if err := yaml.Unmarshal(data, &repos); err != nil {
+		return nil, fmt.Errorf("invalid repositories file %s: %v", fname, err)
+	}
+	base := filepath.Dir(fname)
+	if repos.Workspace == "" {
+		repos.Workspace = ".errorutil-repos"
+	}
+	if !filepath.IsAbs(repos.Workspace) {
+		repos.Workspace = filepath.Join(base, repos.Workspace)
+	}
+	names := make(map[string]bool)
+	for i, r := range repos.Repositories {
+		if !repositoryName.MatchString(r.Name) || names[r.Name] {
+			return nil, fmt.Errorf("invalid repositories file %s: repository %d has an invalid or duplicate name '%s'", fname, i, r.Name)
+		}
+		names[r.Name] = true
+		if (r.URL == "") == (r.Path == "") {
+			return nil, fmt.Errorf("invalid repositories file %s: repository '%s' needs either a url or a path", fname, r.Name)
+		}
+		if r.Path != "" && !filepath.IsAbs(r.Path) {
+			repos.Repositories[i].Path = filepath.Join(base, r.Path)
+		}
+	}
+	return &repos, nil
+}
+
+// analyzeRepos clones or updates the repositories of the file, analyzes them concurrently, and writes the outputs of each
+// repository to <out-dir>/<name>, and the consolidated export and summary of all of them to the output directory.
+// A repository failing does not stop the others, the failures are reported together once all are analyzed.
+func analyzeRepos(globalFlags globalFlags, fname string, parallel int) error {
+	config.Logging(globalFlags.verbose)
+	repos, err := readReposFile(fname)
+	if err != nil {
+		return err
+	}
+	results := make([]repoResult, len(repos.Repositories))
+	indices := make([]int, len(repos.Repositories))
+	for i := range indices {
+		indices[i] = i
+	}
+	analyzeErr := pool.ForEach(context.Background(), indices, pool.Options{Workers: parallel}, func(ctx context.Context, i int) error {
+		r := repos.Repositories[i]
+		result, err := analyzeRepo(ctx, globalFlags, repos.Workspace, r)
+		if err != nil {
+			result.Error = err.Error()
+			logrus.Errorf("repository '%s': %v", r.Name, err)
+			err = fmt.Errorf("repository '%s': %w", r.Name, err)
+		}
+		results[i] = result
+		return err
+	})
+
+	var exports []string
+	for _, result := range results {
+		if result.Error != "" {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(result.OutDir, config.App+"_errors_export*."+globalFlags.exportFormat))
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+		exports = append(exports, files...)
+	}
+	if err := mesherr.ConsolidateExports(exports, filepath.Join(globalFlags.outDir, config.App+"_errors_export_all."+globalFlags.exportFormat), globalFlags.exportFormat); err != nil {
+		return err
+	}
+	jsn, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(globalFlags.outDir, config.App+"_repos_summary.json"), jsn, 0600); err != nil {
+		return err
+	}
+	return analyzeErr
+}
+
+func analyzeRepo(ctx context.Context, globalFlags globalFlags, workspace string, r repository) (repoResult, error) {
+	result := repoResult{Name: r.Name, URL: r.URL, Dir: r.Path, OutDir: filepath.Join(globalFlags.outDir, r.Name)}
+	if r.URL != "" {
+		result.Dir = filepath.Join(workspace, r.Name)
+		if err := cloneOrPull(ctx, r, result.Dir); err != nil {
+			return result, err
+		}
+	}
+	if err := os.MkdirAll(result.OutDir, 0755); err != nil {
+		return result, err
+	}
+	flags := globalFlags
+	flags.rootDir = result.Dir
+	flags.outDir = result.OutDir
+	flags.infoDir = filepath.Join(result.Dir, r.InfoDir)
+	flags.skipDirs = append(append([]string{}, globalFlags.skipDirs...), r.SkipDirs...)
+	// the CODEOWNERS file set by the flag is the one of the current directory, each repository has its own
+	flags.codeOwners = ""
+	return result, walkSummarizeExport(flags, false, false)
+}
+
+// cloneOrPull clones the repository into dir, or pulls it if it was cloned by a previous run.
+func cloneOrPull(ctx context.Context, r repository, dir string) error {
+	var auth *githttp.BasicAuth
+	if r.TokenEnv != "" {
+		if token := os.Getenv(r.TokenEnv); token != "" {
+			auth = &githttp.BasicAuth{Username: "errorutil", Password: token}
+		}
+	}
+	var ref plumbing.ReferenceName
+	if r.Branch != "" {
+		ref = plumbing.NewBranchReferenceName(r.Branch)
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err == git.ErrRepositoryNotExists {
+		logrus.Infof("cloning %s into %s", r.URL, dir)
+		opts := &git.CloneOptions{URL: r.URL, ReferenceName: ref, SingleBranch: true, Depth: 1}
+		if auth != nil {
+			opts.Auth = auth
+		}
+		_, err = git.PlainCloneContext(ctx, dir, false, opts)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	logrus.Infof("updating %s in %s", r.URL, dir)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	opts := &git.PullOptions{RemoteName: git.DefaultRemoteName, ReferenceName: ref, SingleBranch: true, Depth: 1, Force: true}
+	if auth != nil {
+		opts.Auth = auth
+	}
+	if err := worktree.PullContext(ctx, opts); err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
+	}
+	return nil
+}
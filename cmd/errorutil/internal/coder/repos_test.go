@@ -0,0 +1,84 @@
+package coder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRepoErrors = `package a
+
+import "github.com/layer5io/meshkit/errors"
+
+const ErrExampleCode = "%s-1"
+
+func ErrExample(err error) error {
+	return errors.New(ErrExampleCode, errors.Alert, []string{"Example"}, []string{err.Error()}, []string{"cause"}, []string{"remedy"})
+}
+`
+
+func TestAnalyzeRepos(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"repos.yaml":                         "repositories:\n- name: adapter\n  path: adapter\n- name: server\n  path: server\n  info_dir: helpers\n- name: broken\n  path: broken\n",
+		"adapter/component_info.json":        `{"name": "adapter", "type": "adapter", "next_error_code": 2}`,
+		"adapter/error.go":                   strings.ReplaceAll(testRepoErrors, "%s", "adapter"),
+		"server/helpers/component_info.json": `{"name": "server", "type": "server", "next_error_code": 2}`,
+		"server/pkg/error.go":                strings.ReplaceAll(testRepoErrors, "%s", "server"),
+		"broken/component_info.json":         `{"name": "broken", "type": "unknown", "next_error_code": 2}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := t.TempDir()
+	flags := globalFlags{outDir: out, exportFormat: "json", format: formatJSON}
+	if err := analyzeRepos(flags, filepath.Join(root, "repos.yaml"), 2); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the repository with an invalid component type to fail, got %v", err)
+	}
+
+	for _, name := range []string{"adapter/errorutil_errors_export.json", "server/errorutil_analyze_summary.json"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("expected the outputs of each repository: %v", err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(out, "errorutil_errors_export_all.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var consolidated struct {
+		Components []struct {
+			ComponentName string                     `json:"component_name"`
+			ComponentType string                     `json:"component_type"`
+			Errors        map[string]json.RawMessage `json:"errors"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &consolidated); err != nil {
+		t.Fatal(err)
+	}
+	if len(consolidated.Components) != 2 || consolidated.Components[0].ComponentName != "adapter" || consolidated.Components[1].ComponentType != "server" {
+		t.Fatalf("consolidated export = %s", data)
+	}
+	if _, ok := consolidated.Components[1].Errors["1"]; !ok {
+		t.Errorf("expected the errors of the server, got %s", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(out, "errorutil_repos_summary.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []repoResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[0].Error != "" || results[2].Error == "" {
+		t.Errorf("repositories summary = %s", data)
+	}
+}
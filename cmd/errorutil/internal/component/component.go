@@ -2,6 +2,7 @@ package component
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,6 +14,9 @@ const (
 	filename = "component_info.json"
 )
 
+// DefaultTypes are the types of the Meshery components, which the documentation groups the error pages by.
+var DefaultTypes = []string{"library", "adapter", "server", "client"}
+
 // Info specifies type, name, and the next error code of the current component.
 // Refer to the corresponding design document for valid types and names, extend if necessary.
 type Info struct {
@@ -40,6 +44,22 @@ func New(dir string) (*Info, error) {
 	return &info, err
 }
 
+// Validate checks that the type of the component is one of the allowed types, DefaultTypes if none are given.
+func (i *Info) Validate(allowed []string) error {
+	if len(allowed) == 0 {
+		allowed = DefaultTypes
+	}
+	if i.Type == "" {
+		return fmt.Errorf("%s has no component type, use one of %v", i.file, allowed)
+	}
+	for _, t := range allowed {
+		if i.Type == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has the invalid component type '%s', use one of %v", i.file, i.Type, allowed)
+}
+
 // GetNextErrorCode returns the next error code (an int) as a string, and increments to the next error code.
 func (i *Info) GetNextErrorCode() string {
 	s := strconv.Itoa(i.NextErrorCode)
@@ -0,0 +1,28 @@
+package component
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(`{"name": "meshkit", "type": "library", "next_error_code": 1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := info.Validate(nil); err != nil {
+		t.Errorf("expected library to be a default type, got %v", err)
+	}
+	if err := info.Validate([]string{"adapter"}); err == nil {
+		t.Error("expected library not to be allowed")
+	}
+	info.Type = ""
+	if err := info.Validate(nil); err == nil {
+		t.Error("expected a missing type to be invalid")
+	}
+}
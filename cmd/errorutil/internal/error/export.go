@@ -1,16 +1,19 @@
 package error
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/layer5io/meshkit/cmd/errorutil/internal/component"
 	"github.com/layer5io/meshkit/cmd/errorutil/internal/config"
 	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 )
 
 // Error is used to export Error for e.g. documentation purposes.
@@ -23,30 +26,134 @@ import (
 // (in this case, the meshery doc) to have to adjust quickly in order to be able to handle updated content.
 // The lifecycles of producers and consumers should not be tightly coupled.
 type Error struct {
-	Name                 string `yaml:"name" json:"name"`                                   // the name of the error code variable, e.g. "ErrInstallMesh", not guaranteed to be unique as it is package scoped
-	Code                 string `yaml:"code" json:"code"`                                   // the code, an int, but exported as string, e.g. "1001", guaranteed to be unique per component-type:component-name
-	Severity             string `yaml:"severity" json:"severity"`                           // a textual representation of the type Severity (errors/types.go), i.e. "none", "alert", etc
-	LongDescription      string `yaml:"long_description" json:"long_description"`           // might contain newlines (JSON encoded)
-	ShortDescription     string `yaml:"short_description" json:"short_description"`         // might contain newlines (JSON encoded)
-	ProbableCause        string `yaml:"probable_cause" json:"probable_cause"`               // might contain newlines (JSON encoded)
-	SuggestedRemediation string `yaml:"suggested_remediation" json:"suggested_remediation"` // might contain newlines (JSON encoded)
+	Name                 string   `yaml:"name" json:"name"`                                   // the name of the error code variable, e.g. "ErrInstallMesh", not guaranteed to be unique as it is package scoped
+	Code                 string   `yaml:"code" json:"code"`                                   // the code, an int, but exported as string, e.g. "1001", guaranteed to be unique per component-type:component-name
+	Severity             string   `yaml:"severity" json:"severity"`                           // a textual representation of the type Severity (errors/types.go), i.e. "none", "alert", etc
+	LongDescription      string   `yaml:"long_description" json:"long_description"`           // might contain newlines (JSON encoded)
+	ShortDescription     string   `yaml:"short_description" json:"short_description"`         // might contain newlines (JSON encoded)
+	ProbableCause        string   `yaml:"probable_cause" json:"probable_cause"`               // might contain newlines (JSON encoded)
+	SuggestedRemediation string   `yaml:"suggested_remediation" json:"suggested_remediation"` // might contain newlines (JSON encoded)
+	Package              string   `yaml:"package,omitempty" json:"package,omitempty"`         // the full import path of the package defining the error
+	Owners               []string `yaml:"owners,omitempty" json:"owners,omitempty"`           // the users and teams owning the error according to CODEOWNERS, to route issues
 }
 
 // externalAll is used to export all Errors including information about the component for e.g. documentation purposes.
 type externalAll struct {
-	ComponentName string           `yaml:"component_name" json:"component_name"` // component type, e.g. "adapter"
-	ComponentType string           `yaml:"component_type" json:"component_type"` // component name, e.g. "kuma"
-	Errors        map[string]Error `yaml:"errors" json:"errors"`                 // map of all errors with key = code
+	ComponentName string           `yaml:"component_name" json:"component_name"`     // component name, e.g. "kuma"
+	ComponentType string           `yaml:"component_type" json:"component_type"`     // component type, e.g. "adapter"
+	Module        string           `yaml:"module,omitempty" json:"module,omitempty"` // the Go module of the errors, set if the export is namespaced per module
+	Errors        map[string]Error `yaml:"errors" json:"errors"`                     // map of all errors with key = code
 }
 
-func Export(componentInfo *component.Info, infoAll *InfoAll, outputDir string) error {
-	fname := filepath.Join(outputDir, config.App+"_errors_export.json")
+// Export formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// ExportOptions configure the export of the errors.
+type ExportOptions struct {
+	// PerModule namespaces the errors per Go module: one export is written for each module,
+	// and a code used in different modules is not a duplicate.
+	PerModule bool
+	// Format is FormatJSON, the default, or FormatYAML, e.g. to commit the export into the _data directory of a Jekyll site.
+	Format string
+}
+
+// ParseFormat validates the export format, an empty format is FormatJSON.
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatYAML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unsupported export format '%s', use '%s' or '%s'", format, FormatJSON, FormatYAML)
+}
+
+// Export exports the errors to the specified output directory.
+func Export(componentInfo *component.Info, infoAll *InfoAll, outputDir string, opts ExportOptions) error {
+	format, err := ParseFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+	opts.Format = format
+	if !opts.PerModule {
+		return writeExport(exportFileName(outputDir, "", opts.Format), buildExport(componentInfo, "", infoAll.LiteralCodes, infoAll), opts.Format)
+	}
+	codesByModule := make(map[string]map[string][]Info)
+	for code, infos := range infoAll.LiteralCodes {
+		for _, info := range infos {
+			if _, ok := codesByModule[info.Module]; !ok {
+				codesByModule[info.Module] = make(map[string][]Info)
+			}
+			codesByModule[info.Module][code] = append(codesByModule[info.Module][code], info)
+		}
+	}
+	for module, codes := range codesByModule {
+		err := writeExport(exportFileName(outputDir, module, opts.Format), buildExport(componentInfo, module, codes, infoAll), opts.Format)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportFileName returns the name of the export of the module, the module is empty if the export isn't namespaced.
+func exportFileName(outputDir, module, format string) string {
+	name := config.App + "_errors_export"
+	if module != "" {
+		name = fmt.Sprintf("%s_%s", name, strings.ReplaceAll(module, "/", "_"))
+	}
+	return filepath.Join(outputDir, name+"."+format)
+}
+
+func writeExport(fname string, export interface{}, format string) error {
+	var out []byte
+	var err error
+	if format == FormatYAML {
+		buf := new(bytes.Buffer)
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		err = enc.Encode(export)
+		out = buf.Bytes()
+	} else {
+		out, err = json.MarshalIndent(export, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("exporting to %s", fname)
+	return os.WriteFile(fname, out, 0600)
+}
+
+// errorDetails returns the details of the error, created using errors.New(...).
+// Error names are package scoped: if several packages use the same name, the details from the package of the code are used.
+func errorDetails(infoAll *InfoAll, errorInfo Info) ([]Error, bool) {
+	details, ok := infoAll.Errors[errorInfo.Name]
+	if !ok || len(details) < 2 || errorInfo.Package == "" {
+		return details, ok
+	}
+	inPackage := make([]Error, 0, 1)
+	for _, d := range details {
+		if d.Package == errorInfo.Package {
+			inPackage = append(inPackage, d)
+		}
+	}
+	if len(inPackage) == 0 {
+		return details, true
+	}
+	return inPackage, true
+}
+
+func buildExport(componentInfo *component.Info, module string, codes map[string][]Info, infoAll *InfoAll) externalAll {
 	export := externalAll{
 		ComponentType: componentInfo.Type,
 		ComponentName: componentInfo.Name,
+		Module:        module,
 		Errors:        make(map[string]Error),
 	}
-	for k, v := range infoAll.LiteralCodes {
+	for k, v := range codes {
 		if len(v) > 1 {
 			log.Errorf("duplicate code '%s' - skipping export", k)
 			continue
@@ -67,21 +174,24 @@ func Export(componentInfo *component.Info, infoAll *InfoAll, outputDir string) e
 			LongDescription:      "",
 			ProbableCause:        "",
 			SuggestedRemediation: "",
+			Package:              errorInfo.Package,
+			Owners:               errorInfo.Owners,
 		}
 		// were details for this error generated using errors.New(...)?
-		if _, ok := infoAll.Errors[errorInfo.Name]; ok {
+		if details, ok := errorDetails(infoAll, errorInfo); ok {
 			log.Infof("error details found for error name '%s' and code '%s'", errorInfo.Name, errorInfo.Code)
 			// no duplicates?
-			if len(infoAll.Errors[errorInfo.Name]) == 1 {
-				details := infoAll.Errors[errorInfo.Name][0]
+			if len(details) == 1 {
 				export.Errors[k] = Error{
-					Name:                 details.Name,
+					Name:                 details[0].Name,
 					Code:                 errorInfo.Code,
-					Severity:             details.Severity,
-					ShortDescription:     details.ShortDescription,
-					LongDescription:      details.LongDescription,
-					ProbableCause:        details.ProbableCause,
-					SuggestedRemediation: details.SuggestedRemediation,
+					Severity:             details[0].Severity,
+					ShortDescription:     details[0].ShortDescription,
+					LongDescription:      details[0].LongDescription,
+					ProbableCause:        details[0].ProbableCause,
+					SuggestedRemediation: details[0].SuggestedRemediation,
+					Package:              errorInfo.Package,
+					Owners:               errorInfo.Owners,
 				}
 			} else {
 				log.Errorf("duplicate error details for error name '%s' and code '%s'", errorInfo.Name, errorInfo.Code)
@@ -90,10 +200,62 @@ func Export(componentInfo *component.Info, infoAll *InfoAll, outputDir string) e
 			log.Warnf("no error details found for error name '%s' and code '%s'", errorInfo.Name, errorInfo.Code)
 		}
 	}
-	jsn, err := json.MarshalIndent(export, "", "  ")
+	return export
+}
+
+// consolidatedExport is the export of the errors of several components, e.g. the repositories of an organization.
+type consolidatedExport struct {
+	Components []externalAll `yaml:"components" json:"components"` // sorted by component name and module
+}
+
+// ConsolidateExports reads the exports written by Export and writes them together to the file, in the format.
+func ConsolidateExports(fnames []string, outFile string, format string) error {
+	consolidated := consolidatedExport{Components: make([]externalAll, 0, len(fnames))}
+	for _, fname := range fnames {
+		export, err := readExport(fname)
+		if err != nil {
+			return err
+		}
+		consolidated.Components = append(consolidated.Components, export)
+	}
+	sort.SliceStable(consolidated.Components, func(i, j int) bool {
+		a, b := consolidated.Components[i], consolidated.Components[j]
+		if a.ComponentName != b.ComponentName {
+			return a.ComponentName < b.ComponentName
+		}
+		return a.Module < b.Module
+	})
+	return writeExport(outFile, consolidated, format)
+}
+
+func readExport(fname string) (externalAll, error) {
+	var export externalAll
+	data, err := os.ReadFile(fname)
 	if err != nil {
-		return err
+		return export, err
 	}
-	log.Infof("exporting to %s", fname)
-	return os.WriteFile(fname, jsn, 0600)
+	if ext := filepath.Ext(fname); ext == ".yaml" || ext == ".yml" {
+		err = yaml.Unmarshal(data, &export)
+	} else {
+		err = json.Unmarshal(data, &export)
+	}
+	if err != nil {
+		return export, fmt.Errorf("invalid export %s: %v", fname, err)
+	}
+	return export, nil
+}
+
+// ReadExport reads the errors of an export written by Export, in YAML format if the file has a .yaml or .yml extension,
+// in JSON format otherwise. The errors are sorted by code, the name of the component is returned too.
+func ReadExport(fname string) (string, []Error, error) {
+	export, err := readExport(fname)
+	if err != nil {
+		return "", nil, err
+	}
+	errs := make([]Error, 0, len(export.Errors))
+	for _, e := range export.Errors {
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Code < errs[j].Code })
+	return export.ComponentName, errs, nil
 }
@@ -0,0 +1,112 @@
+package error
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshkit/cmd/errorutil/internal/component"
+	"gopkg.in/yaml.v3"
+)
+
+func testExportInfo() (*component.Info, *InfoAll) {
+	componentInfo := &component.Info{Name: "meshkit", Type: "library"}
+	infoAll := NewInfoAll()
+	infoAll.AddEntry(Info{Name: "ErrApplyCode", OldCode: "meshkit-1001", Code: "meshkit-1001", CodeIsLiteral: true, Package: "github.com/layer5io/meshkit/utils", Module: "github.com/layer5io/meshkit"})
+	infoAll.AddEntry(Info{Name: "ErrDeleteCode", OldCode: "meshkit-1002", Code: "meshkit-1002", CodeIsLiteral: true, Package: "github.com/layer5io/meshkit/cmd", Module: "github.com/layer5io/meshkit/cmd"})
+	infoAll.AddError(Error{Name: "ErrApplyCode", Severity: "Alert", ShortDescription: "Failed to apply", Package: "github.com/layer5io/meshkit/utils"})
+	return componentInfo, infoAll
+}
+
+func TestExportYAML(t *testing.T) {
+	componentInfo, infoAll := testExportInfo()
+	dir := t.TempDir()
+	if err := Export(componentInfo, infoAll, dir, ExportOptions{Format: FormatYAML}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "errorutil_errors_export.json")); !os.IsNotExist(err) {
+		t.Errorf("expected no JSON export, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "errorutil_errors_export.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export externalAll
+	if err := yaml.Unmarshal(data, &export); err != nil {
+		t.Fatalf("export is not YAML: %v", err)
+	}
+	if export.ComponentName != "meshkit" || len(export.Errors) != 2 {
+		t.Fatalf("unexpected export %+v", export)
+	}
+	if e := export.Errors["meshkit-1001"]; e.Name != "ErrApplyCode" || e.Severity != "Alert" || e.ShortDescription != "Failed to apply" {
+		t.Errorf("unexpected error %+v", e)
+	}
+}
+
+func TestExportYAMLPerModule(t *testing.T) {
+	componentInfo, infoAll := testExportInfo()
+	dir := t.TempDir()
+	if err := Export(componentInfo, infoAll, dir, ExportOptions{PerModule: true, Format: FormatYAML}); err != nil {
+		t.Fatal(err)
+	}
+	for _, module := range []string{"github.com_layer5io_meshkit", "github.com_layer5io_meshkit_cmd"} {
+		data, err := os.ReadFile(filepath.Join(dir, "errorutil_errors_export_"+module+".yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var export externalAll
+		if err := yaml.Unmarshal(data, &export); err != nil {
+			t.Fatalf("export is not YAML: %v", err)
+		}
+		if len(export.Errors) != 1 {
+			t.Errorf("%s: expected 1 error, got %+v", module, export.Errors)
+		}
+	}
+}
+
+func TestExportDefaultsToJSON(t *testing.T) {
+	componentInfo, infoAll := testExportInfo()
+	dir := t.TempDir()
+	if err := Export(componentInfo, infoAll, dir, ExportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "errorutil_errors_export.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export externalAll
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+	if len(export.Errors) != 2 {
+		t.Errorf("expected 2 errors, got %+v", export.Errors)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{format: "", want: FormatJSON},
+		{format: FormatJSON, want: FormatJSON},
+		{format: FormatYAML, want: FormatYAML},
+		{format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q): unexpected error %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	componentInfo, infoAll := testExportInfo()
+	if err := Export(componentInfo, infoAll, t.TempDir(), ExportOptions{Format: "xml"}); err == nil {
+		t.Error("expected Export to reject an unsupported format")
+	}
+}
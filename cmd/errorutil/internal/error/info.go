@@ -1,12 +1,15 @@
 package error
 
 type Info struct {
-	Name          string `yaml:"name" json:"name"`
-	OldCode       string `yaml:"old_code" json:"old_code"`
-	Code          string `yaml:"code" json:"code"`
-	CodeIsLiteral bool   `yaml:"code_is_literal" json:"code_is_literal"`
-	CodeIsInt     bool   `yaml:"code_is_int" json:"code_is_int"`
-	Path          string `yaml:"path" json:"path"`
+	Name          string   `yaml:"name" json:"name"`
+	OldCode       string   `yaml:"old_code" json:"old_code"`
+	Code          string   `yaml:"code" json:"code"`
+	CodeIsLiteral bool     `yaml:"code_is_literal" json:"code_is_literal"`
+	CodeIsInt     bool     `yaml:"code_is_int" json:"code_is_int"`
+	Path          string   `yaml:"path" json:"path"`
+	Package       string   `yaml:"package" json:"package"`                   // the full import path of the package, empty if the file is in no Go module
+	Module        string   `yaml:"module" json:"module"`                     // the path of the Go module of the package
+	Owners        []string `yaml:"owners,omitempty" json:"owners,omitempty"` // the owners of the file according to CODEOWNERS, e.g. "@meshery/maintainers"
 }
 
 type InfoAll struct {
@@ -15,6 +18,15 @@ type InfoAll struct {
 	CallExprCodes        []Info             `yaml:"call_expr_codes" json:"call_expr_codes"`                // entries with call expressions
 	DeprecatedNewDefault []string           `yaml:"deprecated_new_default" json:"deprecated_new_default" ` // list of files with usage of deprecated NewDefault func
 	Errors               map[string][]Error `yaml:"errors_raw" json:"errors_raw"`                          // map of detected errors created using errors.New(...). The key is the error name, more than 1 entry in the list is a duplication error.
+
+	// Stream, if set, receives the entries, errors and files as they are added, which are then only kept as needed
+	// for the export: the entries with literal codes and the details of the errors.
+	Stream *Stream `yaml:"-" json:"-"`
+	// Summary, if set, is computed as the entries, errors and files are added.
+	Summary *Summary                   `yaml:"-" json:"-"`
+	Owners  func(path string) []string `yaml:"-" json:"-"` // if set, returns the owners of the files of the entries
+
+	deprecatedNewDefault map[string]bool
 }
 
 func NewInfoAll() *InfoAll {
@@ -23,5 +35,57 @@ func NewInfoAll() *InfoAll {
 		LiteralCodes:         make(map[string][]Info),
 		CallExprCodes:        []Info{},
 		DeprecatedNewDefault: []string{},
-		Errors:               map[string][]Error{}}
+		Errors:               map[string][]Error{},
+		deprecatedNewDefault: make(map[string]bool)}
+}
+
+// AddEntry adds the entry of an error code variable.
+func (i *InfoAll) AddEntry(entry Info) {
+	if i.Owners != nil {
+		entry.Owners = i.Owners(entry.Path)
+	}
+	if entry.CodeIsLiteral {
+		key := entry.OldCode
+		if key == "" {
+			key = "no_code"
+		}
+		i.LiteralCodes[key] = append(i.LiteralCodes[key], entry)
+	}
+	i.add(Record{Kind: RecordEntry, Entry: &entry})
+	if i.Stream != nil {
+		return
+	}
+	i.Entries = append(i.Entries, entry)
+	if !entry.CodeIsLiteral {
+		i.CallExprCodes = append(i.CallExprCodes, entry)
+	}
+}
+
+// AddError adds the details of an error created using errors.New(...) or errors.NewBuilder(...).
+func (i *InfoAll) AddError(e Error) {
+	i.Errors[e.Name] = append(i.Errors[e.Name], e)
+	i.add(Record{Kind: RecordError, Error: &e})
+}
+
+// AddDeprecatedNewDefault adds a file using the deprecated NewDefault func, once.
+func (i *InfoAll) AddDeprecatedNewDefault(path string) {
+	if i.deprecatedNewDefault == nil {
+		i.deprecatedNewDefault = make(map[string]bool)
+	}
+	if i.deprecatedNewDefault[path] {
+		return
+	}
+	i.deprecatedNewDefault[path] = true
+	i.add(Record{Kind: RecordDeprecatedNewDefault, Path: path})
+	if i.Stream == nil {
+		i.DeprecatedNewDefault = append(i.DeprecatedNewDefault, path)
+	}
+}
+
+// add writes the record to the stream and the summary, if set.
+func (i *InfoAll) add(r Record) {
+	i.Stream.write(r)
+	if i.Summary != nil {
+		i.Summary.Add(r)
+	}
 }
@@ -0,0 +1,83 @@
+package error
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// predicateName returns the name of the predicate of an error code variable, e.g. IsErrApplyManifest for ErrApplyManifestCode.
+func predicateName(name string) string {
+	return "Is" + strings.TrimSuffix(name, "Code")
+}
+
+// GeneratePredicates returns the source of a Go file of the package pkgName with a predicate function for each error,
+// e.g. IsErrApplyManifest(err error) bool, reporting whether an error has the code of the error, see errors.HasCode.
+// The codes are prefixed with the name of the component, as they are set by the update command, e.g. meshkit-11000.
+// Errors without a name or code are skipped. If several packages define errors of the same name, the predicates
+// are prefixed with the name of their package, e.g. IsBrokerErrInvalidSubject.
+func GeneratePredicates(componentName string, errs []Error, pkgName string) ([]byte, error) {
+	if !token.IsIdentifier(pkgName) {
+		return nil, fmt.Errorf("invalid package name '%s'", pkgName)
+	}
+	counts := make(map[string]int)
+	for _, e := range errs {
+		counts[e.Name]++
+	}
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Code generated by errorutil predicates; DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	fmt.Fprintf(buf, "import \"github.com/layer5io/meshkit/errors\"\n")
+	seen := make(map[string]bool)
+	for _, e := range errs {
+		if e.Name == "" || e.Code == "" || !token.IsIdentifier(e.Name) {
+			continue
+		}
+		name := predicateName(e.Name)
+		if counts[e.Name] > 1 && e.Package != "" {
+			name = "Is" + exportedIdentifier(path.Base(e.Package)) + strings.TrimPrefix(name, "Is")
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		description := e.ShortDescription
+		if i := strings.IndexByte(description, '\n'); i >= 0 {
+			description = description[:i]
+		}
+		code := e.Code
+		if componentName != "" && !strings.HasPrefix(code, componentName+"-") {
+			code = componentName + "-" + code
+		}
+		fmt.Fprintf(buf, "\n// %s reports whether err is the MeshKit error %s", name, code)
+		if e.Package != "" {
+			fmt.Fprintf(buf, " of %s", e.Package)
+		}
+		if description != "" {
+			fmt.Fprintf(buf, ": %s", strings.TrimSuffix(description, "."))
+		}
+		fmt.Fprintf(buf, ".\nfunc %s(err error) bool {\n\treturn errors.HasCode(err, %q)\n}\n", name, code)
+	}
+	return format.Source(buf.Bytes())
+}
+
+// exportedIdentifier returns the name of a package as exported identifier, e.g. Kubernetes for kubernetes and ModelsV1 for models-v1.
+func exportedIdentifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package error
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePredicates(t *testing.T) {
+	errs := []Error{
+		{Name: "ErrConnectCode", Code: "11000", Package: "github.com/layer5io/meshkit/broker/nats", ShortDescription: "Connection to broker failed."},
+		{Name: "ErrApplyCode", Code: "11001", Package: "github.com/layer5io/meshkit/models-v1"},
+		{Name: "ErrApplyCode", Code: "11002", Package: "github.com/layer5io/meshkit/utils/kubernetes"},
+		{Name: "", Code: "11003"},
+	}
+	src, err := GeneratePredicates("meshkit", errs, "meshkiterrors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"package meshkiterrors",
+		"// IsErrConnect reports whether err is the MeshKit error meshkit-11000 of github.com/layer5io/meshkit/broker/nats: Connection to broker failed.\n",
+		"func IsErrConnect(err error) bool {\n\treturn errors.HasCode(err, \"meshkit-11000\")\n}",
+		"func IsModelsV1ErrApply(err error) bool {\n\treturn errors.HasCode(err, \"meshkit-11001\")\n}",
+		"func IsKubernetesErrApply(err error) bool {",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in\n%s", expected, src)
+		}
+	}
+	if strings.Contains(string(src), "11003") {
+		t.Errorf("expected errors without name to be skipped in\n%s", src)
+	}
+
+	if _, err := GeneratePredicates("meshkit", errs, "meshkit-errors"); err == nil {
+		t.Error("expected an error for an invalid package name")
+	}
+}
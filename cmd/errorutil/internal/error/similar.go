@@ -0,0 +1,146 @@
+package error
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultSimilarityThreshold is the similarity above which the descriptions of errors are reported as duplicates, unless configured otherwise.
+const DefaultSimilarityThreshold = 0.9
+
+// DuplicateDescription is a description shared by errors of different codes, e.g. because it was copied and not adapted.
+type DuplicateDescription struct {
+	Field      string   `yaml:"field" json:"field"`           // short_description or long_description
+	Names      []string `yaml:"names" json:"names"`           // the names of the two errors
+	Codes      []string `yaml:"codes" json:"codes"`           // the codes of the two errors
+	Similarity float64  `yaml:"similarity" json:"similarity"` // between 0 and 1, 1 if the normalized descriptions are identical
+}
+
+var (
+	templateActionPattern = regexp.MustCompile(`\{\{[^}]*\}\}`)
+	nonWordPattern        = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// normalizeDescription returns the words of the description, in lower case, without punctuation and template actions.
+func normalizeDescription(description string) []string {
+	description = templateActionPattern.ReplaceAllString(description, " ")
+	return strings.Fields(nonWordPattern.ReplaceAllString(strings.ToLower(description), " "))
+}
+
+// descriptionSimilarity returns the similarity of the words of two descriptions, 1 minus their edit distance
+// relative to the number of words of the longest description.
+func descriptionSimilarity(a, b []string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(b)])/float64(longest)
+}
+
+type describedError struct {
+	name, pkg, code string
+	fields          map[string][]string
+}
+
+func newDescribedError(d Error) describedError {
+	return describedError{
+		name: d.Name,
+		pkg:  d.Package,
+		fields: map[string][]string{
+			"short_description": normalizeDescription(d.ShortDescription),
+			"long_description":  normalizeDescription(d.LongDescription),
+		},
+	}
+}
+
+// FindDuplicateDescriptions returns the pairs of errors with different codes whose short or long descriptions
+// have a similarity of at least the threshold once normalized. Empty descriptions, e.g. set by call expressions, are ignored.
+// A threshold of 0 or less disables the detection.
+func FindDuplicateDescriptions(infoAll *InfoAll, threshold float64) []DuplicateDescription {
+	if threshold <= 0 {
+		return []DuplicateDescription{}
+	}
+	codes := make(map[string]string)
+	for _, entry := range infoAll.Entries {
+		codes[entry.Package+"."+entry.Name] = entry.Code
+		if _, ok := codes[entry.Name]; !ok {
+			codes[entry.Name] = entry.Code
+		}
+	}
+	var described []describedError
+	for name, details := range infoAll.Errors {
+		for _, d := range details {
+			d.Name = name
+			described = append(described, newDescribedError(d))
+		}
+	}
+	return findDuplicateDescriptions(codes, described, threshold)
+}
+
+// findDuplicateDescriptions compares the described errors, whose codes are looked up by package qualified name first.
+func findDuplicateDescriptions(codes map[string]string, described []describedError, threshold float64) []DuplicateDescription {
+	duplicates := []DuplicateDescription{}
+	if threshold <= 0 {
+		return duplicates
+	}
+	for i, d := range described {
+		code, ok := codes[d.pkg+"."+d.name]
+		if !ok {
+			code = codes[d.name]
+		}
+		described[i].code = code
+	}
+	sort.Slice(described, func(i, j int) bool {
+		if described[i].code != described[j].code {
+			return described[i].code < described[j].code
+		}
+		return described[i].name < described[j].name
+	})
+
+	for i, a := range described {
+		for _, b := range described[i+1:] {
+			if a.code == b.code {
+				continue
+			}
+			for _, field := range []string{"short_description", "long_description"} {
+				wordsA, wordsB := a.fields[field], b.fields[field]
+				if len(wordsA) == 0 || len(wordsB) == 0 {
+					continue
+				}
+				// the similarity can't exceed the ratio of the numbers of words
+				if float64(min(len(wordsA), len(wordsB)))/float64(max(len(wordsA), len(wordsB))) < threshold {
+					continue
+				}
+				if similarity := descriptionSimilarity(wordsA, wordsB); similarity >= threshold {
+					duplicates = append(duplicates, DuplicateDescription{
+						Field:      field,
+						Names:      []string{a.name, b.name},
+						Codes:      []string{a.code, b.code},
+						Similarity: similarity,
+					})
+				}
+			}
+		}
+	}
+	return duplicates
+}
@@ -0,0 +1,58 @@
+package error
+
+import "testing"
+
+func TestFindDuplicateDescriptions(t *testing.T) {
+	infoAll := NewInfoAll()
+	infoAll.Entries = []Info{
+		{Name: "ErrApplyManifestCode", Code: "1001", Package: "example.com/a"},
+		{Name: "ErrDeleteManifestCode", Code: "1002", Package: "example.com/a"},
+		{Name: "ErrApplyHelmChartCode", Code: "1003", Package: "example.com/b"},
+		{Name: "ErrConnectCode", Code: "1004", Package: "example.com/b"},
+	}
+	infoAll.Errors = map[string][]Error{
+		"ErrApplyManifestCode": {{
+			Name:             "ErrApplyManifestCode",
+			Package:          "example.com/a",
+			ShortDescription: "Failed to apply the manifest.",
+			LongDescription:  "The manifest {{.Name}} could not be applied to the cluster",
+		}},
+		"ErrDeleteManifestCode": {{
+			Name:             "ErrDeleteManifestCode",
+			Package:          "example.com/a",
+			ShortDescription: "Failed to delete the manifest",
+			LongDescription:  "The manifest {{.Manifest}} could not be applied to the cluster.",
+		}},
+		"ErrApplyHelmChartCode": {{
+			Name:             "ErrApplyHelmChartCode",
+			Package:          "example.com/b",
+			ShortDescription: "failed to APPLY the manifest",
+			LongDescription:  "The Helm chart could not be rendered",
+		}},
+		"ErrConnectCode": {{
+			Name:    "ErrConnectCode",
+			Package: "example.com/b",
+		}},
+	}
+
+	duplicates := FindDuplicateDescriptions(infoAll, 1)
+	if len(duplicates) != 2 {
+		t.Fatalf("expected 2 identical descriptions, got %+v", duplicates)
+	}
+	if d := duplicates[1]; d.Field != "short_description" || d.Codes[0] != "1001" || d.Codes[1] != "1003" || d.Similarity != 1 {
+		t.Errorf("unexpected duplicate %+v", d)
+	}
+	if d := duplicates[0]; d.Field != "long_description" || d.Names[0] != "ErrApplyManifestCode" || d.Names[1] != "ErrDeleteManifestCode" {
+		t.Errorf("unexpected duplicate %+v", d)
+	}
+
+	// "delete" instead of "apply" is one word out of five
+	duplicates = FindDuplicateDescriptions(infoAll, 0.8)
+	if len(duplicates) != 4 {
+		t.Fatalf("expected 4 similar descriptions, got %+v", duplicates)
+	}
+
+	if duplicates := FindDuplicateDescriptions(infoAll, 0); len(duplicates) != 0 {
+		t.Errorf("expected no duplicates if disabled, got %+v", duplicates)
+	}
+}
@@ -0,0 +1,46 @@
+package error
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Kinds of records
+const (
+	RecordEntry                = "entry"
+	RecordError                = "error"
+	RecordDeprecatedNewDefault = "deprecated_new_default"
+)
+
+// Record is a line of the JSON Lines output of the analysis, an entry, the details of an error, or a file using NewDefault.
+type Record struct {
+	Kind  string `yaml:"kind" json:"kind"`                       // one of RecordEntry, RecordError or RecordDeprecatedNewDefault
+	Entry *Info  `yaml:"entry,omitempty" json:"entry,omitempty"` // set if the kind is RecordEntry
+	Error *Error `yaml:"error,omitempty" json:"error,omitempty"` // set if the kind is RecordError
+	Path  string `yaml:"path,omitempty" json:"path,omitempty"`   // set if the kind is RecordDeprecatedNewDefault
+}
+
+// Stream writes the records of the analysis as JSON Lines, see https://jsonlines.org, as they are found,
+// instead of marshaling all of them at once once the tree is walked.
+type Stream struct {
+	enc *json.Encoder
+	err error
+}
+
+// NewStream returns a stream writing to w.
+func NewStream(w io.Writer) *Stream {
+	return &Stream{enc: json.NewEncoder(w)}
+}
+
+// write writes the record, unless a previous write failed. Writing to a nil stream does nothing.
+func (s *Stream) write(r Record) {
+	if s == nil || s.err != nil {
+		return
+	}
+	s.err = s.enc.Encode(r)
+}
+
+// Err returns the first error writing to the stream.
+func (s *Stream) Err() error {
+	return s.err
+}
@@ -0,0 +1,105 @@
+package error
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshkit/cmd/errorutil/internal/component"
+)
+
+func TestStream(t *testing.T) {
+	buf := new(bytes.Buffer)
+	infoAll := NewInfoAll()
+	infoAll.Stream = NewStream(buf)
+	infoAll.Owners = func(path string) []string { return []string{"@owner-of-" + path} }
+
+	infoAll.AddEntry(Info{Name: "ErrApplyCode", OldCode: "1001", Code: "1001", CodeIsLiteral: true, CodeIsInt: true, Path: "a/error.go"})
+	infoAll.AddEntry(Info{Name: "ErrDeleteCode", Path: "b/error.go"})
+	infoAll.AddError(Error{Name: "ErrApplyCode", ShortDescription: "Failed to apply"})
+	infoAll.AddDeprecatedNewDefault("c/error.go")
+	infoAll.AddDeprecatedNewDefault("c/error.go")
+	if err := infoAll.Stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []Record
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %+v", records)
+	}
+	if r := records[0]; r.Kind != RecordEntry || r.Entry.Name != "ErrApplyCode" || len(r.Entry.Owners) != 1 || r.Entry.Owners[0] != "@owner-of-a/error.go" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[2]; r.Kind != RecordError || r.Error.ShortDescription != "Failed to apply" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r := records[3]; r.Kind != RecordDeprecatedNewDefault || r.Path != "c/error.go" {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	// only the records needed for the export are kept
+	if len(infoAll.LiteralCodes["1001"]) != 1 || len(infoAll.Errors["ErrApplyCode"]) != 1 {
+		t.Errorf("unexpected analysis %+v", infoAll)
+	}
+	if len(infoAll.Entries) != 0 || len(infoAll.CallExprCodes) != 0 || len(infoAll.DeprecatedNewDefault) != 0 {
+		t.Errorf("expected the records not to be kept, got %+v", infoAll)
+	}
+}
+
+func TestStreamSummary(t *testing.T) {
+	componentInfo := &component.Info{Name: "meshkit", Type: "library", NextErrorCode: 1003}
+	streamed := NewInfoAll()
+	streamed.Stream = NewStream(new(bytes.Buffer))
+	streamed.Summary = NewSummary(componentInfo, SummaryOptions{SimilarityThreshold: 1})
+	kept := NewInfoAll()
+	for _, infoAll := range []*InfoAll{streamed, kept} {
+		infoAll.AddEntry(Info{Name: "ErrApplyCode", OldCode: "1001", Code: "1001", CodeIsLiteral: true, CodeIsInt: true})
+		infoAll.AddEntry(Info{Name: "ErrRetryCode", OldCode: "1001", Code: "1001", CodeIsLiteral: true, CodeIsInt: true})
+		infoAll.AddEntry(Info{Name: "ErrDeleteCode", OldCode: "1002", Code: "1002", CodeIsLiteral: true, CodeIsInt: true})
+		infoAll.AddEntry(Info{Name: "ErrConnectCode"})
+		infoAll.AddError(Error{Name: "ErrApplyCode", ShortDescription: "Failed to apply"})
+		infoAll.AddError(Error{Name: "ErrDeleteCode", ShortDescription: "Failed to apply"})
+		infoAll.AddError(Error{Name: "ErrDeleteCode", ShortDescription: "Failed to delete"})
+		infoAll.AddDeprecatedNewDefault("c/error.go")
+	}
+
+	// the summary computed while streaming is the one of the analysis kept in memory
+	streamedDir, keptDir := t.TempDir(), t.TempDir()
+	if err := streamed.Summary.Write(streamedDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := SummarizeAnalysis(componentInfo, kept, keptDir, SummaryOptions{SimilarityThreshold: 1}); err != nil {
+		t.Fatal(err)
+	}
+	var summaries []analysisSummary
+	for _, dir := range []string{streamedDir, keptDir} {
+		data, err := os.ReadFile(filepath.Join(dir, "errorutil_analyze_summary.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var summary analysisSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			t.Fatal(err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if !reflect.DeepEqual(summaries[0], summaries[1]) {
+		t.Errorf("streamed summary %+v, want %+v", summaries[0], summaries[1])
+	}
+	got := summaries[0]
+	if len(got.DuplicateCodes["1001"]) != 2 || len(got.DuplicateNames) != 1 || len(got.CallExprCodes) != 1 || got.MaxCode != 1002 || len(got.DuplicateDescriptions) != 1 || len(got.DeprecatedNewDefault) != 1 {
+		t.Errorf("unexpected summary %+v", got)
+	}
+}
@@ -14,6 +14,8 @@ import (
 )
 
 type analysisSummary struct {
+	ComponentName        string              `yaml:"component_name" json:"component_name"`                  // component name, e.g. "kuma"
+	ComponentType        string              `yaml:"component_type" json:"component_type"`                  // component type, e.g. "adapter"
 	MinCode              int                 `yaml:"min_code" json:"min_code"`                              // the smallest error code (an int)
 	MaxCode              int                 `yaml:"max_code" json:"max_code"`                              // the biggest error code (an int)
 	NextCode             int                 `yaml:"next_code" json:"next_code"`                            // the next error code to use, taken from ComponentInfo
@@ -22,43 +24,110 @@ type analysisSummary struct {
 	CallExprCodes        []string            `yaml:"call_expr_codes" json:"call_expr_codes"`                // codes set by call expressions instead of literals
 	IntCodes             []int               `yaml:"int_codes" json:"int_codes"`                            // all error codes as integers
 	DeprecatedNewDefault []string            `yaml:"deprecated_new_default" json:"deprecated_new_default" ` // list of files with usage of deprecated NewDefault func
+	// errors of different codes with identical or near-identical descriptions
+	DuplicateDescriptions []DuplicateDescription `yaml:"duplicate_descriptions" json:"duplicate_descriptions"`
 }
 
-// SummarizeAnalysis summarizes the analysis and writes it to the specified output directory.
-func SummarizeAnalysis(componentInfo *component.Info, infoAll *InfoAll, outputDir string) error {
+// SummaryOptions configure the summary of the analysis.
+type SummaryOptions struct {
+	// SimilarityThreshold is the similarity, between 0 and 1, from which the descriptions of errors with different codes
+	// are reported as duplicates. The detection is disabled if it is 0.
+	SimilarityThreshold float64
+}
+
+// Summary computes the summary of the analysis incrementally, as the records are added, so that the entries and the
+// errors don't have to be kept: only the codes and names of the errors are, and their normalized descriptions if
+// the duplicate descriptions are detected.
+type Summary struct {
+	summary   *analysisSummary
+	threshold float64
+	// names of the entries with literal codes, by code
+	literalCodes map[string][]string
+	// number of errors created with each name
+	errorNames map[string]int
+	deprecated map[string]bool
+	// codes by package qualified and by plain name, see FindDuplicateDescriptions
+	codes     map[string]string
+	described []describedError
+}
+
+// NewSummary returns an empty summary of the analysis of the component.
+func NewSummary(componentInfo *component.Info, opts SummaryOptions) *Summary {
 	maxInt := int(^uint(0) >> 1)
-	summary := &analysisSummary{
-		MinCode:              maxInt,
-		MaxCode:              -maxInt - 1,
-		NextCode:             componentInfo.NextErrorCode,
-		DuplicateCodes:       make(map[string][]string),
-		DuplicateNames:       []string{},
-		CallExprCodes:        []string{},
-		IntCodes:             []int{},
-		DeprecatedNewDefault: []string{}}
-	for k, v := range infoAll.LiteralCodes {
-		if len(v) > 1 {
-			_, ok := summary.DuplicateCodes[k]
-			if !ok {
-				summary.DuplicateCodes[k] = []string{}
-			}
+	return &Summary{
+		summary: &analysisSummary{
+			ComponentName:        componentInfo.Name,
+			ComponentType:        componentInfo.Type,
+			MinCode:              maxInt,
+			MaxCode:              -maxInt - 1,
+			NextCode:             componentInfo.NextErrorCode,
+			DuplicateCodes:       make(map[string][]string),
+			DuplicateNames:       []string{},
+			CallExprCodes:        []string{},
+			IntCodes:             []int{},
+			DeprecatedNewDefault: []string{}},
+		threshold:    opts.SimilarityThreshold,
+		literalCodes: make(map[string][]string),
+		errorNames:   make(map[string]int),
+		deprecated:   make(map[string]bool),
+		codes:        make(map[string]string),
+	}
+}
+
+// Add adds the record to the summary.
+func (s *Summary) Add(r Record) {
+	switch r.Kind {
+	case RecordEntry:
+		s.addEntry(*r.Entry)
+	case RecordError:
+		s.errorNames[r.Error.Name]++
+		if s.threshold > 0 {
+			s.described = append(s.described, newDescribedError(*r.Error))
 		}
-		for _, e := range v {
-			if e.CodeIsInt {
-				i, _ := strconv.Atoi(e.Code)
-				if i < summary.MinCode {
-					summary.MinCode = i
-				}
-				if i > summary.MaxCode {
-					summary.MaxCode = i
-				}
-				if !contains(summary.IntCodes, i) {
-					summary.IntCodes = append(summary.IntCodes, i)
-				}
-			}
-			if _, ok := summary.DuplicateCodes[k]; ok {
-				summary.DuplicateCodes[k] = append(summary.DuplicateCodes[k], e.Name)
-				log.Errorf("duplicate error code '%s', name: '%s'", k, e.Name)
+	case RecordDeprecatedNewDefault:
+		if !s.deprecated[r.Path] {
+			s.deprecated[r.Path] = true
+			s.summary.DeprecatedNewDefault = append(s.summary.DeprecatedNewDefault, r.Path)
+		}
+	}
+}
+
+func (s *Summary) addEntry(e Info) {
+	s.codes[e.Package+"."+e.Name] = e.Code
+	if _, ok := s.codes[e.Name]; !ok {
+		s.codes[e.Name] = e.Code
+	}
+	if !e.CodeIsLiteral {
+		s.summary.CallExprCodes = append(s.summary.CallExprCodes, e.Name)
+		return
+	}
+	key := e.OldCode
+	if key == "" {
+		key = "no_code"
+	}
+	s.literalCodes[key] = append(s.literalCodes[key], e.Name)
+	if e.CodeIsInt {
+		i, _ := strconv.Atoi(e.Code)
+		if i < s.summary.MinCode {
+			s.summary.MinCode = i
+		}
+		if i > s.summary.MaxCode {
+			s.summary.MaxCode = i
+		}
+		if !contains(s.summary.IntCodes, i) {
+			s.summary.IntCodes = append(s.summary.IntCodes, i)
+		}
+	}
+}
+
+// Write completes the summary and writes it to the specified output directory.
+func (s *Summary) Write(outputDir string) error {
+	summary := s.summary
+	for k, names := range s.literalCodes {
+		if len(names) > 1 {
+			summary.DuplicateCodes[k] = names
+			for _, name := range names {
+				log.Errorf("duplicate error code '%s', name: '%s'", k, name)
 			}
 		}
 	}
@@ -66,19 +135,20 @@ func SummarizeAnalysis(componentInfo *component.Info, infoAll *InfoAll, outputDi
 		log.Errorf("component_info.next_error_code '%v' is lower than or equal to highest used code '%v'", summary.NextCode, summary.MaxCode)
 	}
 	sort.Ints(summary.IntCodes)
-	for k, v := range infoAll.Errors {
-		if len(v) > 1 {
+	summary.DuplicateNames = []string{}
+	for k, count := range s.errorNames {
+		if count > 1 {
 			summary.DuplicateNames = append(summary.DuplicateNames, k)
 			log.Errorf("duplicate error code name '%s'", k)
 		}
 	}
 	sort.Strings(summary.DuplicateNames)
-	for _, v := range infoAll.CallExprCodes {
-		summary.CallExprCodes = append(summary.CallExprCodes, v.Name)
-	}
 	sort.Strings(summary.CallExprCodes)
-	summary.DeprecatedNewDefault = infoAll.DeprecatedNewDefault
 	sort.Strings(summary.DeprecatedNewDefault)
+	summary.DuplicateDescriptions = findDuplicateDescriptions(s.codes, s.described, s.threshold)
+	for _, d := range summary.DuplicateDescriptions {
+		log.Warnf("similar %s of error codes '%s' and '%s', names: '%s', '%s' (similarity %.2f)", d.Field, d.Codes[0], d.Codes[1], d.Names[0], d.Names[1], d.Similarity)
+	}
 	jsn, err := json.MarshalIndent(summary, "", "  ")
 	if err != nil {
 		return err
@@ -88,6 +158,23 @@ func SummarizeAnalysis(componentInfo *component.Info, infoAll *InfoAll, outputDi
 	return os.WriteFile(fname, jsn, 0600)
 }
 
+// SummarizeAnalysis summarizes the analysis and writes it to the specified output directory.
+func SummarizeAnalysis(componentInfo *component.Info, infoAll *InfoAll, outputDir string, opts SummaryOptions) error {
+	s := NewSummary(componentInfo, opts)
+	for i := range infoAll.Entries {
+		s.Add(Record{Kind: RecordEntry, Entry: &infoAll.Entries[i]})
+	}
+	for _, details := range infoAll.Errors {
+		for i := range details {
+			s.Add(Record{Kind: RecordError, Error: &details[i]})
+		}
+	}
+	for _, path := range infoAll.DeprecatedNewDefault {
+		s.Add(Record{Kind: RecordDeprecatedNewDefault, Path: path})
+	}
+	return s.Write(outputDir)
+}
+
 func contains(s []int, str int) bool {
 	for _, v := range s {
 		if v == str {
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/layer5io/meshkit/generators"
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils/cas"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// meshkit generates components locally, the way Meshery generates them when registering models, and validates them,
+// e.g. meshkit generate components --source https://github.com/cert-manager/cert-manager --model cert-manager --out ./components
+// or meshkit validate -o report.json ./components
+func main() {
+	log.SetOutput(os.Stdout)
+	log.SetLevel(log.InfoLevel)
+	log.SetFormatter(&log.TextFormatter{})
+
+	if err := rootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func rootCommand() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "meshkit",
+		Short: "MeshKit utilities for integration authors",
+	}
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate MeshModel entities",
+	}
+	generateCmd.AddCommand(componentsCommand())
+	rootCmd.AddCommand(generateCmd, validateCommand())
+	return rootCmd
+}
+
+func componentsCommand() *cobra.Command {
+	var opts generators.GenerateOptions
+	var out, report, mirrors, store string
+	var validate, icons bool
+	cmd := &cobra.Command{
+		Use:   "components",
+		Short: "Generate components from a repository, Helm chart, Artifact Hub package or CRDs",
+		Long: `Generate components from a repository, Helm chart, Artifact Hub package or CRDs, and write them to the output directory.
+The source is a git://, release:// or http(s) URL, or a path to a file or directory of CRDs.
+Packages of Artifact Hub are given by --registrant artifacthub --package <name>.
+Unless --validate=false, the written components are validated and the command fails if any is invalid.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Source == "" && opts.PackageName == "" {
+				return fmt.Errorf("either --source or --package is required")
+			}
+			if mirrors != "" {
+				config, err := models.LoadMirrorConfig(mirrors)
+				if err != nil {
+					return err
+				}
+				opts.Mirrors = config
+			}
+			if store != "" {
+				s, err := cas.New(store, cas.Options{})
+				if err != nil {
+					return err
+				}
+				opts.Store = s
+			}
+			if icons {
+				// the icons are fetched through the mirrors, if any
+				opts.Icons = &models.IconProcessor{}
+			}
+			components, err := generators.GenerateComponents(opts)
+			if err != nil {
+				if len(components) == 0 {
+					return err
+				}
+				log.Warnf("some components could not be generated: %v", err)
+			}
+			if err := generators.WriteComponents(components, out); err != nil {
+				return err
+			}
+			log.Infof("generated %d components in %s", len(components), out)
+			if !validate {
+				return nil
+			}
+			return validateComponents(out, report)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Source, "source", "", "URL, chart or directory to generate the components from")
+	cmd.Flags().StringVar(&opts.Registrant, "registrant", "", "registrant of the package, artifacthub or github (default: github for URLs, CRDs for local sources)")
+	cmd.Flags().StringVar(&opts.PackageName, "package", "", "name of the package")
+	cmd.Flags().StringVar(&opts.Model, "model", "", "name of the model of the components")
+	cmd.Flags().StringVar(&opts.ModelVersion, "model-version", "", "version of the model of the components")
+	cmd.Flags().StringVar(&opts.Category, "category", "", "category of the model of the components")
+	cmd.Flags().StringVar(&out, "out", ".", "directory to write the components to")
+	cmd.Flags().BoolVar(&validate, "validate", true, "validate the generated components")
+	cmd.Flags().BoolVar(&icons, "icons", false, "fetch, sanitize and complete the icons of the components, through the mirrors if any")
+	cmd.Flags().StringVar(&report, "report", "", "write the validation report to this file")
+	cmd.Flags().StringVar(&mirrors, "mirrors", "", "mirror configuration to fetch the sources and the icons from, see models.MirrorConfig")
+	cmd.Flags().StringVar(&store, "store", "", "directory of the content-addressed store caching the downloads across runs, see cas.Store")
+	return cmd
+}
+
+// validateCommand validates the components generated beforehand, e.g. to gate the CI of the integrations.
+func validateCommand() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "validate <dir>",
+		Short: "Validate the generated components of a directory",
+		Long: `Validate the generated components under the directory and print the JSON validation report.
+The command fails if any component is invalid.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := generators.ValidateGenerated(args[0])
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				_, err = cmd.OutOrStdout().Write(append(data, '\n'))
+			} else {
+				err = os.WriteFile(output, data, 0644)
+			}
+			if err != nil {
+				return err
+			}
+			if report.HasErrors() {
+				return fmt.Errorf("%d components are invalid", report.Invalid)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the report to this file instead of stdout")
+	return cmd
+}
+
+func validateComponents(dir, reportPath string) error {
+	report, err := generators.ValidateGenerated(dir)
+	if err != nil {
+		return err
+	}
+	if reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(reportPath, data, 0644); err != nil {
+			return err
+		}
+	}
+	for _, result := range report.Components {
+		for _, e := range result.Errors {
+			log.Errorf("%s: %s", result.Path, e)
+		}
+	}
+	log.Infof("%d of %d components valid", report.Valid, report.Total)
+	if report.HasErrors() {
+		return fmt.Errorf("%d components are invalid", report.Invalid)
+	}
+	return nil
+}
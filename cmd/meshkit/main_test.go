@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshkit/generators"
+)
+
+const (
+	validComponent = `{
+  "schemaVersion": "core.meshery.io/v1beta1",
+  "version": "v1.0.0",
+  "displayName": "Backend",
+  "format": "JSON",
+  "model": {"name": "example", "version": "v1.0.0"},
+  "metadata": {"svgColor": "<svg></svg>"},
+  "component": {
+    "kind": "Backend",
+    "version": "example.io/v1",
+    "schema": "{\"type\":\"object\",\"properties\":{\"spec\":{\"type\":\"object\"}}}"
+  }
+}`
+	invalidComponent = `{
+  "schemaVersion": "core.meshery.io/v1beta1",
+  "displayName": "Frontend",
+  "format": "XML",
+  "model": {"name": "example"},
+  "component": {"kind": "Frontend", "version": "example.io/v1"}
+}`
+)
+
+func writeComponents(t *testing.T, components map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range components {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// execute runs the command line with args, returning what it wrote to stdout.
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := rootCommand()
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		components  map[string]string
+		wantValid   bool
+		wantInvalid int
+	}{
+		{
+			name:       "valid definitions",
+			components: map[string]string{"Backend.json": validComponent},
+			wantValid:  true,
+		},
+		{
+			name:        "invalid definitions",
+			components:  map[string]string{"Backend.json": validComponent, "Frontend.json": invalidComponent},
+			wantInvalid: 1,
+		},
+		{
+			name:        "malformed definition",
+			components:  map[string]string{"Broken.json": `{"component": `},
+			wantInvalid: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeComponents(t, tt.components)
+			stdout, err := execute(t, "validate", dir)
+			if valid := err == nil; valid != tt.wantValid {
+				t.Errorf("expected valid to be %v, got %v (%v)", tt.wantValid, valid, err)
+			}
+			var report generators.ValidationReport
+			if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+				t.Fatalf("report is not JSON: %v", err)
+			}
+			if report.Total != len(tt.components) || report.Invalid != tt.wantInvalid {
+				t.Errorf("expected %d components with %d invalid, got %d with %d invalid", len(tt.components), tt.wantInvalid, report.Total, report.Invalid)
+			}
+		})
+	}
+}
+
+func TestValidateOutputFile(t *testing.T) {
+	dir := writeComponents(t, map[string]string{"Backend.json": validComponent})
+	output := filepath.Join(t.TempDir(), "report.json")
+	stdout, err := execute(t, "validate", "-o", output, dir)
+	if err != nil {
+		t.Fatalf("expected the components to be valid: %v", err)
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report generators.ValidationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report is not JSON: %v", err)
+	}
+	if report.Valid != 1 {
+		t.Errorf("expected 1 valid component, got %d", report.Valid)
+	}
+}
+
+func TestValidateUsage(t *testing.T) {
+	if _, err := execute(t, "validate"); err == nil {
+		t.Error("expected an error without directory")
+	}
+}
+
+// backendCRD is longer than the 512 bytes sniffed to detect the YAML content of the downloads
+const backendCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: backends.example.io
+spec:
+  group: example.io
+  names:
+    kind: Backend
+    plural: backends
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            description: BackendSpec defines the desired state of the Backend, the URL of the service the traffic is routed to.
+            properties:
+              url:
+                type: string
+                description: URL of the backend service.
+`
+
+func TestGenerateComponents(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "backend.yaml"), []byte(backendCRD), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mirror := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mirror, "backend.yaml"), []byte(backendCRD), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mirrors := filepath.Join(t.TempDir(), "mirrors.yaml")
+	if err := os.WriteFile(mirrors, []byte("strict: true\nmirrors:\n  - upstream: https://crds.example.io/\n    local: "+mirror+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		// existing components of the output directory
+		existing map[string]string
+		wantErr  bool
+	}{
+		{name: "local CRDs", args: []string{"--source", src, "--model", "example", "--model-version", "v1.0.0"}},
+		{name: "mirrored source and store", args: []string{"--source", "https://crds.example.io/backend.yaml/v1.0.0", "--model", "example", "--model-version", "v1.0.0", "--mirrors", mirrors, "--store", t.TempDir()}},
+		{name: "mirrored icons", args: []string{"--source", src, "--model", "example", "--model-version", "v1.0.0", "--mirrors", mirrors, "--icons"}},
+		{name: "unmirrored source in strict mode", args: []string{"--source", "https://charts.example.io/backend.yaml/v1.0.0", "--model", "example", "--mirrors", mirrors}, wantErr: true},
+		{name: "no source", args: []string{"--model", "example"}, wantErr: true},
+		{name: "invalid components", args: []string{"--source", src, "--model", "example", "--model-version", "v1.0.0"}, existing: map[string]string{"Frontend.json": invalidComponent}, wantErr: true},
+		{name: "invalid components without validation", args: []string{"--source", src, "--model", "example", "--model-version", "v1.0.0", "--validate=false"}, existing: map[string]string{"Frontend.json": invalidComponent}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := writeComponents(t, tt.existing)
+			report := filepath.Join(t.TempDir(), "report.json")
+			_, err := execute(t, append([]string{"generate", "components", "--out", out, "--report", report}, tt.args...)...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(filepath.Join(out, "example.io_v1", "Backend.json")); err != nil {
+				t.Errorf("expected the component to be written: %v", err)
+			}
+		})
+	}
+
+	// the validation report lists the components written
+	out := t.TempDir()
+	report := filepath.Join(t.TempDir(), "report.json")
+	if _, err := execute(t, "generate", "components", "--source", src, "--model", "example", "--model-version", "v1.0.0", "--out", out, "--report", report); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var validation generators.ValidationReport
+	if err := json.Unmarshal(data, &validation); err != nil || validation.Total != 1 || validation.Valid != 1 {
+		t.Errorf("expected a report of the valid component, got %+v, %v", validation, err)
+	}
+}
+
+func TestGenerateComponentsIconsOptIn(t *testing.T) {
+	// the icons are fetched from the network unless mirrored, they are only processed on request
+	if flag := componentsCommand().Flags().Lookup("icons"); flag == nil || flag.DefValue != "false" {
+		t.Errorf("expected the icons to be disabled by default, got %+v", flag)
+	}
+}
@@ -0,0 +1,488 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultVolumeSize = "1Gi"
+
+// ComposeOptions configure the conversion of Docker Compose files.
+type ComposeOptions struct {
+	// Name is the name of the design
+	Name string
+	// Namespace is set on the generated resources, it is left empty by default
+	Namespace string
+	// VolumeSize is the storage requested by the PersistentVolumeClaims of the named volumes, it defaults to 1Gi
+	VolumeSize string
+	// Resolver resolves the generated kinds to component definitions, see KubernetesOptions
+	Resolver ComponentResolver
+}
+
+type composeFile struct {
+	Version  string                            `yaml:"version"`
+	Services map[string]map[string]interface{} `yaml:"services"`
+	Volumes  map[string]interface{}            `yaml:"volumes"`
+	Networks map[string]interface{}            `yaml:"networks"`
+	Configs  map[string]interface{}            `yaml:"configs"`
+	Secrets  map[string]interface{}            `yaml:"secrets"`
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ComposeToDesign converts a Compose v2 or v3 file into a design of Kubernetes components:
+// a Deployment per service, a Service per service exposing ports, and a PersistentVolumeClaim per named volume.
+// The fields which have no Kubernetes equivalent are listed in the report.
+func ComposeToDesign(compose []byte, opts ComposeOptions) (v1beta1.Design, Report, error) {
+	design := v1beta1.NewDesign(opts.Name)
+	report := Report{}
+
+	var file composeFile
+	if err := yaml.Unmarshal(compose, &file); err != nil {
+		return design, report, ErrParseManifest(err)
+	}
+	if len(file.Services) == 0 {
+		return design, report, ErrParseManifest(fmt.Errorf("the compose file has no services"))
+	}
+	if opts.VolumeSize == "" {
+		opts.VolumeSize = defaultVolumeSize
+	}
+	for _, section := range []struct {
+		name   string
+		values map[string]interface{}
+	}{{"networks", file.Networks}, {"configs", file.Configs}, {"secrets", file.Secrets}} {
+		if len(section.values) > 0 {
+			report.unsupported(section.name, "top level %s are not converted", section.name)
+		}
+	}
+
+	objects := make([]map[string]interface{}, 0)
+	for _, name := range sortedKeys(file.Volumes) {
+		objects = append(objects, newPersistentVolumeClaim(kubernetesName(name), opts))
+	}
+
+	dependsOn := make(map[string][]string)
+	for _, name := range sortedKeys(file.Services) {
+		c := composeServiceConverter{
+			name:    kubernetesName(name),
+			path:    "services." + name,
+			service: file.Services[name],
+			volumes: file.Volumes,
+			opts:    opts,
+			report:  &report,
+		}
+		objects = append(objects, c.convert()...)
+		dependsOn[c.name] = c.dependsOn
+	}
+
+	for i, obj := range objects {
+		comp, err := kubernetesObjectToComponent(obj, opts.Resolver)
+		if err != nil {
+			return design, report, ErrParseManifest(fmt.Errorf("object %d: %w", i, err))
+		}
+		if comp.Kind == "Deployment" {
+			comp.DependsOn = dependsOn[comp.Name]
+		}
+		design.Components = append(design.Components, comp)
+	}
+	design.ID = designID(design)
+	return design, report, nil
+}
+
+type composeServiceConverter struct {
+	name      string
+	path      string
+	service   map[string]interface{}
+	volumes   map[string]interface{}
+	opts      ComposeOptions
+	report    *Report
+	dependsOn []string
+}
+
+func (c *composeServiceConverter) convert() []map[string]interface{} {
+	container := map[string]interface{}{"name": c.name}
+	podSpec := map[string]interface{}{}
+	replicas := 1
+	var ports []map[string]interface{}
+	var volumes []interface{}
+	var mounts []interface{}
+	labels := map[string]interface{}{"app": c.name}
+
+	for _, key := range sortedKeys(c.service) {
+		value := c.service[key]
+		path := c.path + "." + key
+		switch key {
+		case "image":
+			container["image"] = value
+		case "working_dir":
+			container["workingDir"] = value
+		case "command":
+			container["args"] = toCommand(value)
+		case "entrypoint":
+			container["command"] = toCommand(value)
+		case "environment":
+			container["env"] = toEnv(value)
+		case "ports", "expose":
+			ports = append(ports, c.toPorts(path, value, key == "expose")...)
+		case "volumes":
+			v, m := c.toVolumes(path, value)
+			volumes = append(volumes, v...)
+			mounts = append(mounts, m...)
+		case "labels":
+			for k, v := range toEnvMap(value) {
+				if k == "app" && v != c.name {
+					c.report.warn(path, "the app label is the selector of the pods, it is set to %s", c.name)
+				}
+				labels[k] = v
+			}
+		case "depends_on":
+			c.dependsOn = toDependencies(value)
+		case "scale":
+			replicas = toInt(value, 1)
+		case "deploy":
+			replicas = c.convertDeploy(path, value, replicas)
+		case "hostname":
+			podSpec["hostname"] = value
+		case "privileged":
+			container["securityContext"] = map[string]interface{}{"privileged": value}
+		case "healthcheck":
+			if probe := c.toProbe(path, value); probe != nil {
+				container["livenessProbe"] = probe
+			}
+		case "restart":
+			if s, _ := value.(string); s != "always" && s != "unless-stopped" {
+				c.report.warn(path, "restart policy %q is replaced by the Deployment restart policy Always", s)
+			}
+		case "container_name":
+			c.report.warn(path, "the container is named %s", c.name)
+		case "networks", "network_mode":
+			c.report.warn(path, "services reach each other through their Kubernetes Service instead of networks")
+		default:
+			c.report.unsupported(path, "the %s field has no Kubernetes equivalent", key)
+		}
+	}
+
+	// the selector label is applied last so that the labels of the service don't orphan the pods
+	labels["app"] = c.name
+	ports = c.dedupePorts(ports)
+
+	if len(mounts) > 0 {
+		container["volumeMounts"] = mounts
+		podSpec["volumes"] = volumes
+	}
+	containerPorts := make([]interface{}, 0, len(ports))
+	seenContainerPorts := make(map[string]bool)
+	for _, p := range ports {
+		key := fmt.Sprintf("%v/%v", p["targetPort"], p["protocol"])
+		if seenContainerPorts[key] {
+			continue
+		}
+		seenContainerPorts[key] = true
+		containerPorts = append(containerPorts, map[string]interface{}{"containerPort": p["targetPort"], "protocol": p["protocol"]})
+	}
+	if len(containerPorts) > 0 {
+		container["ports"] = containerPorts
+	}
+	podSpec["containers"] = []interface{}{container}
+
+	deployment := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   c.metadata(labels),
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": c.name}},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec":     podSpec,
+			},
+		},
+	}
+	objects := []map[string]interface{}{deployment}
+	if len(ports) > 0 {
+		servicePorts := make([]interface{}, 0, len(ports))
+		for _, p := range ports {
+			servicePorts = append(servicePorts, p)
+		}
+		objects = append(objects, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   c.metadata(map[string]interface{}{"app": c.name}),
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"app": c.name},
+				"ports":    servicePorts,
+			},
+		})
+	}
+	return objects
+}
+
+func (c *composeServiceConverter) metadata(labels map[string]interface{}) map[string]interface{} {
+	metadata := map[string]interface{}{"name": c.name, "labels": labels}
+	if c.opts.Namespace != "" {
+		metadata["namespace"] = c.opts.Namespace
+	}
+	return metadata
+}
+
+func (c *composeServiceConverter) convertDeploy(path string, value interface{}, replicas int) int {
+	deploy, _ := value.(map[string]interface{})
+	for _, key := range sortedKeys(deploy) {
+		switch key {
+		case "replicas":
+			replicas = toInt(deploy[key], replicas)
+		default:
+			c.report.unsupported(path+"."+key, "the deploy.%s field is not converted", key)
+		}
+	}
+	return replicas
+}
+
+// dedupePorts keeps the first of the ports with the same port and protocol, e.g. a port both published and exposed.
+func (c *composeServiceConverter) dedupePorts(ports []map[string]interface{}) []map[string]interface{} {
+	deduped := make([]map[string]interface{}, 0, len(ports))
+	seen := make(map[string]map[string]interface{})
+	for _, p := range ports {
+		key := fmt.Sprintf("%v/%v", p["port"], p["protocol"])
+		if first, ok := seen[key]; ok {
+			if first["targetPort"] != p["targetPort"] {
+				c.report.warn(c.path+".ports", "the port %s is mapped to %v and %v, only %v is kept", key, first["targetPort"], p["targetPort"], first["targetPort"])
+			}
+			continue
+		}
+		seen[key] = p
+		deduped = append(deduped, p)
+	}
+	return deduped
+}
+
+// toPorts parses the short ([host_ip:][published:]target[/protocol]) and long syntaxes of ports
+func (c *composeServiceConverter) toPorts(path string, value interface{}, expose bool) []map[string]interface{} {
+	entries, _ := value.([]interface{})
+	ports := make([]map[string]interface{}, 0, len(entries))
+	for i, entry := range entries {
+		var target, published int
+		protocol := "TCP"
+		switch p := entry.(type) {
+		case map[string]interface{}:
+			target = toInt(p["target"], 0)
+			published = toInt(p["published"], target)
+			if proto, ok := p["protocol"].(string); ok {
+				protocol = strings.ToUpper(proto)
+			}
+		default:
+			spec := fmt.Sprint(p)
+			if proto := strings.SplitN(spec, "/", 2); len(proto) == 2 {
+				spec, protocol = proto[0], strings.ToUpper(proto[1])
+			}
+			parts := strings.Split(spec, ":")
+			target = toInt(parts[len(parts)-1], 0)
+			published = target
+			if len(parts) > 1 {
+				published = toInt(parts[len(parts)-2], target)
+			}
+		}
+		if target == 0 {
+			// ranges, e.g. 3000-3005, have no Service equivalent
+			c.report.unsupported(fmt.Sprintf("%s[%d]", path, i), "the port %v is not converted", entry)
+			continue
+		}
+		if expose {
+			published = target
+		}
+		ports = append(ports, map[string]interface{}{
+			"name":       fmt.Sprintf("%s-%d", strings.ToLower(protocol), published),
+			"port":       published,
+			"targetPort": target,
+			"protocol":   protocol,
+		})
+	}
+	return ports
+}
+
+// toVolumes converts the named volumes mounts, bind mounts are not supported
+func (c *composeServiceConverter) toVolumes(path string, value interface{}) ([]interface{}, []interface{}) {
+	entries, _ := value.([]interface{})
+	var volumes, mounts []interface{}
+	for i, entry := range entries {
+		var source, target string
+		readOnly := false
+		switch v := entry.(type) {
+		case map[string]interface{}:
+			source, _ = v["source"].(string)
+			target, _ = v["target"].(string)
+			readOnly, _ = v["read_only"].(bool)
+		case string:
+			parts := strings.Split(v, ":")
+			if len(parts) == 1 {
+				target = parts[0]
+			} else {
+				source, target = parts[0], parts[1]
+				readOnly = len(parts) > 2 && strings.Contains(parts[2], "ro")
+			}
+		}
+		entryPath := fmt.Sprintf("%s[%d]", path, i)
+		var volume map[string]interface{}
+		switch {
+		case source == "":
+			volume = map[string]interface{}{"emptyDir": map[string]interface{}{}}
+			source = fmt.Sprintf("%s-data-%d", c.name, i)
+		case strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~"):
+			c.report.unsupported(entryPath, "the bind mount of %s is not converted", source)
+			continue
+		default:
+			if _, declared := c.volumes[source]; !declared {
+				c.report.warn(entryPath, "the volume %s is not declared in the top level volumes", source)
+			}
+			volume = map[string]interface{}{"persistentVolumeClaim": map[string]interface{}{"claimName": kubernetesName(source)}}
+		}
+		volume["name"] = kubernetesName(source)
+		volumes = append(volumes, volume)
+		mount := map[string]interface{}{"name": kubernetesName(source), "mountPath": target}
+		if readOnly {
+			mount["readOnly"] = true
+		}
+		mounts = append(mounts, mount)
+	}
+	return volumes, mounts
+}
+
+func (c *composeServiceConverter) toProbe(path string, value interface{}) map[string]interface{} {
+	healthcheck, _ := value.(map[string]interface{})
+	if disabled, _ := healthcheck["disable"].(bool); disabled {
+		return nil
+	}
+	var command []interface{}
+	switch test := healthcheck["test"].(type) {
+	case []interface{}:
+		if len(test) > 0 && test[0] == "NONE" {
+			return nil
+		}
+		if len(test) > 1 && test[0] == "CMD-SHELL" {
+			command = []interface{}{"sh", "-c", test[1]}
+		} else if len(test) > 0 && test[0] == "CMD" {
+			command = test[1:]
+		} else {
+			command = test
+		}
+	case string:
+		command = []interface{}{"sh", "-c", test}
+	default:
+		c.report.unsupported(path, "the healthcheck has no test")
+		return nil
+	}
+	probe := map[string]interface{}{"exec": map[string]interface{}{"command": command}}
+	if retries, ok := healthcheck["retries"]; ok {
+		probe["failureThreshold"] = toInt(retries, 3)
+	}
+	for _, key := range []string{"interval", "timeout", "start_period"} {
+		if _, ok := healthcheck[key]; ok {
+			c.report.warn(path+"."+key, "durations are not converted, the probe uses the Kubernetes defaults")
+		}
+	}
+	return probe
+}
+
+func newPersistentVolumeClaim(name string, opts ComposeOptions) map[string]interface{} {
+	metadata := map[string]interface{}{"name": name}
+	if opts.Namespace != "" {
+		metadata["namespace"] = opts.Namespace
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolumeClaim",
+		"metadata":   metadata,
+		"spec": map[string]interface{}{
+			"accessModes": []interface{}{"ReadWriteOnce"},
+			"resources":   map[string]interface{}{"requests": map[string]interface{}{"storage": opts.VolumeSize}},
+		},
+	}
+}
+
+func kubernetesName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(name, "-")
+}
+
+func toCommand(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.Fields(s)
+	}
+	return value
+}
+
+// toEnvMap normalizes the list (KEY=value) and map syntaxes of environment and labels
+func toEnvMap(value interface{}) map[string]string {
+	env := make(map[string]string)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if val == nil {
+				env[k] = ""
+			} else {
+				env[k] = fmt.Sprint(val)
+			}
+		}
+	case []interface{}:
+		for _, entry := range v {
+			kv := strings.SplitN(fmt.Sprint(entry), "=", 2)
+			if len(kv) == 2 {
+				env[kv[0]] = kv[1]
+			} else {
+				env[kv[0]] = ""
+			}
+		}
+	}
+	return env
+}
+
+func toEnv(value interface{}) []interface{} {
+	envMap := toEnvMap(value)
+	env := make([]interface{}, 0, len(envMap))
+	for _, k := range sortedKeys(envMap) {
+		env = append(env, map[string]interface{}{"name": k, "value": envMap[k]})
+	}
+	return env
+}
+
+func toDependencies(value interface{}) []string {
+	var deps []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, d := range v {
+			deps = append(deps, kubernetesName(fmt.Sprint(d)))
+		}
+	case map[string]interface{}:
+		for _, d := range sortedKeys(v) {
+			deps = append(deps, kubernetesName(d))
+		}
+	}
+	return deps
+}
+
+func toInt(value interface{}, defaultValue int) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
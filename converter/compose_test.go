@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"testing"
+)
+
+const testCompose = `
+version: "3.8"
+services:
+  web:
+    image: nginx:1.25
+    ports:
+      - "8080:80"
+      - target: 443
+        published: 8443
+    environment:
+      - MODE=production
+    depends_on:
+      - db
+    build: .
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD: example
+    volumes:
+      - db_data:/var/lib/postgresql/data
+      - ./init.sql:/docker-entrypoint-initdb.d/init.sql
+    deploy:
+      replicas: 2
+volumes:
+  db_data: {}
+`
+
+func TestComposeToDesign(t *testing.T) {
+	design, report, err := ComposeToDesign([]byte(testCompose), ComposeOptions{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]int)
+	for _, comp := range design.Components {
+		kinds[comp.Kind]++
+	}
+	if kinds["Deployment"] != 2 || kinds["Service"] != 1 || kinds["PersistentVolumeClaim"] != 1 {
+		t.Fatalf("unexpected components %v", kinds)
+	}
+
+	for _, comp := range design.Components {
+		if comp.Kind != "Deployment" {
+			continue
+		}
+		spec := comp.Configuration["spec"].(map[string]interface{})
+		switch comp.Name {
+		case "web":
+			if len(comp.DependsOn) != 1 || comp.DependsOn[0] != "db" {
+				t.Errorf("web should depend on db, got %v", comp.DependsOn)
+			}
+		case "db":
+			if spec["replicas"] != 2 {
+				t.Errorf("db should have 2 replicas, got %v", spec["replicas"])
+			}
+		}
+	}
+
+	unsupported := make(map[string]bool)
+	for _, f := range report.Unsupported {
+		unsupported[f.Path] = true
+	}
+	if !unsupported["services.web.build"] || !unsupported["services.db.volumes[1]"] {
+		t.Errorf("unexpected unsupported fields %v", report.Unsupported)
+	}
+
+	again, _, _ := ComposeToDesign([]byte(testCompose), ComposeOptions{Name: "app"})
+	if again.ID != design.ID {
+		t.Errorf("the design id should be stable across imports")
+	}
+	renamed, _, _ := ComposeToDesign([]byte(testCompose), ComposeOptions{Name: "renamed"})
+	if renamed.ID == design.ID {
+		t.Errorf("designs with different names should have different ids")
+	}
+}
+
+func TestComposeToDesignSelectorAndPorts(t *testing.T) {
+	compose := `
+services:
+  api:
+    image: api:1
+    labels:
+      app: backend
+      tier: api
+    ports:
+      - "8080:8080"
+      - "8080:8080/udp"
+    expose:
+      - "8080"
+`
+	design, report, err := ComposeToDesign([]byte(compose), ComposeOptions{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comp := range design.Components {
+		spec := comp.Configuration["spec"].(map[string]interface{})
+		switch comp.Kind {
+		case "Deployment":
+			template := spec["template"].(map[string]interface{})
+			labels := template["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+			if labels["app"] != "api" || labels["tier"] != "api" {
+				t.Errorf("expected the selector label to be kept, got %v", labels)
+			}
+			container := template["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+			if ports := container["ports"].([]interface{}); len(ports) != 2 {
+				t.Errorf("expected a container port per protocol, got %v", ports)
+			}
+		case "Service":
+			if ports := spec["ports"].([]interface{}); len(ports) != 2 {
+				t.Errorf("expected the exposed port to be deduped, got %v", ports)
+			}
+		}
+	}
+	if len(report.Warnings) == 0 {
+		t.Error("expected the overridden app label to be reported")
+	}
+}
@@ -0,0 +1,37 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrParseManifestCode = "meshkit-11269"
+	ErrExportDesignCode  = "meshkit-11270"
+	ErrMigrateDesignCode = "meshkit-11271"
+
+	ErrUnresolvedVariablesCode = "meshkit-11323"
+	ErrGenerateSBOMCode        = "meshkit-11348"
+)
+
+func ErrParseManifest(err error) error {
+	return errors.New(ErrParseManifestCode, errors.Alert, []string{"Could not parse the manifest"}, []string{err.Error()}, []string{"The manifest is not valid YAML or JSON", "A document of the manifest has no apiVersion or kind"}, []string{"Make sure every document of the manifest is a valid Kubernetes object"})
+}
+
+func ErrExportDesign(err error) error {
+	return errors.New(ErrExportDesignCode, errors.Alert, []string{"Could not export the design"}, []string{err.Error()}, []string{"The design has components with invalid configurations", "The name or version of the design are not valid for the target format"}, []string{"Make sure the design is valid and has a name and a semantic version"})
+}
+
+func ErrMigrateDesign(err error) error {
+	return errors.New(ErrMigrateDesignCode, errors.Alert, []string{"Could not migrate the design"}, []string{err.Error()}, []string{"The schema version of the design is unknown", "There is no migration path between the schema versions", "The design is malformed"}, []string{"Make sure the design was saved by a supported version of Meshery"})
+}
+
+func ErrUnresolvedVariables(names []string) error {
+	return errors.New(ErrUnresolvedVariablesCode, errors.Alert, []string{"Could not flatten the design"}, []string{fmt.Sprintf("the variables %s are not defined", strings.Join(names, ", "))}, []string{"The environments do not define the variables referenced by the design", "A secret of the environment is missing"}, []string{"Define the variables in the environments, or give them a default with ${NAME:-default}"})
+}
+
+func ErrGenerateSBOM(err error) error {
+	return errors.New(ErrGenerateSBOMCode, errors.Alert, []string{"Could not generate the SBOM of the design"}, []string{err.Error()}, []string{"The SBOM format is not supported"}, []string{"Use the CycloneDX or the SPDX format"})
+}
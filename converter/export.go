@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+)
+
+// ComponentToKubernetes returns the Kubernetes object of a design component, the inverse of the conversion done by KubernetesToDesign.
+func ComponentToKubernetes(comp v1beta1.DesignComponent) map[string]interface{} {
+	obj := make(map[string]interface{}, len(comp.Configuration)+3)
+	for k, v := range comp.Configuration {
+		obj[k] = deepCopy(v)
+	}
+	obj["apiVersion"] = comp.APIVersion
+	obj["kind"] = comp.Kind
+
+	metadata := map[string]interface{}{"name": comp.Name}
+	if comp.Namespace != "" {
+		metadata["namespace"] = comp.Namespace
+	}
+	if len(comp.Labels) > 0 {
+		metadata["labels"] = toInterfaceMap(comp.Labels)
+	}
+	if len(comp.Annotations) > 0 {
+		metadata["annotations"] = toInterfaceMap(comp.Annotations)
+	}
+	obj["metadata"] = metadata
+	return obj
+}
+
+// resourceFileName is the name of the file holding the manifest of the component, e.g. web-deployment.yaml
+func resourceFileName(comp v1beta1.DesignComponent) string {
+	return fmt.Sprintf("%s-%s.yaml", kubernetesName(comp.Name), strings.ToLower(comp.Kind))
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = deepCopy(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = deepCopy(val)
+		}
+		return out
+	}
+	return value
+}
+
+// marshalYAML encodes the value with the two spaces indentation used by Kubernetes manifests
+func marshalYAML(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
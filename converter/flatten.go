@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment provides the values of the variables of a design for a target, e.g. dev, stage or prod.
+type Environment struct {
+	Name      string            `json:"name" yaml:"name"`
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"`
+	// Secrets are looked up after the variables, their values are never reported
+	Secrets map[string]string `json:"-" yaml:"-"`
+}
+
+// FlattenOptions configure the flattening of a design into manifests.
+type FlattenOptions struct {
+	// Environments in increasing precedence, e.g. the defaults then prod, a variable is set by the last environment defining it
+	Environments []Environment
+	// Strict fails the flattening if variables are unresolved, they are left as is and reported otherwise
+	Strict bool
+}
+
+// FlattenReport lists the variables which could not be substituted.
+type FlattenReport struct {
+	// Unresolved are the references to variables which none of the environments define, by path in the design
+	Unresolved []Finding `json:"unresolved,omitempty"`
+
+	variables map[string]struct{}
+}
+
+// UnresolvedVariables returns the sorted names of the unresolved variables.
+func (r FlattenReport) UnresolvedVariables() []string {
+	return sortedKeys(r.variables)
+}
+
+func (r *FlattenReport) unresolved(path, name string) {
+	if r.variables == nil {
+		r.variables = make(map[string]struct{})
+	}
+	r.variables[name] = struct{}{}
+	r.Unresolved = append(r.Unresolved, Finding{Path: path, Message: fmt.Sprintf("the variable %s is not defined", name)})
+}
+
+// variableReference matches ${NAME} and ${NAME:-default}, $${NAME} escaping the reference.
+var variableReference = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// FlattenDesign renders the components of the design into the final Kubernetes objects to deploy, in the order of the design.
+// The references to variables, ${NAME} or ${NAME:-default}, in the names, namespaces, labels, annotations and configurations
+// of the components are substituted with the values of the environments, so that the same design can target several
+// environments. $${NAME} is kept as the literal ${NAME}. A value which is a single reference takes the type of the
+// YAML-decoded value of the variable, quote the value, e.g. '"1.0"', to keep it a string, except in the fields which
+// are strings: the metadata, the values of the environment variables of the containers and the data of the ConfigMaps
+// and Secrets. The design is left unchanged.
+func FlattenDesign(design v1beta1.Design, opts FlattenOptions) ([]map[string]interface{}, FlattenReport, error) {
+	f := flattener{values: make(map[string]string)}
+	for _, env := range opts.Environments {
+		for _, values := range []map[string]string{env.Variables, env.Secrets} {
+			for name, value := range values {
+				f.values[name] = value
+			}
+		}
+	}
+
+	objects := make([]map[string]interface{}, 0, len(design.Components))
+	for _, comp := range design.Components {
+		obj := ComponentToKubernetes(comp)
+		path := fmt.Sprintf("%s/%s", comp.Kind, comp.Name)
+		objects = append(objects, f.substituteObject(path, obj))
+	}
+	if opts.Strict && len(f.report.Unresolved) > 0 {
+		return nil, f.report, ErrUnresolvedVariables(f.report.UnresolvedVariables())
+	}
+	return objects, f.report, nil
+}
+
+// Manifest encodes the objects into a YAML stream, e.g. to apply the objects returned by FlattenDesign.
+func Manifest(objects []map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, obj := range objects {
+		data, err := marshalYAML(obj)
+		if err != nil {
+			return nil, ErrExportDesign(err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+type flattener struct {
+	values map[string]string
+	report FlattenReport
+}
+
+// stringFields are the top level fields of the objects whose values are strings: their metadata and the data of the
+// ConfigMaps and Secrets.
+var stringFields = map[string]bool{"metadata": true, "data": true, "stringData": true, "binaryData": true}
+
+// envVarPath matches the path of an environment variable of a container, whose value is a string.
+var envVarPath = regexp.MustCompile(`\.env\[\d+\]$`)
+
+// substituteObject returns a copy of the object with the variables substituted.
+func (f *flattener) substituteObject(path string, obj map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(obj))
+	for _, k := range sortedKeys(obj) {
+		out[f.substituteString(path, k)] = f.substitute(path+"."+k, obj[k], stringFields[k])
+	}
+	return out
+}
+
+// substitute returns a copy of the value with the variables substituted, reporting the unresolved ones under the path.
+// The strings of string typed fields, e.g. the labels of the templates or the values of the environment variables,
+// stay strings.
+func (f *flattener) substitute(path string, value interface{}, stringTyped bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		// sorted for the findings to be reported in a stable order
+		for _, k := range sortedKeys(v) {
+			stringField := stringTyped || k == "metadata" || (k == "value" && envVarPath.MatchString(path))
+			out[f.substituteString(path, k)] = f.substitute(path+"."+k, v[k], stringField)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = f.substitute(fmt.Sprintf("%s[%d]", path, i), val, stringTyped)
+		}
+		return out
+	case string:
+		if stringTyped {
+			return f.substituteString(path, v)
+		}
+		return f.substituteValue(path, v)
+	}
+	return value
+}
+
+// substituteValue substitutes the variables of the string value. A value which is a single reference, e.g. ${REPLICAS},
+// is replaced with the YAML-decoded value of the variable, so that numbers, booleans, lists and maps keep their type,
+// as in the ${REPLICAS} of spec.replicas.
+func (f *flattener) substituteValue(path, s string) interface{} {
+	substituted := f.substituteString(path, s)
+	loc := variableReference.FindStringIndex(s)
+	if loc == nil || loc[0] != 0 || loc[1] != len(s) || strings.HasPrefix(s, "$$") || substituted == s {
+		return substituted
+	}
+	var typed interface{}
+	if err := yaml.Unmarshal([]byte(substituted), &typed); err != nil || typed == nil {
+		return substituted
+	}
+	return typed
+}
+
+func (f *flattener) substituteString(path, s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return variableReference.ReplaceAllStringFunc(s, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		match := variableReference.FindStringSubmatch(ref)
+		name, hasDefault, defaultValue := match[1], match[2] != "", match[3]
+		if value, ok := f.values[name]; ok {
+			return value
+		}
+		if hasDefault {
+			return defaultValue
+		}
+		f.report.unresolved(path, name)
+		return ref
+	})
+}
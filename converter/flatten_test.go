@@ -0,0 +1,128 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestFlattenDesign(t *testing.T) {
+	design := v1beta1.NewDesign("web")
+	design.Components = append(design.Components, v1beta1.DesignComponent{
+		Name:        "web-${ENV}",
+		Namespace:   "${NAMESPACE:-default}",
+		Kind:        "Deployment",
+		APIVersion:  "apps/v1",
+		Labels:      map[string]string{"tier": "${TIER}"},
+		Annotations: map[string]string{"version": "${VERSION}"},
+		Configuration: map[string]interface{}{"spec": map[string]interface{}{
+			"replicas": "${REPLICAS}",
+			"paused":   "${PAUSED:-false}",
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{
+				"image": "registry/web:${TAG}",
+				"env": []interface{}{
+					map[string]interface{}{"name": "PASSWORD", "value": "${PASSWORD}"},
+					map[string]interface{}{"name": "LITERAL", "value": "$${HOME}"},
+					map[string]interface{}{"name": "PORT", "value": "${PORT}"},
+				},
+			}}}},
+		}},
+	})
+	defaults := Environment{Name: "defaults", Variables: map[string]string{"ENV": "dev", "TAG": "latest", "REPLICAS": "3", "VERSION": "1.0", "PORT": "8080"}}
+	prod := Environment{Name: "prod", Variables: map[string]string{"ENV": "prod"}, Secrets: map[string]string{"PASSWORD": "s3cr3t"}}
+
+	objects, report, err := FlattenDesign(design, FlattenOptions{Environments: []Environment{defaults, prod}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := Manifest(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"name: web-prod", "namespace: default", "image: registry/web:latest", "value: s3cr3t", "value: ${HOME}", "tier: ${TIER}"} {
+		if !strings.Contains(string(manifest), want) {
+			t.Errorf("expected the manifest to contain %q, got\n%s", want, manifest)
+		}
+	}
+	spec := objects[0]["spec"].(map[string]interface{})
+	if spec["replicas"] != 3 || spec["paused"] != false {
+		t.Errorf("expected the single references to be typed, got replicas %#v and paused %#v", spec["replicas"], spec["paused"])
+	}
+	for _, want := range []string{`version: "1.0"`, `value: "8080"`} {
+		if !strings.Contains(string(manifest), want) {
+			t.Errorf("expected the manifest to contain %q, got\n%s", want, manifest)
+		}
+	}
+	if got := report.UnresolvedVariables(); len(got) != 1 || got[0] != "TIER" {
+		t.Errorf("unresolved variables = %v", got)
+	}
+	if len(report.Unresolved) != 1 || report.Unresolved[0].Path != "Deployment/web-${ENV}.metadata.labels.tier" {
+		t.Errorf("unresolved = %v", report.Unresolved)
+	}
+	if design.Components[0].Name != "web-${ENV}" {
+		t.Error("expected the design to be left unchanged")
+	}
+
+	if _, _, err := FlattenDesign(design, FlattenOptions{Environments: []Environment{defaults}, Strict: true}); err == nil || !strings.Contains(err.Error(), "PASSWORD, TIER") {
+		t.Errorf("expected the unresolved variables to fail the strict flattening, got %v", err)
+	}
+}
+
+func TestFlattenDesignStringFields(t *testing.T) {
+	design := v1beta1.NewDesign("web")
+	design.Components = append(design.Components,
+		v1beta1.DesignComponent{
+			Name:       "web",
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+			Configuration: map[string]interface{}{"spec": map[string]interface{}{
+				"replicas": "${REPLICAS}",
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"debug": "${DEBUG}"}},
+					"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{
+						"ports": []interface{}{map[string]interface{}{"containerPort": "${PORT}"}},
+						"env":   []interface{}{map[string]interface{}{"name": "PORT", "value": "${PORT}"}},
+					}}},
+				},
+			}},
+		},
+		v1beta1.DesignComponent{
+			Name:          "web",
+			Kind:          "Secret",
+			APIVersion:    "v1",
+			Configuration: map[string]interface{}{"stringData": map[string]interface{}{"PIN": "${PIN}", "DEBUG": "${DEBUG}"}},
+		},
+		v1beta1.DesignComponent{
+			Name:          "web",
+			Kind:          "ConfigMap",
+			APIVersion:    "v1",
+			Configuration: map[string]interface{}{"data": map[string]interface{}{"port": "${PORT}"}},
+		},
+	)
+	env := Environment{Name: "prod", Variables: map[string]string{"REPLICAS": "3", "PORT": "8080", "DEBUG": "true"}, Secrets: map[string]string{"PIN": "0123"}}
+
+	objects, _, err := FlattenDesign(design, FlattenOptions{Environments: []Environment{env}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := objects[0]["spec"].(map[string]interface{})
+	template := spec["template"].(map[string]interface{})
+	container := template["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+	if spec["replicas"] != 3 || container["ports"].([]interface{})[0].(map[string]interface{})["containerPort"] != 8080 {
+		t.Errorf("expected the numeric fields to be typed, got %v", spec)
+	}
+	if value := container["env"].([]interface{})[0].(map[string]interface{})["value"]; value != "8080" {
+		t.Errorf("expected the value of the environment variable to stay a string, got %#v", value)
+	}
+	if debug := template["metadata"].(map[string]interface{})["labels"].(map[string]interface{})["debug"]; debug != "true" {
+		t.Errorf("expected the label of the template to stay a string, got %#v", debug)
+	}
+	stringData := objects[1]["stringData"].(map[string]interface{})
+	if stringData["PIN"] != "0123" || stringData["DEBUG"] != "true" {
+		t.Errorf("expected the data of the secret to stay strings, got %#v", stringData)
+	}
+	if port := objects[2]["data"].(map[string]interface{})["port"]; port != "8080" {
+		t.Errorf("expected the data of the config map to stay strings, got %#v", port)
+	}
+}
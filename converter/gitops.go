@@ -0,0 +1,451 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// GitOpsSourceMetadata is set in the metadata of the components imported from the sources of GitOps resources,
+// with the kind, namespace and name of the resource, e.g. Application/argocd/web.
+const GitOpsSourceMetadata = "gitopsSource"
+
+// Kinds of the sources of GitOps resources.
+const (
+	GitSource  = "Git"
+	HelmSource = "Helm"
+	OCISource  = "OCI"
+)
+
+// GitOpsSource is where a GitOps resource, an Argo CD Application or a Flux Kustomization or HelmRelease, deploys from.
+type GitOpsSource struct {
+	// Kind is GitSource, HelmSource or OCISource
+	Kind     string `json:"kind"`
+	URL      string `json:"url"`
+	Revision string `json:"revision,omitempty"`
+	// Path is the directory of the manifests or of the chart in the repository
+	Path string `json:"path,omitempty"`
+	// Chart and ChartVersion are set for the charts of Helm repositories
+	Chart        string                 `json:"chart,omitempty"`
+	ChartVersion string                 `json:"chartVersion,omitempty"`
+	Values       map[string]interface{} `json:"values,omitempty"`
+	// Namespace is the namespace the resources are deployed to, if set by the GitOps resource
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (s GitOpsSource) String() string {
+	ref := s.URL
+	if s.Path != "" {
+		ref += "//" + s.Path
+	}
+	if s.Chart != "" {
+		ref += "/" + s.Chart
+	}
+	if s.Revision != "" {
+		ref += "@" + s.Revision
+	}
+	if s.ChartVersion != "" {
+		ref += "@" + s.ChartVersion
+	}
+	return ref
+}
+
+// SourceResolver fetches the manifests deployed from a source, rendering the charts.
+// It returns nil if the source cannot be resolved, e.g. it is not available locally.
+type SourceResolver interface {
+	ResolveSource(source GitOpsSource) ([]byte, error)
+}
+
+// GitOpsOptions configure the import of GitOps resources.
+type GitOpsOptions struct {
+	// Name is the name of the design
+	Name string
+	// Sources resolves the sources of the GitOps resources, e.g. LocalSourceResolver or RemoteSourceResolver. If nil,
+	// or if a source cannot be resolved, the GitOps resource is kept as a component.
+	Sources SourceResolver
+	// Resolver resolves the kinds of the deployed resources to component definitions, see KubernetesOptions
+	Resolver ComponentResolver
+}
+
+// GitOpsToDesign converts the Argo CD Applications and the Flux Kustomizations and HelmReleases of the manifest into
+// a design of the resources they deploy, so that existing GitOps setups can be onboarded. The sources of the Flux resources,
+// GitRepositories, HelmRepositories and OCIRepositories, are looked up in the manifest. The components are annotated with
+// the GitOps resource deploying them, see GitOpsSourceMetadata. The other objects of the manifest are imported as is.
+func GitOpsToDesign(manifest []byte, opts GitOpsOptions) (v1beta1.Design, Report, error) {
+	design := v1beta1.NewDesign(opts.Name)
+	report := Report{}
+
+	objects, err := decodeKubernetesObjects(manifest)
+	if err != nil {
+		return design, report, ErrParseManifest(err)
+	}
+	fluxSources := make(map[string]map[string]interface{})
+	for _, obj := range objects {
+		if kind, ok := fluxSourceKind(obj); ok {
+			fluxSources[fluxSourceKey(kind, objectNamespace(obj), objectName(obj))] = obj
+		}
+	}
+
+	for i, obj := range objects {
+		if _, ok := fluxSourceKind(obj); ok {
+			continue
+		}
+		kind, _ := obj["kind"].(string)
+		path := fmt.Sprintf("%s/%s", kind, objectName(obj))
+		sources, isGitOps, err := gitOpsSources(obj, fluxSources)
+		if err != nil {
+			report.unsupported(path, "%s", err)
+		}
+		if !isGitOps || err != nil {
+			comp, err := kubernetesObjectToComponent(obj, opts.Resolver)
+			if err != nil {
+				return design, report, ErrParseManifest(fmt.Errorf("document %d: %w", i, err))
+			}
+			design.Components = append(design.Components, comp)
+			continue
+		}
+
+		origin := fmt.Sprintf("%s/%s/%s", kind, objectNamespace(obj), objectName(obj))
+		resolved := true
+		var deployed []map[string]interface{}
+		for _, source := range sources {
+			var data []byte
+			if opts.Sources != nil {
+				if data, err = opts.Sources.ResolveSource(source); err != nil {
+					return design, report, ErrParseManifest(fmt.Errorf("%s: source %s: %w", path, source, err))
+				}
+			}
+			if data == nil {
+				report.warn(path, "the source %s could not be resolved, imported as the %s itself", source, kind)
+				resolved = false
+				break
+			}
+			objs, err := decodeKubernetesObjects(data)
+			if err != nil {
+				return design, report, ErrParseManifest(fmt.Errorf("%s: source %s: %w", path, source, err))
+			}
+			for _, o := range objs {
+				if source.Namespace != "" && objectNamespace(o) == "" {
+					metadata, _ := o["metadata"].(map[string]interface{})
+					if metadata == nil {
+						metadata = make(map[string]interface{})
+						o["metadata"] = metadata
+					}
+					metadata["namespace"] = source.Namespace
+				}
+			}
+			deployed = append(deployed, objs...)
+		}
+		if !resolved {
+			deployed = []map[string]interface{}{obj}
+		}
+		for _, o := range deployed {
+			comp, err := kubernetesObjectToComponent(o, opts.Resolver)
+			if err != nil {
+				return design, report, ErrParseManifest(fmt.Errorf("%s: %w", path, err))
+			}
+			if resolved {
+				comp.Metadata[GitOpsSourceMetadata] = origin
+			}
+			design.Components = append(design.Components, comp)
+		}
+	}
+	return design, report, nil
+}
+
+// gitOpsSources returns the sources of the GitOps resource, and false if the object is not a GitOps resource.
+func gitOpsSources(obj map[string]interface{}, fluxSources map[string]map[string]interface{}) ([]GitOpsSource, bool, error) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	spec, _ := obj["spec"].(map[string]interface{})
+	switch {
+	case kind == "Application" && strings.HasPrefix(apiVersion, "argoproj.io/"):
+		return argoSources(spec), true, nil
+	case kind == "Kustomization" && strings.HasPrefix(apiVersion, "kustomize.toolkit.fluxcd.io/"):
+		source, err := fluxSource(spec["sourceRef"], objectNamespace(obj), fluxSources)
+		if err != nil {
+			return nil, true, err
+		}
+		source.Path = strings.TrimPrefix(stringField(spec, "path"), "./")
+		source.Namespace = stringField(spec, "targetNamespace")
+		return []GitOpsSource{source}, true, nil
+	case kind == "HelmRelease" && strings.HasPrefix(apiVersion, "helm.toolkit.fluxcd.io/"):
+		chart, _ := spec["chart"].(map[string]interface{})
+		chartSpec, _ := chart["spec"].(map[string]interface{})
+		source, err := fluxSource(chartSpec["sourceRef"], objectNamespace(obj), fluxSources)
+		if err != nil {
+			return nil, true, err
+		}
+		if source.Kind == HelmSource {
+			source.Chart = stringField(chartSpec, "chart")
+			source.ChartVersion = stringField(chartSpec, "version")
+		} else {
+			// the chart is a directory of the repository
+			source.Path = strings.TrimPrefix(stringField(chartSpec, "chart"), "./")
+		}
+		source.Values, _ = spec["values"].(map[string]interface{})
+		source.Namespace = stringField(spec, "targetNamespace")
+		if source.Namespace == "" {
+			source.Namespace = objectNamespace(obj)
+		}
+		return []GitOpsSource{source}, true, nil
+	}
+	return nil, false, nil
+}
+
+// argoSources returns the source, or the sources of a multi-source Application.
+func argoSources(spec map[string]interface{}) []GitOpsSource {
+	destination, _ := spec["destination"].(map[string]interface{})
+	namespace := stringField(destination, "namespace")
+	var specs []interface{}
+	if source, ok := spec["source"]; ok {
+		specs = append(specs, source)
+	}
+	if sources, ok := spec["sources"].([]interface{}); ok {
+		specs = append(specs, sources...)
+	}
+	sources := make([]GitOpsSource, 0, len(specs))
+	for _, s := range specs {
+		src, _ := s.(map[string]interface{})
+		source := GitOpsSource{
+			Kind:      GitSource,
+			URL:       stringField(src, "repoURL"),
+			Revision:  stringField(src, "targetRevision"),
+			Path:      strings.TrimPrefix(stringField(src, "path"), "./"),
+			Namespace: namespace,
+		}
+		if chart := stringField(src, "chart"); chart != "" {
+			source.Kind = HelmSource
+			source.Chart = chart
+			source.ChartVersion = source.Revision
+			source.Revision = ""
+		}
+		if helm, ok := src["helm"].(map[string]interface{}); ok {
+			source.Values, _ = helm["valuesObject"].(map[string]interface{})
+			if values := stringField(helm, "values"); values != "" && source.Values == nil {
+				_ = yaml.Unmarshal([]byte(values), &source.Values)
+			}
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+func fluxSourceKind(obj map[string]interface{}) (string, bool) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	if !strings.HasPrefix(apiVersion, "source.toolkit.fluxcd.io/") {
+		return "", false
+	}
+	switch kind {
+	case "GitRepository", "HelmRepository", "OCIRepository", "Bucket":
+		return kind, true
+	}
+	return "", false
+}
+
+func fluxSourceKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// fluxSource looks up the source referenced by a Flux resource, in the namespace of the resource by default.
+func fluxSource(ref interface{}, namespace string, fluxSources map[string]map[string]interface{}) (GitOpsSource, error) {
+	sourceRef, _ := ref.(map[string]interface{})
+	kind, name := stringField(sourceRef, "kind"), stringField(sourceRef, "name")
+	if ns := stringField(sourceRef, "namespace"); ns != "" {
+		namespace = ns
+	}
+	obj, ok := fluxSources[fluxSourceKey(kind, namespace, name)]
+	if !ok {
+		return GitOpsSource{}, fmt.Errorf("the source %s %s/%s is not in the manifest", kind, namespace, name)
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	source := GitOpsSource{Kind: GitSource, URL: stringField(spec, "url")}
+	gitRef, _ := spec["ref"].(map[string]interface{})
+	for _, field := range []string{"commit", "digest", "tag", "semver", "branch"} {
+		if revision := stringField(gitRef, field); revision != "" {
+			source.Revision = revision
+			break
+		}
+	}
+	switch kind {
+	case "HelmRepository":
+		source.Kind = HelmSource
+	case "OCIRepository":
+		source.Kind = OCISource
+	case "Bucket":
+		return GitOpsSource{}, fmt.Errorf("the Bucket sources are not supported")
+	}
+	return source, nil
+}
+
+func objectName(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	return stringField(metadata, "name")
+}
+
+func objectNamespace(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	return stringField(metadata, "namespace")
+}
+
+func stringField(m map[string]interface{}, field string) string {
+	s, _ := m[field].(string)
+	return s
+}
+
+// LocalSourceResolver resolves the sources from local checkouts of the repositories, e.g. in CI.
+type LocalSourceResolver struct {
+	// Repositories are the directories of the checkouts by URL of the repositories. Charts of Helm repositories are
+	// looked up at <directory>/<chart>.
+	Repositories map[string]string
+}
+
+// ResolveSource returns the manifests of the directory of the source, or the rendered templates of its chart.
+// The directory is read as by the kustomize-controller of Flux: a directory with a kustomization file is built by
+// kustomize, the manifests of the other directories are read along with the ones of their subdirectories.
+// The path of the source must be within the checkout.
+func (r LocalSourceResolver) ResolveSource(source GitOpsSource) ([]byte, error) {
+	root, ok := r.Repositories[source.URL]
+	if !ok {
+		root, ok = r.Repositories[strings.TrimSuffix(source.URL, ".git")]
+	}
+	if !ok {
+		return nil, nil
+	}
+	dir, err := confinedPath(root, path.Join(source.Path, source.Chart))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, chartutil.ChartfileName)); err == nil {
+		return renderLocalChart(dir, source)
+	}
+	return readManifestDir(dir)
+}
+
+// confinedPath joins the slash separated path to the root, it fails if the result is outside the root, e.g. for ../.
+// The symlinks are evaluated, so that a link out of the repository is rejected as well.
+func confinedPath(root, p string) (string, error) {
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(root, filepath.FromSlash(p))
+	if !isWithin(root, dir) {
+		return "", fmt.Errorf("the path %s is outside the repository", p)
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return "", err
+	}
+	if !isWithin(root, dir) {
+		return "", fmt.Errorf("the path %s links outside the repository", p)
+	}
+	return dir, nil
+}
+
+func isWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// kustomizationFileNames are the names of the files configuring a kustomize build
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+func readManifestDir(dir string) ([]byte, error) {
+	for _, name := range kustomizationFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return buildKustomization(dir)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var manifests []string
+	for _, entry := range entries {
+		name := entry.Name()
+		// the symlinks are skipped, they could point outside of the repository
+		if strings.HasPrefix(name, ".") || entry.Type()&fs.ModeSymlink != 0 {
+			continue
+		}
+		var data []byte
+		if entry.IsDir() {
+			data, err = readManifestDir(filepath.Join(dir, name))
+		} else {
+			switch filepath.Ext(name) {
+			case ".yaml", ".yml", ".json":
+				data, err = os.ReadFile(filepath.Join(dir, name))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			manifests = append(manifests, string(data))
+		}
+	}
+	return []byte(strings.Join(manifests, "\n---\n")), nil
+}
+
+// buildKustomization builds the kustomization of the directory, without plugins and loading the files of the
+// kustomization root only.
+func buildKustomization(dir string) ([]byte, error) {
+	opts := krusty.MakeDefaultOptions()
+	opts.LoadRestrictions = types.LoadRestrictionsRootOnly
+	opts.PluginConfig = types.DisabledPluginConfig()
+	resources, err := krusty.MakeKustomizer(opts).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, err
+	}
+	return resources.AsYaml()
+}
+
+func renderLocalChart(dir string, source GitOpsSource) ([]byte, error) {
+	ch, err := loader.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return renderChart(ch, source)
+}
+
+// renderChart renders the templates of the chart and of its enabled dependencies with the values of the source,
+// as helm install does.
+func renderChart(ch *chart.Chart, source GitOpsSource) ([]byte, error) {
+	if err := chartutil.ProcessDependenciesWithMerge(ch, source.Values); err != nil {
+		return nil, err
+	}
+	namespace := source.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	values, err := chartutil.ToRenderValues(ch, source.Values, chartutil.ReleaseOptions{Name: ch.Name(), Namespace: namespace, IsInstall: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := engine.Render(ch, values)
+	if err != nil {
+		return nil, err
+	}
+	var manifests []string
+	for _, name := range sortedKeys(templates) {
+		if strings.HasSuffix(name, "NOTES.txt") || strings.TrimSpace(templates[name]) == "" {
+			continue
+		}
+		manifests = append(manifests, templates[name])
+	}
+	return []byte(strings.Join(manifests, "\n---\n")), nil
+}
@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	ociclient "github.com/fluxcd/pkg/oci/client"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// ociPrefix is the scheme of the URLs of the OCI repositories and of the Helm repositories stored in OCI registries
+const ociPrefix = "oci://"
+
+// commitPattern matches the full or abbreviated hashes of commits
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// RemoteSourceResolver resolves the sources by fetching them: the Git repositories are cloned at the revision, the
+// charts downloaded from the Helm repositories, and the artifacts pulled from the OCI repositories, e.g. the ones
+// pushed with flux push artifact. The fetched sources are then read as by LocalSourceResolver.
+// Revisions given as semver ranges can't be resolved, except for the charts of Helm repositories, nor can the charts
+// of OCI registries without a version.
+type RemoteSourceResolver struct {
+	// HTTPClient downloads the indexes and the charts of the Helm repositories, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// GitAuth authenticates the clones of the Git repositories, they are cloned anonymously if nil
+	GitAuth transport.AuthMethod
+}
+
+func (r RemoteSourceResolver) httpClient() *http.Client {
+	if r.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return r.HTTPClient
+}
+
+// ResolveSource fetches the source into a temporary directory and returns its manifests, or the rendered templates of its chart.
+func (r RemoteSourceResolver) ResolveSource(source GitOpsSource) ([]byte, error) {
+	if source.Kind == HelmSource && !strings.HasPrefix(source.URL, ociPrefix) {
+		// the version can be a constraint, it is looked up in the index of the repository
+		return r.resolveHelmChart(source)
+	}
+	if isRevisionRange(source.Revision) || isRevisionRange(source.ChartVersion) {
+		return nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", "gitops-source-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	switch source.Kind {
+	case GitSource:
+		err = cloneGitSource(dir, source, r.GitAuth)
+	case HelmSource:
+		if source.ChartVersion == "" {
+			return nil, nil
+		}
+		// the chart is the first layer of the artifact, archived in a directory named as the chart
+		err = pullOCIArtifact(dir, strings.TrimSuffix(source.URL, "/")+"/"+source.Chart+":"+source.ChartVersion)
+	case OCISource:
+		err = pullOCIArtifact(dir, ociReference(source))
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return LocalSourceResolver{Repositories: map[string]string{source.URL: dir}}.ResolveSource(source)
+}
+
+// resolveHelmChart downloads the chart from the index of the Helm repository and renders it.
+func (r RemoteSourceResolver) resolveHelmChart(source GitOpsSource) ([]byte, error) {
+	client := r.httpClient()
+	chartURL, err := kubernetes.FindHelmChartURL(client, source.URL, source.Chart, source.ChartVersion)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(chartURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", chartURL, resp.Status)
+	}
+	ch, err := loader.LoadArchive(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return renderChart(ch, source)
+}
+
+// cloneGitSource clones the repository of the source into dir, at the revision of the source: a commit, a branch or
+// a tag, the default branch if none.
+func cloneGitSource(dir string, source GitOpsSource, auth transport.AuthMethod) error {
+	opts := &git.CloneOptions{URL: source.URL, Auth: auth, Depth: 1, SingleBranch: true}
+	revision := strings.TrimPrefix(source.Revision, "refs/heads/")
+	if revision == "" || revision == "HEAD" {
+		_, err := git.PlainClone(dir, false, opts)
+		return err
+	}
+	if commitPattern.MatchString(revision) {
+		// commits can't be fetched individually, the whole history is
+		opts.Depth, opts.SingleBranch, opts.NoCheckout = 0, false, true
+		repository, err := git.PlainClone(dir, false, opts)
+		if err != nil {
+			return err
+		}
+		hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+		if err != nil {
+			return err
+		}
+		worktree, err := repository.Worktree()
+		if err != nil {
+			return err
+		}
+		return worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
+	}
+
+	var err error
+	for _, ref := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(revision), plumbing.NewTagReferenceName(revision)} {
+		opts.ReferenceName = ref
+		if _, err = git.PlainClone(dir, false, opts); err == nil {
+			return nil
+		}
+		// start over from an empty directory if the reference wasn't found
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		if err := os.Mkdir(dir, 0700); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("cloning %s at %s: %w", source.URL, revision, err)
+}
+
+// ociReference returns the reference of the artifact of the OCI repository at its revision, a tag or a digest,
+// latest by default as for Flux.
+func ociReference(source GitOpsSource) string {
+	ref := strings.TrimPrefix(source.URL, ociPrefix)
+	switch {
+	case source.Revision == "":
+		return ref + ":latest"
+	case strings.Contains(source.Revision, ":"):
+		return ref + "@" + source.Revision
+	}
+	return ref + ":" + source.Revision
+}
+
+func pullOCIArtifact(dir, reference string) error {
+	_, err := ociclient.NewClient(ociclient.DefaultOptions()).Pull(context.Background(), strings.TrimPrefix(reference, ociPrefix), dir)
+	return err
+}
+
+// isRevisionRange reports whether the revision is a semver range, e.g. >=1.0.0 or 1.x, rather than a single version.
+func isRevisionRange(revision string) bool {
+	return strings.ContainsAny(revision, "<>=~^*| ,") || strings.HasSuffix(revision, ".x")
+}
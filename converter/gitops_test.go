@@ -0,0 +1,336 @@
+package converter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const testGitOpsManifest = `
+apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: web
+  namespace: argocd
+spec:
+  source:
+    repoURL: https://github.com/example/apps.git
+    targetRevision: main
+    path: ./web
+  destination:
+    namespace: web
+---
+apiVersion: source.toolkit.fluxcd.io/v1
+kind: GitRepository
+metadata:
+  name: apps
+  namespace: flux-system
+spec:
+  url: https://github.com/example/apps
+  ref:
+    branch: main
+---
+apiVersion: helm.toolkit.fluxcd.io/v2beta1
+kind: HelmRelease
+metadata:
+  name: cache
+  namespace: flux-system
+spec:
+  targetNamespace: cache
+  chart:
+    spec:
+      chart: ./charts/cache
+      sourceRef:
+        kind: GitRepository
+        name: apps
+  values:
+    replicas: 3
+---
+apiVersion: kustomize.toolkit.fluxcd.io/v1
+kind: Kustomization
+metadata:
+  name: infra
+  namespace: flux-system
+spec:
+  path: ./infra
+  sourceRef:
+    kind: GitRepository
+    name: missing
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+`
+
+func TestGitOpsToDesign(t *testing.T) {
+	repo := t.TempDir()
+	files := map[string]string{
+		"web/deployment.yaml":             "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 2\n",
+		"web/kustomization.yaml":          "resources:\n- deployment.yaml\n",
+		"charts/cache/Chart.yaml":         "apiVersion: v2\nname: cache\nversion: 0.1.0\n",
+		"charts/cache/values.yaml":        "replicas: 1\n",
+		"charts/cache/templates/sts.yaml": "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: {{ .Release.Name }}\nspec:\n  replicas: {{ .Values.replicas }}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	design, report, err := GitOpsToDesign([]byte(testGitOpsManifest), GitOpsOptions{
+		Name:    "stack",
+		Sources: LocalSourceResolver{Repositories: map[string]string{"https://github.com/example/apps": repo}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	components := map[string]int{}
+	for i, comp := range design.Components {
+		components[comp.Kind] = i
+	}
+	if len(design.Components) != 4 {
+		t.Fatalf("expected the deployed resources, the unresolved Kustomization and the Namespace, got %+v", design.Components)
+	}
+	web := design.Components[components["Deployment"]]
+	if web.Namespace != "web" || web.Metadata[GitOpsSourceMetadata] != "Application/argocd/web" {
+		t.Errorf("deployment = %+v", web)
+	}
+	cache := design.Components[components["StatefulSet"]]
+	if cache.Name != "cache" || cache.Namespace != "cache" || cache.Configuration["spec"].(map[string]interface{})["replicas"] != 3 {
+		t.Errorf("statefulset = %+v", cache)
+	}
+	if _, ok := components["Kustomization"]; !ok || len(report.Unsupported) != 1 {
+		t.Errorf("expected the Kustomization with a missing source to be kept and reported, got %+v", report)
+	}
+	if _, ok := components["GitRepository"]; ok {
+		t.Error("expected the sources not to be imported")
+	}
+}
+
+func TestLocalSourceResolver(t *testing.T) {
+	repo := t.TempDir()
+	files := map[string]string{
+		"base/deployment.yaml":        "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 2\n",
+		"base/kustomization.yaml":     "resources:\n- deployment.yaml\n",
+		"prod/kustomization.yaml":     "resources:\n- ../base\npatches:\n- path: replicas.yaml\n",
+		"prod/replicas.yaml":          "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 5\n",
+		"prod/tests/fixture.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: fixture\n",
+		"plain/service.yaml":          "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		"plain/db/kustomization.yaml": "resources:\n- ../../base\nnamePrefix: db-\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resolver := LocalSourceResolver{Repositories: map[string]string{"https://github.com/example/apps": repo}}
+
+	manifests, err := resolver.ResolveSource(GitOpsSource{URL: "https://github.com/example/apps", Path: "./prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	design, _, err := KubernetesToDesign(manifests, KubernetesOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(design.Components) != 1 || design.Components[0].Configuration["spec"].(map[string]interface{})["replicas"] != 5 {
+		t.Errorf("expected the patched Deployment only, got:\n%s", manifests)
+	}
+
+	manifests, err = resolver.ResolveSource(GitOpsSource{URL: "https://github.com/example/apps", Path: "plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	design, _, err = KubernetesToDesign(manifests, KubernetesOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, comp := range design.Components {
+		names[comp.Kind+"/"+comp.Name] = true
+	}
+	if len(design.Components) != 2 || !names["Service/web"] || !names["Deployment/db-web"] {
+		t.Errorf("expected the Service and the built kustomization of the subdirectory, got:\n%s", manifests)
+	}
+
+	if _, err := resolver.ResolveSource(GitOpsSource{URL: "https://github.com/example/apps", Path: "../outside"}); err == nil {
+		t.Error("expected an error for a path outside the repository")
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocalSourceResolverSymlinks(t *testing.T) {
+	outside := t.TempDir()
+	writeFiles(t, outside, map[string]string{"secret.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n"})
+	repo := t.TempDir()
+	writeFiles(t, repo, map[string]string{"apps/service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"})
+	if err := os.Symlink(filepath.Join(outside, "secret.yaml"), filepath.Join(repo, "apps", "secret.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(repo, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	resolver := LocalSourceResolver{Repositories: map[string]string{"https://github.com/example/apps": repo}}
+
+	manifests, err := resolver.ResolveSource(GitOpsSource{URL: "https://github.com/example/apps", Path: "apps"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(manifests), "Secret") || !strings.Contains(string(manifests), "Service") {
+		t.Errorf("expected the linked file to be skipped, got:\n%s", manifests)
+	}
+	if _, err := resolver.ResolveSource(GitOpsSource{URL: "https://github.com/example/apps", Path: "linked"}); err == nil {
+		t.Error("expected an error for a directory linking outside the repository")
+	}
+}
+
+func TestRenderChartDependencies(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Chart.yaml":                        "apiVersion: v2\nname: app\nversion: 0.1.0\ndependencies:\n- name: cache\n  version: 0.1.0\n  condition: cache.enabled\n",
+		"values.yaml":                       "cache:\n  enabled: false\n",
+		"templates/cm.yaml":                 "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n",
+		"charts/cache/Chart.yaml":           "apiVersion: v2\nname: cache\nversion: 0.1.0\n",
+		"charts/cache/templates/cache.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: cache\n",
+	})
+
+	manifests, err := renderLocalChart(dir, GitOpsSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(manifests), "kind: Service") {
+		t.Errorf("expected the disabled subchart not to be rendered, got:\n%s", manifests)
+	}
+	manifests, err = renderLocalChart(dir, GitOpsSource{Values: map[string]interface{}{"cache": map[string]interface{}{"enabled": true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(manifests), "kind: Service") {
+		t.Errorf("expected the enabled subchart to be rendered, got:\n%s", manifests)
+	}
+}
+
+func TestRemoteSourceResolverGit(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(replicas string) plumbing.Hash {
+		writeFiles(t, dir, map[string]string{"web/deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: " + replicas + "\n"})
+		if _, err := worktree.Add("web"); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := worktree.Commit("replicas "+replicas, &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	first := commit("1")
+	if _, err := repository.CreateTag("v1", first, nil); err != nil {
+		t.Fatal(err)
+	}
+	commit("2")
+
+	resolver := RemoteSourceResolver{}
+	for revision, want := range map[string]string{"": "replicas: 2", "v1": "replicas: 1", first.String(): "replicas: 1"} {
+		manifests, err := resolver.ResolveSource(GitOpsSource{Kind: GitSource, URL: dir, Revision: revision, Path: "web"})
+		if err != nil {
+			t.Fatalf("revision %q: %v", revision, err)
+		}
+		if !strings.Contains(string(manifests), want) {
+			t.Errorf("revision %q: expected %s, got:\n%s", revision, want, manifests)
+		}
+	}
+	if _, err := resolver.ResolveSource(GitOpsSource{Kind: GitSource, URL: dir, Revision: "missing", Path: "web"}); err == nil {
+		t.Error("expected an error for a missing revision")
+	}
+	if manifests, err := resolver.ResolveSource(GitOpsSource{Kind: GitSource, URL: dir, Revision: ">=1.0.0"}); err != nil || manifests != nil {
+		t.Errorf("expected a semver range not to be resolved, got %q, %v", manifests, err)
+	}
+}
+
+func TestRemoteSourceResolverHelm(t *testing.T) {
+	chartDir := t.TempDir()
+	writeFiles(t, chartDir, map[string]string{
+		"cache/Chart.yaml":         "apiVersion: v2\nname: cache\nversion: 1.2.0\n",
+		"cache/values.yaml":        "replicas: 1\n",
+		"cache/templates/sts.yaml": "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: {{ .Release.Name }}\nspec:\n  replicas: {{ .Values.replicas }}\n",
+	})
+	ch, err := loader.Load(filepath.Join(chartDir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := chartutil.Save(ch, chartDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "apiVersion: v1\nentries:\n  cache:\n  - name: cache\n    version: 1.2.0\n    urls:\n    - charts/cache-1.2.0.tgz\n")
+	})
+	mux.HandleFunc("/charts/cache-1.2.0.tgz", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, archive)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resolver := RemoteSourceResolver{HTTPClient: srv.Client()}
+	manifests, err := resolver.ResolveSource(GitOpsSource{Kind: HelmSource, URL: srv.URL, Chart: "cache", ChartVersion: "1.x", Namespace: "cache", Values: map[string]interface{}{"replicas": 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(manifests), "name: cache") || !strings.Contains(string(manifests), "replicas: 3") {
+		t.Errorf("expected the chart rendered with the values, got:\n%s", manifests)
+	}
+}
+
+func TestOCIReference(t *testing.T) {
+	tests := map[string]string{
+		"":                "ghcr.io/example/manifests:latest",
+		"v1.0.0":          "ghcr.io/example/manifests:v1.0.0",
+		"sha256:0123abcd": "ghcr.io/example/manifests@sha256:0123abcd",
+	}
+	for revision, want := range tests {
+		if got := ociReference(GitOpsSource{Kind: OCISource, URL: "oci://ghcr.io/example/manifests", Revision: revision}); got != want {
+			t.Errorf("revision %q: got %s, want %s", revision, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// HelmOptions configure the export of a design to a Helm chart.
+type HelmOptions struct {
+	// Name defaults to the name of the design
+	Name string
+	// Version defaults to the version of the design
+	Version    string
+	AppVersion string
+}
+
+// DesignIDAnnotation is set in the Chart.yaml annotations with the id of the exported design
+const DesignIDAnnotation = "meshery.io/design-id"
+
+var nonAlphanumericRun = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// DesignToHelmChart renders the design into a standalone chart, with one template per component.
+// The replicas, the container images and the Service types are extracted to values.yaml, under
+// components.<component key>, e.g. components.webDeployment.replicas.
+// Use chartutil.SaveDir or chartutil.Save to write the chart.
+func DesignToHelmChart(design v1beta1.Design, opts HelmOptions) (*chart.Chart, error) {
+	name := opts.Name
+	if name == "" {
+		name = kubernetesName(design.Name)
+	}
+	version := opts.Version
+	if version == "" {
+		version = design.Version
+	}
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:  chart.APIVersionV2,
+			Name:        name,
+			Version:     version,
+			AppVersion:  opts.AppVersion,
+			Description: fmt.Sprintf("Helm chart of the Meshery design %s", design.Name),
+			Type:        "application",
+			Annotations: map[string]string{DesignIDAnnotation: design.ID.String()},
+		},
+	}
+
+	components := make(map[string]interface{})
+	for _, comp := range design.Components {
+		key := valuesKey(comp)
+		obj := ComponentToKubernetes(comp)
+		values := make(map[string]interface{})
+		placeholders := extractValues(obj, key, values)
+		if len(values) > 0 {
+			components[key] = values
+		}
+
+		data, err := marshalYAML(obj)
+		if err != nil {
+			return nil, ErrExportDesign(err)
+		}
+		// the manifests may hold template delimiters, e.g. the Go templates of a ConfigMap, which are kept as text
+		template := strings.ReplaceAll(string(data), "{{", `{{"{{"}}`)
+		for placeholder, expr := range placeholders {
+			template = strings.ReplaceAll(template, placeholder, expr)
+		}
+		ch.Templates = append(ch.Templates, &chart.File{
+			Name: "templates/" + resourceFileName(comp),
+			Data: []byte(template),
+		})
+	}
+
+	ch.Values = map[string]interface{}{"components": components}
+	valuesData, err := marshalYAML(ch.Values)
+	if err != nil {
+		return nil, ErrExportDesign(err)
+	}
+	ch.Raw = []*chart.File{{Name: chartutil.ValuesfileName, Data: valuesData}}
+	if err := ch.Validate(); err != nil {
+		return nil, ErrExportDesign(err)
+	}
+	return ch, nil
+}
+
+// valuesKey is the lower camel case key of the component in values.yaml, e.g. webDeployment
+func valuesKey(comp v1beta1.DesignComponent) string {
+	parts := nonAlphanumericRun.Split(comp.Name+" "+comp.Kind, -1)
+	var b strings.Builder
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString(strings.ToLower(part[:1]) + part[1:])
+		} else {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// extractValues moves the parameters of the object to values and replaces them by placeholders.
+// It returns the template expressions by placeholder.
+func extractValues(obj map[string]interface{}, key string, values map[string]interface{}) map[string]string {
+	placeholders := make(map[string]string)
+	replace := func(parent map[string]interface{}, field, valueName string, quote bool) {
+		value, ok := parent[field]
+		if !ok {
+			return
+		}
+		values[valueName] = value
+		placeholder := fmt.Sprintf("__meshery_value_%s_%s__", key, valueName)
+		parent[field] = placeholder
+		expr := fmt.Sprintf("{{ .Values.components.%s.%s }}", key, valueName)
+		if quote {
+			expr = fmt.Sprintf("{{ .Values.components.%s.%s | quote }}", key, valueName)
+		}
+		placeholders[placeholder] = expr
+	}
+
+	spec, _ := obj["spec"].(map[string]interface{})
+	if spec == nil {
+		return placeholders
+	}
+	replace(spec, "replicas", "replicas", false)
+	if obj["kind"] == "Service" {
+		replace(spec, "type", "serviceType", true)
+	}
+
+	podSpec := spec
+	if template, ok := spec["template"].(map[string]interface{}); ok {
+		podSpec, _ = template["spec"].(map[string]interface{})
+	}
+	containers, _ := podSpec["containers"].([]interface{})
+	for i, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		valueName := "image"
+		if len(containers) > 1 {
+			valueName = fmt.Sprintf("image%d", i)
+			if name, ok := container["name"].(string); ok && name != "" {
+				valueName = valuesKey(v1beta1.DesignComponent{Name: name, Kind: "image"})
+			}
+		}
+		replace(container, "image", valueName, true)
+	}
+	return placeholders
+}
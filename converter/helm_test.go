@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+func TestDesignToHelmChart(t *testing.T) {
+	design, _, err := ComposeToDesign([]byte(testCompose), ComposeOptions{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := DesignToHelmChart(design, HelmOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ch.Templates) != len(design.Components) {
+		t.Fatalf("got %d templates, want %d", len(ch.Templates), len(design.Components))
+	}
+	db := ch.Values["components"].(map[string]interface{})["dbDeployment"].(map[string]interface{})
+	if db["replicas"] != 2 || db["image"] != "postgres:16" {
+		t.Errorf("unexpected values for db %v", db)
+	}
+
+	vals, err := chartutil.ToRenderValues(ch, map[string]interface{}{
+		"components": map[string]interface{}{"dbDeployment": map[string]interface{}{"replicas": 3}},
+	}, chartutil.ReleaseOptions{Name: "app", Namespace: "default"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := engine.Render(ch, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := rendered["app/templates/db-deployment.yaml"]
+	if !strings.Contains(manifest, "replicas: 3") || !strings.Contains(manifest, `image: "postgres:16"`) {
+		t.Errorf("unexpected rendered manifest:\n%s", manifest)
+	}
+}
+
+func TestDesignToHelmChartEscapesTemplates(t *testing.T) {
+	design := v1beta1.NewDesign("alerts")
+	design.Version = "0.1.0"
+	design.Components = []v1beta1.DesignComponent{{
+		Name: "templates", Kind: "ConfigMap", APIVersion: "v1",
+		Configuration: map[string]interface{}{"data": map[string]interface{}{"alert.tmpl": "{{ .Labels.alertname }} {{{ raw }}}"}},
+	}}
+	ch, err := DesignToHelmChart(design, HelmOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals, err := chartutil.ToRenderValues(ch, nil, chartutil.ReleaseOptions{Name: "alerts", Namespace: "default"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := engine.Render(ch, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := rendered["alerts/templates/templates-configmap.yaml"]
+	if !strings.Contains(manifest, "{{ .Labels.alertname }} {{{ raw }}}") {
+		t.Errorf("expected the template delimiters to be kept, got:\n%s", manifest)
+	}
+}
@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// KubernetesModel is the model of the components which could not be resolved
+	KubernetesModel = "kubernetes"
+	// GenericComponentMetadata is set in the metadata of the components which could not be resolved
+	GenericComponentMetadata = "isGeneric"
+)
+
+// design and component ids are derived from the identity of the resources, so that importing a manifest twice yields the same design
+var componentIDNamespace = uuid.MustParse("6ba7b811-9dad-11d1-80b4-00c04fd430c8")
+
+// KubernetesOptions configure the conversion of Kubernetes manifests.
+type KubernetesOptions struct {
+	// Name is the name of the design
+	Name string
+	// Resolver resolves the kinds of the manifest to component definitions.
+	// If nil, or if a kind cannot be resolved, the resource is kept as a generic component.
+	Resolver ComponentResolver
+}
+
+// KubernetesToDesign converts Kubernetes manifests, as a YAML or JSON stream of objects and Lists, into a design.
+// The configuration of a component is its object without apiVersion, kind, metadata and status.
+func KubernetesToDesign(manifest []byte, opts KubernetesOptions) (v1beta1.Design, Report, error) {
+	design := v1beta1.NewDesign(opts.Name)
+	report := Report{}
+
+	objects, err := decodeKubernetesObjects(manifest)
+	if err != nil {
+		return design, report, ErrParseManifest(err)
+	}
+	for i, obj := range objects {
+		comp, err := kubernetesObjectToComponent(obj, opts.Resolver)
+		if err != nil {
+			return design, report, ErrParseManifest(fmt.Errorf("document %d: %w", i, err))
+		}
+		path := fmt.Sprintf("%s/%s", comp.Kind, comp.Name)
+		if generic, _ := comp.Metadata[GenericComponentMetadata].(bool); generic {
+			report.warn(path, "no component definition found for %s %s, imported as a generic component", comp.APIVersion, comp.Kind)
+		}
+		if _, ok := obj["status"]; ok {
+			report.unsupported(path+"/status", "the status is not part of the design")
+		}
+		design.Components = append(design.Components, comp)
+	}
+	design.ID = designID(design)
+	return design, report, nil
+}
+
+// designID derives the id of the design from its name and the ids of its components.
+func designID(design v1beta1.Design) uuid.UUID {
+	key := []string{design.Name}
+	for _, comp := range design.Components {
+		key = append(key, comp.ID.String())
+	}
+	return uuid.NewSHA1(componentIDNamespace, []byte(strings.Join(key, "/")))
+}
+
+// decodeKubernetesObjects returns the objects of the stream, with the items of the Lists flattened
+func decodeKubernetesObjects(manifest []byte) ([]map[string]interface{}, error) {
+	objects := make([]map[string]interface{}, 0)
+	dec := yaml.NewDecoder(bytes.NewReader(manifest))
+	for {
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objects = append(objects, flattenList(obj)...)
+	}
+	return objects, nil
+}
+
+func flattenList(obj map[string]interface{}) []map[string]interface{} {
+	kind, _ := obj["kind"].(string)
+	items, isList := obj["items"].([]interface{})
+	if !isList || !strings.HasSuffix(kind, "List") {
+		return []map[string]interface{}{obj}
+	}
+	objects := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if itemObj, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, flattenList(itemObj)...)
+		}
+	}
+	return objects
+}
+
+func kubernetesObjectToComponent(obj map[string]interface{}, resolver ComponentResolver) (v1beta1.DesignComponent, error) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	if apiVersion == "" || kind == "" {
+		return v1beta1.DesignComponent{}, fmt.Errorf("apiVersion and kind are required")
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	comp := v1beta1.DesignComponent{
+		ID:            uuid.NewSHA1(componentIDNamespace, []byte(strings.Join([]string{apiVersion, kind, namespace, name}, "/"))),
+		Name:          name,
+		Namespace:     namespace,
+		Labels:        toStringMap(metadata["labels"]),
+		Annotations:   toStringMap(metadata["annotations"]),
+		DisplayName:   name,
+		Kind:          kind,
+		APIVersion:    apiVersion,
+		Configuration: make(map[string]interface{}),
+		Metadata:      make(map[string]interface{}),
+	}
+	for key, value := range obj {
+		switch key {
+		case "apiVersion", "kind", "metadata", "status":
+		default:
+			comp.Configuration[key] = value
+		}
+	}
+
+	var def *v1beta1.ComponentDefinition
+	if resolver != nil {
+		var err error
+		def, err = resolver.ResolveComponent(apiVersion, kind)
+		if err != nil {
+			return comp, err
+		}
+	}
+	if def == nil {
+		comp.Model = KubernetesModel
+		comp.Metadata[GenericComponentMetadata] = true
+		return comp, nil
+	}
+	comp.Model = def.Model.Name
+	comp.ModelVersion = def.Model.Model.Version
+	if comp.ModelVersion == "" {
+		comp.ModelVersion = def.Model.Version
+	}
+	return comp, nil
+}
+
+func toStringMap(value interface{}) map[string]string {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+const testManifest = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+    namespace: default
+    labels:
+      app: web
+  spec:
+    ports:
+    - port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+status:
+  readyReplicas: 2
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+spec: {}
+`
+
+func TestKubernetesToDesign(t *testing.T) {
+	resolver := StaticResolver{}
+	for _, kind := range []struct{ apiVersion, kind string }{{"v1", "Service"}, {"apps/v1", "Deployment"}} {
+		def := v1beta1.ComponentDefinition{}
+		def.Component.Kind = kind.kind
+		def.Component.Version = kind.apiVersion
+		def.Model.Name = "kubernetes"
+		def.Model.Model.Version = "v1.29.0"
+		resolver = append(resolver, def)
+	}
+
+	design, report, err := KubernetesToDesign([]byte(testManifest), KubernetesOptions{Name: "web", Resolver: resolver})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(design.Components) != 3 {
+		t.Fatalf("got %d components, want 3", len(design.Components))
+	}
+	svc, deploy, widget := design.Components[0], design.Components[1], design.Components[2]
+	if svc.Kind != "Service" || svc.Namespace != "default" || svc.Labels["app"] != "web" || svc.ModelVersion != "v1.29.0" {
+		t.Errorf("unexpected service component %+v", svc)
+	}
+	if _, ok := deploy.Configuration["status"]; ok || deploy.Configuration["spec"] == nil {
+		t.Errorf("unexpected deployment configuration %+v", deploy.Configuration)
+	}
+	if generic, _ := widget.Metadata[GenericComponentMetadata].(bool); !generic {
+		t.Errorf("the Widget should be a generic component")
+	}
+	if len(report.Warnings) != 1 || len(report.Unsupported) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	again, _, _ := KubernetesToDesign([]byte(testManifest), KubernetesOptions{Name: "web", Resolver: resolver})
+	if again.Components[0].ID != svc.ID {
+		t.Errorf("component ids should be stable across imports")
+	}
+	if again.ID != design.ID {
+		t.Errorf("the design id should be stable across imports")
+	}
+	renamed, _, _ := KubernetesToDesign([]byte(testManifest), KubernetesOptions{Name: "renamed", Resolver: resolver})
+	if renamed.ID == design.ID {
+		t.Errorf("designs with different names should have different ids")
+	}
+}
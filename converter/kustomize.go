@@ -0,0 +1,205 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils/diff"
+)
+
+const kustomizationAPIVersion = "kustomize.config.k8s.io/v1beta1"
+
+// FileSet maps slash separated paths to file contents.
+type FileSet map[string][]byte
+
+// Write writes the files under dir, creating the directories as needed.
+func (fs FileSet) Write(dir string) error {
+	for name, data := range fs {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return ErrExportDesign(err)
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			return ErrExportDesign(err)
+		}
+	}
+	return nil
+}
+
+type kustomization struct {
+	APIVersion string              `yaml:"apiVersion"`
+	Kind       string              `yaml:"kind"`
+	Namespace  string              `yaml:"namespace,omitempty"`
+	Resources  []string            `yaml:"resources,omitempty"`
+	Patches    []kustomizationPath `yaml:"patches,omitempty"`
+}
+
+type kustomizationPath struct {
+	Path   string               `yaml:"path"`
+	Target *kustomizationTarget `yaml:"target,omitempty"`
+}
+
+// kustomizationTarget selects the resource a JSON 6902 patch applies to
+type kustomizationTarget struct {
+	Group     string `yaml:"group,omitempty"`
+	Version   string `yaml:"version"`
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// DesignToKustomize exports the design as a kustomization base, in base/, and an overlay per variant, in overlays/<name>/.
+// A variant is a copy of the design configured for an environment: the components are matched by kind and name, and
+// the differences with the base become JSON 6902 patches, which, unlike strategic merge patches, remove the items
+// removed from lists with merge keys, e.g. containers. Components only in the variant are resources of its
+// overlay, components missing from it are deleted by a patch. If all the components of a variant share a namespace
+// different from the base, it is set as the namespace of the overlay.
+func DesignToKustomize(base v1beta1.Design, variants map[string]v1beta1.Design) (FileSet, error) {
+	files := make(FileSet)
+	baseResources := make([]string, 0, len(base.Components))
+	for _, comp := range sortedComponents(base.Components) {
+		name := resourceFileName(comp)
+		if err := files.addYAML("base/"+name, ComponentToKubernetes(comp)); err != nil {
+			return nil, err
+		}
+		baseResources = append(baseResources, name)
+	}
+	if err := files.addYAML("base/kustomization.yaml", kustomization{
+		APIVersion: kustomizationAPIVersion,
+		Kind:       "Kustomization",
+		Resources:  baseResources,
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, variantName := range sortedKeys(variants) {
+		if err := files.addOverlay(base, variants[variantName], kubernetesName(variantName)); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func (fs FileSet) addOverlay(base, variant v1beta1.Design, name string) error {
+	dir := path.Join("overlays", name)
+	k := kustomization{
+		APIVersion: kustomizationAPIVersion,
+		Kind:       "Kustomization",
+		Resources:  []string{"../../base"},
+		Namespace:  overlayNamespace(base, variant),
+	}
+
+	baseComponents := make(map[string]v1beta1.DesignComponent)
+	for _, comp := range base.Components {
+		baseComponents[componentKey(comp)] = comp
+	}
+	seen := make(map[string]bool)
+	for _, comp := range sortedComponents(variant.Components) {
+		key := componentKey(comp)
+		seen[key] = true
+		baseComp, ok := baseComponents[key]
+		if !ok {
+			if err := fs.addYAML(path.Join(dir, resourceFileName(comp)), ComponentToKubernetes(comp)); err != nil {
+				return err
+			}
+			k.Resources = append(k.Resources, resourceFileName(comp))
+			continue
+		}
+		if k.Namespace != "" {
+			// the namespace is set by the overlay
+			comp.Namespace = baseComp.Namespace
+		}
+		ops := diff.JSONPatch(ComponentToKubernetes(baseComp), ComponentToKubernetes(comp))
+		if len(ops) == 0 {
+			continue
+		}
+		if err := fs.addJSONPatch(dir, &k, baseComp, ops); err != nil {
+			return err
+		}
+	}
+	for _, comp := range sortedComponents(base.Components) {
+		if seen[componentKey(comp)] {
+			continue
+		}
+		if err := fs.addPatch(dir, &k, comp, map[string]interface{}{"$patch": "delete"}); err != nil {
+			return err
+		}
+	}
+	return fs.addYAML(path.Join(dir, "kustomization.yaml"), k)
+}
+
+func (fs FileSet) addJSONPatch(dir string, k *kustomization, comp v1beta1.DesignComponent, ops []diff.Operation) error {
+	group, version := "", comp.APIVersion
+	if i := strings.LastIndex(comp.APIVersion, "/"); i >= 0 {
+		group, version = comp.APIVersion[:i], comp.APIVersion[i+1:]
+	}
+	name := path.Join("patches", resourceFileName(comp))
+	k.Patches = append(k.Patches, kustomizationPath{Path: name, Target: &kustomizationTarget{
+		Group:     group,
+		Version:   version,
+		Kind:      comp.Kind,
+		Name:      comp.Name,
+		Namespace: comp.Namespace,
+	}})
+	return fs.addYAML(path.Join(dir, name), ops)
+}
+
+func (fs FileSet) addPatch(dir string, k *kustomization, comp v1beta1.DesignComponent, patch map[string]interface{}) error {
+	metadata, _ := patch["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["name"] = comp.Name
+	if comp.Namespace != "" {
+		metadata["namespace"] = comp.Namespace
+	}
+	patch["metadata"] = metadata
+	patch["apiVersion"] = comp.APIVersion
+	patch["kind"] = comp.Kind
+
+	name := path.Join("patches", resourceFileName(comp))
+	k.Patches = append(k.Patches, kustomizationPath{Path: name})
+	return fs.addYAML(path.Join(dir, name), patch)
+}
+
+func (fs FileSet) addYAML(name string, value interface{}) error {
+	data, err := marshalYAML(value)
+	if err != nil {
+		return ErrExportDesign(err)
+	}
+	fs[name] = data
+	return nil
+}
+
+func overlayNamespace(base, variant v1beta1.Design) string {
+	namespace := ""
+	for i, comp := range variant.Components {
+		if i > 0 && comp.Namespace != namespace {
+			return ""
+		}
+		namespace = comp.Namespace
+	}
+	for _, comp := range base.Components {
+		if comp.Namespace == namespace {
+			return ""
+		}
+	}
+	return namespace
+}
+
+func componentKey(comp v1beta1.DesignComponent) string {
+	return fmt.Sprintf("%s/%s/%s", comp.APIVersion, comp.Kind, comp.Name)
+}
+
+func sortedComponents(components []v1beta1.DesignComponent) []v1beta1.DesignComponent {
+	sorted := append([]v1beta1.DesignComponent{}, components...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return resourceFileName(sorted[i]) < resourceFileName(sorted[j])
+	})
+	return sorted
+}
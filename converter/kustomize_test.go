@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestDesignToKustomize(t *testing.T) {
+	base, _, err := ComposeToDesign([]byte(testCompose), ComposeOptions{Name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	production, _, err := ComposeToDesign([]byte(strings.ReplaceAll(testCompose, "replicas: 2", "replicas: 5")), ComposeOptions{Name: "app", Namespace: "production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := DesignToKustomize(base, map[string]v1beta1.Design{"production": production})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"base/kustomization.yaml", "base/db-deployment.yaml", "overlays/production/kustomization.yaml", "overlays/production/patches/db-deployment.yaml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s was not generated", name)
+		}
+	}
+	overlay := string(files["overlays/production/kustomization.yaml"])
+	if !strings.Contains(overlay, "namespace: production") || strings.Contains(overlay, "web-deployment") {
+		t.Errorf("unexpected overlay:\n%s", overlay)
+	}
+	patch := string(files["overlays/production/patches/db-deployment.yaml"])
+	if !strings.Contains(patch, "path: /spec/replicas") || !strings.Contains(patch, "value: 5") || strings.Contains(patch, "image") {
+		t.Errorf("unexpected patch:\n%s", patch)
+	}
+}
+
+func TestDesignToKustomizeRemovesListItems(t *testing.T) {
+	deployment := func(containers ...string) v1beta1.DesignComponent {
+		list := make([]interface{}, 0, len(containers))
+		for _, name := range containers {
+			list = append(list, map[string]interface{}{"name": name, "image": name + ":1"})
+		}
+		return v1beta1.DesignComponent{Name: "web", Kind: "Deployment", APIVersion: "apps/v1", Namespace: "default", Configuration: map[string]interface{}{
+			"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"containers": list}}},
+		}}
+	}
+	base := v1beta1.NewDesign("app")
+	base.Components = []v1beta1.DesignComponent{deployment("web", "proxy")}
+	variant := v1beta1.NewDesign("app")
+	variant.Components = []v1beta1.DesignComponent{deployment("web")}
+	// the namespace is set by the overlay, the patch targets the resource of the base
+	variant.Components[0].Namespace = "production"
+
+	files, err := DesignToKustomize(base, map[string]v1beta1.Design{"production": variant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := filesys.MakeFsInMemory()
+	for name, data := range files {
+		if err := fsys.WriteFile("/"+name, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fsys, "/overlays/production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, err := resources.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(built), "image: web:1") || !strings.Contains(string(built), "namespace: production") || strings.Contains(string(built), "proxy") {
+		t.Errorf("expected the proxy container to be removed, got:\n%s", built)
+	}
+}
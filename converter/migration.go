@@ -0,0 +1,338 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+)
+
+// DesignSchemaV1alpha2 is the schema version of the designs saved with services, before components and relationships
+const DesignSchemaV1alpha2 = "designs.meshery.io/v1alpha2"
+
+// MigrationFunc converts a design document, decoded from JSON or YAML, from a schema version to the next.
+// It records in the report what it changed.
+type MigrationFunc func(doc map[string]interface{}, report *MigrationStep) (map[string]interface{}, error)
+
+// Migration converts designs between two schema versions.
+type Migration struct {
+	From    string
+	To      string
+	Migrate MigrationFunc
+}
+
+// MigrationStep is the report of a Migration.
+type MigrationStep struct {
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Changes []Finding `json:"changes,omitempty"`
+}
+
+func (s *MigrationStep) change(path, format string, args ...interface{}) {
+	s.Changes = append(s.Changes, Finding{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// MigrationReport lists the migrations applied to a design.
+type MigrationReport struct {
+	From  string          `json:"from"`
+	To    string          `json:"to"`
+	Steps []MigrationStep `json:"steps,omitempty"`
+}
+
+// MigrationRegistry finds and applies the chain of migrations between two schema versions.
+type MigrationRegistry struct {
+	migrations map[string]map[string]Migration
+}
+
+// NewMigrationRegistry returns a registry with the migrations between the known design schema versions.
+func NewMigrationRegistry() *MigrationRegistry {
+	r := &MigrationRegistry{migrations: make(map[string]map[string]Migration)}
+	r.Register(Migration{From: DesignSchemaV1alpha2, To: v1beta1.DesignSchemaVersion, Migrate: migrateV1alpha2ToV1beta1})
+	r.Register(Migration{From: v1beta1.DesignSchemaVersion, To: DesignSchemaV1alpha2, Migrate: migrateV1beta1ToV1alpha2})
+	return r
+}
+
+// Register adds the migration, replacing the one registered between the same versions.
+func (r *MigrationRegistry) Register(m Migration) {
+	if r.migrations[m.From] == nil {
+		r.migrations[m.From] = make(map[string]Migration)
+	}
+	r.migrations[m.From][m.To] = m
+}
+
+// path returns the shortest chain of migrations from a version to another
+func (r *MigrationRegistry) path(from, to string) ([]Migration, bool) {
+	previous := map[string]Migration{}
+	visited := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			chain := make([]Migration, 0)
+			for v := to; v != from; v = previous[v].From {
+				chain = append([]Migration{previous[v]}, chain...)
+			}
+			return chain, true
+		}
+		for _, next := range sortedKeys(r.migrations[current]) {
+			if !visited[next] {
+				visited[next] = true
+				previous[next] = r.migrations[current][next]
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil, false
+}
+
+// Migrate converts the design, JSON or YAML encoded, to the schema version to.
+func (r *MigrationRegistry) Migrate(design []byte, to string) (map[string]interface{}, MigrationReport, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(design, &doc); err != nil {
+		return nil, MigrationReport{}, ErrMigrateDesign(err)
+	}
+	from := DesignSchemaVersionOf(doc)
+	report := MigrationReport{From: from, To: to}
+	if from == "" {
+		return nil, report, ErrMigrateDesign(fmt.Errorf("the schema version of the design cannot be determined"))
+	}
+	chain, ok := r.path(from, to)
+	if !ok {
+		return nil, report, ErrMigrateDesign(fmt.Errorf("no migration from %s to %s", from, to))
+	}
+	for _, m := range chain {
+		step := MigrationStep{From: m.From, To: m.To}
+		var err error
+		if doc, err = m.Migrate(doc, &step); err != nil {
+			return nil, report, ErrMigrateDesign(err)
+		}
+		report.Steps = append(report.Steps, step)
+	}
+	return doc, report, nil
+}
+
+// MigrateToLatest converts the design to the current schema version, so that designs saved with older versions can be loaded.
+func (r *MigrationRegistry) MigrateToLatest(design []byte) (v1beta1.Design, MigrationReport, error) {
+	var out v1beta1.Design
+	doc, report, err := r.Migrate(design, v1beta1.DesignSchemaVersion)
+	if err != nil {
+		return out, report, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return out, report, ErrMigrateDesign(err)
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, report, ErrMigrateDesign(err)
+	}
+	return out, report, nil
+}
+
+// DesignSchemaVersionOf returns the schema version of the design document.
+// Designs without schemaVersion but with services are v1alpha2 designs.
+func DesignSchemaVersionOf(doc map[string]interface{}) string {
+	if version, ok := doc["schemaVersion"].(string); ok && version != "" {
+		return version
+	}
+	if _, ok := doc["services"]; ok {
+		return DesignSchemaV1alpha2
+	}
+	return ""
+}
+
+// v1alpha2 services are keyed by name and hold:
+// name, type, apiVersion, namespace, model, version (of the model), id, labels, annotations, settings, traits and dependsOn.
+// The parent of a service is traits.meshmap.parent, it becomes a hierarchical relationship in v1beta1.
+
+func migrateV1alpha2ToV1beta1(doc map[string]interface{}, step *MigrationStep) (map[string]interface{}, error) {
+	services, _ := doc["services"].(map[string]interface{})
+	out := map[string]interface{}{
+		"id":            doc["id"],
+		"name":          doc["name"],
+		"schemaVersion": v1beta1.DesignSchemaVersion,
+		"version":       doc["version"],
+	}
+	if out["version"] == nil {
+		out["version"] = "0.0.1"
+	}
+
+	components := make([]interface{}, 0, len(services))
+	relationships := make([]interface{}, 0)
+	ids := make(map[string]string)
+	for _, key := range sortedKeys(services) {
+		service, ok := services[key].(map[string]interface{})
+		if !ok {
+			step.change("services."+key, "dropped, the service is not an object")
+			continue
+		}
+		name, _ := service["name"].(string)
+		if name == "" {
+			name = key
+		}
+		id, _ := service["id"].(string)
+		if id == "" {
+			id = uuid.NewSHA1(componentIDNamespace, []byte(key)).String()
+			step.change("services."+key+".id", "generated the id %s", id)
+		}
+		ids[key] = id
+		ids[name] = id
+		comp := map[string]interface{}{
+			"id":           id,
+			"name":         name,
+			"kind":         service["type"],
+			"apiVersion":   service["apiVersion"],
+			"model":        service["model"],
+			"modelVersion": service["version"],
+		}
+		copyFields(service, comp, "namespace", "labels", "annotations", "dependsOn", "displayName")
+		if settings, ok := service["settings"]; ok {
+			comp["configuration"] = settings
+			step.change("services."+key+".settings", "renamed to components[%d].configuration", len(components))
+		}
+		if traits, ok := service["traits"].(map[string]interface{}); ok {
+			metadata := make(map[string]interface{})
+			for _, traitName := range sortedKeys(traits) {
+				metadata[traitName] = traits[traitName]
+			}
+			comp["metadata"] = metadata
+			step.change("services."+key+".traits", "moved to components[%d].metadata", len(components))
+		}
+		components = append(components, comp)
+	}
+	step.change("services", "converted to components")
+
+	for _, key := range sortedKeys(services) {
+		service, _ := services[key].(map[string]interface{})
+		traits, _ := service["traits"].(map[string]interface{})
+		meshmap, _ := traits["meshmap"].(map[string]interface{})
+		parent, _ := meshmap["parent"].(string)
+		if parent == "" {
+			continue
+		}
+		parentID, ok := ids[parent]
+		if !ok {
+			parentID = parent
+		}
+		relationships = append(relationships, map[string]interface{}{
+			"id":      uuid.NewSHA1(componentIDNamespace, []byte(parentID+"/"+ids[key])).String(),
+			"kind":    "Hierarchical",
+			"type":    "parent",
+			"subType": "inventory",
+			"from":    []interface{}{parentID},
+			"to":      []interface{}{ids[key]},
+		})
+		step.change("services."+key+".traits.meshmap.parent", "converted to a hierarchical relationship")
+	}
+
+	if out["id"] == nil {
+		// derived as the ids of the imported designs are, so that migrating the design again yields the same id
+		key := []string{fmt.Sprint(doc["name"])}
+		for _, comp := range components {
+			key = append(key, comp.(map[string]interface{})["id"].(string))
+		}
+		out["id"] = uuid.NewSHA1(componentIDNamespace, []byte(strings.Join(key, "/"))).String()
+		step.change("id", "generated the id %s", out["id"])
+	}
+	out["components"] = components
+	if len(relationships) > 0 {
+		out["relationships"] = relationships
+	}
+	copyFields(doc, out, "metadata")
+	return out, nil
+}
+
+func migrateV1beta1ToV1alpha2(doc map[string]interface{}, step *MigrationStep) (map[string]interface{}, error) {
+	components, _ := doc["components"].([]interface{})
+	services := make(map[string]interface{}, len(components))
+	keys := make(map[string]string)
+	for i, c := range components {
+		comp, ok := c.(map[string]interface{})
+		if !ok {
+			step.change(fmt.Sprintf("components[%d]", i), "dropped, the component is not an object")
+			continue
+		}
+		name, _ := comp["name"].(string)
+		key := name
+		if _, taken := services[key]; taken || key == "" {
+			key = fmt.Sprintf("%s-%d", name, i)
+			step.change(fmt.Sprintf("components[%d]", i), "keyed as %s, the name is not unique", key)
+		}
+		id, _ := comp["id"].(string)
+		keys[id] = key
+		service := map[string]interface{}{
+			"id":         comp["id"],
+			"name":       name,
+			"type":       comp["kind"],
+			"apiVersion": comp["apiVersion"],
+			"model":      comp["model"],
+			"version":    comp["modelVersion"],
+		}
+		copyFields(comp, service, "namespace", "labels", "annotations", "dependsOn", "displayName")
+		if configuration, ok := comp["configuration"]; ok {
+			service["settings"] = configuration
+		}
+		if metadata, ok := comp["metadata"].(map[string]interface{}); ok && len(metadata) > 0 {
+			// copied, the parents are added to the traits without changing the metadata of the component
+			traits := make(map[string]interface{}, len(metadata))
+			for k, v := range metadata {
+				traits[k] = v
+			}
+			service["traits"] = traits
+		}
+		services[key] = service
+	}
+	step.change("components", "converted to services")
+
+	relationships, _ := doc["relationships"].([]interface{})
+	for i, r := range relationships {
+		rel, _ := r.(map[string]interface{})
+		from, _ := rel["from"].([]interface{})
+		to, _ := rel["to"].([]interface{})
+		path := fmt.Sprintf("relationships[%d]", i)
+		if rel["kind"] != "Hierarchical" || rel["type"] != "parent" || len(from) != 1 {
+			step.change(path, "dropped, only hierarchical parent relationships can be represented in %s", DesignSchemaV1alpha2)
+			continue
+		}
+		for _, child := range to {
+			service, ok := services[keys[fmt.Sprint(child)]].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			traits, _ := service["traits"].(map[string]interface{})
+			if traits == nil {
+				traits = make(map[string]interface{})
+			}
+			meshmap := make(map[string]interface{})
+			if existing, ok := traits["meshmap"].(map[string]interface{}); ok {
+				for k, v := range existing {
+					meshmap[k] = v
+				}
+			}
+			meshmap["parent"] = from[0]
+			traits["meshmap"] = meshmap
+			service["traits"] = traits
+		}
+		step.change(path, "converted to traits.meshmap.parent")
+	}
+
+	out := map[string]interface{}{
+		"id":       doc["id"],
+		"name":     doc["name"],
+		"version":  doc["version"],
+		"services": services,
+	}
+	copyFields(doc, out, "metadata")
+	return out, nil
+}
+
+func copyFields(from, to map[string]interface{}, fields ...string) {
+	for _, field := range fields {
+		if value, ok := from[field]; ok && value != nil {
+			to[field] = value
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+const testV1alpha2Design = `
+name: legacy
+version: 0.0.3
+services:
+  ns:
+    type: Namespace
+    apiVersion: v1
+    model: kubernetes
+    version: v1.25.0
+  web:
+    name: web
+    type: Deployment
+    apiVersion: apps/v1
+    namespace: default
+    model: kubernetes
+    version: v1.25.0
+    settings:
+      spec:
+        replicas: 2
+    traits:
+      meshmap:
+        parent: ns
+        position: {posX: 10, posY: 20}
+`
+
+func TestMigrateV1alpha2Design(t *testing.T) {
+	registry := NewMigrationRegistry()
+	design, report, err := registry.MigrateToLatest([]byte(testV1alpha2Design))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.From != DesignSchemaV1alpha2 || len(report.Steps) != 1 || len(report.Steps[0].Changes) == 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if design.SchemaVersion != v1beta1.DesignSchemaVersion || design.Version != "0.0.3" || len(design.Components) != 2 {
+		t.Fatalf("unexpected design %+v", design)
+	}
+	ns, web := design.Components[0], design.Components[1]
+	if web.Kind != "Deployment" || web.ModelVersion != "v1.25.0" || web.Namespace != "default" {
+		t.Fatalf("unexpected component %+v", web)
+	}
+	if spec, _ := web.Configuration["spec"].(map[string]interface{}); spec["replicas"] != 2 && spec["replicas"] != float64(2) {
+		t.Fatalf("settings not migrated: %+v", web.Configuration)
+	}
+	if len(design.Relationships) != 1 {
+		t.Fatalf("expected the parent relationship, got %+v", design.Relationships)
+	}
+	rel := design.Relationships[0]
+	if rel.Kind != "Hierarchical" || rel.From[0] != ns.ID || rel.To[0] != web.ID {
+		t.Fatalf("unexpected relationship %+v", rel)
+	}
+
+	data, err := json.Marshal(design)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, _, err := registry.Migrate(data, DesignSchemaV1alpha2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := legacy["services"].(map[string]interface{})
+	service := services["web"].(map[string]interface{})
+	if service["type"] != "Deployment" || service["traits"].(map[string]interface{})["meshmap"].(map[string]interface{})["parent"] != ns.ID.String() {
+		t.Fatalf("unexpected service after the round trip %+v", service)
+	}
+}
+
+func TestMigrateV1alpha2DesignID(t *testing.T) {
+	registry := NewMigrationRegistry()
+	design, _, err := registry.MigrateToLatest([]byte(testV1alpha2Design))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := registry.MigrateToLatest([]byte(testV1alpha2Design))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if design.ID == uuid.Nil || again.ID != design.ID {
+		t.Errorf("the design id should be stable across migrations, got %s and %s", design.ID, again.ID)
+	}
+}
+
+func TestMigrateV1beta1DesignKeepsMetadata(t *testing.T) {
+	position := map[string]interface{}{"posX": 10}
+	doc := map[string]interface{}{
+		"name": "web",
+		"components": []interface{}{
+			map[string]interface{}{"id": "ns", "name": "ns", "kind": "Namespace"},
+			map[string]interface{}{"id": "web", "name": "web", "kind": "Deployment", "metadata": map[string]interface{}{"meshmap": position}},
+		},
+		"relationships": []interface{}{
+			map[string]interface{}{"kind": "Hierarchical", "type": "parent", "from": []interface{}{"ns"}, "to": []interface{}{"web"}},
+		},
+	}
+	out, err := migrateV1beta1ToV1alpha2(doc, &MigrationStep{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := out["services"].(map[string]interface{})["web"].(map[string]interface{})
+	if web["traits"].(map[string]interface{})["meshmap"].(map[string]interface{})["parent"] != "ns" {
+		t.Fatalf("expected the parent in the traits, got %+v", web)
+	}
+	if _, ok := position["parent"]; ok {
+		t.Errorf("expected the metadata of the component to be left unchanged, got %+v", position)
+	}
+}
+
+func TestMigrateUnknownVersion(t *testing.T) {
+	if _, _, err := NewMigrationRegistry().Migrate([]byte(`schemaVersion: designs.meshery.io/v0`), DesignSchemaV1alpha2); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,35 @@
+package converter
+
+import "fmt"
+
+// Finding is a note about a part of the input of a conversion.
+type Finding struct {
+	// Path locates the finding in the input, e.g. services.web.build
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Path, f.Message)
+}
+
+// Report lists what a conversion could not carry over faithfully.
+type Report struct {
+	// Unsupported are the parts of the input which were dropped
+	Unsupported []Finding `json:"unsupported,omitempty"`
+	// Warnings are the parts of the input which were converted with a loss of information
+	Warnings []Finding `json:"warnings,omitempty"`
+}
+
+func (r *Report) unsupported(path, format string, args ...interface{}) {
+	r.Unsupported = append(r.Unsupported, Finding{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) warn(path, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, Finding{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// IsLossless reports whether the conversion carried over all of the input.
+func (r Report) IsLossless() bool {
+	return len(r.Unsupported) == 0 && len(r.Warnings) == 0
+}
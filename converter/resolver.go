@@ -0,0 +1,48 @@
+package converter
+
+import (
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/registry"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+)
+
+// ComponentResolver finds the component definition of a kind.
+// It returns nil if the kind is not known.
+type ComponentResolver interface {
+	ResolveComponent(apiVersion, kind string) (*v1beta1.ComponentDefinition, error)
+}
+
+// RegistryResolver resolves the component definitions registered in the registry.
+type RegistryResolver struct {
+	RegistryManager *registry.RegistryManager
+}
+
+func (r RegistryResolver) ResolveComponent(apiVersion, kind string) (*v1beta1.ComponentDefinition, error) {
+	entities, _, _, err := r.RegistryManager.GetEntities(&regv1beta1.ComponentFilter{
+		Name:       kind,
+		APIVersion: apiVersion,
+		Trim:       true,
+		Limit:      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, en := range entities {
+		if comp, ok := en.(*v1beta1.ComponentDefinition); ok {
+			return comp, nil
+		}
+	}
+	return nil, nil
+}
+
+// StaticResolver resolves the component definitions it holds, e.g. the ones generated for a model.
+type StaticResolver []v1beta1.ComponentDefinition
+
+func (s StaticResolver) ResolveComponent(apiVersion, kind string) (*v1beta1.ComponentDefinition, error) {
+	for i := range s {
+		if s[i].Component.Kind == kind && s[i].Component.Version == apiVersion {
+			return &s[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,412 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/oci"
+)
+
+// SBOMFormat is the format of the SBOM of a design.
+type SBOMFormat string
+
+const (
+	// SBOMFormatCycloneDX is the CycloneDX 1.5 JSON format
+	SBOMFormatCycloneDX SBOMFormat = "cyclonedx"
+	// SBOMFormatSPDX is the SPDX 2.3 JSON format
+	SBOMFormatSPDX SBOMFormat = "spdx"
+)
+
+// sbomToolName is the tool recorded as the creator of the SBOMs
+const sbomToolName = "meshkit"
+
+// podContainerFields are the fields of the pod specs listing containers
+var podContainerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// SBOMOptions configure the SBOM of a design.
+type SBOMOptions struct {
+	// Format defaults to SBOMFormatCycloneDX
+	Format SBOMFormat
+	// Timestamp is the creation time of the SBOM, it defaults to now. Set it for reproducible SBOMs.
+	Timestamp time.Time
+}
+
+// ContainerImage is a container image referenced by the components of a design.
+type ContainerImage struct {
+	// Reference is the image as written in the design, e.g. nginx:1.25
+	Reference string `json:"reference"`
+	// Registry and Repository are normalized, e.g. index.docker.io and library/nginx
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	// Components are the components running the image, as <kind>/<name>
+	Components []string `json:"components"`
+}
+
+// Name is the last element of the repository, e.g. nginx
+func (i ContainerImage) Name() string {
+	return path.Base(i.Repository)
+}
+
+// Version is the digest of the image if it is pinned, its tag otherwise.
+func (i ContainerImage) Version() string {
+	if i.Digest != "" {
+		return i.Digest
+	}
+	return i.Tag
+}
+
+// PackageURL is the purl of the image, e.g. pkg:oci/nginx?repository_url=index.docker.io/library/nginx&tag=1.25,
+// see https://github.com/package-url/purl-spec/blob/master/PURL-TYPES.rst#oci. The version is the digest, with its
+// colon percent-encoded, e.g. pkg:oci/nginx@sha256%3A0123...
+func (i ContainerImage) PackageURL() string {
+	purl := "pkg:oci/" + url.PathEscape(strings.ToLower(i.Name()))
+	if i.Digest != "" {
+		purl += "@" + strings.ReplaceAll(url.PathEscape(i.Digest), ":", "%3A")
+	}
+	qualifiers := []string{"repository_url=" + i.Registry + "/" + i.Repository}
+	if i.Tag != "" {
+		qualifiers = append(qualifiers, "tag="+url.QueryEscape(i.Tag))
+	}
+	return purl + "?" + strings.Join(qualifiers, "&")
+}
+
+// SBOM is the software bill of materials of a design.
+type SBOM struct {
+	Format SBOMFormat
+	// MediaType is the media type of the content, the artifactType to attach it to an OCI artifact, see oci.Attach
+	MediaType string
+	Content   []byte
+	Images    []ContainerImage
+}
+
+// DesignImages returns the container images of the pod specs of the components of the design, with the components
+// running them, ordered by package URL. The references of the same image, e.g. nginx and docker.io/library/nginx:latest,
+// are listed once, under the first reference found. The images which could not be parsed, e.g. holding unresolved
+// variables, are reported as warnings: flatten the design first to resolve them.
+func DesignImages(design v1beta1.Design) ([]ContainerImage, Report) {
+	report := Report{}
+	// the images by package URL, which is derived from the normalized reference
+	images := make(map[string]*ContainerImage)
+	for _, comp := range sortedComponents(design.Components) {
+		owner := fmt.Sprintf("%s/%s", comp.Kind, comp.Name)
+		for _, ref := range podImages(comp.Configuration, "") {
+			parsed, err := parseImage(ref.image)
+			if err != nil {
+				report.warn(owner+"/"+ref.path, "invalid image %q: %s", ref.image, err)
+				continue
+			}
+			image, ok := images[parsed.PackageURL()]
+			if !ok {
+				image = &parsed
+				images[parsed.PackageURL()] = image
+			}
+			if !containsString(image.Components, owner) {
+				image.Components = append(image.Components, owner)
+			}
+		}
+	}
+	result := make([]ContainerImage, 0, len(images))
+	for _, purl := range sortedKeys(images) {
+		result = append(result, *images[purl])
+	}
+	return result, report
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// DesignToSBOM generates an SBOM of the container images of the design, so that the images can be assessed, e.g. by
+// vulnerability scanners, before the design is deployed. The design is the described application, depending on the images.
+func DesignToSBOM(design v1beta1.Design, opts SBOMOptions) (SBOM, Report, error) {
+	images, report := DesignImages(design)
+	timestamp := opts.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	timestamp = timestamp.UTC().Truncate(time.Second)
+
+	sbom := SBOM{Format: opts.Format, Images: images}
+	if sbom.Format == "" {
+		sbom.Format = SBOMFormatCycloneDX
+	}
+	var doc interface{}
+	switch sbom.Format {
+	case SBOMFormatCycloneDX:
+		sbom.MediaType = oci.CycloneDXArtifactType
+		doc = cycloneDXDocument(design, images, timestamp)
+	case SBOMFormatSPDX:
+		sbom.MediaType = oci.SPDXArtifactType
+		doc = spdxDocument(design, images, timestamp)
+	default:
+		return sbom, report, ErrGenerateSBOM(fmt.Errorf("unknown SBOM format %q", sbom.Format))
+	}
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return sbom, report, ErrGenerateSBOM(err)
+	}
+	sbom.Content = content
+	return sbom, report, nil
+}
+
+type imageRef struct {
+	path  string
+	image string
+}
+
+// podImages returns the images of the containers found anywhere in the configuration, so that the pod specs of
+// all the workloads are covered, e.g. spec.template.spec of Deployments and spec.jobTemplate.spec.template.spec of CronJobs.
+func podImages(value interface{}, p string) []imageRef {
+	var refs []imageRef
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			child := key
+			if p != "" {
+				child = p + "." + key
+			}
+			if isContainerField(key) {
+				if containers, ok := v[key].([]interface{}); ok {
+					for i, c := range containers {
+						container, _ := c.(map[string]interface{})
+						if image, _ := container["image"].(string); image != "" {
+							refs = append(refs, imageRef{path: fmt.Sprintf("%s[%d].image", child, i), image: image})
+						}
+					}
+					continue
+				}
+			}
+			refs = append(refs, podImages(v[key], child)...)
+		}
+	case []interface{}:
+		for i, val := range v {
+			refs = append(refs, podImages(val, fmt.Sprintf("%s[%d]", p, i))...)
+		}
+	}
+	return refs
+}
+
+func isContainerField(key string) bool {
+	for _, field := range podContainerFields {
+		if key == field {
+			return true
+		}
+	}
+	return false
+}
+
+// parseImage normalizes the reference as the container runtimes do: the registry defaults to Docker Hub and the tag to
+// latest, unless the image is pinned by digest.
+func parseImage(image string) (ContainerImage, error) {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return ContainerImage{}, err
+	}
+	img := ContainerImage{
+		Reference:  image,
+		Registry:   ref.Context().RegistryStr(),
+		Repository: ref.Context().RepositoryStr(),
+	}
+	switch r := ref.(type) {
+	case name.Digest:
+		img.Digest = r.DigestStr()
+		// a reference may have both, e.g. nginx:1.25@sha256:...
+		repository := image[:strings.LastIndex(image, "@")]
+		if strings.Contains(path.Base(repository), ":") {
+			if tag, err := name.NewTag(repository, name.WeakValidation); err == nil {
+				img.Tag = tag.TagStr()
+			}
+		}
+	case name.Tag:
+		img.Tag = r.TagStr()
+	}
+	return img, nil
+}
+
+// sbomSerial derives the serial of the SBOM from the design and the timestamp, so that reproducible SBOMs are identical.
+func sbomSerial(design v1beta1.Design, timestamp time.Time) uuid.UUID {
+	return uuid.NewSHA1(componentIDNamespace, []byte(fmt.Sprintf("%s/%s/%s", design.ID, design.Version, timestamp.Format(time.RFC3339))))
+}
+
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Hashes     []cycloneDXHash     `json:"hashes,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func cycloneDXDocument(design v1beta1.Design, images []ContainerImage, timestamp time.Time) cycloneDXBOM {
+	designRef := "design:" + design.ID.String()
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + sbomSerial(design, timestamp).String(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: timestamp.Format(time.RFC3339),
+			Tools:     cycloneDXTools{Components: []cycloneDXComponent{{Type: "application", Name: sbomToolName}}},
+			Component: cycloneDXComponent{Type: "application", BOMRef: designRef, Name: design.Name, Version: design.Version},
+		},
+		Components: make([]cycloneDXComponent, 0, len(images)),
+	}
+	dependencies := cycloneDXDependency{Ref: designRef}
+	for _, image := range images {
+		purl := image.PackageURL()
+		comp := cycloneDXComponent{
+			Type:    "container",
+			BOMRef:  purl,
+			Name:    image.Registry + "/" + image.Repository,
+			Version: image.Version(),
+			PURL:    purl,
+		}
+		if hex, ok := strings.CutPrefix(image.Digest, "sha256:"); ok {
+			comp.Hashes = []cycloneDXHash{{Alg: "SHA-256", Content: hex}}
+		}
+		for _, owner := range image.Components {
+			comp.Properties = append(comp.Properties, cycloneDXProperty{Name: "meshery:component", Value: owner})
+		}
+		bom.Components = append(bom.Components, comp)
+		bom.Dependencies = append(bom.Dependencies, cycloneDXDependency{Ref: purl})
+		dependencies.DependsOn = append(dependencies.DependsOn, purl)
+	}
+	bom.Dependencies = append([]cycloneDXDependency{dependencies}, bom.Dependencies...)
+	return bom
+}
+
+type spdxDoc struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func spdxDocument(design v1beta1.Design, images []ContainerImage, timestamp time.Time) spdxDoc {
+	const designID = "SPDXRef-Design"
+	doc := spdxDoc{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              design.Name,
+		DocumentNamespace: fmt.Sprintf("https://meshery.io/spdx/designs/%s-%s", design.ID, sbomSerial(design, timestamp)),
+		CreationInfo: spdxCreationInfo{
+			Created:  timestamp.Format(time.RFC3339),
+			Creators: []string{"Tool: " + sbomToolName},
+		},
+		Packages: []spdxPackage{{
+			Name:                  design.Name,
+			SPDXID:                designID,
+			VersionInfo:           design.Version,
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: "APPLICATION",
+		}},
+		Relationships: []spdxRelationship{{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: designID}},
+	}
+	for i, image := range images {
+		id := fmt.Sprintf("SPDXRef-Image-%d", i+1)
+		pkg := spdxPackage{
+			Name:                  image.Registry + "/" + image.Repository,
+			SPDXID:                id,
+			VersionInfo:           image.Version(),
+			DownloadLocation:      "NOASSERTION",
+			PrimaryPackagePurpose: "CONTAINER",
+			ExternalRefs:          []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: image.PackageURL()}},
+			Comment:               "Used by " + strings.Join(image.Components, ", "),
+		}
+		if hex, ok := strings.CutPrefix(image.Digest, "sha256:"); ok {
+			pkg.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: hex}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: designID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: id})
+	}
+	return doc
+}
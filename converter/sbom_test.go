@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+const testDigest = "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+
+func testSBOMDesign() v1beta1.Design {
+	design := v1beta1.NewDesign("shop")
+	design.Version = "1.0.0"
+	design.Components = []v1beta1.DesignComponent{
+		{Name: "web", Kind: "Deployment", APIVersion: "apps/v1", Configuration: map[string]interface{}{
+			"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+				"initContainers": []interface{}{map[string]interface{}{"name": "migrate", "image": "ghcr.io/acme/shop:2.1@" + testDigest}},
+				"containers":     []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.25"}},
+			}}},
+		}},
+		{Name: "report", Kind: "CronJob", APIVersion: "batch/v1", Configuration: map[string]interface{}{
+			"spec": map[string]interface{}{"jobTemplate": map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"image": "docker.io/library/nginx:1.25"}, map[string]interface{}{"image": "registry/report:${TAG}"}},
+			}}}}},
+		}},
+		{Name: "config", Kind: "ConfigMap", APIVersion: "v1", Configuration: map[string]interface{}{"data": map[string]interface{}{"image": "ignored:1"}}},
+	}
+	return design
+}
+
+func TestDesignImages(t *testing.T) {
+	images, report := DesignImages(testSBOMDesign())
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got %+v", images)
+	}
+	nginx, shop := images[0], images[1]
+	if shop.Registry != "ghcr.io" || shop.Repository != "acme/shop" || shop.Tag != "2.1" || shop.Digest != testDigest {
+		t.Errorf("unexpected image %+v", shop)
+	}
+	if purl := shop.PackageURL(); purl != "pkg:oci/shop@sha256%3A"+strings.TrimPrefix(testDigest, "sha256:")+"?repository_url=ghcr.io/acme/shop&tag=2.1" {
+		t.Errorf("purl = %s", purl)
+	}
+	// docker.io/library/nginx:1.25 of the CronJob, found first, and nginx:1.25 are the same image
+	if nginx.Reference != "docker.io/library/nginx:1.25" || nginx.Registry != "index.docker.io" || nginx.Repository != "library/nginx" || len(nginx.Components) != 2 {
+		t.Errorf("unexpected image %+v", nginx)
+	}
+	if purl := nginx.PackageURL(); purl != "pkg:oci/nginx?repository_url=index.docker.io/library/nginx&tag=1.25" {
+		t.Errorf("purl = %s", purl)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0].Path, "containers[1].image") {
+		t.Errorf("expected a warning about the unresolved image, got %+v", report)
+	}
+}
+
+func TestDesignToSBOM(t *testing.T) {
+	design := testSBOMDesign()
+	opts := SBOMOptions{Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	sbom, _, err := DesignToSBOM(design, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bom cycloneDXBOM
+	if err := json.Unmarshal(sbom.Content, &bom); err != nil {
+		t.Fatal(err)
+	}
+	if sbom.MediaType != "application/vnd.cyclonedx+json" || bom.BOMFormat != "CycloneDX" || len(bom.Components) != 2 {
+		t.Fatalf("unexpected CycloneDX SBOM:\n%s", sbom.Content)
+	}
+	if len(bom.Components[1].Hashes) != 1 || len(bom.Dependencies[0].DependsOn) != 2 || bom.Metadata.Component.Name != "shop" {
+		t.Errorf("unexpected CycloneDX SBOM:\n%s", sbom.Content)
+	}
+	again, _, _ := DesignToSBOM(design, opts)
+	if string(again.Content) != string(sbom.Content) {
+		t.Error("the SBOM is not reproducible")
+	}
+
+	opts.Format = SBOMFormatSPDX
+	sbom, _, err = DesignToSBOM(design, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc spdxDoc
+	if err := json.Unmarshal(sbom.Content, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || len(doc.Packages) != 3 || len(doc.Relationships) != 3 {
+		t.Errorf("unexpected SPDX SBOM:\n%s", sbom.Content)
+	}
+
+	if _, _, err := DesignToSBOM(design, SBOMOptions{Format: "swid"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -2,7 +2,10 @@ package database
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/layer5io/meshkit/logger"
 	"gorm.io/driver/postgres"
@@ -23,6 +26,31 @@ type Options struct {
 	Filename string `json:"filename,omitempty"`
 	Engine   string `json:"engine,omitempty"`
 	Logger   logger.Handler
+	// SQLite configures the pragmas of SQLite databases.
+	SQLite SQLiteOptions `json:"sqlite,omitempty"`
+	// Pool configures the connection pool, for all engines.
+	Pool PoolOptions `json:"pool,omitempty"`
+}
+
+// SQLiteOptions are the pragmas set on each connection to a SQLite database. Unset pragmas keep the SQLite defaults.
+//
+// Concurrent reads while writing, e.g. reading the registry during MeshSync ingestion, need the WAL journal mode
+// and a busy timeout, otherwise they fail with "database is locked".
+type SQLiteOptions struct {
+	// JournalMode is one of DELETE, TRUNCATE, PERSIST, MEMORY, WAL, OFF.
+	JournalMode string `json:"journal_mode,omitempty"`
+	// BusyTimeout is the time to wait for a lock to be released before failing.
+	BusyTimeout time.Duration `json:"busy_timeout,omitempty"`
+	// Synchronous is one of OFF, NORMAL, FULL, EXTRA. NORMAL is safe with WAL.
+	Synchronous string `json:"synchronous,omitempty"`
+}
+
+// PoolOptions configures the connection pool of the database. Zero values keep the defaults of database/sql.
+type PoolOptions struct {
+	MaxOpenConns    int           `json:"max_open_conns,omitempty"`
+	MaxIdleConns    int           `json:"max_idle_conns,omitempty"`
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime,omitempty"`
+	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time,omitempty"`
 }
 
 type Model struct {
@@ -57,6 +85,9 @@ func New(opts Options) (Handler, error) {
 		if err != nil {
 			return Handler{}, ErrDatabaseOpen(err)
 		}
+		if err := configurePool(db, opts.Pool); err != nil {
+			return Handler{}, ErrDatabaseOpen(err)
+		}
 		return Handler{
 			db,
 			&sync.Mutex{},
@@ -67,10 +98,13 @@ func New(opts Options) (Handler, error) {
 			config.Logger = opts.Logger.DatabaseLogger()
 		}
 
-		db, err := gorm.Open(sqlite.Open(opts.Filename), config)
+		db, err := gorm.Open(sqlite.Open(sqliteDSN(opts.Filename, opts.SQLite)), config)
 		if err != nil {
 			return Handler{}, ErrDatabaseOpen(err)
 		}
+		if err := configurePool(db, opts.Pool); err != nil {
+			return Handler{}, ErrDatabaseOpen(err)
+		}
 
 		return Handler{
 			db,
@@ -80,3 +114,46 @@ func New(opts Options) (Handler, error) {
 
 	return Handler{}, ErrNoneDatabase
 }
+
+// sqliteDSN adds the pragmas to the filename, as connection parameters of the driver,
+// so that they are set on every connection of the pool.
+func sqliteDSN(filename string, opts SQLiteOptions) string {
+	params := url.Values{}
+	if opts.JournalMode != "" {
+		params.Set("_journal_mode", opts.JournalMode)
+	}
+	if opts.BusyTimeout > 0 {
+		params.Set("_busy_timeout", fmt.Sprint(opts.BusyTimeout.Milliseconds()))
+	}
+	if opts.Synchronous != "" {
+		params.Set("_synchronous", opts.Synchronous)
+	}
+	if len(params) == 0 {
+		return filename
+	}
+	separator := "?"
+	if strings.Contains(filename, "?") {
+		separator = "&"
+	}
+	return filename + separator + params.Encode()
+}
+
+func configurePool(db *gorm.DB, opts PoolOptions) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if opts.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(opts.MaxOpenConns)
+	}
+	if opts.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+	if opts.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	}
+	if opts.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	}
+	return nil
+}
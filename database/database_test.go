@@ -0,0 +1,66 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestHandler returns a handler of an in-memory SQLite database with the tables of the models, closed with the test.
+func newTestHandler(t *testing.T, models ...interface{}) Handler {
+	t.Helper()
+	h, err := New(Options{Engine: SQLITE, Filename: "file::memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = h.DBClose() })
+	if err := h.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestSQLiteOptions(t *testing.T) {
+	h, err := New(Options{
+		Engine:   SQLITE,
+		Filename: filepath.Join(t.TempDir(), "meshery.db"),
+		SQLite:   SQLiteOptions{JournalMode: "WAL", BusyTimeout: 5 * time.Second, Synchronous: "NORMAL"},
+		Pool:     PoolOptions{MaxOpenConns: 4, MaxIdleConns: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = h.DBClose() }()
+
+	var journalMode string
+	var busyTimeout, synchronous int
+	if err := h.Raw("PRAGMA journal_mode").Scan(&journalMode).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Raw("PRAGMA synchronous").Scan(&synchronous).Error; err != nil {
+		t.Fatal(err)
+	}
+	// synchronous NORMAL is 1
+	if journalMode != "wal" || busyTimeout != 5000 || synchronous != 1 {
+		t.Errorf("journal_mode = %s, busy_timeout = %d, synchronous = %d; want wal, 5000, 1", journalMode, busyTimeout, synchronous)
+	}
+	sqlDB, err := h.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sqlDB.Stats().MaxOpenConnections; got != 4 {
+		t.Errorf("MaxOpenConnections = %d; want 4", got)
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	if got, want := sqliteDSN("file::memory:?cache=shared", SQLiteOptions{BusyTimeout: time.Second}), "file::memory:?cache=shared&_busy_timeout=1000"; got != want {
+		t.Errorf("sqliteDSN() = %q; want %q", got, want)
+	}
+	if got, want := sqliteDSN("meshery.db", SQLiteOptions{}), "meshery.db"; got != want {
+		t.Errorf("sqliteDSN() = %q; want %q", got, want)
+	}
+}
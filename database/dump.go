@@ -0,0 +1,182 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DumpFormatVersion is the version of the format of the dumps written by DumpTables.
+const DumpFormatVersion = 1
+
+// dumpBatchSize is the number of rows inserted per statement by LoadTables.
+const dumpBatchSize = 100
+
+// Dump is the content of tables written by DumpTables as JSON, independent of the engine, e.g. to move the registry
+// from SQLite to Postgres.
+type Dump struct {
+	FormatVersion int `json:"format_version"`
+	// SchemaVersion is the migration version of the database dumped, see Stats.MigrationVersion. A dump is only
+	// loaded into a database of the same version, as the columns of the tables depend on it.
+	SchemaVersion int64       `json:"schema_version"`
+	Engine        string      `json:"engine"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Tables        []TableDump `json:"tables"`
+}
+
+// TableDump are the rows of a table, their values in the order of the columns. Binary values are written as
+// {"base64": "..."}, even if they are valid UTF-8, to be loaded as binary.
+type TableDump struct {
+	Name    string          `json:"name"`
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// DumpTables writes the rows of the tables as a JSON Dump, in the order of the names, all the tables if none is given
+// but MigrationsTable, whose version is recorded by the dump, and the internal tables of SQLite.
+// The order of the tables is the order they are loaded in, the tables referenced by foreign keys have to come first.
+func (h *Handler) DumpTables(names []string, w io.Writer) error {
+	db := h.DB.Session(&gorm.Session{NewDB: true})
+	stats, err := h.Stats()
+	if err != nil {
+		return ErrDumpTables(err)
+	}
+	if len(names) == 0 {
+		tables, err := db.Migrator().GetTables()
+		if err != nil {
+			return ErrDumpTables(err)
+		}
+		for _, name := range tables {
+			// the names starting with sqlite_ are reserved for the internal tables of SQLite, e.g. sqlite_sequence
+			if name != MigrationsTable && !strings.HasPrefix(name, "sqlite_") {
+				names = append(names, name)
+			}
+		}
+	}
+	dump := Dump{FormatVersion: DumpFormatVersion, SchemaVersion: stats.MigrationVersion, Engine: stats.Engine, CreatedAt: time.Now().UTC()}
+	for _, name := range names {
+		if !db.Migrator().HasTable(name) {
+			return ErrDumpTables(fmt.Errorf("table %s doesn't exist", name))
+		}
+		table, err := dumpTable(db, name)
+		if err != nil {
+			return ErrDumpTables(fmt.Errorf("table %s: %w", name, err))
+		}
+		dump.Tables = append(dump.Tables, table)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(dump); err != nil {
+		return ErrDumpTables(err)
+	}
+	return nil
+}
+
+func dumpTable(db *gorm.DB, name string) (TableDump, error) {
+	rows, err := db.Table(name).Rows()
+	if err != nil {
+		return TableDump{}, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return TableDump{}, err
+	}
+	table := TableDump{Name: name, Columns: columns, Rows: [][]interface{}{}}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return TableDump{}, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = map[string]interface{}{"base64": base64.StdEncoding.EncodeToString(b)}
+			}
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	return table, rows.Err()
+}
+
+// LoadTables inserts the rows of a Dump written by DumpTables into the tables of the database, which have to exist,
+// in a transaction: no row is inserted if one fails, e.g. as it exists. The schema version of the dump has to be
+// the migration version of the database. The sequences of the primary keys are not updated.
+func (h *Handler) LoadTables(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	// numbers are kept as written, e.g. IDs larger than the integers of float64
+	dec.UseNumber()
+	var dump Dump
+	if err := dec.Decode(&dump); err != nil {
+		return ErrLoadTables(err)
+	}
+	if dump.FormatVersion != DumpFormatVersion {
+		return ErrLoadTables(fmt.Errorf("unsupported dump format version %d, expected %d", dump.FormatVersion, DumpFormatVersion))
+	}
+	stats, err := h.Stats()
+	if err != nil {
+		return ErrLoadTables(err)
+	}
+	if dump.SchemaVersion != stats.MigrationVersion {
+		return ErrDumpSchemaVersion(dump.SchemaVersion, stats.MigrationVersion)
+	}
+	err = h.DB.Session(&gorm.Session{NewDB: true}).Transaction(func(tx *gorm.DB) error {
+		for _, table := range dump.Tables {
+			if !tx.Migrator().HasTable(table.Name) {
+				return fmt.Errorf("table %s doesn't exist", table.Name)
+			}
+			rows := make([]map[string]interface{}, 0, len(table.Rows))
+			for i, values := range table.Rows {
+				if len(values) != len(table.Columns) {
+					return fmt.Errorf("row %d of table %s has %d values for %d columns", i, table.Name, len(values), len(table.Columns))
+				}
+				row := make(map[string]interface{}, len(values))
+				for j, v := range values {
+					if row[table.Columns[j]], err = loadValue(v); err != nil {
+						return fmt.Errorf("row %d of table %s: %w", i, table.Name, err)
+					}
+				}
+				rows = append(rows, row)
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			if err := tx.Table(table.Name).CreateInBatches(rows, dumpBatchSize).Error; err != nil {
+				return fmt.Errorf("table %s: %w", table.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ErrLoadTables(err)
+	}
+	return nil
+}
+
+// loadValue converts a value of a dump to the value inserted.
+func loadValue(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i, nil
+		}
+		return value.Float64()
+	case map[string]interface{}:
+		encoded, ok := value["base64"].(string)
+		if !ok || len(value) != 1 {
+			return nil, fmt.Errorf("unexpected object %v", value)
+		}
+		return base64.StdEncoding.DecodeString(encoded)
+	case []interface{}:
+		return nil, fmt.Errorf("unexpected array %v", value)
+	}
+	return v, nil
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+type dumpedHost struct {
+	ID       uint64 `gorm:"primarykey"`
+	Hostname string
+	Metadata []byte
+}
+
+func newDumpTestHandler(t *testing.T, version int64) Handler {
+	t.Helper()
+	h := newTestHandler(t, &dumpedHost{})
+	if err := h.Exec("CREATE TABLE " + MigrationsTable + " (version bigint, dirty boolean)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Exec("INSERT INTO "+MigrationsTable+" VALUES (?, false)", version).Error; err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestDumpTables(t *testing.T) {
+	source := newDumpTestHandler(t, 3)
+	hosts := []dumpedHost{
+		{ID: 1 << 60, Hostname: "artifacthub", Metadata: []byte(`{"verified":true}`)},
+		{ID: 2, Hostname: "github", Metadata: []byte{0xff, 0x00}},
+	}
+	if err := source.Create(&hosts).Error; err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := source.DumpTables([]string{"dumped_hosts"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	var dump Dump
+	if err := json.Unmarshal(out.Bytes(), &dump); err != nil {
+		t.Fatal(err)
+	}
+	if dump.SchemaVersion != 3 || dump.Engine != SQLITE || len(dump.Tables) != 1 || len(dump.Tables[0].Rows) != 2 {
+		t.Fatalf("unexpected dump %s", out.String())
+	}
+	metadata := -1
+	for i, column := range dump.Tables[0].Columns {
+		if column == "metadata" {
+			metadata = i
+		}
+	}
+	for _, row := range dump.Tables[0].Rows {
+		if _, ok := row[metadata].(map[string]interface{}); !ok {
+			t.Errorf("expected the binary values to be written as base64, got %v", row[metadata])
+		}
+	}
+
+	target := newDumpTestHandler(t, 3)
+	if err := target.LoadTables(bytes.NewReader(out.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	var loaded []dumpedHost
+	if err := target.Order("id DESC").Find(&loaded).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].ID != 1<<60 || string(loaded[0].Metadata) != `{"verified":true}` || !bytes.Equal(loaded[1].Metadata, []byte{0xff, 0x00}) {
+		t.Errorf("unexpected rows loaded %+v", loaded)
+	}
+	// the rows exist, none is inserted
+	if err := target.LoadTables(bytes.NewReader(out.Bytes())); errors.GetCode(err) != ErrLoadTablesCode {
+		t.Errorf("expected loading existing rows to fail, got %v", err)
+	}
+
+	other := newDumpTestHandler(t, 4)
+	if err := other.LoadTables(bytes.NewReader(out.Bytes())); errors.GetCode(err) != ErrDumpSchemaVersionCode {
+		t.Errorf("expected a dump of another schema version to fail, got %v", err)
+	}
+	var all bytes.Buffer
+	if err := source.DumpTables(nil, &all); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(all.Bytes(), &dump); err != nil {
+		t.Fatal(err)
+	}
+	if len(dump.Tables) != 1 || dump.Tables[0].Name != "dumped_hosts" {
+		t.Errorf("expected all the tables but %s to be dumped, got %s", MigrationsTable, all.String())
+	}
+	if err := source.DumpTables([]string{"missing"}, &out); errors.GetCode(err) != ErrDumpTablesCode {
+		t.Errorf("expected a missing table to fail, got %v", err)
+	}
+}
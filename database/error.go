@@ -1,6 +1,10 @@
 package database
 
-import "github.com/layer5io/meshkit/errors"
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
 
 var (
 	ErrNoneDatabaseCode              = "meshkit-11126"
@@ -11,6 +15,18 @@ var (
 	ErrSQLMapUnmarshalScannedCode    = "meshkit-11131"
 	ErrSQLMapInvalidScanCode         = "meshkit-11132"
 	ErrClosingDatabaseConnectionCode = "meshkit-11133"
+	ErrTenancyModelCode              = "meshkit-11285"
+	ErrTenantMissingCode             = "meshkit-11286"
+	ErrRevisionModelCode             = "meshkit-11307"
+	ErrRevisionsCode                 = "meshkit-11308"
+	ErrRevisionNotFoundCode          = "meshkit-11309"
+	ErrDatabasePingCode              = "meshkit-11310"
+	ErrDatabaseStatsCode             = "meshkit-11311"
+	ErrRecordNotFoundCode            = "meshkit-11329"
+	ErrRepositoryCode                = "meshkit-11330"
+	ErrDumpTablesCode                = "meshkit-11331"
+	ErrLoadTablesCode                = "meshkit-11332"
+	ErrDumpSchemaVersionCode         = "meshkit-11333"
 	ErrNoneDatabase                  = errors.New(ErrNoneDatabaseCode, errors.Alert, []string{"No Database selected"}, []string{}, []string{"database name is empty"}, []string{"Input a name for the database"})
 	ErrSQLMapInvalidScan             = errors.New(ErrSQLMapInvalidScanCode, errors.Alert, []string{"invalid data type: expected []byte"}, []string{}, []string{}, []string{})
 )
@@ -47,3 +63,51 @@ func ErrSQLMapUnmarshalScanned(err error) error {
 func ErrClosingDatabaseConnection(err error) error {
 	return errors.New(ErrClosingDatabaseConnectionCode, errors.Alert, []string{"failed to close database connection"}, []string{err.Error()}, []string{"Invalid database instance passed."}, []string{"Make sure the DB handler has a valid database instance."})
 }
+
+func ErrTenancyModel(err error, model string) error {
+	return errors.New(ErrTenancyModelCode, errors.Alert, []string{"Unable to enable multi-tenancy for " + model}, []string{err.Error()}, []string{"The model can't be parsed or has no tenant column"}, []string{"Make sure the model has a field for the tenant column configured in the tenancy options"})
+}
+
+func ErrTenantMissing(table string) error {
+	return errors.New(ErrTenantMissingCode, errors.Alert, []string{"No tenant found for accessing " + table}, []string{"The statement is not executed as the tenant is required and the context carries no tenant ID"}, []string{"The context of the statement was not created using database.WithTenant"}, []string{"Execute the statement using db.WithContext(database.WithTenant(ctx, tenantID))"})
+}
+
+func ErrRevisionModel(err error, model string) error {
+	return errors.New(ErrRevisionModelCode, errors.Alert, []string{"Unable to enable revisions for " + model}, []string{err.Error()}, []string{"The model can't be parsed or has no primary key"}, []string{"Make sure the model is a GORM model with a primary key"})
+}
+
+func ErrRevisions(err error, action string) error {
+	return errors.New(ErrRevisionsCode, errors.Alert, []string{"Unable to " + action}, []string{err.Error()}, []string{"The revisions table is missing or unreachable", "The columns of the model can't be encoded as JSON"}, []string{"Make sure revisions are enabled using EnableRevisions and the database is reachable"})
+}
+
+func ErrRevisionNotFound(id uint64, table string) error {
+	return errors.New(ErrRevisionNotFoundCode, errors.Alert, []string{fmt.Sprintf("Revision %d of %s not found", id, table)}, []string{"No revision with this ID was recorded for the table"}, []string{"The revision was pruned or belongs to another table"}, []string{"List the revisions of the row using ListRevisions"})
+}
+
+func ErrDatabasePing(err error) error {
+	return errors.New(ErrDatabasePingCode, errors.Critical, []string{"Database is not available"}, []string{err.Error()}, []string{"Database is unreachable", "The connection was closed", "The database file can't be read"}, []string{"Make sure your database is reachable and its file is readable"})
+}
+
+func ErrDatabaseStats(err error) error {
+	return errors.New(ErrDatabaseStatsCode, errors.Alert, []string{"Unable to get the statistics of the database"}, []string{err.Error()}, []string{"Database is unreachable", "The table " + MigrationsTable + " has no version or dirty column"}, []string{"Make sure your database is reachable", "Make sure the migrations are recorded by golang-migrate"})
+}
+
+func ErrRecordNotFound(model string, id interface{}) error {
+	return errors.New(ErrRecordNotFoundCode, errors.Alert, []string{fmt.Sprintf("%s %v not found", model, id)}, []string{fmt.Sprintf("No %s with the primary key %v was found", model, id)}, []string{"The record was deleted or belongs to another tenant", "The primary key is invalid"}, []string{"List the records using the repository of the model"})
+}
+
+func ErrRepository(err error, action, model string) error {
+	return errors.New(ErrRepositoryCode, errors.Alert, []string{fmt.Sprintf("Unable to %s %s", action, model)}, []string{err.Error()}, []string{"Database is unreachable", "The table of the model is missing or has other columns", "A column of the filters doesn't exist"}, []string{"Make sure your database is reachable and migrated", "Make sure the filters use the columns of the table"})
+}
+
+func ErrDumpTables(err error) error {
+	return errors.New(ErrDumpTablesCode, errors.Alert, []string{"Unable to dump the tables"}, []string{err.Error()}, []string{"Database is unreachable", "A table doesn't exist"}, []string{"Make sure your database is reachable and the names are the names of its tables"})
+}
+
+func ErrLoadTables(err error) error {
+	return errors.New(ErrLoadTablesCode, errors.Alert, []string{"Unable to load the tables"}, []string{err.Error()}, []string{"The dump is not a dump of DumpTables", "A table doesn't exist or has other columns", "A row already exists"}, []string{"Migrate the database before loading the dump", "Load the dump into an empty database"})
+}
+
+func ErrDumpSchemaVersion(dumped, current int64) error {
+	return errors.New(ErrDumpSchemaVersionCode, errors.Alert, []string{"The dump is of another schema version"}, []string{fmt.Sprintf("The tables were dumped at the schema version %d, the database is at the version %d", dumped, current)}, []string{"The database dumped and the database loaded were migrated to different versions"}, []string{"Migrate both databases to the same version before dumping the tables"})
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// MigrationsTable is the table recording the schema version of the database, as written by golang-migrate.
+const MigrationsTable = "schema_migrations"
+
+// Stats describes the state of the database, e.g. for the readiness endpoints of the services embedding MeshKit.
+type Stats struct {
+	// Engine is the name of the dialect, e.g. sqlite or postgres.
+	Engine string `json:"engine"`
+	// Pool are the statistics of the connection pool, e.g. the number of open connections.
+	Pool sql.DBStats `json:"pool"`
+	// WALSize is the size in bytes of the write-ahead log of a SQLite database in WAL journal mode, zero otherwise.
+	// A growing WAL means checkpoints are starved, e.g. by long-running reads.
+	WALSize int64 `json:"wal_size"`
+	// MigrationVersion is the schema version recorded in MigrationsTable, zero if the table doesn't exist.
+	MigrationVersion int64 `json:"migration_version"`
+	// MigrationDirty is set if the last migration failed, the schema has to be fixed before migrating again.
+	MigrationDirty bool `json:"migration_dirty"`
+}
+
+// Ping verifies the database is reachable by executing a statement, unlike the Ping of database/sql
+// which may succeed for SQLite databases whose file can't be read, e.g. for the liveness and readiness endpoints.
+func (h *Handler) Ping(ctx context.Context) error {
+	db, err := h.DB.DB()
+	if err != nil {
+		return ErrDatabasePing(err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return ErrDatabasePing(err)
+	}
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return ErrDatabasePing(err)
+	}
+	return nil
+}
+
+// Stats returns the statistics of the connection pool, the size of the WAL of SQLite databases and the migration version.
+// The statements are executed with the context of the handler, see gorm.DB.WithContext.
+func (h *Handler) Stats() (Stats, error) {
+	ctx := h.DB.Statement.Context
+	db, err := h.DB.DB()
+	if err != nil {
+		return Stats{}, ErrDatabaseStats(err)
+	}
+	stats := Stats{Engine: h.DB.Dialector.Name(), Pool: db.Stats()}
+	if stats.Engine == SQLITE {
+		if stats.WALSize, err = walSize(ctx, db); err != nil {
+			return stats, ErrDatabaseStats(err)
+		}
+	}
+	tx := h.DB.Session(&gorm.Session{NewDB: true})
+	if tx.Migrator().HasTable(MigrationsTable) {
+		var migration struct {
+			Version int64
+			Dirty   bool
+		}
+		if err := tx.Table(MigrationsTable).Select("version", "dirty").Order("version DESC").Limit(1).Scan(&migration).Error; err != nil {
+			return stats, ErrDatabaseStats(err)
+		}
+		stats.MigrationVersion, stats.MigrationDirty = migration.Version, migration.Dirty
+	}
+	return stats, nil
+}
+
+// walSize returns the size of the WAL file of the main database, zero for in-memory databases or other journal modes.
+func walSize(ctx context.Context, db *sql.DB) (int64, error) {
+	var seq int
+	var name, file string
+	if err := db.QueryRowContext(ctx, "SELECT seq, name, file FROM pragma_database_list WHERE name = 'main'").Scan(&seq, &name, &file); err != nil {
+		return 0, err
+	}
+	if file == "" {
+		return 0, nil
+	}
+	info, err := os.Stat(file + "-wal")
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
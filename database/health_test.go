@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestHealth(t *testing.T) {
+	h, err := New(Options{
+		Engine:   SQLITE,
+		Filename: filepath.Join(t.TempDir(), "meshery.db"),
+		SQLite:   SQLiteOptions{JournalMode: "WAL"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := h.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := h.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Engine != SQLITE || stats.Pool.OpenConnections == 0 || stats.MigrationVersion != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if err := h.Exec("CREATE TABLE schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (3, true)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if stats, err = h.Stats(); err != nil {
+		t.Fatal(err)
+	}
+	if stats.WALSize == 0 || stats.MigrationVersion != 3 || !stats.MigrationDirty {
+		t.Errorf("stats = %+v; want a WAL and the dirty migration version 3", stats)
+	}
+
+	if err := h.DBClose(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Ping(ctx); errors.GetCode(err) != ErrDatabasePingCode {
+		t.Errorf("Ping() of a closed database = %v; want %s", err, ErrDatabasePingCode)
+	}
+}
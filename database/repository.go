@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Filter narrows, orders or paginates the rows of a query, as a GORM scope, e.g. Eq("name", "pod").
+// Scopes such as ScopeTenant are Filters as well.
+type Filter func(*gorm.DB) *gorm.DB
+
+// Repo is the typed persistence of the model T, a GORM model, so that the models don't have to repeat their CRUD code.
+// The context of the methods is the context of the statements, e.g. the tenant of WithTenant.
+type Repo[T any] struct {
+	db *gorm.DB
+}
+
+// NewRepo returns the repository of the model T in the database, e.g. NewRepo[v1beta1.Host](handler.DB).
+func NewRepo[T any](db *gorm.DB) *Repo[T] {
+	return &Repo[T]{db: db}
+}
+
+func (r *Repo[T]) query(ctx context.Context, filters []Filter) *gorm.DB {
+	tx := r.db.WithContext(ctx).Model(new(T))
+	for _, f := range filters {
+		tx = f(tx)
+	}
+	return tx
+}
+
+// Query returns a statement of the model narrowed by the filters, for the queries the repository doesn't cover,
+// e.g. joins and aggregates.
+func (r *Repo[T]) Query(ctx context.Context, filters ...Filter) *gorm.DB {
+	return r.query(ctx, filters)
+}
+
+// Get returns the row of the primary key, or an error of code ErrRecordNotFoundCode if there is none.
+func (r *Repo[T]) Get(ctx context.Context, id interface{}) (*T, error) {
+	row := new(T)
+	err := r.query(ctx, nil).First(row, primaryKeyCondition(id)).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound(modelName[T](), id)
+	}
+	if err != nil {
+		return nil, ErrRepository(err, "get", modelName[T]())
+	}
+	return row, nil
+}
+
+// List returns the rows matching the filters, in the order of the filters, or of the database.
+func (r *Repo[T]) List(ctx context.Context, filters ...Filter) ([]T, error) {
+	var rows []T
+	if err := r.query(ctx, filters).Find(&rows).Error; err != nil {
+		return nil, ErrRepository(err, "list", modelName[T]())
+	}
+	return rows, nil
+}
+
+// Count returns the number of rows matching the filters, the pagination of the filters being ignored.
+func (r *Repo[T]) Count(ctx context.Context, filters ...Filter) (int64, error) {
+	var count int64
+	if err := r.query(ctx, filters).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return 0, ErrRepository(err, "count", modelName[T]())
+	}
+	return count, nil
+}
+
+// Create inserts the rows in a transaction, setting their primary keys and timestamps. None is inserted if one fails.
+func (r *Repo[T]) Create(ctx context.Context, rows ...*T) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			if err := tx.Create(row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ErrRepository(err, "create", modelName[T]())
+	}
+	return nil
+}
+
+// Update saves all the fields of the row of its primary key, or returns an error of code ErrRecordNotFoundCode if
+// there is none. Unlike Save, the row is never inserted.
+func (r *Repo[T]) Update(ctx context.Context, row *T) error {
+	tx := r.db.WithContext(ctx).Model(row).Select("*").Updates(row)
+	if tx.Error != nil {
+		return ErrRepository(tx.Error, "update", modelName[T]())
+	}
+	if tx.RowsAffected == 0 {
+		var id interface{}
+		if field := tx.Statement.Schema.PrioritizedPrimaryField; field != nil {
+			id, _ = field.ValueOf(ctx, reflect.ValueOf(row).Elem())
+		}
+		return ErrRecordNotFound(modelName[T](), id)
+	}
+	return nil
+}
+
+// Delete deletes the row of the primary key, softly if the model has a gorm.DeletedAt field, or an error of code
+// ErrRecordNotFoundCode if there is none.
+func (r *Repo[T]) Delete(ctx context.Context, id interface{}) error {
+	tx := r.db.WithContext(ctx).Delete(new(T), primaryKeyCondition(id))
+	if tx.Error != nil {
+		return ErrRepository(tx.Error, "delete", modelName[T]())
+	}
+	if tx.RowsAffected == 0 {
+		return ErrRecordNotFound(modelName[T](), id)
+	}
+	return nil
+}
+
+// primaryKeyCondition returns the condition on the primary key, as GORM only accepts numbers as inline conditions.
+func primaryKeyCondition(id interface{}) clause.Expression {
+	return clause.Eq{Column: clause.PrimaryColumn, Value: id}
+}
+
+func modelName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().Name()
+}
+
+// Eq matches the rows whose column equals the value.
+func Eq(column string, value interface{}) Filter {
+	return where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+}
+
+// Neq matches the rows whose column differs from the value.
+func Neq(column string, value interface{}) Filter {
+	return where(clause.Neq{Column: clause.Column{Name: column}, Value: value})
+}
+
+// In matches the rows whose column equals one of the values.
+func In[V any](column string, values ...V) Filter {
+	in := make([]interface{}, len(values))
+	for i, v := range values {
+		in[i] = v
+	}
+	return where(clause.IN{Column: clause.Column{Name: column}, Values: in})
+}
+
+// Like matches the rows whose column matches the pattern, e.g. "istio%".
+func Like(column, pattern string) Filter {
+	return where(clause.Like{Column: clause.Column{Name: column}, Value: pattern})
+}
+
+// Gt matches the rows whose column is greater than the value.
+func Gt(column string, value interface{}) Filter {
+	return where(clause.Gt{Column: clause.Column{Name: column}, Value: value})
+}
+
+// Lt matches the rows whose column is less than the value.
+func Lt(column string, value interface{}) Filter {
+	return where(clause.Lt{Column: clause.Column{Name: column}, Value: value})
+}
+
+// Where matches the rows of a condition of GORM, e.g. Where("json_extract(metadata, '$.status') = ?", "enabled").
+func Where(query interface{}, args ...interface{}) Filter {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
+
+// Or matches the rows matching any of the filters, which should only narrow the rows.
+func Or(filters ...Filter) Filter {
+	return func(db *gorm.DB) *gorm.DB {
+		group := db.Session(&gorm.Session{NewDB: true})
+		for i, f := range filters {
+			condition := f(db.Session(&gorm.Session{NewDB: true}))
+			if i == 0 {
+				group = group.Where(condition)
+			} else {
+				group = group.Or(condition)
+			}
+		}
+		return db.Where(group)
+	}
+}
+
+// OrderBy orders the rows by the column, in descending order if desc is set. Filters of the same query append orders.
+func OrderBy(column string, desc bool) Filter {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	}
+}
+
+// Page returns the page of the rows, the first being 1, of the size given. A size of 0 or less doesn't paginate.
+func Page(page, size int) Filter {
+	return func(db *gorm.DB) *gorm.DB {
+		if size <= 0 {
+			return db
+		}
+		if page < 1 {
+			page = 1
+		}
+		return db.Offset((page - 1) * size).Limit(size)
+	}
+}
+
+func where(condition clause.Expression) Filter {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(condition)
+	}
+}
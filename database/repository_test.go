@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"gorm.io/gorm"
+)
+
+type repoComponent struct {
+	ID        string `gorm:"primarykey"`
+	Name      string
+	Model     string
+	Version   int
+	DeletedAt gorm.DeletedAt
+}
+
+func TestRepo(t *testing.T) {
+	h := newTestHandler(t, &repoComponent{})
+	ctx := context.Background()
+	repo := NewRepo[repoComponent](h.DB)
+	err := repo.Create(ctx,
+		&repoComponent{ID: "1", Name: "Pod", Model: "kubernetes", Version: 1},
+		&repoComponent{ID: "2", Name: "Service", Model: "kubernetes", Version: 1},
+		&repoComponent{ID: "3", Name: "VirtualService", Model: "istio", Version: 2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod, err := repo.Get(ctx, "1")
+	if err != nil || pod.Name != "Pod" {
+		t.Fatalf("Get = %+v, %v", pod, err)
+	}
+	pod.Version = 2
+	if err := repo.Update(ctx, pod); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Update(ctx, &repoComponent{ID: "4", Name: "Ingress", Model: "kubernetes"}); errors.GetCode(err) != ErrRecordNotFoundCode {
+		t.Errorf("expected updating a missing row to fail, got %v", err)
+	}
+	if _, err := repo.Get(ctx, "4"); errors.GetCode(err) != ErrRecordNotFoundCode {
+		t.Errorf("expected the missing row not to be inserted, got %v", err)
+	}
+	// a failing row rolls the batch back
+	if err := repo.Create(ctx, &repoComponent{ID: "5", Name: "Ingress"}, &repoComponent{ID: "1", Name: "Pod"}); errors.GetCode(err) != ErrRepositoryCode {
+		t.Errorf("expected a duplicate primary key to fail, got %v", err)
+	}
+	if _, err := repo.Get(ctx, "5"); errors.GetCode(err) != ErrRecordNotFoundCode {
+		t.Errorf("expected the batch to be rolled back, got %v", err)
+	}
+
+	rows, err := repo.List(ctx, Or(Eq("model", "istio"), Gt("version", 1)), OrderBy("name", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Name != "VirtualService" || rows[1].Name != "Pod" {
+		t.Errorf("List = %+v", rows)
+	}
+	rows, err = repo.List(ctx, In("name", "Pod", "Service"), Like("model", "kube%"), OrderBy("name", false), Page(2, 1))
+	if err != nil || len(rows) != 1 || rows[0].Name != "Service" {
+		t.Errorf("List of the second page = %+v, %v", rows, err)
+	}
+	if rows, err = repo.List(ctx, Page(1, 0)); err != nil || len(rows) != 3 {
+		t.Errorf("List without page size = %+v, %v", rows, err)
+	}
+	if count, err := repo.Count(ctx, Eq("model", "kubernetes"), Page(1, 1)); err != nil || count != 2 {
+		t.Errorf("Count = %d, %v", count, err)
+	}
+
+	if err := repo.Delete(ctx, "2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Get(ctx, "2"); errors.GetCode(err) != ErrRecordNotFoundCode {
+		t.Errorf("expected the deleted row to be not found, got %v", err)
+	}
+	if err := repo.Delete(ctx, "2"); errors.GetCode(err) != ErrRecordNotFoundCode {
+		t.Errorf("expected deleting a missing row to fail, got %v", err)
+	}
+	if _, err := repo.List(ctx, Eq("missing", 1)); errors.GetCode(err) != ErrRepositoryCode {
+		t.Errorf("expected an unknown column to fail, got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const revisionsCallbackName = "meshkit:revisions"
+
+const (
+	// RevisionUpdate is the operation of the revisions recorded before rows are updated.
+	RevisionUpdate = "update"
+	// RevisionDelete is the operation of the revisions recorded before rows are deleted, soft deletes included.
+	RevisionDelete = "delete"
+)
+
+// Revision is a prior version of a row of a model registered using EnableRevisions.
+type Revision struct {
+	ID uint64 `json:"id" gorm:"primarykey;autoIncrement"`
+	// Table is the table of the row.
+	Table string `json:"table_name" gorm:"column:table_name;index:idx_meshkit_revisions_row"`
+	// RowID is the primary key of the row, the values of composite primary keys are separated by commas.
+	RowID string `json:"row_id" gorm:"index:idx_meshkit_revisions_row"`
+	// Operation is the operation which replaced the version, RevisionUpdate or RevisionDelete.
+	Operation string `json:"operation"`
+	// Data holds the values of the columns of the version, as a JSON object keyed by column name.
+	Data      json.RawMessage `json:"data" gorm:"type:text"`
+	CreatedAt time.Time       `json:"created_at" gorm:"index"`
+}
+
+// RevisionOptions configures the revision history of the models, see EnableRevisions.
+type RevisionOptions struct {
+	// MaxRevisions is the number of revisions kept per row, the oldest ones are pruned. All revisions are kept if zero.
+	MaxRevisions int
+}
+
+type revisions struct {
+	maxRevisions int
+	// schemas of the registered models, by table
+	schemas map[string]*schema.Schema
+}
+
+// EnableRevisions records the prior versions of the rows of the models in the meshkit_revisions table before they are updated
+// or deleted, so that accidental modifications of the registry can be undone using ListRevisions and RestoreRevision.
+//
+// Statements built using Raw and Exec are not recorded, neither are the updates of a session created using SkipHooks.
+func (h *Handler) EnableRevisions(opts RevisionOptions, models ...interface{}) error {
+	r := &revisions{
+		maxRevisions: opts.MaxRevisions,
+		schemas:      map[string]*schema.Schema{},
+	}
+	if err := h.DB.AutoMigrate(&Revision{}); err != nil {
+		return ErrRevisions(err, "migrate the revisions table")
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: h.DB}
+		if err := stmt.Parse(model); err != nil {
+			return ErrRevisionModel(err, reflect.TypeOf(model).String())
+		}
+		if len(stmt.Schema.PrimaryFields) == 0 {
+			return ErrRevisionModel(fmt.Errorf("the table %s has no primary key", stmt.Schema.Table), stmt.Schema.Name)
+		}
+		r.schemas[stmt.Schema.Table] = stmt.Schema
+	}
+
+	callback := h.DB.Callback()
+	if err := callback.Update().Before("gorm:update").Register(revisionsCallbackName, r.record(RevisionUpdate)); err != nil {
+		return ErrRevisionModel(err, "update")
+	}
+	if err := callback.Delete().Before("gorm:delete").Register(revisionsCallbackName, r.record(RevisionDelete)); err != nil {
+		return ErrRevisionModel(err, "delete")
+	}
+	return nil
+}
+
+// TableName implements the Tabler interface of GORM.
+func (Revision) TableName() string {
+	return "meshkit_revisions"
+}
+
+// record returns the callback recording the rows matched by a statement before it executes the operation.
+// The revisions are written in the transaction of the statement.
+func (r *revisions) record(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Schema == nil || r.schemas[db.Statement.Schema.Table] == nil {
+			return
+		}
+		conditions := revisionConditions(db)
+		if len(conditions) == 0 && !db.AllowGlobalUpdate {
+			// GORM rejects the statement
+			return
+		}
+		s := db.Statement.Schema
+		tx := db.Session(&gorm.Session{NewDB: true, Context: db.Statement.Context})
+		find := tx.Table(db.Statement.Table).Clauses(clause.Where{Exprs: conditions})
+		if db.Statement.Unscoped {
+			find = find.Unscoped()
+		}
+		rows := reflect.New(reflect.SliceOf(s.ModelType))
+		if err := find.Find(rows.Interface()).Error; err != nil {
+			db.AddError(ErrRevisions(err, "read the rows of "+s.Table))
+			return
+		}
+		for i := 0; i < rows.Elem().Len(); i++ {
+			revision, err := newRevision(db, s, operation, rows.Elem().Index(i))
+			if err != nil {
+				db.AddError(ErrRevisions(err, "snapshot a row of "+s.Table))
+				return
+			}
+			if err := tx.Create(revision).Error; err != nil {
+				db.AddError(ErrRevisions(err, "record a revision of "+s.Table))
+				return
+			}
+			if err := r.prune(tx, revision); err != nil {
+				db.AddError(ErrRevisions(err, "prune the revisions of "+s.Table))
+				return
+			}
+		}
+	}
+}
+
+// prune deletes the revisions of the row of the revision beyond the maximum number kept.
+func (r *revisions) prune(tx *gorm.DB, revision *Revision) error {
+	if r.maxRevisions <= 0 {
+		return nil
+	}
+	var ids []uint64
+	err := tx.Model(&Revision{}).Where("table_name = ? AND row_id = ?", revision.Table, revision.RowID).
+		Order("id DESC").Offset(r.maxRevisions).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	return tx.Where("table_name = ? AND row_id = ? AND id <= ?", revision.Table, revision.RowID, ids[0]).Delete(&Revision{}).Error
+}
+
+// revisionConditions returns the conditions of the statement, including the primary keys of its models,
+// which GORM adds as conditions of updates and deletes after the callbacks registered before them.
+func revisionConditions(db *gorm.DB) []clause.Expression {
+	var conditions []clause.Expression
+	if c, ok := db.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conditions = append(conditions, where.Exprs...)
+		}
+	}
+	s := db.Statement.Schema
+	values := []reflect.Value{db.Statement.ReflectValue}
+	if db.Statement.Model != nil {
+		values = append(values, reflect.ValueOf(db.Statement.Model))
+	}
+	for _, value := range values {
+		_, primaryValues := schema.GetIdentityFieldValuesMap(db.Statement.Context, value, s.PrimaryFields)
+		if column, queryValues := schema.ToQueryValues(clause.CurrentTable, s.PrimaryFieldDBNames, primaryValues); len(queryValues) > 0 {
+			conditions = append(conditions, clause.IN{Column: column, Values: queryValues})
+		}
+	}
+	return conditions
+}
+
+// newRevision returns the revision of the row, holding the values of its columns.
+func newRevision(db *gorm.DB, s *schema.Schema, operation string, row reflect.Value) (*Revision, error) {
+	data := make(map[string]json.RawMessage, len(s.DBNames))
+	for _, name := range s.DBNames {
+		field := s.FieldsByDBName[name]
+		value, _ := field.ValueOf(db.Statement.Context, row)
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		data[name] = b
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Revision{Table: s.Table, RowID: rowID(db, s, row), Operation: operation, Data: b}, nil
+}
+
+func rowID(db *gorm.DB, s *schema.Schema, row reflect.Value) string {
+	values := make([]string, 0, len(s.PrimaryFields))
+	for _, field := range s.PrimaryFields {
+		value, _ := field.ValueOf(db.Statement.Context, row)
+		values = append(values, fmt.Sprint(value))
+	}
+	return strings.Join(values, ",")
+}
+
+// ListRevisions returns the revisions of the row of the model with the primary key id, the latest first.
+// The values of composite primary keys are separated by commas.
+func ListRevisions(db *gorm.DB, model interface{}, id string) ([]Revision, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, ErrRevisionModel(err, reflect.TypeOf(model).String())
+	}
+	var list []Revision
+	if err := db.Where("table_name = ? AND row_id = ?", stmt.Schema.Table, id).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, ErrRevisions(err, "list the revisions of "+stmt.Schema.Table)
+	}
+	return list, nil
+}
+
+// RestoreRevision restores the row of the model to the version of the revision, recreating the row if it was deleted.
+// Soft deleted rows are restored too. If the model is registered using EnableRevisions, the version replaced is recorded,
+// so that restoring can be undone as well.
+func RestoreRevision(db *gorm.DB, model interface{}, revisionID uint64) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return ErrRevisionModel(err, reflect.TypeOf(model).String())
+	}
+	s := stmt.Schema
+	var revision Revision
+	if err := db.Where("id = ? AND table_name = ?", revisionID, s.Table).Take(&revision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrRevisionNotFound(revisionID, s.Table)
+		}
+		return ErrRevisions(err, "read the revision of "+s.Table)
+	}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(revision.Data, &data); err != nil {
+		return ErrRevisions(err, "decode the revision of "+s.Table)
+	}
+	row := reflect.New(s.ModelType)
+	for name, raw := range data {
+		field := s.LookUpField(name)
+		if field == nil {
+			// the column was dropped since
+			continue
+		}
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return ErrRevisions(err, "decode the column "+name+" of the revision of "+s.Table)
+		}
+		if err := field.Set(db.Statement.Context, row.Elem(), value.Elem().Interface()); err != nil {
+			return ErrRevisions(err, "restore the column "+name+" of "+s.Table)
+		}
+	}
+	if err := db.Unscoped().Save(row.Interface()).Error; err != nil {
+		return ErrRevisions(err, "restore the revision of "+s.Table)
+	}
+	return nil
+}
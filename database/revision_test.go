@@ -0,0 +1,100 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"gorm.io/gorm"
+)
+
+type revisionedComponent struct {
+	ID        string `gorm:"primarykey"`
+	Name      string
+	Version   int
+	DeletedAt gorm.DeletedAt
+}
+
+func newRevisionTestHandler(t *testing.T, opts RevisionOptions) Handler {
+	t.Helper()
+	h := newTestHandler(t, &revisionedComponent{})
+	if err := h.EnableRevisions(opts, &revisionedComponent{}); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestRevisions(t *testing.T) {
+	h := newRevisionTestHandler(t, RevisionOptions{})
+	if err := h.Create(&[]revisionedComponent{{ID: "1", Name: "pod", Version: 1}, {ID: "2", Name: "service", Version: 1}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(&revisionedComponent{ID: "1", Name: "pod", Version: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Model(&revisionedComponent{}).Where("name = ?", "pod").Update("version", 3).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Delete(&revisionedComponent{ID: "1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := ListRevisions(h.DB, &revisionedComponent{}, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[0].Operation != RevisionDelete || revisions[2].Operation != RevisionUpdate {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+	if others, err := ListRevisions(h.DB, &revisionedComponent{}, "2"); err != nil || len(others) != 0 {
+		t.Errorf("revisions of an unmodified row = %+v, %v", others, err)
+	}
+
+	// undo the delete, then the first update
+	if err := RestoreRevision(h.DB, &revisionedComponent{}, revisions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	var component revisionedComponent
+	if err := h.First(&component, "id = ?", "1").Error; err != nil || component.Version != 3 {
+		t.Errorf("component = %+v, %v; want version 3", component, err)
+	}
+	if err := RestoreRevision(h.DB, &revisionedComponent{}, revisions[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.First(&component, "id = ?", "1").Error; err != nil || component.Version != 1 {
+		t.Errorf("component = %+v, %v; want version 1", component, err)
+	}
+	if revisions, _ = ListRevisions(h.DB, &revisionedComponent{}, "1"); len(revisions) != 5 {
+		t.Errorf("expected the restores to be recorded, got %d revisions", len(revisions))
+	}
+
+	err = RestoreRevision(h.DB, &revisionedComponent{}, 1000)
+	if errors.GetCode(err) != ErrRevisionNotFoundCode {
+		t.Errorf("err = %v; want %s", err, ErrRevisionNotFoundCode)
+	}
+}
+
+func TestRevisionsMaxRevisions(t *testing.T) {
+	h := newRevisionTestHandler(t, RevisionOptions{MaxRevisions: 2})
+	if err := h.Create(&revisionedComponent{ID: "1", Name: "pod", Version: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for version := 2; version <= 5; version++ {
+		if err := h.Model(&revisionedComponent{ID: "1"}).Update("version", version).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	revisions, err := ListRevisions(h.DB, &revisionedComponent{}, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || string(revisions[0].Data) == string(revisions[1].Data) {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+	if err := RestoreRevision(h.DB, &revisionedComponent{}, revisions[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	var component revisionedComponent
+	if err := h.First(&component, "id = ?", "1").Error; err != nil || component.Version != 3 {
+		t.Errorf("component = %+v, %v; want version 3", component, err)
+	}
+}
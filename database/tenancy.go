@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultTenantColumn is the column holding the tenant ID, unless configured otherwise in TenancyOptions.
+const DefaultTenantColumn = "org_id"
+
+const (
+	tenancyCallbackName = "meshkit:tenancy"
+	skipTenancyKey      = "meshkit:skip_tenancy"
+)
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context carrying the tenant ID, which scopes the queries and writes executed with it,
+// e.g. db.WithContext(database.WithTenant(ctx, orgID)).Find(&designs).
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID of the context, if any.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenantID, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// TenancyOptions configures the multi-tenancy of the database, see EnableTenancy.
+type TenancyOptions struct {
+	// Column holding the tenant ID in the tables of the models, DefaultTenantColumn if empty.
+	Column string
+	// RequireTenant makes queries and writes of the models fail if the context carries no tenant ID.
+	// Otherwise they are executed unscoped.
+	RequireTenant bool
+}
+
+type tenancy struct {
+	column        string
+	requireTenant bool
+	// tables of the registered models
+	tables map[string]bool
+}
+
+// EnableTenancy scopes the queries, updates and deletes of the models to the tenant ID of the context of the statement,
+// and sets the tenant ID of the models created, the upserts only updating the rows of the tenant. The tables of the models need to have the tenant column.
+//
+// Statements built using Raw and Exec are not scoped, neither are statements of a session created using SkipTenancy.
+func (h *Handler) EnableTenancy(opts TenancyOptions, models ...interface{}) error {
+	t := &tenancy{
+		column:        opts.Column,
+		requireTenant: opts.RequireTenant,
+		tables:        map[string]bool{},
+	}
+	if t.column == "" {
+		t.column = DefaultTenantColumn
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: h.DB}
+		if err := stmt.Parse(model); err != nil {
+			return ErrTenancyModel(err, reflect.TypeOf(model).String())
+		}
+		if stmt.Schema.LookUpField(t.column) == nil {
+			return ErrTenancyModel(fmt.Errorf("the table %s has no column %s", stmt.Schema.Table, t.column), stmt.Schema.Name)
+		}
+		t.tables[stmt.Schema.Table] = true
+	}
+
+	callback := h.DB.Callback()
+	if err := callback.Query().Before("gorm:query").Register(tenancyCallbackName, t.scope); err != nil {
+		return ErrTenancyModel(err, "query")
+	}
+	if err := callback.Row().Before("gorm:row").Register(tenancyCallbackName, t.scope); err != nil {
+		return ErrTenancyModel(err, "row")
+	}
+	if err := callback.Update().Before("gorm:update").Register(tenancyCallbackName, t.scopeWrite); err != nil {
+		return ErrTenancyModel(err, "update")
+	}
+	if err := callback.Delete().Before("gorm:delete").Register(tenancyCallbackName, t.scopeWrite); err != nil {
+		return ErrTenancyModel(err, "delete")
+	}
+	if err := callback.Create().Before("gorm:create").Register(tenancyCallbackName, t.assign); err != nil {
+		return ErrTenancyModel(err, "create")
+	}
+	return nil
+}
+
+// SkipTenancy returns a session whose statements are not scoped to a tenant, e.g. for administrative tasks across tenants.
+func SkipTenancy(db *gorm.DB) *gorm.DB {
+	return db.Set(skipTenancyKey, true)
+}
+
+// ScopeTenant is a GORM scope restricting a statement to the tenant explicitly,
+// e.g. db.Scopes(database.ScopeTenant(orgID)).Find(&designs), for models not registered using EnableTenancy.
+func ScopeTenant(tenantID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: DefaultTenantColumn}, Value: tenantID})
+	}
+}
+
+// tenant returns the tenant ID of the statement if it has to be scoped.
+func (t *tenancy) tenant(db *gorm.DB) (string, bool) {
+	if db.Error != nil || db.Statement.Schema == nil || !t.tables[db.Statement.Schema.Table] {
+		return "", false
+	}
+	if skip, ok := db.Get(skipTenancyKey); ok && skip == true {
+		return "", false
+	}
+	tenantID, ok := TenantFromContext(db.Statement.Context)
+	if !ok {
+		if t.requireTenant {
+			db.AddError(ErrTenantMissing(db.Statement.Schema.Table))
+		}
+		return "", false
+	}
+	return tenantID, true
+}
+
+func (t *tenancy) scope(db *gorm.DB) {
+	if tenantID, ok := t.tenant(db); ok {
+		t.where(db, tenantID)
+	}
+}
+
+// scopeWrite scopes updates and deletes. As the tenant condition satisfies GORM's check for global updates and deletes,
+// that check is done here before adding it.
+func (t *tenancy) scopeWrite(db *gorm.DB) {
+	tenantID, ok := t.tenant(db)
+	if !ok {
+		return
+	}
+	if !db.AllowGlobalUpdate && !hasConditions(db) {
+		db.AddError(gorm.ErrMissingWhereClause)
+		return
+	}
+	t.where(db, tenantID)
+}
+
+func (t *tenancy) where(db *gorm.DB, tenantID string) {
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: t.column}, Value: tenantID},
+	}})
+}
+
+// assign sets the tenant of the rows created. The updates of the upserts, e.g. of Save when no row is updated, are
+// restricted to the rows of the tenant, so that a tenant can't take over the rows of another one.
+func (t *tenancy) assign(db *gorm.DB) {
+	tenantID, ok := t.tenant(db)
+	if !ok {
+		return
+	}
+	db.Statement.SetColumn(t.column, tenantID, true)
+	c, ok := db.Statement.Clauses["ON CONFLICT"]
+	if !ok {
+		return
+	}
+	if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+		onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: t.column}, Value: tenantID})
+		c.Expression = onConflict
+		db.Statement.Clauses["ON CONFLICT"] = c
+	}
+}
+
+// hasConditions reports whether the statement has conditions, or GORM adds the primary keys of the models as conditions.
+func hasConditions(db *gorm.DB) bool {
+	if _, ok := db.Statement.Clauses["WHERE"]; ok {
+		return true
+	}
+	s := db.Statement.Schema
+	if _, values := schema.GetIdentityFieldValuesMap(db.Statement.Context, db.Statement.ReflectValue, s.PrimaryFields); len(values) > 0 {
+		return true
+	}
+	if db.Statement.Model != nil {
+		if _, values := schema.GetIdentityFieldValuesMap(db.Statement.Context, reflect.ValueOf(db.Statement.Model), s.PrimaryFields); len(values) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tenantDesign struct {
+	ID    string `gorm:"primarykey"`
+	OrgID string
+	Name  string
+}
+
+type untenantedModel struct {
+	ID string `gorm:"primarykey"`
+}
+
+func newTenancyTestHandler(t *testing.T, opts TenancyOptions) Handler {
+	t.Helper()
+	h := newTestHandler(t, &tenantDesign{})
+	if err := h.EnableTenancy(opts, &tenantDesign{}); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestTenancy(t *testing.T) {
+	h := newTenancyTestHandler(t, TenancyOptions{})
+	org1 := h.WithContext(WithTenant(context.Background(), "org1"))
+	org2 := h.WithContext(WithTenant(context.Background(), "org2"))
+
+	if err := org1.Create(&[]tenantDesign{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := org2.Create(&tenantDesign{ID: "3", Name: "c", OrgID: "org1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var designs []tenantDesign
+	if err := org1.Order("id").Find(&designs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(designs) != 2 || designs[0].OrgID != "org1" || designs[1].OrgID != "org1" {
+		t.Errorf("designs of org1 = %+v", designs)
+	}
+	var count int64
+	if err := org2.Model(&tenantDesign{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("count of org2 = %d, %v; want 1", count, err)
+	}
+
+	// updates and deletes of other tenants do not affect the rows
+	if err := org2.Model(&tenantDesign{}).Where("id = ?", "1").Update("name", "changed").Error; err != nil {
+		t.Fatal(err)
+	}
+	if res := org2.Delete(&tenantDesign{ID: "2"}); res.Error != nil || res.RowsAffected != 0 {
+		t.Errorf("delete of org2 affected %d rows, %v", res.RowsAffected, res.Error)
+	}
+	if err := org2.Delete(&tenantDesign{}).Error; err != gorm.ErrMissingWhereClause {
+		t.Errorf("global delete = %v; want %v", err, gorm.ErrMissingWhereClause)
+	}
+	// Save upserts when the scoped update changes no row, the upsert must not take over the row of org1
+	if err := org2.Save(&tenantDesign{ID: "1", Name: "hijacked"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := org2.Clauses(clause.OnConflict{UpdateAll: true}).Create(&tenantDesign{ID: "2", Name: "hijacked"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// the upserts of a tenant still update its own rows
+	if err := org2.Clauses(clause.OnConflict{UpdateAll: true}).Create(&tenantDesign{ID: "3", Name: "d"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var design tenantDesign
+	if err := org2.First(&design, "id = ?", "3").Error; err != nil || design.Name != "d" {
+		t.Errorf("design of org2 = %+v, %v", design, err)
+	}
+	var hijacked int64
+	if err := SkipTenancy(h.DB).Model(&tenantDesign{}).Where("org_id = ? AND name = ?", "org1", "hijacked").Or("org_id = ? AND id IN ?", "org2", []string{"1", "2"}).Count(&hijacked).Error; err != nil || hijacked != 0 {
+		t.Errorf("rows of org1 taken over by org2 = %d, %v", hijacked, err)
+	}
+	design = tenantDesign{}
+	if err := SkipTenancy(h.DB).First(&design, "id = ?", "1").Error; err != nil || design.Name != "a" {
+		t.Errorf("design = %+v, %v", design, err)
+	}
+	if err := SkipTenancy(h.DB).Model(&tenantDesign{}).Count(&count).Error; err != nil || count != 3 {
+		t.Errorf("count of all tenants = %d, %v; want 3", count, err)
+	}
+	if err := h.Scopes(ScopeTenant("org2")).Model(&tenantDesign{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("count of explicitly scoped org2 = %d, %v; want 1", count, err)
+	}
+}
+
+func TestTenancyRequireTenant(t *testing.T) {
+	h := newTenancyTestHandler(t, TenancyOptions{RequireTenant: true})
+	var designs []tenantDesign
+	err := h.Find(&designs).Error
+	if err == nil || errors.GetCode(err) != ErrTenantMissingCode {
+		t.Errorf("err = %v; want %s", err, ErrTenantMissingCode)
+	}
+	if err := h.EnableTenancy(TenancyOptions{}, &untenantedModel{}); err == nil {
+		t.Error("tenancy of a model without tenant column succeeded")
+	}
+}
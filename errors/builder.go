@@ -0,0 +1,100 @@
+package errors
+
+// Builder builds a MeshKit error step by step, an alternative to the positional arguments of New(...).
+//
+// The same conventions as for New(...) apply, so that the errorutil tool can extract the error details:
+// the code has to be passed as the error code constant (or variable), the severity as one of the Severity constants,
+// and the descriptions should be string literals. Build has to be called at the end of the same chain.
+//
+// Example:
+//
+//	errors.NewBuilder(ErrConnectCode).
+//		Severity(errors.Alert).
+//		ShortDescription("Connection to broker failed").
+//		ProbableCause("Endpoint might not be reachable").
+//		Remedy("Make sure the NATS endpoint is reachable").
+//		Cause(err).
+//		Build()
+type Builder struct {
+	err    Error
+	params Parameters
+	causes []error
+}
+
+// NewBuilder returns a Builder of an error with the code.
+// Unless set, the severity is None and the descriptions are empty.
+func NewBuilder(code string) *Builder {
+	return &Builder{
+		err: Error{
+			Code:                 code,
+			Severity:             None,
+			ShortDescription:     []string{},
+			LongDescription:      []string{},
+			ProbableCause:        []string{},
+			SuggestedRemediation: []string{},
+		},
+	}
+}
+
+// Severity sets the severity of the error.
+func (b *Builder) Severity(severity Severity) *Builder {
+	b.err.Severity = severity
+	return b
+}
+
+// ShortDescription appends the statements to the short description.
+func (b *Builder) ShortDescription(statements ...string) *Builder {
+	b.err.ShortDescription = append(b.err.ShortDescription, statements...)
+	return b
+}
+
+// LongDescription appends the statements to the long description.
+func (b *Builder) LongDescription(statements ...string) *Builder {
+	b.err.LongDescription = append(b.err.LongDescription, statements...)
+	return b
+}
+
+// ProbableCause appends the statements to the probable cause.
+func (b *Builder) ProbableCause(statements ...string) *Builder {
+	b.err.ProbableCause = append(b.err.ProbableCause, statements...)
+	return b
+}
+
+// Remedy appends the statements to the suggested remediation.
+func (b *Builder) Remedy(statements ...string) *Builder {
+	b.err.SuggestedRemediation = append(b.err.SuggestedRemediation, statements...)
+	return b
+}
+
+// Parameters adds the parameters the description templates are rendered with when the error is built, see Error.WithParameters.
+func (b *Builder) Parameters(params Parameters) *Builder {
+	if b.params == nil {
+		b.params = Parameters{}
+	}
+	for k, v := range params {
+		b.params[k] = v
+	}
+	return b
+}
+
+// Cause adds the errors whose messages are appended to the long description when the error is built,
+// after the description templates are rendered, so that they are never rendered as templates.
+func (b *Builder) Cause(causes ...error) *Builder {
+	b.causes = append(b.causes, causes...)
+	return b
+}
+
+// Build returns the error. The builder can be reused, the errors built don't share their descriptions.
+func (b *Builder) Build() *Error {
+	register(b.err.Code, b.err.Severity)
+	err := b.err
+	err.ShortDescription = append([]string{}, b.err.ShortDescription...)
+	err.LongDescription = append([]string{}, b.err.LongDescription...)
+	err.ProbableCause = append([]string{}, b.err.ProbableCause...)
+	err.SuggestedRemediation = append([]string{}, b.err.SuggestedRemediation...)
+	built := &err
+	if b.params != nil {
+		built = err.WithParameters(b.params)
+	}
+	return built.WithCauses(b.causes...)
+}
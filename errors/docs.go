@@ -0,0 +1,68 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DefaultDocsBaseURL is the base URL of the error code reference of the Meshery documentation.
+const DefaultDocsBaseURL = "https://docs.meshery.io/reference/error-codes"
+
+// docsRegistry holds the base URL of the documentation and the types of the components, by name.
+var docsRegistry = struct {
+	mu         sync.RWMutex
+	baseURL    string
+	components map[string]string
+}{
+	baseURL: DefaultDocsBaseURL,
+	components: map[string]string{
+		"meshkit":    "library",
+		"meshery":    "server",
+		"mesheryctl": "client",
+	},
+}
+
+// SetDocsBaseURL overrides the base URL of the error code reference, e.g. for a self-hosted copy of the documentation.
+func SetDocsBaseURL(baseURL string) {
+	docsRegistry.mu.Lock()
+	defer docsRegistry.mu.Unlock()
+	docsRegistry.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// RegisterComponent registers the type of the component, as in its component_info.json, e.g. "adapter" for "meshery-istio",
+// so that DocsURL links to the page of its errors.
+func RegisterComponent(name, componentType string) {
+	docsRegistry.mu.Lock()
+	defer docsRegistry.mu.Unlock()
+	docsRegistry.components[name] = componentType
+}
+
+// DocsURL returns the URL of the documentation of the MeshKit error err is or wraps, empty if there is none.
+// The errors are documented by component, the name of the component being the prefix of the code, e.g. meshkit-11000:
+// <base URL>/<component type>/<component name>#<code>. Codes of unregistered components, or without prefix,
+// link to the reference itself.
+//
+// Example:
+//
+//	fmt.Printf("%s\nSee %s\n", err, errors.DocsURL(err))
+func DocsURL(err error) string {
+	var e *Error
+	if !stderrors.As(err, &e) || e == nil || strings.TrimSpace(e.Code) == "" {
+		return ""
+	}
+	docsRegistry.mu.RLock()
+	defer docsRegistry.mu.RUnlock()
+	// the code is the last segment, the name can contain dashes, e.g. meshery-istio-1000
+	name := ""
+	if i := strings.LastIndex(e.Code, "-"); i > 0 && strings.IndexFunc(e.Code[i+1:], func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		name = e.Code[:i]
+	}
+	componentType, ok := docsRegistry.components[name]
+	if !ok {
+		return fmt.Sprintf("%s#%s", docsRegistry.baseURL, e.Code)
+	}
+	return fmt.Sprintf("%s/%s/%s#%s", docsRegistry.baseURL, componentType, name, e.Code)
+}
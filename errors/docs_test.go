@@ -0,0 +1,29 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDocsURL(t *testing.T) {
+	err := New("meshkit-11000", Alert, []string{"Test"}, []string{"test"}, nil, nil)
+	if got := DocsURL(fmt.Errorf("wrapped: %w", err)); got != "https://docs.meshery.io/reference/error-codes/library/meshkit#meshkit-11000" {
+		t.Errorf("DocsURL = %s", got)
+	}
+
+	RegisterComponent("meshery-istio", "adapter")
+	SetDocsBaseURL("https://docs.example.com/errors/")
+	defer SetDocsBaseURL(DefaultDocsBaseURL)
+	for code, want := range map[string]string{
+		"meshery-istio-1000": "https://docs.example.com/errors/adapter/meshery-istio#meshery-istio-1000",
+		"unknown-1":          "https://docs.example.com/errors#unknown-1",
+		"1001":               "https://docs.example.com/errors#1001",
+	} {
+		if got := DocsURL(New(code, Alert, nil, nil, nil, nil)); got != want {
+			t.Errorf("DocsURL(%s) = %s, want %s", code, got, want)
+		}
+	}
+	if got := DocsURL(fmt.Errorf("not a MeshKit error")); got != "" {
+		t.Errorf("expected no URL, got %s", got)
+	}
+}
@@ -0,0 +1,18 @@
+package errors
+
+import "fmt"
+
+const (
+	ErrCodeConflictCode = "meshkit-11305"
+	ErrPanicCode        = "meshkit-11328"
+)
+
+// ErrCodeConflict is the warning of an error code created with different metadata at runtime, see Conflicts.
+func ErrCodeConflict(first, other Registration) error {
+	return New(ErrCodeConflictCode, Alert, []string{"Error code used by different errors"}, []string{fmt.Sprintf("error code %s was first created with severity %d by %s, then with severity %d by %s", first.Code, first.Severity, first.Origin, other.Severity, other.Origin)}, []string{"The same error code is defined by different packages or modules linked into the binary", "The error code was copied and not updated"}, []string{"Give each error its own code, using the errorutil tool to assign the codes"})
+}
+
+// ErrPanic is the error of a recovered panic, with the code designated by the caller or ErrPanicCode, see Recover.
+func ErrPanic(code string, r interface{}) *Error {
+	return New(code, Critical, []string{"Unexpected panic"}, []string{fmt.Sprint(r)}, []string{"A bug in the code, e.g. a nil pointer dereference or an index out of range"}, []string{"Report the issue along with the stack trace of the error"})
+}
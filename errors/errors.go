@@ -11,7 +11,8 @@
 // The initial value of the code is a placeholder string, e.g. "replace_me", set by the developer.
 // The final value of the code is an integer, set by the errorutil tool, as part of a CI workflow.
 //
-// 2) Error details defined using the function New(...) in this package, see below for details.
+// 2) Error details defined using the function New(...) in this package, see below for details,
+// or using the Builder returned by NewBuilder(...).
 //
 // Additionally, the following conventions apply:
 //
@@ -23,6 +24,10 @@
 //
 // See also the doc command of errorutil, and https://docs.meshery.io/project/contributing/contributing-error.
 //
+// At runtime, the codes of the errors created are registered with their severity and the function creating them,
+// so that codes used by different errors are detected across the modules linked into a binary, which the errorutil tool
+// analyzing one repository can't see. See Conflicts and SetConflictLogger.
+//
 // Example:
 //
 //	 const  ErrConnectCode        = "11000"
@@ -40,6 +45,7 @@ import "strings"
 
 // Deprecated: NewDefault is deprecated, use New(...) instead.
 func NewDefault(code string, ldescription ...string) *Error {
+	register(code, None)
 	return &Error{
 		Code:                 code,
 		Severity:             None,
@@ -73,6 +79,7 @@ func NewDefault(code string, ldescription ...string) *Error {
 //	           []string{"Endpoint might not be reachable"},
 //	           []string{"Make sure the NATS endpoint is reachable"})
 func New(code string, severity Severity, sdescription []string, ldescription []string, probablecause []string, remedy []string) *Error {
+	register(code, severity)
 	return &Error{
 		Code:                 code,
 		Severity:             severity,
@@ -84,6 +91,7 @@ func New(code string, severity Severity, sdescription []string, ldescription []s
 }
 
 func NewV2(code string, severity Severity, sdescription []string, ldescription []string, probablecause []string, remedy []string, additionalInfo interface{}) *ErrorV2 {
+	register(code, severity)
 	return &ErrorV2{
 		Code:                 code,
 		Severity:             severity,
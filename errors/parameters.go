@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Parameters are the values of the placeholders in the description templates of an error,
+// e.g. {"Name": "istio-operator", "Namespace": "istio-system"} for the template
+// "Failed to apply manifest {{.Name}} in namespace {{.Namespace}}".
+type Parameters map[string]interface{}
+
+// WithParameters returns a copy of the error with its descriptions, probable causes and remedies rendered as templates
+// using the parameters, and the parameters stored for structured access. The error itself is left unchanged, e.g.
+// an error declared once can be rendered with different parameters.
+//
+// The statements are kept as string literals in the call to New(...) or the Builder, so that the errorutil tool
+// exports the static template text for the documentation. Dynamic statements, e.g. err.Error(), would be rendered as
+// templates as well, they are appended with WithCauses once the statements are rendered instead.
+// The parameters are rendered by their string representation, formatted with fmt.Sprint before rendering, which calls
+// their String or Error method if any. The templates can't call functions or other methods of the values.
+// A statement that is no valid template or refers to a missing parameter is left unchanged.
+//
+// Example:
+//
+//	errors.New(ErrApplyManifestCode,
+//	           errors.Alert,
+//	           []string{"Failed to apply manifest {{.Name}} in namespace {{.Namespace}}"},
+//	           []string{},
+//	           []string{"Namespace {{.Namespace}} might not exist"},
+//	           []string{"Make sure the namespace {{.Namespace}} exists"}).
+//		WithParameters(errors.Parameters{"Name": name, "Namespace": namespace}).
+//		WithCauses(err)
+func (e *Error) WithParameters(params Parameters) *Error {
+	rendered := *e
+	rendered.Parameters = nil
+	rendered.addParameters(e.Parameters)
+	rendered.addParameters(params)
+	values := make(map[string]string, len(rendered.Parameters))
+	for k, v := range rendered.Parameters {
+		values[k] = fmt.Sprint(v)
+	}
+	rendered.ShortDescription = renderStatements(e.ShortDescription, values)
+	rendered.LongDescription = renderStatements(e.LongDescription, values)
+	rendered.ProbableCause = renderStatements(e.ProbableCause, values)
+	rendered.SuggestedRemediation = renderStatements(e.SuggestedRemediation, values)
+	return &rendered
+}
+
+// WithCauses appends the messages of the causes to the long description as they are, they are not rendered as templates.
+func (e *Error) WithCauses(causes ...error) *Error {
+	for _, cause := range causes {
+		if cause != nil {
+			e.LongDescription = append(e.LongDescription, cause.Error())
+		}
+	}
+	return e
+}
+
+// addParameters stores the parameters in the error without rendering its statements.
+func (e *Error) addParameters(params Parameters) {
+	if e.Parameters == nil {
+		e.Parameters = Parameters{}
+	}
+	for k, v := range params {
+		e.Parameters[k] = v
+	}
+}
+
+// GetParameters returns the parameters the descriptions of the error were rendered with, if any.
+func GetParameters(err error) Parameters {
+	if obj, ok := err.(*Error); ok && obj != nil {
+		return obj.Parameters
+	}
+	return nil
+}
+
+func renderStatements(statements []string, values map[string]string) []string {
+	rendered := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		rendered = append(rendered, renderStatement(statement, values))
+	}
+	return rendered
+}
+
+func renderStatement(statement string, values map[string]string) string {
+	if !strings.Contains(statement, "{{") {
+		return statement
+	}
+	tmpl, err := template.New("statement").Option("missingkey=error").Parse(statement)
+	if err != nil {
+		return statement
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, values); err != nil {
+		return statement
+	}
+	return sb.String()
+}
@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const errTestApplyCode = "test"
+
+type stringer struct{ called *bool }
+
+func (s stringer) Secret() string {
+	*s.called = true
+	return "secret"
+}
+
+func TestWithParameters(t *testing.T) {
+	err := New(errTestApplyCode, Alert,
+		[]string{"Failed to apply manifest {{.Name}} in namespace {{.Namespace}}"},
+		[]string{"Static text", "Missing {{.Missing}}", "Invalid {{.Name"},
+		[]string{"Unsafe {{call .Namespace}}"},
+		[]string{"Make sure the namespace {{.Namespace}} exists"}).
+		WithParameters(Parameters{"Name": "operator", "Namespace": "istio-system"})
+
+	if got, want := err.ShortDescription, []string{"Failed to apply manifest operator in namespace istio-system"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortDescription = %v; want %v", got, want)
+	}
+	if got, want := err.LongDescription, []string{"Static text", "Missing {{.Missing}}", "Invalid {{.Name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LongDescription = %v; want %v", got, want)
+	}
+	if got, want := err.ProbableCause, []string{"Unsafe {{call .Namespace}}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ProbableCause = %v; want %v", got, want)
+	}
+	if got, want := err.SuggestedRemediation, []string{"Make sure the namespace istio-system exists"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestedRemediation = %v; want %v", got, want)
+	}
+	if got := GetParameters(err); got["Namespace"] != "istio-system" || got["Name"] != "operator" {
+		t.Errorf("GetParameters() = %v", got)
+	}
+}
+
+func TestWithParametersDoesNotCallMethods(t *testing.T) {
+	called := false
+	err := NewBuilder(errTestApplyCode).
+		ShortDescription("Value {{.Value.Secret}}").
+		Parameters(Parameters{"Value": stringer{called: &called}}).
+		Build()
+	if called {
+		t.Error("method of a parameter was called while rendering")
+	}
+	if got, want := err.ShortDescription, []string{"Value {{.Value.Secret}}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortDescription = %v; want %v", got, want)
+	}
+}
+
+func TestWithCausesAreNotRendered(t *testing.T) {
+	cause := fmt.Errorf("namespace {{.Name}} not found")
+	err := NewBuilder(errTestApplyCode).
+		ShortDescription("Failed to apply manifest {{.Name}}").
+		Cause(cause).
+		Parameters(Parameters{"Name": "operator"}).
+		Build()
+	if got, want := err.ShortDescription, []string{"Failed to apply manifest operator"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortDescription = %v; want %v", got, want)
+	}
+	if got, want := err.LongDescription, []string{"namespace {{.Name}} not found"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LongDescription = %v; want %v", got, want)
+	}
+
+	err = New(errTestApplyCode, Alert, []string{"Failed to apply manifest {{.Name}}"}, []string{}, []string{}, []string{}).
+		WithParameters(Parameters{"Name": "operator"}).
+		WithCauses(cause, nil)
+	if got, want := err.LongDescription, []string{"namespace {{.Name}} not found"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LongDescription = %v; want %v", got, want)
+	}
+}
+
+func TestWithParametersReturnsCopy(t *testing.T) {
+	declared := New(errTestApplyCode, Alert, []string{"Failed to apply manifest {{.Name}}"}, []string{}, []string{}, []string{})
+	first := declared.WithParameters(Parameters{"Name": "operator"})
+	second := declared.WithParameters(Parameters{"Name": "adapter"})
+	if got, want := declared.ShortDescription, []string{"Failed to apply manifest {{.Name}}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortDescription of the declared error = %v; want %v", got, want)
+	}
+	if declared.Parameters != nil {
+		t.Errorf("Parameters of the declared error = %v; want none", declared.Parameters)
+	}
+	if got, want := first.ShortDescription, []string{"Failed to apply manifest operator"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortDescription = %v; want %v", got, want)
+	}
+	if got, want := second.ShortDescription, []string{"Failed to apply manifest adapter"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShortDescription = %v; want %v", got, want)
+	}
+	if GetParameters(first)["Name"] != "operator" {
+		t.Errorf("GetParameters() = %v", GetParameters(first))
+	}
+}
@@ -0,0 +1,22 @@
+package errors
+
+import stderrors "errors"
+
+// HasCode returns whether err, or an error it wraps, is a MeshKit error with the code.
+// Use it to branch on specific MeshKit errors, preferably through the IsErr... predicates generated by
+// the predicates command of errorutil, instead of comparing the codes returned by GetCode.
+func HasCode(err error, code string) bool {
+	var e *Error
+	return stderrors.As(err, &e) && e != nil && e.Code == code
+}
+
+// Predicate returns a function reporting whether an error has the code, see HasCode.
+//
+// Example:
+//
+//	var IsErrConnect = errors.Predicate(ErrConnectCode)
+func Predicate(code string) func(err error) bool {
+	return func(err error) bool {
+		return HasCode(err, code)
+	}
+}
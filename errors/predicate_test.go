@@ -0,0 +1,26 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHasCode(t *testing.T) {
+	err := New(errTestApplyCode, Alert, []string{"Failed to apply"}, []string{}, []string{}, []string{})
+	isErrTestApply := Predicate(errTestApplyCode)
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{err, true},
+		{fmt.Errorf("deploying: %w", err), true},
+		{New("other", Alert, []string{}, []string{}, []string{}, []string{}), false},
+		{fmt.Errorf("deploying: %v", err), false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if got := isErrTestApply(test.err); got != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.err, test.expected, got)
+		}
+	}
+}
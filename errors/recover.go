@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// StackParameter is the parameter of the errors created from panics holding the stack trace of the panic, see GetParameters.
+const StackParameter = "Stack"
+
+// Recover converts a panic of the function deferring it into a MeshKit error with the code, and sets it in err,
+// so that the caller handles it as any other error. Recover has to be deferred directly, and err has to be the named
+// result of the function. The stack trace of the panic is kept in the StackParameter of the error.
+// A MeshKit error passed to panic is returned as is.
+//
+// Example:
+//
+//	func (h *Handler) Handle(msg *broker.Message) (err error) {
+//		defer errors.Recover(&err, ErrHandleMessagePanicCode)
+//		...
+//	}
+func Recover(err *error, code string) {
+	if r := recover(); r != nil {
+		*err = fromPanic(r, code, debug.Stack())
+	}
+}
+
+// WrapPanic returns fn converting its panics into MeshKit errors with the code ErrPanicCode, see Recover.
+func WrapPanic(fn func() error) func() error {
+	return func() (err error) {
+		defer Recover(&err, ErrPanicCode)
+		return fn()
+	}
+}
+
+// RecoverHTTP converts the panics of the handler into MeshKit errors with the code, reported to report if not nil,
+// and responds with the status 500 and the code of the error, instead of closing the connection.
+func RecoverHTTP(code string, next http.Handler, report func(r *http.Request, err error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				// the server aborts the response on purpose
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				err := fromPanic(rec, code, debug.Stack())
+				if report != nil {
+					report(r, err)
+				}
+				http.Error(w, fmt.Sprintf("%s: %s", GetCode(err), GetSDescription(err)), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func fromPanic(r interface{}, code string, stack []byte) error {
+	if e, ok := r.(*Error); ok && e != nil {
+		return e
+	}
+	// the description holds the value of the panic, it is no template
+	err := ErrPanic(code, r)
+	err.addParameters(Parameters{StackParameter: string(stack)})
+	return err
+}
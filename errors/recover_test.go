@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const errTestPanicCode = "test-recover-1"
+
+func handle(items []int) (err error) {
+	defer Recover(&err, errTestPanicCode)
+	_ = items[len(items)]
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	err := handle(nil)
+	if !HasCode(err, errTestPanicCode) || GetSeverity(err) != Critical {
+		t.Fatalf("expected the panic to be converted, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "index out of range") {
+		t.Errorf("expected the panic value in the long description, got %q", err.Error())
+	}
+	if stack, _ := GetParameters(err)[StackParameter].(string); !strings.Contains(stack, "errors.handle") {
+		t.Errorf("expected the stack trace of the panic, got %q", stack)
+	}
+
+	original := New("test-recover-2", Alert, []string{"Original"}, []string{"original"}, nil, nil)
+	err = WrapPanic(func() error { panic(original) })()
+	if err != original {
+		t.Errorf("expected the MeshKit error passed to panic to be returned as is, got %v", err)
+	}
+	if err := WrapPanic(func() error { return nil })(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := WrapPanic(func() error { panic("boom") })(); !HasCode(err, ErrPanicCode) {
+		t.Errorf("expected the default code, got %v", err)
+	}
+	// the value of the panic is no template
+	if err := WrapPanic(func() error { panic("boom {{.Stack}}") })(); !strings.Contains(err.Error(), "boom {{.Stack}}") {
+		t.Errorf("expected the value of the panic as is, got %q", err.Error())
+	}
+}
+
+func TestRecoverHTTP(t *testing.T) {
+	var reported error
+	h := RecoverHTTP(errTestPanicCode, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), func(r *http.Request, err error) { reported = err })
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), errTestPanicCode) {
+		t.Errorf("response = %d %q", rec.Code, rec.Body.String())
+	}
+	if !HasCode(reported, errTestPanicCode) {
+		t.Errorf("expected the panic to be reported, got %v", reported)
+	}
+}
@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Registration is the metadata an error code was created with at runtime.
+type Registration struct {
+	Code     string
+	Severity Severity
+	// Origin is the function which created the error, e.g. github.com/layer5io/meshkit/broker.ErrInvalidSubject.
+	Origin string
+}
+
+// Conflict is an error code created with different metadata, i.e. with another severity or by another function,
+// typically because the same code is used by different packages or modules linked into the same binary.
+type Conflict struct {
+	Code string
+	// Registrations are the first registration of the code, followed by the different ones, in the order they were seen.
+	Registrations []Registration
+}
+
+// Warner is the part of a logger the conflicts are reported to, e.g. the logger.Handler of MeshKit.
+type Warner interface {
+	Warn(err error)
+}
+
+type registry struct {
+	mu     sync.Mutex
+	codes  map[string][]Registration
+	logger Warner
+}
+
+var runtimeRegistry = &registry{codes: make(map[string][]Registration)}
+
+// SetConflictLogger sets the logger warned about conflicting error codes once per conflicting registration, none if nil.
+// Conflicts are recorded regardless of the logger, see Conflicts.
+func SetConflictLogger(logger Warner) {
+	runtimeRegistry.mu.Lock()
+	defer runtimeRegistry.mu.Unlock()
+	runtimeRegistry.logger = logger
+}
+
+// Conflicts returns the error codes created with different metadata so far, sorted by code, e.g. for a health endpoint.
+//
+// The errors created using New(...), NewV2(...), NewDefault(...) and Builder.Build() are registered by code,
+// along with their severity and the function which created them. The descriptions are not compared,
+// as they often contain runtime values. Placeholder codes, which have no digit, e.g. "replace_me", are ignored.
+func Conflicts() []Conflict {
+	runtimeRegistry.mu.Lock()
+	defer runtimeRegistry.mu.Unlock()
+	conflicts := []Conflict{}
+	for code, registrations := range runtimeRegistry.codes {
+		if len(registrations) > 1 {
+			conflicts = append(conflicts, Conflict{Code: code, Registrations: append([]Registration{}, registrations...)})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Code < conflicts[j].Code })
+	return conflicts
+}
+
+// register registers the code created by the caller of the function calling register.
+func register(code string, severity Severity) {
+	if strings.IndexFunc(code, unicode.IsDigit) == -1 {
+		return
+	}
+	r := Registration{Code: code, Severity: severity, Origin: origin(3)}
+	runtimeRegistry.mu.Lock()
+	registrations := runtimeRegistry.codes[code]
+	for _, known := range registrations {
+		if known == r {
+			runtimeRegistry.mu.Unlock()
+			return
+		}
+	}
+	runtimeRegistry.codes[code] = append(registrations, r)
+	logger := runtimeRegistry.logger
+	runtimeRegistry.mu.Unlock()
+	// the warning is an error itself, registered once with the same origin, so that it can't warn again
+	if len(registrations) > 0 && logger != nil {
+		logger.Warn(ErrCodeConflict(registrations[0], r))
+	}
+}
+
+// origin returns the name of the function skip frames up the stack, without the suffixes of closures.
+func origin(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i == -1 || strings.IndexFunc(name[i+len(".func"):], func(r rune) bool { return !unicode.IsDigit(r) && r != '.' }) != -1 {
+			return name
+		}
+		name = name[:i]
+	}
+}
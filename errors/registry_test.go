@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	errTestRegisteredCode = "test-1"
+	errTestConflictCode   = "test-2"
+)
+
+type warner struct{ warnings []error }
+
+func (w *warner) Warn(err error) { w.warnings = append(w.warnings, err) }
+
+func errTestFirst() error {
+	return New(errTestConflictCode, Alert, []string{"First"}, []string{}, []string{}, []string{})
+}
+
+func errTestSecond() error {
+	return NewBuilder(errTestConflictCode).Severity(Alert).ShortDescription("Second").Build()
+}
+
+func TestConflicts(t *testing.T) {
+	w := &warner{}
+	SetConflictLogger(w)
+	defer SetConflictLogger(nil)
+
+	for i := 0; i < 3; i++ {
+		// the descriptions are not compared, nor are the registrations of the same function
+		_ = New(errTestRegisteredCode, Alert, []string{"Attempt"}, []string{strings.Repeat("again", i)}, []string{}, []string{})
+		_ = New("replace_me", Alert, []string{}, []string{}, []string{}, []string{})
+		_ = func() error { return New("replace_me", Critical, []string{}, []string{}, []string{}, []string{}) }()
+	}
+	_ = errTestFirst()
+	_ = errTestSecond()
+	_ = errTestSecond()
+
+	var conflicts []Conflict
+	for _, c := range Conflicts() {
+		if strings.HasPrefix(c.Code, "test-") {
+			conflicts = append(conflicts, c)
+		}
+	}
+	if len(conflicts) != 1 || conflicts[0].Code != errTestConflictCode || len(conflicts[0].Registrations) != 2 {
+		t.Fatalf("expected a conflict of %s, got %+v", errTestConflictCode, conflicts)
+	}
+	if origin := conflicts[0].Registrations[0].Origin; origin != "github.com/layer5io/meshkit/errors.errTestFirst" {
+		t.Errorf("unexpected origin %s", origin)
+	}
+	if origin := conflicts[0].Registrations[1].Origin; origin != "github.com/layer5io/meshkit/errors.errTestSecond" {
+		t.Errorf("unexpected origin %s", origin)
+	}
+	if len(w.warnings) != 1 || GetCode(w.warnings[0]) != ErrCodeConflictCode {
+		t.Errorf("expected a warning, got %v", w.warnings)
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	if name := func() string { return func() string { return origin(1) }() }(); name != "github.com/layer5io/meshkit/errors.TestOrigin" {
+		t.Errorf("unexpected origin %s", name)
+	}
+}
@@ -8,6 +8,8 @@ type (
 		LongDescription      []string
 		ProbableCause        []string
 		SuggestedRemediation []string
+		// Parameters holds the values the description templates were rendered with, see WithParameters.
+		Parameters Parameters
 	}
 	// Limitations of Error struct defined above:
 	// There are different types of Errors. Each type of error contains different information.
@@ -0,0 +1,25 @@
+package files
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrReadContentCode         = "meshkit-11272"
+	ErrUnidentifiedContentCode = "meshkit-11273"
+	ErrParseContentCode        = "meshkit-11274"
+)
+
+func ErrReadContent(err error, name string) error {
+	return errors.New(ErrReadContentCode, errors.Alert, []string{fmt.Sprintf("Could not read %s", name)}, []string{err.Error()}, []string{"The file does not exist or is not readable", "The archive is corrupted"}, []string{"Make sure the file exists and is a valid archive, if compressed"})
+}
+
+func ErrUnidentifiedContent(name string) error {
+	return errors.New(ErrUnidentifiedContentCode, errors.Alert, []string{fmt.Sprintf("Could not identify the content of %s", name)}, []string{"The content is not a Kubernetes manifest, Helm chart, kustomization, Meshery design, CRD bundle or model archive"}, []string{"The file has an unsupported format", "The file is not valid YAML or JSON"}, []string{"Make sure to import one of the supported formats"})
+}
+
+func ErrParseContent(err error, contentType ContentType, name string) error {
+	return errors.New(ErrParseContentCode, errors.Alert, []string{fmt.Sprintf("Could not parse %s as a %s", name, contentType)}, []string{err.Error()}, []string{fmt.Sprintf("The %s is invalid or incomplete", contentType)}, []string{fmt.Sprintf("Make sure the %s is valid", contentType)})
+}
@@ -0,0 +1,465 @@
+// Package files identifies the content imported into Meshery and parses it with the matching parser.
+package files
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/layer5io/meshkit/converter"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// ContentType is the kind of content identified.
+type ContentType string
+
+const (
+	KubernetesManifest ContentType = "Kubernetes Manifest"
+	HelmChart          ContentType = "Helm Chart"
+	Kustomization      ContentType = "Kustomization"
+	MesheryDesign      ContentType = "Meshery Design"
+	CRDBundle          ContentType = "CRD Bundle"
+	ModelArchive       ContentType = "Model Archive"
+)
+
+// IdentifiedFile is the content identified and parsed.
+// ParsedFile depends on Type:
+//   - MesheryDesign: v1beta1.Design, migrated to the latest schema version
+//   - HelmChart: *chart.Chart
+//   - Kustomization: []map[string]interface{}, the resources built by kustomize
+//   - KubernetesManifest and CRDBundle: []map[string]interface{}, the objects of the manifest
+//   - ModelArchive: ModelDefinitions
+type IdentifiedFile struct {
+	Name       string
+	Type       ContentType
+	ParsedFile interface{}
+}
+
+// ModelDefinitions are the definitions found in a model archive.
+type ModelDefinitions struct {
+	Models        []v1beta1.Model
+	Components    []v1beta1.ComponentDefinition
+	Relationships []v1alpha2.RelationshipDefinition
+}
+
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// IdentifyPath identifies the content of a file, archive or directory.
+func IdentifyPath(path string) (IdentifiedFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return IdentifiedFile{}, ErrReadContent(err, path)
+	}
+	if info.IsDir() {
+		return identifyDirectory(path, path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return IdentifiedFile{}, ErrReadContent(err, path)
+	}
+	defer f.Close()
+	return Identify(f, filepath.Base(path))
+}
+
+// Identify identifies the content of the reader by its structure, the name is only used in reports.
+// Archives, tar, gzipped tar or zip, are extracted and identified as directories.
+// The content, once decompressed or extracted, is limited to DefaultMaxImportSize.
+func Identify(r io.Reader, name string) (IdentifiedFile, error) {
+	data, err := readLimited(r, DefaultMaxImportSize)
+	if err != nil {
+		return IdentifiedFile{}, ErrReadContent(err, name)
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return IdentifiedFile{}, ErrReadContent(err, name)
+		}
+		if data, err = readLimited(gz, DefaultMaxImportSize); err != nil {
+			return IdentifiedFile{}, ErrReadContent(err, name)
+		}
+	}
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return identifyArchive(data, name, extractZip)
+	case isTar(data):
+		return identifyArchive(data, name, extractTar)
+	}
+	docs, err := decodeDocuments(data)
+	if err != nil {
+		return IdentifiedFile{}, ErrUnidentifiedContent(name)
+	}
+	if len(docs) == 1 && isKustomization(docs[0]) {
+		fSys := filesys.MakeFsInMemory()
+		if err := fSys.WriteFile(filepath.Join(kustomizationRoot, kustomizationFiles[0]), data); err != nil {
+			return IdentifiedFile{}, ErrReadContent(err, name)
+		}
+		return buildKustomization(fSys, name)
+	}
+	return identifyDocuments(docs, name)
+}
+
+func identifyArchive(data []byte, name string, extract func([]byte, string, int64) error) (IdentifiedFile, error) {
+	dir, err := os.MkdirTemp("", "identify")
+	if err != nil {
+		return IdentifiedFile{}, ErrReadContent(err, name)
+	}
+	defer os.RemoveAll(dir)
+	if err := extract(data, dir, DefaultMaxImportSize); err != nil {
+		return IdentifiedFile{}, ErrReadContent(err, name)
+	}
+	return identifyDirectory(dir, name)
+}
+
+func identifyDirectory(dir, name string) (IdentifiedFile, error) {
+	// archives usually wrap their content in a single directory
+	for {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return IdentifiedFile{}, ErrReadContent(err, name)
+		}
+		if len(entries) != 1 || !entries[0].IsDir() {
+			break
+		}
+		dir = filepath.Join(dir, entries[0].Name())
+	}
+
+	if fileExists(filepath.Join(dir, "Chart.yaml")) {
+		ch, err := loader.Load(dir)
+		if err != nil {
+			return IdentifiedFile{}, ErrParseContent(err, HelmChart, name)
+		}
+		return IdentifiedFile{Name: name, Type: HelmChart, ParsedFile: ch}, nil
+	}
+	for _, file := range kustomizationFiles {
+		if fileExists(filepath.Join(dir, file)) {
+			fSys, err := copyToMemory(dir, DefaultMaxImportSize)
+			if err != nil {
+				return IdentifiedFile{}, ErrReadContent(err, name)
+			}
+			return buildKustomization(fSys, name)
+		}
+	}
+
+	var docs []map[string]interface{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fileDocs, err := decodeDocuments(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.TrimPrefix(path, dir), err)
+		}
+		docs = append(docs, fileDocs...)
+		return nil
+	})
+	if err != nil {
+		return IdentifiedFile{}, ErrReadContent(err, name)
+	}
+	return identifyDocuments(docs, name)
+}
+
+func identifyDocuments(docs []map[string]interface{}, name string) (IdentifiedFile, error) {
+	if len(docs) == 0 {
+		return IdentifiedFile{}, ErrUnidentifiedContent(name)
+	}
+	if len(docs) == 1 && converter.DesignSchemaVersionOf(docs[0]) != "" {
+		data, err := json.Marshal(docs[0])
+		if err != nil {
+			return IdentifiedFile{}, ErrParseContent(err, MesheryDesign, name)
+		}
+		design, _, err := converter.NewMigrationRegistry().MigrateToLatest(data)
+		if err != nil {
+			return IdentifiedFile{}, ErrParseContent(err, MesheryDesign, name)
+		}
+		return IdentifiedFile{Name: name, Type: MesheryDesign, ParsedFile: design}, nil
+	}
+
+	var definitions ModelDefinitions
+	crds, objects := 0, 0
+	for _, doc := range docs {
+		var err error
+		switch {
+		case isModelDefinition(doc):
+			var model v1beta1.Model
+			err = convert(doc, &model)
+			definitions.Models = append(definitions.Models, model)
+		case isComponentDefinition(doc):
+			var comp v1beta1.ComponentDefinition
+			err = convert(doc, &comp)
+			definitions.Components = append(definitions.Components, comp)
+		case isRelationshipDefinition(doc):
+			var rel v1alpha2.RelationshipDefinition
+			err = convert(doc, &rel)
+			definitions.Relationships = append(definitions.Relationships, rel)
+		case isKubernetesObject(doc):
+			objects++
+			if doc["kind"] == "CustomResourceDefinition" {
+				crds++
+			}
+		}
+		if err != nil {
+			return IdentifiedFile{}, ErrParseContent(err, ModelArchive, name)
+		}
+	}
+
+	switch {
+	case len(definitions.Models) > 0 || len(definitions.Components) > 0:
+		return IdentifiedFile{Name: name, Type: ModelArchive, ParsedFile: definitions}, nil
+	case objects != len(docs):
+		return IdentifiedFile{}, ErrUnidentifiedContent(name)
+	case crds == objects:
+		return IdentifiedFile{Name: name, Type: CRDBundle, ParsedFile: docs}, nil
+	default:
+		return IdentifiedFile{Name: name, Type: KubernetesManifest, ParsedFile: docs}, nil
+	}
+}
+
+// kustomizationRoot is the directory of the in-memory file system the kustomizations are built from.
+const kustomizationRoot = "/"
+
+// buildKustomization builds the kustomization at the root of fSys. The default options restrict the loading of files
+// to the root and disable the plugins, and the file system only holds the imported files: a kustomization can neither
+// read the files of the host, nor execute anything.
+func buildKustomization(fSys filesys.FileSystem, name string) (IdentifiedFile, error) {
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, kustomizationRoot)
+	if err != nil {
+		return IdentifiedFile{}, ErrParseContent(err, Kustomization, name)
+	}
+	manifest, err := resources.AsYaml()
+	if err != nil {
+		return IdentifiedFile{}, ErrParseContent(err, Kustomization, name)
+	}
+	docs, err := decodeDocuments(manifest)
+	if err != nil {
+		return IdentifiedFile{}, ErrParseContent(err, Kustomization, name)
+	}
+	return IdentifiedFile{Name: name, Type: Kustomization, ParsedFile: docs}, nil
+}
+
+// copyToMemory copies the regular files under dir to the root of an in-memory file system, failing once more than limit bytes are copied.
+func copyToMemory(dir string, limit int64) (filesys.FileSystem, error) {
+	fSys := filesys.MakeFsInMemory()
+	left := limit
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		data, err := readLimited(f, left)
+		if err != nil {
+			return err
+		}
+		left -= int64(len(data))
+		return fSys.WriteFile(filepath.Join(kustomizationRoot, rel), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fSys, nil
+}
+
+// decodeDocuments decodes the YAML or JSON documents of data, skipping the empty ones.
+// Lists of Kubernetes objects are flattened.
+func decodeDocuments(data []byte) ([]map[string]interface{}, error) {
+	var docs []map[string]interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		if items, ok := doc["items"].([]interface{}); ok && strings.HasSuffix(fmt.Sprint(doc["kind"]), "List") {
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					docs = append(docs, obj)
+				}
+			}
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func isKubernetesObject(doc map[string]interface{}) bool {
+	apiVersion, _ := doc["apiVersion"].(string)
+	kind, _ := doc["kind"].(string)
+	return apiVersion != "" && kind != ""
+}
+
+func isKustomization(doc map[string]interface{}) bool {
+	apiVersion, _ := doc["apiVersion"].(string)
+	return doc["kind"] == "Kustomization" && strings.HasPrefix(apiVersion, "kustomize.config.k8s.io/")
+}
+
+func isModelDefinition(doc map[string]interface{}) bool {
+	_, hasCategory := doc["category"]
+	_, hasRegistrant := doc["registrant"]
+	name, _ := doc["name"].(string)
+	return name != "" && (hasCategory || hasRegistrant) && !isKubernetesObject(doc)
+}
+
+func isComponentDefinition(doc map[string]interface{}) bool {
+	comp, ok := doc["component"].(map[string]interface{})
+	_, hasModel := doc["model"].(map[string]interface{})
+	return ok && hasModel && comp["kind"] != nil
+}
+
+func isRelationshipDefinition(doc map[string]interface{}) bool {
+	_, hasSelectors := doc["selectors"]
+	_, hasSubType := doc["subType"]
+	_, hasModel := doc["model"].(map[string]interface{})
+	return hasSelectors && hasSubType && hasModel
+}
+
+// convert decodes the generic document into out through JSON, the encoding of the definitions
+func convert(doc map[string]interface{}, out interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func isTar(data []byte) bool {
+	return len(data) > 262 && string(data[257:262]) == "ustar"
+}
+
+// readLimited reads r, failing if it is larger than limit bytes.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("the content is larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// safeJoin joins the entry name of an archive to dir, refusing the names escaping dir
+func safeJoin(dir, name string) (string, error) {
+	path := filepath.Join(dir, name)
+	if path != dir && !strings.HasPrefix(path, dir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("the archive entry %s escapes the destination directory", name)
+	}
+	return path, nil
+}
+
+// extractTar extracts the tar archive into dir, failing once more than limit bytes are extracted.
+func extractTar(data []byte, dir string, limit int64) error {
+	left := limit
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path, err := safeJoin(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg:
+			err = writeFile(path, tr, &left)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip extracts the zip archive into dir, failing once more than limit bytes are extracted.
+func extractZip(data []byte, dir string, limit int64) error {
+	left := limit
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, file := range zr.File {
+		path, err := safeJoin(dir, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(path, rc, &left)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes the content of r to path, failing if it is larger than the bytes left, which are decreased by the bytes written.
+func writeFile(path string, r io.Reader, left *int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(f, io.LimitReader(r, *left+1))
+	*left -= n
+	if err == nil && *left < 0 {
+		err = fmt.Errorf("the content is larger than the size limit once extracted")
+	}
+	return err
+}
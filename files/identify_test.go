@@ -0,0 +1,191 @@
+package files
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const testDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`
+
+const testCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		expected ContentType
+	}{
+		{"manifest.txt", []byte(testDeployment + "---\n" + testCRD), KubernetesManifest},
+		{"crds.yaml", []byte(testCRD + "---\n" + strings.Replace(testCRD, "widgets", "gadgets", 1)), CRDBundle},
+		{"design.json", []byte(`{"name": "app", "schemaVersion": "designs.meshery.io/v1beta1", "version": "0.0.1", "components": []}`), MesheryDesign},
+		{"legacy.yml", []byte("name: legacy\nservices:\n  web:\n    type: Deployment\n    apiVersion: apps/v1\n"), MesheryDesign},
+		{"chart.tgz", tarGz(t, map[string]string{
+			"web/Chart.yaml":            "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+			"web/templates/deploy.yaml": testDeployment,
+		}), HelmChart},
+		{"model.tar.gz", tarGz(t, map[string]string{
+			"widgets/model.json":             `{"name": "widgets", "category": {"name": "App Definition"}, "model": {"version": "v1.0.0"}}`,
+			"widgets/components/Widget.json": `{"displayName": "Widget", "model": {"name": "widgets"}, "component": {"kind": "Widget", "version": "example.com/v1"}}`,
+		}), ModelArchive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identified, err := Identify(bytes.NewReader(tt.content), tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identified.Type != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, identified.Type)
+			}
+			switch parsed := identified.ParsedFile.(type) {
+			case v1beta1.Design:
+				if parsed.SchemaVersion != v1beta1.DesignSchemaVersion {
+					t.Fatalf("design not migrated: %+v", parsed)
+				}
+			case *chart.Chart:
+				if parsed.Metadata.Name != "web" {
+					t.Fatalf("unexpected chart %+v", parsed.Metadata)
+				}
+			case ModelDefinitions:
+				if len(parsed.Models) != 1 || len(parsed.Components) != 1 || parsed.Components[0].Component.Kind != "Widget" {
+					t.Fatalf("unexpected definitions %+v", parsed)
+				}
+			}
+		})
+	}
+}
+
+func TestIdentifyPath(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nnamespace: prod\nresources:\n- deploy.yaml\n",
+		"deploy.yaml":        testDeployment,
+	})
+	identified, err := IdentifyPath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, _ := identified.ParsedFile.([]map[string]interface{})
+	if identified.Type != Kustomization || len(objects) != 1 {
+		t.Fatalf("unexpected identification %+v", identified)
+	}
+	if metadata, _ := objects[0]["metadata"].(map[string]interface{}); metadata["namespace"] != "prod" {
+		t.Fatalf("kustomization not built: %+v", objects[0])
+	}
+
+	if _, err := Identify(strings.NewReader("just some text"), "notes.txt"); err == nil {
+		t.Fatal("expected plain text to be unidentified")
+	}
+}
+
+func TestIdentifyKustomizationConfined(t *testing.T) {
+	// a file of the host, outside of the imported content
+	outside := writeFiles(t, map[string]string{"deploy.yaml": testDeployment})
+	kustomization := "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n- " + filepath.Join(outside, "deploy.yaml") + "\n"
+
+	if _, err := Identify(strings.NewReader(kustomization), "kustomization.yaml"); err == nil {
+		t.Fatal("expected the kustomization not to read the files of the host")
+	}
+	dir := writeFiles(t, map[string]string{"kustomization.yaml": kustomization})
+	if _, err := IdentifyPath(dir); err == nil {
+		t.Fatal("expected the kustomization not to read the files of the host")
+	}
+}
+
+func TestExtractLimit(t *testing.T) {
+	files := map[string]string{"a.yaml": strings.Repeat("a", 600), "b.yaml": strings.Repeat("b", 600)}
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, extract := range map[string]func([]byte, string, int64) error{"tar": extractTar, "zip": extractZip} {
+		data := tarBuf.Bytes()
+		if name == "zip" {
+			data = zipBuf.Bytes()
+		}
+		// each entry is below the limit, but not the total
+		if err := extract(data, t.TempDir(), 1000); err == nil {
+			t.Errorf("%s: expected the extraction to be limited", name)
+		}
+		if err := extract(data, t.TempDir(), 1200); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
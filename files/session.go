@@ -0,0 +1,398 @@
+package files
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// valuesFile matches the names of the values files of charts, e.g. values.yaml or values-prod.yaml
+var valuesFile = regexp.MustCompile(`^(.*[-_.])?values([-_.].*)?\.ya?ml$`)
+
+// Import is a content consolidated from the files of an ImportSession.
+type Import struct {
+	IdentifiedFile
+	// Files are the paths of the files of the session making the content
+	Files []string
+	// ValuesFiles are the values files applied to the values of a chart, in the order they were applied
+	ValuesFiles []string
+	// DependsOn are the names of the imports defining the custom resources of the content, e.g. CRD bundles
+	DependsOn []string
+}
+
+// FileDiagnostic reports how a file of an ImportSession was imported.
+type FileDiagnostic struct {
+	Path string
+	// Import is the name of the import the file is part of, empty if it could not be imported
+	Import string
+	Type   ContentType
+	Err    error
+}
+
+// ImportResult is the consolidated result of an ImportSession.
+type ImportResult struct {
+	Imports []Import
+	// Diagnostics are sorted by path, there is one for each file of the session
+	Diagnostics []FileDiagnostic
+}
+
+// Errors returns the errors of the files which could not be imported.
+func (r ImportResult) Errors() []error {
+	var errs []error
+	for _, d := range r.Diagnostics {
+		if d.Err != nil {
+			errs = append(errs, d.Err)
+		}
+	}
+	return errs
+}
+
+// ImportSession accepts the files and archives of an import one by one, e.g. the files of a folder dropped by the user,
+// and identifies them together once they are all added: the directories holding a Chart.yaml or a kustomization are
+// imported as a whole, the values files next to a chart are applied to it, and the CRDs are linked to the content
+// using their custom resources. The files are staged in a temporary directory, Close removes it.
+type ImportSession struct {
+	// MaxSize is the maximum size of a file added, archives being limited once decompressed, DefaultMaxImportSize if 0
+	MaxSize int64
+
+	mu    sync.Mutex
+	dir   string
+	paths map[string]struct{}
+}
+
+// DefaultMaxImportSize is the default maximum size of the files added to an ImportSession.
+const DefaultMaxImportSize = 256 << 20
+
+// NewImportSession returns an empty session.
+func NewImportSession() (*ImportSession, error) {
+	dir, err := os.MkdirTemp("", "import-session")
+	if err != nil {
+		return nil, ErrReadContent(err, "the import session")
+	}
+	return &ImportSession{dir: dir, paths: make(map[string]struct{})}, nil
+}
+
+// Add stages the file at its path relative to the root of the import, e.g. charts/web/Chart.yaml.
+// Archives are extracted in a directory named after them, without their extension. Adding a file or an archive
+// again replaces the one added before.
+func (s *ImportSession) Add(name string, r io.Reader) error {
+	maxSize := s.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxImportSize
+	}
+	data, err := readLimited(r, maxSize)
+	if err != nil {
+		return ErrReadContent(err, name)
+	}
+	name = path.Clean("/" + filepath.ToSlash(name))[1:]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	extracted := data
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return ErrReadContent(err, name)
+		}
+		if extracted, err = readLimited(gz, maxSize); err != nil {
+			return ErrReadContent(err, name)
+		}
+	}
+	var extract func([]byte, string, int64) error
+	switch {
+	case bytes.HasPrefix(extracted, []byte("PK\x03\x04")):
+		extract = extractZip
+	case isTar(extracted):
+		extract = extractTar
+	}
+	if extract == nil {
+		dst, err := safeJoin(s.dir, name)
+		if err == nil {
+			err = writeFile(dst, bytes.NewReader(data), &maxSize)
+		}
+		if err != nil {
+			return ErrReadContent(err, name)
+		}
+		s.paths[name] = struct{}{}
+		return nil
+	}
+
+	base := name
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		base = strings.TrimSuffix(base, ext)
+	}
+	dst, err := safeJoin(s.dir, base)
+	if err == nil {
+		err = s.remove(base, dst)
+	}
+	if err == nil {
+		err = s.extractArchive(extracted, dst, extract, maxSize)
+	}
+	if err != nil {
+		return ErrReadContent(err, name)
+	}
+	return filepath.Walk(dst, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(s.dir, p)
+		s.paths[filepath.ToSlash(rel)] = struct{}{}
+		return nil
+	})
+}
+
+// remove removes the files staged at the path, e.g. the files extracted from an archive added before.
+func (s *ImportSession) remove(name, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	for p := range s.paths {
+		if p == name || strings.HasPrefix(p, name+"/") {
+			delete(s.paths, p)
+		}
+	}
+	return nil
+}
+
+// extractArchive extracts the archive to dst, without the directory archives usually wrap their content in.
+// The extraction fails once more than limit bytes are extracted.
+func (s *ImportSession) extractArchive(data []byte, dst string, extract func([]byte, string, int64) error, limit int64) error {
+	tmp, err := os.MkdirTemp(s.dir, ".archive")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := extract(data, tmp, limit); err != nil {
+		return err
+	}
+	content := tmp
+	for {
+		entries, err := os.ReadDir(content)
+		if err != nil {
+			return err
+		}
+		if len(entries) != 1 || !entries[0].IsDir() {
+			break
+		}
+		content = filepath.Join(content, entries[0].Name())
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.Rename(content, dst)
+}
+
+// Close removes the staged files.
+func (s *ImportSession) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// Result identifies the files added so far. The files which cannot be identified are reported in the diagnostics,
+// and do not fail the others.
+func (s *ImportSession) Result() ImportResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := sortedPaths(s.paths)
+	// the directories of charts and kustomizations are imported as a whole, the outermost winning
+	var dirs []string
+	for _, p := range paths {
+		if file := path.Base(p); file == "Chart.yaml" || isKustomizationFile(file) {
+			dirs = append(dirs, path.Dir(p))
+		}
+	}
+	depth := func(dir string) int {
+		if dir == "." {
+			return -1
+		}
+		return strings.Count(dir, "/")
+	}
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return depth(dirs[i]) < depth(dirs[j])
+	})
+	var roots []string
+	for _, dir := range dirs {
+		if rootOf(roots, dir) == "" {
+			roots = append(roots, dir)
+		}
+	}
+	units := make(map[string][]string)
+	var values []string
+	for _, p := range paths {
+		if root := rootOf(roots, p); root != "" {
+			units[root] = append(units[root], p)
+			continue
+		}
+		if valuesFile.MatchString(path.Base(p)) && !s.isKubernetesManifest(p) {
+			values = append(values, p)
+			continue
+		}
+		units[p] = append(units[p], p)
+	}
+
+	var result ImportResult
+	diagnostics := make(map[string]FileDiagnostic)
+	charts := make(map[string]*Import)
+	for _, name := range sortedPaths(units) {
+		identified, err := IdentifyPath(filepath.Join(s.dir, filepath.FromSlash(name)))
+		if err != nil {
+			for _, p := range units[name] {
+				diagnostics[p] = FileDiagnostic{Path: p, Err: err}
+			}
+			continue
+		}
+		identified.Name = name
+		result.Imports = append(result.Imports, Import{IdentifiedFile: identified, Files: units[name]})
+		for _, p := range units[name] {
+			diagnostics[p] = FileDiagnostic{Path: p, Import: name, Type: identified.Type}
+		}
+	}
+	for i := range result.Imports {
+		if result.Imports[i].Type == HelmChart {
+			charts[result.Imports[i].Name] = &result.Imports[i]
+		}
+	}
+
+	for _, p := range values {
+		diagnostics[p] = s.applyValues(p, charts)
+	}
+	linkCustomResourceDefinitions(result.Imports)
+
+	for _, p := range paths {
+		result.Diagnostics = append(result.Diagnostics, diagnostics[p])
+	}
+	return result
+}
+
+// applyValues applies the values file to the chart in its directory, or in a sub directory if there is a single one.
+func (s *ImportSession) applyValues(p string, charts map[string]*Import) FileDiagnostic {
+	dir := path.Dir(p)
+	var candidates []*Import
+	for _, name := range sortedPaths(charts) {
+		if name == dir || path.Dir(name) == dir {
+			candidates = append(candidates, charts[name])
+		}
+	}
+	if len(candidates) != 1 {
+		err := fmt.Errorf("found %d charts for the values file, expected one in its directory", len(candidates))
+		return FileDiagnostic{Path: p, Err: ErrParseContent(err, HelmChart, p)}
+	}
+	imp := candidates[0]
+	values, err := chartutil.ReadValuesFile(filepath.Join(s.dir, filepath.FromSlash(p)))
+	if err != nil {
+		return FileDiagnostic{Path: p, Err: ErrParseContent(err, HelmChart, p)}
+	}
+	ch := imp.ParsedFile.(*chart.Chart)
+	ch.Values = chartutil.CoalesceTables(values.AsMap(), ch.Values)
+	imp.ValuesFiles = append(imp.ValuesFiles, p)
+	imp.Files = append(imp.Files, p)
+	return FileDiagnostic{Path: p, Import: imp.Name, Type: HelmChart}
+}
+
+func (s *ImportSession) isKubernetesManifest(p string) bool {
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(p)))
+	if err != nil {
+		return false
+	}
+	docs, err := decodeDocuments(data)
+	if err != nil || len(docs) == 0 {
+		return false
+	}
+	for _, doc := range docs {
+		if !isKubernetesObject(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+// linkCustomResourceDefinitions sets the imports defining the custom resources of the manifests and kustomizations.
+func linkCustomResourceDefinitions(imports []Import) {
+	definedBy := make(map[string]string)
+	for _, imp := range imports {
+		for _, doc := range objectsOf(imp) {
+			if doc["kind"] == "CustomResourceDefinition" {
+				definedBy[crdGroupKind(doc)] = imp.Name
+			}
+		}
+		if ch, ok := imp.ParsedFile.(*chart.Chart); ok {
+			for _, file := range ch.CRDObjects() {
+				docs, _ := decodeDocuments(file.File.Data)
+				for _, doc := range docs {
+					definedBy[crdGroupKind(doc)] = imp.Name
+				}
+			}
+		}
+	}
+	for i := range imports {
+		seen := make(map[string]bool)
+		for _, doc := range objectsOf(imports[i]) {
+			apiVersion, _ := doc["apiVersion"].(string)
+			group := ""
+			if j := strings.LastIndex(apiVersion, "/"); j >= 0 {
+				group = apiVersion[:j]
+			}
+			name, ok := definedBy[fmt.Sprintf("%s/%s", group, doc["kind"])]
+			if ok && name != imports[i].Name && !seen[name] {
+				seen[name] = true
+				imports[i].DependsOn = append(imports[i].DependsOn, name)
+			}
+		}
+		sort.Strings(imports[i].DependsOn)
+	}
+}
+
+func objectsOf(imp Import) []map[string]interface{} {
+	switch imp.Type {
+	case KubernetesManifest, CRDBundle, Kustomization:
+		docs, _ := imp.ParsedFile.([]map[string]interface{})
+		return docs
+	}
+	return nil
+}
+
+// crdGroupKind returns the group and kind of the resources defined by the CRD, e.g. example.com/Widget
+func crdGroupKind(crd map[string]interface{}) string {
+	spec, _ := crd["spec"].(map[string]interface{})
+	names, _ := spec["names"].(map[string]interface{})
+	return fmt.Sprintf("%v/%v", spec["group"], names["kind"])
+}
+
+func isKustomizationFile(name string) bool {
+	for _, file := range kustomizationFiles {
+		if name == file {
+			return true
+		}
+	}
+	return false
+}
+
+// rootOf returns the root the path is in, empty if it is in none.
+func rootOf(roots []string, p string) string {
+	for _, root := range roots {
+		if root == "." || p == root || strings.HasPrefix(p, root+"/") {
+			return root
+		}
+	}
+	return ""
+}
+
+func sortedPaths[T any](m map[string]T) []string {
+	paths := make([]string, 0, len(m))
+	for p := range m {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,126 @@
+package files
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const testWidget = `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+`
+
+func TestImportSession(t *testing.T) {
+	s, err := NewImportSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	crd := testCRD + "spec:\n  group: example.com\n  names:\n    kind: Widget\n"
+	for name, content := range map[string]string{
+		"crds/widgets.yaml":  crd,
+		"app/widget.yaml":    testWidget,
+		"app/deploy.yaml":    testDeployment,
+		"values-prod.yaml":   "replicas: 3\n",
+		"notes/readme.yaml":  "title: not an object\n",
+		"app/web/values.yml": "image: web\n",
+	} {
+		if err := s.Add(name, strings.NewReader(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive := tarGz(t, map[string]string{
+		"web/Chart.yaml":  "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+		"web/values.yaml": "replicas: 1\nimage: nginx\n",
+	})
+	if err := s.Add("web.tgz", bytes.NewReader(archive)); err != nil {
+		t.Fatal(err)
+	}
+
+	result := s.Result()
+	imports := make(map[string]Import)
+	for _, imp := range result.Imports {
+		imports[imp.Name] = imp
+	}
+	web, ok := imports["web"]
+	if !ok || web.Type != HelmChart {
+		t.Fatalf("expected the chart archive to be imported, got %+v", result.Imports)
+	}
+	if values := web.ParsedFile.(*chart.Chart).Values; values["replicas"] != float64(3) || values["image"] != "nginx" {
+		t.Errorf("expected the values file to be applied to the chart, got %v", values)
+	}
+	if len(web.ValuesFiles) != 1 || web.ValuesFiles[0] != "values-prod.yaml" {
+		t.Errorf("values files = %v", web.ValuesFiles)
+	}
+	if widget := imports["app/widget.yaml"]; len(widget.DependsOn) != 1 || widget.DependsOn[0] != "crds/widgets.yaml" {
+		t.Errorf("expected the widget to depend on its CRD, got %+v", widget)
+	}
+	if imports["crds/widgets.yaml"].Type != CRDBundle || imports["app/deploy.yaml"].Type != KubernetesManifest {
+		t.Errorf("imports = %+v", result.Imports)
+	}
+
+	if len(result.Diagnostics) != 8 {
+		t.Fatalf("expected a diagnostic per file, got %+v", result.Diagnostics)
+	}
+	failed := map[string]bool{}
+	for _, d := range result.Diagnostics {
+		if d.Err != nil {
+			failed[d.Path] = true
+		}
+	}
+	// the values file without chart and the unknown document do not fail the others
+	if len(result.Errors()) != 2 || !failed["notes/readme.yaml"] || !failed["app/web/values.yml"] {
+		t.Errorf("diagnostics = %+v", result.Diagnostics)
+	}
+}
+
+func TestImportSessionReplacesArchives(t *testing.T) {
+	s, err := NewImportSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, files := range []map[string]string{
+		{"web/Chart.yaml": "apiVersion: v2\nname: web\nversion: 0.1.0\n", "web/values.yaml": "replicas: 1\n"},
+		{"web/Chart.yaml": "apiVersion: v2\nname: web\nversion: 0.2.0\n"},
+	} {
+		if err := s.Add("web.tgz", bytes.NewReader(tarGz(t, files))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := s.Result()
+	if len(result.Imports) != 1 || result.Imports[0].ParsedFile.(*chart.Chart).Metadata.Version != "0.2.0" {
+		t.Fatalf("expected the archive added last to replace the first, got %+v", result.Imports)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Path != "web/Chart.yaml" {
+		t.Errorf("expected the files of the first archive to be removed, got %+v", result.Diagnostics)
+	}
+}
+
+func TestImportSessionMaxSize(t *testing.T) {
+	s, err := NewImportSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.MaxSize = 1024
+
+	if err := s.Add("large.yaml", strings.NewReader(strings.Repeat("#", 2048))); err == nil {
+		t.Error("expected an error adding a file larger than the maximum size")
+	}
+	// compressed, the archive is smaller than the maximum size, but not once decompressed
+	archive := tarGz(t, map[string]string{"large/values.yaml": strings.Repeat("#", 4096)})
+	if err := s.Add("large.tgz", bytes.NewReader(archive)); err == nil {
+		t.Error("expected an error adding an archive larger than the maximum size once decompressed")
+	}
+	if err := s.Add("deploy.yaml", strings.NewReader(testDeployment)); err != nil {
+		t.Error(err)
+	}
+}
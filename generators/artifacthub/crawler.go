@@ -0,0 +1,318 @@
+package artifacthub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/pool"
+)
+
+// CrawlerOptions configures the ArtifactHub batch crawler.
+// Zero values are replaced with the values from DefaultCrawlerOptions.
+type CrawlerOptions struct {
+	// ArtifactHub API endpoint
+	Endpoint string
+	// Number of packages fetched in parallel
+	Concurrency int
+	// Minimum time between two requests of a worker
+	RequestInterval time.Duration
+	// Maximum number of retries for a request failing with 429 or 5xx status codes
+	MaxRetries int
+	// Backoff before the first retry, doubled with every subsequent retry unless the response carries a Retry-After header
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Directory in which the package metadata returned by ArtifactHub is cached, caching is disabled when empty
+	CacheDir string
+	// Cached metadata older than CacheTTL is fetched again, zero means the cache never expires
+	CacheTTL time.Duration
+	// File in which the crawled packages are persisted, so that an interrupted crawl resumes from where it stopped.
+	// Progress is not persisted when empty.
+	ProgressFile string
+	HTTPClient   *http.Client
+}
+
+var DefaultCrawlerOptions = CrawlerOptions{
+	Endpoint:        ArtifactHubAPIEndpint,
+	Concurrency:     4,
+	RequestInterval: 500 * time.Millisecond,
+	MaxRetries:      5,
+	InitialBackoff:  2 * time.Second,
+	MaxBackoff:      time.Minute,
+	HTTPClient:      http.DefaultClient,
+}
+
+// progress is persisted after these many packages are crawled, and once the crawl finishes
+const progressFlushInterval = 25
+
+type crawlProgress struct {
+	Packages map[string]AhPackage `json:"packages"`
+}
+
+// Crawler fetches the metadata of all the helm packages published on ArtifactHub.
+type Crawler struct {
+	opts CrawlerOptions
+
+	mu       sync.Mutex
+	progress crawlProgress
+	pending  int
+}
+
+func NewCrawler(opts CrawlerOptions) *Crawler {
+	if opts.Endpoint == "" {
+		opts.Endpoint = DefaultCrawlerOptions.Endpoint
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultCrawlerOptions.Concurrency
+	}
+	if opts.RequestInterval <= 0 {
+		opts.RequestInterval = DefaultCrawlerOptions.RequestInterval
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = DefaultCrawlerOptions.MaxRetries
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = DefaultCrawlerOptions.InitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultCrawlerOptions.MaxBackoff
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = DefaultCrawlerOptions.HTTPClient
+	}
+	return &Crawler{
+		opts: opts,
+		progress: crawlProgress{
+			Packages: make(map[string]AhPackage),
+		},
+	}
+}
+
+type helmExporterEntry struct {
+	Name       string `json:"name"`
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"`
+}
+
+// Crawl returns all the helm packages on ArtifactHub.
+// Packages present in the progress file are not fetched again. Packages which could not be fetched are skipped
+// and reported in the returned error, they are retried when the crawl is resumed.
+func (c *Crawler) Crawl(ctx context.Context) ([]AhPackage, error) {
+	if err := c.loadProgress(); err != nil {
+		return nil, err
+	}
+
+	var entries []helmExporterEntry
+	body, err := c.get(ctx, c.opts.Endpoint+"/helm-exporter")
+	if err != nil {
+		return nil, ErrGetAllHelmPackages(err)
+	}
+	if err = json.Unmarshal(body, &entries); err != nil {
+		return nil, ErrGetAllHelmPackages(err)
+	}
+
+	p := pool.New(ctx, pool.Options{Workers: c.opts.Concurrency})
+	for _, entry := range entries {
+		if c.isCrawled(packageKey(entry.Repository.Name, entry.Name)) {
+			continue
+		}
+		entry := entry
+		err := p.Go(func(ctx context.Context) error {
+			err := c.crawlPackage(ctx, entry)
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.opts.RequestInterval):
+			}
+			return err
+		})
+		if err != nil {
+			break
+		}
+	}
+	_ = p.Wait()
+	errs := p.Errors()
+
+	if err := c.saveProgress(); err != nil {
+		errs = append(errs, err)
+	}
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+
+	c.mu.Lock()
+	pkgs := make([]AhPackage, 0, len(c.progress.Packages))
+	for _, entry := range entries {
+		if pkg, ok := c.progress.Packages[packageKey(entry.Repository.Name, entry.Name)]; ok {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(errs) != 0 {
+		return pkgs, ErrCrawlPackages(utils.CombineErrors(errs, "\n"), len(errs))
+	}
+	return pkgs, nil
+}
+
+func (c *Crawler) crawlPackage(ctx context.Context, entry helmExporterEntry) error {
+	key := packageKey(entry.Repository.Name, entry.Name)
+	body, err := c.readCache(key)
+	if err != nil {
+		body, err = c.get(ctx, fmt.Sprintf("%s/packages/helm/%s/%s", c.opts.Endpoint, entry.Repository.Name, entry.Name))
+		if err != nil {
+			return err
+		}
+		c.writeCache(key, body)
+	}
+	var res map[string]interface{}
+	if err = json.Unmarshal(body, &res); err != nil {
+		return utils.ErrUnmarshal(err)
+	}
+
+	c.mu.Lock()
+	c.progress.Packages[key] = *parseArtifacthubResponse(res)
+	c.pending++
+	flush := c.pending >= progressFlushInterval
+	c.mu.Unlock()
+	if flush {
+		return c.saveProgress()
+	}
+	return nil
+}
+
+// get performs a GET request, retrying with backoff when rate limited or when the server errors out.
+func (c *Crawler) get(ctx context.Context, url string) ([]byte, error) {
+	backoff := c.opts.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.opts.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return body, nil
+		}
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= c.opts.MaxRetries {
+			return nil, fmt.Errorf("status code %d for %s", resp.StatusCode, url)
+		}
+
+		wait := backoff
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		if wait > c.opts.MaxBackoff {
+			wait = c.opts.MaxBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Crawler) isCrawled(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.progress.Packages[key]
+	return ok
+}
+
+func (c *Crawler) cachePath(key string) string {
+	return filepath.Join(c.opts.CacheDir, filepath.FromSlash(key)+".json")
+}
+
+func (c *Crawler) readCache(key string) ([]byte, error) {
+	if c.opts.CacheDir == "" {
+		return nil, os.ErrNotExist
+	}
+	path := c.cachePath(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.opts.CacheTTL > 0 && time.Since(info.ModTime()) > c.opts.CacheTTL {
+		return nil, os.ErrNotExist
+	}
+	return os.ReadFile(path)
+}
+
+// writeCache is best effort, failing to cache only means that the package is fetched again on the next crawl.
+func (c *Crawler) writeCache(key string, body []byte) {
+	if c.opts.CacheDir == "" {
+		return
+	}
+	path := c.cachePath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, body, 0644)
+}
+
+func (c *Crawler) loadProgress() error {
+	if c.opts.ProgressFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.opts.ProgressFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return utils.ErrReadFile(err, c.opts.ProgressFile)
+	}
+	var progress crawlProgress
+	if err = json.Unmarshal(data, &progress); err != nil {
+		return utils.ErrUnmarshal(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, pkg := range progress.Packages {
+		c.progress.Packages[key] = pkg
+	}
+	return nil
+}
+
+// saveProgress writes to a temporary file first, so that an interruption while writing doesn't corrupt the progress.
+func (c *Crawler) saveProgress() error {
+	if c.opts.ProgressFile == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.Marshal(c.progress)
+	if err != nil {
+		return utils.ErrMarshal(err)
+	}
+	tmpFile := c.opts.ProgressFile + ".tmp"
+	if err = os.WriteFile(tmpFile, data, 0644); err != nil {
+		return utils.ErrWriteFile(err, tmpFile)
+	}
+	if err = os.Rename(tmpFile, c.opts.ProgressFile); err != nil {
+		return utils.ErrWriteFile(err, c.opts.ProgressFile)
+	}
+	c.pending = 0
+	return nil
+}
+
+func packageKey(repo, name string) string {
+	return repo + "/" + name
+}
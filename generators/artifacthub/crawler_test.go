@@ -0,0 +1,63 @@
+package artifacthub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawl(t *testing.T) {
+	var requests, rateLimited int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/helm-exporter", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"consul","repository":{"name":"bitnami"}},{"name":"istiod","repository":{"name":"istio"}}]`)
+	})
+	mux.HandleFunc("/packages/helm/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		// rate limit the first two package requests
+		if atomic.AddInt32(&rateLimited, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		name := filepath.Base(r.URL.Path)
+		fmt.Fprintf(w, `{"name":"%s","version":"1.0.0","repository":{"name":"%s","url":"https://example.com","official":true}}`, name, filepath.Base(filepath.Dir(r.URL.Path)))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	opts := CrawlerOptions{
+		Endpoint:        server.URL,
+		Concurrency:     2,
+		RequestInterval: time.Millisecond,
+		InitialBackoff:  time.Millisecond,
+		CacheDir:        filepath.Join(dir, "cache"),
+		ProgressFile:    filepath.Join(dir, "progress.json"),
+	}
+	pkgs, err := NewCrawler(opts).Crawl(context.Background())
+	if err != nil {
+		t.Fatalf("error while crawling: %v", err)
+	}
+	if len(pkgs) != 2 || pkgs[0].Name != "consul" || pkgs[1].Name != "istiod" {
+		t.Fatalf("got %v, want packages consul and istiod", pkgs)
+	}
+	if !pkgs[0].Official || pkgs[0].Version != "1.0.0" {
+		t.Errorf("package metadata not parsed: %v", pkgs[0])
+	}
+
+	// resuming a completed crawl should not fetch the packages again
+	before := atomic.LoadInt32(&requests)
+	pkgs, err = NewCrawler(opts).Crawl(context.Background())
+	if err != nil || len(pkgs) != 2 {
+		t.Fatalf("got %d packages, err %v on resume, want 2 packages", len(pkgs), err)
+	}
+	if after := atomic.LoadInt32(&requests); after != before {
+		t.Errorf("resumed crawl made %d package requests, want 0", after-before)
+	}
+}
@@ -1,6 +1,8 @@
 package artifacthub
 
 import (
+	"fmt"
+
 	"github.com/layer5io/meshkit/errors"
 )
 
@@ -9,6 +11,7 @@ var (
 	ErrGetAhPackageCode       = "meshkit-11135"
 	ErrComponentGenerateCode  = "meshkit-11136"
 	ErrGetAllHelmPackagesCode = "meshkit-11137"
+	ErrCrawlPackagesCode      = "meshkit-11258"
 )
 
 func ErrGetAllHelmPackages(err error) error {
@@ -26,3 +29,7 @@ func ErrGetAhPackage(err error) error {
 func ErrComponentGenerate(err error) error {
 	return errors.New(ErrComponentGenerateCode, errors.Alert, []string{"failed to generate components for the package"}, []string{err.Error()}, []string{}, []string{"Make sure that the package is compatible"})
 }
+
+func ErrCrawlPackages(err error, failed int) error {
+	return errors.New(ErrCrawlPackagesCode, errors.Alert, []string{fmt.Sprintf("Could not crawl %d ArtifactHub packages", failed)}, []string{err.Error()}, []string{"ArtifactHub rate limits were exhausted", "The package was removed from ArtifactHub"}, []string{"Resume the crawl later using the same progress file", "Reduce the crawler concurrency"})
+}
@@ -0,0 +1,86 @@
+package artifacthub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+// Categories maps the categories of the packages of ArtifactHub to the categories and sub categories of the models.
+var Categories = map[int][2]string{
+	1: {"Machine Learning", ""},
+	2: {"Database", ""},
+	3: {"App Definition and Development", "Continuous Integration & Delivery"},
+	4: {"Observability and Analysis", "Monitoring"},
+	5: {"Cloud Native Network", ""},
+	6: {"Security & Compliance", ""},
+	7: {"Cloud Native Storage", ""},
+	8: {"App Definition and Development", "Streaming & Messaging"},
+}
+
+// MetadataSource provides the description, category, home page and deprecation of the models from the helm
+// package of the same name on ArtifactHub, ranked as SortPackagesWithScore when several repositories publish it.
+type MetadataSource struct {
+	crawler *Crawler
+}
+
+// NewMetadataSource returns a MetadataSource sending its requests as a Crawler configured with the options.
+func NewMetadataSource(opts CrawlerOptions) *MetadataSource {
+	return &MetadataSource{crawler: NewCrawler(opts)}
+}
+
+func (s *MetadataSource) Name() string {
+	return "artifacthub"
+}
+
+func (s *MetadataSource) ModelMetadata(model v1beta1.Model) (models.ModelMetadata, error) {
+	ctx := context.Background()
+	query := url.Values{AhTextSearchQueryFieldName: {model.Name}}
+	for key, val := range AhApiSearchParams {
+		query.Set(key, val)
+	}
+	body, err := s.crawler.get(ctx, fmt.Sprintf("%s/packages/search?%s", s.crawler.opts.Endpoint, query.Encode()))
+	if err != nil {
+		return models.ModelMetadata{}, ErrGetAhPackage(err)
+	}
+	var res map[string][]map[string]interface{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return models.ModelMetadata{}, ErrGetAhPackage(err)
+	}
+	pkgs := make([]AhPackage, 0)
+	for _, p := range res["packages"] {
+		if pkg := parseArtifacthubResponse(p); pkg.Name == model.Name {
+			pkgs = append(pkgs, *pkg)
+		}
+	}
+	if len(pkgs) == 0 {
+		return models.ModelMetadata{}, nil
+	}
+	pkg := SortPackagesWithScore(pkgs)[0]
+
+	body, err = s.crawler.get(ctx, fmt.Sprintf("%s/packages/helm/%s/%s", s.crawler.opts.Endpoint, url.PathEscape(pkg.Repository), url.PathEscape(pkg.Name)))
+	if err != nil {
+		return models.ModelMetadata{}, ErrGetAhPackage(err)
+	}
+	var details struct {
+		Description string `json:"description"`
+		HomeURL     string `json:"home_url"`
+		Category    int    `json:"category"`
+		Deprecated  bool   `json:"deprecated"`
+	}
+	if err := json.Unmarshal(body, &details); err != nil {
+		return models.ModelMetadata{}, ErrGetAhPackage(err)
+	}
+	m := models.ModelMetadata{Description: details.Description, DocsURL: details.HomeURL}
+	if category, ok := Categories[details.Category]; ok {
+		m.Category, m.SubCategory = category[0], category[1]
+	}
+	if details.Deprecated {
+		m.Maturity = "deprecated"
+	}
+	return m, nil
+}
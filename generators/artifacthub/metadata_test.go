@@ -0,0 +1,37 @@
+package artifacthub
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestMetadataSource(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/packages/search", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"packages":[
+			{"name":"consul","repository":{"name":"community"}},
+			{"name":"consul","repository":{"name":"hashicorp","verified_publisher":true}},
+			{"name":"consul-esm","repository":{"name":"hashicorp","verified_publisher":true}}]}`)
+	})
+	mux.HandleFunc("/packages/helm/hashicorp/consul", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"consul","description":"Official HashiCorp Consul Chart","home_url":"https://www.consul.io","category":5}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	source := NewMetadataSource(CrawlerOptions{Endpoint: server.URL})
+	m, err := source.ModelMetadata(v1beta1.Model{Name: "consul"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Description != "Official HashiCorp Consul Chart" || m.DocsURL != "https://www.consul.io" || m.Category != "Cloud Native Network" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	if m, err = source.ModelMetadata(v1beta1.Model{Name: "vault"}); err != nil || m.Description != "" {
+		t.Errorf("expected no metadata for an unknown package, got %+v, %v", m, err)
+	}
+}
@@ -1,15 +1,16 @@
 package artifacthub
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
 	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/cas"
 	"github.com/layer5io/meshkit/utils/component"
+	k8s "github.com/layer5io/meshkit/utils/kubernetes"
 	"github.com/layer5io/meshkit/utils/manifests"
 	"gopkg.in/yaml.v2"
 )
@@ -30,6 +31,13 @@ type AhPackage struct {
 	VerifiedPublisher bool   `yaml:"verified_publisher"`
 	CNCF              bool   `yaml:"cncf"`
 	Version           string `yaml:"version"`
+	// MaxDependencyDepth is how many levels of the chart dependencies which are not vendored are downloaded to find CRDs,
+	// as k8s.HelmDependencyOptions.MaxDepth: 0 means that only the vendored subcharts are used and a negative value means no limit.
+	MaxDependencyDepth int `yaml:"-" json:"-"`
+	// HTTPClient fetches the index of the repository and the chart, e.g. from mirrors, http.DefaultClient if nil.
+	HTTPClient *http.Client `yaml:"-" json:"-"`
+	// Store, if set, caches the chart and its dependencies by content, see cas.Store.
+	Store *cas.Store `yaml:"-" json:"-"`
 }
 
 func (pkg AhPackage) GetVersion() string {
@@ -39,10 +47,11 @@ func (pkg AhPackage) GetVersion() string {
 func (pkg AhPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error) {
 	components := make([]v1beta1.ComponentDefinition, 0)
 	// TODO: Move this to the configuration
-	crds, err := manifests.GetCrdsFromHelm(pkg.ChartUrl)
+	crds, rendered, err := manifests.GetCrdsAndResourcesFromHelmWithDependencies(pkg.ChartUrl, pkg.dependencyOptions())
 	if err != nil {
 		return components, ErrComponentGenerate(err)
 	}
+	resources := component.DecodeResources(rendered)
 	for _, crd := range crds {
 		comp, err := component.Generate(crd)
 		if err != nil {
@@ -51,6 +60,7 @@ func (pkg AhPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error)
 		if comp.Metadata == nil {
 			comp.Metadata = make(map[string]interface{})
 		}
+		component.SetDefaultsAndPresets(&comp, resources)
 		if comp.Model.Metadata == nil {
 			comp.Model.Metadata = make(map[string]interface{})
 		}
@@ -66,6 +76,17 @@ func (pkg AhPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error)
 	return components, nil
 }
 
+func (pkg AhPackage) dependencyOptions() k8s.HelmDependencyOptions {
+	return k8s.HelmDependencyOptions{MaxDepth: pkg.MaxDependencyDepth, HTTPClient: pkg.HTTPClient, Store: pkg.Store}
+}
+
+func (pkg AhPackage) httpClient() *http.Client {
+	if pkg.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return pkg.HTTPClient
+}
+
 // function that will take the AhPackage as input and give the helm chart url for that package
 func (pkg *AhPackage) UpdatePackageData() error {
 	if pkg.ChartUrl != "" {
@@ -75,7 +96,7 @@ func (pkg *AhPackage) UpdatePackageData() error {
 	if strings.HasSuffix(pkg.RepoUrl, "/") {
 		urlSuffix = "index.yaml"
 	}
-	charts, err := utils.ReadRemoteFile(pkg.RepoUrl + urlSuffix)
+	charts, err := utils.ReadRemoteFileWithClient(pkg.httpClient(), pkg.RepoUrl+urlSuffix)
 	if err != nil {
 		return ErrGetChartUrl(err)
 	}
@@ -114,49 +135,11 @@ func (pkg *AhPackage) Validator() {
 
 }
 
-// GetAllAhHelmPackages returns a list of all AhPackages and is super slow to avoid rate limits.
+// GetAllAhHelmPackages returns a list of all AhPackages, it crawls ArtifactHub using the DefaultCrawlerOptions.
+// Packages which could not be fetched are skipped: the packages which were fetched are returned along with the error.
+// Use a Crawler directly to cache metadata or resume an interrupted crawl.
 func GetAllAhHelmPackages() ([]AhPackage, error) {
-	pkgs := make([]AhPackage, 0)
-	resp, err := http.Get(AhHelmExporterEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != 200 {
-		err = fmt.Errorf("status code %d for %s", resp.StatusCode, AhHelmExporterEndpoint)
-		return nil, ErrGetAllHelmPackages(err)
-	}
-	defer resp.Body.Close()
-	var res []map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&res)
-	if err != nil {
-		return nil, err
-	}
-	for _, p := range res {
-		name := p["name"].(string)
-		repo := p["repository"].(map[string]interface{})["name"].(string)
-		url := fmt.Sprintf("https://artifacthub.io/api/v1/packages/helm/%s/%s", repo, name)
-		resp, err := http.Get(url)
-		if err != nil {
-			fmt.Println(err)
-			continue
-		}
-		if resp.StatusCode != 200 {
-			err = fmt.Errorf("status code %d for %s", resp.StatusCode, url)
-			fmt.Println(err)
-			continue
-		}
-		defer resp.Body.Close()
-		var res map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&res)
-		if err != nil {
-			fmt.Println(err)
-			continue
-		}
-
-		pkgs = append(pkgs, *parseArtifacthubResponse(res))
-		time.Sleep(500 * time.Millisecond)
-	}
-	return pkgs, nil
+	return NewCrawler(DefaultCrawlerOptions).Crawl(context.Background())
 }
 
 func parseArtifacthubResponse(response map[string]interface{}) *AhPackage {
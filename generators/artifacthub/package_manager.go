@@ -2,23 +2,35 @@ package artifacthub
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils/cas"
 )
 
 type ArtifactHubPackageManager struct {
 	PackageName string
 	SourceURL   string
+	// HTTPClient fetches the packages, their indexes and charts, e.g. from mirrors, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// Store, if set, caches the charts and their dependencies by content, see cas.Store.
+	Store *cas.Store
 }
 
 func (ahpm ArtifactHubPackageManager) GetPackage() (models.Package, error) {
 	// get relevant packages
-	pkgs, err := GetAhPackagesWithName(ahpm.PackageName)
+	client := ahpm.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	pkgs, err := getAhPackagesWithName(client, ahpm.PackageName)
 	if err != nil {
 		return nil, err
 	}
 	// update package information
 	for i, ap := range pkgs {
+		ap.HTTPClient = ahpm.HTTPClient
+		ap.Store = ahpm.Store
 		_ = ap.UpdatePackageData()
 		pkgs[i] = ap
 	}
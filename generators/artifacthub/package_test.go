@@ -5,9 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 func TestGetChartUrl(t *testing.T) {
@@ -58,3 +64,63 @@ func TestGetChartUrl(t *testing.T) {
 		})
 	}
 }
+
+const testSubchartCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+`
+
+func TestGenerateComponentsVendoredSubchart(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "widgets", Version: "0.1.0", APIVersion: "v2"},
+		Files:    []*chart.File{{Name: "crds/widgets.yaml", Data: []byte(testSubchartCRD)}},
+	}
+	root := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "vendored-subchart-test", Version: "0.1.0", APIVersion: "v2",
+			Dependencies: []*chart.Dependency{{Name: "widgets", Version: "0.1.0", Repository: "https://charts.example.com"}},
+		},
+	}
+	root.AddDependency(sub)
+	dir := t.TempDir()
+	archive, err := chartutil.Save(root, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+	// the chart is cached by file name
+	cached := filepath.Join(os.TempDir(), filepath.Base(archive))
+	os.Remove(cached)
+	t.Cleanup(func() { os.Remove(cached) })
+
+	// the zero value keeps the vendored subcharts, without downloading anything
+	pkg := AhPackage{Name: "vendored", Version: "0.1.0", ChartUrl: srv.URL + "/" + filepath.Base(archive), HTTPClient: srv.Client()}
+	comps, err := pkg.GenerateComponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 1 || comps[0].Component.Kind != "Widget" {
+		t.Fatalf("expected the CRD of the vendored subchart, got %+v", comps)
+	}
+}
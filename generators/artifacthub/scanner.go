@@ -19,6 +19,10 @@ var AhApiSearchParams = map[string]string{
 const AhTextSearchQueryFieldName = "ts_query_web"
 
 func GetAhPackagesWithName(name string) ([]AhPackage, error) {
+	return getAhPackagesWithName(http.DefaultClient, name)
+}
+
+func getAhPackagesWithName(client *http.Client, name string) ([]AhPackage, error) {
 	pkgs := make([]AhPackage, 0)
 	url := fmt.Sprintf("%s/packages/search?%s=%s&", ArtifactHubAPIEndpint, AhTextSearchQueryFieldName, name)
 	// add params
@@ -26,7 +30,7 @@ func GetAhPackagesWithName(name string) ([]AhPackage, error) {
 		url = fmt.Sprintf("%s%s=%s&", url, key, val)
 	}
 	// get packages
-	resp, err := http.Get(url)
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, ErrGetAhPackage(err)
 	}
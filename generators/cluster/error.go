@@ -0,0 +1,23 @@
+package cluster
+
+import (
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrListClusterCRDsCode     = "meshkit-11255"
+	ErrGetClusterOpenAPICode   = "meshkit-11256"
+	ErrRegisterClusterCompCode = "meshkit-11257"
+)
+
+func ErrListClusterCRDs(err error) error {
+	return errors.New(ErrListClusterCRDsCode, errors.Alert, []string{"Could not list the CRDs installed in the cluster"}, []string{err.Error()}, []string{"The cluster is not reachable", "The service account does not have permissions to list customresourcedefinitions"}, []string{"Make sure the cluster is reachable", "Grant list permissions on customresourcedefinitions.apiextensions.k8s.io"})
+}
+
+func ErrGetClusterOpenAPI(err error) error {
+	return errors.New(ErrGetClusterOpenAPICode, errors.Alert, []string{"Could not get the OpenAPI schema of the cluster"}, []string{err.Error()}, []string{"The cluster is not reachable", "The /openapi/v2 endpoint is disabled on the API server"}, []string{"Make sure the cluster is reachable", "Disable discovery of core API resources"})
+}
+
+func ErrRegisterClusterComp(err error, context string) error {
+	return errors.New(ErrRegisterClusterCompCode, errors.Alert, []string{"Could not register the components discovered in the cluster ", context}, []string{err.Error()}, []string{"The registry database is not reachable"}, []string{"Make sure the database is reachable and retry"})
+}
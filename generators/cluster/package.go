@@ -0,0 +1,133 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/generators/openapi"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/registry"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/component"
+	"github.com/layer5io/meshkit/utils/manifests"
+)
+
+// ClusterPackage holds the API resources discovered in a cluster.
+type ClusterPackage struct {
+	Name        string `yaml:"name" json:"name"`
+	ContextName string `yaml:"context" json:"context"`
+	Server      string `yaml:"server" json:"server"`
+	version     string
+	crds        []string
+	// OpenAPI v2 document served by the cluster, only present when core resources are to be included.
+	openAPIDocument []byte
+}
+
+// GetVersion returns the version of the Kubernetes API server.
+func (pkg ClusterPackage) GetVersion() string {
+	return pkg.version
+}
+
+func (pkg ClusterPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error) {
+	components := make([]v1beta1.ComponentDefinition, 0)
+	var errs []error
+	crdGroups := make(map[string]struct{})
+
+	for _, crd := range pkg.crds {
+		comps, err := component.GenerateServedVersions(crd)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, comp := range comps {
+			crdGroups[apiGroup(comp.Component.Version)] = struct{}{}
+			components = append(components, pkg.tag(comp))
+		}
+	}
+
+	if pkg.openAPIDocument != nil {
+		oapiPkg, err := openapi.NewPackage(pkg.Name, pkg.sourceURI(), pkg.openAPIDocument)
+		if err != nil {
+			return components, err
+		}
+		comps, err := oapiPkg.GenerateComponents()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, comp := range comps {
+			// The OpenAPI document of the cluster describes the custom resources too, which are already generated from their CRDs.
+			if _, ok := crdGroups[apiGroup(comp.Component.Version)]; ok {
+				continue
+			}
+			components = append(components, pkg.tag(comp))
+		}
+	}
+	return components, utils.CombineErrors(errs, "\n")
+}
+
+// Register generates the components and registers them under the "kubernetes" host, scoped to the context of the cluster.
+// Registering again after the cluster changes, registers the newly discovered components and removes the components
+// of the context no longer discovered. The components are only removed if all of them are generated and registered.
+func (pkg ClusterPackage) Register(rm *registry.RegistryManager) error {
+	comps, genErr := pkg.GenerateComponents()
+	if genErr != nil && len(comps) == 0 {
+		return genErr
+	}
+	host := v1beta1.Host{
+		Hostname: v1beta1.Kubernetes{}.String(),
+		Metadata: pkg.ContextName,
+	}
+	var errs []error
+	registered := make([]uuid.UUID, 0, len(comps))
+	for i := range comps {
+		err := rm.RegisterEntity(host, &comps[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		registered = append(registered, comps[i].ID)
+	}
+	if len(errs) != 0 {
+		return ErrRegisterClusterComp(utils.CombineErrors(errs, "\n"), pkg.ContextName)
+	}
+	if genErr != nil {
+		return nil
+	}
+	if _, err := rm.RemoveStaleComponents(host, registered); err != nil {
+		return ErrRegisterClusterComp(err, pkg.ContextName)
+	}
+	return nil
+}
+
+func (pkg ClusterPackage) tag(comp v1beta1.ComponentDefinition) v1beta1.ComponentDefinition {
+	if comp.Metadata == nil {
+		comp.Metadata = make(map[string]interface{})
+	}
+	comp.Metadata["clusterContext"] = pkg.ContextName
+	comp.Metadata["clusterServer"] = pkg.Server
+	if comp.Model.Metadata == nil {
+		comp.Model.Metadata = make(map[string]interface{})
+	}
+	comp.Model.Metadata["source_uri"] = pkg.sourceURI()
+	comp.Model.Version = pkg.version
+	comp.Model.Name = pkg.Name
+	comp.Model.Category = v1beta1.Category{
+		Name: v1beta1.DefaultCategory,
+	}
+	comp.Model.DisplayName = manifests.FormatToReadableString(comp.Model.Name)
+	return comp
+}
+
+func (pkg ClusterPackage) sourceURI() string {
+	return fmt.Sprintf("kubernetes://%s", pkg.ContextName)
+}
+
+// apiGroup returns the group of an apiVersion, core resources have an empty group.
+func apiGroup(apiVersion string) string {
+	group, _, found := strings.Cut(apiVersion, "/")
+	if !found {
+		return ""
+	}
+	return group
+}
@@ -0,0 +1,72 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// ClusterPackageManager discovers the CRDs (and optionally the core API resources) served by a live cluster.
+type ClusterPackageManager struct {
+	PackageName string
+	Client      *kubernetes.Client
+	// Name of the kubeconfig context the client points to, generated components are tagged with it.
+	ContextName string
+	// When set, components are generated for the built-in API resources (Pods, Deployments...) as well.
+	IncludeCoreResources bool
+}
+
+func (cpm ClusterPackageManager) GetPackage() (models.Package, error) {
+	if cpm.Client == nil || cpm.Client.KubeClient == nil || cpm.Client.DynamicKubeClient == nil {
+		return nil, ErrListClusterCRDs(fmt.Errorf("nil kubernetes client"))
+	}
+	return cpm.discover(context.Background(), cpm.Client.DynamicKubeClient, cpm.Client.KubeClient.Discovery())
+}
+
+// discover lists the CRDs through the dynamic client and reads the server version (and the OpenAPI document) through the discovery client.
+func (cpm ClusterPackageManager) discover(ctx context.Context, dyn dynamic.Interface, disc discovery.DiscoveryInterface) (ClusterPackage, error) {
+	crdList, err := dyn.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return ClusterPackage{}, ErrListClusterCRDs(err)
+	}
+	crds := make([]string, 0, len(crdList.Items))
+	for _, crd := range crdList.Items {
+		// JSON is valid YAML, which is what the component generator expects.
+		byt, err := json.Marshal(crd.Object)
+		if err != nil {
+			return ClusterPackage{}, utils.ErrMarshal(err)
+		}
+		crds = append(crds, string(byt))
+	}
+
+	pkg := ClusterPackage{
+		Name:        cpm.PackageName,
+		ContextName: cpm.ContextName,
+		Server:      cpm.Client.RestConfig.Host,
+		crds:        crds,
+	}
+
+	serverVersion, err := disc.ServerVersion()
+	if err == nil {
+		pkg.version = serverVersion.GitVersion
+	}
+
+	if cpm.IncludeCoreResources {
+		doc, err := disc.RESTClient().Get().AbsPath("/openapi/v2").SetHeader("Accept", "application/json").Do(ctx).Raw()
+		if err != nil {
+			return ClusterPackage{}, ErrGetClusterOpenAPI(err)
+		}
+		pkg.openAPIDocument = doc
+	}
+	return pkg, nil
+}
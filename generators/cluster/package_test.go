@@ -0,0 +1,187 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/registry"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func testCRD(name, group, kind string, versions ...string) *unstructured.Unstructured {
+	vers := make([]interface{}, 0, len(versions))
+	for _, v := range versions {
+		vers = append(vers, map[string]interface{}{
+			"name":    v,
+			"served":  true,
+			"storage": v == versions[0],
+			"schema": map[string]interface{}{
+				"openAPIV3Schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"spec": map[string]interface{}{"type": "object"},
+					},
+				},
+			},
+		})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"group":    group,
+			"names":    map[string]interface{}{"kind": kind, "plural": name},
+			"scope":    "Namespaced",
+			"versions": vers,
+		},
+	}}
+}
+
+func newFakeClients(objs ...runtime.Object) (*fakedynamic.FakeDynamicClient, *fakediscovery.FakeDiscovery) {
+	dyn := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"}, objs...)
+	disc := &fakediscovery.FakeDiscovery{
+		Fake:               &k8stesting.Fake{},
+		FakedServerVersion: &version.Info{GitVersion: "v1.28.4"},
+	}
+	return dyn, disc
+}
+
+func TestClusterPackage(t *testing.T) {
+	dyn, disc := newFakeClients(
+		testCRD("widgets", "example.com", "Widget", "v1", "v1beta1"),
+		testCRD("gadgets", "example.com", "Gadget", "v1"),
+	)
+	cpm := ClusterPackageManager{
+		PackageName: "kind-cluster",
+		ContextName: "kind-kind",
+		Client:      &kubernetes.Client{RestConfig: rest.Config{Host: "https://127.0.0.1:6443"}},
+	}
+
+	pkg, err := cpm.discover(context.Background(), dyn, disc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkg.crds) != 2 {
+		t.Fatalf("expected 2 CRDs, got %d", len(pkg.crds))
+	}
+	if pkg.GetVersion() != "v1.28.4" {
+		t.Errorf("expected version v1.28.4, got %q", pkg.GetVersion())
+	}
+
+	comps, err := pkg.GenerateComponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 3 {
+		t.Fatalf("expected 3 components (one per served version), got %d", len(comps))
+	}
+	for _, comp := range comps {
+		if comp.Model.Name != "kind-cluster" || comp.Model.Version != "v1.28.4" {
+			t.Errorf("%s: unexpected model %s@%s", comp.Component.Kind, comp.Model.Name, comp.Model.Version)
+		}
+		if comp.Model.Category.Name != v1beta1.DefaultCategory {
+			t.Errorf("%s: expected category %q, got %q", comp.Component.Kind, v1beta1.DefaultCategory, comp.Model.Category.Name)
+		}
+		if comp.Metadata["clusterContext"] != "kind-kind" || comp.Metadata["clusterServer"] != "https://127.0.0.1:6443" {
+			t.Errorf("%s: missing cluster metadata: %v", comp.Component.Kind, comp.Metadata)
+		}
+		if comp.Model.Metadata["source_uri"] != "kubernetes://kind-kind" {
+			t.Errorf("%s: unexpected source_uri %v", comp.Component.Kind, comp.Model.Metadata["source_uri"])
+		}
+	}
+}
+
+func TestClusterPackageRegisterRemovesStale(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: "file::memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.DBClose() })
+	rm, err := registry.NewRegistryManager(&db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a component of another registrant is left as it is
+	other := &v1beta1.ComponentDefinition{
+		Model:     v1beta1.Model{Name: "other", Category: v1beta1.Category{Name: v1beta1.DefaultCategory}, Model: v1beta1.ModelEntity{Version: "v1.0.0"}},
+		Metadata:  map[string]interface{}{},
+		Component: v1beta1.ComponentEntity{TypeMeta: v1beta1.TypeMeta{Kind: "Other", Version: "example.com/v1"}, Schema: `{"type": "object"}`},
+	}
+	if err := rm.RegisterEntity(v1beta1.Host{Hostname: "artifacthub"}, other); err != nil {
+		t.Fatal(err)
+	}
+	cpm := ClusterPackageManager{
+		PackageName: "kind-cluster",
+		ContextName: "kind-kind",
+		Client:      &kubernetes.Client{RestConfig: rest.Config{Host: "https://127.0.0.1:6443"}},
+	}
+	register := func(crds ...runtime.Object) []string {
+		t.Helper()
+		dyn, disc := newFakeClients(crds...)
+		pkg, err := cpm.discover(context.Background(), dyn, disc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pkg.Register(rm); err != nil {
+			t.Fatal(err)
+		}
+		entities, _, _, err := rm.GetEntities(&regv1beta1.ComponentFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kinds []string
+		for _, e := range entities {
+			comp := e.(*v1beta1.ComponentDefinition)
+			kinds = append(kinds, comp.Component.Kind+"/"+comp.Component.Version)
+		}
+		sort.Strings(kinds)
+		return kinds
+	}
+
+	got := register(testCRD("widgets", "example.com", "Widget", "v1", "v1beta1"), testCRD("gadgets", "example.com", "Gadget", "v1"))
+	if want := []string{"Gadget/example.com/v1", "Other/example.com/v1", "Widget/example.com/v1", "Widget/example.com/v1beta1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got components %v, want %v", got, want)
+	}
+	got = register(testCRD("widgets", "example.com", "Widget", "v1"))
+	if want := []string{"Other/example.com/v1", "Widget/example.com/v1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got components %v after the cluster changed, want %v", got, want)
+	}
+}
+
+func TestClusterPackageNoCRDs(t *testing.T) {
+	dyn, disc := newFakeClients()
+	cpm := ClusterPackageManager{PackageName: "empty", Client: &kubernetes.Client{}}
+
+	pkg, err := cpm.discover(context.Background(), dyn, disc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comps, err := pkg.GenerateComponents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 0 {
+		t.Errorf("expected no components, got %d", len(comps))
+	}
+}
+
+func TestClusterPackageManagerNilClient(t *testing.T) {
+	if _, err := (ClusterPackageManager{}).GetPackage(); err == nil {
+		t.Error("expected an error for a nil client")
+	}
+}
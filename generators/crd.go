@@ -0,0 +1,123 @@
+package generators
+
+import (
+	"bytes"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/component"
+	"github.com/layer5io/meshkit/utils/manifests"
+)
+
+var crdFileExtensions = []string{".yaml", ".yml", ".json"}
+
+// GenerateComponentsFromCRDs generates components for all the CRDs found in the source.
+// The source can be a http/https URL, a path to a file or directory (optionally prefixed with file://) or a raw YAML stream of CRDs.
+// A component is generated for each served version of a CRD, and of the composite resources and claims defined by Crossplane XRDs.
+// The model of the generated components is left for the caller to fill, unless the source is a Crossplane package whose
+// crossplane.yaml names it.
+func GenerateComponentsFromCRDs(source string) ([]v1beta1.ComponentDefinition, error) {
+	return generateComponentsFromCRDs(source, http.DefaultClient)
+}
+
+// generateComponentsFromCRDs is GenerateComponentsFromCRDs, the http/https sources being fetched with the client.
+func generateComponentsFromCRDs(source string, client *http.Client) ([]v1beta1.ComponentDefinition, error) {
+	data, sourceURI, err := readCRDSource(source, client)
+	if err != nil {
+		return nil, ErrReadCRDSource(err)
+	}
+
+	crds, errs := component.FilterCRDs(bytes.Split(data, []byte("\n---\n")))
+	resources := component.DecodeResources(string(data))
+	xrdComponents, xrdErrs := generateXRDComponents(resources)
+	errs = append(errs, xrdErrs...)
+	crossplanePackage := parseCrossplanePackage(resources)
+	components := make([]v1beta1.ComponentDefinition, 0)
+	for _, crd := range crds {
+		comps, err := component.GenerateServedVersions(crd)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, comp := range comps {
+			component.SetDefaultsAndPresets(&comp, resources)
+			components = append(components, comp)
+		}
+	}
+	components = append(components, xrdComponents...)
+	for i := range components {
+		comp := &components[i]
+		if sourceURI != "" {
+			if comp.Model.Metadata == nil {
+				comp.Model.Metadata = make(map[string]interface{})
+			}
+			comp.Model.Metadata["source_uri"] = sourceURI
+		}
+		if crossplanePackage != nil {
+			if comp.Model.Metadata == nil {
+				comp.Model.Metadata = make(map[string]interface{})
+			}
+			comp.Model.Metadata[CrossplanePackageMetadataKey] = crossplanePackage
+			if comp.Model.Name == "" {
+				comp.Model.Name = crossplanePackage.Name
+				comp.Model.DisplayName = manifests.FormatToReadableString(crossplanePackage.Name)
+			}
+		}
+	}
+	return components, utils.CombineErrors(errs, "\n")
+}
+
+// readCRDSource returns the contents of the source along with the uri it was read from.
+// The uri is empty when the source is a raw YAML stream.
+func readCRDSource(source string, client *http.Client) ([]byte, string, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		content, err := utils.ReadRemoteFileWithClient(client, source)
+		if err != nil {
+			return nil, "", err
+		}
+		return []byte(content), source, nil
+	}
+
+	path := strings.TrimPrefix(source, "file://")
+	info, err := os.Stat(path)
+	if err != nil {
+		// Can not be resolved to a file, hence consider it to be a raw YAML stream.
+		if strings.HasPrefix(source, "file://") {
+			return nil, "", utils.ErrReadFile(err, path)
+		}
+		return []byte(source), "", nil
+	}
+	if !info.IsDir() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", utils.ErrReadFile(err, path)
+		}
+		return content, source, nil
+	}
+
+	var buf bytes.Buffer
+	err = filepath.WalkDir(path, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !utils.Contains(crdFileExtensions, strings.ToLower(filepath.Ext(filePath))) {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return utils.ErrReadFile(err, filePath)
+		}
+		buf.Write(content)
+		buf.WriteString("\n---\n")
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), source, nil
+}
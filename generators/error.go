@@ -4,8 +4,23 @@ import "github.com/layer5io/meshkit/errors"
 
 var (
 	ErrUnsupportedRegistrantCode = "meshkit-11138"
+	ErrReadCRDSourceCode         = "meshkit-11250"
+	ErrValidateGeneratedCode     = "meshkit-11262"
+	ErrWriteComponentsCode       = "meshkit-11299"
 )
 
 func ErrUnsupportedRegistrant(err error) error {
 	return errors.New(ErrUnsupportedRegistrantCode, errors.Alert, []string{"unsupported registrant"}, []string{err.Error()}, []string{"Select from one of the supported registrants"}, []string{"Check docs for the list of supported registrants"})
 }
+
+func ErrReadCRDSource(err error) error {
+	return errors.New(ErrReadCRDSourceCode, errors.Alert, []string{"Could not read CRDs from the given source"}, []string{err.Error()}, []string{"The URL is not reachable", "The file or directory does not exist", "Insufficient permissions"}, []string{"Make sure the source is a valid URL, file path, directory path or YAML stream"})
+}
+
+func ErrValidateGenerated(err error, dir string) error {
+	return errors.New(ErrValidateGeneratedCode, errors.Alert, []string{"Could not validate the generated components in ", dir}, []string{err.Error()}, []string{"The directory does not exist", "Insufficient permissions"}, []string{"Make sure the directory exists and is readable"})
+}
+
+func ErrWriteComponents(err error, dir string) error {
+	return errors.New(ErrWriteComponentsCode, errors.Alert, []string{"Could not write the generated components to ", dir}, []string{err.Error()}, []string{"The directory could not be created", "Insufficient permissions"}, []string{"Make sure the output directory is writable"})
+}
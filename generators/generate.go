@@ -0,0 +1,192 @@
+package generators
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/cas"
+	"github.com/layer5io/meshkit/utils/manifests"
+)
+
+// GenerateOptions configures GenerateComponents.
+type GenerateOptions struct {
+	// Source of the components: a git://, release:// or http(s) URL, e.g. of a repository or a Helm chart,
+	// or a path to a file or directory of CRDs. Ignored for Artifact Hub packages.
+	Source string
+	// Registrant the package is fetched from, artifacthub or github.
+	// If empty, github is used for URLs and local sources are read as CRDs.
+	Registrant string
+	// PackageName is the name of the package, e.g. the name of the chart on Artifact Hub.
+	PackageName string
+	// Model, ModelVersion and Category override the model of the generated components, if set.
+	Model        string
+	ModelVersion string
+	Category     string
+	// Icons, if set, processes the icons of the generated components. Unless it has a Fetch function or an HTTPClient,
+	// it is given the client of the Mirrors.
+	Icons *models.IconProcessor
+	// Metadata, if set, enriches the models of the generated components with the metadata of its sources.
+	// Model and Category still take precedence.
+	Metadata *models.MetadataEnricher
+	// Mirrors, if set, fetch the sources, e.g. the charts and their dependencies, from local mirrors, see MirrorConfig.
+	// The generators are given the client of the mirrors, the other HTTP requests of the process are left as is.
+	Mirrors *models.MirrorConfig
+	// Store, if set, caches the downloaded manifests, charts and release assets by content, so that repeated runs reuse
+	// them, see cas.Store. A store with a failing Options.Fetch only serves the content it holds, e.g. air-gapped.
+	Store *cas.Store
+	// GeneratedAt is the time of the generation recorded in the provenance of the components. It defaults to
+	// SOURCE_DATE_EPOCH if set, no time is recorded otherwise.
+	GeneratedAt time.Time
+}
+
+// GenerateComponents generates the components of the package described by the options.
+// Components which can't be generated are reported in the returned error along with the components generated.
+// The output is deterministic: the components are sorted and their IDs derived from their identity, see StableComponentID.
+// The provenance of each component is recorded in its metadata, see v1beta1.Provenance, set GeneratedAt to reproduce the output.
+func GenerateComponents(opts GenerateOptions) ([]v1beta1.ComponentDefinition, error) {
+	var components []v1beta1.ComponentDefinition
+	var err error
+	if opts.Registrant == "" && !isRemoteSource(opts.Source) {
+		components, err = generateComponentsFromCRDs(opts.Source, opts.httpClient())
+	} else {
+		components, err = generateFromPackage(opts)
+	}
+	if components == nil {
+		return nil, err
+	}
+	// the metadata is cached by model, so is its error
+	var metadataErrs []error
+	generatedAt := generationTime(opts)
+	if opts.Icons != nil && opts.Mirrors != nil && opts.Icons.Fetch == nil && opts.Icons.HTTPClient == nil {
+		opts.Icons.HTTPClient = opts.Mirrors.Client()
+	}
+	for i := range components {
+		recordProvenance(&components[i], opts, generatedAt)
+		ApplyProviderConventions(&components[i])
+		if opts.Metadata != nil {
+			if err := opts.Metadata.Enrich(&components[i].Model); err != nil && !containsError(metadataErrs, err) {
+				metadataErrs = append(metadataErrs, err)
+			}
+		}
+		overrideModel(&components[i], opts)
+		if opts.Icons != nil {
+			opts.Icons.Process(&components[i])
+		}
+	}
+	stabilize(components)
+	if len(metadataErrs) > 0 {
+		if err != nil {
+			metadataErrs = append([]error{err}, metadataErrs...)
+		}
+		err = utils.CombineErrors(metadataErrs, "\n")
+	}
+	return components, err
+}
+
+func (opts GenerateOptions) httpClient() *http.Client {
+	if opts.Mirrors == nil {
+		return http.DefaultClient
+	}
+	return opts.Mirrors.Client()
+}
+
+func containsError(errs []error, err error) bool {
+	for _, e := range errs {
+		if e == err {
+			return true
+		}
+	}
+	return false
+}
+
+func isRemoteSource(source string) bool {
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "git", "release", "http", "https":
+		return true
+	}
+	return false
+}
+
+func generateFromPackage(opts GenerateOptions) ([]v1beta1.ComponentDefinition, error) {
+	registrant := opts.Registrant
+	if registrant == "" {
+		registrant = gitHub
+	}
+	packageName := opts.PackageName
+	if packageName == "" {
+		packageName = opts.Model
+	}
+	var cloneURL func(string) (string, error)
+	if opts.Mirrors != nil {
+		cloneURL = opts.Mirrors.Resolve
+	}
+	pm, err := newGenerator(registrant, opts.Source, packageName, opts.httpClient(), cloneURL, opts.Store)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := pm.GetPackage()
+	if err != nil {
+		return nil, err
+	}
+	return pkg.GenerateComponents()
+}
+
+func overrideModel(comp *v1beta1.ComponentDefinition, opts GenerateOptions) {
+	if opts.Model != "" {
+		comp.Model.Name = opts.Model
+		comp.Model.DisplayName = manifests.FormatToReadableString(opts.Model)
+	}
+	if opts.ModelVersion != "" {
+		comp.Model.Version = opts.ModelVersion
+		comp.Model.Model.Version = opts.ModelVersion
+	}
+	if opts.Category != "" {
+		comp.Model.Category.Name = opts.Category
+	}
+	if comp.Model.SchemaVersion == "" {
+		comp.Model.SchemaVersion = v1beta1.SchemaVersion
+	}
+}
+
+// WriteComponents writes the components as JSON files to dir, at their ComponentFileName. The components mapping to
+// the same file, e.g. the same kind and API version of two models, are reported instead of overwriting each other.
+func WriteComponents(components []v1beta1.ComponentDefinition, dir string) error {
+	errs := make([]error, 0)
+	written := make(map[string]v1beta1.ComponentDefinition)
+	for _, comp := range components {
+		if comp.Component.Kind == "" {
+			continue
+		}
+		name := ComponentFileName(comp)
+		if other, ok := written[name]; ok {
+			if StableComponentID(other) != StableComponentID(comp) {
+				errs = append(errs, fmt.Errorf("the components %s of %s and %s both map to %s", comp.Component.Kind, other.Model.Name, comp.Model.Name, name))
+			}
+			continue
+		}
+		written[name] = comp
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			errs = append(errs, utils.ErrCreateDir(err, filepath.Dir(path)))
+			continue
+		}
+		if err := utils.WriteJSONToFile[v1beta1.ComponentDefinition](path, comp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return ErrWriteComponents(utils.CombineErrors(errs, "\n"), dir)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package generators
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils/cas"
+	"github.com/layer5io/meshkit/utils/component"
+)
+
+const backendCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: backends.example.io
+spec:
+  group: example.io
+  names:
+    kind: Backend
+    plural: backends
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              url:
+                type: string
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              url:
+                type: string
+`
+
+func TestGenerateAndWriteComponents(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "backend.yaml"), []byte(backendCRD), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	components, err := GenerateComponents(GenerateOptions{Source: src, Model: "example", ModelVersion: "v1.0.0", Category: "Provisioning"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(components) != 2 {
+		t.Fatalf("expected a component per served version, got %d", len(components))
+	}
+	for _, comp := range components {
+		if comp.Model.Name != "example" || comp.Model.Version != "v1.0.0" || comp.Model.Category.Name != "Provisioning" {
+			t.Errorf("expected the model to be overridden, got %+v", comp.Model)
+		}
+	}
+
+	out := t.TempDir()
+	if err := WriteComponents(components, out); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"example.io_v1alpha1/Backend.json", "example.io_v1/Backend.json"} {
+		if _, err := os.Stat(filepath.Join(out, path)); err != nil {
+			t.Errorf("expected %s to be written: %v", path, err)
+		}
+	}
+
+	if name := ComponentFileName(components[0]); name != "example.io_v1/Backend.json" {
+		t.Errorf("ComponentFileName = %s", name)
+	}
+	other := components[0]
+	other.Model.Name = "other"
+	if err := WriteComponents(append(components, other), t.TempDir()); err == nil {
+		t.Error("expected an error writing two components to the same file")
+	}
+
+	report, err := ValidateGenerated(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 2 || report.HasErrors() {
+		t.Errorf("expected the written components to be valid, got %+v", report)
+	}
+}
+
+func TestGenerateComponentsFromMirrors(t *testing.T) {
+	mirror := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mirror, "backend.yaml"), []byte(backendCRD), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mirror, "logo.svg"), []byte(`<svg xmlns="http://www.w3.org/2000/svg"><path fill="#326CE5"/></svg>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mirrors := &models.MirrorConfig{Strict: true, Mirrors: []models.Mirror{{Upstream: "https://crds.example.io/", Local: mirror}}}
+
+	icons := &models.IconProcessor{}
+	components, err := GenerateComponents(GenerateOptions{Source: "https://crds.example.io/backend.yaml/v1.0.0", Model: "example", Mirrors: mirrors, Icons: icons})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(components) == 0 || components[0].Component.Kind != "Backend" {
+		t.Errorf("expected the components of the mirrored CRD, got %+v", components)
+	}
+	// the icons are fetched through the mirrors as well
+	comp := v1beta1.ComponentDefinition{Metadata: map[string]interface{}{"svgColor": "https://crds.example.io/logo.svg"}}
+	icons.Process(&comp)
+	if color, _ := comp.Metadata["svgColor"].(string); !strings.Contains(color, "#326CE5") {
+		t.Errorf("expected the mirrored icon, got %s", color)
+	}
+	if _, err := GenerateComponents(GenerateOptions{Source: "https://charts.example.io/backend.yaml/v1.0.0", Model: "example", Mirrors: mirrors}); err == nil {
+		t.Error("expected an error generating components from an unmirrored source in strict mode")
+	}
+}
+
+func TestGenerateComponentsFromStore(t *testing.T) {
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		_, _ = w.Write([]byte(backendCRD))
+	}))
+	defer srv.Close()
+	store, err := cas.New(t.TempDir(), cas.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		components, err := GenerateComponents(GenerateOptions{Source: srv.URL + "/backend.yaml/v1.0.0", Model: "example", Store: store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(components) == 0 || components[0].Component.Kind != "Backend" {
+			t.Fatalf("expected the components of the stored CRD, got %+v", components)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("expected the CRD to be downloaded once, got %d downloads", downloads)
+	}
+}
+
+func TestGenerateComponentsProvenance(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "backend.yaml"), []byte(backendCRD), 0644); err != nil {
+		t.Fatal(err)
+	}
+	generatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	components, err := GenerateComponents(GenerateOptions{Source: src, Model: "example", ModelVersion: "v1.0.0", GeneratedAt: generatedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := components[0].Provenance()
+	if !ok {
+		t.Fatalf("no provenance in %+v", components[0].Metadata)
+	}
+	if p.Registrant != "crds" || p.Source != src || p.Generator != GeneratorName || p.GeneratedAt == nil || !p.GeneratedAt.Equal(generatedAt) || p.CRDDigest == "" {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+
+	if matching := FilterByProvenance(components, v1beta1.ProvenanceQuery{CRDDigest: p.CRDDigest}); len(matching) != 2 {
+		t.Errorf("expected the components of the CRD, got %d", len(matching))
+	}
+	if matching := FilterByProvenance(components, v1beta1.ProvenanceQuery{GeneratedBefore: generatedAt}); len(matching) != 0 {
+		t.Errorf("expected no components generated before %s, got %d", generatedAt, len(matching))
+	}
+	if matching := FilterByProvenance(components, v1beta1.ProvenanceQuery{CRDDigest: component.CRDDigest("other")}); len(matching) != 0 {
+		t.Errorf("expected no components of another CRD, got %d", len(matching))
+	}
+
+	t.Setenv(SourceDateEpochEnvVar, "")
+	components, err = GenerateComponents(GenerateOptions{Source: src, Model: "example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := components[0].Provenance(); p.GeneratedAt != nil {
+		t.Errorf("expected no generation time without GeneratedAt or %s, got %s", SourceDateEpochEnvVar, p.GeneratedAt)
+	}
+	if _, ok := components[0].Metadata[v1beta1.ProvenanceMetadataKey].(map[string]interface{})["generatedAt"]; ok {
+		t.Error("expected the generation time to be omitted from the metadata")
+	}
+	t.Setenv(SourceDateEpochEnvVar, "1700000000")
+	if at := generationTime(GenerateOptions{}); at == nil || at.Unix() != 1700000000 {
+		t.Errorf("generation time %v ignores %s", at, SourceDateEpochEnvVar)
+	}
+}
+
+func TestGenerateComponentsIsDeterministic(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "backend.yaml"), []byte(backendCRD), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := GenerateOptions{Source: src, Model: "example", ModelVersion: "v1.0.0", GeneratedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	var outs []string
+	var ids [][]string
+	for i := 0; i < 2; i++ {
+		components, err := GenerateComponents(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// sorted by API version
+		if len(components) != 2 || components[0].Component.Version != "example.io/v1" || components[1].Component.Version != "example.io/v1alpha1" {
+			t.Fatalf("expected the components to be sorted, got %+v", components)
+		}
+		var run []string
+		for _, comp := range components {
+			if comp.ID != StableComponentID(comp) {
+				t.Errorf("expected the ID of %s to be derived from its identity", comp.Component.Version)
+			}
+			run = append(run, comp.ID.String())
+		}
+		ids = append(ids, run)
+		out := t.TempDir()
+		if err := WriteComponents(components, out); err != nil {
+			t.Fatal(err)
+		}
+		outs = append(outs, out)
+	}
+	if ids[0][0] != ids[1][0] || ids[0][1] != ids[1][1] || ids[0][0] == ids[0][1] {
+		t.Errorf("expected stable and distinct IDs, got %v", ids)
+	}
+	for _, path := range []string{"example.io_v1alpha1/Backend.json", "example.io_v1/Backend.json"} {
+		first, err := os.ReadFile(filepath.Join(outs[0], path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := os.ReadFile(filepath.Join(outs[1], path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, second) {
+			t.Errorf("expected %s to be written identically", path)
+		}
+	}
+}
+
+func TestCanonicalSchema(t *testing.T) {
+	if got := CanonicalSchema(`{"b": 1, "a": {"d": 2, "c": 3}}`); got != `{"a":{"c":3,"d":2},"b":1}` {
+		t.Errorf("CanonicalSchema = %s", got)
+	}
+	if got := CanonicalSchema("not json"); got != "not json" {
+		t.Errorf("expected invalid JSON to be left as is, got %s", got)
+	}
+}
+
+func TestContentDigest(t *testing.T) {
+	comp := v1beta1.ComponentDefinition{}
+	comp.Model.Name = "example"
+	comp.Component.Kind = "Backend"
+	comp.Component.Version = "example.io/v1"
+	comp.Component.Schema = `{"type": "object", "properties": {"url": {"type": "string"}}}`
+	digest := ContentDigest(comp)
+	if !strings.HasPrefix(digest, "sha256:") {
+		t.Fatalf("unexpected digest %s", digest)
+	}
+
+	reordered := comp
+	reordered.Component.Schema = `{"properties": {"url": {"type": "string"}}, "type": "object"}`
+	if ContentDigest(reordered) != digest {
+		t.Error("expected the digest not to depend on the order of the keys of the schema")
+	}
+	changed := comp
+	changed.Component.Schema = `{"type": "object", "properties": {"url": {"type": "integer"}}}`
+	if ContentDigest(changed) == digest || StableComponentID(changed) != StableComponentID(comp) {
+		t.Error("expected a changed schema to change the digest but not the ID")
+	}
+}
+
+func TestIsRemoteSource(t *testing.T) {
+	for source, want := range map[string]bool{
+		"https://github.com/cert-manager/cert-manager":  true,
+		"git://github.com/istio/istio/master/manifests": true,
+		"release://github.com/kubernetes/kubernetes":    true,
+		"./crds":                    false,
+		"file:///tmp/crds.yaml":     false,
+		"apiVersion: v1\nkind: Pod": false,
+	} {
+		if got := isRemoteSource(source); got != want {
+			t.Errorf("isRemoteSource(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
@@ -2,11 +2,13 @@ package generators
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/layer5io/meshkit/generators/artifacthub"
 	"github.com/layer5io/meshkit/generators/github"
 	"github.com/layer5io/meshkit/generators/models"
 	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/cas"
 )
 
 const (
@@ -15,18 +17,38 @@ const (
 )
 
 func NewGenerator(registrant, url, packageName string) (models.PackageManager, error) {
+	return newGenerator(registrant, url, packageName, nil, nil, nil)
+}
+
+// newGenerator returns the generator of the registrant fetching with the client, through the store if it is set, and
+// cloning the git repositories from the URLs cloneURL maps them to, as is if nil.
+func newGenerator(registrant, url, packageName string, client *http.Client, cloneURL func(string) (string, error), store *cas.Store) (models.PackageManager, error) {
 	registrant = utils.ReplaceSpacesAndConvertToLowercase(registrant)
 	switch registrant {
 	case artifactHub:
 		return artifacthub.ArtifactHubPackageManager{
 			PackageName: packageName,
 			SourceURL:   url,
+			HTTPClient:  client,
+			Store:       store,
 		}, nil
 	case gitHub:
 		return github.GitHubPackageManager{
 			PackageName: packageName,
 			SourceURL:   url,
+			HTTPClient:  client,
+			CloneURL:    cloneURL,
+			Store:       store,
 		}, nil
 	}
 	return nil, ErrUnsupportedRegistrant(fmt.Errorf("generator not implemented for the registrant %s", registrant))
 }
+
+// NewStyledGenerator returns a generator whose components are styled according to the given profile.
+func NewStyledGenerator(registrant, url, packageName string, profile models.StylingProfile) (models.PackageManager, error) {
+	pm, err := NewGenerator(registrant, url, packageName)
+	if err != nil {
+		return nil, err
+	}
+	return models.StyledPackageManager{PackageManager: pm, Profile: profile}, nil
+}
@@ -7,8 +7,10 @@ import (
 )
 
 const (
-	ErrGenerateGitHubPackageCode  = "meshkit-11139"
-	ErrInvalidGitHubSourceURLCode = "meshkit-11140"
+	ErrGenerateGitHubPackageCode      = "meshkit-11139"
+	ErrInvalidGitHubSourceURLCode     = "meshkit-11140"
+	ErrGetGitHubReleaseCode           = "meshkit-11259"
+	ErrGitHubReleaseAssetNotFoundCode = "meshkit-11260"
 )
 
 func ErrGenerateGitHubPackage(err error, pkgName string) error {
@@ -16,5 +18,13 @@ func ErrGenerateGitHubPackage(err error, pkgName string) error {
 }
 
 func ErrInvalidGitHubSourceURL(err error) error {
-	return errors.New(ErrInvalidGitHubSourceURLCode, errors.Alert, []string{}, []string{err.Error()}, []string{"sourceURL provided might be invalid", "provided repo/version tag does not exist"}, []string{"ensure source url follows the format: git://<owner>/<repositoryname>/<branch>/<version>/<path from the root of repository>"})
+	return errors.New(ErrInvalidGitHubSourceURLCode, errors.Alert, []string{}, []string{err.Error()}, []string{"sourceURL provided might be invalid", "provided repo/version tag does not exist"}, []string{"ensure source url follows the format: git://<owner>/<repositoryname>/<branch>/<version>/<path from the root of repository>", "ensure release source url follows the format: release://github.com/<owner>/<repositoryname>/<tag>/<asset name>"})
+}
+
+func ErrGetGitHubRelease(err error, owner, repo string) error {
+	return errors.New(ErrGetGitHubReleaseCode, errors.Alert, []string{fmt.Sprintf("could not get the release for %s/%s", owner, repo)}, []string{err.Error()}, []string{"release tag does not exist", "repository might be private", "GitHub API rate limit exceeded"}, []string{"verify the release tag exists", "provide a token with read access to the repository"})
+}
+
+func ErrGitHubReleaseAssetNotFound(asset, tag string) error {
+	return errors.New(ErrGitHubReleaseAssetNotFoundCode, errors.Alert, []string{fmt.Sprintf("asset %s not found in release %s", asset, tag)}, []string{fmt.Sprintf("none of the assets of the release %s match %s", tag, asset)}, []string{"asset name or pattern is incorrect"}, []string{"verify the asset name against the assets published with the release"})
 }
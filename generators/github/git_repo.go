@@ -3,6 +3,7 @@ package github
 import (
 	"bufio"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -16,8 +17,15 @@ import (
 
 type GitRepo struct {
 	// <git://github.com/owner/repo/branch/versiontag/root(path to the directory/file)>
+	// <git://github.com/owner/repo/branch//root>, "//" separates the path in a monorepo and allows branch names containing "/"
 	URL         *url.URL
 	PackageName string
+	// Token used to clone private repositories
+	Token string
+	// HTTPClient fetches the releases of the repository, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// CloneURL maps the URL of the repository cloned, e.g. to the URL of a mirror, it is cloned as is if nil
+	CloneURL func(url string) (string, error)
 }
 
 // Assumpations: 1. Always a K8s manifest
@@ -31,11 +39,10 @@ func (gr GitRepo) GetContent() (models.Package, error) {
 		return nil, err
 	}
 
-	versions, err := utils.GetLatestReleaseTagsSorted(owner, repo)
+	version, err := gr.getLatestVersion(owner, repo)
 	if err != nil {
 		return nil, ErrInvalidGitHubSourceURL(err)
 	}
-	version := versions[len(versions)-1]
 	dirPath := filepath.Join(os.TempDir(), owner, repo, branch)
 	_ = os.MkdirAll(dirPath, 0755)
 	filePath := filepath.Join(dirPath, utils.GetRandomAlphabetsOfDigit(5))
@@ -59,6 +66,16 @@ func (gr GitRepo) GetContent() (models.Package, error) {
 	if version != "" {
 		gw = gw.ReferenceName(fmt.Sprintf("refs/tags/%s", version))
 	}
+	if gr.Token != "" {
+		gw = gw.Auth(owner, gr.Token)
+	}
+	if gr.CloneURL != nil {
+		cloneURL, err := gr.CloneURL(fmt.Sprintf("https://github.com/%s/%s", owner, repo))
+		if err != nil {
+			return nil, walker.ErrCloningRepo(err)
+		}
+		gw = gw.URL(cloneURL)
+	}
 	err = gw.Walk()
 
 	if err != nil {
@@ -75,8 +92,38 @@ func (gr GitRepo) GetContent() (models.Package, error) {
 	}, nil
 }
 
+// getLatestVersion returns the latest release tag.
+// Release pages of private repositories aren't public, so the GitHub API is used when a token is provided.
+func (gr GitRepo) getLatestVersion(owner, repo string) (string, error) {
+	if gr.Token != "" {
+		release, err := getRelease(gr.HTTPClient, owner, repo, "latest", gr.Token)
+		if err != nil {
+			return "", err
+		}
+		return release.TagName, nil
+	}
+	client := gr.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	versions, err := utils.GetLatestReleaseTagsSortedWithClient(client, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	return versions[len(versions)-1], nil
+}
+
 func (gr GitRepo) extractRepoDetailsFromSourceURL() (owner, repo, branch, root string, err error) {
-	parts := strings.SplitN(strings.TrimPrefix(gr.URL.Path, "/"), "/", 4)
+	repoPath, subPath := splitSubPath(strings.TrimPrefix(gr.URL.Path, "/"))
+	if subPath != "" {
+		parts := strings.SplitN(repoPath, "/", 3)
+		if len(parts) == 3 && parts[2] != "" {
+			return parts[0], parts[1], parts[2], subPath, nil
+		}
+		err = ErrInvalidGitHubSourceURL(fmt.Errorf("Source URL %s is invalid, specify owner, repo, branch and filepath in the url according to the specified source url format", gr.URL.String()))
+		return
+	}
+	parts := strings.SplitN(repoPath, "/", 4)
 	size := len(parts)
 	if size > 3 {
 		owner = parts[0]
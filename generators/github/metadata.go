@@ -0,0 +1,105 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+// TopicCategories maps the topics of GitHub repositories to the categories and sub categories of the models.
+// The first topic of a repository found in the map sets the category.
+var TopicCategories = map[string][2]string{
+	"service-mesh":           {"Cloud Native Network", "Service Mesh"},
+	"networking":             {"Cloud Native Network", ""},
+	"ingress":                {"Cloud Native Network", "API Gateway"},
+	"api-gateway":            {"Cloud Native Network", "API Gateway"},
+	"observability":          {"Observability and Analysis", ""},
+	"monitoring":             {"Observability and Analysis", "Monitoring"},
+	"logging":                {"Observability and Analysis", "Logging"},
+	"tracing":                {"Observability and Analysis", "Tracing"},
+	"database":               {"Database", ""},
+	"storage":                {"Cloud Native Storage", ""},
+	"security":               {"Security & Compliance", ""},
+	"policy":                 {"Security & Compliance", "Security & Compliance"},
+	"serverless":             {"Serverless", ""},
+	"machine-learning":       {"Machine Learning", ""},
+	"gitops":                 {"App Definition and Development", "Continuous Integration & Delivery"},
+	"continuous-delivery":    {"App Definition and Development", "Continuous Integration & Delivery"},
+	"streaming":              {"App Definition and Development", "Streaming & Messaging"},
+	"messaging":              {"App Definition and Development", "Streaming & Messaging"},
+	"crossplane":             {"Provisioning", "Automation & Configuration"},
+	"infrastructure-as-code": {"Provisioning", "Automation & Configuration"},
+}
+
+// TopicsMetadataSource provides the description, the home page and the category, from its topics, of the GitHub
+// repository of the models, and marks archived repositories. The repository is found in the source_uri of the model
+// metadata, e.g. git://github.com/<owner>/<repository>/... or https://github.com/<owner>/<repository>/...
+type TopicsMetadataSource struct {
+	// Token is a GitHub personal access token, which raises the rate limit of the API.
+	Token string
+	// Repositories maps the names of the models to their owner/repository, overriding the source_uri of the models.
+	Repositories map[string]string
+	// HTTPClient sends the requests to the API, e.g. through models.MirrorConfig.Client, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+func (s TopicsMetadataSource) Name() string {
+	return "github"
+}
+
+func (s TopicsMetadataSource) ModelMetadata(model v1beta1.Model) (models.ModelMetadata, error) {
+	repository := s.Repositories[model.Name]
+	if repository == "" {
+		sourceURI, _ := model.Metadata["source_uri"].(string)
+		repository = repositoryOf(sourceURI)
+	}
+	if repository == "" {
+		return models.ModelMetadata{}, nil
+	}
+	resp, err := doRequest(s.HTTPClient, fmt.Sprintf("%s/repos/%s", GitHubAPIEndpoint, repository), s.Token, "application/vnd.github+json")
+	if err != nil {
+		return models.ModelMetadata{}, err
+	}
+	defer resp.Body.Close()
+	var repo struct {
+		Description string   `json:"description"`
+		Homepage    string   `json:"homepage"`
+		HTMLURL     string   `json:"html_url"`
+		Topics      []string `json:"topics"`
+		Archived    bool     `json:"archived"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return models.ModelMetadata{}, err
+	}
+	m := models.ModelMetadata{Description: repo.Description, DocsURL: repo.Homepage}
+	if m.DocsURL == "" {
+		m.DocsURL = repo.HTMLURL
+	}
+	for _, topic := range repo.Topics {
+		if category, ok := TopicCategories[topic]; ok {
+			m.Category, m.SubCategory = category[0], category[1]
+			break
+		}
+	}
+	if repo.Archived {
+		m.Maturity = "archived"
+	}
+	return m, nil
+}
+
+// repositoryOf returns the owner/repository of a URL of github.com, empty for other URLs.
+func repositoryOf(uri string) string {
+	_, path, found := strings.Cut(uri, "github.com/")
+	if !found {
+		return ""
+	}
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
+}
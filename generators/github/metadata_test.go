@@ -0,0 +1,40 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestTopicsMetadataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/istio/istio" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"description":"Connect, secure, control, and observe services.","homepage":"https://istio.io","topics":["kubernetes","service-mesh"],"archived":false}`)
+	}))
+	defer server.Close()
+	endpoint := GitHubAPIEndpoint
+	GitHubAPIEndpoint = server.URL
+	defer func() { GitHubAPIEndpoint = endpoint }()
+
+	source := TopicsMetadataSource{}
+	model := v1beta1.Model{Name: "istio-base", Metadata: map[string]interface{}{"source_uri": "git://github.com/istio/istio/master/manifests/charts/base/crds"}}
+	m, err := source.ModelMetadata(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Category != "Cloud Native Network" || m.SubCategory != "Service Mesh" || m.DocsURL != "https://istio.io" || m.Description == "" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	if _, err := (TopicsMetadataSource{Repositories: map[string]string{"istio-base": "istio/missing"}}).ModelMetadata(model); err == nil {
+		t.Error("expected an error for a missing repository")
+	}
+	if m, err := source.ModelMetadata(v1beta1.Model{Name: "local"}); err != nil || m.Description != "" {
+		t.Errorf("expected no metadata for a model without repository, got %+v, %v", m, err)
+	}
+}
@@ -33,6 +33,7 @@ func (gp GitHubPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, err
 
 	manifestBytes := bytes.Split(data, []byte("\n---\n"))
 	crds, errs := component.FilterCRDs(manifestBytes)
+	resources := component.DecodeResources(string(data))
 
 	for _, crd := range crds {
 		comp, err := component.Generate(crd)
@@ -42,6 +43,7 @@ func (gp GitHubPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, err
 		if comp.Metadata == nil {
 			comp.Metadata = make(map[string]interface{})
 		}
+		component.SetDefaultsAndPresets(&comp, resources)
 		if comp.Model.Metadata == nil {
 			comp.Model.Metadata = make(map[string]interface{})
 		}
@@ -1,15 +1,25 @@
 package github
 
 import (
+	"net/http"
 	"net/url"
 
 	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils/cas"
 	"github.com/layer5io/meshkit/utils/walker"
 )
 
 type GitHubPackageManager struct {
 	PackageName string
 	SourceURL   string
+	// Token is a GitHub personal access token, required for private repositories.
+	Token string
+	// HTTPClient downloads the files, the release assets and the release pages, e.g. from mirrors, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// CloneURL maps the URLs of the repositories cloned, e.g. to the URLs of mirrors, they are cloned as is if nil.
+	CloneURL func(url string) (string, error)
+	// Store, if set, caches the downloaded files and release assets by content, see cas.Store.
+	Store *cas.Store
 }
 
 func (ghpm GitHubPackageManager) GetPackage() (models.Package, error) {
@@ -20,7 +30,7 @@ func (ghpm GitHubPackageManager) GetPackage() (models.Package, error) {
 	}
 	protocol := url.Scheme
 
-	downloader := NewDownloaderForScheme(protocol, url, ghpm.PackageName)
+	downloader := newDownloaderForScheme(protocol, url, ghpm)
 	if downloader == nil {
 		return nil, ErrGenerateGitHubPackage(err, ghpm.PackageName)
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
@@ -118,3 +119,41 @@ func TestGenerateCompFromGitHub(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractRepoDetailsWithSubPath(t *testing.T) {
+	var tests = []struct {
+		sourceURL                 string
+		owner, repo, branch, root string
+	}{
+		{"git://github.com/meshery/meshery/master/install/kubernetes", "meshery", "meshery", "master", "install/kubernetes"},
+		{"git://github.com/meshery/meshery/release/v0.7//install/kubernetes/helm", "meshery", "meshery", "release/v0.7", "install/kubernetes/helm"},
+	}
+	for _, tt := range tests {
+		t.Run("ExtractRepoDetails", func(t *testing.T) {
+			u, _ := url.Parse(tt.sourceURL)
+			owner, repo, branch, root, err := GitRepo{URL: u}.ExtractRepoDetailsFromSourceURL()
+			if err != nil {
+				t.Fatalf("error extracting repo details: %v", err)
+			}
+			if owner != tt.owner || repo != tt.repo || branch != tt.branch || root != tt.root {
+				t.Errorf("got %s %s %s %s, want %s %s %s %s", owner, repo, branch, root, tt.owner, tt.repo, tt.branch, tt.root)
+			}
+		})
+	}
+}
+
+func TestSplitSubPath(t *testing.T) {
+	var tests = []struct {
+		location, want, wantSubPath string
+	}{
+		{"https://github.com/org/repo/releases/download/v1.0.0/crds.tar.gz", "https://github.com/org/repo/releases/download/v1.0.0/crds.tar.gz", ""},
+		{"https://github.com/org/repo/releases/download/v1.0.0/bundle.tar.gz//config/crds/", "https://github.com/org/repo/releases/download/v1.0.0/bundle.tar.gz", "config/crds"},
+		{"org/repo/v1.0.0/bundle.zip//crds", "org/repo/v1.0.0/bundle.zip", "crds"},
+	}
+	for _, tt := range tests {
+		got, gotSubPath := splitSubPath(tt.location)
+		if got != tt.want || gotSubPath != tt.wantSubPath {
+			t.Errorf("got %s and %s, want %s and %s", got, gotSubPath, tt.want, tt.wantSubPath)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package github
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/cas"
+)
+
+var GitHubAPIEndpoint = "https://api.github.com"
+
+type githubRelease struct {
+	TagName string               `json:"tag_name"`
+	Assets  []githubReleaseAsset `json:"assets"`
+}
+
+type githubReleaseAsset struct {
+	Name string `json:"name"`
+	// API URL of the asset, it serves the asset content when requested with "Accept: application/octet-stream", including for private repositories
+	URL string `json:"url"`
+}
+
+// Release downloads an asset attached to a GitHub release.
+type Release struct {
+	// <release://github.com/owner/repo/tag/asset-name//path inside the asset>
+	// The tag can be "latest" and the asset name can be a glob pattern, eg: "crds-*.tar.gz".
+	URL         *url.URL
+	PackageName string
+	Token       string
+	// HTTPClient fetches the release and downloads the asset, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// Store, if set, caches the asset by content, see cas.Store
+	Store *cas.Store
+}
+
+func (r Release) GetContent() (models.Package, error) {
+	owner, repo, tag, asset, subPath, err := r.extractReleaseDetailsFromSourceURL()
+	if err != nil {
+		return nil, err
+	}
+	release, err := getRelease(r.HTTPClient, owner, repo, tag, r.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchedAsset *githubReleaseAsset
+	for i, a := range release.Assets {
+		if ok, _ := path.Match(asset, a.Name); ok {
+			matchedAsset = &release.Assets[i]
+			break
+		}
+	}
+	if matchedAsset == nil {
+		return nil, ErrGitHubReleaseAssetNotFound(asset, release.TagName)
+	}
+
+	downloadDirPath := filepath.Join(os.TempDir(), utils.GetRandomAlphabetsOfDigit(5))
+	_ = os.MkdirAll(downloadDirPath, 0755)
+	downloadfilePath := filepath.Join(downloadDirPath, matchedAsset.Name)
+	// The URL of the asset comes from the API response, the token is never sent to hosts other than GitHub.
+	token := ""
+	if assetURL, err := url.Parse(matchedAsset.URL); err == nil && isGitHubHost(assetURL.Hostname()) {
+		token = r.Token
+	}
+	err = storedDownloadFile(r.Store, r.HTTPClient, downloadfilePath, matchedAsset.URL, token, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+
+	manifestFilePath := filepath.Join(os.TempDir(), utils.GetRandomAlphabetsOfDigit(5)) + ".yml"
+	manifestFile, err := os.Create(manifestFilePath)
+	if err != nil {
+		return nil, utils.ErrCreateFile(err, manifestFilePath)
+	}
+	w := bufio.NewWriter(manifestFile)
+	defer func() {
+		_ = os.RemoveAll(downloadDirPath)
+		_ = w.Flush()
+		_ = manifestFile.Close()
+	}()
+
+	err = processContent(w, downloadDirPath, downloadfilePath, subPath)
+	if err != nil {
+		return nil, err
+	}
+	return GitHubPackage{
+		Name:       r.PackageName,
+		filePath:   manifestFilePath,
+		repository: repo,
+		version:    release.TagName,
+		SourceURL:  r.URL.String(),
+	}, nil
+}
+
+func (r Release) extractReleaseDetailsFromSourceURL() (owner, repo, tag, asset, subPath string, err error) {
+	releasePath, subPath := splitSubPath(strings.TrimPrefix(r.URL.Path, "/"))
+	parts := strings.SplitN(releasePath, "/", 4)
+	if len(parts) != 4 || parts[3] == "" {
+		err = ErrInvalidGitHubSourceURL(fmt.Errorf("Source URL %s is invalid, specify owner, repo, release tag and asset name in the url according to the specified source url format", r.URL.String()))
+		return
+	}
+	return parts[0], parts[1], parts[2], parts[3], subPath, nil
+}
+
+// getRelease fetches the release with the given tag, "latest" fetches the latest release.
+func getRelease(client *http.Client, owner, repo, tag, token string) (release githubRelease, err error) {
+	releaseURL := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", GitHubAPIEndpoint, owner, repo, tag)
+	if tag == "latest" {
+		releaseURL = fmt.Sprintf("%s/repos/%s/%s/releases/latest", GitHubAPIEndpoint, owner, repo)
+	}
+	resp, err := doRequest(client, releaseURL, token, "application/vnd.github+json")
+	if err != nil {
+		return release, ErrGetGitHubRelease(err, owner, repo)
+	}
+	defer resp.Body.Close()
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	if err != nil {
+		return release, ErrGetGitHubRelease(err, owner, repo)
+	}
+	return release, nil
+}
+
+// downloadFile is same as utils.DownloadFile, additionally it authenticates the request when a token is provided.
+func downloadFile(client *http.Client, filePath, url, token, accept string) error {
+	resp, err := doRequest(client, url, token, accept)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return utils.ErrCreateFile(err, filePath)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, resp.Body)
+	if err != nil {
+		return utils.ErrWriteFile(err, filePath)
+	}
+	return nil
+}
+
+// storedDownloadFile is downloadFile, the content being fetched through the store if it is set, so that it is
+// downloaded once per store, or once per its Options.RefTTL.
+func storedDownloadFile(store *cas.Store, client *http.Client, filePath, url, token, accept string) error {
+	if store == nil {
+		return downloadFile(client, filePath, url, token, accept)
+	}
+	stored, _, err := store.FetchWith(context.Background(), url, "", func(ctx context.Context, url string) (io.ReadCloser, error) {
+		resp, err := doRequest(client, url, token, accept)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Body, nil
+	})
+	if err != nil {
+		return err
+	}
+	// the stored content must not be modified, the archives are extracted next to the copy
+	in, err := os.Open(stored)
+	if err != nil {
+		return utils.ErrReadFile(err, stored)
+	}
+	defer in.Close()
+	out, err := os.Create(filePath)
+	if err != nil {
+		return utils.ErrCreateFile(err, filePath)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return utils.ErrWriteFile(err, filePath)
+	}
+	return nil
+}
+
+// doRequest sends the request with the client, http.DefaultClient if nil.
+func doRequest(client *http.Client, url, token, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s, status code %d", url, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// splitSubPath splits the location at the first "//" (ignoring the one following the scheme).
+// The part after "//" is the path inside a repository or an archive, eg: "owner/repo/main//charts/crds".
+func splitSubPath(location string) (string, string) {
+	offset := 0
+	if i := strings.Index(location, "://"); i != -1 {
+		offset = i + len("://")
+	}
+	i := strings.Index(location[offset:], "//")
+	if i == -1 {
+		return location, ""
+	}
+	i += offset
+	return location[:i], strings.Trim(location[i+len("//"):], "/")
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+// releaseCRD is longer than the 512 bytes sniffed to detect YAML content
+var releaseCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gateways.example.com
+  annotations:
+    description: "` + strings.Repeat("gateway ", 64) + `"
+`
+
+func TestReleaseGetContent(t *testing.T) {
+	// the assets hosted on another host must be downloaded without the token
+	var assetAuthorization []string
+	assets := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assetAuthorization = append(assetAuthorization, r.Header.Get("Authorization"))
+		fmt.Fprint(w, releaseCRD)
+	}))
+	defer assets.Close()
+
+	var apiAuthorization []string
+	var api *httptest.Server
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiAuthorization = append(apiAuthorization, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/acme/gateway/releases/latest":
+			fmt.Fprintf(w, `{"tag_name":"v2.0.0","assets":[{"name":"README.md","url":"%[1]s/assets/1"},{"name":"crds-v2.0.0.yaml","url":"%[1]s/assets/2"}]}`, api.URL)
+		case "/repos/acme/gateway/releases/tags/v1.0.0":
+			// another host than the one of the API, which is 127.0.0.1 too
+			assetsURL := strings.Replace(assets.URL, "127.0.0.1", "localhost", 1)
+			fmt.Fprintf(w, `{"tag_name":"v1.0.0","assets":[{"name":"crds-v1.0.0.yaml","url":"%s/assets/3"}]}`, assetsURL)
+		case "/assets/2":
+			if r.Header.Get("Accept") != "application/octet-stream" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			fmt.Fprint(w, releaseCRD)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer api.Close()
+	endpoint := GitHubAPIEndpoint
+	GitHubAPIEndpoint = api.URL
+	defer func() { GitHubAPIEndpoint = endpoint }()
+
+	tests := []struct {
+		name        string
+		url         string
+		wantVersion string
+		wantErr     bool
+	}{
+		{name: "latest release, glob asset", url: "release://github.com/acme/gateway/latest/crds-*.yaml", wantVersion: "v2.0.0"},
+		{name: "tagged release, asset on another host", url: "release://github.com/acme/gateway/v1.0.0/crds-*.yaml", wantVersion: "v1.0.0"},
+		{name: "no matching asset", url: "release://github.com/acme/gateway/latest/*.tar.gz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkg, err := Release{URL: u, PackageName: "gateway", Token: "secret"}.GetContent()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			gp := pkg.(GitHubPackage)
+			defer os.Remove(gp.filePath)
+			if gp.GetVersion() != tt.wantVersion {
+				t.Errorf("got version %s, want %s", gp.GetVersion(), tt.wantVersion)
+			}
+			manifest, err := os.ReadFile(gp.filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(manifest), "gateways.example.com") {
+				t.Errorf("expected the manifest of the asset, got %s", manifest)
+			}
+		})
+	}
+
+	for _, auth := range apiAuthorization {
+		if auth != "Bearer secret" {
+			t.Errorf("expected the token to be sent to the GitHub API, got %q", auth)
+		}
+	}
+	if len(assetAuthorization) != 1 || assetAuthorization[0] != "" {
+		t.Errorf("expected the token not to be sent to the other hosts, got %q", assetAuthorization)
+	}
+}
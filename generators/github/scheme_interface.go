@@ -11,18 +11,36 @@ type DownloaderScheme interface {
 }
 
 func NewDownloaderForScheme(scheme string, url *url.URL, packageName string) DownloaderScheme {
+	return newDownloaderForScheme(scheme, url, GitHubPackageManager{PackageName: packageName})
+}
+
+func newDownloaderForScheme(scheme string, url *url.URL, ghpm GitHubPackageManager) DownloaderScheme {
 	switch scheme {
 	case "git":
 		return GitRepo{
 			URL:         url,
-			PackageName: packageName,
+			PackageName: ghpm.PackageName,
+			Token:       ghpm.Token,
+			HTTPClient:  ghpm.HTTPClient,
+			CloneURL:    ghpm.CloneURL,
+		}
+	case "release":
+		return Release{
+			URL:         url,
+			PackageName: ghpm.PackageName,
+			Token:       ghpm.Token,
+			HTTPClient:  ghpm.HTTPClient,
+			Store:       ghpm.Store,
 		}
 	case "http":
 		fallthrough
 	case "https":
 		return URL{
 			URL:         url,
-			PackageName: packageName,
+			PackageName: ghpm.PackageName,
+			Token:       ghpm.Token,
+			HTTPClient:  ghpm.HTTPClient,
+			Store:       ghpm.Store,
 		}
 	}
 	return nil
@@ -2,7 +2,9 @@ package github
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"net/http"
 
 	"net/url"
 	"os"
@@ -11,15 +13,22 @@ import (
 
 	"github.com/layer5io/meshkit/generators/models"
 	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/cas"
 	"github.com/layer5io/meshkit/utils/helm"
 )
 
 type URL struct {
 	URL         *url.URL
 	PackageName string
+	Token       string
+	// HTTPClient downloads the file, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// Store, if set, caches the file by content, see cas.Store
+	Store *cas.Store
 }
 
 // < http/https://url/version>
+// < http/https://url/archive.tar.gz//path inside the archive/version>
 //close the descriptors
 
 func (u URL) GetContent() (models.Package, error) {
@@ -29,11 +38,17 @@ func (u URL) GetContent() (models.Package, error) {
 	url := u.URL.String()
 	version := url[strings.LastIndex(url, "/")+1:]
 	url, _ = strings.CutSuffix(url, "/"+version)
+	url, subPath := splitSubPath(url)
 
 	fileName := utils.GetRandomAlphabetsOfDigit(6)
 	downloadfilePath := filepath.Join(downloadDirPath, fileName)
 
-	err := utils.DownloadFile(downloadfilePath, url)
+	// The token is never sent to hosts other than GitHub.
+	token := ""
+	if isGitHubHost(u.URL.Hostname()) {
+		token = u.Token
+	}
+	err := storedDownloadFile(u.Store, u.HTTPClient, downloadfilePath, url, token, "")
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +64,7 @@ func (u URL) GetContent() (models.Package, error) {
 		_ = w.Flush()
 	}()
 
-	err = ProcessContent(w, downloadDirPath, downloadfilePath)
+	err = processContent(w, downloadDirPath, downloadfilePath, subPath)
 	if err != nil {
 		return nil, err
 	}
@@ -62,6 +77,11 @@ func (u URL) GetContent() (models.Package, error) {
 }
 
 func ProcessContent(w io.Writer, downloadDirPath, downloadfilePath string) error {
+	return processContent(w, downloadDirPath, downloadfilePath, "")
+}
+
+// processContent restricts the processing to the subPath inside the extracted archive, when provided.
+func processContent(w io.Writer, downloadDirPath, downloadfilePath, subPath string) error {
 	var err error
 	if utils.IsTarGz(downloadfilePath) {
 		err = utils.ExtractTarGz(downloadDirPath, downloadfilePath)
@@ -76,6 +96,12 @@ func ProcessContent(w io.Writer, downloadDirPath, downloadfilePath string) error
 		return err
 	}
 
+	if subPath != "" {
+		if downloadDirPath, err = confinedSubPath(downloadDirPath, subPath); err != nil {
+			return ErrInvalidGitHubSourceURL(err)
+		}
+	}
+
 	err = utils.ProcessContent(downloadDirPath, func(path string) error {
 		err = helm.ConvertToK8sManifest(path, w)
 		if err != nil {
@@ -89,3 +115,21 @@ func ProcessContent(w io.Writer, downloadDirPath, downloadfilePath string) error
 	}
 	return nil
 }
+
+// confinedSubPath joins the slash separated subPath to dir, it fails if the result is outside dir, e.g. for ../.
+func confinedSubPath(dir, subPath string) (string, error) {
+	dir = filepath.Clean(dir)
+	p := filepath.Join(dir, filepath.FromSlash(subPath))
+	if p != dir && !strings.HasPrefix(p, dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("the path %s is outside the downloaded content", subPath)
+	}
+	return p, nil
+}
+
+// isGitHubHost reports whether the host is one of GitHub, including the host of GitHubAPIEndpoint.
+func isGitHubHost(host string) bool {
+	if endpoint, err := url.Parse(GitHubAPIEndpoint); err == nil && host == endpoint.Hostname() {
+		return true
+	}
+	return host == "github.com" || strings.HasSuffix(host, ".github.com") || strings.HasSuffix(host, ".githubusercontent.com")
+}
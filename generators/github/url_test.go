@@ -0,0 +1,29 @@
+package github
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcessContentConfinesSubPath(t *testing.T) {
+	dir := t.TempDir()
+	downloadDir := filepath.Join(dir, "download")
+	if err := os.MkdirAll(filepath.Join(downloadDir, "manifests"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the file is in the directory the download directory is in, not in the download
+	if err := os.WriteFile(filepath.Join(dir, "secret.yaml"), []byte("kind: Secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, subPath := range []string{"../secret.yaml", "manifests/../../secret.yaml", "../"} {
+		if err := processContent(io.Discard, downloadDir, filepath.Join(downloadDir, "missing"), subPath); err == nil {
+			t.Errorf("expected the sub path %s to be rejected", subPath)
+		}
+	}
+	if _, err := confinedSubPath(downloadDir, "manifests/../manifests"); err != nil {
+		t.Errorf("expected a sub path inside the download to be accepted: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrParseStylingProfileCode    = "meshkit-11261"
+	ErrEnrichModelMetadataCode    = "meshkit-11321"
+	ErrParseMetadataOverridesCode = "meshkit-11322"
+	ErrParseMirrorConfigCode      = "meshkit-11345"
+	ErrUnmirroredFetchCode        = "meshkit-11346"
+)
+
+func ErrParseStylingProfile(err error) error {
+	return errors.New(ErrParseStylingProfileCode, errors.Alert, []string{"Unable to parse the styling profile"}, []string{err.Error()}, []string{"The styling profile is not a valid YAML or JSON document"}, []string{"Make sure the styling profile only contains the default, categories and kinds sections, each with valid style fields"})
+}
+
+func ErrEnrichModelMetadata(err error, model string) error {
+	return errors.New(ErrEnrichModelMetadataCode, errors.Alert, []string{fmt.Sprintf("Unable to get the metadata of the model %s from some of the sources", model)}, []string{err.Error()}, []string{"A source is not reachable", "The API rate limit of a source was exceeded"}, []string{"The metadata of the other sources was applied, retry or provide a token for the failing sources"})
+}
+
+func ErrParseMetadataOverrides(err error, path string) error {
+	return errors.New(ErrParseMetadataOverridesCode, errors.Alert, []string{fmt.Sprintf("Unable to parse the metadata overrides %s", path)}, []string{err.Error()}, []string{"The overrides file is not a valid YAML or JSON document"}, []string{"Make sure the overrides file maps the names of the models to their description, category, subCategory, docsURL and maturity"})
+}
+
+func ErrParseMirrorConfig(err error, path string) error {
+	return errors.New(ErrParseMirrorConfigCode, errors.Alert, []string{fmt.Sprintf("Unable to parse the mirror configuration %s", path)}, []string{err.Error()}, []string{"The mirror configuration is not a valid YAML or JSON document"}, []string{"Make sure the mirror configuration lists the mirrors with their upstream and local locations"})
+}
+
+func ErrUnmirroredFetch(url string) error {
+	return errors.New(ErrUnmirroredFetchCode, errors.Alert, []string{fmt.Sprintf("Unable to fetch %s, it is not mirrored", url)}, []string{"The mirrors are strict and no mirror matches the URL, or the URL is mirrored by a directory"}, []string{"The source of a model, e.g. a chart or a dependency of a chart, is missing from the mirror configuration"}, []string{"Add a mirror of the URL to the mirror configuration, or disable the strict mode to fetch it from upstream"})
+}
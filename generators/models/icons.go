@@ -0,0 +1,141 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils"
+)
+
+// IconProcessor ingests the icons of generated components: icons given as URLs are fetched and inlined,
+// SVGs are sanitized, missing white variants are derived from the colored icons,
+// and components without a usable icon get an icon showing the initial letter of their model.
+// Icons fetched are cached, as the components of a model usually share the icon of the model.
+type IconProcessor struct {
+	// Fetch returns the contents of the icon at the URL, it is fetched with HTTPClient if nil.
+	Fetch func(url string) (string, error)
+	// HTTPClient fetches the icons if Fetch is nil, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// FallbackColor is the background of the generated initial-letter icons, the primary color of the component if empty.
+	FallbackColor string
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// Process processes the icons in the metadata of the component and of its model.
+func (p *IconProcessor) Process(comp *v1beta1.ComponentDefinition) {
+	if comp.Metadata == nil {
+		comp.Metadata = make(map[string]interface{})
+	}
+	name := comp.Model.DisplayName
+	if name == "" {
+		name = comp.Model.Name
+	}
+	if name == "" {
+		name = comp.DisplayName
+	}
+	p.processMetadata(comp.Metadata, name)
+	if comp.Model.Metadata != nil {
+		p.processMetadata(comp.Model.Metadata, name)
+	}
+}
+
+func (p *IconProcessor) processMetadata(metadata map[string]interface{}, name string) {
+	color := p.icon(metadata["svgColor"])
+	white := p.icon(metadata["svgWhite"])
+	if color == "" {
+		fallback := p.FallbackColor
+		if fallback == "" {
+			fallback, _ = metadata["primaryColor"].(string)
+		}
+		color = utils.InitialLetterSVG(name, fallback)
+	}
+	if white == "" {
+		white, _ = utils.WhiteSVG(color)
+	}
+	metadata["svgColor"] = color
+	if white != "" {
+		metadata["svgWhite"] = white
+	}
+}
+
+// icon returns the sanitized SVG of the icon, fetching it if it is a URL. Empty if the icon is missing or broken.
+func (p *IconProcessor) icon(value interface{}) string {
+	icon, _ := value.(string)
+	icon = strings.TrimSpace(icon)
+	if strings.HasPrefix(icon, "http://") || strings.HasPrefix(icon, "https://") {
+		icon = p.fetch(icon)
+	}
+	if icon == "" {
+		return ""
+	}
+	sanitized, err := utils.SanitizeSVG(icon)
+	if err != nil {
+		return ""
+	}
+	return sanitized
+}
+
+// fetch returns the icon at the URL, from the cache if it was fetched. The lock is not held while fetching, so that
+// the icons at other URLs are fetched meanwhile, an icon fetched concurrently is only cached once.
+func (p *IconProcessor) fetch(url string) string {
+	p.mu.Lock()
+	icon, ok := p.cache[url]
+	p.mu.Unlock()
+	if ok {
+		return icon
+	}
+	var err error
+	switch {
+	case p.Fetch != nil:
+		icon, err = p.Fetch(url)
+	case p.HTTPClient != nil:
+		icon, err = utils.ReadRemoteFileWithClient(p.HTTPClient, url)
+	default:
+		icon, err = utils.ReadRemoteFile(url)
+	}
+	if err != nil {
+		icon = ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cached, ok := p.cache[url]; ok {
+		return cached
+	}
+	if p.cache == nil {
+		p.cache = make(map[string]string)
+	}
+	p.cache[url] = icon
+	return icon
+}
+
+// IconPackage wraps a Package and processes the icons of every component it generates.
+type IconPackage struct {
+	Package
+	Processor *IconProcessor
+}
+
+func (ip IconPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error) {
+	comps, err := ip.Package.GenerateComponents()
+	for i := range comps {
+		ip.Processor.Process(&comps[i])
+	}
+	return comps, err
+}
+
+// IconPackageManager wraps a PackageManager so that the packages it returns process the icons of their components.
+type IconPackageManager struct {
+	PackageManager
+	Processor *IconProcessor
+}
+
+func (ipm IconPackageManager) GetPackage() (Package, error) {
+	pkg, err := ipm.PackageManager.GetPackage()
+	if err != nil {
+		return nil, err
+	}
+	return IconPackage{Package: pkg, Processor: ipm.Processor}, nil
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestIconProcessor(t *testing.T) {
+	fetched := 0
+	p := &IconProcessor{
+		Fetch: func(url string) (string, error) {
+			fetched++
+			switch url {
+			case "https://example.com/logo.svg":
+				return `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script><path fill="#326CE5"/></svg>`, nil
+			case "https://example.com/404.svg":
+				return "<html>not found</html>", nil
+			}
+			return "", fmt.Errorf("unreachable")
+		},
+	}
+
+	fromURL := v1beta1.ComponentDefinition{
+		Model:    v1beta1.Model{Name: "kubernetes"},
+		Metadata: map[string]interface{}{"svgColor": "https://example.com/logo.svg"},
+	}
+	p.Process(&fromURL)
+	color, _ := fromURL.Metadata["svgColor"].(string)
+	white, _ := fromURL.Metadata["svgWhite"].(string)
+	if !strings.Contains(color, `fill="#326CE5"`) || strings.Contains(color, "script") {
+		t.Errorf("expected the fetched icon sanitized, got %s", color)
+	}
+	if !strings.Contains(white, `fill="#FFFFFF"`) || strings.Contains(white, "326CE5") {
+		t.Errorf("expected a white variant, got %s", white)
+	}
+
+	again := v1beta1.ComponentDefinition{Metadata: map[string]interface{}{"svgColor": "https://example.com/logo.svg"}}
+	p.Process(&again)
+	if fetched != 1 {
+		t.Errorf("expected the icon to be fetched once, got %d", fetched)
+	}
+
+	for _, icon := range []string{"https://example.com/404.svg", "https://example.com/unreachable.svg", "<svg><path>", ""} {
+		broken := v1beta1.ComponentDefinition{
+			Model:    v1beta1.Model{Name: "cert-manager", DisplayName: "Cert Manager"},
+			Metadata: map[string]interface{}{"svgColor": icon, "primaryColor": "#326CE5"},
+		}
+		p.Process(&broken)
+		color, _ := broken.Metadata["svgColor"].(string)
+		if !strings.Contains(color, ">C</text>") || !strings.Contains(color, `fill="#326CE5"`) {
+			t.Errorf("icon %q: expected the initial-letter fallback, got %s", icon, color)
+		}
+		if _, ok := broken.Metadata["svgWhite"].(string); !ok {
+			t.Errorf("icon %q: expected a white variant of the fallback", icon)
+		}
+	}
+
+	ownWhite := v1beta1.ComponentDefinition{
+		Metadata: map[string]interface{}{
+			"svgColor": `<svg xmlns="http://www.w3.org/2000/svg"><path fill="red"/></svg>`,
+			"svgWhite": `<svg xmlns="http://www.w3.org/2000/svg"><path fill="white" onclick="x()"/></svg>`,
+		},
+	}
+	p.Process(&ownWhite)
+	if got := ownWhite.Metadata["svgWhite"]; got != `<svg xmlns="http://www.w3.org/2000/svg"><path fill="white"></path></svg>` {
+		t.Errorf("expected the white icon to be sanitized only, got %s", got)
+	}
+}
+
+func TestIconProcessorFetchesConcurrently(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	p := &IconProcessor{
+		Fetch: func(url string) (string, error) {
+			if url == "https://example.com/slow.svg" {
+				close(started)
+				<-release
+			}
+			return `<svg xmlns="http://www.w3.org/2000/svg"><path fill="#326CE5"/></svg>`, nil
+		},
+	}
+	slow := make(chan struct{})
+	go func() {
+		defer close(slow)
+		p.Process(&v1beta1.ComponentDefinition{Metadata: map[string]interface{}{"svgColor": "https://example.com/slow.svg"}})
+	}()
+	<-started
+
+	fast := make(chan struct{})
+	go func() {
+		defer close(fast)
+		p.Process(&v1beta1.ComponentDefinition{Metadata: map[string]interface{}{"svgColor": "https://example.com/fast.svg"}})
+	}()
+	select {
+	case <-fast:
+	case <-time.After(5 * time.Second):
+		t.Error("expected an icon to be fetched while another one is fetched")
+	}
+	close(release)
+	<-slow
+	<-fast
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of the model metadata set by the MetadataEnricher.
+const (
+	DocsURLMetadataKey  = "docsURL"
+	MaturityMetadataKey = "maturity"
+	// EnrichedFromMetadataKey is the key of the names of the sources of the enriched fields, by field.
+	EnrichedFromMetadataKey = "enrichedFrom"
+)
+
+// ModelMetadata is the metadata of a model provided by a MetadataSource. Empty fields are left to the other sources.
+type ModelMetadata struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Category    string `json:"category,omitempty" yaml:"category,omitempty"`
+	SubCategory string `json:"subCategory,omitempty" yaml:"subCategory,omitempty"`
+	DocsURL     string `json:"docsURL,omitempty" yaml:"docsURL,omitempty"`
+	// Maturity of the project, e.g. stable, deprecated or archived
+	Maturity string `json:"maturity,omitempty" yaml:"maturity,omitempty"`
+}
+
+// MetadataSource provides the metadata of models, e.g. Artifact Hub, the topics of GitHub repositories or an overrides file.
+type MetadataSource interface {
+	Name() string
+	// ModelMetadata returns the metadata of the model, empty if the source doesn't know the model.
+	ModelMetadata(model v1beta1.Model) (ModelMetadata, error)
+}
+
+// MetadataEnricher merges the metadata of the models of generated components from several sources, so that the
+// generated models need less hand-editing. The metadata is cached by model, as the components of a model share it.
+type MetadataEnricher struct {
+	// Sources in decreasing precedence, e.g. the overrides file, Artifact Hub, then GitHub. A field is set from the first
+	// source providing it, the values of the generated model having the lowest precedence.
+	Sources []MetadataSource
+
+	mu    sync.Mutex
+	cache map[string]enrichedMetadata
+}
+
+type enrichedMetadata struct {
+	metadata ModelMetadata
+	from     map[string]string
+	err      error
+}
+
+// Enrich sets the description, category, sub category, documentation URL and maturity of the model from the sources.
+// The fields are set even if some of the sources fail, their errors are combined in the error returned. The names of
+// the sources of the fields are recorded in the metadata of the model under EnrichedFromMetadataKey.
+func (e *MetadataEnricher) Enrich(model *v1beta1.Model) error {
+	enriched := e.get(*model)
+	set := func(value string, dst *string) {
+		if value != "" {
+			*dst = value
+		}
+	}
+	m := enriched.metadata
+	set(m.Description, &model.Description)
+	set(m.Category, &model.Category.Name)
+	set(m.SubCategory, &model.SubCategory)
+	if m.DocsURL != "" || m.Maturity != "" || len(enriched.from) > 0 {
+		if model.Metadata == nil {
+			model.Metadata = make(map[string]interface{})
+		}
+	}
+	if m.DocsURL != "" {
+		model.Metadata[DocsURLMetadataKey] = m.DocsURL
+	}
+	if m.Maturity != "" {
+		model.Metadata[MaturityMetadataKey] = m.Maturity
+	}
+	if len(enriched.from) > 0 {
+		from := make(map[string]interface{}, len(enriched.from))
+		for field, source := range enriched.from {
+			from[field] = source
+		}
+		model.Metadata[EnrichedFromMetadataKey] = from
+	}
+	return enriched.err
+}
+
+// get returns the metadata of the model merged from the sources, from the cache if it was merged. The lock is not held
+// while querying the sources, so that other models are enriched meanwhile.
+func (e *MetadataEnricher) get(model v1beta1.Model) enrichedMetadata {
+	key := model.Name + "@" + model.Model.Version
+	e.mu.Lock()
+	cached, ok := e.cache[key]
+	e.mu.Unlock()
+	if ok {
+		return cached
+	}
+	enriched := enrichedMetadata{from: map[string]string{}}
+	var errs []error
+	for _, source := range e.Sources {
+		m, err := source.ModelMetadata(model)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+		merge := func(field, value string, dst *string) {
+			if value != "" && *dst == "" {
+				*dst = value
+				enriched.from[field] = source.Name()
+			}
+		}
+		merge("description", m.Description, &enriched.metadata.Description)
+		merge("category", m.Category, &enriched.metadata.Category)
+		merge("subCategory", m.SubCategory, &enriched.metadata.SubCategory)
+		merge(DocsURLMetadataKey, m.DocsURL, &enriched.metadata.DocsURL)
+		merge(MaturityMetadataKey, m.Maturity, &enriched.metadata.Maturity)
+	}
+	if len(errs) > 0 {
+		enriched.err = ErrEnrichModelMetadata(utils.CombineErrors(errs, "\n"), model.Name)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if cached, ok := e.cache[key]; ok {
+		return cached
+	}
+	if e.cache == nil {
+		e.cache = make(map[string]enrichedMetadata)
+	}
+	e.cache[key] = enriched
+	return enriched
+}
+
+// MetadataOverrides is the metadata of models by model name, maintained by hand. It has the highest precedence.
+type MetadataOverrides map[string]ModelMetadata
+
+// LoadMetadataOverrides reads the overrides from a YAML or JSON file mapping the names of the models to their metadata, e.g.
+//
+//	istio-base:
+//	  category: Cloud Native Network
+//	  subCategory: Service Mesh
+//	  docsURL: https://istio.io/latest/docs
+func LoadMetadataOverrides(path string) (MetadataOverrides, error) {
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.ErrReadFile(err, path)
+	}
+	overrides := MetadataOverrides{}
+	if err := yaml.Unmarshal(byt, &overrides); err != nil {
+		return nil, ErrParseMetadataOverrides(err, path)
+	}
+	return overrides, nil
+}
+
+func (o MetadataOverrides) Name() string {
+	return "overrides"
+}
+
+func (o MetadataOverrides) ModelMetadata(model v1beta1.Model) (ModelMetadata, error) {
+	return o[model.Name], nil
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+type testMetadataSource struct {
+	name     string
+	metadata ModelMetadata
+	err      error
+	calls    int
+}
+
+func (s *testMetadataSource) Name() string { return s.name }
+
+func (s *testMetadataSource) ModelMetadata(model v1beta1.Model) (ModelMetadata, error) {
+	s.calls++
+	return s.metadata, s.err
+}
+
+func TestMetadataEnricher(t *testing.T) {
+	overridesFile := filepath.Join(t.TempDir(), "overrides.yaml")
+	if err := os.WriteFile(overridesFile, []byte("istio-base:\n  category: Cloud Native Network\n  subCategory: Service Mesh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := LoadMetadataOverrides(overridesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifactHub := &testMetadataSource{name: "artifacthub", metadata: ModelMetadata{Description: "Istio base chart", Category: "Tools", DocsURL: "https://istio.io"}}
+	gitHub := &testMetadataSource{name: "github", metadata: ModelMetadata{Description: "Connect, secure, control, and observe services.", Maturity: "stable"}}
+	failing := &testMetadataSource{name: "failing", err: fmt.Errorf("rate limited")}
+	enricher := &MetadataEnricher{Sources: []MetadataSource{overrides, artifactHub, failing, gitHub}}
+
+	model := v1beta1.Model{Name: "istio-base", Description: "generated"}
+	if err := enricher.Enrich(&model); err == nil {
+		t.Error("expected the error of the failing source")
+	}
+	if model.Category.Name != "Cloud Native Network" || model.SubCategory != "Service Mesh" || model.Description != "Istio base chart" {
+		t.Errorf("unexpected precedence: %+v", model)
+	}
+	if model.Metadata[DocsURLMetadataKey] != "https://istio.io" || model.Metadata[MaturityMetadataKey] != "stable" {
+		t.Errorf("unexpected metadata %v", model.Metadata)
+	}
+	from, _ := model.Metadata[EnrichedFromMetadataKey].(map[string]interface{})
+	if from["category"] != "overrides" || from["description"] != "artifacthub" || from[MaturityMetadataKey] != "github" {
+		t.Errorf("unexpected sources %v", from)
+	}
+
+	other := v1beta1.Model{Name: "istio-base"}
+	_ = enricher.Enrich(&other)
+	if artifactHub.calls != 1 || other.Description != "Istio base chart" {
+		t.Errorf("expected the metadata to be cached, got %d calls", artifactHub.calls)
+	}
+
+	if _, err := LoadMetadataOverrides(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing overrides file")
+	}
+}
+
+type blockingMetadataSource struct {
+	started, release chan struct{}
+}
+
+func (s *blockingMetadataSource) Name() string { return "blocking" }
+
+func (s *blockingMetadataSource) ModelMetadata(model v1beta1.Model) (ModelMetadata, error) {
+	if model.Name == "slow" {
+		close(s.started)
+		<-s.release
+	}
+	return ModelMetadata{Description: model.Name}, nil
+}
+
+func TestMetadataEnricherQueriesConcurrently(t *testing.T) {
+	source := &blockingMetadataSource{started: make(chan struct{}), release: make(chan struct{})}
+	enricher := &MetadataEnricher{Sources: []MetadataSource{source}}
+	slow := make(chan struct{})
+	go func() {
+		defer close(slow)
+		_ = enricher.Enrich(&v1beta1.Model{Name: "slow"})
+	}()
+	<-source.started
+
+	fast := make(chan struct{})
+	go func() {
+		defer close(fast)
+		_ = enricher.Enrich(&v1beta1.Model{Name: "fast"})
+	}()
+	select {
+	case <-fast:
+	case <-time.After(5 * time.Second):
+		t.Error("expected a model to be enriched while another one is")
+	}
+	close(source.release)
+	<-slow
+	<-fast
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Mirror maps the upstream URLs starting with Upstream to Local, a local directory or file, or the URL of a mirror,
+// e.g. of a local chart repository. The rest of the upstream URL is appended to the local directory or URL, e.g.
+//
+//	upstream: https://charts.jetstack.io/
+//	local: /mirrors/charts/jetstack
+//
+// serves https://charts.jetstack.io/charts/cert-manager-v1.14.0.tgz from /mirrors/charts/jetstack/charts/cert-manager-v1.14.0.tgz.
+type Mirror struct {
+	Upstream string `json:"upstream" yaml:"upstream"`
+	Local    string `json:"local" yaml:"local"`
+}
+
+// MirrorConfig configures the mirrors of the sources of the generators, so that the components can be generated
+// without internet access. The longest matching upstream of the mirrors is used.
+type MirrorConfig struct {
+	Mirrors []Mirror `json:"mirrors" yaml:"mirrors"`
+	// Strict fails the fetches of URLs which are not mirrored, instead of fetching them from upstream, to make sure
+	// that the generation doesn't depend on the network.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+}
+
+// LoadMirrorConfig reads the mirror configuration from a YAML or JSON file, e.g.
+//
+//	strict: true
+//	mirrors:
+//	  - upstream: https://charts.jetstack.io/
+//	    local: /mirrors/charts/jetstack
+//	  - upstream: https://raw.githubusercontent.com/
+//	    local: http://mirror.local/github/
+func LoadMirrorConfig(path string) (*MirrorConfig, error) {
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.ErrReadFile(err, path)
+	}
+	config := &MirrorConfig{}
+	if err := yaml.Unmarshal(byt, config); err != nil {
+		return nil, ErrParseMirrorConfig(err, path)
+	}
+	return config, nil
+}
+
+// Resolve returns the location the URL is fetched from: a local path, a mirror URL, or the URL itself if it is not
+// mirrored. An error is returned for URLs which are not mirrored in strict mode. The rest of a mirrored URL is
+// cleaned, so that the location is within the mirror, e.g. ../ segments don't leave its directory.
+func (c *MirrorConfig) Resolve(url string) (string, error) {
+	mirrors := append([]Mirror{}, c.Mirrors...)
+	sort.SliceStable(mirrors, func(i, j int) bool { return len(mirrors[i].Upstream) > len(mirrors[j].Upstream) })
+	for _, m := range mirrors {
+		if m.Upstream == "" || !strings.HasPrefix(url, m.Upstream) {
+			continue
+		}
+		rest := strings.TrimPrefix(url, m.Upstream)
+		if isURL(m.Local) {
+			return joinURL(m.Local, confine(rest)), nil
+		}
+		local := filepath.FromSlash(strings.TrimPrefix(m.Local, "file://"))
+		if rest == "" {
+			return local, nil
+		}
+		return filepath.Join(local, filepath.FromSlash(confine(strings.SplitN(rest, "?", 2)[0]))), nil
+	}
+	if c.Strict && !c.isMirrorURL(url) {
+		return "", ErrUnmirroredFetch(url)
+	}
+	return url, nil
+}
+
+// isMirrorURL reports whether the URL is of a mirror, which is fetched in strict mode.
+func (c *MirrorConfig) isMirrorURL(url string) bool {
+	for _, m := range c.Mirrors {
+		if isURL(m.Local) && strings.HasPrefix(url, m.Local) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundTripper returns a RoundTripper serving the requests of mirrored URLs from the mirrors, the other requests
+// being sent by the base RoundTripper, http.DefaultTransport if nil, unless the mirrors are strict.
+func (c *MirrorConfig) RoundTripper(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &mirrorTransport{config: c, base: base}
+}
+
+// Client returns an HTTP client fetching through the mirrors, see RoundTripper. The generators fetching charts, CRDs
+// and GitHub or Artifact Hub content are given the client, and clone the git repositories from the locations Resolve
+// returns, e.g. https://github.com/ mirrored by http://git.local/github/ or by a directory of repositories.
+func (c *MirrorConfig) Client() *http.Client {
+	return &http.Client{Transport: c.RoundTripper(nil)}
+}
+
+type mirrorTransport struct {
+	config *MirrorConfig
+	base   http.RoundTripper
+}
+
+func (t *mirrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	location, err := t.config.Resolve(url)
+	if err != nil {
+		return nil, err
+	}
+	if location == url {
+		return t.base.RoundTrip(req)
+	}
+	if isURL(location) {
+		mirrored := req.Clone(req.Context())
+		u, err := req.URL.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+		mirrored.URL, mirrored.Host = u, u.Host
+		return t.base.RoundTrip(mirrored)
+	}
+
+	resp := &http.Response{Proto: "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1, Header: http.Header{}, Request: req}
+	f, err := os.Open(location)
+	if os.IsNotExist(err) {
+		resp.StatusCode, resp.Status = http.StatusNotFound, "404 Not Found"
+		resp.Body = io.NopCloser(strings.NewReader(""))
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.IsDir() {
+		err = ErrUnmirroredFetch(url)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	resp.StatusCode, resp.Status = http.StatusOK, "200 OK"
+	resp.ContentLength = info.Size()
+	resp.Body = f
+	return resp, nil
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// confine cleans the path, its ../ segments not going above its root
+func confine(p string) string {
+	if p == "" {
+		return ""
+	}
+	query := ""
+	if i := strings.Index(p, "?"); i >= 0 {
+		p, query = p[:i], p[i:]
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if strings.HasSuffix(p, "/") && cleaned != "" {
+		cleaned += "/"
+	}
+	return cleaned + query
+}
+
+func joinURL(base, rest string) string {
+	if rest == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(rest, "/")
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshkit/utils"
+)
+
+func TestMirrorConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "charts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "charts", "index.yaml"), []byte("apiVersion: v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "mirrored %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	config := &MirrorConfig{Strict: true, Mirrors: []Mirror{
+		{Upstream: "https://charts.example.com/", Local: dir},
+		{Upstream: "https://charts.example.com/stable/", Local: server.URL + "/stable-mirror/"},
+		{Upstream: "https://raw.githubusercontent.com/", Local: "file://" + filepath.ToSlash(dir)},
+	}}
+	client := config.Client()
+
+	content, err := utils.ReadRemoteFileWithClient(client, "https://charts.example.com/charts/index.yaml")
+	if err != nil || content != "apiVersion: v1" {
+		t.Errorf("read %q, %v from the local mirror", content, err)
+	}
+	if content, err = utils.ReadRemoteFileWithClient(client, "https://charts.example.com/stable/cert-manager.tgz"); err != nil || content != "mirrored /stable-mirror/cert-manager.tgz" {
+		t.Errorf("read %q, %v from the mirror URL", content, err)
+	}
+	if _, err = utils.ReadRemoteFileWithClient(client, "https://raw.githubusercontent.com/missing.yaml"); err == nil {
+		t.Error("expected an error reading a file missing from the mirror")
+	}
+	if _, err = utils.ReadRemoteFileWithClient(client, "https://artifacthub.io/api/v1/packages"); err == nil {
+		t.Error("expected an error fetching an unmirrored URL in strict mode")
+	}
+	if _, err = utils.ReadRemoteFileWithClient(client, server.URL+"/stable-mirror/direct"); err != nil {
+		t.Errorf("the mirror itself is not reachable in strict mode: %v", err)
+	}
+	if http.DefaultClient.Transport != nil {
+		t.Error("expected the default client to be left as is")
+	}
+
+	if location, err := config.Resolve("https://charts.example.com/charts/index.yaml?raw"); err != nil || location != filepath.Join(dir, "charts", "index.yaml") {
+		t.Errorf("resolved %q, %v", location, err)
+	}
+	if location, err := config.Resolve("https://charts.example.com/../../etc/passwd"); err != nil || location != filepath.Join(dir, "etc", "passwd") {
+		t.Errorf("expected the location to be confined to the mirror, got %q, %v", location, err)
+	}
+	if location, err := config.Resolve("https://charts.example.com/stable/../../secret?raw"); err != nil || location != server.URL+"/stable-mirror/secret?raw" {
+		t.Errorf("expected the URL to be confined to the mirror, got %q, %v", location, err)
+	}
+	config.Strict = false
+	if location, err := config.Resolve("https://artifacthub.io/api/v1/packages"); err != nil || location != "https://artifacthub.io/api/v1/packages" {
+		t.Errorf("unmirrored URL resolved to %q, %v", location, err)
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+)
+
+// Style holds the visual attributes of a component, as stored in its metadata.
+// Empty fields are left untouched when the style is applied.
+type Style struct {
+	SVGColor       string `json:"svgColor,omitempty" yaml:"svgColor,omitempty"`
+	SVGWhite       string `json:"svgWhite,omitempty" yaml:"svgWhite,omitempty"`
+	Shape          string `json:"shape,omitempty" yaml:"shape,omitempty"`
+	PrimaryColor   string `json:"primaryColor,omitempty" yaml:"primaryColor,omitempty"`
+	SecondaryColor string `json:"secondaryColor,omitempty" yaml:"secondaryColor,omitempty"`
+}
+
+// merge returns s with the non empty fields of override applied on top.
+func (s Style) merge(override Style) Style {
+	if override.SVGColor != "" {
+		s.SVGColor = override.SVGColor
+	}
+	if override.SVGWhite != "" {
+		s.SVGWhite = override.SVGWhite
+	}
+	if override.Shape != "" {
+		s.Shape = override.Shape
+	}
+	if override.PrimaryColor != "" {
+		s.PrimaryColor = override.PrimaryColor
+	}
+	if override.SecondaryColor != "" {
+		s.SecondaryColor = override.SecondaryColor
+	}
+	return s
+}
+
+func (s Style) applyTo(metadata map[string]interface{}) {
+	fields := map[string]string{
+		"svgColor":       s.SVGColor,
+		"svgWhite":       s.SVGWhite,
+		"shape":          s.Shape,
+		"primaryColor":   s.PrimaryColor,
+		"secondaryColor": s.SecondaryColor,
+	}
+	for key, value := range fields {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+}
+
+// StylingProfile describes how generated components should look.
+// Styles are resolved in the order Default, Categories (keyed by category name) and Kinds,
+// each one overriding the fields set by the previous ones.
+// Kinds are keyed either by "<model>/<kind>" or by "<kind>", the former taking precedence.
+type StylingProfile struct {
+	Default    Style            `json:"default,omitempty" yaml:"default,omitempty"`
+	Categories map[string]Style `json:"categories,omitempty" yaml:"categories,omitempty"`
+	Kinds      map[string]Style `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+}
+
+// ParseStylingProfile parses a YAML or JSON encoded styling profile.
+func ParseStylingProfile(data []byte) (StylingProfile, error) {
+	var profile StylingProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return profile, ErrParseStylingProfile(err)
+	}
+	return profile, nil
+}
+
+// StyleFor resolves the style of the component.
+func (p StylingProfile) StyleFor(comp v1beta1.ComponentDefinition) Style {
+	style := p.Default.merge(p.Categories[comp.Model.Category.Name])
+	if kindStyle, ok := p.Kinds[comp.Model.Name+"/"+comp.Component.Kind]; ok {
+		return style.merge(kindStyle)
+	}
+	return style.merge(p.Kinds[comp.Component.Kind])
+}
+
+// Apply sets the resolved style in the metadata of the component.
+// The model metadata receives the category level style, so that the model and its components share the same branding.
+func (p StylingProfile) Apply(comp *v1beta1.ComponentDefinition) {
+	if comp.Metadata == nil {
+		comp.Metadata = make(map[string]interface{})
+	}
+	p.StyleFor(*comp).applyTo(comp.Metadata)
+
+	if comp.Model.Metadata == nil {
+		comp.Model.Metadata = make(map[string]interface{})
+	}
+	p.Default.merge(p.Categories[comp.Model.Category.Name]).applyTo(comp.Model.Metadata)
+}
+
+// StyledPackage wraps a Package and applies the styling profile to every component it generates.
+type StyledPackage struct {
+	Package
+	Profile StylingProfile
+}
+
+func (sp StyledPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error) {
+	comps, err := sp.Package.GenerateComponents()
+	for i := range comps {
+		sp.Profile.Apply(&comps[i])
+	}
+	return comps, err
+}
+
+// StyledPackageManager wraps a PackageManager so that the packages it returns generate styled components.
+type StyledPackageManager struct {
+	PackageManager
+	Profile StylingProfile
+}
+
+func (spm StyledPackageManager) GetPackage() (Package, error) {
+	pkg, err := spm.PackageManager.GetPackage()
+	if err != nil {
+		return nil, err
+	}
+	return StyledPackage{Package: pkg, Profile: spm.Profile}, nil
+}
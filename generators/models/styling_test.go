@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestStylingProfile(t *testing.T) {
+	profile, err := ParseStylingProfile([]byte(`
+default:
+  shape: circle
+  primaryColor: "#00b39f"
+categories:
+  Security:
+    primaryColor: "#ff0000"
+kinds:
+  Certificate:
+    shape: hexagon
+  cert-manager/Issuer:
+    shape: star
+  Issuer:
+    shape: rectangle
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		kind, category string
+		want           Style
+	}{
+		{"Pod", "", Style{Shape: "circle", PrimaryColor: "#00b39f"}},
+		{"Pod", "Security", Style{Shape: "circle", PrimaryColor: "#ff0000"}},
+		{"Certificate", "Security", Style{Shape: "hexagon", PrimaryColor: "#ff0000"}},
+		{"Issuer", "", Style{Shape: "star", PrimaryColor: "#00b39f"}},
+	}
+	for _, tt := range tests {
+		comp := v1beta1.ComponentDefinition{}
+		comp.Component.Kind = tt.kind
+		comp.Model.Name = "cert-manager"
+		comp.Model.Category.Name = tt.category
+		profile.Apply(&comp)
+		if got := profile.StyleFor(comp); got != tt.want {
+			t.Errorf("%s/%s: got style %+v, want %+v", tt.category, tt.kind, got, tt.want)
+		}
+		if comp.Metadata["shape"] != tt.want.Shape || comp.Model.Metadata["primaryColor"] != tt.want.PrimaryColor {
+			t.Errorf("%s/%s: style not applied to metadata", tt.category, tt.kind)
+		}
+	}
+}
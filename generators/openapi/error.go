@@ -0,0 +1,18 @@
+package openapi
+
+import (
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrGetOpenAPIDocumentCode   = "meshkit-11251"
+	ErrParseOpenAPIDocumentCode = "meshkit-11252"
+)
+
+func ErrGetOpenAPIDocument(err error, source string) error {
+	return errors.New(ErrGetOpenAPIDocumentCode, errors.Alert, []string{"Could not get the OpenAPI document from ", source}, []string{err.Error()}, []string{"The source URL is not reachable", "The file does not exist"}, []string{"Make sure the source is a valid http, https or file URL"})
+}
+
+func ErrParseOpenAPIDocument(err error) error {
+	return errors.New(ErrParseOpenAPIDocumentCode, errors.Alert, []string{"Could not parse the OpenAPI document"}, []string{err.Error()}, []string{"The document is neither a valid OpenAPI v2 (swagger) nor v3 document"}, []string{"Make sure the document is valid JSON or YAML and contains either 'definitions' or 'components.schemas'"})
+}
@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/component"
+	"github.com/layer5io/meshkit/utils/manifests"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	gvkExtension = "x-kubernetes-group-version-kind"
+	refKey       = "$ref"
+)
+
+// OpenAPIPackage generates components for the schemas defined in an OpenAPI v2 (swagger) or v3 document.
+//
+// When the document follows the Kubernetes conventions (schemas annotated with x-kubernetes-group-version-kind),
+// only the annotated resource schemas are considered and the kind/apiVersion are taken from the annotation.
+// Otherwise every object schema becomes a component, named after the schema and versioned with the API version from the document info.
+type OpenAPIPackage struct {
+	Name      string `yaml:"name" json:"name"`
+	SourceURL string `yaml:"source_url" json:"source_url"`
+	version   string
+	schemas   map[string]map[string]interface{}
+}
+
+type openAPIDocument struct {
+	Swagger     string                            `yaml:"swagger"`
+	OpenAPI     string                            `yaml:"openapi"`
+	Info        map[string]interface{}            `yaml:"info"`
+	Definitions map[string]map[string]interface{} `yaml:"definitions"`
+	Components  struct {
+		Schemas map[string]map[string]interface{} `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// NewPackage parses the OpenAPI document, which can either be JSON or YAML encoded.
+func NewPackage(name, sourceURL string, doc []byte) (OpenAPIPackage, error) {
+	pkg := OpenAPIPackage{
+		Name:      name,
+		SourceURL: sourceURL,
+	}
+	var parsedDoc openAPIDocument
+	err := yaml.Unmarshal(doc, &parsedDoc)
+	if err != nil {
+		return pkg, ErrParseOpenAPIDocument(err)
+	}
+	switch {
+	case parsedDoc.Swagger != "":
+		pkg.schemas = parsedDoc.Definitions
+	case parsedDoc.OpenAPI != "":
+		pkg.schemas = parsedDoc.Components.Schemas
+	default:
+		return pkg, ErrParseOpenAPIDocument(fmt.Errorf("missing 'swagger' or 'openapi' version field"))
+	}
+	pkg.version, _ = parsedDoc.Info["version"].(string)
+	return pkg, nil
+}
+
+func (pkg OpenAPIPackage) GetVersion() string {
+	return pkg.version
+}
+
+func (pkg OpenAPIPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error) {
+	components := make([]v1beta1.ComponentDefinition, 0)
+	var errs []error
+
+	isKubernetesStyle := false
+	for _, schema := range pkg.schemas {
+		if _, ok := schema[gvkExtension]; ok {
+			isKubernetesStyle = true
+			break
+		}
+	}
+
+	// iterate in a stable order so that the generated components are the same across runs
+	names := make([]string, 0, len(pkg.schemas))
+	for name := range pkg.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		schema := pkg.schemas[name]
+		var kind, apiVersion string
+		if isKubernetesStyle {
+			gvk, ok := getResourceGVK(schema)
+			if !ok {
+				continue
+			}
+			kind = gvk["kind"]
+			apiVersion = gvk["version"]
+			if gvk["group"] != "" {
+				apiVersion = fmt.Sprintf("%s/%s", gvk["group"], gvk["version"])
+			}
+		} else {
+			if schemaType, _ := schema["type"].(string); schemaType != "" && schemaType != "object" {
+				continue
+			}
+			kind = name[strings.LastIndex(name, ".")+1:]
+			apiVersion = pkg.version
+		}
+
+		resolved, err := resolveRefs(schema, pkg.schemas, map[string]bool{name: true})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resolvedSchema := resolved.(map[string]interface{})
+		delete(resolvedSchema, gvkExtension)
+		component.DeleteFields(resolvedSchema)
+		resolvedSchema["title"] = manifests.FormatToReadableString(kind)
+		byt, err := json.MarshalIndent(resolvedSchema, "", " ")
+		if err != nil {
+			errs = append(errs, utils.ErrMarshal(err))
+			continue
+		}
+
+		comp := v1beta1.ComponentDefinition{
+			VersionMeta: v1beta1.VersionMeta{
+				SchemaVersion: v1beta1.SchemaVersion,
+			},
+			DisplayName: manifests.FormatToReadableString(kind),
+			Format:      v1beta1.JSON,
+			Metadata:    make(map[string]interface{}),
+			Component: v1beta1.ComponentEntity{
+				TypeMeta: v1beta1.TypeMeta{
+					Kind:    kind,
+					Version: apiVersion,
+				},
+				Schema: string(byt),
+			},
+		}
+		if description, ok := schema["description"].(string); ok {
+			comp.Description = description
+		}
+		comp.Model.Metadata = map[string]interface{}{
+			"source_uri": pkg.SourceURL,
+		}
+		comp.Model.Version = pkg.version
+		comp.Model.Name = pkg.Name
+		comp.Model.DisplayName = manifests.FormatToReadableString(comp.Model.Name)
+		components = append(components, comp)
+	}
+	return components, utils.CombineErrors(errs, "\n")
+}
+
+// getResourceGVK returns the group/version/kind of schemas which represent a single resource.
+// Schemas with multiple GVKs (eg: DeleteOptions), list types and schemas without object metadata are not resources.
+func getResourceGVK(schema map[string]interface{}) (map[string]string, bool) {
+	gvks, ok := schema[gvkExtension].([]interface{})
+	if !ok || len(gvks) != 1 {
+		return nil, false
+	}
+	gvk, ok := gvks[0].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	kind, _ := gvk["kind"].(string)
+	if kind == "" || strings.HasSuffix(kind, "List") {
+		return nil, false
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	if _, ok := properties["metadata"]; !ok {
+		return nil, false
+	}
+	group, _ := gvk["group"].(string)
+	version, _ := gvk["version"].(string)
+	return map[string]string{
+		"group":   group,
+		"version": version,
+		"kind":    kind,
+	}, true
+}
+
+// resolveRefs inlines the referenced schemas.
+// References which are already being resolved higher up in the tree (recursive schemas) are replaced with a free-form object.
+func resolveRefs(node interface{}, schemas map[string]map[string]interface{}, resolving map[string]bool) (interface{}, error) {
+	switch val := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := val[refKey].(string); ok {
+			name := ref[strings.LastIndex(ref, "/")+1:]
+			if resolving[name] {
+				return map[string]interface{}{"type": "object"}, nil
+			}
+			refSchema, ok := schemas[name]
+			if !ok {
+				return nil, ErrParseOpenAPIDocument(fmt.Errorf("unable to resolve reference %s", ref))
+			}
+			resolving[name] = true
+			defer delete(resolving, name)
+			return resolveRefs(refSchema, schemas, resolving)
+		}
+		out := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			resolved, err := resolveRefs(v, schemas, resolving)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, 0, len(val))
+		for _, v := range val {
+			resolved, err := resolveRefs(v, schemas, resolving)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, resolved)
+		}
+		return out, nil
+	}
+	return node, nil
+}
@@ -0,0 +1,20 @@
+package openapi
+
+import (
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils"
+)
+
+type OpenAPIPackageManager struct {
+	PackageName string
+	// http, https or file URL of the OpenAPI document
+	SourceURL string
+}
+
+func (opm OpenAPIPackageManager) GetPackage() (models.Package, error) {
+	doc, err := utils.ReadFileSource(opm.SourceURL)
+	if err != nil {
+		return nil, ErrGetOpenAPIDocument(err, opm.SourceURL)
+	}
+	return NewPackage(opm.PackageName, opm.SourceURL, []byte(doc))
+}
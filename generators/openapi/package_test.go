@@ -0,0 +1,114 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+var kubernetesSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1.28.4"},
+  "definitions": {
+    "io.k8s.api.core.v1.ConfigMap": {
+      "description": "ConfigMap holds configuration data for pods to consume.",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"},
+        "data": {"type": "object", "additionalProperties": {"type": "string"}}
+      },
+      "type": "object",
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "ConfigMap", "version": "v1"}]
+    },
+    "io.k8s.api.core.v1.ConfigMapList": {
+      "properties": {
+        "items": {"type": "array", "items": {"$ref": "#/definitions/io.k8s.api.core.v1.ConfigMap"}},
+        "metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ListMeta"}
+      },
+      "type": "object",
+      "x-kubernetes-group-version-kind": [{"group": "", "kind": "ConfigMapList", "version": "v1"}]
+    },
+    "io.k8s.api.apps.v1.Deployment": {
+      "properties": {
+        "metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"},
+        "spec": {"$ref": "#/definitions/io.k8s.api.apps.v1.DeploymentSpec"}
+      },
+      "type": "object",
+      "x-kubernetes-group-version-kind": [{"group": "apps", "kind": "Deployment", "version": "v1"}]
+    },
+    "io.k8s.api.apps.v1.DeploymentSpec": {
+      "properties": {"replicas": {"type": "integer"}},
+      "type": "object"
+    },
+    "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+      "properties": {"name": {"type": "string"}},
+      "type": "object"
+    },
+    "io.k8s.apimachinery.pkg.apis.meta.v1.ListMeta": {
+      "properties": {"continue": {"type": "string"}},
+      "type": "object"
+    }
+  }
+}`
+
+var petstoreOpenAPI = `
+openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+        owner:
+          $ref: "#/components/schemas/Owner"
+    Owner:
+      type: object
+      properties:
+        pets:
+          type: array
+          items:
+            $ref: "#/components/schemas/Pet"
+    PetID:
+      type: string
+`
+
+func TestGenerateComponents(t *testing.T) {
+	var tests = []struct {
+		doc   string
+		kinds []string
+		apis  []string
+	}{
+		{kubernetesSwagger, []string{"Deployment", "ConfigMap"}, []string{"apps/v1", "v1"}},
+		{petstoreOpenAPI, []string{"Owner", "Pet"}, []string{"1.0.0", "1.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run("GenerateComponents", func(t *testing.T) {
+			pkg, err := NewPackage("test", "", []byte(tt.doc))
+			if err != nil {
+				t.Fatalf("error parsing document: %v", err)
+			}
+			comps, err := pkg.GenerateComponents()
+			if err != nil {
+				t.Fatalf("error generating components: %v", err)
+			}
+			if len(comps) != len(tt.kinds) {
+				t.Fatalf("generated %d, want %d", len(comps), len(tt.kinds))
+			}
+			for i, comp := range comps {
+				if comp.Component.Kind != tt.kinds[i] {
+					t.Errorf("got %v, want %v", comp.Component.Kind, tt.kinds[i])
+				}
+				if comp.Component.Version != tt.apis[i] {
+					t.Errorf("got %v, want %v", comp.Component.Version, tt.apis[i])
+				}
+				if strings.Contains(comp.Component.Schema, "$ref") {
+					t.Errorf("schema for %v contains unresolved references", comp.Component.Kind)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package generators
+
+import (
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+// GeneratorName is the generator recorded in the provenance of the generated components.
+const GeneratorName = "meshkit"
+
+// SourceDateEpochEnvVar is the variable of the time of the generation, in seconds since the epoch, used in the
+// provenance of the components when GenerateOptions.GeneratedAt is not set. Without either, no time is recorded,
+// so that the output can be reproduced.
+const SourceDateEpochEnvVar = "SOURCE_DATE_EPOCH"
+
+const meshkitModule = "github.com/layer5io/meshkit"
+
+// GeneratorVersion is the version of MeshKit recorded in the provenance of the generated components, read from the
+// build information of the binary.
+var GeneratorVersion = generatorVersion()
+
+func generatorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if info.Main.Path == meshkitModule {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == meshkitModule {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "devel"
+}
+
+// generationTime returns the time of the generation recorded in the provenance of the components, nil if none is set.
+func generationTime(opts GenerateOptions) *time.Time {
+	if !opts.GeneratedAt.IsZero() {
+		at := opts.GeneratedAt.UTC()
+		return &at
+	}
+	if epoch, err := strconv.ParseInt(os.Getenv(SourceDateEpochEnvVar), 10, 64); err == nil {
+		at := time.Unix(epoch, 0).UTC()
+		return &at
+	}
+	return nil
+}
+
+// recordProvenance records where the component was generated from in its metadata, see v1beta1.Provenance. It is
+// called before the model is overridden, so that the package is the one of the source.
+func recordProvenance(comp *v1beta1.ComponentDefinition, opts GenerateOptions, generatedAt *time.Time) {
+	p, _ := comp.Provenance()
+	p.Registrant = opts.Registrant
+	if p.Registrant == "" {
+		p.Registrant = gitHub
+		if !isRemoteSource(opts.Source) {
+			p.Registrant = "crds"
+		}
+	}
+	p.Source, _ = comp.Model.Metadata["source_uri"].(string)
+	if p.Source == "" {
+		p.Source = opts.Source
+	}
+	p.Package = opts.PackageName
+	if p.Package == "" {
+		p.Package = comp.Model.Name
+	}
+	p.PackageVersion = comp.Model.Model.Version
+	if p.PackageVersion == "" {
+		p.PackageVersion = comp.Model.Version
+	}
+	p.Generator = GeneratorName
+	p.GeneratorVersion = GeneratorVersion
+	p.GeneratedAt = generatedAt
+	comp.SetProvenance(p)
+}
+
+// FilterByProvenance returns the components whose provenance matches the query, e.g. to regenerate the components
+// generated from a version of a chart, or by a version of MeshKit.
+func FilterByProvenance(components []v1beta1.ComponentDefinition, query v1beta1.ProvenanceQuery) []v1beta1.ComponentDefinition {
+	var matching []v1beta1.ComponentDefinition
+	for i := range components {
+		if components[i].HasProvenance(query) {
+			matching = append(matching, components[i])
+		}
+	}
+	return matching
+}
@@ -0,0 +1,265 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils/component"
+	"github.com/layer5io/meshkit/utils/manifests"
+)
+
+// Providers of cloud resources whose packages define a large number of CRDs, grouped by service.
+const (
+	ProviderACK        = "ack"
+	ProviderCrossplane = "crossplane"
+)
+
+const (
+	// CrossplanePackageMetadataKey is the key of the CrossplanePackage in the metadata of the models.
+	CrossplanePackageMetadataKey = "crossplanePackage"
+	// CrossplaneResourceMetadataKey is the key of the kind of the resources defined by XRDs, composite or claim, in the metadata of their components.
+	CrossplaneResourceMetadataKey = "crossplaneResource"
+)
+
+const (
+	ackGroupSuffix        = ".services.k8s.aws"
+	crossplaneMetaGroup   = "meta.pkg.crossplane.io"
+	crossplaneAPIExtGroup = "apiextensions.crossplane.io"
+	provisioningCategory  = "Provisioning"
+)
+
+// crossplaneGroupSuffixes are the suffixes of the API groups of the Crossplane providers, e.g. s3.aws.upbound.io.
+var crossplaneGroupSuffixes = []string{".upbound.io", ".crossplane.io"}
+
+// crossplaneCoreGroups are the API groups of Crossplane itself, which belong to no provider.
+var crossplaneCoreGroups = []string{crossplaneAPIExtGroup, "pkg.crossplane.io", "secrets.crossplane.io"}
+
+// CrossplanePackage is the metadata of a Crossplane provider or configuration package, read from its crossplane.yaml.
+type CrossplanePackage struct {
+	// Kind is Provider or Configuration
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Maintainer  string `json:"maintainer,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Controller is the image of the controller of a provider
+	Controller string `json:"controller,omitempty"`
+}
+
+// ProviderService returns the provider, the cloud and the service of the API group of the component, e.g. ack, aws and s3
+// for s3.services.k8s.aws, or crossplane, aws and s3 for s3.aws.upbound.io. The provider is empty for other groups.
+func ProviderService(comp v1beta1.ComponentDefinition) (provider, cloud, service string) {
+	group := comp.Component.Version
+	if i := strings.LastIndex(group, "/"); i >= 0 {
+		group = group[:i]
+	} else {
+		return "", "", ""
+	}
+	if strings.HasSuffix(group, ackGroupSuffix) {
+		return ProviderACK, "aws", strings.TrimSuffix(group, ackGroupSuffix)
+	}
+	for _, core := range crossplaneCoreGroups {
+		if group == core {
+			return "", "", ""
+		}
+	}
+	for _, suffix := range crossplaneGroupSuffixes {
+		if !strings.HasSuffix(group, suffix) {
+			continue
+		}
+		labels := strings.Split(strings.TrimSuffix(group, suffix), ".")
+		cloud = labels[len(labels)-1]
+		if len(labels) > 1 {
+			service = strings.Join(labels[:len(labels)-1], ".")
+		}
+		return ProviderCrossplane, cloud, service
+	}
+	return "", "", ""
+}
+
+// ApplyProviderConventions groups the components of ACK controllers and Crossplane providers by service, so that
+// their very large sets of CRDs are split into a model per service, as the controllers and the family providers are:
+// aws-<service>-controller for ACK and provider-<cloud>-<service> for Crossplane. The category defaults to Provisioning.
+// The model and the category are only set if empty, and components of other groups are left as is.
+func ApplyProviderConventions(comp *v1beta1.ComponentDefinition) bool {
+	provider, cloud, service := ProviderService(*comp)
+	if provider == "" {
+		return false
+	}
+	if comp.Model.Name == "" {
+		switch {
+		case provider == ProviderACK:
+			comp.Model.Name = fmt.Sprintf("aws-%s-controller", service)
+		case service != "":
+			comp.Model.Name = fmt.Sprintf("provider-%s-%s", cloud, service)
+		default:
+			comp.Model.Name = "provider-" + cloud
+		}
+		comp.Model.DisplayName = manifests.FormatToReadableString(comp.Model.Name)
+	}
+	if comp.Model.Category.Name == "" {
+		comp.Model.Category.Name = provisioningCategory
+	}
+	if comp.Model.SubCategory == "" && service != "" {
+		comp.Model.SubCategory = strings.ToUpper(cloud) + " " + strings.ToUpper(service)
+	}
+	if comp.Metadata == nil {
+		comp.Metadata = make(map[string]interface{})
+	}
+	comp.Metadata["provider"] = provider
+	comp.Metadata["service"] = service
+	return true
+}
+
+// parseCrossplanePackage returns the metadata of the Crossplane package among the resources, nil if there is none.
+func parseCrossplanePackage(resources []map[string]interface{}) *CrossplanePackage {
+	for _, res := range resources {
+		apiVersion, _ := res["apiVersion"].(string)
+		if !strings.HasPrefix(apiVersion, crossplaneMetaGroup+"/") {
+			continue
+		}
+		pkg := &CrossplanePackage{}
+		pkg.Kind, _ = res["kind"].(string)
+		metadata, _ := res["metadata"].(map[string]interface{})
+		pkg.Name, _ = metadata["name"].(string)
+		annotations, _ := metadata["annotations"].(map[string]interface{})
+		pkg.Maintainer, _ = annotations["meta.crossplane.io/maintainer"].(string)
+		pkg.Source, _ = annotations["meta.crossplane.io/source"].(string)
+		pkg.Description, _ = annotations["meta.crossplane.io/description"].(string)
+		spec, _ := res["spec"].(map[string]interface{})
+		controller, _ := spec["controller"].(map[string]interface{})
+		pkg.Controller, _ = controller["image"].(string)
+		return pkg
+	}
+	return nil
+}
+
+// generateXRDComponents generates the components of the composite resources defined by the CompositeResourceDefinitions
+// among the resources, and of their claims, which are namespaced.
+func generateXRDComponents(resources []map[string]interface{}) ([]v1beta1.ComponentDefinition, []error) {
+	var components []v1beta1.ComponentDefinition
+	var errs []error
+	for _, res := range resources {
+		if !isCrossplaneResource(res, "CompositeResourceDefinition") {
+			continue
+		}
+		spec, _ := res["spec"].(map[string]interface{})
+		// the composite resource is cluster scoped, its claim namespaced
+		definitions := [][2]interface{}{{"composite", spec["names"]}}
+		if claimNames, ok := spec["claimNames"]; ok {
+			definitions = append(definitions, [2]interface{}{"claim", claimNames})
+		}
+		for _, def := range definitions {
+			xrd := map[string]interface{}{}
+			for k, v := range res {
+				xrd[k] = v
+			}
+			xrdSpec := map[string]interface{}{}
+			for k, v := range spec {
+				xrdSpec[k] = v
+			}
+			xrdSpec["names"] = def[1]
+			xrdSpec["scope"] = "Cluster"
+			if def[0] == "claim" {
+				xrdSpec["scope"] = "Namespaced"
+			}
+			xrd["spec"] = xrdSpec
+			byt, err := json.Marshal(xrd)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			comps, err := component.GenerateServedVersions(string(byt))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for i := range comps {
+				comps[i].Metadata[CrossplaneResourceMetadataKey] = def[0]
+			}
+			components = append(components, comps...)
+		}
+	}
+	return components, errs
+}
+
+func isCrossplaneResource(res map[string]interface{}, kind string) bool {
+	apiVersion, _ := res["apiVersion"].(string)
+	return res["kind"] == kind && strings.HasPrefix(apiVersion, crossplaneAPIExtGroup+"/")
+}
+
+// InferCompositionRelationships proposes a Hierarchical parent relationship from the composite resource of each of the
+// Crossplane Compositions found in the source to each of the resources it composes. The source is read as by
+// GenerateComponentsFromCRDs, and the models of the selectors are looked up in the components by kind and API version.
+// As InferRelationships, the relationships carry the "draft" status in their metadata.
+func InferCompositionRelationships(source string, components []v1beta1.ComponentDefinition) ([]v1alpha2.RelationshipDefinition, error) {
+	return InferCompositionRelationshipsWithClient(source, components, http.DefaultClient)
+}
+
+// InferCompositionRelationshipsWithClient is InferCompositionRelationships reading a remote source with the client,
+// e.g. the client of a MirrorConfig.
+func InferCompositionRelationshipsWithClient(source string, components []v1beta1.ComponentDefinition, client *http.Client) ([]v1alpha2.RelationshipDefinition, error) {
+	data, _, err := readCRDSource(source, client)
+	if err != nil {
+		return nil, ErrReadCRDSource(err)
+	}
+	models := make(map[string]v1beta1.Model)
+	for _, comp := range components {
+		models[comp.Component.Version+"/"+comp.Component.Kind] = comp.Model
+	}
+
+	relationships := make([]v1alpha2.RelationshipDefinition, 0)
+	seen := make(map[string]struct{})
+	for _, res := range component.DecodeResources(string(data)) {
+		if !isCrossplaneResource(res, "Composition") {
+			continue
+		}
+		spec, _ := res["spec"].(map[string]interface{})
+		compositeRef, _ := spec["compositeTypeRef"].(map[string]interface{})
+		compositeVersion, _ := compositeRef["apiVersion"].(string)
+		compositeKind, _ := compositeRef["kind"].(string)
+		if compositeKind == "" {
+			continue
+		}
+		compositeModel := models[compositeVersion+"/"+compositeKind]
+		composed, _ := spec["resources"].([]interface{})
+		for _, r := range composed {
+			resource, _ := r.(map[string]interface{})
+			base, _ := resource["base"].(map[string]interface{})
+			apiVersion, _ := base["apiVersion"].(string)
+			kind, _ := base["kind"].(string)
+			key := compositeVersion + "/" + compositeKind + ">" + apiVersion + "/" + kind
+			if _, ok := seen[key]; kind == "" || ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			rel := v1alpha2.RelationshipDefinition{
+				VersionMeta:      v1beta1.VersionMeta{SchemaVersion: v1alpha2.SchemaVersion},
+				Kind:             "Hierarchical",
+				RelationshipType: "parent",
+				SubType:          "composition",
+				Model:            compositeModel,
+				Metadata: map[string]interface{}{
+					"description": fmt.Sprintf("Inferred from the Composition of %s composing %s", compositeKind, kind),
+					"status":      InferredRelationshipStatus,
+					"inferred":    true,
+				},
+				Selectors: []map[string]interface{}{{
+					"allow": map[string]interface{}{
+						"from": []map[string]interface{}{{"kind": compositeKind, "model": compositeModel.Name}},
+						"to":   []map[string]interface{}{{"kind": kind, "model": models[apiVersion+"/"+kind].Name}},
+					},
+				}},
+			}
+			rel.EvaluationQuery = rel.GetDefaultEvaluationQuery()
+			relationships = append(relationships, rel)
+		}
+	}
+	stabilizeRelationships(relationships)
+	return relationships, nil
+}
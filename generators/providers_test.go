@@ -0,0 +1,113 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+const crossplanePackage = `
+apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: configuration-database
+  annotations:
+    meta.crossplane.io/maintainer: Meshery Authors
+    meta.crossplane.io/source: github.com/meshery/configuration-database
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xdatabases.example.io
+spec:
+  group: example.io
+  names:
+    kind: XDatabase
+    plural: xdatabases
+  claimNames:
+    kind: Database
+    plural: databases
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              storageGB:
+                type: integer
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xdatabases.aws
+spec:
+  compositeTypeRef:
+    apiVersion: example.io/v1alpha1
+    kind: XDatabase
+  resources:
+  - name: instance
+    base:
+      apiVersion: rds.aws.upbound.io/v1beta1
+      kind: Instance
+  - name: subnets
+    base:
+      apiVersion: rds.aws.upbound.io/v1beta1
+      kind: SubnetGroup
+`
+
+func TestGenerateCrossplaneComponents(t *testing.T) {
+	components, err := GenerateComponents(GenerateOptions{Source: crossplanePackage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopes := map[string]interface{}{}
+	for _, comp := range components {
+		if comp.Model.Name != "configuration-database" || comp.Model.Metadata[CrossplanePackageMetadataKey] == nil {
+			t.Errorf("unexpected model of %s: %+v", comp.Component.Kind, comp.Model)
+		}
+		scopes[comp.Component.Kind] = comp.Metadata["isNamespaced"]
+	}
+	if len(components) != 2 || scopes["XDatabase"] != false || scopes["Database"] != true {
+		t.Fatalf("expected the composite and the claim, got %v", scopes)
+	}
+
+	relationships, err := InferCompositionRelationships(crossplanePackage, components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relationships) != 2 || relationships[0].SubType != "composition" || relationships[0].Model.Name != "configuration-database" {
+		t.Fatalf("expected a relationship per composed resource, got %+v", relationships)
+	}
+	if err := relationships[0].Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApplyProviderConventions(t *testing.T) {
+	tests := []struct {
+		version, model, subCategory string
+		applied                     bool
+	}{
+		{"s3.services.k8s.aws/v1alpha1", "aws-s3-controller", "AWS S3", true},
+		{"rds.aws.upbound.io/v1beta1", "provider-aws-rds", "AWS RDS", true},
+		{"gcp.upbound.io/v1beta1", "provider-gcp", "", true},
+		{"apiextensions.crossplane.io/v1", "", "", false},
+		{"apps/v1", "", "", false},
+	}
+	for _, tt := range tests {
+		comp := v1beta1.ComponentDefinition{}
+		comp.Component.Kind = "Bucket"
+		comp.Component.Version = tt.version
+		if applied := ApplyProviderConventions(&comp); applied != tt.applied {
+			t.Errorf("%s: applied = %v", tt.version, applied)
+		}
+		if comp.Model.Name != tt.model || comp.Model.SubCategory != tt.subCategory {
+			t.Errorf("%s: model %q, sub category %q", tt.version, comp.Model.Name, comp.Model.SubCategory)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+const (
+	// Inferred relationships are drafts and need to be reviewed before being published.
+	InferredRelationshipStatus = "draft"
+	kubernetesModel            = "kubernetes"
+)
+
+var (
+	secretRefExpr    = regexp.MustCompile(`^(?i)(.*secret(key)?ref|.*secretname)$`)
+	configMapRefExpr = regexp.MustCompile(`^(?i)(.*configmap(key)?ref|.*configmapname)$`)
+	// fields like "providerConfigRef" or "clusterRef", which by convention refer to a resource of the kind preceding "Ref"
+	ownerRefExpr = regexp.MustCompile(`^(.+)Ref$`)
+)
+
+// InferRelationships proposes relationship definitions between the given components, by looking for conventional fields in their schemas:
+//
+// 1. Fields referencing Secrets or ConfigMaps (secretRef, secretKeyRef, secretName, configMapRef...) yield an Edge non-binding relationship.
+// 2. Fields named "<kind>Ref", where kind is the kind of one of the given components, yield a Hierarchical parent relationship.
+//
+// The "from" selector is the referenced component and the "to" selector is the component declaring the field.
+// Fields inside arrays are not considered. The relationships carry the "draft" status in their metadata and are meant for human review.
+// They are sorted and their IDs derived from their content, see StableRelationshipID.
+func InferRelationships(components []v1beta1.ComponentDefinition) []v1alpha2.RelationshipDefinition {
+	kinds := make(map[string]v1beta1.ComponentDefinition)
+	for _, comp := range components {
+		kinds[strings.ToLower(comp.Component.Kind)] = comp
+	}
+
+	relationships := make([]v1alpha2.RelationshipDefinition, 0)
+	seen := make(map[string]struct{})
+	for _, comp := range components {
+		if comp.Component.Schema == "" {
+			continue
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(comp.Component.Schema), &schema); err != nil {
+			continue
+		}
+		for _, ref := range findReferenceFields(schema, nil) {
+			var rel v1alpha2.RelationshipDefinition
+			fieldName := ref.path[len(ref.path)-1]
+			switch {
+			case secretRefExpr.MatchString(fieldName):
+				rel = newReferenceRelationship("Secret", kubernetesModel, comp, ref)
+			case configMapRefExpr.MatchString(fieldName):
+				rel = newReferenceRelationship("ConfigMap", kubernetesModel, comp, ref)
+			default:
+				match := ownerRefExpr.FindStringSubmatch(fieldName)
+				if match == nil {
+					continue
+				}
+				parent, ok := kinds[strings.ToLower(match[1])]
+				if !ok || parent.Component.Kind == comp.Component.Kind {
+					continue
+				}
+				rel = newParentRelationship(parent, comp, ref)
+			}
+			key := fmt.Sprintf("%s/%s/%s/%s", rel.Kind, rel.SubType, comp.Component.Kind, strings.Join(ref.path, "."))
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			relationships = append(relationships, rel)
+		}
+	}
+	stabilizeRelationships(relationships)
+	return relationships
+}
+
+type referenceField struct {
+	// path of the field from the root of the schema properties
+	path []string
+	// path of the property holding the name of the referenced resource
+	namePath []string
+}
+
+// findReferenceFields returns the fields which can refer to other resources, sorted by path.
+func findReferenceFields(schema map[string]interface{}, parentPath []string) []referenceField {
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	fields := make([]referenceField, 0)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := append(append([]string{}, parentPath...), name)
+		isRef := secretRefExpr.MatchString(name) || configMapRefExpr.MatchString(name) || ownerRefExpr.MatchString(name)
+		propType, _ := prop["type"].(string)
+		if isRef {
+			nestedProps, _ := prop["properties"].(map[string]interface{})
+			if _, hasName := nestedProps["name"]; hasName {
+				fields = append(fields, referenceField{path: path, namePath: append(append([]string{}, path...), "name")})
+				continue
+			}
+			if propType == "string" {
+				fields = append(fields, referenceField{path: path, namePath: path})
+				continue
+			}
+		}
+		if propType == "object" || prop["properties"] != nil {
+			fields = append(fields, findReferenceFields(prop, path)...)
+		}
+	}
+	return fields
+}
+
+func newReferenceRelationship(fromKind, fromModel string, to v1beta1.ComponentDefinition, ref referenceField) v1alpha2.RelationshipDefinition {
+	rel := newInferredRelationship("Edge", "non-binding", "reference", to, ref)
+	rel.Selectors = []map[string]interface{}{
+		newSelector(fromKind, fromModel, to.Component.Kind, to.Model.Name, ref),
+	}
+	return rel
+}
+
+func newParentRelationship(parent, child v1beta1.ComponentDefinition, ref referenceField) v1alpha2.RelationshipDefinition {
+	rel := newInferredRelationship("Hierarchical", "parent", "inventory", child, ref)
+	rel.Selectors = []map[string]interface{}{
+		newSelector(parent.Component.Kind, parent.Model.Name, child.Component.Kind, child.Model.Name, ref),
+	}
+	return rel
+}
+
+func newInferredRelationship(kind, relType, subType string, comp v1beta1.ComponentDefinition, ref referenceField) v1alpha2.RelationshipDefinition {
+	rel := v1alpha2.RelationshipDefinition{
+		VersionMeta: v1beta1.VersionMeta{
+			SchemaVersion: v1alpha2.SchemaVersion,
+		},
+		Kind:             kind,
+		RelationshipType: relType,
+		SubType:          subType,
+		Model:            comp.Model,
+		Metadata: map[string]interface{}{
+			"description": fmt.Sprintf("Inferred from the field %s of %s", strings.Join(ref.path, "."), comp.Component.Kind),
+			"status":      InferredRelationshipStatus,
+			"inferred":    true,
+		},
+	}
+	rel.EvaluationQuery = rel.GetDefaultEvaluationQuery()
+	return rel
+}
+
+func newSelector(fromKind, fromModel, toKind, toModel string, ref referenceField) map[string]interface{} {
+	return map[string]interface{}{
+		"allow": map[string]interface{}{
+			"from": []map[string]interface{}{
+				{
+					"kind":  fromKind,
+					"model": fromModel,
+					"patch": map[string]interface{}{
+						"patchStrategy": "replace",
+						"mutatorRef":    [][]string{{"name"}},
+					},
+				},
+			},
+			"to": []map[string]interface{}{
+				{
+					"kind":  toKind,
+					"model": toModel,
+					"patch": map[string]interface{}{
+						"patchStrategy": "replace",
+						"mutatedRef":    [][]string{append([]string{"settings"}, ref.namePath...)},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func newTestComponent(kind, schema string) v1beta1.ComponentDefinition {
+	comp := v1beta1.ComponentDefinition{}
+	comp.Component.Kind = kind
+	comp.Component.Schema = schema
+	comp.Model.Name = "crossplane"
+	return comp
+}
+
+func TestInferRelationships(t *testing.T) {
+	components := []v1beta1.ComponentDefinition{
+		newTestComponent("ProviderConfig", `{"properties": {"spec": {"type": "object", "properties": {
+			"credentials": {"type": "object", "properties": {"secretRef": {"type": "object", "properties": {"name": {"type": "string"}, "key": {"type": "string"}}}}}
+		}}}}`),
+		newTestComponent("Bucket", `{"properties": {"spec": {"type": "object", "properties": {
+			"providerConfigRef": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"tlsSecretName": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "object", "properties": {"configMapRef": {"type": "object", "properties": {"name": {"type": "string"}}}}}}
+		}}}}`),
+	}
+	rels := InferRelationships(components)
+
+	want := map[string]string{
+		"Secret->ProviderConfig": "Edge",
+		"ProviderConfig->Bucket": "Hierarchical",
+		"Secret->Bucket":         "Edge",
+	}
+	if len(rels) != len(want) {
+		t.Fatalf("inferred %d relationships, want %d", len(rels), len(want))
+	}
+	for _, rel := range rels {
+		allow := rel.Selectors[0]["allow"].(map[string]interface{})
+		from := allow["from"].([]map[string]interface{})[0]["kind"].(string)
+		to := allow["to"].([]map[string]interface{})[0]["kind"].(string)
+		kind, ok := want[from+"->"+to]
+		if !ok || kind != rel.Kind {
+			t.Errorf("unexpected relationship %s %s->%s", rel.Kind, from, to)
+		}
+		if rel.Metadata["status"] != InferredRelationshipStatus {
+			t.Errorf("got status %v, want %v", rel.Metadata["status"], InferredRelationshipStatus)
+		}
+	}
+}
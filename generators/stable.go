@@ -0,0 +1,122 @@
+package generators
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+// IDNamespace is the namespace of the UUIDs of the generated entities, see StableComponentID and StableRelationshipID.
+var IDNamespace = uuid.MustParse("5a4a9cd6-0a1e-4b5f-9d5e-9d1f4e0c6b3a")
+
+// StableComponentID derives the ID of the component from its identity: the name and version of its model, and its API
+// version and kind. Generating the component again yields the same ID, so that registries can de-duplicate components.
+func StableComponentID(comp v1beta1.ComponentDefinition) uuid.UUID {
+	version := comp.Model.Model.Version
+	if version == "" {
+		version = comp.Model.Version
+	}
+	identity := strings.Join([]string{comp.Model.Name, version, comp.Component.Version, comp.Component.Kind}, "/")
+	return uuid.NewSHA1(IDNamespace, []byte(identity))
+}
+
+// ContentDigestMetadataKey is the key of the ContentDigest of a generated component in its metadata.
+const ContentDigestMetadataKey = "contentDigest"
+
+// ContentDigest returns the digest, sha256:<hex>, of the content of the component: its identity and canonical schema.
+// Unlike the ID, it changes when the schema does, so that registries can tell the regenerated definitions which changed.
+func ContentDigest(comp v1beta1.ComponentDefinition) string {
+	content := strings.Join([]string{StableComponentID(comp).String(), CanonicalSchema(comp.Component.Schema)}, "/")
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// StableRelationshipID derives the ID of the relationship from a hash of its kind, type, sub type, model and selectors.
+func StableRelationshipID(rel v1alpha2.RelationshipDefinition) uuid.UUID {
+	// maps are marshalled with sorted keys
+	selectors, _ := json.Marshal(rel.Selectors)
+	identity := strings.Join([]string{rel.Kind, rel.RelationshipType, rel.SubType, rel.Model.Name, rel.Model.Model.Version, string(selectors)}, "/")
+	return uuid.NewSHA1(IDNamespace, []byte(identity))
+}
+
+// ComponentFileName is the slash separated path of the file of the component written by WriteComponents,
+// <API version>/<kind>.json, the slashes of the API version replaced by underscores so that the versions of a kind don't
+// overwrite each other. It only depends on the identity of the component, so that generating again rewrites the same files.
+func ComponentFileName(comp v1beta1.ComponentDefinition) string {
+	return path.Join(strings.ReplaceAll(comp.Component.Version, "/", "_"), comp.Component.Kind+".json")
+}
+
+// CanonicalSchema returns the schema with its keys sorted, the schema as is if it is not JSON.
+func CanonicalSchema(schema string) string {
+	if schema == "" {
+		return schema
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(schema), &v); err != nil {
+		return schema
+	}
+	byt, err := json.Marshal(v)
+	if err != nil {
+		return schema
+	}
+	return string(byt)
+}
+
+// SortComponents sorts the components by model, API version and kind.
+func SortComponents(components []v1beta1.ComponentDefinition) {
+	sort.SliceStable(components, func(i, j int) bool {
+		a, b := components[i], components[j]
+		if a.Model.Name != b.Model.Name {
+			return a.Model.Name < b.Model.Name
+		}
+		if a.Component.Version != b.Component.Version {
+			return a.Component.Version < b.Component.Version
+		}
+		return a.Component.Kind < b.Component.Kind
+	})
+}
+
+// SortRelationships sorts the relationships by kind, type, sub type and ID, the IDs being derived from the selectors.
+func SortRelationships(relationships []v1alpha2.RelationshipDefinition) {
+	sort.SliceStable(relationships, func(i, j int) bool {
+		a, b := relationships[i], relationships[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.RelationshipType != b.RelationshipType {
+			return a.RelationshipType < b.RelationshipType
+		}
+		if a.SubType != b.SubType {
+			return a.SubType < b.SubType
+		}
+		return a.ID.String() < b.ID.String()
+	})
+}
+
+// stabilize makes the generated components deterministic: their schemas have sorted keys, their IDs are derived
+// from their identity, their content digest is recorded and they are sorted, so that generating them again yields the same output.
+func stabilize(components []v1beta1.ComponentDefinition) {
+	for i := range components {
+		components[i].Component.Schema = CanonicalSchema(components[i].Component.Schema)
+		components[i].ID = StableComponentID(components[i])
+		if components[i].Metadata == nil {
+			components[i].Metadata = make(map[string]interface{})
+		}
+		components[i].Metadata[ContentDigestMetadataKey] = ContentDigest(components[i])
+	}
+	SortComponents(components)
+}
+
+func stabilizeRelationships(relationships []v1alpha2.RelationshipDefinition) {
+	for i := range relationships {
+		relationships[i].ID = StableRelationshipID(relationships[i])
+	}
+	SortRelationships(relationships)
+}
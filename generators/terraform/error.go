@@ -0,0 +1,18 @@
+package terraform
+
+import (
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrGetProviderSchemaCode   = "meshkit-11253"
+	ErrParseProviderSchemaCode = "meshkit-11254"
+)
+
+func ErrGetProviderSchema(err error, source string) error {
+	return errors.New(ErrGetProviderSchemaCode, errors.Alert, []string{"Could not get the terraform provider schema from ", source}, []string{err.Error()}, []string{"The source URL is not reachable", "The file does not exist"}, []string{"Make sure the source is a valid http, https or file URL"})
+}
+
+func ErrParseProviderSchema(err error) error {
+	return errors.New(ErrParseProviderSchemaCode, errors.Alert, []string{"Could not parse the terraform provider schema"}, []string{err.Error()}, []string{"The document is not the output of 'terraform providers schema -json'"}, []string{"Generate the schema using 'terraform providers schema -json' after running 'terraform init' for the provider"})
+}
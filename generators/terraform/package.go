@@ -0,0 +1,379 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/manifests"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// TerraformPackage generates a component for every resource of the providers present in the output of `terraform providers schema -json`.
+type TerraformPackage struct {
+	Name      string `yaml:"name" json:"name"`
+	SourceURL string `yaml:"source_url" json:"source_url"`
+	// When set, components are generated for data sources as well
+	IncludeDataSources bool `yaml:"include_data_sources" json:"include_data_sources"`
+	version            string
+	providers          map[string]providerSchema
+}
+
+type providerSchemas struct {
+	FormatVersion   string                    `json:"format_version"`
+	ProviderSchemas map[string]providerSchema `json:"provider_schemas"`
+}
+
+type providerSchema struct {
+	ResourceSchemas   map[string]schema `json:"resource_schemas"`
+	DataSourceSchemas map[string]schema `json:"data_source_schemas"`
+}
+
+type schema struct {
+	Version int   `json:"version"`
+	Block   block `json:"block"`
+}
+
+type block struct {
+	Attributes  map[string]attribute `json:"attributes"`
+	BlockTypes  map[string]blockType `json:"block_types"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+}
+
+type attribute struct {
+	// cty type in its JSON representation, eg: "string" or ["list","string"]
+	Type json.RawMessage `json:"type"`
+	// NestedType replaces Type for the attributes of nested attributes (protocol version 6 providers)
+	NestedType  *nestedType `json:"nested_type"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required"`
+	Optional    bool        `json:"optional"`
+	Computed    bool        `json:"computed"`
+	Sensitive   bool        `json:"sensitive"`
+	Deprecated  bool        `json:"deprecated"`
+}
+
+type nestedType struct {
+	Attributes  map[string]attribute `json:"attributes"`
+	NestingMode string               `json:"nesting_mode"`
+	MinItems    int                  `json:"min_items"`
+	MaxItems    int                  `json:"max_items"`
+}
+
+type blockType struct {
+	NestingMode string `json:"nesting_mode"`
+	Block       block  `json:"block"`
+	MinItems    int    `json:"min_items"`
+	MaxItems    int    `json:"max_items"`
+}
+
+func NewPackage(name, sourceURL, version string, doc []byte) (TerraformPackage, error) {
+	pkg := TerraformPackage{
+		Name:      name,
+		SourceURL: sourceURL,
+		version:   version,
+	}
+	var schemas providerSchemas
+	err := json.Unmarshal(doc, &schemas)
+	if err != nil {
+		return pkg, ErrParseProviderSchema(err)
+	}
+	if schemas.FormatVersion == "" {
+		return pkg, ErrParseProviderSchema(fmt.Errorf("missing 'format_version' field"))
+	}
+	pkg.providers = schemas.ProviderSchemas
+	return pkg, nil
+}
+
+func (pkg TerraformPackage) GetVersion() string {
+	return pkg.version
+}
+
+func (pkg TerraformPackage) GenerateComponents() ([]v1beta1.ComponentDefinition, error) {
+	components := make([]v1beta1.ComponentDefinition, 0)
+	var errs []error
+
+	for _, providerAddress := range sortedKeys(pkg.providers) {
+		provider := pkg.providers[providerAddress]
+		for _, resourceType := range sortedKeys(provider.ResourceSchemas) {
+			comp, err := pkg.generateComponent(providerAddress, resourceType, provider.ResourceSchemas[resourceType], false)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			components = append(components, comp)
+		}
+		if !pkg.IncludeDataSources {
+			continue
+		}
+		for _, dataSourceType := range sortedKeys(provider.DataSourceSchemas) {
+			comp, err := pkg.generateComponent(providerAddress, dataSourceType, provider.DataSourceSchemas[dataSourceType], true)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			components = append(components, comp)
+		}
+	}
+	return components, utils.CombineErrors(errs, "\n")
+}
+
+func (pkg TerraformPackage) generateComponent(providerAddress, resourceType string, s schema, isDataSource bool) (v1beta1.ComponentDefinition, error) {
+	comp := v1beta1.ComponentDefinition{}
+	jsonSchema, err := blockToJSONSchema(s.Block)
+	if err != nil {
+		return comp, ErrParseProviderSchema(fmt.Errorf("invalid schema for %s: %w", resourceType, err))
+	}
+	displayName := formatResourceType(resourceType)
+	jsonSchema["title"] = displayName
+	byt, err := json.MarshalIndent(jsonSchema, "", " ")
+	if err != nil {
+		return comp, utils.ErrMarshal(err)
+	}
+
+	comp.SchemaVersion = v1beta1.SchemaVersion
+	comp.DisplayName = displayName
+	comp.Description = s.Block.Description
+	comp.Format = v1beta1.JSON
+	// the version of the provider, the version of the schema of the resource if it isn't known
+	version := pkg.version
+	if version == "" {
+		version = strconv.Itoa(s.Version)
+	}
+	comp.Component = v1beta1.ComponentEntity{
+		TypeMeta: v1beta1.TypeMeta{
+			Kind:    resourceType,
+			Version: version,
+		},
+		Schema: string(byt),
+	}
+	comp.Metadata = map[string]interface{}{
+		"terraformProvider":      providerAddress,
+		"terraformSchemaVersion": s.Version,
+		"isDataSource":           isDataSource,
+		"isNamespaced":           false,
+	}
+	comp.Model.Metadata = map[string]interface{}{
+		"source_uri": pkg.SourceURL,
+	}
+	comp.Model.Version = pkg.version
+	comp.Model.Name = pkg.Name
+	comp.Model.DisplayName = manifests.FormatToReadableString(comp.Model.Name)
+	return comp, nil
+}
+
+// blockToJSONSchema converts a terraform configuration block into a JSON schema.
+// Computed only attributes are skipped as they can not be configured.
+func blockToJSONSchema(b block) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+
+	for _, name := range sortedKeys(b.Attributes) {
+		attr := b.Attributes[name]
+		if attr.Computed && !attr.Optional && !attr.Required {
+			continue
+		}
+		prop, err := attributeToJSONSchema(attr)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", name, err)
+		}
+		if attr.Description != "" {
+			prop["description"] = attr.Description
+		}
+		if attr.Sensitive {
+			prop["format"] = "password"
+		}
+		if attr.Deprecated {
+			prop["deprecated"] = true
+		}
+		properties[name] = prop
+		if attr.Required {
+			required = append(required, name)
+		}
+	}
+
+	for _, name := range sortedKeys(b.BlockTypes) {
+		bt := b.BlockTypes[name]
+		nested, err := blockToJSONSchema(bt.Block)
+		if err != nil {
+			return nil, err
+		}
+		prop, err := nest(nested, bt.NestingMode, bt.MinItems, bt.MaxItems)
+		if err != nil {
+			return nil, fmt.Errorf("block %s: %w", name, err)
+		}
+		properties[name] = prop
+		if bt.MinItems > 0 {
+			required = append(required, name)
+		}
+	}
+
+	out := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	if b.Description != "" {
+		out["description"] = b.Description
+	}
+	return out, nil
+}
+
+// attributeToJSONSchema converts the attribute into a JSON schema, from its cty type or from its nested attributes.
+func attributeToJSONSchema(attr attribute) (map[string]interface{}, error) {
+	if attr.NestedType != nil {
+		nested, err := blockToJSONSchema(block{Attributes: attr.NestedType.Attributes})
+		if err != nil {
+			return nil, err
+		}
+		return nest(nested, attr.NestedType.NestingMode, attr.NestedType.MinItems, attr.NestedType.MaxItems)
+	}
+	var ctyType interface{}
+	err := json.Unmarshal(attr.Type, &ctyType)
+	if err != nil {
+		return nil, err
+	}
+	return ctyTypeToJSONSchema(ctyType)
+}
+
+// nest wraps the schema of a nested block or of nested attributes according to their nesting mode.
+func nest(nested map[string]interface{}, nestingMode string, minItems, maxItems int) (map[string]interface{}, error) {
+	switch nestingMode {
+	case "single", "group":
+		return nested, nil
+	case "list", "set":
+		prop := map[string]interface{}{
+			"type":  "array",
+			"items": nested,
+		}
+		if minItems > 0 {
+			prop["minItems"] = minItems
+		}
+		if maxItems > 0 {
+			prop["maxItems"] = maxItems
+		}
+		if nestingMode == "set" {
+			prop["uniqueItems"] = true
+		}
+		return prop, nil
+	case "map":
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": nested,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported nesting mode %q", nestingMode)
+}
+
+// ctyTypeToJSONSchema converts the JSON representation of a cty type (https://github.com/zclconf/go-cty) into a JSON schema.
+func ctyTypeToJSONSchema(ctyType interface{}) (map[string]interface{}, error) {
+	switch t := ctyType.(type) {
+	case string:
+		switch t {
+		case "string":
+			return map[string]interface{}{"type": "string"}, nil
+		case "number":
+			return map[string]interface{}{"type": "number"}, nil
+		case "bool":
+			return map[string]interface{}{"type": "boolean"}, nil
+		case "dynamic":
+			return map[string]interface{}{}, nil
+		}
+	case []interface{}:
+		// the object types with optional attributes have a third element, e.g. ["object",{"a":"string"},["a"]]
+		if len(t) != 2 && (len(t) != 3 || t[0] != "object") {
+			break
+		}
+		kind, _ := t[0].(string)
+		switch kind {
+		case "list", "set":
+			items, err := ctyTypeToJSONSchema(t[1])
+			if err != nil {
+				return nil, err
+			}
+			out := map[string]interface{}{"type": "array", "items": items}
+			if kind == "set" {
+				out["uniqueItems"] = true
+			}
+			return out, nil
+		case "map":
+			values, err := ctyTypeToJSONSchema(t[1])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+		case "object":
+			attrs, ok := t[1].(map[string]interface{})
+			if !ok {
+				break
+			}
+			optional := make(map[string]bool)
+			if len(t) == 3 {
+				names, ok := t[2].([]interface{})
+				if !ok {
+					break
+				}
+				for _, name := range names {
+					if name, ok := name.(string); ok {
+						optional[name] = true
+					}
+				}
+			}
+			properties := make(map[string]interface{}, len(attrs))
+			required := make([]string, 0)
+			for _, name := range sortedKeys(attrs) {
+				prop, err := ctyTypeToJSONSchema(attrs[name])
+				if err != nil {
+					return nil, err
+				}
+				properties[name] = prop
+				if !optional[name] {
+					required = append(required, name)
+				}
+			}
+			out := map[string]interface{}{"type": "object", "properties": properties}
+			if len(required) > 0 {
+				out["required"] = required
+			}
+			return out, nil
+		case "tuple":
+			elems, ok := t[1].([]interface{})
+			if !ok {
+				break
+			}
+			items := make([]interface{}, 0, len(elems))
+			for _, elem := range elems {
+				item, err := ctyTypeToJSONSchema(elem)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			return map[string]interface{}{"type": "array", "items": items}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %v", ctyType)
+}
+
+// formatResourceType converts resource types like "aws_s3_bucket" into "Aws S3 Bucket"
+func formatResourceType(resourceType string) string {
+	caser := cases.Title(language.English)
+	return caser.String(strings.ReplaceAll(resourceType, "_", " "))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
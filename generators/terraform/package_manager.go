@@ -0,0 +1,22 @@
+package terraform
+
+import (
+	"github.com/layer5io/meshkit/generators/models"
+	"github.com/layer5io/meshkit/utils"
+)
+
+type TerraformPackageManager struct {
+	PackageName string
+	// http, https or file URL of the JSON output of 'terraform providers schema -json'
+	SourceURL string
+	// The schema output does not carry the provider version, hence it has to be specified explicitly
+	Version string
+}
+
+func (tpm TerraformPackageManager) GetPackage() (models.Package, error) {
+	doc, err := utils.ReadFileSource(tpm.SourceURL)
+	if err != nil {
+		return nil, ErrGetProviderSchema(err, tpm.SourceURL)
+	}
+	return NewPackage(tpm.PackageName, tpm.SourceURL, tpm.Version, []byte(doc))
+}
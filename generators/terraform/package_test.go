@@ -0,0 +1,163 @@
+package terraform
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var providerSchemaJSON = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/hashicorp/random": {
+      "resource_schemas": {
+        "random_password": {
+          "version": 3,
+          "block": {
+            "attributes": {
+              "id": {"type": "string", "computed": true},
+              "length": {"type": "number", "required": true},
+              "special": {"type": "bool", "optional": true},
+              "keepers": {"type": ["map", "string"], "optional": true},
+              "policy": {"type": ["object", {"name": "string", "ttl": "number"}, ["ttl"]], "optional": true},
+              "result": {"type": "string", "computed": true, "sensitive": true}
+            },
+            "block_types": {
+              "rule": {"nesting_mode": "list", "min_items": 1, "block": {"attributes": {"name": {"type": "string", "required": true}}}}
+            }
+          }
+        }
+      },
+      "data_source_schemas": {
+        "random_source": {"version": 0, "block": {"attributes": {"seed": {"type": "string", "optional": true}}}}
+      }
+    }
+  }
+}`
+
+func TestGenerateComponents(t *testing.T) {
+	pkg, err := NewPackage("random", "", "3.5.1", []byte(providerSchemaJSON))
+	if err != nil {
+		t.Fatalf("error parsing provider schema: %v", err)
+	}
+	comps, err := pkg.GenerateComponents()
+	if err != nil {
+		t.Fatalf("error generating components: %v", err)
+	}
+	if len(comps) != 1 {
+		t.Fatalf("generated %d, want %d", len(comps), 1)
+	}
+	comp := comps[0]
+	if comp.Component.Kind != "random_password" || comp.DisplayName != "Random Password" {
+		t.Errorf("got %v (%v), want random_password (Random Password)", comp.Component.Kind, comp.DisplayName)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(comp.Component.Schema), &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	for _, computed := range []string{"id", "result"} {
+		if _, ok := properties[computed]; ok {
+			t.Errorf("computed attribute %v should not be part of the schema", computed)
+		}
+	}
+	if comp.Component.Version != "3.5.1" || comp.Metadata["terraformProvider"] != "registry.terraform.io/hashicorp/random" {
+		t.Errorf("got version %v of %v, want 3.5.1 of registry.terraform.io/hashicorp/random", comp.Component.Version, comp.Metadata["terraformProvider"])
+	}
+	for _, prop := range []string{"length", "special", "keepers", "policy", "rule"} {
+		if _, ok := properties[prop]; !ok {
+			t.Errorf("expected property %v in the schema", prop)
+		}
+	}
+	required, _ := schema["required"].([]interface{})
+	if len(required) != 2 {
+		t.Errorf("got required %v, want [length rule]", required)
+	}
+	policy := properties["policy"].(map[string]interface{})
+	if policyRequired, _ := policy["required"].([]interface{}); len(policyRequired) != 1 || policyRequired[0] != "name" {
+		t.Errorf("got required %v for the object, want [name] as ttl is optional", policy["required"])
+	}
+
+	pkg.IncludeDataSources = true
+	comps, _ = pkg.GenerateComponents()
+	if len(comps) != 2 {
+		t.Errorf("generated %d with data sources, want %d", len(comps), 2)
+	}
+}
+
+var nestedTypeSchemaJSON = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/hashicorp/tfe": {
+      "resource_schemas": {
+        "tfe_workspace_settings": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "workspace_id": {"type": "string", "required": true},
+              "execution": {
+                "nested_type": {
+                  "nesting_mode": "single",
+                  "attributes": {
+                    "mode": {"type": "string", "required": true},
+                    "agent_pool_id": {"type": "string", "optional": true}
+                  }
+                },
+                "optional": true
+              },
+              "variables": {
+                "nested_type": {
+                  "nesting_mode": "set",
+                  "attributes": {
+                    "key": {"type": "string", "required": true},
+                    "value": {"type": "string", "optional": true, "sensitive": true},
+                    "id": {"type": "string", "computed": true}
+                  }
+                },
+                "optional": true
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestGenerateComponentsNestedType(t *testing.T) {
+	pkg, err := NewPackage("tfe", "", "0.51.1", []byte(nestedTypeSchemaJSON))
+	if err != nil {
+		t.Fatalf("error parsing provider schema: %v", err)
+	}
+	comps, err := pkg.GenerateComponents()
+	if err != nil {
+		t.Fatalf("error generating components: %v", err)
+	}
+	if len(comps) != 1 {
+		t.Fatalf("generated %d, want %d", len(comps), 1)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(comps[0].Component.Schema), &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	execution, _ := properties["execution"].(map[string]interface{})
+	if execution["type"] != "object" {
+		t.Fatalf("got %v, want the object of the single nested attributes", execution)
+	}
+	if required, _ := execution["required"].([]interface{}); len(required) != 1 || required[0] != "mode" {
+		t.Errorf("got required %v, want [mode]", execution["required"])
+	}
+	variables, _ := properties["variables"].(map[string]interface{})
+	if variables["type"] != "array" || variables["uniqueItems"] != true {
+		t.Fatalf("got %v, want the array of the set nested attributes", variables)
+	}
+	items := variables["items"].(map[string]interface{})["properties"].(map[string]interface{})
+	if _, ok := items["id"]; ok {
+		t.Error("computed nested attribute id should not be part of the schema")
+	}
+	if value, _ := items["value"].(map[string]interface{}); value["format"] != "password" {
+		t.Errorf("got %v, want the sensitive value to be a password", items["value"])
+	}
+}
@@ -0,0 +1,236 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils"
+)
+
+// ValidationReport is the machine readable outcome of ValidateGenerated.
+type ValidationReport struct {
+	Directory  string                      `json:"directory"`
+	Total      int                         `json:"total"`
+	Valid      int                         `json:"valid"`
+	Invalid    int                         `json:"invalid"`
+	Components []ComponentValidationResult `json:"components"`
+}
+
+// ComponentValidationResult holds the problems found in a single component definition file.
+type ComponentValidationResult struct {
+	Path   string   `json:"path"`
+	Model  string   `json:"model,omitempty"`
+	Kind   string   `json:"kind,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+func (r ComponentValidationResult) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+// HasErrors reports whether at least one component failed validation.
+func (r ValidationReport) HasErrors() bool {
+	return r.Invalid > 0
+}
+
+// ValidateGenerated walks dir and validates every component definition found in the JSON files under it.
+// Files which are not component definitions (e.g. model definitions) are skipped.
+// For each component it checks that:
+//
+// 1. the fields required by the meshmodel schema are set and the format is known,
+// 2. the component schema is a valid JSON schema which compiles to CUE,
+// 3. the svgColor and svgWhite icons are inline SVGs, URLs or files relative to the component file,
+// 4. the capabilities in the metadata, if any, are well-formed.
+//
+// The returned error only reports failures to read the directory, validation failures are part of the report.
+func ValidateGenerated(dir string) (ValidationReport, error) {
+	report := ValidationReport{Directory: dir, Components: make([]ComponentValidationResult, 0)}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !isComponentDefinition(data) {
+			return nil
+		}
+		result := validateComponentFile(path, data)
+		report.Components = append(report.Components, result)
+		return nil
+	})
+	if err != nil {
+		return report, ErrValidateGenerated(err, dir)
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Path < report.Components[j].Path
+	})
+	for _, result := range report.Components {
+		report.Total++
+		if result.IsValid() {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+	}
+	return report, nil
+}
+
+func isComponentDefinition(data []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// let the validation report the malformed component
+		return true
+	}
+	_, ok := fields["component"]
+	return ok
+}
+
+func validateComponentFile(path string, data []byte) ComponentValidationResult {
+	result := ComponentValidationResult{Path: path}
+	var comp v1beta1.ComponentDefinition
+	if err := json.Unmarshal(data, &comp); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid component definition: %s", err.Error()))
+		return result
+	}
+	result.Model = comp.Model.Name
+	result.Kind = comp.Component.Kind
+
+	result.Errors = append(result.Errors, validateRequiredFields(comp)...)
+	result.Errors = append(result.Errors, validateComponentSchema(comp)...)
+	result.Errors = append(result.Errors, validateIcons(comp, filepath.Dir(path))...)
+	result.Errors = append(result.Errors, validateCapabilities(comp)...)
+	return result
+}
+
+func validateRequiredFields(comp v1beta1.ComponentDefinition) []string {
+	errs := make([]string, 0)
+	required := map[string]string{
+		"schemaVersion":     comp.SchemaVersion,
+		"displayName":       comp.DisplayName,
+		"component.kind":    comp.Component.Kind,
+		"component.version": comp.Component.Version,
+		"model.name":        comp.Model.Name,
+		"model.version":     comp.Model.Version,
+	}
+	for _, field := range sortedFieldNames(required) {
+		if strings.TrimSpace(required[field]) == "" {
+			errs = append(errs, fmt.Sprintf("%s is a required field", field))
+		}
+	}
+	switch comp.Format {
+	case v1beta1.JSON, v1beta1.YAML, v1beta1.CUE, "":
+	default:
+		errs = append(errs, fmt.Sprintf("unknown format %q", comp.Format))
+	}
+	return errs
+}
+
+func validateComponentSchema(comp v1beta1.ComponentDefinition) []string {
+	if comp.Component.Schema == "" {
+		if comp.IsAnnotation() {
+			return nil
+		}
+		return []string{"component.schema is empty"}
+	}
+	if !utils.IsSchemaEmpty(comp.Component.Schema) {
+		return []string{"component.schema does not define any properties"}
+	}
+	if _, err := utils.JsonSchemaToCue(comp.Component.Schema); err != nil {
+		return []string{fmt.Sprintf("component.schema does not compile: %s", err.Error())}
+	}
+	return nil
+}
+
+func validateIcons(comp v1beta1.ComponentDefinition, baseDir string) []string {
+	errs := make([]string, 0)
+	for _, key := range []string{"svgColor", "svgWhite"} {
+		value, ok := comp.Metadata[key]
+		if !ok {
+			continue
+		}
+		icon, ok := value.(string)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("metadata.%s should be a string", key))
+			continue
+		}
+		if err := resolveIcon(icon, baseDir); err != nil {
+			errs = append(errs, fmt.Sprintf("metadata.%s does not resolve: %s", key, err.Error()))
+		}
+	}
+	return errs
+}
+
+func resolveIcon(icon, baseDir string) error {
+	icon = strings.TrimSpace(icon)
+	switch {
+	case icon == "":
+		return fmt.Errorf("icon is empty")
+	case strings.HasPrefix(icon, "<svg") || strings.HasPrefix(icon, "<?xml"):
+		return nil
+	case strings.HasPrefix(icon, "http://") || strings.HasPrefix(icon, "https://"):
+		if _, err := url.ParseRequestURI(icon); err != nil {
+			return err
+		}
+		return nil
+	}
+	path := icon
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateCapabilities reports every malformed capability, and each of the required fields missing, instead of
+// the first error of v1beta1.ParseCapabilities.
+func validateCapabilities(comp v1beta1.ComponentDefinition) []string {
+	value, ok := comp.Metadata[v1beta1.CapabilitiesMetadataKey]
+	if !ok || value == nil {
+		return nil
+	}
+	capabilities, ok := value.([]interface{})
+	if !ok {
+		return []string{"metadata.capabilities should be a list"}
+	}
+	errs := make([]string, 0)
+	for i, c := range capabilities {
+		capability, ok := c.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("metadata.capabilities[%d] should be an object", i))
+			continue
+		}
+		if states, ok := capability["entityState"]; ok {
+			if _, isList := states.([]interface{}); !isList {
+				errs = append(errs, fmt.Sprintf("metadata.capabilities[%d].entityState should be a list", i))
+				continue
+			}
+		}
+		if _, err := v1beta1.ParseCapabilities([]interface{}{capability}); err != nil {
+			errs = append(errs, fmt.Sprintf("metadata.capabilities[%d]: %s", i, strings.TrimPrefix(err.Error(), "capabilities[0]: ")))
+		}
+	}
+	return errs
+}
+
+func sortedFieldNames(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,206 @@
+package generators
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+const validComponentSchema = `{"type":"object","properties":{"spec":{"type":"object","properties":{"replicas":{"type":"integer"}}}}}`
+
+func validComponent() v1beta1.ComponentDefinition {
+	return v1beta1.ComponentDefinition{
+		VersionMeta: v1beta1.VersionMeta{SchemaVersion: v1beta1.SchemaVersion, Version: "v1.0.0"},
+		DisplayName: "Backend",
+		Format:      v1beta1.JSON,
+		Model: v1beta1.Model{
+			VersionMeta: v1beta1.VersionMeta{Version: "v1.0.0"},
+			Name:        "example",
+		},
+		Metadata: map[string]interface{}{
+			"svgColor": "<svg></svg>",
+		},
+		Component: v1beta1.ComponentEntity{
+			TypeMeta: v1beta1.TypeMeta{Kind: "Backend", Version: "example.io/v1"},
+			Schema:   validComponentSchema,
+		},
+	}
+}
+
+func writeComponentFile(t *testing.T, dir, name string, comp v1beta1.ComponentDefinition) {
+	t.Helper()
+	byt, err := json.Marshal(comp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), byt, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateComponentFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*v1beta1.ComponentDefinition)
+		// substrings expected in the errors, none means the component is valid
+		wantErrs []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*v1beta1.ComponentDefinition) {},
+		},
+		{
+			name: "valid with capabilities",
+			mutate: func(c *v1beta1.ComponentDefinition) {
+				c.Metadata[v1beta1.CapabilitiesMetadataKey] = []interface{}{
+					map[string]interface{}{"displayName": "Scale", "kind": "mutate", "type": "configuration"},
+				}
+			},
+		},
+		{
+			name: "missing required fields",
+			mutate: func(c *v1beta1.ComponentDefinition) {
+				c.DisplayName = ""
+				c.Model.Version = ""
+			},
+			wantErrs: []string{"displayName is a required field", "model.version is a required field"},
+		},
+		{
+			name:     "unknown format",
+			mutate:   func(c *v1beta1.ComponentDefinition) { c.Format = "XML" },
+			wantErrs: []string{`unknown format "XML"`},
+		},
+		{
+			name:     "empty schema",
+			mutate:   func(c *v1beta1.ComponentDefinition) { c.Component.Schema = "" },
+			wantErrs: []string{"component.schema is empty"},
+		},
+		{
+			name:     "schema without properties",
+			mutate:   func(c *v1beta1.ComponentDefinition) { c.Component.Schema = `{"type":"object"}` },
+			wantErrs: []string{"component.schema does not define any properties"},
+		},
+		{
+			name:     "icon which is not a string",
+			mutate:   func(c *v1beta1.ComponentDefinition) { c.Metadata["svgWhite"] = 42 },
+			wantErrs: []string{"metadata.svgWhite should be a string"},
+		},
+		{
+			name:     "icon file which does not exist",
+			mutate:   func(c *v1beta1.ComponentDefinition) { c.Metadata["svgColor"] = "icons/color/backend.svg" },
+			wantErrs: []string{"metadata.svgColor does not resolve"},
+		},
+		{
+			name: "malformed capabilities",
+			mutate: func(c *v1beta1.ComponentDefinition) {
+				c.Metadata[v1beta1.CapabilitiesMetadataKey] = []interface{}{map[string]interface{}{"displayName": "Scale"}}
+			},
+			wantErrs: []string{"metadata.capabilities[0]"},
+		},
+		{
+			name: "every malformed capability",
+			mutate: func(c *v1beta1.ComponentDefinition) {
+				c.Metadata[v1beta1.CapabilitiesMetadataKey] = []interface{}{
+					map[string]interface{}{"displayName": "Scale", "kind": "mutate", "type": "configuration", "entityState": "declaration"},
+					"scale",
+					map[string]interface{}{"displayName": "Style"},
+				}
+			},
+			wantErrs: []string{
+				"metadata.capabilities[0].entityState should be a list",
+				"metadata.capabilities[1] should be an object",
+				"metadata.capabilities[2]: kind, type is a required field",
+			},
+		},
+		{
+			name:     "capabilities which are not a list",
+			mutate:   func(c *v1beta1.ComponentDefinition) { c.Metadata[v1beta1.CapabilitiesMetadataKey] = "workload" },
+			wantErrs: []string{"metadata.capabilities should be a list"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp := validComponent()
+			tt.mutate(&comp)
+			byt, err := json.Marshal(comp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := validateComponentFile(filepath.Join(t.TempDir(), "backend.json"), byt)
+			if len(tt.wantErrs) == 0 {
+				if !result.IsValid() {
+					t.Fatalf("expected the component to be valid, got %v", result.Errors)
+				}
+				return
+			}
+			joined := strings.Join(result.Errors, "\n")
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(joined, want) {
+					t.Errorf("expected an error containing %q, got %v", want, result.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateComponentFileMalformed(t *testing.T) {
+	result := validateComponentFile("broken.json", []byte(`{"component": `))
+	if result.IsValid() {
+		t.Fatal("expected a malformed component to be invalid")
+	}
+}
+
+func TestValidateGenerated(t *testing.T) {
+	dir := t.TempDir()
+	modelDir := filepath.Join(dir, "example", "v1.0.0")
+	if err := os.MkdirAll(filepath.Join(modelDir, "icons"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(modelDir, "icons", "backend.svg"), []byte("<svg></svg>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := validComponent()
+	valid.Metadata["svgWhite"] = "icons/backend.svg"
+	writeComponentFile(t, modelDir, "Backend.json", valid)
+
+	invalid := validComponent()
+	invalid.Component.Kind = "Frontend"
+	invalid.Component.Schema = ""
+	writeComponentFile(t, modelDir, "Frontend.json", invalid)
+
+	// model definitions and files which are not JSON are skipped
+	if err := os.WriteFile(filepath.Join(modelDir, "model.json"), []byte(`{"name":"example"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(modelDir, "README.md"), []byte("# example"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ValidateGenerated(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 2 || report.Valid != 1 || report.Invalid != 1 {
+		t.Fatalf("expected 2 components, 1 valid and 1 invalid, got %d/%d/%d", report.Total, report.Valid, report.Invalid)
+	}
+	if !report.HasErrors() {
+		t.Error("expected the report to have errors")
+	}
+	if report.Components[0].Kind != "Backend" || !report.Components[0].IsValid() {
+		t.Errorf("expected Backend to be valid, got %+v", report.Components[0])
+	}
+	if report.Components[1].Kind != "Frontend" || report.Components[1].IsValid() {
+		t.Errorf("expected Frontend to be invalid, got %+v", report.Components[1])
+	}
+}
+
+func TestValidateGeneratedMissingDir(t *testing.T) {
+	if _, err := ValidateGenerated(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
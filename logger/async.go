@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy is what an asynchronous logger does with an entry if its buffer is full.
+type OverflowPolicy int
+
+const (
+	// BlockOnOverflow waits until the buffer has room for the entry, no entry is lost.
+	BlockOnOverflow OverflowPolicy = iota
+	// DropOnOverflow drops the entry, so that logging never blocks, e.g. in MeshSync event handlers.
+	DropOnOverflow
+)
+
+// DefaultAsyncBufferSize is the number of entries buffered by an asynchronous logger unless configured otherwise.
+const DefaultAsyncBufferSize = 1024
+
+// AsyncOptions configures the asynchronous writing of the entries of a logger.
+type AsyncOptions struct {
+	// BufferSize is the number of entries queued, DefaultAsyncBufferSize if zero.
+	BufferSize int
+	// Overflow is the policy applied if the buffer is full.
+	Overflow OverflowPolicy
+}
+
+// asyncWrite is a formatted entry to write to an output, or a flush request if done is set.
+type asyncWrite struct {
+	out  io.Writer
+	data []byte
+	done chan error
+}
+
+// asyncWriter writes the entries of the outputs it wraps from a background goroutine, in the order they were logged.
+type asyncWriter struct {
+	queue    chan asyncWrite
+	overflow OverflowPolicy
+	stopped  chan struct{}
+	dropped  atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+	// err is the first error writing since the last flush, only accessed by the background goroutine.
+	err error
+}
+
+func newAsyncWriter(opts AsyncOptions) *asyncWriter {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = DefaultAsyncBufferSize
+	}
+	a := &asyncWriter{queue: make(chan asyncWrite, size), overflow: opts.Overflow, stopped: make(chan struct{})}
+	go a.run()
+	return a
+}
+
+func (a *asyncWriter) run() {
+	defer close(a.stopped)
+	for w := range a.queue {
+		if w.done != nil {
+			w.done <- a.err
+			a.err = nil
+			continue
+		}
+		if _, err := w.out.Write(w.data); err != nil && a.err == nil {
+			a.err = err
+		}
+	}
+}
+
+// wrap returns a writer whose writes to out are queued.
+func (a *asyncWriter) wrap(out io.Writer) io.Writer {
+	return &asyncOutput{async: a, out: out}
+}
+
+type asyncOutput struct {
+	async *asyncWriter
+	out   io.Writer
+}
+
+// Write queues a copy of p, the formatters of logrus reuse their buffers. Once the writer is closed, p is written synchronously.
+func (o *asyncOutput) Write(p []byte) (int, error) {
+	a := o.async
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return o.out.Write(p)
+	}
+	w := asyncWrite{out: o.out, data: append([]byte(nil), p...)}
+	if a.overflow == DropOnOverflow {
+		select {
+		case a.queue <- w:
+		default:
+			a.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+	a.queue <- w
+	return len(p), nil
+}
+
+// flush waits until the entries queued so far are written, and returns the first error writing them.
+func (a *asyncWriter) flush() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return nil
+	}
+	done := make(chan error, 1)
+	a.queue <- asyncWrite{done: done}
+	return <-done
+}
+
+// close flushes the entries and stops the background goroutine, the entries logged afterwards are written synchronously.
+func (a *asyncWriter) close() error {
+	err := a.flush()
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	<-a.stopped
+	return err
+}
+
+// Flush waits until the entries logged so far are written if the logger is asynchronous, see Options.Async,
+// and returns the first error writing them since the last flush.
+func (l *Logger) Flush() error {
+	if l.async == nil {
+		return nil
+	}
+	return l.async.flush()
+}
+
+// Close flushes the entries of an asynchronous logger and stops its background goroutine.
+// The entries logged afterwards are written synchronously.
+func (l *Logger) Close() error {
+	if l.async == nil {
+		return nil
+	}
+	return l.async.close()
+}
+
+// Dropped returns the number of entries dropped by an asynchronous logger as its buffer was full, see DropOnOverflow.
+func (l *Logger) Dropped() uint64 {
+	if l.async == nil {
+		return 0
+	}
+	return l.async.dropped.Load()
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// blockingWriter blocks the writes until it is released.
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestAsyncBlock(t *testing.T) {
+	var out, file bytes.Buffer
+	log, err := New("test", Options{
+		Format:   TerminalLogFormat,
+		LogLevel: int(logrus.InfoLevel),
+		Output:   &out,
+		Outputs:  []OutputOptions{{Format: TerminalLogFormat, LogLevel: int(logrus.InfoLevel), Output: &file}},
+		Async:    &AsyncOptions{BufferSize: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		log.Info(fmt.Sprintf("entry %d", i))
+	}
+	if err := log.(Flusher).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []*bytes.Buffer{&out, &file} {
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		if len(lines) != 100 || lines[0] != "entry 0" || lines[99] != "entry 99" {
+			t.Fatalf("expected 100 entries in order, got %q", b.String())
+		}
+	}
+	if err := log.(Flusher).Close(); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("after close")
+	if !strings.HasSuffix(out.String(), "after close\n") {
+		t.Errorf("expected the entries after close to be written synchronously, got %q", out.String())
+	}
+}
+
+func TestAsyncDrop(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{})}
+	log, err := New("test", Options{
+		Format:   TerminalLogFormat,
+		LogLevel: int(logrus.InfoLevel),
+		Output:   w,
+		Async:    &AsyncOptions{BufferSize: 4, Overflow: DropOnOverflow},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		log.Info("entry")
+	}
+	close(w.release)
+	if err := log.(Flusher).Close(); err != nil {
+		t.Fatal(err)
+	}
+	written := strings.Count(w.buf.String(), "entry\n")
+	// one entry may be taken out of the buffer by the blocked write
+	if written < 4 || written > 5 || uint64(written)+log.(Flusher).Dropped() != 20 {
+		t.Errorf("written %d, dropped %d", written, log.(Flusher).Dropped())
+	}
+}
@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// gelfChunkSize is the maximum size of the datagrams of GELF over UDP, the payload of the chunks being smaller.
+	gelfChunkSize = 8192
+	// gelfMaxChunks is the maximum number of chunks of a message accepted by Graylog.
+	gelfMaxChunks = 128
+	// gelfChunkHeaderSize is the size of the magic bytes, the message ID, the sequence number and the sequence count.
+	gelfChunkHeaderSize = 12
+)
+
+// GELFFormatter formats the entries as GELF 1.1 messages, for Graylog. The fields of the entries are sent as additional
+// fields, prefixed with an underscore, and the levels as syslog severities.
+type GELFFormatter struct {
+	// Host is the host of the messages, the host name if empty.
+	Host string
+}
+
+// Format formats the entry as a line of JSON, so that the output can be a file as well as a GELF transport.
+func (f *GELFFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	host := f.Host
+	if host == "" {
+		host = hostname()
+	}
+	shortMessage, fullMessage, _ := strings.Cut(entry.Message, "\n")
+	if shortMessage == "" {
+		shortMessage = "-"
+	}
+	message := map[string]interface{}{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": shortMessage,
+		"timestamp":     float64(entry.Time.UnixNano()/int64(1e6)) / 1e3,
+		"level":         syslogSeverity(entry.Level),
+	}
+	if fullMessage != "" {
+		message["full_message"] = entry.Message
+	}
+	for key, value := range entry.Data {
+		// _id is reserved by Graylog
+		if key == "id" {
+			key = "field_id"
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		message["_"+gelfFieldName(key)] = value
+	}
+	b, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the GELF message: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// gelfFieldName replaces the characters which are not allowed in the names of additional fields with underscores.
+func gelfFieldName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// gelfWriter sends the messages written by the GELFFormatter to a GELF input, a message per write.
+type gelfWriter struct {
+	mu      sync.Mutex
+	network string
+	address string
+	conn    net.Conn
+}
+
+// DialGELF connects to the GELF input of Graylog at the address, over udp or tcp, to be used as the output of the
+// GELFLogFormat, e.g. Options{Format: GELFLogFormat, Output: w}. Messages larger than a datagram are chunked over UDP,
+// and delimited by a null byte over TCP, where the connection is redialed once if a write fails.
+func DialGELF(network, address string) (io.WriteCloser, error) {
+	w := &gelfWriter{network: network, address: address}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *gelfWriter) dial() error {
+	switch w.network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return fmt.Errorf("unsupported GELF network %q, use udp or tcp", w.network)
+	}
+	conn, err := net.DialTimeout(w.network, w.address, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the GELF input at %s: %w", w.address, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *gelfWriter) Write(p []byte) (int, error) {
+	message := bytes.TrimRight(p, "\n")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if strings.HasPrefix(w.network, "udp") {
+		// the datagrams are not acknowledged, the connection is only dialed again once closed
+		if w.conn == nil {
+			if err := w.dial(); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), w.writeChunks(message)
+	}
+	frame := append(append(make([]byte, 0, len(message)+1), message...), 0)
+	if w.conn != nil {
+		if _, err := w.conn.Write(frame); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+	}
+	if err := w.dial(); err != nil {
+		return 0, err
+	}
+	if _, err := w.conn.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeChunks sends the message in one datagram, or in chunks sharing a random message ID if it is too large.
+func (w *gelfWriter) writeChunks(message []byte) error {
+	if len(message) <= gelfChunkSize {
+		_, err := w.conn.Write(message)
+		return err
+	}
+	payload := gelfChunkSize - gelfChunkHeaderSize
+	count := (len(message) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return fmt.Errorf("the GELF message of %d bytes needs more than %d chunks", len(message), gelfMaxChunks)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		chunk := make([]byte, 0, gelfChunkSize)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		end := (i + 1) * payload
+		if end > len(message) {
+			end = len(message)
+		}
+		chunk = append(chunk, message[i*payload:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *gelfWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// syslogSeverity returns the syslog severity of the level, used by GELF and RFC 5424.
+func syslogSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 0 // emergency
+	case logrus.FatalLevel:
+		return 2 // critical
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6 // informational, as logrus has no notice level
+	default:
+		return 7 // debug
+	}
+}
+
+var (
+	hostnameOnce sync.Once
+	hostnameVal  string
+)
+
+// hostname returns the host name of the machine, "-" if it is unknown.
+func hostname() string {
+	hostnameOnce.Do(func() {
+		hostnameVal, _ = os.Hostname()
+		if hostnameVal == "" {
+			hostnameVal = "-"
+		}
+	})
+	return hostnameVal
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGELFFormat(t *testing.T) {
+	var out bytes.Buffer
+	log, err := New("test", Options{Format: GELFLogFormat, LogLevel: int(logrus.InfoLevel), Output: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Warn(ErrController(errTest("lost connection"), "Controller failed"))
+
+	message := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &message); err != nil {
+		t.Fatalf("invalid GELF message %q: %v", out.String(), err)
+	}
+	if message["version"] != "1.1" || message["short_message"] != "lost connection" || message["level"] != float64(4) {
+		t.Errorf("unexpected GELF message %v", message)
+	}
+	if message["_app"] != "test" || message["_code"] != ErrControllerCode {
+		t.Errorf("expected the fields as additional fields, got %v", message)
+	}
+	if _, ok := message["host"].(string); !ok {
+		t.Errorf("expected a host, got %v", message)
+	}
+}
+
+func TestDialGELF(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	w, err := DialGELF("udp", server.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log, err := New("test", Options{Format: GELFLogFormat, LogLevel: int(logrus.InfoLevel), Output: w})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.Info("small message")
+	buf := make([]byte, 65536)
+	_ = server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := server.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(buf[:n]) || !bytes.Contains(buf[:n], []byte("small message")) {
+		t.Errorf("unexpected datagram %q", buf[:n])
+	}
+
+	log.Info(strings.Repeat("x", 3*gelfChunkSize))
+	var payload []byte
+	for i := 0; i < 4; i++ {
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > gelfChunkSize || buf[0] != 0x1e || buf[1] != 0x0f || buf[10] != byte(i) || buf[11] != 4 {
+			t.Fatalf("unexpected chunk header % x of %d bytes", buf[:gelfChunkHeaderSize], n)
+		}
+		payload = append(payload, buf[gelfChunkHeaderSize:n]...)
+	}
+	if !json.Valid(payload) {
+		t.Errorf("expected the chunks to form the message, got %d bytes", len(payload))
+	}
+
+	if _, err := DialGELF("unix", "/dev/null"); err == nil {
+		t.Error("expected an unsupported network to fail")
+	}
+}
+
+func TestGELFWriteAfterClose(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	w, err := DialGELF("udp", server.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte("{\"short_message\":\"after close\"}\n")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 65536)
+	_ = server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := server.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf[:n], []byte("after close")) {
+		t.Errorf("unexpected datagram %q", buf[:n])
+	}
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLevelEnvVar is the variable holding the level, in the environment or in the file watched by WatchLevel.
+const DefaultLevelEnvVar = "LOG_LEVEL"
+
+// DefaultLevelPollInterval is the interval the file of the level is read at unless configured otherwise.
+const DefaultLevelPollInterval = 10 * time.Second
+
+// LevelWatchOptions configures where WatchLevel reads the level from.
+type LevelWatchOptions struct {
+	// EnvVar is the variable holding the level, DefaultLevelEnvVar if empty.
+	// It is read from the environment if no File is set, e.g. for processes sending themselves SIGHUP after a setenv.
+	EnvVar string
+	// File holds the level, e.g. a mounted ConfigMap: either the level itself, or lines of KEY=VALUE including EnvVar.
+	// It is read on the signals and every PollInterval, so that the level changes without signaling the process.
+	File string
+	// PollInterval is the interval of the reads of File, DefaultLevelPollInterval if zero, never if negative.
+	PollInterval time.Duration
+	// Signals trigger a read of the level, SIGHUP if empty.
+	Signals []os.Signal
+	// OnChange, if set, is called after the level changes, e.g. to log the change.
+	OnChange func(from, to logrus.Level)
+	// OnError, if set, is called if the level can't be read, the level is left unchanged.
+	OnError func(err error)
+}
+
+// WatchLevel changes the level of the handler at runtime, so that a misbehaving long-running process, e.g. an adapter,
+// can be debugged without restarting it. The level is read on SIGHUP and from the polled file, as a name, e.g. debug,
+// or as a number, e.g. 5. The level is applied once before WatchLevel returns, which fails if it can't be read then.
+// The watch stops when the context is done.
+func WatchLevel(ctx context.Context, h Handler, opts LevelWatchOptions) error {
+	if opts.EnvVar == "" {
+		opts.EnvVar = DefaultLevelEnvVar
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultLevelPollInterval
+	}
+	if len(opts.Signals) == 0 {
+		opts.Signals = []os.Signal{syscall.SIGHUP}
+	}
+	if err := applyLevel(h, opts); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, opts.Signals...)
+	var poll <-chan time.Time
+	if opts.File != "" && opts.PollInterval > 0 {
+		ticker := time.NewTicker(opts.PollInterval)
+		poll = ticker.C
+		go func() {
+			<-ctx.Done()
+			ticker.Stop()
+		}()
+	}
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			case <-poll:
+			}
+			if err := applyLevel(h, opts); err != nil && opts.OnError != nil {
+				opts.OnError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// applyLevel sets the level read from the source of the options, if any.
+func applyLevel(h Handler, opts LevelWatchOptions) error {
+	value, ok, err := readLevel(opts)
+	if err != nil || !ok {
+		return err
+	}
+	level, err := ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	if from := h.GetLevel(); from != level {
+		h.SetLevel(level)
+		if opts.OnChange != nil {
+			opts.OnChange(from, level)
+		}
+	}
+	return nil
+}
+
+// readLevel returns the level of the file or of the environment, false if it is not set.
+func readLevel(opts LevelWatchOptions) (string, bool, error) {
+	if opts.File == "" {
+		value, ok := os.LookupEnv(opts.EnvVar)
+		return value, ok, nil
+	}
+	data, err := os.ReadFile(opts.File)
+	if err != nil {
+		return "", false, fmt.Errorf("reading the log level from %s: %w", opts.File, err)
+	}
+	if !bytes.Contains(data, []byte("=")) {
+		value := strings.TrimSpace(string(data))
+		return value, value != "", nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if found && strings.TrimSpace(strings.TrimPrefix(key, "export ")) == opts.EnvVar {
+			return strings.Trim(strings.TrimSpace(value), `"'`), true, nil
+		}
+	}
+	return "", false, scanner.Err()
+}
+
+// ParseLevel parses a level by name, e.g. debug, or by number, e.g. 5, as in Options.LogLevel.
+func ParseLevel(value string) (logrus.Level, error) {
+	value = strings.TrimSpace(value)
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < int(logrus.PanicLevel) || n > int(logrus.TraceLevel) {
+			return 0, fmt.Errorf("invalid log level %d, use a level between %d and %d", n, logrus.PanicLevel, logrus.TraceLevel)
+		}
+		return logrus.Level(n), nil
+	}
+	return logrus.ParseLevel(value)
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWatchLevel(t *testing.T) {
+	h, err := New("test", Options{Format: JsonLogFormat, LogLevel: int(logrus.InfoLevel), Output: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "env")
+	if err := os.WriteFile(file, []byte("# adapter settings\nexport LOG_LEVEL=\"warn\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changes := make(chan logrus.Level, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = WatchLevel(ctx, h, LevelWatchOptions{File: file, PollInterval: -1, OnChange: func(from, to logrus.Level) { changes <- to }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.GetLevel() != logrus.WarnLevel {
+		t.Errorf("expected the level of the file to be applied, got %s", h.GetLevel())
+	}
+	<-changes
+
+	if err := os.WriteFile(file, []byte("5"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case level := <-changes:
+		if level != logrus.DebugLevel {
+			t.Errorf("expected the level to change to debug, got %s", level)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the level to change on SIGHUP")
+	}
+
+	if err := WatchLevel(ctx, h, LevelWatchOptions{File: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestSetLevelWithOutputs(t *testing.T) {
+	var primary, file bytes.Buffer
+	h, err := New("test", Options{
+		Format:   TerminalLogFormat,
+		LogLevel: int(logrus.InfoLevel),
+		Output:   &primary,
+		Outputs:  []OutputOptions{{Format: TerminalLogFormat, LogLevel: int(logrus.WarnLevel), Output: &file}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MESHKIT_TEST_LOG_LEVEL", "debug")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := WatchLevel(ctx, h, LevelWatchOptions{EnvVar: "MESHKIT_TEST_LOG_LEVEL"}); err != nil {
+		t.Fatal(err)
+	}
+	if h.GetLevel() != logrus.DebugLevel {
+		t.Errorf("expected the level of the primary output to be debug, got %s", h.GetLevel())
+	}
+	h.Debug("debug message")
+	if primary.String() != "debug message\n" || file.Len() != 0 {
+		t.Errorf("expected the debug message on the primary output only, got %q and %q", primary.String(), file.String())
+	}
+
+	primary.Reset()
+	h.SetLevel(logrus.ErrorLevel)
+	h.Info("info message")
+	h.Warn(ErrController(errTest("warning"), "Controller failed"))
+	if primary.Len() != 0 || file.String() != "warning\n" {
+		t.Errorf("expected the warning on the additional output only, got %q and %q", primary.String(), file.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for value, want := range map[string]logrus.Level{"debug": logrus.DebugLevel, " 2 ": logrus.ErrorLevel, "WARNING": logrus.WarnLevel} {
+		if got, err := ParseLevel(value); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %s, %v", value, got, err)
+		}
+	}
+	if _, err := ParseLevel("9"); err == nil {
+		t.Error("expected an out of range level to fail")
+	}
+}
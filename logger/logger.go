@@ -24,8 +24,22 @@ type Handler interface {
 	DatabaseLogger() gormlogger.Interface
 }
 
+// Flusher is implemented by the handlers returned by New, assert it on a Handler to flush or close an asynchronous logger.
+// Flush, Close and Dropped apply to the asynchronous loggers, see Options.Async, they are no-ops otherwise.
+type Flusher interface {
+	Flush() error
+	Close() error
+	Dropped() uint64
+}
+
 type Logger struct {
 	handler *logrus.Entry
+	// primary is the hook writing to Options.Output if additional outputs are configured.
+	primary *outputHook
+	// outputs are the hooks of the outputs, primary included, if additional outputs are configured.
+	outputs []*outputHook
+	// async queues the writes to the outputs if Options.Async is set.
+	async *asyncWriter
 }
 
 // TerminalFormatter is exported
@@ -38,32 +52,66 @@ func (f *TerminalFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 }
 
 func New(appname string, opts Options) (Handler, error) {
+	if opts.Format == InteractiveTerminalLogFormat {
+		return NewTerminal(appname, opts)
+	}
 	log := logrus.New()
 
-	switch opts.Format {
-	case JsonLogFormat:
-		log.SetFormatter(&logrus.JSONFormatter{
-			TimestampFormat: time.RFC3339,
-		})
-	case SyslogLogFormat:
-		log.SetFormatter(&logrus.TextFormatter{
-			TimestampFormat: time.RFC3339,
-			FullTimestamp:   true,
-		})
-	case TerminalLogFormat:
-		log.SetFormatter(new(TerminalFormatter))
-	}
+	log.SetFormatter(formatter(opts.Format))
 
 	// log.SetReportCaller(true)
-	log.SetOutput(os.Stdout)
-	if opts.Output != nil {
-		log.SetOutput(opts.Output)
+	log.SetLevel(logrus.Level(opts.LogLevel))
+	if err := addRedaction(log, opts.Redaction); err != nil {
+		return nil, err
 	}
 
-	log.SetLevel(logrus.Level(opts.LogLevel))
+	l := &Logger{}
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	if opts.Async != nil {
+		l.async = newAsyncWriter(*opts.Async)
+		opts.Output = l.async.wrap(opts.Output)
+		outputs := make([]OutputOptions, 0, len(opts.Outputs))
+		for _, o := range opts.Outputs {
+			if o.Output != nil {
+				o.Output = l.async.wrap(o.Output)
+			}
+			outputs = append(outputs, o)
+		}
+		opts.Outputs = outputs
+	}
+	log.SetOutput(opts.Output)
+	if len(opts.Outputs) > 0 {
+		l.outputs = teeOutputs(log, opts)
+		l.primary = l.outputs[0]
+	}
 
-	entry := log.WithFields(logrus.Fields{"app": appname})
-	return &Logger{handler: entry}, nil
+	l.handler = log.WithFields(logrus.Fields{"app": appname})
+	return l, nil
+}
+
+// formatter returns the logrus formatter of the format.
+func formatter(format Format) logrus.Formatter {
+	switch format {
+	case SyslogLogFormat:
+		return &logrus.TextFormatter{
+			TimestampFormat: time.RFC3339,
+			FullTimestamp:   true,
+		}
+	case TerminalLogFormat, InteractiveTerminalLogFormat:
+		return new(TerminalFormatter)
+	case GELFLogFormat:
+		return new(GELFFormatter)
+	case RFC5424LogFormat:
+		return new(RFC5424Formatter)
+	case JsonLogFormat:
+		return &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		}
+	default:
+		return new(logrus.TextFormatter)
+	}
 }
 
 func (l *Logger) Error(err error) {
@@ -106,14 +154,32 @@ func (l *Logger) Warn(err error) {
 	}).Log(logrus.WarnLevel, err.Error())
 }
 
+// SetLevel sets the level of the logger, that of the primary output if additional outputs are configured, the
+// additional outputs keeping theirs.
 func (l *Logger) SetLevel(level logrus.Level) {
-	l.handler.Logger.SetLevel(level)
+	if l.primary == nil {
+		l.handler.Logger.SetLevel(level)
+		return
+	}
+	l.primary.setLevel(level)
+	l.handler.Logger.SetLevel(maxLevel(l.outputs))
 }
 
+// GetLevel returns the level of the logger, that of the primary output if additional outputs are configured.
 func (l *Logger) GetLevel() logrus.Level {
+	if l.primary != nil {
+		return l.primary.getLevel()
+	}
 	return l.handler.Logger.GetLevel()
 }
 
 func (l *Logger) UpdateLogOutput(output io.Writer) {
+	if l.async != nil {
+		output = l.async.wrap(output)
+	}
+	if l.primary != nil {
+		l.primary.setOutput(output)
+		return
+	}
 	l.handler.Logger.SetOutput(output)
 }
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRedactionReplacement replaces the redacted values unless Redaction.Replacement is set.
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// DefaultRedactionKeys are the names of fields and parameters holding credentials, matched case-insensitively
+// as part of the name, e.g. token matches X-Auth-Token and access_token.
+var DefaultRedactionKeys = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey", "credential", "private_key", "cookie"}
+
+// Redaction configures the redaction of sensitive values from the messages and fields of the log entries, before they are written.
+// It is a defense in depth against credentials accidentally logged, e.g. by adapters: logging them has to be avoided anyway.
+type Redaction struct {
+	// Keys are the names of the fields whose values are redacted, and of the key-value pairs redacted in messages and string fields,
+	// e.g. password=... or "token": "...". DefaultRedactionKeys are used if empty.
+	Keys []string
+	// Patterns are regular expressions of the values redacted in messages and string fields, e.g. `ghp_[A-Za-z0-9]{36}`.
+	// If a pattern has a group named secret, only the group is replaced, e.g. `https://[^:]+:(?P<secret>[^@]+)@`.
+	Patterns []string
+	// Replacement replaces the redacted values, DefaultRedactionReplacement if empty.
+	Replacement string
+}
+
+// redactor redacts the values configured by a Redaction.
+type redactor struct {
+	keys        []string
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+func newRedactor(r Redaction) (*redactor, error) {
+	keys := r.Keys
+	if len(keys) == 0 {
+		keys = DefaultRedactionKeys
+	}
+	rd := &redactor{replacement: r.Replacement}
+	if rd.replacement == "" {
+		rd.replacement = DefaultRedactionReplacement
+	}
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		rd.keys = append(rd.keys, strings.ToLower(key))
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+	// key=value, key: value, "key": "value" and the schemes of authorization headers, e.g. Authorization: Bearer value
+	keyValue, err := regexp.Compile(`(?i)([\w.-]*(?:` + strings.Join(quoted, "|") + `)[\w.-]*["']?\s*[:=]\s*["']?)(?P<secret>(?:bearer\s+|basic\s+|token\s+)?[^\s"',;&]+)`)
+	if err != nil {
+		return nil, err
+	}
+	rd.patterns = append(rd.patterns, keyValue)
+	for _, p := range r.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		rd.patterns = append(rd.patterns, re)
+	}
+	return rd, nil
+}
+
+// isSensitiveKey returns whether the name of a field contains one of the keys.
+func (rd *redactor) isSensitiveKey(name string) bool {
+	name = strings.ToLower(name)
+	for _, key := range rd.keys {
+		if strings.Contains(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns the text with the matches of the patterns replaced.
+func (rd *redactor) redact(text string) string {
+	for _, re := range rd.patterns {
+		group := re.SubexpIndex("secret")
+		if group == -1 {
+			text = re.ReplaceAllLiteralString(text, rd.replacement)
+			continue
+		}
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			loc := re.FindStringSubmatchIndex(match)
+			if loc == nil || loc[2*group] == -1 {
+				return match
+			}
+			return match[:loc[2*group]] + rd.replacement + match[loc[2*group+1]:]
+		})
+	}
+	return text
+}
+
+// redactionHook redacts the entries before the formatters and the hooks added after it write them.
+type redactionHook struct {
+	redactor *redactor
+}
+
+func (h *redactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactionHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.redactor.redact(entry.Message)
+	for k, v := range entry.Data {
+		if h.redactor.isSensitiveKey(k) {
+			entry.Data[k] = h.redactor.replacement
+			continue
+		}
+		switch value := v.(type) {
+		case string:
+			entry.Data[k] = h.redactor.redact(value)
+		case error:
+			if s := value.Error(); h.redactor.redact(s) != s {
+				entry.Data[k] = h.redactor.redact(s)
+			}
+		case fmt.Stringer:
+			if s := value.String(); h.redactor.redact(s) != s {
+				entry.Data[k] = h.redactor.redact(s)
+			}
+		}
+	}
+	return nil
+}
+
+// addRedaction adds the redaction hook to log if configured, it has to be the first hook added.
+func addRedaction(log *logrus.Logger, r *Redaction) error {
+	if r == nil {
+		return nil
+	}
+	rd, err := newRedactor(*r)
+	if err != nil {
+		return err
+	}
+	log.AddHook(&redactionHook{redactor: rd})
+	return nil
+}
+
+// Redactor returns a function redacting the sensitive values from a text as the log entries are, e.g. to sanitize
+// the logs of other components before they are shared.
+func (r Redaction) Redactor() (func(string) string, error) {
+	rd, err := newRedactor(r)
+	if err != nil {
+		return nil, err
+	}
+	return rd.redact, nil
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FacilityUser is the syslog facility of user-level messages, the default facility of the RFC5424Formatter.
+const FacilityUser = 1
+
+// DefaultStructuredDataID is the ID of the structured data element holding the fields of the entries. The enterprise
+// number 32473 is the one reserved for documentation by RFC 5612, as MeshKit has none.
+const DefaultStructuredDataID = "fields@32473"
+
+// RFC5424Formatter formats the entries as RFC 5424 syslog messages. The app field of the entries, set by New, is the
+// APP-NAME of the messages, and the other fields are the parameters of a structured data element.
+type RFC5424Formatter struct {
+	// Hostname is the HOSTNAME of the messages, the host name if empty.
+	Hostname string
+	// AppName is the APP-NAME of the messages, the app field of the entries if empty.
+	AppName string
+	// Facility of the messages, FacilityUser if zero. Use 16 to 23 for local0 to local7.
+	Facility int
+	// StructuredDataID is the SD-ID of the element of the fields, DefaultStructuredDataID if empty.
+	StructuredDataID string
+}
+
+// Format formats the entry as a line, so that the output can be a file as well as a syslog transport.
+func (f *RFC5424Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	facility := f.Facility
+	if facility == 0 {
+		facility = FacilityUser
+	}
+	host := f.Hostname
+	if host == "" {
+		host = hostname()
+	}
+	app := f.AppName
+	if app == "" {
+		app, _ = entry.Data["app"].(string)
+	}
+	sdID := f.StructuredDataID
+	if sdID == "" {
+		sdID = DefaultStructuredDataID
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d - ", facility*8+syslogSeverity(entry.Level),
+		entry.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(host, 255), syslogHeaderField(app, 48), os.Getpid())
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		if key != "app" || f.AppName != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + sdID)
+		for _, key := range keys {
+			value := entry.Data[key]
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			fmt.Fprintf(&b, " %s=\"%s\"", syslogParamName(key), syslogParamEscaper.Replace(fmt.Sprint(value)))
+		}
+		b.WriteString("]")
+	}
+	if entry.Message != "" {
+		b.WriteString(" " + strings.ReplaceAll(entry.Message, "\n", " "))
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// syslogParamEscaper escapes the characters which have to be escaped in the values of the parameters.
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogHeaderField returns the value as printable ASCII without spaces, truncated to the maximum length of the field.
+func syslogHeaderField(value string, max int) string {
+	if value == "" {
+		return "-"
+	}
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > max {
+		value = value[:max]
+	}
+	return value
+}
+
+// syslogParamName returns the key as a valid name of parameter: printable ASCII, without =, space, ] or ", and at most 32 characters.
+func syslogParamName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogWriter sends the messages written by the RFC5424Formatter to a syslog server, a message per write.
+type syslogWriter struct {
+	mu      sync.Mutex
+	network string
+	address string
+	conn    net.Conn
+}
+
+// DialSyslog connects to the syslog server at the address, over udp or tcp, to be used as the output of the
+// RFC5424LogFormat, e.g. Options{Format: RFC5424LogFormat, Output: w}. The messages are sent a datagram each over UDP,
+// as of RFC 5426, and framed by octet counting over TCP, as of RFC 6587, where the connection is redialed once if a write fails.
+func DialSyslog(network, address string) (io.WriteCloser, error) {
+	w := &syslogWriter{network: network, address: address}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *syslogWriter) dial() error {
+	switch w.network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return fmt.Errorf("unsupported syslog network %q, use udp or tcp", w.network)
+	}
+	conn, err := net.DialTimeout(w.network, w.address, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the syslog server at %s: %w", w.address, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	message := bytes.TrimRight(p, "\n")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	frame := message
+	if strings.HasPrefix(w.network, "tcp") {
+		frame = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+	if w.conn != nil {
+		if _, err := w.conn.Write(frame); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+	}
+	if err := w.dial(); err != nil {
+		return 0, err
+	}
+	if _, err := w.conn.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRFC5424Format(t *testing.T) {
+	var out bytes.Buffer
+	log, err := New("test", Options{Format: RFC5424LogFormat, LogLevel: int(logrus.InfoLevel), Output: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Error(ErrController(errTest(`bad "quote"]`), "Controller failed"))
+
+	header := regexp.MustCompile(`^<11>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z \S+ test \d+ - \[fields@32473 `)
+	line := out.String()
+	if !header.MatchString(line) {
+		t.Fatalf("unexpected syslog message %q", line)
+	}
+	if !strings.Contains(line, `code="`+ErrControllerCode+`"`) || !strings.HasSuffix(line, "] bad \"quote\"]\n") {
+		t.Errorf("expected the fields as structured data, got %q", line)
+	}
+
+	f := &RFC5424Formatter{Hostname: "host name", AppName: "adapter", Facility: 16}
+	b, err := f.Format(&logrus.Entry{Level: logrus.DebugLevel, Time: time.Unix(0, 0), Message: "line\nbreak", Data: logrus.Fields{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); !strings.HasPrefix(got, "<135>1 1970-01-01T00:00:00.000000Z host_name adapter ") || !strings.HasSuffix(got, " - - line break\n") {
+		t.Errorf("unexpected syslog message %q", got)
+	}
+	b, err = f.Format(&logrus.Entry{Time: time.Unix(0, 0), Data: logrus.Fields{"a b": `x"]\`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); !strings.HasSuffix(got, ` [fields@32473 a_b="x\"\]\\"]`+"\n") {
+		t.Errorf("expected escaped parameters, got %q", got)
+	}
+}
+
+func TestDialSyslog(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	w, err := DialSyslog("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log, err := New("test", Options{Format: RFC5424LogFormat, LogLevel: int(logrus.InfoLevel), Output: w})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := server.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	log.Info("first")
+	log.Info("second")
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"first", "second"} {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			t.Fatalf("expected octet counting, got %q", length)
+		}
+		message := make([]byte, n)
+		if _, err := io.ReadFull(r, message); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(message), "<14>1 ") || !strings.HasSuffix(string(message), " "+want) {
+			t.Errorf("unexpected syslog message %q", message)
+		}
+	}
+}
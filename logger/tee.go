@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// outputHook writes the log entries up to its level to an output, formatted by its formatter.
+// It is registered for all levels, as logrus reads the levels of the hooks once, so that its level can change.
+type outputHook struct {
+	mu        sync.Mutex
+	level     logrus.Level
+	formatter logrus.Formatter
+	out       io.Writer
+}
+
+func (h *outputHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *outputHook) Fire(entry *logrus.Entry) error {
+	if entry.Level > h.getLevel() {
+		return nil
+	}
+	b, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.out.Write(b)
+	return err
+}
+
+func (h *outputHook) getLevel() logrus.Level {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.level
+}
+
+func (h *outputHook) setLevel(level logrus.Level) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.level = level
+}
+
+func (h *outputHook) setOutput(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.out = w
+}
+
+// teeOutputs writes the entries of log to the output and the additional outputs of opts, as hooks.
+// The level of log is raised to the highest level of the outputs, as it caps the levels of all hooks.
+// It returns the hooks of the outputs, the first being the one of the primary output.
+func teeOutputs(log *logrus.Logger, opts Options) []*outputHook {
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	hooks := []*outputHook{newOutputHook(opts.Format, opts.LogLevel, out)}
+	for _, o := range opts.Outputs {
+		if o.Output == nil {
+			continue
+		}
+		hooks = append(hooks, newOutputHook(o.Format, o.LogLevel, o.Output))
+	}
+	for _, hook := range hooks {
+		log.AddHook(hook)
+	}
+	log.SetOutput(io.Discard)
+	log.SetLevel(maxLevel(hooks))
+	return hooks
+}
+
+// maxLevel returns the highest level of the hooks.
+func maxLevel(hooks []*outputHook) logrus.Level {
+	level := logrus.PanicLevel
+	for _, hook := range hooks {
+		if l := hook.getLevel(); l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+func newOutputHook(format Format, level int, out io.Writer) *outputHook {
+	l := logrus.Level(level)
+	if l > logrus.TraceLevel {
+		l = logrus.TraceLevel
+	}
+	return &outputHook{level: l, formatter: formatter(format), out: out}
+}
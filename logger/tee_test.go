@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMultipleOutputs(t *testing.T) {
+	var stderr, file bytes.Buffer
+	log, err := New("test", Options{
+		Format:   TerminalLogFormat,
+		LogLevel: int(logrus.WarnLevel),
+		Output:   &stderr,
+		Outputs: []OutputOptions{
+			{Format: JsonLogFormat, LogLevel: int(logrus.DebugLevel), Output: &file},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Debug("debug message")
+	log.Info("info message")
+	log.Warn(ErrController(errTest("lost connection"), "Controller failed"))
+
+	if got, want := stderr.String(), "lost connection\n"; got != want {
+		t.Errorf("primary output = %q; want %q", got, want)
+	}
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d JSON lines; want 3: %q", len(lines), file.String())
+	}
+	for i, want := range []string{"debug message", "info message", "lost connection"} {
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", lines[i], err)
+		}
+		if entry["msg"] != want || entry["app"] != "test" {
+			t.Errorf("entry %d = %v; want msg %q", i, entry, want)
+		}
+	}
+
+	var updated bytes.Buffer
+	log.UpdateLogOutput(&updated)
+	log.Warn(ErrController(errTest("again"), "Controller failed"))
+	if updated.String() != "again\n" || strings.Contains(stderr.String(), "again") {
+		t.Errorf("primary output not updated: %q, %q", updated.String(), stderr.String())
+	}
+}
+
+type errTest string
+
+func (e errTest) Error() string { return string(e) }
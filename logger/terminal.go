@@ -0,0 +1,319 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorGray   = "\033[90m"
+
+	clearLine = "\r\033[K"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const spinnerInterval = 100 * time.Millisecond
+
+// Terminal is a Handler for interactive command line tools, e.g. mesheryctl.
+// Besides logging, it reports the progress of steps using spinners, and prints a summary of the steps.
+//
+// Colors and spinners are only used if the output is a terminal, otherwise plain text is written,
+// one line per log entry and per started or finished step.
+type Terminal struct {
+	*Logger
+
+	out io.Writer
+	// interactive is read by the formatter of the log entries too, which runs outside of mu
+	interactive atomic.Bool
+
+	mu         sync.Mutex
+	start      time.Time
+	totalSteps int
+	steps      []*Step
+	active     *Step
+	frame      int
+	// stop is closed to stop the spinner of the active step
+	stop chan struct{}
+}
+
+// Step is a unit of work whose progress is reported by the Terminal.
+type Step struct {
+	terminal *Terminal
+	index    int
+	title    string
+	start    time.Time
+	duration time.Duration
+	done     bool
+	err      error
+}
+
+// NewTerminal returns a Terminal writing to opts.Output, or to os.Stdout if unset.
+// opts.Format is ignored.
+func NewTerminal(appname string, opts Options) (*Terminal, error) {
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	t := &Terminal{
+		out:   out,
+		start: time.Now(),
+	}
+	t.interactive.Store(isTerminal(out))
+
+	log := logrus.New()
+	log.SetFormatter(&interactiveFormatter{terminal: t})
+	log.SetOutput(&terminalWriter{terminal: t})
+	log.SetLevel(logrus.Level(opts.LogLevel))
+	if err := addRedaction(log, opts.Redaction); err != nil {
+		return nil, err
+	}
+	t.Logger = &Logger{handler: log.WithFields(logrus.Fields{"app": appname})}
+	return t, nil
+}
+
+// isTerminal reports whether w is a terminal supporting colors and cursor movement.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return os.Getenv("TERM") != "dumb"
+}
+
+// UpdateLogOutput changes the output, the interactive mode is detected again for the new output.
+func (t *Terminal) UpdateLogOutput(output io.Writer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out = output
+	t.interactive.Store(isTerminal(output))
+}
+
+// SetTotalSteps sets the number of steps expected, the steps are numbered as e.g. [2/5] then.
+func (t *Terminal) SetTotalSteps(total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.totalSteps = total
+}
+
+// StartStep starts a step, finishing the active step if any. Finish the step using Done or Fail.
+func (t *Terminal) StartStep(title string) *Step {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active != nil {
+		t.finishLocked(t.active, nil)
+	}
+	s := &Step{terminal: t, index: len(t.steps) + 1, title: title, start: time.Now()}
+	t.steps = append(t.steps, s)
+	t.active = s
+	if t.interactive.Load() {
+		t.frame = 0
+		t.drawLocked()
+		t.stop = make(chan struct{})
+		go t.spin(t.stop)
+	} else {
+		fmt.Fprintf(t.out, "%s%s ...\n", s.prefix(t.totalSteps), s.title)
+	}
+	return s
+}
+
+// Update changes the title of the step.
+func (s *Step) Update(title string) {
+	t := s.terminal
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s.title = title
+	if t.active == s && t.interactive.Load() {
+		t.drawLocked()
+	}
+}
+
+// Done marks the step as succeeded.
+func (s *Step) Done() {
+	s.finish(nil)
+}
+
+// Fail marks the step as failed with the error.
+func (s *Step) Fail(err error) {
+	if err == nil {
+		err = fmt.Errorf("failed")
+	}
+	s.finish(err)
+}
+
+// Err returns the error the step failed with, if any.
+func (s *Step) Err() error {
+	s.terminal.mu.Lock()
+	defer s.terminal.mu.Unlock()
+	return s.err
+}
+
+func (s *Step) finish(err error) {
+	t := s.terminal
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finishLocked(s, err)
+}
+
+// finishLocked marks the step as finished and prints its result, t.mu has to be held.
+func (t *Terminal) finishLocked(s *Step, err error) {
+	if s.done {
+		return
+	}
+	s.done = true
+	s.err = err
+	s.duration = time.Since(s.start)
+	if t.active == s {
+		t.active = nil
+		if t.stop != nil {
+			close(t.stop)
+			t.stop = nil
+		}
+	}
+	if t.interactive.Load() {
+		fmt.Fprint(t.out, clearLine)
+	}
+	fmt.Fprintln(t.out, t.resultLine(s))
+}
+
+// Summary prints the number of succeeded and failed steps, the failed steps with their error, and the total duration.
+// It finishes the active step, if any.
+func (t *Terminal) Summary() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active != nil {
+		t.finishLocked(t.active, nil)
+	}
+	var failed []*Step
+	for _, s := range t.steps {
+		if s.err != nil {
+			failed = append(failed, s)
+		}
+	}
+	status := t.colorize(colorGreen, "Succeeded")
+	if len(failed) > 0 {
+		status = t.colorize(colorRed, "Failed")
+	}
+	fmt.Fprintf(t.out, "%s: %d of %d steps succeeded in %s\n", status, len(t.steps)-len(failed), len(t.steps), time.Since(t.start).Round(time.Millisecond))
+	for _, s := range failed {
+		fmt.Fprintf(t.out, "  %s %s: %v\n", t.colorize(colorRed, "✖"), s.title, s.err)
+	}
+}
+
+func (t *Terminal) spin(stop chan struct{}) {
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			t.mu.Lock()
+			// the step may have finished while waiting for the lock, the line is not drawn anymore then
+			select {
+			case <-stop:
+				t.mu.Unlock()
+				return
+			default:
+			}
+			t.frame = (t.frame + 1) % len(spinnerFrames)
+			t.drawLocked()
+			t.mu.Unlock()
+		}
+	}
+}
+
+// drawLocked redraws the line of the active step, t.mu has to be held.
+func (t *Terminal) drawLocked() {
+	if t.active == nil {
+		return
+	}
+	fmt.Fprintf(t.out, "%s%s %s%s", clearLine, t.colorize(colorBlue, spinnerFrames[t.frame]), t.active.prefix(t.totalSteps), t.active.title)
+}
+
+func (t *Terminal) resultLine(s *Step) string {
+	duration := t.colorize(colorGray, fmt.Sprintf("(%s)", s.duration.Round(time.Millisecond)))
+	if s.err != nil {
+		return fmt.Sprintf("%s %s%s: %v %s", t.colorize(colorRed, "✖"), s.prefix(t.totalSteps), s.title, s.err, duration)
+	}
+	return fmt.Sprintf("%s %s%s %s", t.colorize(colorGreen, "✔"), s.prefix(t.totalSteps), s.title, duration)
+}
+
+func (t *Terminal) colorize(color, text string) string {
+	if !t.interactive.Load() {
+		return text
+	}
+	return color + text + colorReset
+}
+
+func (s *Step) prefix(total int) string {
+	if total > 0 {
+		return fmt.Sprintf("[%d/%d] ", s.index, total)
+	}
+	return ""
+}
+
+// terminalWriter writes log entries above the line of the active step, which is redrawn afterwards.
+type terminalWriter struct {
+	terminal *Terminal
+}
+
+func (w *terminalWriter) Write(p []byte) (int, error) {
+	t := w.terminal
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.interactive.Load() && t.active != nil {
+		fmt.Fprint(t.out, clearLine)
+		defer t.drawLocked()
+	}
+	return t.out.Write(p)
+}
+
+// interactiveFormatter formats log entries as the TerminalFormatter, prefixed by a colored symbol of the level.
+// The suggested remediation of MeshKit errors is added to warnings and errors.
+type interactiveFormatter struct {
+	terminal *Terminal
+}
+
+func (f *interactiveFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	t := f.terminal
+	var symbol string
+	switch entry.Level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		symbol = t.colorize(colorRed, "✖")
+	case logrus.WarnLevel:
+		symbol = t.colorize(colorYellow, "⚠")
+	case logrus.InfoLevel:
+		symbol = t.colorize(colorBlue, "ℹ")
+	default:
+		symbol = t.colorize(colorGray, "·")
+	}
+	var sb strings.Builder
+	sb.WriteString(symbol)
+	sb.WriteString(" ")
+	sb.WriteString(entry.Message)
+	sb.WriteString("\n")
+	if remedy, ok := entry.Data["suggested-remediation"].(string); ok && remedy != "" && remedy != strings.Join(errors.NoneString, "") {
+		sb.WriteString("  ")
+		sb.WriteString(t.colorize(colorGray, "Remedy: "+remedy))
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTerminalPlainOutput(t *testing.T) {
+	var buf bytes.Buffer
+	h, err := New("test", Options{Format: InteractiveTerminalLogFormat, LogLevel: 4, Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	term, ok := h.(*Terminal)
+	if !ok {
+		t.Fatalf("New() = %T; want *Terminal", h)
+	}
+	term.SetTotalSteps(2)
+	term.StartStep("Deploying adapter")
+	term.Info("pulling image")
+	term.StartStep("Verifying adapter").Fail(fmt.Errorf("timeout"))
+	term.Summary()
+
+	out := buf.String()
+	if strings.Contains(out, "\033[") {
+		t.Errorf("plain output contains escape sequences: %q", out)
+	}
+	for _, want := range []string{
+		"[1/2] Deploying adapter ...\n",
+		"ℹ pulling image\n",
+		"✔ [1/2] Deploying adapter",
+		"✖ [2/2] Verifying adapter: timeout",
+		"Failed: 1 of 2 steps succeeded",
+		"  ✖ Verifying adapter: timeout\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestTerminalInteractiveOutput(t *testing.T) {
+	var buf bytes.Buffer
+	term, err := NewTerminal("test", Options{LogLevel: 4, Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	term.interactive.Store(true)
+
+	step := term.StartStep("Deploying adapter")
+	time.Sleep(3 * spinnerInterval)
+	term.Info("pulling image")
+	step.Done()
+	term.Summary()
+
+	out := buf.String()
+	for _, want := range []string{clearLine, spinnerFrames[1], colorGreen + "✔" + colorReset, "Succeeded" + colorReset + ": 1 of 1 steps succeeded"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+	if !strings.Contains(out, clearLine+colorBlue+"ℹ"+colorReset+" pulling image\n") {
+		t.Errorf("log entry is not written above the step: %q", out)
+	}
+}
+
+// TestTerminalConcurrentOutputUpdate is meant to be run with -race, the log entries being formatted while the output changes.
+func TestTerminalConcurrentOutputUpdate(t *testing.T) {
+	term, err := NewTerminal("test", Options{LogLevel: 4, Output: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			term.UpdateLogOutput(&bytes.Buffer{})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		term.Info("pulling image")
+	}
+	<-done
+}
+
+// TestTerminalConcurrentStartStep is meant to be run with -race, the steps being started while the active one finishes.
+func TestTerminalConcurrentStartStep(t *testing.T) {
+	var buf bytes.Buffer
+	term, err := NewTerminal("test", Options{LogLevel: 4, Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	term.interactive.Store(true)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			term.StartStep(fmt.Sprintf("step %d", i))
+		}(i)
+	}
+	wg.Wait()
+	term.Summary()
+
+	// every step started is finished by the next one or by the summary
+	if got := strings.Count(buf.String(), "✔"); got != 50 {
+		t.Errorf("expected 50 finished steps, got %d in %q", got, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Succeeded"+colorReset+": 50 of 50 steps succeeded") {
+		t.Errorf("unexpected summary in %q", buf.String())
+	}
+}
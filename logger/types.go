@@ -8,6 +8,14 @@ const (
 	JsonLogFormat = iota
 	SyslogLogFormat
 	TerminalLogFormat
+	// InteractiveTerminalLogFormat selects the Terminal handler, with colors, spinners and step progress.
+	// It falls back to plain output if the output is not a terminal.
+	InteractiveTerminalLogFormat
+	// GELFLogFormat formats the entries as GELF messages, for Graylog, sent by an Output of DialGELF.
+	GELFLogFormat
+	// RFC5424LogFormat formats the entries as RFC 5424 syslog messages, sent by an Output of DialSyslog.
+	// The SyslogLogFormat is a text format with timestamps, not of syslog.
+	RFC5424LogFormat
 )
 
 type Format int
@@ -16,4 +24,21 @@ type Options struct {
 	Format   Format
 	LogLevel int
 	Output   io.Writer
+	// Outputs are written in addition to Output, each one with its own format and level,
+	// e.g. warnings in the terminal format to os.Stderr, and debug logs in JSON to a file.
+	// The InteractiveTerminalLogFormat is not supported for additional outputs, and ignores them.
+	Outputs []OutputOptions
+	// Redaction, if set, redacts sensitive values, e.g. tokens and passwords, from the entries written to all outputs.
+	Redaction *Redaction
+	// Async, if set, queues the entries and writes them from a background goroutine, to keep logging off the hot path.
+	// Flush and Close the Logger to write the queued entries, e.g. before the process exits.
+	// The InteractiveTerminalLogFormat writes synchronously.
+	Async *AsyncOptions
+}
+
+// OutputOptions configures an additional output of a logger.
+type OutputOptions struct {
+	Format   Format
+	LogLevel int
+	Output   io.Writer
 }
@@ -0,0 +1,31 @@
+package events
+
+import "github.com/layer5io/meshkit/errors"
+
+const (
+	ErrPersistEventCode = "meshkit-11275"
+	ErrGetEventsCode    = "meshkit-11276"
+	ErrUpdateEventCode  = "meshkit-11277"
+	ErrDeleteEventCode  = "meshkit-11278"
+	ErrPublishEventCode = "meshkit-11279"
+)
+
+func ErrPersistEvent(err error) error {
+	return errors.New(ErrPersistEventCode, errors.Alert, []string{"Could not persist the event"}, []string{err.Error()}, []string{"The database is not reachable", "The status of the event is not supported"}, []string{"Make sure the database is reachable and the event is valid"})
+}
+
+func ErrGetEvents(err error) error {
+	return errors.New(ErrGetEventsCode, errors.Alert, []string{"Could not fetch the events"}, []string{err.Error()}, []string{"The database is not reachable", "The filter sorts on an unsupported field"}, []string{"Make sure the database is reachable and the filter is valid"})
+}
+
+func ErrUpdateEvent(err error, id string) error {
+	return errors.New(ErrUpdateEventCode, errors.Alert, []string{"Could not update the event " + id}, []string{err.Error()}, []string{"The event does not exist", "The status of the event is not supported"}, []string{"Make sure the event exists and the status is read or unread"})
+}
+
+func ErrDeleteEvent(err error, id string) error {
+	return errors.New(ErrDeleteEventCode, errors.Alert, []string{"Could not delete the event " + id}, []string{err.Error()}, []string{"The event does not exist", "The database is not reachable"}, []string{"Make sure the event exists and the database is reachable"})
+}
+
+func ErrPublishEvent(err error) error {
+	return errors.New(ErrPublishEventCode, errors.Alert, []string{"Could not publish the event"}, []string{err.Error()}, []string{"The broker is not connected"}, []string{"Make sure the broker is reachable"})
+}
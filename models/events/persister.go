@@ -0,0 +1,145 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+)
+
+// sortableFields are the columns the events can be sorted on
+var sortableFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"severity":   true,
+	"category":   true,
+	"action":     true,
+	"status":     true,
+}
+
+// EventsResponse is a page of the events matching an EventsFilter
+type EventsResponse struct {
+	Events     []*Event `json:"events"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	TotalCount int64    `json:"total_count"`
+}
+
+// EventsPersister stores the events through the database handler
+type EventsPersister struct {
+	DB *database.Handler
+}
+
+// NewEventsPersister returns an EventsPersister, migrating the events table
+func NewEventsPersister(db *database.Handler) (*EventsPersister, error) {
+	if err := db.AutoMigrate(&Event{}); err != nil {
+		return nil, ErrPersistEvent(err)
+	}
+	return &EventsPersister{DB: db}, nil
+}
+
+// PersistEvent stores the event, its ID is generated
+func (ep *EventsPersister) PersistEvent(event *Event) error {
+	ep.DB.Lock()
+	defer ep.DB.Unlock()
+	if err := ep.DB.Create(event).Error; err != nil {
+		return ErrPersistEvent(err)
+	}
+	return nil
+}
+
+// GetEvents returns the events matching the filter. When userID is not nil,
+// only the events of the user are returned.
+func (ep *EventsPersister) GetEvents(filter *EventsFilter, userID *uuid.UUID) (*EventsResponse, error) {
+	if filter == nil {
+		filter = &EventsFilter{}
+	}
+	query := ep.DB.Model(&Event{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if len(filter.Category) > 0 {
+		query = query.Where("category IN ?", filter.Category)
+	}
+	if len(filter.Action) > 0 {
+		query = query.Where("action IN ?", filter.Action)
+	}
+	if len(filter.Severity) > 0 {
+		query = query.Where("severity IN ?", filter.Severity)
+	}
+	if len(filter.ActedUpon) > 0 {
+		query = query.Where("acted_upon IN ?", filter.ActedUpon)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Search != "" {
+		query = query.Where("LOWER(description) LIKE ?", "%"+strings.ToLower(filter.Search)+"%")
+	}
+
+	sortOn := filter.SortOn
+	if sortOn == "" {
+		sortOn = "created_at"
+	}
+	if !sortableFields[sortOn] {
+		return nil, ErrGetEvents(fmt.Errorf("events can't be sorted on %s", sortOn))
+	}
+	order := "asc"
+	if strings.EqualFold(filter.Order, "desc") {
+		order = "desc"
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, ErrGetEvents(err)
+	}
+	events := make([]*Event, 0)
+	query = query.Order(sortOn + " " + order).Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, ErrGetEvents(err)
+	}
+
+	page := 0
+	if filter.Limit > 0 {
+		page = filter.Offset / filter.Limit
+	}
+	return &EventsResponse{
+		Events:     events,
+		Page:       page,
+		PageSize:   filter.Limit,
+		TotalCount: count,
+	}, nil
+}
+
+// UpdateEventStatus sets the status of the event and returns the updated event
+func (ep *EventsPersister) UpdateEventStatus(id uuid.UUID, status EventStatus) (*Event, error) {
+	ep.DB.Lock()
+	defer ep.DB.Unlock()
+	event := &Event{}
+	if err := ep.DB.First(event, "id = ?", id).Error; err != nil {
+		return nil, ErrUpdateEvent(err, id.String())
+	}
+	event.Status = status
+	if err := ep.DB.Save(event).Error; err != nil {
+		return nil, ErrUpdateEvent(err, id.String())
+	}
+	return event, nil
+}
+
+// DeleteEvent deletes the event
+func (ep *EventsPersister) DeleteEvent(id uuid.UUID) error {
+	ep.DB.Lock()
+	defer ep.DB.Unlock()
+	result := ep.DB.Delete(&Event{}, "id = ?", id)
+	if result.Error != nil {
+		return ErrDeleteEvent(result.Error, id.String())
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeleteEvent(fmt.Errorf("event not found"), id.String())
+	}
+	return nil
+}
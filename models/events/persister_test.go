@@ -0,0 +1,89 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/broker"
+	"github.com/layer5io/meshkit/database"
+)
+
+func TestEventsPersister(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: "file::memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	persister, err := NewEventsPersister(&db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := uuid.NewV4()
+	events := []*Event{
+		NewEvent().FromUser(user).WithCategory("pattern").WithAction("deploy").WithSeverity(Success).WithDescription("Deployed the design").Build(),
+		NewEvent().FromUser(user).WithCategory("pattern").WithAction("undeploy").WithSeverity(Error).WithDescription("Could not undeploy the design").Build(),
+		NewEvent().WithCategory("connection").WithAction("register").WithSeverity(Informational).WithMetadata(map[string]interface{}{"kind": "kubernetes"}).Build(),
+	}
+	for _, event := range events {
+		if err := persister.PersistEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := persister.GetEvents(&EventsFilter{Category: []string{"pattern"}, SortOn: "action", Order: "desc"}, &user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalCount != 2 || res.Events[0].Action != "undeploy" {
+		t.Fatalf("unexpected events %+v", res)
+	}
+	res, err = persister.GetEvents(&EventsFilter{Search: "DESIGN", Limit: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalCount != 2 || len(res.Events) != 1 {
+		t.Fatalf("expected a page of one of the two events, got %+v", res)
+	}
+	if _, err := persister.GetEvents(&EventsFilter{SortOn: "description; DROP TABLE events"}, nil); err == nil {
+		t.Fatal("expected sorting on an unsupported field to fail")
+	}
+
+	updated, err := persister.UpdateEventStatus(events[0].ID, Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != Read {
+		t.Fatalf("expected the event to be read, got %s", updated.Status)
+	}
+	if _, err := persister.UpdateEventStatus(events[0].ID, "archived"); err == nil {
+		t.Fatal("expected an unsupported status to fail")
+	}
+	if err := persister.DeleteEvent(events[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := persister.DeleteEvent(events[1].ID); err == nil {
+		t.Fatal("expected deleting a deleted event to fail")
+	}
+	res, err = persister.GetEvents(&EventsFilter{Severity: []string{string(Informational)}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalCount != 1 || res.Events[0].Metadata["kind"] != "kubernetes" {
+		t.Fatalf("unexpected events %+v", res)
+	}
+}
+
+func TestEventFromMessage(t *testing.T) {
+	event := NewEvent().WithCategory("pattern").WithAction("deploy").Build()
+	data, _ := json.Marshal(event)
+	var object map[string]interface{}
+	_ = json.Unmarshal(data, &object)
+
+	decoded := eventFromMessage(&broker.Message{ObjectType: broker.EventObject, Object: object})
+	if decoded == nil || decoded.OperationID != event.OperationID || decoded.Action != "deploy" {
+		t.Fatalf("unexpected event %+v", decoded)
+	}
+	if eventFromMessage(&broker.Message{ObjectType: broker.MeshSync, Object: object}) != nil {
+		t.Fatal("expected the messages which aren't events to be ignored")
+	}
+}
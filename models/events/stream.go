@@ -0,0 +1,57 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/layer5io/meshkit/broker"
+)
+
+// PublishEvent publishes the event on the subject of the broker
+func PublishEvent(h broker.Handler, subject string, event *Event) error {
+	err := h.Publish(subject, &broker.Message{
+		ObjectType: broker.EventObject,
+		EventType:  broker.Add,
+		Object:     event,
+	})
+	if err != nil {
+		return ErrPublishEvent(err)
+	}
+	return nil
+}
+
+// SubscribeEvents sends the events published on the subject of the broker to the channel.
+// The messages which aren't events are ignored.
+func SubscribeEvents(h broker.Handler, subject, queue string, events chan<- *Event) error {
+	messages := make(chan *broker.Message)
+	if err := h.SubscribeWithChannel(subject, queue, messages); err != nil {
+		return err
+	}
+	go func() {
+		for msg := range messages {
+			if event := eventFromMessage(msg); event != nil {
+				events <- event
+			}
+		}
+	}()
+	return nil
+}
+
+// eventFromMessage returns the event of the message, decoded as a map by the brokers
+// serializing the messages.
+func eventFromMessage(msg *broker.Message) *Event {
+	if msg == nil || msg.ObjectType != broker.EventObject {
+		return nil
+	}
+	if event, ok := msg.Object.(*Event); ok {
+		return event
+	}
+	data, err := json.Marshal(msg.Object)
+	if err != nil {
+		return nil
+	}
+	event := &Event{}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil
+	}
+	return event
+}
@@ -46,6 +46,9 @@ func (r *RelationshipDefinition) GetEntityDetail() string {
 }
 
 func (r *RelationshipDefinition) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error) {
+	if err := r.Validate(); err != nil {
+		return uuid.UUID{}, err
+	}
 	r.ID = uuid.New()
 	mid, err := r.Model.Create(db, hostID)
 	if err != nil {
@@ -59,6 +62,19 @@ func (r *RelationshipDefinition) Create(db *database.Handler, hostID uuid.UUID)
 	return r.ID, err
 }
 
+// Update updates the stored definition with the fields of r.
+func (r *RelationshipDefinition) Update(db *database.Handler) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	return db.Omit(clause.Associations).Model(&RelationshipDefinition{ID: r.ID}).Select("*").Omit("id", "model_id", "created_at").Updates(r).Error
+}
+
+// Delete deletes the stored definition.
+func (r *RelationshipDefinition) Delete(db *database.Handler) error {
+	return db.Delete(&RelationshipDefinition{ID: r.ID}).Error
+}
+
 func (m *RelationshipDefinition) UpdateStatus(db *database.Handler, status entity.EntityStatus) error {
 	return nil
 }
@@ -0,0 +1,167 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+// Patch strategies of the selectors, how the mutated component is patched with the values of the mutator component.
+const (
+	PatchStrategyReplace        = "replace"
+	PatchStrategyMerge          = "merge"
+	PatchStrategyStrategicMerge = "strategic"
+	PatchStrategyJSONPatch      = "json"
+)
+
+// SelectorWildcard matches any kind or model.
+const SelectorWildcard = "*"
+
+// SelectorSet is a set of selectors of a relationship: the relationship applies from the components matching a from selector
+// to the components matching a to selector, unless the pair is matched by the deny selectors.
+type SelectorSet struct {
+	Allow Selectors  `json:"allow"`
+	Deny  *Selectors `json:"deny,omitempty"`
+}
+
+type Selectors struct {
+	From []Selector `json:"from"`
+	To   []Selector `json:"to"`
+}
+
+// Selector matches components by kind and model, SelectorWildcard or empty matching any.
+type Selector struct {
+	Kind  string `json:"kind"`
+	Model string `json:"model,omitempty"`
+	Patch *Patch `json:"patch,omitempty"`
+}
+
+// Patch describes how the component matched by a to selector is patched with the values of the component matched by the from selector.
+type Patch struct {
+	PatchStrategy string `json:"patchStrategy,omitempty"`
+	// MutatorRef are the paths of the values of the mutator component, e.g. [["configuration", "spec", "selector"]].
+	MutatorRef [][]string `json:"mutatorRef,omitempty"`
+	// MutatedRef are the paths of the values of the mutated component, in the order of the MutatorRef.
+	MutatedRef [][]string `json:"mutatedRef,omitempty"`
+}
+
+// RelationshipMatch is a pair of components the relationship applies to, see RelationshipDefinition.Match.
+type RelationshipMatch struct {
+	// Selector is the index of the selector set matching the pair.
+	Selector int
+	// From and To are the indices of the components matched by the from and the to selectors.
+	From, To int
+}
+
+// GetSelectorSets decodes the selectors of the definition.
+func (r *RelationshipDefinition) GetSelectorSets() ([]SelectorSet, error) {
+	byt, err := json.Marshal(r.Selectors)
+	if err != nil {
+		return nil, err
+	}
+	var sets []SelectorSet
+	if err := json.Unmarshal(byt, &sets); err != nil {
+		return nil, err
+	}
+	return sets, nil
+}
+
+// Validate checks that the definition has a kind and valid selectors: each selector set allows relationships
+// from and to kinds, and the patches of the selectors use a known strategy and reference as many values on both sides.
+func (r *RelationshipDefinition) Validate() error {
+	if r.Kind == "" {
+		return v1beta1.ErrInvalidDefinition(fmt.Errorf("kind is empty"), string(r.Type()))
+	}
+	sets, err := r.GetSelectorSets()
+	if err != nil {
+		return v1beta1.ErrInvalidDefinition(fmt.Errorf("invalid selectors: %w", err), string(r.Type()))
+	}
+	for i, set := range sets {
+		if len(set.Allow.From) == 0 || len(set.Allow.To) == 0 {
+			return v1beta1.ErrInvalidDefinition(fmt.Errorf("selector %d: allow needs from and to selectors", i), string(r.Type()))
+		}
+		selectors := append(append([]Selector{}, set.Allow.From...), set.Allow.To...)
+		if set.Deny != nil {
+			selectors = append(append(selectors, set.Deny.From...), set.Deny.To...)
+		}
+		for _, s := range selectors {
+			if err := s.validate(); err != nil {
+				return v1beta1.ErrInvalidDefinition(fmt.Errorf("selector %d: %w", i, err), string(r.Type()))
+			}
+		}
+	}
+	return nil
+}
+
+func (s Selector) validate() error {
+	if s.Kind == "" {
+		return fmt.Errorf("a selector has no kind, use %q to match any kind", SelectorWildcard)
+	}
+	if s.Patch == nil {
+		return nil
+	}
+	switch s.Patch.PatchStrategy {
+	case "", PatchStrategyReplace, PatchStrategyMerge, PatchStrategyStrategicMerge, PatchStrategyJSONPatch:
+	default:
+		return fmt.Errorf("the selector of %s has the unknown patch strategy %q", s.Kind, s.Patch.PatchStrategy)
+	}
+	for _, refs := range [][][]string{s.Patch.MutatorRef, s.Patch.MutatedRef} {
+		for _, ref := range refs {
+			if len(ref) == 0 {
+				return fmt.Errorf("the selector of %s has an empty reference", s.Kind)
+			}
+		}
+	}
+	if len(s.Patch.MutatorRef) > 0 && len(s.Patch.MutatedRef) > 0 && len(s.Patch.MutatorRef) != len(s.Patch.MutatedRef) {
+		return fmt.Errorf("the selector of %s has %d mutator and %d mutated references", s.Kind, len(s.Patch.MutatorRef), len(s.Patch.MutatedRef))
+	}
+	return nil
+}
+
+// Matches reports whether the selector matches the component.
+func (s Selector) Matches(c v1beta1.ComponentDefinition) bool {
+	return matchSelectorField(s.Kind, c.Component.Kind) && matchSelectorField(s.Model, c.Model.Name)
+}
+
+func matchSelectorField(selector, value string) bool {
+	return selector == "" || selector == SelectorWildcard || selector == value
+}
+
+func matchAny(selectors []Selector, c v1beta1.ComponentDefinition) bool {
+	for _, s := range selectors {
+		if s.Matches(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the pairs of distinct components the relationship applies to, e.g. to test a definition against
+// sample components before shipping it. Pairs are reported once, for the first selector set matching them.
+func (r *RelationshipDefinition) Match(components []v1beta1.ComponentDefinition) ([]RelationshipMatch, error) {
+	sets, err := r.GetSelectorSets()
+	if err != nil {
+		return nil, v1beta1.ErrInvalidDefinition(fmt.Errorf("invalid selectors: %w", err), string(r.Type()))
+	}
+	var matches []RelationshipMatch
+	matched := map[[2]int]bool{}
+	for i, set := range sets {
+		for from, fromComponent := range components {
+			if !matchAny(set.Allow.From, fromComponent) {
+				continue
+			}
+			for to, toComponent := range components {
+				if from == to || matched[[2]int{from, to}] || !matchAny(set.Allow.To, toComponent) {
+					continue
+				}
+				if set.Deny != nil && matchAny(set.Deny.From, fromComponent) && matchAny(set.Deny.To, toComponent) {
+					continue
+				}
+				matched[[2]int{from, to}] = true
+				matches = append(matches, RelationshipMatch{Selector: i, From: from, To: to})
+			}
+		}
+	}
+	return matches, nil
+}
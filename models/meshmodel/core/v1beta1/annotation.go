@@ -0,0 +1,122 @@
+package v1beta1
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"github.com/layer5io/meshkit/utils"
+	"gorm.io/gorm/clause"
+)
+
+// AnnotationMetadataKey marks component definitions which are annotations in their metadata.
+const AnnotationMetadataKey = "isAnnotation"
+
+// AnnotationDefinition is a purely visual entity of designs, e.g. a shape, a text box or an image on a whiteboard.
+// Unlike components, annotations have no schema and are not deployed.
+//
+// swagger:response AnnotationDefinition
+type AnnotationDefinition struct {
+	ID uuid.UUID `json:"id"`
+	VersionMeta
+	// Kind of the annotation, e.g. rectangle, circle, text, image
+	Kind        string `json:"kind" yaml:"kind"`
+	DisplayName string `json:"displayName" yaml:"displayName" gorm:"displayName"`
+	Description string `json:"description" yaml:"description" gorm:"description"`
+	// Styles are the default styles of annotations of this kind, e.g. shape, background-color, svgColor
+	Styles   map[string]interface{} `json:"styles,omitempty" yaml:"styles" gorm:"type:bytes;serializer:json"`
+	Status   entity.EntityStatus    `json:"status" yaml:"status" gorm:"status"`
+	Metadata map[string]interface{} `json:"metadata" yaml:"metadata" gorm:"type:bytes;serializer:json"`
+	ModelID  uuid.UUID              `json:"-" gorm:"index:idx_annotation_definition_dbs_model_id,column:model_id"`
+	Model    Model                  `json:"model" gorm:"foreignKey:ModelID;references:ID"`
+}
+
+func (a AnnotationDefinition) TableName() string {
+	return "annotation_definition_dbs"
+}
+
+func (a AnnotationDefinition) Type() entity.EntityType {
+	return entity.AnnotationDefinition
+}
+
+func (a AnnotationDefinition) GetID() uuid.UUID {
+	return a.ID
+}
+
+func (a *AnnotationDefinition) GetEntityDetail() string {
+	return fmt.Sprintf("type: %s, definition version: %s, kind: %s, model: %s, version: %s", a.Type(), a.Version, a.Kind, a.Model.Name, a.Model.Model.Version)
+}
+
+func (a *AnnotationDefinition) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error) {
+	if a.Kind == "" {
+		return uuid.UUID{}, ErrInvalidDefinition(fmt.Errorf("kind is empty"), string(a.Type()))
+	}
+	a.ID = uuid.New()
+	mid, err := a.Model.Create(db, hostID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	a.ModelID = mid
+	if a.Status == "" {
+		a.Status = entity.Enabled
+	}
+	err = db.Omit(clause.Associations).Create(&a).Error
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return a.ID, nil
+}
+
+func (a *AnnotationDefinition) UpdateStatus(db *database.Handler, status entity.EntityStatus) error {
+	err := db.Model(&AnnotationDefinition{}).Where("id = ?", a.ID).Update("status", status).Error
+	if err != nil {
+		return entity.ErrUpdateEntityStatus(err, string(a.Type()), status)
+	}
+	return nil
+}
+
+func (a AnnotationDefinition) WriteAnnotationDefinition(annotationDirPath string) error {
+	if a.Kind == "" {
+		return nil
+	}
+	annotationPath := filepath.Join(annotationDirPath, a.Kind+".json")
+	return utils.WriteJSONToFile[AnnotationDefinition](annotationPath, a)
+}
+
+// IsAnnotation reports whether the component is marked as an annotation in its metadata.
+func (c *ComponentDefinition) IsAnnotation() bool {
+	isAnnotation, _ := c.Metadata[AnnotationMetadataKey].(bool)
+	return isAnnotation
+}
+
+// AnnotationFromComponent converts a component marked as an annotation to an annotation definition.
+// The styles of the component in its metadata become the styles of the annotation.
+func AnnotationFromComponent(c ComponentDefinition) (AnnotationDefinition, bool) {
+	if !c.IsAnnotation() {
+		return AnnotationDefinition{}, false
+	}
+	metadata := make(map[string]interface{}, len(c.Metadata))
+	var styles map[string]interface{}
+	for k, v := range c.Metadata {
+		switch k {
+		case AnnotationMetadataKey:
+		case "styles":
+			styles, _ = v.(map[string]interface{})
+		default:
+			metadata[k] = v
+		}
+	}
+	return AnnotationDefinition{
+		ID:          c.ID,
+		VersionMeta: c.VersionMeta,
+		Kind:        c.Component.Kind,
+		DisplayName: c.DisplayName,
+		Description: c.Description,
+		Styles:      styles,
+		Metadata:    metadata,
+		ModelID:     c.ModelID,
+		Model:       c.Model,
+	}, true
+}
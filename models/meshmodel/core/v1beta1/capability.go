@@ -0,0 +1,116 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CapabilitiesMetadataKey is the key of the capabilities in the metadata of component definitions.
+const CapabilitiesMetadataKey = "capabilities"
+
+// Capability is a feature declared by a component, e.g. that it is a workload, or that it can be configured or styled.
+type Capability struct {
+	DisplayName string `json:"displayName" yaml:"displayName"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	// Kind of the capability, e.g. action, mutate, view, interaction
+	Kind    string `json:"kind" yaml:"kind"`
+	Type    string `json:"type" yaml:"type"`
+	SubType string `json:"subType,omitempty" yaml:"subType"`
+	Key     string `json:"key,omitempty" yaml:"key"`
+	// EntityState are the states of the design entity the capability applies to, e.g. declaration, instance
+	EntityState []string               `json:"entityState,omitempty" yaml:"entityState"`
+	Status      string                 `json:"status,omitempty" yaml:"status"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty" yaml:"metadata"`
+}
+
+// Validate checks that the required fields of the capability are set.
+func (c Capability) Validate() error {
+	var missing []string
+	if c.DisplayName == "" {
+		missing = append(missing, "displayName")
+	}
+	if c.Kind == "" {
+		missing = append(missing, "kind")
+	}
+	if c.Type == "" {
+		missing = append(missing, "type")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is a required field", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// CapabilityQuery selects capabilities. Empty fields match any value, and values are compared case-insensitively.
+type CapabilityQuery struct {
+	Kind    string `json:"kind,omitempty"`
+	Type    string `json:"type,omitempty"`
+	SubType string `json:"subType,omitempty"`
+	Key     string `json:"key,omitempty"`
+}
+
+// Matches reports whether the capability is selected by the query.
+func (q CapabilityQuery) Matches(c Capability) bool {
+	return matchCapabilityField(q.Kind, c.Kind) &&
+		matchCapabilityField(q.Type, c.Type) &&
+		matchCapabilityField(q.SubType, c.SubType) &&
+		matchCapabilityField(q.Key, c.Key)
+}
+
+func matchCapabilityField(query, value string) bool {
+	return query == "" || strings.EqualFold(query, value)
+}
+
+// Capabilities returns the capabilities declared in the metadata of the component.
+// An error is returned if they are malformed or miss required fields.
+func (c *ComponentDefinition) Capabilities() ([]Capability, error) {
+	capabilities, err := ParseCapabilities(c.Metadata[CapabilitiesMetadataKey])
+	if err != nil {
+		return nil, ErrInvalidCapability(err, c.DisplayName)
+	}
+	return capabilities, nil
+}
+
+// ParseCapabilities decodes and validates the capabilities of a component, as found in its metadata.
+func ParseCapabilities(value interface{}) ([]Capability, error) {
+	if value == nil {
+		return nil, nil
+	}
+	byt, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var capabilities []Capability
+	if err := json.Unmarshal(byt, &capabilities); err != nil {
+		return nil, fmt.Errorf("capabilities should be a list of objects: %w", err)
+	}
+	for i, capability := range capabilities {
+		if err := capability.Validate(); err != nil {
+			return nil, fmt.Errorf("capabilities[%d]: %w", i, err)
+		}
+	}
+	return capabilities, nil
+}
+
+// HasCapabilities reports whether each of the queries matches at least one capability of the component.
+// Components with malformed capabilities have none.
+func (c *ComponentDefinition) HasCapabilities(queries ...CapabilityQuery) bool {
+	capabilities, err := c.Capabilities()
+	if err != nil {
+		return false
+	}
+	for _, query := range queries {
+		found := false
+		for _, capability := range capabilities {
+			if query.Matches(capability) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
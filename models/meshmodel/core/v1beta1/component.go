@@ -65,12 +65,16 @@ func (c *ComponentDefinition) GetEntityDetail() string {
 	return fmt.Sprintf("type: %s, definition version: %s, name: %s, model: %s, version: %s", c.Type(), c.Version, c.DisplayName, c.Model.Name, c.Model.Version)
 }
 
+// Create stores the component, with a new ID unless it has one, e.g. a stable ID derived from its identity by the
+// generators. A component whose ID is already stored for the same model replaces the stored one, so that a regenerated
+// definition updates its schema, traits and provenance. The ID being stored for the model of another registrant, the
+// component gets an ID derived from it and its model, so that it doesn't take the component of the other registrant.
 func (c *ComponentDefinition) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error) {
-	c.ID = uuid.New()
-
-	isAnnotation, _ := c.Metadata["isAnnotation"].(bool)
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
 
-	if c.Component.Schema == "" && !isAnnotation { //For components which has an empty schema and is not an annotation, return error
+	if c.Component.Schema == "" && !c.IsAnnotation() { //For components which has an empty schema and is not an annotation, return error
 		// return ErrEmptySchema()
 		return uuid.Nil, nil
 	}
@@ -85,7 +89,15 @@ func (c *ComponentDefinition) Create(db *database.Handler, hostID uuid.UUID) (uu
 	}
 
 	c.ModelID = mid
-	err = db.Omit(clause.Associations).Create(&c).Error
+	var stored ComponentDefinition
+	err = db.Select("model_id").Where("id = ?", c.ID).Limit(1).Find(&stored).Error
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if stored.ModelID != uuid.Nil && stored.ModelID != mid {
+		c.ID = uuid.NewSHA1(c.ID, mid[:])
+	}
+	err = db.Omit(clause.Associations).Clauses(clause.OnConflict{UpdateAll: true}).Create(&c).Error
 	return c.ID, err
 }
 
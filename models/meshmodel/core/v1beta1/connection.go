@@ -0,0 +1,199 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"github.com/layer5io/meshkit/utils"
+	"gorm.io/gorm/clause"
+)
+
+// ConnectionStatus is the state of a connection, e.g. a Kubernetes cluster or a Prometheus instance managed by Meshery.
+type ConnectionStatus string
+
+const (
+	ConnectionDiscovered   ConnectionStatus = "discovered"
+	ConnectionRegistered   ConnectionStatus = "registered"
+	ConnectionConnected    ConnectionStatus = "connected"
+	ConnectionIgnored      ConnectionStatus = "ignored"
+	ConnectionMaintenance  ConnectionStatus = "maintenance"
+	ConnectionDisconnected ConnectionStatus = "disconnected"
+	ConnectionDeleted      ConnectionStatus = "deleted"
+	ConnectionNotFound     ConnectionStatus = "not found"
+)
+
+// DefaultConnectionTransitions are the transitions between the states of connections,
+// used for connection definitions without transitions of their own.
+var DefaultConnectionTransitions = map[ConnectionStatus][]ConnectionStatus{
+	ConnectionDiscovered:   {ConnectionRegistered, ConnectionConnected, ConnectionIgnored, ConnectionNotFound},
+	ConnectionRegistered:   {ConnectionConnected, ConnectionIgnored, ConnectionDeleted, ConnectionNotFound},
+	ConnectionConnected:    {ConnectionMaintenance, ConnectionDisconnected, ConnectionIgnored, ConnectionDeleted, ConnectionNotFound},
+	ConnectionIgnored:      {ConnectionRegistered, ConnectionConnected, ConnectionDeleted},
+	ConnectionMaintenance:  {ConnectionConnected, ConnectionDisconnected, ConnectionDeleted, ConnectionNotFound},
+	ConnectionDisconnected: {ConnectionConnected, ConnectionIgnored, ConnectionDeleted, ConnectionNotFound},
+	ConnectionNotFound:     {ConnectionDiscovered, ConnectionConnected, ConnectionDeleted},
+	ConnectionDeleted:      {},
+}
+
+// swagger:response ConnectionDefinition
+type ConnectionDefinition struct {
+	ID uuid.UUID `json:"id"`
+	VersionMeta
+	// Kind of the connection, e.g. kubernetes, prometheus, grafana
+	Kind        string `json:"kind" yaml:"kind"`
+	SubType     string `json:"subType" yaml:"subType"`
+	DisplayName string `json:"displayName" yaml:"displayName" gorm:"displayName"`
+	Description string `json:"description" yaml:"description" gorm:"description"`
+	// Schema is the JSON schema of the metadata of connections of this kind, e.g. the server URL.
+	Schema string `json:"schema,omitempty" yaml:"schema"`
+	// CredentialKinds are the kinds of the credential definitions connections of this kind can authenticate with.
+	CredentialKinds []string `json:"credentialKinds,omitempty" yaml:"credentialKinds" gorm:"type:bytes;serializer:json"`
+	// Transitions between the states of connections of this kind, DefaultConnectionTransitions if empty.
+	Transitions map[ConnectionStatus][]ConnectionStatus `json:"transitions,omitempty" yaml:"transitions" gorm:"type:bytes;serializer:json"`
+	Status      entity.EntityStatus                     `json:"status" yaml:"status" gorm:"status"`
+	Metadata    map[string]interface{}                  `json:"metadata" yaml:"metadata" gorm:"type:bytes;serializer:json"`
+	ModelID     uuid.UUID                               `json:"-" gorm:"index:idx_connection_definition_dbs_model_id,column:model_id"`
+	Model       Model                                   `json:"model" gorm:"foreignKey:ModelID;references:ID"`
+}
+
+func (c ConnectionDefinition) TableName() string {
+	return "connection_definition_dbs"
+}
+
+func (c ConnectionDefinition) Type() entity.EntityType {
+	return entity.ConnectionDefinition
+}
+
+func (c ConnectionDefinition) GetID() uuid.UUID {
+	return c.ID
+}
+
+func (c *ConnectionDefinition) GetEntityDetail() string {
+	return fmt.Sprintf("type: %s, definition version: %s, kind: %s, model: %s, version: %s", c.Type(), c.Version, c.Kind, c.Model.Name, c.Model.Model.Version)
+}
+
+func (c *ConnectionDefinition) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error) {
+	if err := c.Validate(); err != nil {
+		return uuid.UUID{}, err
+	}
+	c.ID = uuid.New()
+	mid, err := c.Model.Create(db, hostID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	c.ModelID = mid
+	if c.Status == "" {
+		c.Status = entity.Enabled
+	}
+	err = db.Omit(clause.Associations).Create(&c).Error
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return c.ID, nil
+}
+
+// Update updates the stored definition with the fields of c.
+func (c *ConnectionDefinition) Update(db *database.Handler) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return db.Omit(clause.Associations).Model(&ConnectionDefinition{ID: c.ID}).Select("*").Omit("id", "model_id").Updates(c).Error
+}
+
+// Delete deletes the stored definition.
+func (c *ConnectionDefinition) Delete(db *database.Handler) error {
+	return db.Delete(&ConnectionDefinition{ID: c.ID}).Error
+}
+
+func (c *ConnectionDefinition) UpdateStatus(db *database.Handler, status entity.EntityStatus) error {
+	err := db.Model(&ConnectionDefinition{}).Where("id = ?", c.ID).Update("status", status).Error
+	if err != nil {
+		return entity.ErrUpdateEntityStatus(err, string(c.Type()), status)
+	}
+	return nil
+}
+
+// Validate checks that the definition has a kind, a valid schema and transitions between known states.
+func (c *ConnectionDefinition) Validate() error {
+	if c.Kind == "" {
+		return ErrInvalidDefinition(fmt.Errorf("kind is empty"), string(c.Type()))
+	}
+	if err := validateDefinitionSchema(c.Schema); err != nil {
+		return ErrInvalidDefinition(err, string(c.Type()))
+	}
+	for from, targets := range c.Transitions {
+		if _, ok := DefaultConnectionTransitions[from]; !ok {
+			return ErrInvalidDefinition(fmt.Errorf("unknown connection status %q", from), string(c.Type()))
+		}
+		for _, to := range targets {
+			if _, ok := DefaultConnectionTransitions[to]; !ok {
+				return ErrInvalidDefinition(fmt.Errorf("unknown connection status %q", to), string(c.Type()))
+			}
+		}
+	}
+	return nil
+}
+
+// CanTransition reports whether a connection of this kind can change from one state to the other.
+func (c *ConnectionDefinition) CanTransition(from, to ConnectionStatus) bool {
+	transitions := c.Transitions
+	if len(transitions) == 0 {
+		transitions = DefaultConnectionTransitions
+	}
+	if from == to {
+		return true
+	}
+	for _, target := range transitions[from] {
+		if target == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an error if a connection of this kind can't change from one state to the other.
+func (c *ConnectionDefinition) ValidateTransition(from, to ConnectionStatus) error {
+	if !c.CanTransition(from, to) {
+		return ErrInvalidConnectionTransition(c.Kind, from, to)
+	}
+	return nil
+}
+
+// ValidateMetadata validates the metadata of a connection of this kind against the schema of the definition.
+func (c *ConnectionDefinition) ValidateMetadata(metadata map[string]interface{}) error {
+	return validateAgainstSchema(c.Schema, metadata, string(c.Type()))
+}
+
+// validateDefinitionSchema checks that the schema, if set, is a JSON schema.
+func validateDefinitionSchema(schema string) error {
+	if schema == "" {
+		return nil
+	}
+	_, err := utils.JsonSchemaToCue(schema)
+	return err
+}
+
+func validateAgainstSchema(schema string, value map[string]interface{}, entityType string) error {
+	if schema == "" {
+		return nil
+	}
+	byt, err := json.Marshal(value)
+	if err != nil {
+		return ErrValidateEntity(err, entityType)
+	}
+	violations, err := utils.ValidateJSONWithJSONSchema(schema, byt)
+	if err != nil {
+		return ErrValidateEntity(err, entityType)
+	}
+	if len(violations) > 0 {
+		messages := make([]string, 0, len(violations))
+		for _, v := range violations {
+			messages = append(messages, fmt.Sprintf("%s: %s", v.Path, v.Message))
+		}
+		return ErrValidateEntity(fmt.Errorf("%v", messages), entityType)
+	}
+	return nil
+}
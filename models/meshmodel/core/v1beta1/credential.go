@@ -0,0 +1,115 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"gorm.io/gorm/clause"
+)
+
+// swagger:response CredentialDefinition
+type CredentialDefinition struct {
+	ID uuid.UUID `json:"id"`
+	VersionMeta
+	// Kind of the credential, e.g. kubeconfig, bearer-token, basic-auth
+	Kind        string `json:"kind" yaml:"kind"`
+	DisplayName string `json:"displayName" yaml:"displayName" gorm:"displayName"`
+	Description string `json:"description" yaml:"description" gorm:"description"`
+	// Schema is the JSON schema of the secret of credentials of this kind, e.g. a token or username and password.
+	Schema string `json:"schema,omitempty" yaml:"schema"`
+	// SecretFields are the fields of the secret to be masked when credentials are displayed.
+	SecretFields []string               `json:"secretFields,omitempty" yaml:"secretFields" gorm:"type:bytes;serializer:json"`
+	Status       entity.EntityStatus    `json:"status" yaml:"status" gorm:"status"`
+	Metadata     map[string]interface{} `json:"metadata" yaml:"metadata" gorm:"type:bytes;serializer:json"`
+	ModelID      uuid.UUID              `json:"-" gorm:"index:idx_credential_definition_dbs_model_id,column:model_id"`
+	Model        Model                  `json:"model" gorm:"foreignKey:ModelID;references:ID"`
+}
+
+func (c CredentialDefinition) TableName() string {
+	return "credential_definition_dbs"
+}
+
+func (c CredentialDefinition) Type() entity.EntityType {
+	return entity.CredentialDefinition
+}
+
+func (c CredentialDefinition) GetID() uuid.UUID {
+	return c.ID
+}
+
+func (c *CredentialDefinition) GetEntityDetail() string {
+	return fmt.Sprintf("type: %s, definition version: %s, kind: %s, model: %s, version: %s", c.Type(), c.Version, c.Kind, c.Model.Name, c.Model.Model.Version)
+}
+
+func (c *CredentialDefinition) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error) {
+	if err := c.Validate(); err != nil {
+		return uuid.UUID{}, err
+	}
+	c.ID = uuid.New()
+	mid, err := c.Model.Create(db, hostID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	c.ModelID = mid
+	if c.Status == "" {
+		c.Status = entity.Enabled
+	}
+	err = db.Omit(clause.Associations).Create(&c).Error
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return c.ID, nil
+}
+
+// Update updates the stored definition with the fields of c.
+func (c *CredentialDefinition) Update(db *database.Handler) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return db.Omit(clause.Associations).Model(&CredentialDefinition{ID: c.ID}).Select("*").Omit("id", "model_id").Updates(c).Error
+}
+
+// Delete deletes the stored definition.
+func (c *CredentialDefinition) Delete(db *database.Handler) error {
+	return db.Delete(&CredentialDefinition{ID: c.ID}).Error
+}
+
+func (c *CredentialDefinition) UpdateStatus(db *database.Handler, status entity.EntityStatus) error {
+	err := db.Model(&CredentialDefinition{}).Where("id = ?", c.ID).Update("status", status).Error
+	if err != nil {
+		return entity.ErrUpdateEntityStatus(err, string(c.Type()), status)
+	}
+	return nil
+}
+
+// Validate checks that the definition has a kind and a valid schema.
+func (c *CredentialDefinition) Validate() error {
+	if c.Kind == "" {
+		return ErrInvalidDefinition(fmt.Errorf("kind is empty"), string(c.Type()))
+	}
+	if err := validateDefinitionSchema(c.Schema); err != nil {
+		return ErrInvalidDefinition(err, string(c.Type()))
+	}
+	return nil
+}
+
+// ValidateSecret validates the secret of a credential of this kind against the schema of the definition.
+func (c *CredentialDefinition) ValidateSecret(secret map[string]interface{}) error {
+	return validateAgainstSchema(c.Schema, secret, string(c.Type()))
+}
+
+// MaskSecret returns a copy of the secret with the values of the secret fields masked.
+func (c *CredentialDefinition) MaskSecret(secret map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(secret))
+	for k, v := range secret {
+		masked[k] = v
+	}
+	for _, field := range c.SecretFields {
+		if _, ok := masked[field]; ok {
+			masked[field] = "********"
+		}
+	}
+	return masked
+}
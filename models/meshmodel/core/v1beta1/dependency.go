@@ -0,0 +1,54 @@
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ModelDependency is a model required by the components or relationships of another model, e.g. the Kubernetes model
+// required by the models of operators, whose components reference its kinds.
+type ModelDependency struct {
+	Name string `json:"name" yaml:"name"`
+	// Version is the semantic version constraint of the versions satisfying the dependency, e.g. ">= 1.25, < 2",
+	// any version if empty. Versions with a leading v, e.g. v1.28.0, are accepted.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Optional dependencies are resolved if possible, and don't prevent registering the model otherwise.
+	Optional bool `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+func (d ModelDependency) String() string {
+	if d.Version == "" {
+		return d.Name
+	}
+	return d.Name + " " + d.Version
+}
+
+// SatisfiedBy reports whether the version of a model satisfies the dependency. Versions which are not semantic versions
+// only satisfy dependencies without constraint, or whose constraint is the version itself.
+func (d ModelDependency) SatisfiedBy(version string) (bool, error) {
+	if d.Version == "" || d.Version == version {
+		return true, nil
+	}
+	constraint, err := semver.NewConstraint(d.Version)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint %q of the dependency on %s: %w", d.Version, d.Name, err)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false, nil
+	}
+	return constraint.Check(v), nil
+}
+
+// CompareModelVersions compares two versions of a model, as semantic versions if both are, lexically otherwise.
+// The result is negative if a is lower than b, positive if it is greater, and 0 if they are equal.
+func CompareModelVersions(a, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA == nil && errB == nil {
+		return va.Compare(vb)
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,69 @@
+package v1beta1
+
+import (
+	"github.com/google/uuid"
+)
+
+const DesignSchemaVersion = "designs.meshery.io/v1beta1"
+
+// Design is a set of configured components and of the relationships between them.
+type Design struct {
+	ID            uuid.UUID              `json:"id" yaml:"id"`
+	Name          string                 `json:"name" yaml:"name"`
+	SchemaVersion string                 `json:"schemaVersion" yaml:"schemaVersion"`
+	Version       string                 `json:"version" yaml:"version"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Components    []DesignComponent      `json:"components" yaml:"components"`
+	Relationships []DesignRelationship   `json:"relationships,omitempty" yaml:"relationships,omitempty"`
+}
+
+// DesignComponent is an instance of a component definition in a design.
+type DesignComponent struct {
+	ID          uuid.UUID         `json:"id" yaml:"id"`
+	Name        string            `json:"name" yaml:"name"`
+	DisplayName string            `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	Namespace   string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// Kind and APIVersion identify the component definition, they are the component.kind and component.version of the definition
+	Kind         string `json:"kind" yaml:"kind"`
+	APIVersion   string `json:"apiVersion" yaml:"apiVersion"`
+	Model        string `json:"model" yaml:"model"`
+	ModelVersion string `json:"modelVersion,omitempty" yaml:"modelVersion,omitempty"`
+	// Configuration holds the settings of the component, e.g. the spec of a Kubernetes resource
+	Configuration map[string]interface{} `json:"configuration,omitempty" yaml:"configuration,omitempty"`
+	DependsOn     []string               `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// DesignRelationship is an instance of a relationship definition between components of a design.
+type DesignRelationship struct {
+	ID               uuid.UUID              `json:"id" yaml:"id"`
+	Kind             string                 `json:"kind" yaml:"kind"`
+	RelationshipType string                 `json:"type" yaml:"type"`
+	SubType          string                 `json:"subType" yaml:"subType"`
+	From             []uuid.UUID            `json:"from" yaml:"from"`
+	To               []uuid.UUID            `json:"to" yaml:"to"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// NewDesign returns an empty design of the current schema version.
+func NewDesign(name string) Design {
+	return Design{
+		ID:            uuid.New(),
+		Name:          name,
+		SchemaVersion: DesignSchemaVersion,
+		Version:       "0.0.1",
+		Components:    make([]DesignComponent, 0),
+	}
+}
+
+// GetComponent returns the component of the design with the given id, nil if there is none.
+func (d *Design) GetComponent(id uuid.UUID) *DesignComponent {
+	for i := range d.Components {
+		if d.Components[i].ID == id {
+			return &d.Components[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrInvalidDefinitionCode           = "meshkit-11294"
+	ErrValidateEntityCode              = "meshkit-11295"
+	ErrInvalidConnectionTransitionCode = "meshkit-11296"
+	ErrInvalidCapabilityCode           = "meshkit-11297"
+	ErrComposeTraitsCode               = "meshkit-11318"
+	ErrApplyOverridesCode              = "meshkit-11341"
+)
+
+func ErrInvalidDefinition(err error, entityType string) error {
+	return errors.New(ErrInvalidDefinitionCode, errors.Alert, []string{fmt.Sprintf("invalid %s definition", entityType)}, []string{err.Error()}, []string{"The definition has no kind, or its schema is no valid JSON schema"}, []string{"Make sure the definition has a kind and a valid JSON schema"})
+}
+
+func ErrValidateEntity(err error, entityType string) error {
+	return errors.New(ErrValidateEntityCode, errors.Alert, []string{fmt.Sprintf("validation against the %s definition failed", entityType)}, []string{err.Error()}, []string{"The values don't match the schema of the definition"}, []string{"Correct the values reported as invalid"})
+}
+
+func ErrInvalidConnectionTransition(kind string, from, to ConnectionStatus) error {
+	return errors.New(ErrInvalidConnectionTransitionCode, errors.Alert, []string{"invalid connection status transition"}, []string{fmt.Sprintf("a %s connection can't change from %s to %s", kind, from, to)}, []string{"The transition is not allowed by the connection definition"}, []string{"Change the status of the connection to one of the states reachable from its current state"})
+}
+
+func ErrInvalidCapability(err error, component string) error {
+	return errors.New(ErrInvalidCapabilityCode, errors.Alert, []string{fmt.Sprintf("invalid capabilities of the component %s", component)}, []string{err.Error()}, []string{"The capabilities in the metadata of the component are not a list of capabilities, or miss required fields"}, []string{"Make sure each capability has a displayName, kind and type"})
+}
+
+func ErrComposeTraits(err error, component string) error {
+	return errors.New(ErrComposeTraitsCode, errors.Alert, []string{fmt.Sprintf("unable to compose the traits of the component %s", component)}, []string{err.Error()}, []string{"The schema of the component or of a trait is not a JSON object"}, []string{"Make sure the schemas of the component and of its traits are valid JSON schemas of objects"})
+}
+
+func ErrApplyOverrides(err error, component string) error {
+	return errors.New(ErrApplyOverridesCode, errors.Alert, []string{fmt.Sprintf("unable to apply the overrides of the component %s", component)}, []string{err.Error()}, []string{"The schema of the component is not a JSON object"}, []string{"Make sure the schema of the component is a valid JSON schema of an object"})
+}
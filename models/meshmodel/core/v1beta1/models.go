@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"reflect"
 	"sync"
 
 	"github.com/google/uuid"
@@ -26,19 +27,21 @@ type ModelEntity struct {
 type Model struct {
 	ID uuid.UUID `json:"id"`
 	VersionMeta
-	Name          string                 `json:"name" gorm:"modelName"`
-	DisplayName   string                 `json:"displayName"`
-	Description   string                 `json:"description" gorm:"description"`
-	Status        entity.EntityStatus    `json:"status" gorm:"status"`
-	RegistrantID  uuid.UUID              `json:"hostID" gorm:"column:host_id"` // make as a foreign refer to host's table
-	Registrant    Host                   `json:"registrant" gorm:"foreignKey:RegistrantID;references:ID"`
-	CategoryID    uuid.UUID              `json:"-" gorm:"categoryID"`
-	Category      Category               `json:"category" gorm:"foreignKey:CategoryID;references:ID"`
-	SubCategory   string                 `json:"subCategory" gorm:"subCategory"`
-	Metadata      map[string]interface{} `json:"metadata" gorm:"type:bytes;serializer:json"`
-	Model         ModelEntity            `json:"model,omitempty" gorm:"model;type:bytes;serializer:json"`
-	Components    []ComponentDefinition  `json:"components" gorm:"-"`
-	Relationships interface{}            `json:"relationships" gorm:"-"`
+	Name         string                 `json:"name" gorm:"modelName"`
+	DisplayName  string                 `json:"displayName"`
+	Description  string                 `json:"description" gorm:"description"`
+	Status       entity.EntityStatus    `json:"status" gorm:"status"`
+	RegistrantID uuid.UUID              `json:"hostID" gorm:"column:host_id"` // make as a foreign refer to host's table
+	Registrant   Host                   `json:"registrant" gorm:"foreignKey:RegistrantID;references:ID"`
+	CategoryID   uuid.UUID              `json:"-" gorm:"categoryID"`
+	Category     Category               `json:"category" gorm:"foreignKey:CategoryID;references:ID"`
+	SubCategory  string                 `json:"subCategory" gorm:"subCategory"`
+	Metadata     map[string]interface{} `json:"metadata" gorm:"type:bytes;serializer:json"`
+	Model        ModelEntity            `json:"model,omitempty" gorm:"model;type:bytes;serializer:json"`
+	// Dependencies are the models the model requires, see ModelDependency.
+	Dependencies  []ModelDependency     `json:"dependencies,omitempty" gorm:"type:bytes;serializer:json"`
+	Components    []ComponentDefinition `json:"components" gorm:"-"`
+	Relationships interface{}           `json:"relationships" gorm:"-"`
 }
 
 func (m Model) TableName() string {
@@ -56,6 +59,8 @@ func (m *Model) GetEntityDetail() string {
 	return fmt.Sprintf("type: %s, model: %s, definition version: %s, version: %s", m.Type(), m.Name, m.Version, m.Model.Version)
 }
 
+// Create registers the model from the host, unless it is already registered, and returns its ID. The dependencies of
+// the model are stored as declared, not resolved, see RegistryManager.ResolveDependencies for the importers to call first.
 func (m *Model) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error) {
 	modelIdentifier := Model{
 		Registrant:  m.Registrant,
@@ -100,6 +105,13 @@ func (m *Model) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error
 		}
 		return m.ID, nil
 	}
+	// the dependencies declared by a model registered again replace the stored ones, an empty list clearing them
+	if m.Dependencies != nil && !reflect.DeepEqual(m.Dependencies, model.Dependencies) {
+		err = db.Model(&Model{ID: model.ID}).Select("dependencies").Updates(&Model{Dependencies: m.Dependencies}).Error
+		if err != nil {
+			return uuid.UUID{}, err
+		}
+	}
 	return model.ID, nil
 }
 
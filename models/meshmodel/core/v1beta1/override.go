@@ -0,0 +1,150 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OverridesMetadataKey is the key of the layers of the overrides applied to a component definition, in its metadata.
+const OverridesMetadataKey = "overrides"
+
+// StylesMetadataKey is the key of the styles of a component definition, in its metadata.
+const StylesMetadataKey = "styles"
+
+// ComponentOverride is a layer of customizations of the definitions of a kind of components, e.g. by a user, applied
+// on top of the definitions provided by the registrants. The overrides are stored apart from the definitions, so that
+// regenerating the definitions doesn't lose them.
+type ComponentOverride struct {
+	ID uuid.UUID `json:"id"`
+	// Layer is the owner of the override, e.g. the ID of a user or of an organization.
+	Layer string `json:"layer" yaml:"layer" gorm:"index:idx_component_override_target"`
+	// Precedence orders the layers: the overrides of higher precedence are applied last, and win.
+	Precedence int `json:"precedence,omitempty" yaml:"precedence"`
+	// Model, Kind and APIVersion select the definitions overridden, empty matching any.
+	Model      string `json:"model,omitempty" yaml:"model" gorm:"index:idx_component_override_target"`
+	Kind       string `json:"kind,omitempty" yaml:"kind" gorm:"index:idx_component_override_target"`
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion" gorm:"index:idx_component_override_target"`
+	// SchemaPatch is a JSON merge patch of the schema of the definitions, null values removing properties.
+	SchemaPatch map[string]interface{} `json:"schemaPatch,omitempty" yaml:"schemaPatch" gorm:"type:bytes;serializer:json"`
+	// Metadata is merged into the metadata of the definitions, as a JSON merge patch.
+	Metadata map[string]interface{} `json:"metadata,omitempty" yaml:"metadata" gorm:"type:bytes;serializer:json"`
+	// Styles are merged into the styles of the metadata of the definitions, as a JSON merge patch.
+	Styles    map[string]interface{} `json:"styles,omitempty" yaml:"styles" gorm:"type:bytes;serializer:json"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+func (o ComponentOverride) TableName() string {
+	return "component_override_dbs"
+}
+
+// Matches reports whether the override applies to the component definition.
+func (o ComponentOverride) Matches(c ComponentDefinition) bool {
+	return (o.Model == "" || o.Model == c.Model.Name) &&
+		(o.Kind == "" || o.Kind == c.Component.Kind) &&
+		(o.APIVersion == "" || o.APIVersion == c.Component.Version)
+}
+
+// WithOverrides returns a copy of the component definition with the matching overrides applied, in increasing
+// precedence, the definition itself being left unchanged. The layers of the overrides applied are recorded in the
+// metadata of the copy under OverridesMetadataKey.
+func (c ComponentDefinition) WithOverrides(overrides ...ComponentOverride) (ComponentDefinition, error) {
+	var matching []ComponentOverride
+	for _, o := range overrides {
+		if o.Matches(c) {
+			matching = append(matching, o)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool { return matching[i].Precedence < matching[j].Precedence })
+
+	overridden := c
+	overridden.Metadata = copyJSONMap(c.Metadata)
+	if len(matching) == 0 {
+		return overridden, nil
+	}
+	if overridden.Metadata == nil {
+		overridden.Metadata = map[string]interface{}{}
+	}
+	var schema map[string]interface{}
+	layers := make([]string, 0, len(matching))
+	for _, o := range matching {
+		if len(o.SchemaPatch) > 0 {
+			if schema == nil {
+				schema = map[string]interface{}{}
+				if c.Component.Schema != "" {
+					if err := json.Unmarshal([]byte(c.Component.Schema), &schema); err != nil {
+						return c, ErrApplyOverrides(fmt.Errorf("invalid schema of the component: %w", err), c.Component.Kind)
+					}
+				}
+			}
+			schema = mergePatch(schema, o.SchemaPatch)
+		}
+		overridden.Metadata = mergePatch(overridden.Metadata, o.Metadata)
+		if len(o.Styles) > 0 {
+			styles, _ := overridden.Metadata[StylesMetadataKey].(map[string]interface{})
+			overridden.Metadata[StylesMetadataKey] = mergePatch(styles, o.Styles)
+		}
+		layers = append(layers, o.Layer)
+	}
+	if schema != nil {
+		byt, err := json.Marshal(schema)
+		if err != nil {
+			return c, ErrApplyOverrides(err, c.Component.Kind)
+		}
+		overridden.Component.Schema = string(byt)
+	}
+	overridden.Metadata[OverridesMetadataKey] = layers
+	return overridden, nil
+}
+
+// mergePatch applies the JSON merge patch to the target, see https://www.rfc-editor.org/rfc/rfc7386.
+// The maps of the target are modified, and have to be copied before if they are shared.
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = map[string]interface{}{}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+		if patchMap, ok := v.(map[string]interface{}); ok {
+			targetMap, _ := target[k].(map[string]interface{})
+			target[k] = mergePatch(targetMap, patchMap)
+			continue
+		}
+		target[k] = v
+	}
+	return target
+}
+
+// copyJSONMap copies the nested maps and slices of the map, so that patching the copy leaves the map unchanged.
+func copyJSONMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = copyJSONValue(v)
+	}
+	return copied
+}
+
+func copyJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return copyJSONMap(v)
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyJSONValue(item)
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -8,23 +8,39 @@ import (
 	"github.com/google/uuid"
 	"github.com/layer5io/meshkit/database"
 	"github.com/layer5io/meshkit/utils"
+	"github.com/open-policy-agent/opa/ast"
 	"gorm.io/gorm/clause"
 
 	"github.com/layer5io/meshkit/models/meshmodel/entity"
 )
 
+// PolicyDefinition is a set of Rego modules evaluated against designs, parameterized by values validated by its
+// parameters schema. Each version of a policy is stored separately, the version being the one of TypeMeta.
+//
 // swagger:response PolicyDefinition
 type PolicyDefinition struct {
 	ID uuid.UUID `json:"-"`
 	TypeMeta
-	ModelID    uuid.UUID              `json:"-" gorm:"column:modelID"`
-	Model      Model                  `json:"model"`
-	SubType    string                 `json:"subType" yaml:"subType"`
+	ModelID uuid.UUID `json:"-" gorm:"column:modelID"`
+	Model   Model     `json:"model" gorm:"foreignKey:ModelID;references:ID"`
+	SubType string    `json:"subType" yaml:"subType"`
+	// Modules are the Rego modules of the policy by file name, e.g. {"deny_privileged.rego": "package meshery.policies..."}
+	Modules map[string]string `json:"modules" yaml:"modules" gorm:"type:bytes;serializer:json"`
+	// ParametersSchema is the JSON schema of the parameters of the policy, e.g. the registries images may be pulled from.
+	ParametersSchema string `json:"parametersSchema,omitempty" yaml:"parametersSchema"`
+	// Selectors select the components of the designs the policy applies to, a policy without selectors applies to all designs.
+	Selectors  []PolicySelector       `json:"selectors,omitempty" yaml:"selectors" gorm:"type:bytes;serializer:json"`
 	Expression map[string]interface{} `json:"expression" yaml:"expression" gorm:"type:bytes;serializer:json"`
 	CreatedAt  time.Time              `json:"-"`
 	UpdatedAt  time.Time              `json:"-"`
 }
 
+// PolicySelector selects components by kind and model, empty fields matching any.
+type PolicySelector struct {
+	Kind  string `json:"kind,omitempty" yaml:"kind"`
+	Model string `json:"model,omitempty" yaml:"model"`
+}
+
 func (p PolicyDefinition) GetID() uuid.UUID {
 	return p.ID
 }
@@ -38,6 +54,9 @@ func (p *PolicyDefinition) GetEntityDetail() string {
 }
 
 func (p *PolicyDefinition) Create(db *database.Handler, hostID uuid.UUID) (uuid.UUID, error) {
+	if err := p.Validate(); err != nil {
+		return uuid.UUID{}, err
+	}
 	p.ID = uuid.New()
 	mid, err := p.Model.Create(db, hostID)
 	if err != nil {
@@ -51,10 +70,70 @@ func (p *PolicyDefinition) Create(db *database.Handler, hostID uuid.UUID) (uuid.
 	return p.ID, nil
 }
 
+// Update updates the stored definition with the fields of p.
+func (p *PolicyDefinition) Update(db *database.Handler) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return db.Omit(clause.Associations).Model(&PolicyDefinition{ID: p.ID}).Select("*").Omit("id", "modelID", "created_at").Updates(p).Error
+}
+
+// Delete deletes the stored definition.
+func (p *PolicyDefinition) Delete(db *database.Handler) error {
+	return db.Delete(&PolicyDefinition{ID: p.ID}).Error
+}
+
 func (m *PolicyDefinition) UpdateStatus(db *database.Handler, status entity.EntityStatus) error {
 	return nil
 }
 
+// Validate checks that the definition has a kind, that its modules are valid Rego and that the parameters schema, if any, is a JSON schema.
+func (p *PolicyDefinition) Validate() error {
+	if p.Kind == "" {
+		return ErrInvalidDefinition(fmt.Errorf("kind is empty"), string(p.Type()))
+	}
+	for name, module := range p.Modules {
+		if _, err := ast.ParseModule(name, module); err != nil {
+			return ErrInvalidDefinition(err, string(p.Type()))
+		}
+	}
+	if err := validateDefinitionSchema(p.ParametersSchema); err != nil {
+		return ErrInvalidDefinition(fmt.Errorf("invalid parameters schema: %w", err), string(p.Type()))
+	}
+	return nil
+}
+
+// ValidateParameters validates the parameters of an evaluation of the policy against the parameters schema of the definition.
+func (p *PolicyDefinition) ValidateParameters(parameters map[string]interface{}) error {
+	return validateAgainstSchema(p.ParametersSchema, parameters, string(p.Type()))
+}
+
+// Selects reports whether the component is selected by the selectors of the policy.
+func (p *PolicyDefinition) Selects(c DesignComponent) bool {
+	if len(p.Selectors) == 0 {
+		return true
+	}
+	for _, s := range p.Selectors {
+		if (s.Kind == "" || s.Kind == c.Kind) && (s.Model == "" || s.Model == c.Model) {
+			return true
+		}
+	}
+	return false
+}
+
+// AppliesTo reports whether the policy applies to the design, i.e. it has no selectors or selects one of the components of the design.
+func (p *PolicyDefinition) AppliesTo(d Design) bool {
+	if len(p.Selectors) == 0 {
+		return true
+	}
+	for _, c := range d.Components {
+		if p.Selects(c) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p PolicyDefinition) WritePolicyDefinition(policyDirPath string) error {
 	policyPath := filepath.Join(policyDirPath, p.Kind+".json")
 	err := utils.WriteJSONToFile[PolicyDefinition](policyPath, p)
@@ -0,0 +1,91 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ProvenanceMetadataKey is the key of the Provenance of a generated component definition, in its metadata.
+const ProvenanceMetadataKey = "provenance"
+
+// Provenance records where a component definition was generated from, and by what, so that it can be regenerated
+// from the same source and bad schemas can be traced back to their CRD.
+type Provenance struct {
+	// Registrant the source was fetched from, e.g. artifacthub or github.
+	Registrant string `json:"registrant,omitempty"`
+	// Source is the URL or the path of the source, e.g. of the Helm chart.
+	Source string `json:"source,omitempty"`
+	// Package and PackageVersion are the name and version of the package of the source, e.g. of the Helm chart.
+	Package        string `json:"package,omitempty"`
+	PackageVersion string `json:"packageVersion,omitempty"`
+	// CRDDigest is the digest of the CRD the definition was generated from, e.g. sha256:<hex>.
+	CRDDigest        string `json:"crdDigest,omitempty"`
+	Generator        string `json:"generator,omitempty"`
+	GeneratorVersion string `json:"generatorVersion,omitempty"`
+	// GeneratedAt is the time of the generation, nil unless it was set, so that the output can be reproduced.
+	GeneratedAt *time.Time `json:"generatedAt,omitempty"`
+}
+
+// ProvenanceQuery matches the provenance of component definitions, empty fields matching any.
+type ProvenanceQuery struct {
+	Registrant       string
+	Source           string
+	Package          string
+	PackageVersion   string
+	CRDDigest        string
+	GeneratorVersion string
+	// GeneratedAfter and GeneratedBefore bound the generation time, if set. The provenances without a generation
+	// time don't match bounded queries.
+	GeneratedAfter  time.Time
+	GeneratedBefore time.Time
+}
+
+// Matches reports whether the provenance matches the query.
+func (q ProvenanceQuery) Matches(p Provenance) bool {
+	return matchProvenanceField(q.Registrant, p.Registrant) &&
+		matchProvenanceField(q.Source, p.Source) &&
+		matchProvenanceField(q.Package, p.Package) &&
+		matchProvenanceField(q.PackageVersion, p.PackageVersion) &&
+		matchProvenanceField(q.CRDDigest, p.CRDDigest) &&
+		matchProvenanceField(q.GeneratorVersion, p.GeneratorVersion) &&
+		(q.GeneratedAfter.IsZero() || (p.GeneratedAt != nil && p.GeneratedAt.After(q.GeneratedAfter))) &&
+		(q.GeneratedBefore.IsZero() || (p.GeneratedAt != nil && p.GeneratedAt.Before(q.GeneratedBefore)))
+}
+
+func matchProvenanceField(query, value string) bool {
+	return query == "" || query == value
+}
+
+// Provenance returns the provenance recorded in the metadata of the component, false if it has none.
+func (c *ComponentDefinition) Provenance() (Provenance, bool) {
+	value, ok := c.Metadata[ProvenanceMetadataKey]
+	if !ok || value == nil {
+		return Provenance{}, false
+	}
+	byt, err := json.Marshal(value)
+	if err != nil {
+		return Provenance{}, false
+	}
+	var p Provenance
+	if err := json.Unmarshal(byt, &p); err != nil {
+		return Provenance{}, false
+	}
+	return p, true
+}
+
+// SetProvenance records the provenance in the metadata of the component, as a JSON object.
+func (c *ComponentDefinition) SetProvenance(p Provenance) {
+	if c.Metadata == nil {
+		c.Metadata = map[string]interface{}{}
+	}
+	byt, _ := json.Marshal(p)
+	var value map[string]interface{}
+	_ = json.Unmarshal(byt, &value)
+	c.Metadata[ProvenanceMetadataKey] = value
+}
+
+// HasProvenance reports whether the component has a provenance matching the query.
+func (c *ComponentDefinition) HasProvenance(q ProvenanceQuery) bool {
+	p, ok := c.Provenance()
+	return ok && q.Matches(p)
+}
@@ -0,0 +1,172 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TraitsMetadataKey is the key of the names of the traits composed into a component definition, in its metadata.
+const TraitsMetadataKey = "traits"
+
+// TraitConflictsMetadataKey is the key of the conflicts found composing the traits of a component definition, in its metadata.
+const TraitConflictsMetadataKey = "traitConflicts"
+
+// Trait is a reusable part of component definitions, e.g. "has replicas" or "exposes ports", composed into the
+// definitions which declare it instead of being repeated in each of them.
+type Trait struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	// Schema is the JSON schema of an object whose properties are merged into the schema of the components.
+	Schema string `json:"schema,omitempty" yaml:"schema"`
+	// Metadata is merged into the metadata of the components, e.g. capabilities or styles.
+	Metadata map[string]interface{} `json:"metadata,omitempty" yaml:"metadata"`
+}
+
+// TraitConflict is a property or a metadata key defined differently by a trait and the component, or by two traits.
+// The definition composed first is kept.
+type TraitConflict struct {
+	Trait string `json:"trait"`
+	// Path is the path of the property in the schema, e.g. properties.spec.properties.replicas, or metadata.<key>.
+	Path string `json:"path"`
+}
+
+func (c TraitConflict) String() string {
+	return fmt.Sprintf("%s: %s is already defined differently", c.Trait, c.Path)
+}
+
+// TraitNames returns the names of the traits declared in the metadata of the component.
+func (c *ComponentDefinition) TraitNames() []string {
+	var names []string
+	switch traits := c.Metadata[TraitsMetadataKey].(type) {
+	case []string:
+		names = append(names, traits...)
+	case []interface{}:
+		for _, t := range traits {
+			if name, ok := t.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// ComposeTraits merges the schemas and the metadata of the traits into the component, in order, and adds their names
+// to the traits of its metadata. Composing a trait twice doesn't change the component.
+//
+// The properties of the schemas are merged recursively and their required properties are added up. A property or a
+// metadata key defined differently by the component or a previous trait is kept as is and reported as a conflict.
+// An error is returned if the schema of the component or of a trait is not a JSON object.
+func (c *ComponentDefinition) ComposeTraits(traits ...Trait) ([]TraitConflict, error) {
+	schema := map[string]interface{}{}
+	if c.Component.Schema != "" {
+		if err := json.Unmarshal([]byte(c.Component.Schema), &schema); err != nil {
+			return nil, ErrComposeTraits(fmt.Errorf("invalid schema of the component: %w", err), c.Component.Kind)
+		}
+	}
+	if c.Metadata == nil {
+		c.Metadata = map[string]interface{}{}
+	}
+	names := c.TraitNames()
+	var conflicts []TraitConflict
+	for _, t := range traits {
+		if t.Schema != "" {
+			traitSchema := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(t.Schema), &traitSchema); err != nil {
+				return nil, ErrComposeTraits(fmt.Errorf("invalid schema of the trait %s: %w", t.Name, err), c.Component.Kind)
+			}
+			if schema["type"] == nil && traitSchema["type"] != nil {
+				schema["type"] = traitSchema["type"]
+			}
+			for _, path := range mergeSchemaProperties(schema, traitSchema, nil) {
+				conflicts = append(conflicts, TraitConflict{Trait: t.Name, Path: path})
+			}
+		}
+		for k, v := range t.Metadata {
+			existing, ok := c.Metadata[k]
+			if !ok {
+				c.Metadata[k] = v
+			} else if !reflect.DeepEqual(existing, v) {
+				conflicts = append(conflicts, TraitConflict{Trait: t.Name, Path: "metadata." + k})
+			}
+		}
+		if !containsString(names, t.Name) {
+			names = append(names, t.Name)
+		}
+	}
+	if len(schema) > 0 {
+		byt, err := json.Marshal(schema)
+		if err != nil {
+			return nil, ErrComposeTraits(err, c.Component.Kind)
+		}
+		c.Component.Schema = string(byt)
+	}
+	if len(names) > 0 {
+		c.Metadata[TraitsMetadataKey] = names
+	}
+	return conflicts, nil
+}
+
+// mergeSchemaProperties merges the properties and the required properties of src into dst, and returns the paths of the conflicting properties.
+func mergeSchemaProperties(dst, src map[string]interface{}, path []string) []string {
+	var conflicts []string
+	srcProperties, _ := src["properties"].(map[string]interface{})
+	if len(srcProperties) > 0 {
+		dstProperties, ok := dst["properties"].(map[string]interface{})
+		if !ok {
+			dstProperties = map[string]interface{}{}
+			dst["properties"] = dstProperties
+		}
+		for name, property := range srcProperties {
+			propertyPath := append(append([]string{}, path...), "properties", name)
+			existing, ok := dstProperties[name]
+			if !ok {
+				dstProperties[name] = property
+				continue
+			}
+			if reflect.DeepEqual(existing, property) {
+				continue
+			}
+			existingObject, ok1 := existing.(map[string]interface{})
+			propertyObject, ok2 := property.(map[string]interface{})
+			if ok1 && ok2 && isObjectSchema(existingObject) && isObjectSchema(propertyObject) {
+				conflicts = append(conflicts, mergeSchemaProperties(existingObject, propertyObject, propertyPath)...)
+				continue
+			}
+			conflicts = append(conflicts, strings.Join(propertyPath, "."))
+		}
+	}
+	if required, _ := src["required"].([]interface{}); len(required) > 0 {
+		dstRequired, _ := dst["required"].([]interface{})
+		for _, r := range required {
+			found := false
+			for _, d := range dstRequired {
+				if d == r {
+					found = true
+					break
+				}
+			}
+			if !found {
+				dstRequired = append(dstRequired, r)
+			}
+		}
+		dst["required"] = dstRequired
+	}
+	return conflicts
+}
+
+// isObjectSchema reports whether the schema is the one of an object, whose properties can be merged.
+func isObjectSchema(schema map[string]interface{}) bool {
+	_, hasProperties := schema["properties"]
+	return schema["type"] == "object" || schema["type"] == nil && hasProperties
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,9 @@ const (
 	RelationshipDefinition EntityType = "relationship"
 	Model                  EntityType = "model"
 	Category               EntityType = "category"
+	ConnectionDefinition   EntityType = "connection"
+	CredentialDefinition   EntityType = "credential"
+	AnnotationDefinition   EntityType = "annotation"
 )
 
 // Each entity will have it's own Filter implementation via which it exposes the nobs and dials to fetch entities
@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+)
+
+func TestAnnotationDefinitionRegistry(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "meshery"}
+	model := v1beta1.Model{Name: "meshery-shapes", Category: v1beta1.Category{Name: "Uncategorized"}, Model: v1beta1.ModelEntity{Version: "v1.0.0"}}
+
+	shape := v1beta1.ComponentDefinition{
+		DisplayName: "Rectangle",
+		Model:       model,
+		Metadata:    map[string]interface{}{v1beta1.AnnotationMetadataKey: true, "styles": map[string]interface{}{"shape": "rectangle"}, "svgColor": "<svg/>"},
+		Component:   v1beta1.ComponentEntity{TypeMeta: v1beta1.TypeMeta{Kind: "Rectangle"}},
+	}
+	annotation, ok := v1beta1.AnnotationFromComponent(shape)
+	if !ok {
+		t.Fatal("expected the component to be converted to an annotation")
+	}
+	if annotation.Kind != "Rectangle" || annotation.Styles["shape"] != "rectangle" || annotation.Metadata["svgColor"] != "<svg/>" {
+		t.Fatalf("unexpected annotation %+v", annotation)
+	}
+	if _, ok := annotation.Metadata[v1beta1.AnnotationMetadataKey]; ok {
+		t.Error("expected the annotation marker to be dropped from the metadata")
+	}
+	if _, ok := v1beta1.AnnotationFromComponent(v1beta1.ComponentDefinition{Metadata: map[string]interface{}{}}); ok {
+		t.Error("expected components not marked as annotations not to be converted")
+	}
+
+	// components marked as annotations are registered as annotations
+	if err := rm.RegisterEntity(host, &shape); err != nil {
+		t.Fatal(err)
+	}
+	circle := v1beta1.AnnotationDefinition{Kind: "Circle", DisplayName: "Circle", Model: model}
+	if err := rm.RegisterEntity(host, &circle); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.RegisterEntity(host, &v1beta1.AnnotationDefinition{Model: model}); err == nil {
+		t.Error("expected an error registering an annotation without kind")
+	}
+
+	entities, count, _, err := rm.GetEntities(&regv1beta1.AnnotationFilter{ModelName: "meshery-shapes", OrderOn: "kind"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || entities[0].(*v1beta1.AnnotationDefinition).Kind != "Circle" {
+		t.Fatalf("unexpected annotations %+v", entities)
+	}
+	got := entities[1].(*v1beta1.AnnotationDefinition)
+	if got.Status != entity.Enabled || got.Model.Name != "meshery-shapes" || got.Styles["shape"] != "rectangle" {
+		t.Fatalf("unexpected annotation %+v", got)
+	}
+
+	entities, _, _, err = rm.GetEntities(&regv1beta1.AnnotationFilter{DisplayName: "rect", Greedy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(entities))
+	}
+
+	if err := rm.UpdateEntityStatus(got.ID.String(), string(entity.Ignored), "annotations"); err != nil {
+		t.Fatal(err)
+	}
+	_, count, _, err = rm.GetEntities(&regv1beta1.AnnotationFilter{Status: string(entity.Enabled)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 enabled annotation, got %d", count)
+	}
+
+	// annotations are no components
+	_, count, _, err = rm.GetEntities(&regv1beta1.ComponentFilter{ModelName: "meshery-shapes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no components, got %d", count)
+	}
+}
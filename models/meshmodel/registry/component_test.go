@@ -0,0 +1,203 @@
+package registry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+)
+
+func testComponent(kind string, capabilities ...map[string]interface{}) *v1beta1.ComponentDefinition {
+	caps := make([]interface{}, 0, len(capabilities))
+	for _, c := range capabilities {
+		caps = append(caps, c)
+	}
+	return &v1beta1.ComponentDefinition{
+		DisplayName: kind,
+		Model:       v1beta1.Model{Name: "kubernetes", Category: v1beta1.Category{Name: "Orchestration"}, Model: v1beta1.ModelEntity{Version: "v1.28.0"}},
+		Metadata:    map[string]interface{}{v1beta1.CapabilitiesMetadataKey: caps},
+		Component:   v1beta1.ComponentEntity{TypeMeta: v1beta1.TypeMeta{Kind: kind, Version: "apps/v1"}, Schema: `{"type": "object"}`},
+	}
+}
+
+func workload(subType string) map[string]interface{} {
+	return map[string]interface{}{"displayName": "Workload", "kind": "view", "type": "workload", "subType": subType}
+}
+
+func TestComponentFilterCapabilities(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	styling := map[string]interface{}{"displayName": "Styling", "kind": "mutate", "type": "style"}
+	for _, comp := range []*v1beta1.ComponentDefinition{
+		testComponent("StatefulSet", workload("stateful"), styling),
+		testComponent("Deployment", workload("stateless"), styling),
+		testComponent("DaemonSet", workload("stateless")),
+		testComponent("ConfigMap", styling),
+		testComponent("Secret"),
+	} {
+		if err := rm.RegisterEntity(host, comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter regv1beta1.ComponentFilter
+		want   []string
+	}{
+		{"type", regv1beta1.ComponentFilter{Capabilities: []v1beta1.CapabilityQuery{{Type: "workload"}}}, []string{"DaemonSet", "Deployment", "StatefulSet"}},
+		{"sub type", regv1beta1.ComponentFilter{Capabilities: []v1beta1.CapabilityQuery{{Type: "workload", SubType: "stateful"}}}, []string{"StatefulSet"}},
+		{"all queries", regv1beta1.ComponentFilter{Capabilities: []v1beta1.CapabilityQuery{{Type: "Workload"}, {Kind: "mutate", Type: "style"}}}, []string{"Deployment", "StatefulSet"}},
+		{"page", regv1beta1.ComponentFilter{Capabilities: []v1beta1.CapabilityQuery{{Type: "workload"}}, Offset: 1, Limit: 1}, []string{"Deployment"}},
+		{"none", regv1beta1.ComponentFilter{Capabilities: []v1beta1.CapabilityQuery{{Type: "network"}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			entities, count, _, err := rm.GetEntities(&filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entities {
+				got = append(got, e.(*v1beta1.ComponentDefinition).Component.Kind)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got components %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got components %v, want %v", got, tt.want)
+				}
+			}
+			if tt.name != "page" && count != int64(len(tt.want)) {
+				t.Errorf("got count %d, want %d", count, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseCapabilities(t *testing.T) {
+	valid := []interface{}{workload("stateful")}
+	capabilities, err := v1beta1.ParseCapabilities(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capabilities) != 1 || capabilities[0].SubType != "stateful" {
+		t.Fatalf("unexpected capabilities %+v", capabilities)
+	}
+
+	for _, invalid := range []interface{}{
+		"workload",
+		[]interface{}{"workload"},
+		[]interface{}{map[string]interface{}{"displayName": "Workload", "kind": "view"}},
+	} {
+		if _, err := v1beta1.ParseCapabilities(invalid); err == nil {
+			t.Errorf("expected an error parsing %v", invalid)
+		}
+	}
+
+	comp := testComponent("Pod", map[string]interface{}{"kind": "view"})
+	if comp.HasCapabilities(v1beta1.CapabilityQuery{Kind: "view"}) {
+		t.Error("expected a component with invalid capabilities to have none")
+	}
+}
+
+func TestComponentFilterProvenance(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	for i, kind := range []string{"Deployment", "StatefulSet", "Secret"} {
+		comp := testComponent(kind)
+		if i < 2 {
+			comp.SetProvenance(v1beta1.Provenance{Package: "apps", PackageVersion: fmt.Sprintf("v1.%d.0", i)})
+		}
+		if err := rm.RegisterEntity(host, comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entities, count, _, err := rm.GetEntities(&regv1beta1.ComponentFilter{Provenance: &v1beta1.ProvenanceQuery{Package: "apps"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || len(entities) != 2 {
+		t.Errorf("expected the 2 components generated from apps, got %d", count)
+	}
+	entities, _, _, err = rm.GetEntities(&regv1beta1.ComponentFilter{Provenance: &v1beta1.ProvenanceQuery{PackageVersion: "v1.1.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].(*v1beta1.ComponentDefinition).Component.Kind != "StatefulSet" {
+		t.Errorf("unexpected components %+v", entities)
+	}
+}
+
+func TestRegisterComponentKeepsID(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	id := uuid.MustParse("6f1b61a6-7a3e-5c8e-9d7c-3e2b8f7a1c01")
+	schemas := []string{`{"type": "object"}`, `{"type": "object", "properties": {"replicas": {"type": "integer"}}}`}
+	for _, schema := range schemas {
+		c := testComponent("Deployment")
+		c.ID = id
+		c.Component.Schema = schema
+		if err := rm.RegisterEntity(host, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entities, _, _, err := rm.GetEntities(&regv1beta1.ComponentFilter{Name: "Deployment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].(*v1beta1.ComponentDefinition).ID != id {
+		t.Fatalf("expected the component to be registered once with its ID, got %+v", entities)
+	}
+	// the regenerated definition replaces the stored one
+	if got := entities[0].(*v1beta1.ComponentDefinition).Component.Schema; got != schemas[1] {
+		t.Errorf("expected the schema to be updated, got %s", got)
+	}
+	var entries int64
+	if err := rm.db.Model(&Registry{}).Where("entity = ?", id).Count(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entries != 1 {
+		t.Errorf("expected one registry entry for the component, got %d", entries)
+	}
+}
+
+func TestComponentRegisteredByTwoHosts(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	stableID := uuid.NewSHA1(uuid.Nil, []byte("kubernetes/v1.28.0/apps/v1/StatefulSet"))
+	for _, host := range []v1beta1.Host{{Hostname: "kubernetes"}, {Hostname: "artifacthub"}} {
+		comp := testComponent("StatefulSet")
+		comp.ID = stableID
+		comp.Model.Registrant = host
+		if err := rm.RegisterEntity(host, comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// registering again updates the component of the registrant instead of adding one
+	comp := testComponent("StatefulSet")
+	comp.ID = stableID
+	comp.Model.Registrant = v1beta1.Host{Hostname: "artifacthub"}
+	if err := rm.RegisterEntity(comp.Model.Registrant, comp); err != nil {
+		t.Fatal(err)
+	}
+
+	entities, count, _, err := rm.GetEntities(&regv1beta1.ComponentFilter{Name: "StatefulSet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected the component of each host, got %d", count)
+	}
+	hosts := map[string]bool{}
+	for _, e := range entities {
+		hosts[e.(*v1beta1.ComponentDefinition).Model.Registrant.Hostname] = true
+	}
+	if !hosts["kubernetes"] || !hosts["artifacthub"] {
+		t.Fatalf("expected the components of both hosts, got %v", hosts)
+	}
+}
@@ -0,0 +1,187 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+)
+
+const prometheusSchema = `{
+	"type": "object",
+	"properties": {
+		"url": {"type": "string"}
+	},
+	"required": ["url"]
+}`
+
+func newTestRegistryManager(t *testing.T) *RegistryManager {
+	t.Helper()
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: "file::memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.DBClose() })
+	rm, err := NewRegistryManager(&db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rm
+}
+
+func testModel() v1beta1.Model {
+	return v1beta1.Model{
+		Name:     "prometheus",
+		Category: v1beta1.Category{Name: "Observability and Analysis"},
+		Model:    v1beta1.ModelEntity{Version: "v2.47.0"},
+	}
+}
+
+func TestConnectionDefinitionRegistry(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "meshery"}
+
+	connection := &v1beta1.ConnectionDefinition{
+		Kind:            "prometheus",
+		DisplayName:     "Prometheus",
+		Schema:          prometheusSchema,
+		CredentialKinds: []string{"basic-auth"},
+		Model:           testModel(),
+	}
+	if err := rm.RegisterEntity(host, connection); err != nil {
+		t.Fatal(err)
+	}
+	credential := &v1beta1.CredentialDefinition{
+		Kind:         "basic-auth",
+		Schema:       `{"type": "object", "properties": {"username": {"type": "string"}, "password": {"type": "string"}}}`,
+		SecretFields: []string{"password"},
+		Model:        testModel(),
+	}
+	if err := rm.RegisterEntity(host, credential); err != nil {
+		t.Fatal(err)
+	}
+
+	entities, count, _, err := rm.GetEntities(&regv1beta1.ConnectionFilter{Kind: "prometheus", ModelName: "prometheus"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || len(entities) != 1 {
+		t.Fatalf("expected 1 connection definition, got %d", count)
+	}
+	got := entities[0].(*v1beta1.ConnectionDefinition)
+	if got.Status != entity.Enabled || got.Model.Name != "prometheus" || len(got.CredentialKinds) != 1 {
+		t.Fatalf("unexpected connection definition %+v", got)
+	}
+
+	if err := rm.UpdateEntityStatus(got.ID.String(), string(entity.Ignored), "connections"); err != nil {
+		t.Fatal(err)
+	}
+	_, count, _, err = rm.GetEntities(&regv1beta1.ConnectionFilter{Kind: "prometheus", Status: string(entity.Enabled)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no enabled connection definition, got %d", count)
+	}
+
+	got.DisplayName = "Prometheus Server"
+	if err := got.Update(rm.db); err != nil {
+		t.Fatal(err)
+	}
+	entities, _, _, err = rm.GetEntities(&regv1beta1.ConnectionFilter{Kind: "prom", Greedy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].(*v1beta1.ConnectionDefinition).DisplayName != "Prometheus Server" {
+		t.Fatalf("expected the updated connection definition, got %+v", entities)
+	}
+
+	entities, count, _, err = rm.GetEntities(&regv1beta1.CredentialFilter{Kind: "basic-auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 credential definition, got %d", count)
+	}
+	if err := entities[0].(*v1beta1.CredentialDefinition).Delete(rm.db); err != nil {
+		t.Fatal(err)
+	}
+	_, count, _, err = rm.GetEntities(&regv1beta1.CredentialFilter{Kind: "basic-auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the credential definition to be deleted, got %d", count)
+	}
+}
+
+func TestConnectionDefinitionValidation(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "meshery"}
+
+	invalid := []*v1beta1.ConnectionDefinition{
+		{Model: testModel()},
+		{Kind: "prometheus", Schema: "{", Model: testModel()},
+		{Kind: "prometheus", Transitions: map[v1beta1.ConnectionStatus][]v1beta1.ConnectionStatus{"unknown": {v1beta1.ConnectionConnected}}, Model: testModel()},
+	}
+	for _, def := range invalid {
+		if err := rm.RegisterEntity(host, def); err == nil {
+			t.Errorf("expected an error registering %+v", def)
+		}
+	}
+
+	def := &v1beta1.ConnectionDefinition{Kind: "prometheus", Schema: prometheusSchema}
+	if err := def.ValidateMetadata(map[string]interface{}{"url": "http://prometheus:9090"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := def.ValidateMetadata(map[string]interface{}{"url": 9090}); err == nil {
+		t.Error("expected an error validating metadata of the wrong type")
+	}
+}
+
+func TestConnectionTransitions(t *testing.T) {
+	def := &v1beta1.ConnectionDefinition{Kind: "kubernetes"}
+	tests := []struct {
+		from, to v1beta1.ConnectionStatus
+		allowed  bool
+	}{
+		{v1beta1.ConnectionDiscovered, v1beta1.ConnectionRegistered, true},
+		{v1beta1.ConnectionConnected, v1beta1.ConnectionMaintenance, true},
+		{v1beta1.ConnectionConnected, v1beta1.ConnectionConnected, true},
+		{v1beta1.ConnectionDeleted, v1beta1.ConnectionConnected, false},
+		{v1beta1.ConnectionDiscovered, v1beta1.ConnectionMaintenance, false},
+	}
+	for _, tt := range tests {
+		if got := def.CanTransition(tt.from, tt.to); got != tt.allowed {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.allowed)
+		}
+	}
+	if err := def.ValidateTransition(v1beta1.ConnectionDeleted, v1beta1.ConnectionConnected); err == nil {
+		t.Error("expected an error for a transition out of deleted")
+	}
+
+	custom := &v1beta1.ConnectionDefinition{
+		Kind:        "grafana",
+		Transitions: map[v1beta1.ConnectionStatus][]v1beta1.ConnectionStatus{v1beta1.ConnectionDiscovered: {v1beta1.ConnectionMaintenance}},
+	}
+	if !custom.CanTransition(v1beta1.ConnectionDiscovered, v1beta1.ConnectionMaintenance) {
+		t.Error("expected the custom transition to be allowed")
+	}
+	if custom.CanTransition(v1beta1.ConnectionDiscovered, v1beta1.ConnectionRegistered) {
+		t.Error("expected the default transitions not to apply to definitions with transitions of their own")
+	}
+}
+
+func TestCredentialDefinitionMaskSecret(t *testing.T) {
+	def := &v1beta1.CredentialDefinition{Kind: "basic-auth", SecretFields: []string{"password"}}
+	secret := map[string]interface{}{"username": "admin", "password": "s3cret"}
+	masked := def.MaskSecret(secret)
+	if masked["password"] == "s3cret" || masked["username"] != "admin" {
+		t.Errorf("unexpected masked secret %v", masked)
+	}
+	if secret["password"] != "s3cret" {
+		t.Error("expected the secret not to be modified")
+	}
+}
@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+)
+
+// DependencyFetcher fetches the entities of a model satisfying the dependency, e.g. from the model repository of Meshery,
+// to be registered from the host returned. The entities register the model, e.g. its components.
+type DependencyFetcher func(dep v1beta1.ModelDependency) (v1beta1.Host, []entity.Entity, error)
+
+// DependencyResolution is how a dependency of a model was resolved by ResolveDependencies.
+type DependencyResolution struct {
+	// Model is the name of the model declaring the dependency.
+	Model      string                  `json:"model"`
+	Dependency v1beta1.ModelDependency `json:"dependency"`
+	// Version is the version of the registered model satisfying the dependency.
+	Version string `json:"version,omitempty"`
+	// Fetched is set if the model was fetched and registered to satisfy the dependency.
+	Fetched bool `json:"fetched,omitempty"`
+	// Missing is set if no registered model satisfies the dependency, Reason being why.
+	Missing bool   `json:"missing,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ResolveDependencies verifies that the dependencies of the model, and their own dependencies, are satisfied by
+// registered models, e.g. before registering an imported model. The models missing are fetched and registered if a
+// fetcher is given. The resolutions of all the dependencies are returned, with an error listing the required
+// dependencies missing, if any. Registering the entities of a model doesn't resolve its dependencies, the importers
+// of models call ResolveDependencies before RegisterEntity.
+func (rm *RegistryManager) ResolveDependencies(model v1beta1.Model, fetch DependencyFetcher) ([]DependencyResolution, error) {
+	var resolutions []DependencyResolution
+	visited := map[string]bool{model.Name: true}
+	queue := []v1beta1.Model{model}
+	for len(queue) > 0 {
+		m := queue[0]
+		queue = queue[1:]
+		for _, dep := range m.Dependencies {
+			resolution := DependencyResolution{Model: m.Name, Dependency: dep}
+			// the model being resolved satisfies the dependencies on itself, e.g. of the models it depends on
+			if dep.Name == model.Name {
+				if ok, err := dep.SatisfiedBy(model.Model.Version); err == nil && ok {
+					resolution.Version = model.Model.Version
+					resolutions = append(resolutions, resolution)
+					continue
+				}
+			}
+			satisfying, err := rm.satisfyingModel(dep)
+			if err != nil {
+				return resolutions, ErrResolveDependencies(err, model.Name)
+			}
+			if satisfying == nil && fetch != nil {
+				if satisfying, err = rm.fetchDependency(dep, fetch); err != nil {
+					resolution.Reason = err.Error()
+				}
+				resolution.Fetched = satisfying != nil
+			}
+			if satisfying == nil {
+				resolution.Missing = true
+				if resolution.Reason == "" {
+					resolution.Reason = "no registered model satisfies the dependency"
+				}
+				resolutions = append(resolutions, resolution)
+				continue
+			}
+			resolution.Version = satisfying.Model.Version
+			resolutions = append(resolutions, resolution)
+			if !visited[satisfying.Name] {
+				visited[satisfying.Name] = true
+				queue = append(queue, *satisfying)
+			}
+		}
+	}
+
+	var missing []string
+	for _, r := range resolutions {
+		if r.Missing && !r.Dependency.Optional {
+			missing = append(missing, fmt.Sprintf("%s requires %s: %s", r.Model, r.Dependency, r.Reason))
+		}
+	}
+	if len(missing) > 0 {
+		return resolutions, ErrMissingDependencies(model.Name, missing)
+	}
+	return resolutions, nil
+}
+
+// satisfyingModel returns the registered model of the highest version satisfying the dependency, nil if there is none.
+func (rm *RegistryManager) satisfyingModel(dep v1beta1.ModelDependency) (*v1beta1.Model, error) {
+	var models []v1beta1.Model
+	if err := rm.db.Where("name = ?", dep.Name).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	var satisfying *v1beta1.Model
+	for i := range models {
+		ok, err := dep.SatisfiedBy(models[i].Model.Version)
+		if err != nil {
+			return nil, err
+		}
+		if ok && (satisfying == nil || v1beta1.CompareModelVersions(models[i].Model.Version, satisfying.Model.Version) > 0) {
+			satisfying = &models[i]
+		}
+	}
+	return satisfying, nil
+}
+
+// fetchDependency registers the entities fetched for the dependency, and returns the model registered.
+func (rm *RegistryManager) fetchDependency(dep v1beta1.ModelDependency, fetch DependencyFetcher) (*v1beta1.Model, error) {
+	host, entities, err := fetch(dep)
+	if err != nil {
+		return nil, fmt.Errorf("fetching the model failed: %w", err)
+	}
+	for _, en := range entities {
+		if err := rm.RegisterEntity(host, en); err != nil {
+			return nil, fmt.Errorf("registering the model fetched failed: %w", err)
+		}
+	}
+	satisfying, err := rm.satisfyingModel(dep)
+	if err != nil {
+		return nil, err
+	}
+	if satisfying == nil {
+		return nil, fmt.Errorf("the model fetched doesn't satisfy the dependency")
+	}
+	return satisfying, nil
+}
+
+// VerifyDesignModels verifies that the models of the components of the design are registered, in the version of the
+// component if it has one, e.g. before importing the design, so that it doesn't reference components which can't be
+// deployed. The error lists the models missing.
+func (rm *RegistryManager) VerifyDesignModels(design v1beta1.Design) error {
+	checked := map[string]bool{}
+	var missing []string
+	for _, c := range design.Components {
+		dep := v1beta1.ModelDependency{Name: c.Model, Version: c.ModelVersion}
+		if checked[dep.String()] {
+			continue
+		}
+		checked[dep.String()] = true
+		satisfying, err := rm.satisfyingModel(dep)
+		if err != nil {
+			return ErrResolveDependencies(err, design.Name)
+		}
+		if satisfying == nil {
+			missing = append(missing, fmt.Sprintf("the component %s requires the model %s", c.Name, dep))
+		}
+	}
+	if len(missing) > 0 {
+		return ErrMissingDependencies(design.Name, missing)
+	}
+	return nil
+}
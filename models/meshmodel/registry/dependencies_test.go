@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+)
+
+func TestResolveDependencies(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	if err := rm.RegisterEntity(host, testComponent("Deployment")); err != nil {
+		t.Fatal(err)
+	}
+
+	operator := v1beta1.Model{Name: "cert-manager", Dependencies: []v1beta1.ModelDependency{
+		{Name: "kubernetes", Version: ">= 1.25"},
+		{Name: "prometheus", Optional: true},
+	}}
+	resolutions, err := rm.ResolveDependencies(operator, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolutions) != 2 || resolutions[0].Version != "v1.28.0" || !resolutions[1].Missing {
+		t.Errorf("unexpected resolutions %+v", resolutions)
+	}
+
+	operator.Dependencies[0].Version = ">= 1.29"
+	if _, err := rm.ResolveDependencies(operator, nil); err == nil {
+		t.Error("expected an error resolving a dependency on a version not registered")
+	}
+
+	fetched := 0
+	fetch := func(dep v1beta1.ModelDependency) (v1beta1.Host, []entity.Entity, error) {
+		fetched++
+		if dep.Name != "kubernetes" {
+			return v1beta1.Host{}, nil, fmt.Errorf("model %s not found", dep.Name)
+		}
+		c := testComponent("Deployment")
+		c.Model.Model.Version = "v1.29.2"
+		// a cycle back to the model being resolved
+		c.Model.Dependencies = []v1beta1.ModelDependency{{Name: "cert-manager"}}
+		return host, []entity.Entity{c}, nil
+	}
+	resolutions, err = rm.ResolveDependencies(operator, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched != 2 || len(resolutions) != 3 || !resolutions[0].Fetched || resolutions[0].Version != "v1.29.2" || resolutions[1].Reason == "" || resolutions[2].Missing {
+		t.Errorf("unexpected resolutions %+v after fetching %d models", resolutions, fetched)
+	}
+}
+
+func TestVerifyDesignModels(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	if err := rm.RegisterEntity(v1beta1.Host{Hostname: "kubernetes"}, testComponent("Deployment")); err != nil {
+		t.Fatal(err)
+	}
+
+	design := v1beta1.Design{Name: "app", Components: []v1beta1.DesignComponent{
+		{Name: "web", Kind: "Deployment", Model: "kubernetes", ModelVersion: "v1.28.0"},
+		{Name: "api", Kind: "Deployment", Model: "kubernetes"},
+	}}
+	if err := rm.VerifyDesignModels(design); err != nil {
+		t.Fatal(err)
+	}
+	design.Components = append(design.Components,
+		v1beta1.DesignComponent{Name: "gateway", Kind: "Gateway", Model: "istio-base"},
+		v1beta1.DesignComponent{Name: "db", Kind: "StatefulSet", Model: "kubernetes", ModelVersion: "v1.27.0"},
+	)
+	if err := rm.VerifyDesignModels(design); err == nil {
+		t.Error("expected an error verifying a design referencing models not registered")
+	}
+}
+
+func TestRegisterModelUpdatesDependencies(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	c := testComponent("Deployment")
+	c.Model.Dependencies = []v1beta1.ModelDependency{{Name: "etcd"}}
+	if err := rm.RegisterEntity(host, c); err != nil {
+		t.Fatal(err)
+	}
+
+	c = testComponent("StatefulSet")
+	c.Model.Dependencies = []v1beta1.ModelDependency{{Name: "etcd", Version: ">= 3.5"}}
+	if err := rm.RegisterEntity(host, c); err != nil {
+		t.Fatal(err)
+	}
+	// the components not declaring the dependencies of their model leave them as is
+	if err := rm.RegisterEntity(host, testComponent("DaemonSet")); err != nil {
+		t.Fatal(err)
+	}
+	var models []v1beta1.Model
+	if err := rm.db.Where("name = ?", "kubernetes").Find(&models).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || len(models[0].Dependencies) != 1 || models[0].Dependencies[0].Version != ">= 3.5" {
+		t.Errorf("expected the dependencies of the model registered again, got %+v", models)
+	}
+}
@@ -1,14 +1,53 @@
 package registry
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/layer5io/meshkit/errors"
 )
 
 var (
 	ErrUnknownHostCode    = "meshkit-11146"
 	ErrRegisterEntityCode = ""
+	ErrGetSummaryCode     = "meshkit-11298"
+	ErrUnknownTraitCode   = "meshkit-11319"
+	ErrCheckHostsCode     = "meshkit-11320"
+
+	ErrResolveDependenciesCode = "meshkit-11339"
+	ErrMissingDependenciesCode = "meshkit-11340"
+	ErrComponentOverridesCode  = "meshkit-11342"
+	ErrExportSiteCode          = "meshkit-11343"
 )
 
 func ErrUnknownHost(err error) error {
 	return errors.New(ErrUnknownHostCode, errors.Alert, []string{"host is not supported"}, []string{err.Error()}, []string{"The component's host is not supported by the version of server you are running"}, []string{"Try upgrading to latest available version"})
 }
+
+func ErrGetSummary(err error) error {
+	return errors.New(ErrGetSummaryCode, errors.Alert, []string{"unable to summarize the registry"}, []string{err.Error()}, []string{"The tables of the registry are missing or the database is not reachable"}, []string{"Make sure the registry is initialized and the database is reachable"})
+}
+
+func ErrUnknownTrait(component string, traits []string) error {
+	return errors.New(ErrUnknownTraitCode, errors.Alert, []string{fmt.Sprintf("unknown traits of the component %s", component)}, []string{fmt.Sprintf("the traits %s are not registered", strings.Join(traits, ", "))}, []string{"The traits were not registered before the component", "The names of the traits in the metadata of the component are misspelled"}, []string{"Register the traits with RegisterTraits before the components composing them"})
+}
+
+func ErrCheckHosts(err error) error {
+	return errors.New(ErrCheckHostsCode, errors.Alert, []string{"unable to record the status of the hosts"}, []string{err.Error()}, []string{"The tables of the registry are missing or the database is not reachable"}, []string{"Make sure the registry is initialized and the database is reachable"})
+}
+
+func ErrResolveDependencies(err error, name string) error {
+	return errors.New(ErrResolveDependenciesCode, errors.Alert, []string{fmt.Sprintf("unable to resolve the model dependencies of %s", name)}, []string{err.Error()}, []string{"The version constraint of a dependency is invalid", "The database of the registry is not reachable"}, []string{"Make sure the versions of the dependencies are semantic version constraints, e.g. \">= 1.25\"", "Make sure the registry is initialized and the database is reachable"})
+}
+
+func ErrMissingDependencies(name string, missing []string) error {
+	return errors.New(ErrMissingDependenciesCode, errors.Alert, []string{fmt.Sprintf("the models required by %s are not registered", name)}, []string{strings.Join(missing, "\n")}, []string{"The models required were not imported, or not in a version satisfying the constraints", "The models could not be fetched"}, []string{"Import the required models in a satisfying version before importing the model or design referencing them"})
+}
+
+func ErrComponentOverrides(err error, action string) error {
+	return errors.New(ErrComponentOverridesCode, errors.Alert, []string{fmt.Sprintf("unable to %s the component overrides", action)}, []string{err.Error()}, []string{"The tables of the registry are missing or the database is not reachable"}, []string{"Make sure the registry is initialized and the database is reachable"})
+}
+
+func ErrExportSite(err error, name string) error {
+	return errors.New(ErrExportSiteCode, errors.Alert, []string{fmt.Sprintf("unable to export the site data of %s", name)}, []string{err.Error()}, []string{"The output directory is not writable", "The template of the pages failed to render", "The name of a model is not a valid directory name", "The database of the registry is not reachable"}, []string{"Make sure the output directory is writable and the template only references fields of the SitePage", "Rename the model so that its name is a single path element"})
+}
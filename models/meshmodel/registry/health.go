@@ -0,0 +1,155 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultHealthCheckTimeout is the time a health check of a host may take unless configured otherwise.
+	DefaultHealthCheckTimeout = 30 * time.Second
+	// DefaultHealthCheckInterval is the time between two checks of the hosts by StartHealthChecks unless configured otherwise.
+	DefaultHealthCheckInterval = 5 * time.Minute
+)
+
+// HostStatus is the connectivity of a registrant representing a live source, e.g. a cluster or ArtifactHub.
+type HostStatus struct {
+	HostID   uuid.UUID `json:"hostId" gorm:"primaryKey"`
+	Hostname string    `json:"hostname"`
+	// Reachable reports whether the last health check of the host succeeded.
+	Reachable bool `json:"reachable"`
+	// Error is the error of the last health check, if it failed.
+	Error     string    `json:"error,omitempty"`
+	LastCheck time.Time `json:"lastCheck"`
+	// LastSync is the last time an entity of the host was registered.
+	LastSync time.Time `json:"lastSync"`
+}
+
+// Stale reports whether the host wasn't checked or synced successfully in the last maxAge.
+func (s HostStatus) Stale(maxAge time.Duration) bool {
+	since := time.Now().Add(-maxAge)
+	return !s.Reachable || s.LastCheck.Before(since) && s.LastSync.Before(since)
+}
+
+// HostHealthCheck checks the connectivity of a host, it returns an error if the host is not reachable.
+type HostHealthCheck func(ctx context.Context, h v1beta1.Host) error
+
+// HTTPHealthCheck checks that a GET of the url returns a status below 400, e.g. for https://artifacthub.io/api/v1/stats.
+func HTTPHealthCheck(client *http.Client, url string) HostHealthCheck {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, _ v1beta1.Host) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		return nil
+	}
+}
+
+// KubernetesHealthCheck checks that the API server of the cluster of the client is reachable.
+func KubernetesHealthCheck(client *kubernetes.Client) HostHealthCheck {
+	return func(ctx context.Context, _ v1beta1.Host) error {
+		return client.KubeClient.Discovery().RESTClient().Get().AbsPath("/readyz").Do(ctx).Error()
+	}
+}
+
+// HealthCheckOptions configures the health checks of the hosts.
+type HealthCheckOptions struct {
+	// Checks are the health checks by hostname, e.g. "artifacthub" or "kubernetes". Hosts without check are not checked.
+	Checks map[string]HostHealthCheck
+	// Interval is the time between two checks of the hosts by StartHealthChecks, DefaultHealthCheckInterval if zero.
+	Interval time.Duration
+	// Timeout is the time a check may take, DefaultHealthCheckTimeout if zero.
+	Timeout time.Duration
+}
+
+// CheckHosts runs the health checks of the registered hosts once and records their status.
+func (rm *RegistryManager) CheckHosts(ctx context.Context, opts HealthCheckOptions) error {
+	var hosts []v1beta1.Host
+	if err := rm.db.Find(&hosts).Error; err != nil {
+		return ErrCheckHosts(err)
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	for _, h := range hosts {
+		check, ok := opts.Checks[h.Hostname]
+		if !ok {
+			continue
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := check(checkCtx, h)
+		cancel()
+		status := HostStatus{HostID: h.ID, Hostname: h.Hostname, Reachable: err == nil, LastCheck: time.Now()}
+		if err != nil {
+			status.Error = err.Error()
+		}
+		err = rm.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "host_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"hostname", "reachable", "error", "last_check"}),
+		}).Create(&status).Error
+		if err != nil {
+			return ErrCheckHosts(err)
+		}
+	}
+	return nil
+}
+
+// StartHealthChecks checks the hosts at each interval until the context is done. Errors recording the status are
+// passed to onError, if not nil.
+func (rm *RegistryManager) StartHealthChecks(ctx context.Context, opts HealthCheckOptions, onError func(error)) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := rm.CheckHosts(ctx, opts); err != nil && onError != nil {
+				onError(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// GetHostStatuses returns the status of the hosts which were checked or synced.
+func (rm *RegistryManager) GetHostStatuses() ([]HostStatus, error) {
+	var statuses []HostStatus
+	if err := rm.db.Order("hostname").Find(&statuses).Error; err != nil {
+		return nil, ErrCheckHosts(err)
+	}
+	return statuses, nil
+}
+
+// recordSync records the registration of an entity of the host.
+func (rm *RegistryManager) recordSync(h v1beta1.Host, hostID uuid.UUID) error {
+	status := HostStatus{HostID: hostID, Hostname: h.Hostname, Reachable: true, LastSync: time.Now()}
+	return rm.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "host_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_sync"}),
+	}).Create(&status).Error
+}
@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestCheckHosts(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	prometheus := testComponent("Prometheus")
+	prometheus.Model = testModel()
+	if err := rm.RegisterEntity(v1beta1.Host{Hostname: "artifacthub"}, prometheus); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.RegisterEntity(v1beta1.Host{Hostname: "kubernetes"}, testComponent("Deployment")); err != nil {
+		t.Fatal(err)
+	}
+
+	artifactHub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer artifactHub.Close()
+	cluster := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer cluster.Close()
+
+	err := rm.CheckHosts(context.Background(), HealthCheckOptions{Checks: map[string]HostHealthCheck{
+		"artifacthub": HTTPHealthCheck(nil, artifactHub.URL),
+		"kubernetes":  HTTPHealthCheck(nil, cluster.URL),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := rm.GetHostStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected the status of 2 hosts, got %+v", statuses)
+	}
+	artifactHubStatus, clusterStatus := statuses[0], statuses[1]
+	if !artifactHubStatus.Reachable || artifactHubStatus.LastSync.IsZero() || artifactHubStatus.LastCheck.IsZero() || artifactHubStatus.Stale(time.Hour) {
+		t.Errorf("unexpected status of artifacthub %+v", artifactHubStatus)
+	}
+	if clusterStatus.Reachable || clusterStatus.Error == "" || !clusterStatus.Stale(time.Hour) {
+		t.Errorf("unexpected status of the cluster %+v", clusterStatus)
+	}
+}
+
+func TestStartHealthChecks(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	if err := rm.RegisterEntity(v1beta1.Host{Hostname: "kubernetes"}, testComponent("Deployment")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, interval := range []time.Duration{0, time.Millisecond} {
+		checks := make(chan struct{}, 10)
+		check := func(ctx context.Context, h v1beta1.Host) error {
+			select {
+			case checks <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+		errs := make(chan error, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		rm.StartHealthChecks(ctx, HealthCheckOptions{Interval: interval, Checks: map[string]HostHealthCheck{"kubernetes": check}}, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+		want := 1
+		if interval > 0 {
+			want = 3
+		}
+		for i := 0; i < want; i++ {
+			select {
+			case <-checks:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("interval %s: got %d checks, want %d", interval, i, want)
+			}
+		}
+		cancel()
+		select {
+		case err := <-errs:
+			t.Errorf("interval %s: %v", interval, err)
+		default:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gorm.io/gorm"
+)
+
+// OverrideFilter selects component overrides by layer and target, empty fields matching any.
+type OverrideFilter struct {
+	Layers []string
+	Model  string
+	Kind   string
+}
+
+func (f OverrideFilter) apply(db *gorm.DB) *gorm.DB {
+	if len(f.Layers) > 0 {
+		db = db.Where("layer IN ?", f.Layers)
+	}
+	if f.Model != "" {
+		db = db.Where("model = ?", f.Model)
+	}
+	if f.Kind != "" {
+		db = db.Where("kind = ?", f.Kind)
+	}
+	return db
+}
+
+// SetComponentOverride stores the override, replacing the override of the same layer and target if there is one.
+func (rm *RegistryManager) SetComponentOverride(o *v1beta1.ComponentOverride) error {
+	var existing v1beta1.ComponentOverride
+	err := rm.db.Where("layer = ? AND model = ? AND kind = ? AND api_version = ?", o.Layer, o.Model, o.Kind, o.APIVersion).
+		Limit(1).Find(&existing).Error
+	if err != nil {
+		return ErrComponentOverrides(err, "set")
+	}
+	now := time.Now()
+	if existing.ID != uuid.Nil {
+		o.ID, o.CreatedAt = existing.ID, existing.CreatedAt
+	} else {
+		o.ID, o.CreatedAt = uuid.New(), now
+	}
+	o.UpdatedAt = now
+	if err := rm.db.Save(o).Error; err != nil {
+		return ErrComponentOverrides(err, "set")
+	}
+	return nil
+}
+
+// ListComponentOverrides returns the overrides selected by the filter, in increasing precedence.
+func (rm *RegistryManager) ListComponentOverrides(f OverrideFilter) ([]v1beta1.ComponentOverride, error) {
+	var overrides []v1beta1.ComponentOverride
+	if err := f.apply(rm.db.Model(&v1beta1.ComponentOverride{})).Order("precedence, created_at").Find(&overrides).Error; err != nil {
+		return nil, ErrComponentOverrides(err, "list")
+	}
+	return overrides, nil
+}
+
+// ClearComponentOverrides deletes the overrides selected by the filter, e.g. all the overrides of a user, and returns
+// the number of overrides deleted. The definitions are left as provided by their registrants.
+func (rm *RegistryManager) ClearComponentOverrides(f OverrideFilter) (int64, error) {
+	result := f.apply(rm.db.Session(&gorm.Session{AllowGlobalUpdate: true})).Delete(&v1beta1.ComponentOverride{})
+	if result.Error != nil {
+		return 0, ErrComponentOverrides(result.Error, "clear")
+	}
+	return result.RowsAffected, nil
+}
+
+// ApplyComponentOverrides returns copies of the component definitions with the overrides of the layers applied, of
+// all the layers if none is given. The definitions stored in the registry are not changed.
+func (rm *RegistryManager) ApplyComponentOverrides(components []v1beta1.ComponentDefinition, layers ...string) ([]v1beta1.ComponentDefinition, error) {
+	overrides, err := rm.ListComponentOverrides(OverrideFilter{Layers: layers})
+	if err != nil {
+		return nil, err
+	}
+	overridden := make([]v1beta1.ComponentDefinition, 0, len(components))
+	for _, c := range components {
+		o, err := c.WithOverrides(overrides...)
+		if err != nil {
+			return nil, err
+		}
+		overridden = append(overridden, o)
+	}
+	return overridden, nil
+}
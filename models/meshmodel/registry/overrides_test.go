@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestWithOverrides(t *testing.T) {
+	c := *testComponent("Deployment")
+	c.Component.Schema = `{"type": "object", "properties": {"replicas": {"type": "integer"}, "paused": {"type": "boolean"}}}`
+	c.Metadata["styles"] = map[string]interface{}{"shape": "circle", "color": "#00b39f"}
+
+	overridden, err := c.WithOverrides(
+		v1beta1.ComponentOverride{Layer: "user", Precedence: 2, Kind: "Deployment", Styles: map[string]interface{}{"color": "#ff0000"}},
+		v1beta1.ComponentOverride{Layer: "org", Precedence: 1, Model: "kubernetes", Styles: map[string]interface{}{"color": "#0000ff", "shape": "rectangle"},
+			SchemaPatch: map[string]interface{}{"properties": map[string]interface{}{"paused": nil, "replicas": map[string]interface{}{"maximum": 10}}}},
+		v1beta1.ComponentOverride{Layer: "other", Kind: "StatefulSet", Metadata: map[string]interface{}{"hidden": true}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	styles := overridden.Metadata["styles"].(map[string]interface{})
+	if styles["color"] != "#ff0000" || styles["shape"] != "rectangle" || overridden.Metadata["hidden"] != nil {
+		t.Errorf("unexpected metadata %+v", overridden.Metadata)
+	}
+	if layers := overridden.Metadata[v1beta1.OverridesMetadataKey].([]string); len(layers) != 2 || layers[0] != "org" {
+		t.Errorf("layers = %v", layers)
+	}
+	var schema struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(overridden.Component.Schema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Properties) != 1 || schema.Properties["replicas"]["maximum"] != float64(10) {
+		t.Errorf("unexpected schema %s", overridden.Component.Schema)
+	}
+
+	if c.Metadata["styles"].(map[string]interface{})["color"] != "#00b39f" || c.Metadata[v1beta1.OverridesMetadataKey] != nil ||
+		len(c.Component.Schema) == len(overridden.Component.Schema) {
+		t.Errorf("the original definition was changed: %+v", c)
+	}
+}
+
+func TestComponentOverrides(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	set := func(o v1beta1.ComponentOverride) {
+		t.Helper()
+		if err := rm.SetComponentOverride(&o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set(v1beta1.ComponentOverride{Layer: "alice", Kind: "Deployment", Metadata: map[string]interface{}{"hidden": true}})
+	set(v1beta1.ComponentOverride{Layer: "alice", Kind: "Deployment", Metadata: map[string]interface{}{"hidden": false}})
+	set(v1beta1.ComponentOverride{Layer: "bob", Kind: "Deployment", Metadata: map[string]interface{}{"pinned": true}})
+
+	overrides, err := rm.ListComponentOverrides(OverrideFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 2 {
+		t.Fatalf("expected the override of alice to be replaced, got %+v", overrides)
+	}
+
+	components, err := rm.ApplyComponentOverrides([]v1beta1.ComponentDefinition{*testComponent("Deployment")}, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if components[0].Metadata["hidden"] != false || components[0].Metadata["pinned"] != nil {
+		t.Errorf("unexpected metadata %+v", components[0].Metadata)
+	}
+
+	cleared, err := rm.ClearComponentOverrides(OverrideFilter{Layers: []string{"alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overrides, _ = rm.ListComponentOverrides(OverrideFilter{}); cleared != 1 || len(overrides) != 1 || overrides[0].Layer != "bob" {
+		t.Errorf("cleared %d overrides, left %+v", cleared, overrides)
+	}
+}
@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+)
+
+const denyPrivilegedModule = `package meshery.policies.privileged
+
+deny[msg] {
+	input.components[_].configuration.securityContext.privileged
+	msg := "privileged containers are not allowed"
+}
+`
+
+func testPolicy(kind, version string, createdAt time.Time, selectors ...v1beta1.PolicySelector) *v1beta1.PolicyDefinition {
+	return &v1beta1.PolicyDefinition{
+		TypeMeta:         v1beta1.TypeMeta{Kind: kind, Version: version},
+		Model:            testModel(),
+		Modules:          map[string]string{"privileged.rego": denyPrivilegedModule},
+		ParametersSchema: `{"type": "object", "properties": {"allowedRegistries": {"type": "array", "items": {"type": "string"}}}}`,
+		Selectors:        selectors,
+		CreatedAt:        createdAt,
+	}
+}
+
+func TestPolicyDefinitionRegistry(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "meshery"}
+	now := time.Now()
+
+	for _, p := range []*v1beta1.PolicyDefinition{
+		testPolicy("deny-privileged", "v1.1.0", now.Add(-time.Hour), v1beta1.PolicySelector{Kind: "Deployment"}),
+		testPolicy("deny-privileged", "v1.0.0", now, v1beta1.PolicySelector{Kind: "Deployment"}),
+		testPolicy("allowed-registries", "v1.0.0", now, v1beta1.PolicySelector{Kind: "Pod", Model: "kubernetes"}),
+		testPolicy("naming", "v1.0.0", now),
+	} {
+		if err := rm.RegisterEntity(host, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, count, _, err := rm.GetEntities(&regv1beta1.PolicyFilter{Kind: "deny-privileged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected both versions of the policy, got %d", count)
+	}
+	entities, count, _, err := rm.GetEntities(&regv1beta1.PolicyFilter{Kind: "deny-privileged", Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || entities[0].(*v1beta1.PolicyDefinition).Version != "v1.1.0" {
+		t.Fatalf("expected the highest version of the policy, got %+v", entities)
+	}
+	got := entities[0].(*v1beta1.PolicyDefinition)
+	if got.Model.Name != "prometheus" || got.Modules["privileged.rego"] != denyPrivilegedModule {
+		t.Fatalf("unexpected policy definition %+v", got)
+	}
+
+	design := v1beta1.NewDesign("meshery")
+	design.Components = append(design.Components, v1beta1.DesignComponent{Name: "meshery", Kind: "Deployment", Model: "kubernetes"})
+	entities, count, _, err = rm.GetEntities(&regv1beta1.PolicyFilter{Design: &design, Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]bool{}
+	for _, e := range entities {
+		kinds[e.(*v1beta1.PolicyDefinition).Kind] = true
+	}
+	if count != 2 || !kinds["deny-privileged"] || !kinds["naming"] {
+		t.Fatalf("expected the policies selecting the deployment and the policy without selectors, got %v", kinds)
+	}
+
+	// Definitions decoded from requests carry no model ID, the update must keep the stored one.
+	got.SubType = "security"
+	got.ModelID = uuid.Nil
+	if err := got.Update(rm.db); err != nil {
+		t.Fatal(err)
+	}
+	entities, count, _, err = rm.GetEntities(&regv1beta1.PolicyFilter{Kind: "deny-privileged", Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || entities[0].(*v1beta1.PolicyDefinition).SubType != "security" {
+		t.Fatalf("expected the updated policy to keep its model, got %+v", entities)
+	}
+	if err := got.Delete(rm.db); err != nil {
+		t.Fatal(err)
+	}
+	_, count, _, err = rm.GetEntities(&regv1beta1.PolicyFilter{Kind: "deny-privileged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the deleted version to be removed, got %d", count)
+	}
+}
+
+func TestPolicyDefinitionValidation(t *testing.T) {
+	invalid := []*v1beta1.PolicyDefinition{
+		{Modules: map[string]string{"privileged.rego": denyPrivilegedModule}},
+		{TypeMeta: v1beta1.TypeMeta{Kind: "deny-privileged"}, Modules: map[string]string{"privileged.rego": "package"}},
+		{TypeMeta: v1beta1.TypeMeta{Kind: "deny-privileged"}, ParametersSchema: "{"},
+	}
+	for _, def := range invalid {
+		if err := def.Validate(); err == nil {
+			t.Errorf("expected an error validating %+v", def)
+		}
+	}
+
+	def := testPolicy("allowed-registries", "v1.0.0", time.Now())
+	if err := def.ValidateParameters(map[string]interface{}{"allowedRegistries": []interface{}{"docker.io"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := def.ValidateParameters(map[string]interface{}{"allowedRegistries": "docker.io"}); err == nil {
+		t.Error("expected an error validating parameters of the wrong type")
+	}
+}
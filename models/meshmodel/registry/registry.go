@@ -3,15 +3,18 @@ package registry
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/errors"
 	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
 	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
 	"github.com/layer5io/meshkit/models/meshmodel/entity"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -39,6 +42,18 @@ type Registry struct {
 // RegistryManager instance will expose methods for registry operations & sits between the database level operations and user facing API handlers.
 type RegistryManager struct {
 	db *database.Handler //This database handler will be used to perform queries inside the database
+
+	traitsMu sync.RWMutex
+	traits   map[string]v1beta1.Trait
+
+	// logger is warned about the non-fatal errors of the registrations
+	logger errors.Warner
+}
+
+// SetLogger sets the logger warned about the non-fatal errors of the registrations, e.g. failing to record the
+// last sync of a host, none if nil.
+func (rm *RegistryManager) SetLogger(logger errors.Warner) {
+	rm.logger = logger
 }
 
 // NewRegistryManager initializes the registry manager by creating appropriate tables.
@@ -48,7 +63,8 @@ func NewRegistryManager(db *database.Handler) (*RegistryManager, error) {
 		return nil, fmt.Errorf("nil database handler")
 	}
 	rm := RegistryManager{
-		db: db,
+		db:     db,
+		traits: map[string]v1beta1.Trait{},
 	}
 	err := rm.db.AutoMigrate(
 		&Registry{},
@@ -58,6 +74,11 @@ func NewRegistryManager(db *database.Handler) (*RegistryManager, error) {
 		&v1beta1.PolicyDefinition{},
 		&v1beta1.Model{},
 		&v1beta1.Category{},
+		&v1beta1.ConnectionDefinition{},
+		&v1beta1.CredentialDefinition{},
+		&v1beta1.AnnotationDefinition{},
+		&v1beta1.ComponentOverride{},
+		&HostStatus{},
 	)
 	if err != nil {
 		return nil, err
@@ -72,9 +93,25 @@ func (rm *RegistryManager) Cleanup() {
 		&v1beta1.Model{},
 		&v1beta1.Category{},
 		&v1alpha2.RelationshipDefinition{},
+		&v1beta1.PolicyDefinition{},
+		&v1beta1.ConnectionDefinition{},
+		&v1beta1.CredentialDefinition{},
+		&v1beta1.AnnotationDefinition{},
+		&v1beta1.ComponentOverride{},
+		&HostStatus{},
 	)
 }
+
+// RegisterEntity registers the entity of the host. The components marked as annotations are registered as
+// annotation definitions.
 func (rm *RegistryManager) RegisterEntity(h v1beta1.Host, en entity.Entity) error {
+	if c, ok := en.(*v1beta1.ComponentDefinition); ok {
+		if annotation, ok := v1beta1.AnnotationFromComponent(*c); ok {
+			en = &annotation
+		} else if err := rm.composeTraits(c); err != nil {
+			return err
+		}
+	}
 	registrantID, err := h.Create(rm.db)
 	if err != nil {
 		return err
@@ -85,21 +122,65 @@ func (rm *RegistryManager) RegisterEntity(h v1beta1.Host, en entity.Entity) erro
 		return err
 	}
 
-	entry := Registry{
-		ID:           uuid.New(),
-		RegistrantID: registrantID,
-		Entity:       entityID,
-		Type:         en.Type(),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+	// an entity registered again by the same registrant keeps its entry
+	var entry Registry
+	err = rm.db.Where("registrant_id = ? AND entity = ?", registrantID, entityID).Limit(1).Find(&entry).Error
+	if err != nil {
+		return err
+	}
+	if entry.ID != uuid.Nil {
+		err = rm.db.Model(&entry).Update("updated_at", time.Now()).Error
+	} else {
+		entry = Registry{
+			ID:           uuid.New(),
+			RegistrantID: registrantID,
+			Entity:       entityID,
+			Type:         en.Type(),
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+		err = rm.db.Create(&entry).Error
 	}
-	err = rm.db.Create(&entry).Error
 	if err != nil {
 		return err
 	}
+	// the entity is registered, failing to record the sync only makes the status of the host stale
+	if err := rm.recordSync(h, registrantID); err != nil && rm.logger != nil {
+		rm.logger.Warn(ErrCheckHosts(err))
+	}
 	return nil
 }
 
+// RemoveStaleComponents removes the components registered by the host but the ones kept, e.g. the resources a cluster
+// no longer serves once its components are registered again, and returns the number of components removed.
+func (rm *RegistryManager) RemoveStaleComponents(h v1beta1.Host, keep []uuid.UUID) (int64, error) {
+	registrantID, err := h.Create(rm.db)
+	if err != nil {
+		return 0, err
+	}
+	var removed int64
+	err = rm.db.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&Registry{}).Where("registrant_id = ? AND type = ?", registrantID, entity.ComponentDefinition)
+		if len(keep) != 0 {
+			stale = stale.Where("entity NOT IN ?", keep)
+		}
+		var ids []uuid.UUID
+		if err := stale.Pluck("entity", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		result := tx.Where("id IN ?", ids).Delete(&v1beta1.ComponentDefinition{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removed = result.RowsAffected
+		return tx.Where("registrant_id = ? AND entity IN ?", registrantID, ids).Delete(&Registry{}).Error
+	})
+	return removed, err
+}
+
 // UpdateEntityStatus updates the ignore status of an entity based on the provided parameters.
 // By default during models generation ignore is set to false
 func (rm *RegistryManager) UpdateEntityStatus(ID string, status string, entityType string) error {
@@ -116,6 +197,15 @@ func (rm *RegistryManager) UpdateEntityStatus(ID string, status string, entityTy
 			return err
 		}
 		return nil
+	case "connections":
+		connectionDefinition := v1beta1.ConnectionDefinition{ID: entityID}
+		return connectionDefinition.UpdateStatus(rm.db, entity.EntityStatus(status))
+	case "credentials":
+		credentialDefinition := v1beta1.CredentialDefinition{ID: entityID}
+		return credentialDefinition.UpdateStatus(rm.db, entity.EntityStatus(status))
+	case "annotations":
+		annotationDefinition := v1beta1.AnnotationDefinition{ID: entityID}
+		return annotationDefinition.UpdateStatus(rm.db, entity.EntityStatus(status))
 	default:
 		return nil
 	}
@@ -0,0 +1,121 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	regv1alpha2 "github.com/layer5io/meshkit/models/meshmodel/registry/v1alpha2"
+)
+
+func testRelationshipSelectors(patchStrategy string) []map[string]interface{} {
+	return []map[string]interface{}{{
+		"allow": map[string]interface{}{
+			"from": []interface{}{map[string]interface{}{
+				"kind":  "ConfigMap",
+				"model": "kubernetes",
+				"patch": map[string]interface{}{"patchStrategy": patchStrategy, "mutatorRef": [][]string{{"name"}}},
+			}},
+			"to": []interface{}{map[string]interface{}{
+				"kind":  "*",
+				"model": "kubernetes",
+				"patch": map[string]interface{}{"patchStrategy": patchStrategy, "mutatedRef": [][]string{{"spec", "volumes", "_", "configMap", "name"}}},
+			}},
+		},
+		"deny": map[string]interface{}{
+			"from": []interface{}{map[string]interface{}{"kind": "ConfigMap"}},
+			"to":   []interface{}{map[string]interface{}{"kind": "Service"}},
+		},
+	}}
+}
+
+func TestRelationshipDefinitionRegistry(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "meshery"}
+
+	relationship := &v1alpha2.RelationshipDefinition{
+		Kind:             "Edge",
+		RelationshipType: "non-binding",
+		SubType:          "mount",
+		Model:            testModel(),
+		Selectors:        testRelationshipSelectors(v1alpha2.PatchStrategyReplace),
+	}
+	if err := rm.RegisterEntity(host, relationship); err != nil {
+		t.Fatal(err)
+	}
+	entities, count, _, err := rm.GetEntities(&regv1alpha2.RelationshipFilter{Id: relationship.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 relationship definition, got %d", count)
+	}
+	got := entities[0].(*v1alpha2.RelationshipDefinition)
+
+	got.SubType = "volume"
+	if err := got.Update(rm.db); err != nil {
+		t.Fatal(err)
+	}
+	got.Selectors = testRelationshipSelectors("unknown")
+	if err := got.Update(rm.db); err == nil {
+		t.Error("expected an error updating the relationship with an unknown patch strategy")
+	}
+	entities, _, _, err = rm.GetEntities(&regv1alpha2.RelationshipFilter{Kind: "Edge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].(*v1alpha2.RelationshipDefinition).SubType != "volume" {
+		t.Fatalf("expected the updated relationship definition, got %+v", entities)
+	}
+
+	if err := got.Delete(rm.db); err != nil {
+		t.Fatal(err)
+	}
+	_, count, _, err = rm.GetEntities(&regv1alpha2.RelationshipFilter{Kind: "Edge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the relationship definition to be deleted, got %d", count)
+	}
+}
+
+func TestRelationshipDefinitionValidation(t *testing.T) {
+	invalid := []*v1alpha2.RelationshipDefinition{
+		{Selectors: testRelationshipSelectors(v1alpha2.PatchStrategyMerge)},
+		{Kind: "Edge", Selectors: testRelationshipSelectors("unknown")},
+		{Kind: "Edge", Selectors: []map[string]interface{}{{"allow": map[string]interface{}{"from": []interface{}{map[string]interface{}{"kind": "Pod"}}}}}},
+		{Kind: "Edge", Selectors: []map[string]interface{}{{"allow": map[string]interface{}{
+			"from": []interface{}{map[string]interface{}{"model": "kubernetes"}},
+			"to":   []interface{}{map[string]interface{}{"kind": "Pod"}},
+		}}}},
+		{Kind: "Edge", Selectors: []map[string]interface{}{{"allow": "Pod"}}},
+	}
+	for _, def := range invalid {
+		if err := def.Validate(); err == nil {
+			t.Errorf("expected an error validating %+v", def)
+		}
+	}
+}
+
+func TestRelationshipDefinitionMatch(t *testing.T) {
+	component := func(kind, model string) v1beta1.ComponentDefinition {
+		c := v1beta1.ComponentDefinition{Model: v1beta1.Model{Name: model}}
+		c.Component.Kind = kind
+		return c
+	}
+	components := []v1beta1.ComponentDefinition{
+		component("ConfigMap", "kubernetes"),
+		component("Deployment", "kubernetes"),
+		component("Service", "kubernetes"),
+		component("VirtualService", "istio"),
+	}
+	relationship := &v1alpha2.RelationshipDefinition{Kind: "Edge", Selectors: testRelationshipSelectors(v1alpha2.PatchStrategyReplace)}
+	matches, err := relationship.Match(components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].From != 0 || matches[0].To != 1 {
+		t.Errorf("expected the ConfigMap to match the Deployment only, got %+v", matches)
+	}
+}
@@ -0,0 +1,341 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"gopkg.in/yaml.v3"
+)
+
+// SiteIndexFile is the file listing the models exported, at the root of the exported site data.
+const SiteIndexFile = "index.json"
+
+// SitePage is the data of the page of a model on the integrations documentation site. It is written as the front
+// matter of the Markdown page of the model, and as its JSON data file.
+type SitePage struct {
+	Name        string `json:"name" yaml:"name"`
+	Title       string `json:"title" yaml:"title"`
+	Subtitle    string `json:"subtitle,omitempty" yaml:"subtitle,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+	Category    string `json:"category,omitempty" yaml:"category,omitempty"`
+	SubCategory string `json:"subcategory,omitempty" yaml:"subcategory,omitempty"`
+	Registrant  string `json:"registrant,omitempty" yaml:"registrant,omitempty"`
+	DocURL      string `json:"docURL,omitempty" yaml:"docURL,omitempty"`
+	// IntegrationIcon and DarkModeIntegrationIcon are the paths of the icons of the model, relative to its page,
+	// or their URLs if the icons are not inline SVGs.
+	IntegrationIcon         string             `json:"integrationIcon,omitempty" yaml:"integrationIcon,omitempty"`
+	DarkModeIntegrationIcon string             `json:"darkModeIntegrationIcon,omitempty" yaml:"darkModeIntegrationIcon,omitempty"`
+	Components              []SiteComponent    `json:"components" yaml:"components"`
+	Relationships           []SiteRelationship `json:"relationships,omitempty" yaml:"relationships,omitempty"`
+
+	icons map[string]string
+}
+
+// SiteComponent is a component listed on the page of its model.
+type SiteComponent struct {
+	Name        string `json:"name" yaml:"name"`
+	Kind        string `json:"kind" yaml:"kind"`
+	APIVersion  string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	ColorIcon   string `json:"colorIcon,omitempty" yaml:"colorIcon,omitempty"`
+	WhiteIcon   string `json:"whiteIcon,omitempty" yaml:"whiteIcon,omitempty"`
+}
+
+// SiteRelationship is a relationship listed on the page of its model.
+type SiteRelationship struct {
+	Kind        string `json:"kind" yaml:"kind"`
+	Type        string `json:"type" yaml:"type"`
+	SubType     string `json:"subType,omitempty" yaml:"subType,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// SiteExportOptions configures ExportSite.
+type SiteExportOptions struct {
+	// Models are the names of the models exported, all the models if empty.
+	Models []string
+	// Template renders the body of the Markdown page of a model from its SitePage, DefaultSiteTemplate if nil.
+	Template *template.Template
+}
+
+// DefaultSiteTemplate renders the components and the relationships of a model as Markdown tables. The cells are
+// escaped with the cell function, which the templates given in SiteExportOptions can call as well.
+var DefaultSiteTemplate = template.Must(template.New("page").Funcs(SiteTemplateFuncs).Parse(`
+{{- if .Description}}{{.Description}}
+
+{{end -}}
+## Components
+
+| Component | API version | Description |
+| --- | --- | --- |
+{{range .Components}}| {{if .ColorIcon}}<img src="{{.ColorIcon}}" width="20" height="20" /> {{end}}{{cell .Name}} | {{cell .APIVersion}} | {{cell .Description}} |
+{{end -}}
+{{if .Relationships}}
+## Relationships
+
+| Kind | Type | Sub type | Description |
+| --- | --- | --- | --- |
+{{range .Relationships}}| {{cell .Kind}} | {{cell .Type}} | {{cell .SubType}} | {{cell .Description}} |
+{{end -}}
+{{end -}}
+`))
+
+// SiteTemplateFuncs are the functions of DefaultSiteTemplate:
+//
+//	cell  escapes the pipes and the line breaks of a value, for it to fit in a cell of a Markdown table
+var SiteTemplateFuncs = template.FuncMap{
+	"cell": markdownCell,
+}
+
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func markdownCell(value string) string {
+	return markdownCellReplacer.Replace(value)
+}
+
+// siteSlugPattern matches the characters which can't be part of the names of the files of the site.
+var siteSlugPattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// siteSlug returns the name of a file of the site for the name, e.g. a kind, confined to a single path element.
+func siteSlug(name string) string {
+	slug := strings.Trim(siteSlugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" || strings.Trim(slug, ".") == "" {
+		return "-"
+	}
+	return slug
+}
+
+// BuildSitePages builds the pages of the models from their components and relationships, e.g. listed from the
+// registry or read from the generated definitions. A model registered in several versions has the page of its latest
+// version, see v1beta1.CompareModelVersions, listing the components and the relationships of that version only.
+// The pages are sorted by model name, and the components by name.
+// The inline SVG icons are collected to be written by WriteSite next to the pages.
+func BuildSitePages(models []v1beta1.Model, components []v1beta1.ComponentDefinition, relationships []v1alpha2.RelationshipDefinition) []SitePage {
+	byID := make(map[uuid.UUID]v1beta1.Model, len(models))
+	for _, m := range models {
+		if m.ID != uuid.Nil {
+			byID[m.ID] = m
+		}
+	}
+	// the model of an entity is looked up by ID, the entities read from the definitions embedding theirs
+	modelOf := func(id uuid.UUID, m v1beta1.Model) v1beta1.Model {
+		if model, ok := byID[id]; ok {
+			return model
+		}
+		return m
+	}
+	latest := make(map[string]v1beta1.Model, len(models))
+	addModel := func(m v1beta1.Model) {
+		if m.Name == "" {
+			return
+		}
+		if l, ok := latest[m.Name]; !ok || v1beta1.CompareModelVersions(m.Model.Version, l.Model.Version) > 0 {
+			latest[m.Name] = m
+		}
+	}
+	for _, m := range models {
+		addModel(m)
+	}
+	for _, c := range components {
+		addModel(modelOf(c.ModelID, c.Model))
+	}
+	for _, r := range relationships {
+		addModel(modelOf(r.ModelID, r.Model))
+	}
+
+	pages := make(map[string]*SitePage, len(latest))
+	for name, m := range latest {
+		pages[name] = newSitePage(m)
+	}
+	pageOf := func(id uuid.UUID, m v1beta1.Model) *SitePage {
+		m = modelOf(id, m)
+		page, ok := pages[m.Name]
+		if !ok || v1beta1.CompareModelVersions(m.Model.Version, page.Version) != 0 {
+			return nil
+		}
+		return page
+	}
+
+	for _, c := range components {
+		page := pageOf(c.ModelID, c.Model)
+		if page == nil {
+			continue
+		}
+		name := c.DisplayName
+		if name == "" {
+			name = c.Component.Kind
+		}
+		comp := SiteComponent{Name: name, Kind: c.Component.Kind, APIVersion: c.Component.Version, Description: c.Description}
+		slug := siteSlug(c.Component.Kind)
+		comp.ColorIcon = page.icon(c.Metadata["svgColor"], filepath.ToSlash(filepath.Join("icons", "components", slug, "icons", "color", slug+"-color.svg")))
+		comp.WhiteIcon = page.icon(c.Metadata["svgWhite"], filepath.ToSlash(filepath.Join("icons", "components", slug, "icons", "white", slug+"-white.svg")))
+		page.Components = append(page.Components, comp)
+	}
+	for _, r := range relationships {
+		page := pageOf(r.ModelID, r.Model)
+		if page == nil {
+			continue
+		}
+		description, _ := r.Metadata["description"].(string)
+		page.Relationships = append(page.Relationships, SiteRelationship{Kind: r.Kind, Type: r.RelationshipType, SubType: r.SubType, Description: description})
+	}
+
+	sorted := make([]SitePage, 0, len(pages))
+	for _, page := range pages {
+		sort.SliceStable(page.Components, func(i, j int) bool { return page.Components[i].Name < page.Components[j].Name })
+		sort.SliceStable(page.Relationships, func(i, j int) bool {
+			a, b := page.Relationships[i], page.Relationships[j]
+			return a.Kind+a.Type+a.SubType < b.Kind+b.Type+b.SubType
+		})
+		if page.Components == nil {
+			page.Components = []SiteComponent{}
+		}
+		sorted = append(sorted, *page)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+func newSitePage(m v1beta1.Model) *SitePage {
+	title := m.DisplayName
+	if title == "" {
+		title = m.Name
+	}
+	page := &SitePage{
+		Name:        m.Name,
+		Title:       title,
+		Subtitle:    "Collaborative and visual infrastructure as design for " + title,
+		Description: m.Description,
+		Version:     m.Model.Version,
+		Category:    m.Category.Name,
+		SubCategory: m.SubCategory,
+		Registrant:  m.Registrant.Hostname,
+		icons:       map[string]string{},
+	}
+	page.DocURL, _ = m.Metadata["docsURL"].(string)
+	slug := siteSlug(m.Name)
+	page.IntegrationIcon = page.icon(m.Metadata["svgColor"], "icons/color/"+slug+"-color.svg")
+	page.DarkModeIntegrationIcon = page.icon(m.Metadata["svgWhite"], "icons/white/"+slug+"-white.svg")
+	return page
+}
+
+// icon returns the path of the icon if it is an inline SVG, to be written at the path, and the icon itself otherwise, e.g. a URL.
+func (p *SitePage) icon(value interface{}, path string) string {
+	icon, _ := value.(string)
+	icon = strings.TrimSpace(icon)
+	if strings.HasPrefix(icon, "<svg") || strings.HasPrefix(icon, "<?xml") {
+		p.icons[path] = icon
+		return path
+	}
+	return icon
+}
+
+// WriteSite writes the pages to the directory, each model in its own directory named after it, with:
+//
+//	index.md      the Markdown page, with the SitePage as front matter and the body rendered by the template
+//	data.json     the SitePage
+//	icons/...     the icons of the model and of its components
+//
+// The models exported are listed in index.json at the root of the directory. The names of the models must be valid
+// directory names, the pages are not written outside of the directory.
+func WriteSite(dir string, pages []SitePage, tmpl *template.Template) error {
+	if tmpl == nil {
+		tmpl = DefaultSiteTemplate
+	}
+	index := make([]SitePage, 0, len(pages))
+	for _, page := range pages {
+		if !filepath.IsLocal(page.Name) || strings.ContainsAny(page.Name, `/\`) {
+			return ErrExportSite(fmt.Errorf("%q is not a valid directory name", page.Name), page.Name)
+		}
+		pageDir := filepath.Join(dir, page.Name)
+		for path, svg := range page.icons {
+			if !filepath.IsLocal(filepath.FromSlash(path)) {
+				return ErrExportSite(fmt.Errorf("the icon %q is outside of the directory of the page", path), page.Name)
+			}
+			if err := writeSiteFile(filepath.Join(pageDir, filepath.FromSlash(path)), []byte(svg)); err != nil {
+				return ErrExportSite(err, page.Name)
+			}
+		}
+		frontMatter, err := yaml.Marshal(page)
+		if err != nil {
+			return ErrExportSite(err, page.Name)
+		}
+		var md bytes.Buffer
+		md.WriteString("---\n")
+		md.Write(frontMatter)
+		md.WriteString("---\n\n")
+		if err := tmpl.Execute(&md, page); err != nil {
+			return ErrExportSite(err, page.Name)
+		}
+		if err := writeSiteFile(filepath.Join(pageDir, "index.md"), md.Bytes()); err != nil {
+			return ErrExportSite(err, page.Name)
+		}
+		data, err := json.MarshalIndent(page, "", "  ")
+		if err != nil {
+			return ErrExportSite(err, page.Name)
+		}
+		if err := writeSiteFile(filepath.Join(pageDir, "data.json"), data); err != nil {
+			return ErrExportSite(err, page.Name)
+		}
+		summary := page
+		summary.Components, summary.Relationships = nil, nil
+		index = append(index, summary)
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return ErrExportSite(err, SiteIndexFile)
+	}
+	if err := writeSiteFile(filepath.Join(dir, SiteIndexFile), data); err != nil {
+		return ErrExportSite(err, SiteIndexFile)
+	}
+	return nil
+}
+
+func writeSiteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ExportSite writes the data files of the integrations documentation site for the models of the registry to the
+// directory, see WriteSite, and returns the pages written.
+func (rm *RegistryManager) ExportSite(dir string, opts SiteExportOptions) ([]SitePage, error) {
+	var models []v1beta1.Model
+	query := rm.db.Preload("Category").Preload("Registrant")
+	if len(opts.Models) > 0 {
+		query = query.Where("name IN ?", opts.Models)
+	}
+	if err := query.Find(&models).Error; err != nil {
+		return nil, ErrExportSite(err, "models")
+	}
+	ids := make([]uuid.UUID, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+	var components []v1beta1.ComponentDefinition
+	var relationships []v1alpha2.RelationshipDefinition
+	if len(ids) > 0 {
+		if err := rm.db.Where("model_id IN ?", ids).Find(&components).Error; err != nil {
+			return nil, ErrExportSite(err, "components")
+		}
+		if err := rm.db.Where("model_id IN ?", ids).Find(&relationships).Error; err != nil {
+			return nil, ErrExportSite(err, "relationships")
+		}
+	}
+	pages := BuildSitePages(models, components, relationships)
+	if err := WriteSite(dir, pages, opts.Template); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
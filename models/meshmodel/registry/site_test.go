@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestExportSite(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	for _, kind := range []string{"StatefulSet", "Deployment"} {
+		c := testComponent(kind)
+		c.Description = "A " + kind
+		c.Model.Metadata = map[string]interface{}{"svgColor": `<svg xmlns="http://www.w3.org/2000/svg"/>`, "svgWhite": "https://example.com/white.svg"}
+		c.Metadata["svgColor"] = `<svg xmlns="http://www.w3.org/2000/svg"><circle r="1"/></svg>`
+		if err := rm.RegisterEntity(host, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	pages, err := rm.ExportSite(dir, SiteExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || len(pages[0].Components) != 2 || pages[0].Components[0].Name != "Deployment" {
+		t.Fatalf("unexpected pages %+v", pages)
+	}
+	page := pages[0]
+	if page.Category != "Orchestration" || page.Registrant != "kubernetes" || page.DarkModeIntegrationIcon != "https://example.com/white.svg" {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	for _, path := range []string{page.IntegrationIcon, page.Components[0].ColorIcon, "index.md", "data.json"} {
+		if _, err := os.Stat(filepath.Join(dir, "kubernetes", path)); err != nil {
+			t.Errorf("%s not written: %v", path, err)
+		}
+	}
+	md, err := os.ReadFile(filepath.Join(dir, "kubernetes", "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(md), "---\nname: kubernetes\n") || !strings.Contains(string(md), "| A StatefulSet |") {
+		t.Errorf("unexpected page\n%s", md)
+	}
+	var index []SitePage
+	byt, err := os.ReadFile(filepath.Join(dir, SiteIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(byt, &index); err != nil {
+		t.Fatal(err)
+	}
+	if len(index) != 1 || index[0].Title != "kubernetes" || len(index[0].Components) != 0 {
+		t.Errorf("unexpected index %s", byt)
+	}
+
+	if pages, err = rm.ExportSite(t.TempDir(), SiteExportOptions{Models: []string{"istio-base"}}); err != nil || len(pages) != 0 {
+		t.Errorf("exported %+v, %v for a model not registered", pages, err)
+	}
+}
+
+func TestBuildSitePagesLatestVersion(t *testing.T) {
+	older, latest := testComponent("Deployment"), testComponent("Deployment")
+	older.Model.Model.Version = "v1.9.0"
+	latest.Model.Model.Version = "v1.10.0"
+	removed := testComponent("PodSecurityPolicy")
+	removed.Model.Model.Version = "v1.9.0"
+	latest.Description = "Runs pods | replicas\nof an application"
+
+	pages := BuildSitePages(nil, []v1beta1.ComponentDefinition{*older, *removed, *latest}, nil)
+	if len(pages) != 1 || pages[0].Version != "v1.10.0" || len(pages[0].Components) != 1 {
+		t.Fatalf("expected the page of the latest version only, got %+v", pages)
+	}
+
+	dir := t.TempDir()
+	if err := WriteSite(dir, pages, nil); err != nil {
+		t.Fatal(err)
+	}
+	md, err := os.ReadFile(filepath.Join(dir, "kubernetes", "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), `| Runs pods \| replicas<br>of an application |`) {
+		t.Errorf("expected the cells to be escaped, got\n%s", md)
+	}
+}
+
+func TestWriteSiteConfined(t *testing.T) {
+	comp := testComponent("../../Deployment")
+	comp.Metadata["svgColor"] = `<svg xmlns="http://www.w3.org/2000/svg"/>`
+	pages := BuildSitePages(nil, []v1beta1.ComponentDefinition{*comp}, nil)
+	if icon := pages[0].Components[0].ColorIcon; !filepath.IsLocal(icon) || !strings.HasPrefix(filepath.Clean(icon), filepath.Join("icons", "components")) {
+		t.Errorf("expected the icon to be confined to the page, got %s", icon)
+	}
+
+	dir := t.TempDir()
+	comp.Model.Name = "../escaped"
+	pages = BuildSitePages(nil, []v1beta1.ComponentDefinition{*comp}, nil)
+	if err := WriteSite(filepath.Join(dir, "site"), pages, nil); err == nil {
+		t.Error("expected a model name outside of the directory to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be written outside of the directory, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"gorm.io/gorm"
+)
+
+// RegistrySummary counts the entities of the registry, in total and grouped.
+type RegistrySummary struct {
+	Total v1beta1.EntitySummary `json:"total"`
+	// ByRegistrant is keyed by the hostname of the registrants.
+	ByRegistrant map[string]v1beta1.EntitySummary `json:"byRegistrant"`
+	// ByCategory is keyed by the name of the categories of the models.
+	ByCategory map[string]v1beta1.EntitySummary `json:"byCategory"`
+	// ByStatus is keyed by the status of the models, which the entities of a model share.
+	ByStatus map[entity.EntityStatus]v1beta1.EntitySummary `json:"byStatus"`
+}
+
+type summaryRow struct {
+	Hostname   string
+	Category   string
+	Status     entity.EntityStatus
+	EntityType entity.EntityType
+	Count      int64
+}
+
+// Summary counts the models, components, relationships and policies of the registry, grouped by registrant, category and status,
+// using a single query.
+func (rm *RegistryManager) Summary() (*RegistrySummary, error) {
+	parts := make([]string, 0, 4)
+	part := func(model interface{}, entityType entity.EntityType, modelColumn string) error {
+		stmt := &gorm.Statement{DB: rm.db.DB}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		from := "model_dbs m"
+		if entityType != entity.Model {
+			column := modelColumn
+			if field := stmt.Schema.LookUpField(modelColumn); field != nil {
+				column = field.DBName
+			}
+			from = fmt.Sprintf("%s e JOIN model_dbs m ON m.id = e.%s", stmt.Quote(stmt.Schema.Table), stmt.Quote(column))
+		}
+		parts = append(parts, fmt.Sprintf("SELECT h.hostname AS hostname, COALESCE(c.name, '') AS category, COALESCE(m.status, '') AS status, '%s' AS entity_type, COUNT(*) AS count "+
+			"FROM %s JOIN hosts h ON h.id = m.host_id LEFT JOIN category_dbs c ON c.id = m.category_id "+
+			"GROUP BY h.hostname, c.name, m.status", entityType, from))
+		return nil
+	}
+	for _, p := range []struct {
+		model       interface{}
+		entityType  entity.EntityType
+		modelColumn string
+	}{
+		{&v1beta1.Model{}, entity.Model, ""},
+		{&v1beta1.ComponentDefinition{}, entity.ComponentDefinition, "ModelID"},
+		{&v1alpha2.RelationshipDefinition{}, entity.RelationshipDefinition, "ModelID"},
+		{&v1beta1.PolicyDefinition{}, entity.PolicyDefinition, "ModelID"},
+	} {
+		if err := part(p.model, p.entityType, p.modelColumn); err != nil {
+			return nil, ErrGetSummary(err)
+		}
+	}
+
+	var rows []summaryRow
+	if err := rm.db.Raw(strings.Join(parts, " UNION ALL ")).Scan(&rows).Error; err != nil {
+		return nil, ErrGetSummary(err)
+	}
+
+	summary := &RegistrySummary{
+		ByRegistrant: map[string]v1beta1.EntitySummary{},
+		ByCategory:   map[string]v1beta1.EntitySummary{},
+		ByStatus:     map[entity.EntityStatus]v1beta1.EntitySummary{},
+	}
+	for _, row := range rows {
+		addToSummary(&summary.Total, row)
+		byRegistrant := summary.ByRegistrant[row.Hostname]
+		addToSummary(&byRegistrant, row)
+		summary.ByRegistrant[row.Hostname] = byRegistrant
+		byCategory := summary.ByCategory[row.Category]
+		addToSummary(&byCategory, row)
+		summary.ByCategory[row.Category] = byCategory
+		byStatus := summary.ByStatus[row.Status]
+		addToSummary(&byStatus, row)
+		summary.ByStatus[row.Status] = byStatus
+	}
+	return summary, nil
+}
+
+func addToSummary(s *v1beta1.EntitySummary, row summaryRow) {
+	switch row.EntityType {
+	case entity.Model:
+		s.Models += row.Count
+	case entity.ComponentDefinition:
+		s.Components += row.Count
+	case entity.RelationshipDefinition:
+		s.Relationships += row.Count
+	case entity.PolicyDefinition:
+		s.Policies += row.Count
+	}
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+)
+
+func TestSummary(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	kubernetes := v1beta1.Model{Name: "kubernetes", Category: v1beta1.Category{Name: "Orchestration"}, Model: v1beta1.ModelEntity{Version: "v1.28.0"}}
+	istio := v1beta1.Model{Name: "istio", Category: v1beta1.Category{Name: "Cloud Native Network"}, Model: v1beta1.ModelEntity{Version: "v1.20.0"}}
+
+	for _, comp := range []*v1beta1.ComponentDefinition{
+		testComponent("Deployment"),
+		testComponent("StatefulSet"),
+	} {
+		comp.Model = kubernetes
+		if err := rm.RegisterEntity(v1beta1.Host{Hostname: "kubernetes"}, comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gateway := testComponent("Gateway")
+	gateway.Model = istio
+	if err := rm.RegisterEntity(v1beta1.Host{Hostname: "artifacthub"}, gateway); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.UpdateEntityStatus(gateway.ModelID.String(), string(entity.Ignored), "models"); err != nil {
+		t.Fatal(err)
+	}
+	relationship := &v1alpha2.RelationshipDefinition{Kind: "edge", SubType: "network", Model: istio}
+	if err := rm.RegisterEntity(v1beta1.Host{Hostname: "artifacthub"}, relationship); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := rm.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := v1beta1.EntitySummary{Models: 2, Components: 3, Relationships: 1}
+	if summary.Total != want {
+		t.Errorf("got total %+v, want %+v", summary.Total, want)
+	}
+	if got, want := summary.ByRegistrant["kubernetes"], (v1beta1.EntitySummary{Models: 1, Components: 2}); got != want {
+		t.Errorf("got summary of registrant %+v, want %+v", got, want)
+	}
+	if got, want := summary.ByCategory["Cloud Native Network"], (v1beta1.EntitySummary{Models: 1, Components: 1, Relationships: 1}); got != want {
+		t.Errorf("got summary of category %+v, want %+v", got, want)
+	}
+	if got, want := summary.ByStatus[entity.Ignored], (v1beta1.EntitySummary{Models: 1, Components: 1, Relationships: 1}); got != want {
+		t.Errorf("got summary of status %+v, want %+v", got, want)
+	}
+}
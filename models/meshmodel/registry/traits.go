@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+// RegisterTraits registers the traits which the component definitions registered afterwards can compose, replacing
+// the traits of the same names.
+func (rm *RegistryManager) RegisterTraits(traits ...v1beta1.Trait) {
+	rm.traitsMu.Lock()
+	defer rm.traitsMu.Unlock()
+	if rm.traits == nil {
+		rm.traits = map[string]v1beta1.Trait{}
+	}
+	for _, t := range traits {
+		rm.traits[t.Name] = t
+	}
+}
+
+// GetTrait returns the registered trait of the given name.
+func (rm *RegistryManager) GetTrait(name string) (v1beta1.Trait, bool) {
+	rm.traitsMu.RLock()
+	defer rm.traitsMu.RUnlock()
+	t, ok := rm.traits[name]
+	return t, ok
+}
+
+// composeTraits composes the traits declared in the metadata of the component into it, before it is stored.
+// The conflicts are recorded in the metadata of the component, so that they can be fixed in the definitions.
+func (rm *RegistryManager) composeTraits(c *v1beta1.ComponentDefinition) error {
+	names := c.TraitNames()
+	if len(names) == 0 {
+		return nil
+	}
+	traits := make([]v1beta1.Trait, 0, len(names))
+	var unknown []string
+	for _, name := range names {
+		t, ok := rm.GetTrait(name)
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		traits = append(traits, t)
+	}
+	if len(unknown) > 0 {
+		return ErrUnknownTrait(c.Component.Kind, unknown)
+	}
+	conflicts, err := c.ComposeTraits(traits...)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		messages := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			messages = append(messages, conflict.String())
+		}
+		c.Metadata[v1beta1.TraitConflictsMetadataKey] = messages
+	} else {
+		delete(c.Metadata, v1beta1.TraitConflictsMetadataKey)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	regv1beta1 "github.com/layer5io/meshkit/models/meshmodel/registry/v1beta1"
+)
+
+var (
+	replicasTrait = v1beta1.Trait{
+		Name:     "has-replicas",
+		Schema:   `{"type": "object", "properties": {"spec": {"type": "object", "properties": {"replicas": {"type": "integer"}}, "required": ["replicas"]}}}`,
+		Metadata: map[string]interface{}{"scalable": true},
+	}
+	portsTrait = v1beta1.Trait{
+		Name:   "exposes-ports",
+		Schema: `{"type": "object", "properties": {"spec": {"type": "object", "properties": {"ports": {"type": "array"}, "replicas": {"type": "string"}}}}}`,
+	}
+)
+
+func TestComposeTraits(t *testing.T) {
+	c := testComponent("Deployment")
+	c.Component.Schema = `{"type": "object", "properties": {"spec": {"type": "object", "properties": {"selector": {"type": "object"}}}}}`
+	c.Metadata["scalable"] = false
+
+	conflicts, err := c.ComposeTraits(replicasTrait, portsTrait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := map[string]bool{}
+	for _, conflict := range conflicts {
+		paths[conflict.Trait+" "+conflict.Path] = true
+	}
+	if len(conflicts) != 2 || !paths["has-replicas metadata.scalable"] || !paths["exposes-ports properties.spec.properties.replicas"] {
+		t.Errorf("unexpected conflicts %+v", conflicts)
+	}
+
+	var schema struct {
+		Properties struct {
+			Spec struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+				Required   []string                          `json:"required"`
+			} `json:"spec"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(c.Component.Schema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	spec := schema.Properties.Spec
+	if len(spec.Properties) != 3 || spec.Properties["replicas"]["type"] != "integer" || len(spec.Required) != 1 {
+		t.Errorf("unexpected composed schema %s", c.Component.Schema)
+	}
+	if names := c.TraitNames(); len(names) != 2 {
+		t.Errorf("traits = %v", names)
+	}
+
+	composed := c.Component.Schema
+	if _, err := c.ComposeTraits(replicasTrait); err != nil {
+		t.Fatal(err)
+	}
+	if c.Component.Schema != composed || len(c.TraitNames()) != 2 {
+		t.Errorf("composing a trait twice changed the component: %s", c.Component.Schema)
+	}
+
+	if _, err := c.ComposeTraits(v1beta1.Trait{Name: "invalid", Schema: "["}); err == nil {
+		t.Error("expected an error composing a trait with an invalid schema")
+	}
+}
+
+func TestRegisterComponentWithTraits(t *testing.T) {
+	rm := newTestRegistryManager(t)
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	rm.RegisterTraits(replicasTrait, portsTrait)
+
+	c := testComponent("StatefulSet")
+	c.Metadata[v1beta1.TraitsMetadataKey] = []interface{}{"has-replicas", "exposes-ports"}
+	if err := rm.RegisterEntity(host, c); err != nil {
+		t.Fatal(err)
+	}
+	entities, _, _, err := rm.GetEntities(&regv1beta1.ComponentFilter{Name: "StatefulSet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 {
+		t.Fatalf("expected 1 component, got %d", len(entities))
+	}
+	got := entities[0].(*v1beta1.ComponentDefinition)
+	if got.Metadata["scalable"] != true || got.Metadata[v1beta1.TraitConflictsMetadataKey] == nil {
+		t.Errorf("traits not composed into %+v", got.Metadata)
+	}
+
+	unknown := testComponent("DaemonSet")
+	unknown.Metadata[v1beta1.TraitsMetadataKey] = []interface{}{"has-sidecars"}
+	if err := rm.RegisterEntity(host, unknown); err == nil {
+		t.Error("expected an error registering a component with an unknown trait")
+	}
+}
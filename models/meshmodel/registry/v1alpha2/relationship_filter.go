@@ -11,6 +11,7 @@ import (
 // In the future, we will add support to query using `selectors` (using CUE)
 // TODO: Add support for Model
 type RelationshipFilter struct {
+	Id               string
 	Kind             string
 	Greedy           bool //when set to true - instead of an exact match, kind will be prefix matched
 	SubType          string
@@ -36,6 +37,9 @@ func (relationshipFilter *RelationshipFilter) Get(db *database.Handler) ([]entit
 	finder := db.Model(&v1alpha2.RelationshipDefinition{}).Preload("Model").Preload("Model.Category").
 		Joins("JOIN model_dbs ON relationship_definition_dbs.model_id = model_dbs.id").
 		Joins("JOIN category_dbs ON model_dbs.category_id = category_dbs.id")
+	if relationshipFilter.Id != "" {
+		finder = finder.Where("relationship_definition_dbs.id = ?", relationshipFilter.Id)
+	}
 	if relationshipFilter.Kind != "" {
 		if relationshipFilter.Greedy {
 			finder = finder.Where("relationship_definition_dbs.kind LIKE ?", "%"+relationshipFilter.Kind+"%")
@@ -0,0 +1,82 @@
+package v1beta1
+
+import (
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"gorm.io/gorm/clause"
+)
+
+type AnnotationFilter struct {
+	Kind        string
+	Greedy      bool //when set to true - instead of an exact match, kind and display name will be matched as a substring
+	DisplayName string
+	ModelName   string
+	Version     string
+	Status      string
+	OrderOn     string
+	Sort        string //asc or desc. Default behavior is asc
+	Limit       int    //If 0 or  unspecified then all records are returned and limit is not used
+	Offset      int
+}
+
+// Create the filter from map[string]interface{}
+func (af *AnnotationFilter) Create(m map[string]interface{}) {
+	if m == nil {
+		return
+	}
+	af.Kind, _ = m["kind"].(string)
+}
+
+func (af *AnnotationFilter) Get(db *database.Handler) ([]entity.Entity, int64, int, error) {
+	var definitions []v1beta1.AnnotationDefinition
+	finder := db.Model(&v1beta1.AnnotationDefinition{}).Preload("Model").Preload("Model.Category").
+		Joins("JOIN model_dbs ON annotation_definition_dbs.model_id = model_dbs.id")
+	if af.Kind != "" {
+		if af.Greedy {
+			finder = finder.Where("annotation_definition_dbs.kind LIKE ?", "%"+af.Kind+"%")
+		} else {
+			finder = finder.Where("annotation_definition_dbs.kind = ?", af.Kind)
+		}
+	}
+	if af.DisplayName != "" {
+		if af.Greedy {
+			finder = finder.Where("annotation_definition_dbs.display_name LIKE ?", "%"+af.DisplayName+"%")
+		} else {
+			finder = finder.Where("annotation_definition_dbs.display_name = ?", af.DisplayName)
+		}
+	}
+	if af.ModelName != "" {
+		finder = finder.Where("model_dbs.name = ?", af.ModelName)
+	}
+	if af.Version != "" {
+		finder = finder.Where("model_dbs.model->>'version' = ?", af.Version)
+	}
+	if af.Status != "" {
+		finder = finder.Where("annotation_definition_dbs.status = ?", af.Status)
+	}
+	if af.OrderOn != "" {
+		if af.Sort == "desc" {
+			finder = finder.Order(clause.OrderByColumn{Column: clause.Column{Name: af.OrderOn}, Desc: true})
+		} else {
+			finder = finder.Order(af.OrderOn)
+		}
+	}
+
+	var count int64
+	finder.Count(&count)
+
+	finder = finder.Offset(af.Offset)
+	if af.Limit != 0 {
+		finder = finder.Limit(af.Limit)
+	}
+	err := finder.Find(&definitions).Error
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defs := make([]entity.Entity, 0, len(definitions))
+	for i := range definitions {
+		defs = append(defs, &definitions[i])
+	}
+	return defs, count, int(count), nil
+}
@@ -1,9 +1,11 @@
 package v1beta1
 
 import (
+	"github.com/google/uuid"
 	"github.com/layer5io/meshkit/database"
 	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
 	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -20,7 +22,11 @@ type ComponentFilter struct {
 	OrderOn      string
 	Limit        int //If 0 or  unspecified then all records are returned and limit is not used
 	Offset       int
+	// Capabilities select the components declaring a matching capability for each of the queries, e.g. workloads of the sub type stateful.
+	Capabilities []v1beta1.CapabilityQuery
 	Annotations  string //When this query parameter is "true", only components with the "isAnnotation" property set to true are returned. When this query parameter is "false", all components except those considered to be annotation components are returned. Any other value of the query parameter results in both annotations as well as non-annotation models being returned.
+	// Provenance selects the generated components whose provenance matches, e.g. generated from a version of a chart.
+	Provenance *v1beta1.ProvenanceQuery
 }
 
 type componentDefinitionWithModel struct {
@@ -105,10 +111,20 @@ func (componentFilter *ComponentFilter) Get(db *database.Handler) ([]entity.Enti
 		finder = finder.Order("display_name")
 	}
 	var count int64
-	finder.Count(&count)
-	finder = finder.Offset(componentFilter.Offset)
-	if componentFilter.Limit != 0 {
-		finder = finder.Limit(componentFilter.Limit)
+	if len(componentFilter.Capabilities) > 0 || componentFilter.Provenance != nil {
+		// capabilities and provenance are matched on the IDs and metadata of the components, then the page is loaded
+		ids, err := componentFilter.matchingIDs(finder.Session(&gorm.Session{}))
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		count = int64(len(ids))
+		finder = finder.Where("component_definition_dbs.id IN ?", componentFilter.page(ids))
+	} else {
+		finder.Count(&count)
+		finder = finder.Offset(componentFilter.Offset)
+		if componentFilter.Limit != 0 {
+			finder = finder.Limit(componentFilter.Limit)
+		}
 	}
 	err := finder.
 		Scan(&componentDefinitionsWithModel).Error
@@ -135,3 +151,36 @@ func (componentFilter *ComponentFilter) Get(db *database.Handler) ([]entity.Enti
 
 	return defs, count, uniqueCount, nil
 }
+
+// matchingIDs returns the IDs of the components found by finder having the capabilities and the provenance of the
+// filter, in order. Only the IDs, the display names and the metadata of the components are loaded.
+func (componentFilter *ComponentFilter) matchingIDs(finder *gorm.DB) ([]uuid.UUID, error) {
+	var components []v1beta1.ComponentDefinition
+	// the display name is selected so that ordering on it is not ambiguous with the one of the model
+	err := finder.Select("component_definition_dbs.id, component_definition_dbs.display_name AS display_name, component_definition_dbs.metadata").Scan(&components).Error
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0)
+	for i := range components {
+		if componentFilter.Provenance != nil && !components[i].HasProvenance(*componentFilter.Provenance) {
+			continue
+		}
+		if components[i].HasCapabilities(componentFilter.Capabilities...) {
+			ids = append(ids, components[i].ID)
+		}
+	}
+	return ids, nil
+}
+
+// page applies the offset and limit of the filter to the IDs of the components.
+func (componentFilter *ComponentFilter) page(ids []uuid.UUID) []uuid.UUID {
+	if componentFilter.Offset >= len(ids) {
+		return ids[:0]
+	}
+	ids = ids[componentFilter.Offset:]
+	if componentFilter.Limit != 0 && componentFilter.Limit < len(ids) {
+		ids = ids[:componentFilter.Limit]
+	}
+	return ids
+}
@@ -0,0 +1,78 @@
+package v1beta1
+
+import (
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"gorm.io/gorm/clause"
+)
+
+type ConnectionFilter struct {
+	Kind      string
+	Greedy    bool //when set to true - instead of an exact match, kind will be prefix matched
+	SubType   string
+	ModelName string
+	Version   string
+	Status    string
+	OrderOn   string
+	Sort      string //asc or desc. Default behavior is asc
+	Limit     int    //If 0 or  unspecified then all records are returned and limit is not used
+	Offset    int
+}
+
+// Create the filter from map[string]interface{}
+func (cf *ConnectionFilter) Create(m map[string]interface{}) {
+	if m == nil {
+		return
+	}
+	cf.Kind, _ = m["kind"].(string)
+}
+
+func (cf *ConnectionFilter) Get(db *database.Handler) ([]entity.Entity, int64, int, error) {
+	var definitions []v1beta1.ConnectionDefinition
+	finder := db.Model(&v1beta1.ConnectionDefinition{}).Preload("Model").Preload("Model.Category").
+		Joins("JOIN model_dbs ON connection_definition_dbs.model_id = model_dbs.id")
+	if cf.Kind != "" {
+		if cf.Greedy {
+			finder = finder.Where("connection_definition_dbs.kind LIKE ?", "%"+cf.Kind+"%")
+		} else {
+			finder = finder.Where("connection_definition_dbs.kind = ?", cf.Kind)
+		}
+	}
+	if cf.SubType != "" {
+		finder = finder.Where("connection_definition_dbs.sub_type = ?", cf.SubType)
+	}
+	if cf.ModelName != "" {
+		finder = finder.Where("model_dbs.name = ?", cf.ModelName)
+	}
+	if cf.Version != "" {
+		finder = finder.Where("model_dbs.model->>'version' = ?", cf.Version)
+	}
+	if cf.Status != "" {
+		finder = finder.Where("connection_definition_dbs.status = ?", cf.Status)
+	}
+	if cf.OrderOn != "" {
+		if cf.Sort == "desc" {
+			finder = finder.Order(clause.OrderByColumn{Column: clause.Column{Name: cf.OrderOn}, Desc: true})
+		} else {
+			finder = finder.Order(cf.OrderOn)
+		}
+	}
+
+	var count int64
+	finder.Count(&count)
+
+	finder = finder.Offset(cf.Offset)
+	if cf.Limit != 0 {
+		finder = finder.Limit(cf.Limit)
+	}
+	err := finder.Find(&definitions).Error
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defs := make([]entity.Entity, 0, len(definitions))
+	for i := range definitions {
+		defs = append(defs, &definitions[i])
+	}
+	return defs, count, int(count), nil
+}
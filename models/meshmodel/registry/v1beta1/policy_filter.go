@@ -4,17 +4,25 @@ import (
 	"github.com/layer5io/meshkit/database"
 	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
 	"github.com/layer5io/meshkit/models/meshmodel/entity"
+	"gorm.io/gorm/clause"
 )
 
 type PolicyFilter struct {
+	Id        string
 	Kind      string
 	Greedy    bool
 	SubType   string
 	ModelName string
-	OrderOn   string
-	Sort      string
-	Limit     int
-	Offset    int
+	// Version is the version of the policy definitions
+	Version string
+	// Latest keeps only the highest version of each policy of a model, see v1beta1.CompareModelVersions
+	Latest bool
+	// Design keeps only the policies applying to the design, see v1beta1.PolicyDefinition.AppliesTo
+	Design  *v1beta1.Design
+	OrderOn string
+	Sort    string
+	Limit   int
+	Offset  int
 }
 
 func (pf *PolicyFilter) Create(m map[string]interface{}) {
@@ -26,28 +34,83 @@ func (pf *PolicyFilter) Create(m map[string]interface{}) {
 func (pf *PolicyFilter) Get(db *database.Handler) ([]entity.Entity, int64, int, error) {
 	pl := []entity.Entity{}
 	var policyDefinitionWithModel []v1beta1.PolicyDefinition
-	finder := db.Model(&v1beta1.PolicyDefinition{}).
-		Select("policy_definition_dbs.*").
-		Joins("JOIN model_dbs ON model_dbs.id = policy_definition_dbs.model_id")
+	finder := db.Model(&v1beta1.PolicyDefinition{}).Preload("Model").
+		Joins(`JOIN model_dbs ON model_dbs.id = policy_definitions."modelID"`)
+	if pf.Id != "" {
+		finder = finder.Where("policy_definitions.id = ?", pf.Id)
+	}
 	if pf.Kind != "" {
-		finder = finder.Where("policy_definition_dbs.kind = ?", pf.Kind)
+		if pf.Greedy {
+			finder = finder.Where("policy_definitions.kind LIKE ?", "%"+pf.Kind+"%")
+		} else {
+			finder = finder.Where("policy_definitions.kind = ?", pf.Kind)
+		}
 	}
 	if pf.SubType != "" {
-		finder = finder.Where("policy_definition_dbs.sub_type = ?", pf.SubType)
+		finder = finder.Where("policy_definitions.sub_type = ?", pf.SubType)
 	}
 	if pf.ModelName != "" {
 		finder = finder.Where("model_dbs.name = ?", pf.ModelName)
 	}
+	if pf.Version != "" {
+		finder = finder.Where("policy_definitions.version = ?", pf.Version)
+	}
+	if pf.OrderOn != "" {
+		if pf.Sort == "desc" {
+			finder = finder.Order(clause.OrderByColumn{Column: clause.Column{Name: pf.OrderOn}, Desc: true})
+		} else {
+			finder = finder.Order(pf.OrderOn)
+		}
+	}
+
+	// the latest versions and the policies applying to the design are selected once fetched, so they are counted and paginated here
+	if pf.Latest || pf.Design != nil {
+		err := finder.Find(&policyDefinitionWithModel).Error
+		if err != nil {
+			return pl, 0, 0, err
+		}
+		latest := map[[2]string]*v1beta1.PolicyDefinition{}
+		for i := range policyDefinitionWithModel {
+			p := &policyDefinitionWithModel[i]
+			key := [2]string{p.ModelID.String(), p.Kind}
+			if l, ok := latest[key]; !ok || v1beta1.CompareModelVersions(p.Version, l.Version) > 0 {
+				latest[key] = p
+			}
+		}
+		for i := range policyDefinitionWithModel {
+			p := &policyDefinitionWithModel[i]
+			if pf.Latest && latest[[2]string{p.ModelID.String(), p.Kind}] != p {
+				continue
+			}
+			if pf.Design != nil && !p.AppliesTo(*pf.Design) {
+				continue
+			}
+			pl = append(pl, p)
+		}
+		count := int64(len(pl))
+		if pf.Offset >= len(pl) {
+			return []entity.Entity{}, count, int(count), nil
+		}
+		pl = pl[pf.Offset:]
+		if pf.Limit != 0 && pf.Limit < len(pl) {
+			pl = pl[:pf.Limit]
+		}
+		return pl, count, int(count), nil
+	}
 
 	var count int64
 	finder.Count(&count)
 
-	err := finder.Scan(&policyDefinitionWithModel).Error
+	finder = finder.Offset(pf.Offset)
+	if pf.Limit != 0 {
+		finder = finder.Limit(pf.Limit)
+	}
+	err := finder.Find(&policyDefinitionWithModel).Error
 	if err != nil {
 		return pl, 0, 0, err
 	}
-	for _, pm := range policyDefinitionWithModel {
-		pl = append(pl, &pm)
+	for i := range policyDefinitionWithModel {
+		pl = append(pl, &policyDefinitionWithModel[i])
 	}
 	return pl, count, int(count), nil
 }
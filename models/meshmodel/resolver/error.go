@@ -0,0 +1,15 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrResolveCode = "meshkit-11344"
+)
+
+func ErrResolve(err error, entities string) error {
+	return errors.New(ErrResolveCode, errors.Alert, []string{fmt.Sprintf("unable to fetch the %s", entities)}, []string{err.Error()}, []string{"The tables of the registry are missing or the database is not reachable"}, []string{"Make sure the registry is initialized and the database is reachable"})
+}
@@ -0,0 +1,157 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Defaults of the LoaderOptions.
+const (
+	DefaultLoaderWait     = time.Millisecond
+	DefaultLoaderMaxBatch = 100
+)
+
+// BatchFunc fetches the values of the keys at once, e.g. with a single query. Keys missing from the map have no value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// LoaderOptions configures the batching of the loads of a Loader.
+type LoaderOptions struct {
+	// Wait is how long the loads are collected before the batch is fetched, DefaultLoaderWait if 0.
+	Wait time.Duration
+	// MaxBatch is the maximum number of keys fetched at once, DefaultLoaderMaxBatch if 0.
+	MaxBatch int
+}
+
+// Loader batches and caches the loads of values by key, as a dataloader does: the loads of the resolvers of the fields
+// of a GraphQL query, e.g. the components of each of the models listed, are collected for LoaderOptions.Wait and
+// fetched with a single call of the BatchFunc, instead of a query per model.
+//
+// The values are cached for the life of the Loader, which is meant to be created for each request.
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+	opts  LoaderOptions
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+	cache   map[K]*batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	// ctx is the context of the first load, without its cancellation: the batch is shared by the loads, each of them
+	// only stops waiting for it when its own context is done.
+	ctx        context.Context
+	keys       []K
+	timer      *time.Timer
+	dispatched bool
+	done       chan struct{}
+	results    map[K]V
+	err        error
+}
+
+// NewLoader returns a Loader fetching the values with the BatchFunc.
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], opts LoaderOptions) *Loader[K, V] {
+	if opts.Wait <= 0 {
+		opts.Wait = DefaultLoaderWait
+	}
+	if opts.MaxBatch <= 0 {
+		opts.MaxBatch = DefaultLoaderMaxBatch
+	}
+	return &Loader[K, V]{fetch: fetch, opts: opts, cache: map[K]*batch[K, V]{}}
+}
+
+// Load returns the value of the key, the zero value if it has none, fetched with the keys loaded meanwhile.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	b := l.enqueue(ctx, key)
+	select {
+	case <-b.done:
+		return b.results[key], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// LoadMany returns the values of the keys, in order, fetched in as few batches as possible.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	batches := make([]*batch[K, V], len(keys))
+	for i, key := range keys {
+		batches[i] = l.enqueue(ctx, key)
+	}
+	values := make([]V, len(keys))
+	for i, b := range batches {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if b.err != nil {
+			return nil, b.err
+		}
+		values[i] = b.results[keys[i]]
+	}
+	return values, nil
+}
+
+// Clear removes the value of the key from the cache, e.g. after it was updated, so that it is fetched again.
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, key)
+}
+
+// enqueue returns the batch fetching the key, adding it to the pending batch if it is not cached.
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *batch[K, V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.cache[key]; ok {
+		return b
+	}
+	b := l.pending
+	if b == nil {
+		b = &batch[K, V]{ctx: context.WithoutCancel(ctx), done: make(chan struct{})}
+		l.pending = b
+		b.timer = time.AfterFunc(l.opts.Wait, func() { l.dispatch(b) })
+	}
+	b.keys = append(b.keys, key)
+	l.cache[key] = b
+	if len(b.keys) >= l.opts.MaxBatch {
+		// the next loads start a new batch
+		l.pending = nil
+		b.dispatched = true
+		b.timer.Stop()
+		go l.run(b)
+	}
+	return b
+}
+
+// dispatch fetches the batch once the wait is over, unless it was already dispatched because it was full.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+	l.run(b)
+}
+
+// run fetches the keys of the batch and releases the loads waiting for it.
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	b.results, b.err = l.fetch(b.ctx, b.keys)
+	if b.err != nil {
+		// failed loads are not cached, to be retried
+		l.mu.Lock()
+		for _, key := range b.keys {
+			if l.cache[key] == b {
+				delete(l.cache, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+	close(b.done)
+}
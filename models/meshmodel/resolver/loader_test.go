@@ -0,0 +1,142 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoader(t *testing.T) {
+	var batches int32
+	var mu sync.Mutex
+	var sizes []int
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt32(&batches, 1)
+		mu.Lock()
+		sizes = append(sizes, len(keys))
+		mu.Unlock()
+		values := map[int]string{}
+		for _, k := range keys {
+			if k%2 == 0 {
+				values[k] = fmt.Sprint(k)
+			}
+		}
+		return values, nil
+	}, LoaderOptions{MaxBatch: 4})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			value, err := loader.Load(ctx, key)
+			if err != nil {
+				t.Error(err)
+			}
+			if want := map[bool]string{true: fmt.Sprint(key)}[key%2 == 0]; value != want {
+				t.Errorf("Load(%d) = %q, want %q", key, value, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if batches != 2 {
+		t.Errorf("6 keys fetched in %d batches of %v, expected 2", batches, sizes)
+	}
+
+	values, err := loader.LoadMany(ctx, []int{0, 2, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batches != 2 || values[2] != "4" {
+		t.Errorf("cached keys fetched again: %d batches, values %v", batches, values)
+	}
+	loader.Clear(2)
+	if _, err := loader.LoadMany(ctx, []int{0, 2}); err != nil || batches != 3 || sizes[2] != 1 {
+		t.Errorf("cleared key not fetched alone: %d batches of %v, %v", batches, sizes, err)
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	fail := true
+	loader := NewLoader(func(ctx context.Context, keys []string) (map[string]int, error) {
+		if fail {
+			return nil, fmt.Errorf("unreachable")
+		}
+		return map[string]int{"a": 1}, nil
+	}, LoaderOptions{})
+	ctx := context.Background()
+	if _, err := loader.Load(ctx, "a"); err == nil {
+		t.Fatal("expected the error of the batch")
+	}
+	fail = false
+	if value, err := loader.Load(ctx, "a"); err != nil || value != 1 {
+		t.Errorf("failed load was cached: %d, %v", value, err)
+	}
+}
+
+func TestLoaderMaxBatch(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		sizes = append(sizes, len(keys))
+		mu.Unlock()
+		return map[int]int{}, nil
+	}, LoaderOptions{MaxBatch: 2})
+	if _, err := loader.LoadMany(context.Background(), []int{1, 2, 3, 4, 5}); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sizes) != 3 {
+		t.Errorf("5 keys fetched in batches of %v, expected 3 batches", sizes)
+	}
+	for _, size := range sizes {
+		if size > 2 {
+			t.Errorf("batch of %d keys, larger than MaxBatch", size)
+		}
+	}
+}
+
+func TestLoaderCanceledWaiter(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var fetchErr error
+	loader := NewLoader(func(ctx context.Context, keys []string) (map[string]int, error) {
+		close(started)
+		<-release
+		fetchErr = ctx.Err()
+		return map[string]int{"a": 1, "b": 2}, nil
+	}, LoaderOptions{MaxBatch: 2, Wait: time.Hour})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := loader.Load(canceled, "a")
+		first <- err
+	}()
+	second := make(chan int, 1)
+	go func() {
+		value, err := loader.Load(context.Background(), "b")
+		if err != nil {
+			t.Error(err)
+		}
+		second <- value
+	}()
+
+	<-started
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Errorf("expected the canceled load to return, got %v", err)
+	}
+	close(release)
+	if value := <-second; value != 2 {
+		t.Errorf("Load(b) = %d, want 2", value)
+	}
+	if fetchErr != nil {
+		t.Errorf("the batch was fetched with a canceled context: %v", fetchErr)
+	}
+}
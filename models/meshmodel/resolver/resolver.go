@@ -0,0 +1,132 @@
+// Package resolver exposes the queries of the registry in the shapes of the resolvers of a GraphQL API: the entities
+// related to many parents are fetched in batches, by the keys of all the parents, to avoid a query per parent.
+package resolver
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+// ComponentRef identifies the component definitions of a kind in a model.
+type ComponentRef struct {
+	Kind  string `json:"kind"`
+	Model string `json:"model"`
+}
+
+// ComponentPair is an ordered pair of components, the relationships of which are resolved.
+type ComponentPair struct {
+	From ComponentRef `json:"from"`
+	To   ComponentRef `json:"to"`
+}
+
+// Resolver fetches the entities of the registry in batches.
+type Resolver struct {
+	db *database.Handler
+}
+
+func New(db *database.Handler) *Resolver {
+	return &Resolver{db: db}
+}
+
+// ModelsByIDs returns the models of the IDs, with their category and registrant, by ID.
+func (r *Resolver) ModelsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]v1beta1.Model, error) {
+	var models []v1beta1.Model
+	if err := r.db.WithContext(ctx).Preload("Category").Preload("Registrant").Where("id IN ?", ids).Find(&models).Error; err != nil {
+		return nil, ErrResolve(err, "models")
+	}
+	byID := make(map[uuid.UUID]v1beta1.Model, len(models))
+	for _, m := range models {
+		byID[m.ID] = m
+	}
+	return byID, nil
+}
+
+// ComponentsByModelIDs returns the component definitions of the models of the IDs, with their model, by model ID.
+func (r *Resolver) ComponentsByModelIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]v1beta1.ComponentDefinition, error) {
+	var components []v1beta1.ComponentDefinition
+	if err := r.db.WithContext(ctx).Where("model_id IN ?", ids).Order("display_name").Find(&components).Error; err != nil {
+		return nil, ErrResolve(err, "components")
+	}
+	models, err := r.ModelsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byModel := make(map[uuid.UUID][]v1beta1.ComponentDefinition, len(ids))
+	for _, c := range components {
+		c.Model = models[c.ModelID]
+		byModel[c.ModelID] = append(byModel[c.ModelID], c)
+	}
+	return byModel, nil
+}
+
+// RelationshipsByComponentPairs returns the relationship definitions applying from the first to the second component
+// of each of the pairs, by pair. The relationships of the models of the components are matched against the pairs,
+// fetched with a single query.
+func (r *Resolver) RelationshipsByComponentPairs(ctx context.Context, pairs []ComponentPair) (map[ComponentPair][]v1alpha2.RelationshipDefinition, error) {
+	names := map[string]struct{}{}
+	for _, p := range pairs {
+		names[p.From.Model] = struct{}{}
+		names[p.To.Model] = struct{}{}
+	}
+	modelNames := make([]string, 0, len(names))
+	for name := range names {
+		modelNames = append(modelNames, name)
+	}
+	var relationships []v1alpha2.RelationshipDefinition
+	err := r.db.WithContext(ctx).Preload("Model").
+		Joins("JOIN model_dbs ON model_dbs.id = relationship_definition_dbs.model_id").
+		Where("model_dbs.name IN ?", modelNames).Find(&relationships).Error
+	if err != nil {
+		return nil, ErrResolve(err, "relationships")
+	}
+
+	byPair := make(map[ComponentPair][]v1alpha2.RelationshipDefinition, len(pairs))
+	for _, p := range pairs {
+		components := []v1beta1.ComponentDefinition{p.From.definition(), p.To.definition()}
+		for i := range relationships {
+			rel := &relationships[i]
+			if rel.Model.Name != p.From.Model && rel.Model.Name != p.To.Model {
+				continue
+			}
+			matches, err := rel.Match(components)
+			if err != nil {
+				// relationships with invalid selectors apply to no components
+				continue
+			}
+			for _, m := range matches {
+				if m.From == 0 && m.To == 1 {
+					byPair[p] = append(byPair[p], *rel)
+					break
+				}
+			}
+		}
+	}
+	return byPair, nil
+}
+
+func (c ComponentRef) definition() v1beta1.ComponentDefinition {
+	return v1beta1.ComponentDefinition{
+		Model:     v1beta1.Model{Name: c.Model},
+		Component: v1beta1.ComponentEntity{TypeMeta: v1beta1.TypeMeta{Kind: c.Kind}},
+	}
+}
+
+// Loaders are the loaders of the entities of the registry, to be created for each request and shared by its resolvers.
+type Loaders struct {
+	Models              *Loader[uuid.UUID, v1beta1.Model]
+	ComponentsByModel   *Loader[uuid.UUID, []v1beta1.ComponentDefinition]
+	RelationshipsByPair *Loader[ComponentPair, []v1alpha2.RelationshipDefinition]
+}
+
+// NewLoaders returns the loaders fetching the entities with the resolver.
+func NewLoaders(r *Resolver, opts LoaderOptions) *Loaders {
+	return &Loaders{
+		Models:              NewLoader(r.ModelsByIDs, opts),
+		ComponentsByModel:   NewLoader(r.ComponentsByModelIDs, opts),
+		RelationshipsByPair: NewLoader(r.RelationshipsByComponentPairs, opts),
+	}
+}
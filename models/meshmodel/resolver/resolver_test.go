@@ -0,0 +1,107 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha2"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/models/meshmodel/registry"
+)
+
+func testComponent(model, kind string) *v1beta1.ComponentDefinition {
+	return &v1beta1.ComponentDefinition{
+		DisplayName: kind,
+		Model:       v1beta1.Model{Name: model, Category: v1beta1.Category{Name: "Orchestration"}, Model: v1beta1.ModelEntity{Version: "v1.0.0"}},
+		Metadata:    map[string]interface{}{},
+		Component:   v1beta1.ComponentEntity{TypeMeta: v1beta1.TypeMeta{Kind: kind, Version: "v1"}, Schema: `{"type": "object"}`},
+	}
+}
+
+func newTestResolver(t *testing.T) (*Resolver, map[string]uuid.UUID) {
+	t.Helper()
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: "file::memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.DBClose() })
+	rm, err := registry.NewRegistryManager(&db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := v1beta1.Host{Hostname: "kubernetes"}
+	for _, c := range []*v1beta1.ComponentDefinition{
+		testComponent("kubernetes", "Service"), testComponent("kubernetes", "Pod"), testComponent("istio-base", "VirtualService"),
+	} {
+		if err := rm.RegisterEntity(host, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	relationship := &v1alpha2.RelationshipDefinition{
+		Kind:             "Edge",
+		RelationshipType: "non-binding",
+		SubType:          "network",
+		Model:            testComponent("kubernetes", "").Model,
+		Selectors: []map[string]interface{}{{"allow": map[string]interface{}{
+			"from": []interface{}{map[string]interface{}{"kind": "Service", "model": "kubernetes"}},
+			"to":   []interface{}{map[string]interface{}{"kind": "*"}},
+		}}},
+	}
+	if err := rm.RegisterEntity(host, relationship); err != nil {
+		t.Fatal(err)
+	}
+
+	var models []v1beta1.Model
+	if err := db.Find(&models).Error; err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]uuid.UUID{}
+	for _, m := range models {
+		ids[m.Name] = m.ID
+	}
+	return New(&db), ids
+}
+
+func TestComponentsByModelIDs(t *testing.T) {
+	r, ids := newTestResolver(t)
+	loaders := NewLoaders(r, LoaderOptions{})
+	ctx := context.Background()
+
+	components, err := loaders.ComponentsByModel.LoadMany(ctx, []uuid.UUID{ids["kubernetes"], ids["istio-base"], uuid.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(components[0]) != 2 || components[0][0].DisplayName != "Pod" || len(components[1]) != 1 || components[2] != nil {
+		t.Fatalf("unexpected components %+v", components)
+	}
+	if m := components[1][0].Model; m.Name != "istio-base" || m.Category.Name != "Orchestration" {
+		t.Errorf("unexpected model %+v", m)
+	}
+
+	model, err := loaders.Models.Load(ctx, ids["kubernetes"])
+	if err != nil || model.Registrant.Hostname != "kubernetes" {
+		t.Errorf("unexpected model %+v, %v", model, err)
+	}
+}
+
+func TestRelationshipsByComponentPairs(t *testing.T) {
+	r, _ := newTestResolver(t)
+	service := ComponentRef{Kind: "Service", Model: "kubernetes"}
+	pairs := []ComponentPair{
+		{From: service, To: ComponentRef{Kind: "Pod", Model: "kubernetes"}},
+		{From: service, To: ComponentRef{Kind: "VirtualService", Model: "istio-base"}},
+		{From: ComponentRef{Kind: "Pod", Model: "kubernetes"}, To: service},
+	}
+	relationships, err := NewLoaders(r, LoaderOptions{}).RelationshipsByPair.LoadMany(context.Background(), pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(relationships[0]) != 1 || len(relationships[1]) != 1 || len(relationships[2]) != 0 {
+		t.Errorf("unexpected relationships %+v", relationships)
+	}
+	if relationships[0][0].SubType != "network" {
+		t.Errorf("unexpected relationship %+v", relationships[0][0])
+	}
+}
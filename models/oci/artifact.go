@@ -0,0 +1,300 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fluxcd/pkg/oci/client"
+	"github.com/fluxcd/pkg/tar"
+	"github.com/layer5io/meshkit/utils/cas"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	oras "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// ArtifactKind is the kind of Meshery content an artifact holds.
+type ArtifactKind string
+
+const (
+	ArtifactKindDesign ArtifactKind = "design"
+	ArtifactKindModel  ArtifactKind = "model"
+)
+
+const (
+	// DesignArtifactType is the artifactType of the manifest of a design artifact
+	DesignArtifactType = "application/vnd.meshery.design.v1"
+	// ModelArtifactType is the artifactType of the manifest of a model artifact
+	ModelArtifactType = "application/vnd.meshery.model.v1"
+	// ContentLayerMediaType is the media type of the layer holding the gzipped tarball of the packaged directory
+	ContentLayerMediaType = "application/vnd.meshery.content.v1.tar+gzip"
+
+	// AnnotationArtifactKind holds the ArtifactKind in the manifest annotations
+	AnnotationArtifactKind = "io.meshery.artifact.kind"
+
+	// DefaultMaxUnpackSize is the default limit, in bytes, of the content unpacked from an artifact or a bundle archive
+	DefaultMaxUnpackSize = 256 << 20
+)
+
+// ArtifactType returns the manifest artifactType for the kind.
+func (k ArtifactKind) ArtifactType() string {
+	if k == ArtifactKindModel {
+		return ModelArtifactType
+	}
+	return DesignArtifactType
+}
+
+func artifactKindFromType(artifactType string) (ArtifactKind, error) {
+	switch artifactType {
+	case DesignArtifactType:
+		return ArtifactKindDesign, nil
+	case ModelArtifactType:
+		return ArtifactKindModel, nil
+	}
+	return "", ErrUnknownArtifact(fmt.Errorf("unsupported artifact type %q", artifactType))
+}
+
+// ArtifactOptions are the optional metadata of a packaged artifact.
+type ArtifactOptions struct {
+	// Name and Version are stored as the OCI title and version annotations
+	Name    string
+	Version string
+	// Annotations are added to the manifest annotations
+	Annotations map[string]string
+	// IgnorePaths are patterns, in the .gitignore format, of the files which are not packaged
+	IgnorePaths []string
+	// Attestations, e.g. SBOMs and provenance, are attached to the artifact as referrers
+	Attestations []Attestation
+}
+
+// RegistryOptions configure the access to the remote registry.
+type RegistryOptions struct {
+	Username string
+	Password string
+	// Credentials resolves the credentials when Username and Password are not set, it defaults to NewDefaultCredentialChain(nil)
+	Credentials CredentialProvider
+	// Warn, if set, is called with the errors of the credential lookups, e.g. the Warn of a logger.Handler.
+	// The registry is accessed anonymously when the lookup fails.
+	Warn func(error)
+	// PlainHTTP connects to the registry over HTTP instead of HTTPS, e.g. for local registries
+	PlainHTTP bool
+	// Signer, if set, signs the artifacts on push
+	Signer *Signer
+	// Verification is the policy the signatures of the artifacts are verified against on pull
+	Verification VerificationPolicy
+	// Progress, if set, is called as the content of the artifacts is transferred
+	Progress ProgressFunc
+	// ChunkSize, if positive, is the size of the chunks blobs larger than it are uploaded in
+	ChunkSize int64
+	// Retries is the number of times a failed chunk upload or an interrupted download is resumed,
+	// DefaultTransferRetries if zero, none if negative
+	Retries int
+	// MaxUnpackSize is the limit, in bytes, of the content unpacked from the pulled artifacts,
+	// DefaultMaxUnpackSize if zero, none if negative
+	MaxUnpackSize int
+	// Store, if set, caches the pulled blobs by digest, so that they are downloaded once across pulls
+	Store *cas.Store
+}
+
+// PackArtifact packages the directory into an artifact of the given kind, stored in target along with its attestations.
+// The returned descriptor is the descriptor of the manifest.
+func PackArtifact(ctx context.Context, target content.Storage, dir string, kind ArtifactKind, opts ArtifactOptions) (ocispec.Descriptor, error) {
+	tmpDir, err := os.MkdirTemp("", "oci")
+	if err != nil {
+		return ocispec.Descriptor{}, ErrWriteFile(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tmpFile := filepath.Join(tmpDir, "artifact.tgz")
+	if err := client.NewClient(client.DefaultOptions()).Build(tmpFile, dir, opts.IgnorePaths); err != nil {
+		return ocispec.Descriptor{}, ErrCompressingLayer(err)
+	}
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		return ocispec.Descriptor{}, ErrReadingFile(err)
+	}
+
+	layer := content.NewDescriptorFromBytes(ContentLayerMediaType, data)
+	layer.Annotations = map[string]string{ocispec.AnnotationTitle: filepath.Base(filepath.Clean(dir)) + ".tgz"}
+	if err := pushIfNotExists(ctx, target, layer, data); err != nil {
+		return ocispec.Descriptor{}, ErrAddLayer(err)
+	}
+
+	annotations := map[string]string{
+		AnnotationArtifactKind:    string(kind),
+		ocispec.AnnotationCreated: time.Now().UTC().Format(time.RFC3339),
+	}
+	if opts.Name != "" {
+		annotations[ocispec.AnnotationTitle] = opts.Name
+	}
+	if opts.Version != "" {
+		annotations[ocispec.AnnotationVersion] = opts.Version
+	}
+	for k, v := range opts.Annotations {
+		annotations[k] = v
+	}
+
+	manifest, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1_RC4, kind.ArtifactType(), oras.PackManifestOptions{
+		Layers:              []ocispec.Descriptor{layer},
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, ErrGettingLayer(err)
+	}
+	for _, attestation := range opts.Attestations {
+		if _, err := Attach(ctx, target, manifest, attestation); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	return manifest, nil
+}
+
+// UnpackArtifact extracts the content of the artifact described by desc into dest and returns its kind.
+// Content larger than maxSize bytes is not unpacked, maxSize defaults to DefaultMaxUnpackSize if zero and a negative maxSize disables the limit.
+func UnpackArtifact(ctx context.Context, source content.Fetcher, desc ocispec.Descriptor, dest string, maxSize int) (ArtifactKind, error) {
+	manifestData, err := content.FetchAll(ctx, source, desc)
+	if err != nil {
+		return "", ErrGettingImage(err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return "", ErrUnknownArtifact(err)
+	}
+	kind, err := artifactKindFromType(manifest.ArtifactType)
+	if err != nil {
+		return "", err
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != ContentLayerMediaType {
+			continue
+		}
+		blob, err := source.Fetch(ctx, layer)
+		if err != nil {
+			return "", ErrGettingLayer(err)
+		}
+		defer blob.Close()
+		if err := tar.Untar(content.NewVerifyReader(blob, layer), dest, tar.WithMaxUntarSize(unpackLimit(maxSize)), tar.WithSkipSymlinks()); err != nil {
+			return "", ErrUnTaringLayer(err)
+		}
+		return kind, nil
+	}
+	return "", ErrUnknownArtifact(fmt.Errorf("the artifact has no layer of media type %s", ContentLayerMediaType))
+}
+
+// unpackLimit returns the limit passed to tar.Untar for maxSize.
+func unpackLimit(maxSize int) int {
+	switch {
+	case maxSize == 0:
+		return DefaultMaxUnpackSize
+	case maxSize < 0:
+		return tar.UnlimitedUntarSize
+	}
+	return maxSize
+}
+
+// PushArtifact packages the directory and pushes it to the reference, of the form registry/repository[:tag].
+// The tag defaults to latest. The attestations of artifactOpts are pushed as referrers of the artifact,
+// and the artifact is signed if registryOpts has a Signer.
+func PushArtifact(ctx context.Context, dir, reference string, kind ArtifactKind, artifactOpts ArtifactOptions, registryOpts RegistryOptions) (ocispec.Descriptor, error) {
+	repo, ref, err := newRemoteRepository(reference, registryOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	tag := ref.ReferenceOrDefault()
+
+	store := memory.New()
+	desc, err := PackArtifact(ctx, store, dir, kind, artifactOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := store.Tag(ctx, desc, tag); err != nil {
+		return ocispec.Descriptor{}, ErrTaggingPackage(err)
+	}
+	dst := registryOpts.withProgress(newRemoteTarget(repo, registryOpts))
+	if _, err := oras.Copy(ctx, store, tag, dst, tag, registryOpts.copyOptions()); err != nil {
+		return ocispec.Descriptor{}, ErrPushingPackage(err)
+	}
+	if err := copyReferrers(ctx, store, dst, desc, registryOpts); err != nil {
+		return desc, ErrPushingPackage(err)
+	}
+	if registryOpts.Signer != nil {
+		if _, err := registryOpts.Signer.Sign(ctx, repo, ref.Registry+"/"+ref.Repository, desc); err != nil {
+			return desc, err
+		}
+	}
+	return desc, nil
+}
+
+// PullArtifact pulls the artifact at the reference, of the form registry/repository[:tag|@digest], and unpacks it into dest.
+// The artifact is only unpacked if its signatures satisfy registryOpts.Verification.
+// Its attestations can be retrieved with PullAttestations.
+func PullArtifact(ctx context.Context, reference, dest string, registryOpts RegistryOptions) (ArtifactKind, ocispec.Descriptor, error) {
+	repo, ref, err := newRemoteRepository(reference, registryOpts)
+	if err != nil {
+		return "", ocispec.Descriptor{}, err
+	}
+	src := ref.ReferenceOrDefault()
+
+	store := memory.New()
+	desc, err := oras.Copy(ctx, newRemoteTarget(repo, registryOpts), src, registryOpts.withProgress(store), src, registryOpts.copyOptions())
+	if err != nil {
+		return "", ocispec.Descriptor{}, ErrGettingImage(err)
+	}
+	if err := registryOpts.Verification.Verify(ctx, repo, desc); err != nil {
+		return "", desc, err
+	}
+	kind, err := UnpackArtifact(ctx, store, desc, dest, registryOpts.MaxUnpackSize)
+	return kind, desc, err
+}
+
+func newRemoteRepository(reference string, opts RegistryOptions) (*remote.Repository, registry.Reference, error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return nil, ref, ErrInvalidReference(err, reference)
+	}
+	repo, err := remote.NewRepository(reference)
+	if err != nil {
+		return nil, ref, ErrConnectingToRegistry(err)
+	}
+	repo.PlainHTTP = opts.PlainHTTP
+	if opts.Username != "" || opts.Password != "" {
+		if err := AuthToOCIRegistry(repo, ref.Registry, opts.Username, opts.Password); err != nil {
+			return nil, ref, ErrAuthenticatingToRegistry(err)
+		}
+		return repo, ref, nil
+	}
+
+	provider := opts.Credentials
+	if provider == nil {
+		provider = NewDefaultCredentialChain(nil)
+	}
+	repo.Client = &auth.Client{
+		Client: retry.DefaultClient,
+		Cache:  auth.NewCache(),
+		Credential: func(ctx context.Context, hostport string) (auth.Credential, error) {
+			registry := normalizeRegistry(hostport)
+			if chain, ok := provider.(CredentialChain); ok {
+				return chain.lookup(ctx, registry, opts.Warn), nil
+			}
+			cred, err := provider.Credential(ctx, registry)
+			if err != nil {
+				// fall back to anonymous access
+				if opts.Warn != nil {
+					opts.Warn(fmt.Errorf("looking up the credential of %s: %w", registry, err))
+				}
+				return auth.EmptyCredential, nil
+			}
+			return cred, nil
+		},
+	}
+	return repo, ref, nil
+}
@@ -0,0 +1,122 @@
+package oci
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/layer5io/meshkit/utils/cas"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestPushPullArtifact(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "components"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "components", "Pod.json"), []byte(`{"component": {"kind": "Pod"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	opts := RegistryOptions{PlainHTTP: true}
+	desc, err := PushArtifact(ctx, src, host+"/meshery/kubernetes:v1.0.0", ArtifactKindModel, ArtifactOptions{Name: "kubernetes", Version: "v1.0.0"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range []string{host + "/meshery/kubernetes:v1.0.0", host + "/meshery/kubernetes@" + desc.Digest.String()} {
+		dest := t.TempDir()
+		kind, _, err := PullArtifact(ctx, ref, dest, opts)
+		if err != nil {
+			t.Fatalf("pulling %s: %v", ref, err)
+		}
+		if kind != ArtifactKindModel {
+			t.Errorf("pulling %s: got kind %s, want %s", ref, kind, ArtifactKindModel)
+		}
+		if _, err := os.Stat(filepath.Join(dest, "components", "Pod.json")); err != nil {
+			t.Errorf("pulling %s: %v", ref, err)
+		}
+	}
+}
+
+func TestPullArtifactStore(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	blobRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+			blobRequests++
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "design.yaml"), []byte("name: design"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	store, err := cas.New(t.TempDir(), cas.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := RegistryOptions{PlainHTTP: true, Store: store}
+	if _, err := PushArtifact(ctx, src, host+"/meshery/design:v1", ArtifactKindDesign, ArtifactOptions{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		dest := t.TempDir()
+		if _, _, err := PullArtifact(ctx, host+"/meshery/design:v1", dest, opts); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dest, "design.yaml")); err != nil {
+			t.Error(err)
+		}
+	}
+	blobs, err := store.Blobs()
+	if err != nil || len(blobs) == 0 {
+		t.Fatalf("expected the blobs to be stored, got %v, %v", blobs, err)
+	}
+	// the second pull reads the blobs from the store
+	if blobRequests != len(blobs) {
+		t.Errorf("expected each of the %d blobs to be downloaded once, got %d downloads", len(blobs), blobRequests)
+	}
+}
+
+func TestUnpackArtifactMaxSize(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "design.yaml"), []byte(strings.Repeat("a", 4096)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	store := memory.New()
+	desc, err := PackArtifact(ctx, store, src, ArtifactKindDesign, ArtifactOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := UnpackArtifact(ctx, store, desc, t.TempDir(), 1024); err == nil {
+		t.Error("expected content larger than the limit not to be unpacked")
+	}
+	for _, maxSize := range []int{0, -1, 8192} {
+		dest := t.TempDir()
+		if _, err := UnpackArtifact(ctx, store, desc, dest, maxSize); err != nil {
+			t.Fatalf("max size %d: %v", maxSize, err)
+		}
+		if _, err := os.Stat(filepath.Join(dest, "design.yaml")); err != nil {
+			t.Errorf("max size %d: %v", maxSize, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/fluxcd/pkg/oci/client"
+	"github.com/fluxcd/pkg/tar"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	oras "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Bundle is a set of artifacts stored in an OCI image layout directory, see https://github.com/opencontainers/image-spec/blob/main/image-layout.md.
+// Every artifact is tagged with its name in the index of the layout, along with its signatures and attestations.
+// A bundle can be saved to a single archive, e.g. to be transferred into an air-gapped environment on removable media,
+// and its artifacts restored into a registry of that environment.
+type Bundle struct {
+	path  string
+	store *oci.Store
+	// MaxUnpackSize is the limit, in bytes, of the content of the artifacts unpacked by Extract,
+	// DefaultMaxUnpackSize if zero, none if negative
+	MaxUnpackSize int
+}
+
+// BundleEntry describes an artifact of a bundle.
+type BundleEntry struct {
+	Name   string       `json:"name"`
+	Kind   ArtifactKind `json:"kind"`
+	Digest string       `json:"digest"`
+	Signed bool         `json:"signed"`
+	// Attestations are the artifact types of the attestations attached to the artifact
+	Attestations []string          `json:"attestations,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// NewBundle opens the OCI image layout at path, it is created if it does not exist.
+func NewBundle(path string) (*Bundle, error) {
+	store, err := oci.New(path)
+	if err != nil {
+		return nil, ErrOpeningLayout(err, path)
+	}
+	return &Bundle{path: path, store: store}, nil
+}
+
+// OpenBundle extracts the bundle archive, saved with Save, into dir and opens it.
+// The archive is not extracted if it is larger than maxSize bytes, maxSize defaults to DefaultMaxUnpackSize if zero
+// and a negative maxSize disables the limit. The limit also applies to the artifacts unpacked by Extract.
+func OpenBundle(archivePath, dir string, maxSize int) (*Bundle, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, ErrFileNotFound(err, archivePath)
+	}
+	defer f.Close()
+	if err := tar.Untar(f, dir, tar.WithMaxUntarSize(unpackLimit(maxSize)), tar.WithSkipSymlinks()); err != nil {
+		return nil, ErrOpeningLayout(err, archivePath)
+	}
+	b, err := NewBundle(dir)
+	if err != nil {
+		return nil, err
+	}
+	b.MaxUnpackSize = maxSize
+	return b, nil
+}
+
+// Save writes the bundle to a single gzipped tarball at archivePath.
+func (b *Bundle) Save(archivePath string) error {
+	if err := b.store.SaveIndex(); err != nil {
+		return ErrWriteFile(err)
+	}
+	if err := client.NewClient(client.DefaultOptions()).Build(archivePath, b.path, nil); err != nil {
+		return ErrWriteFile(err)
+	}
+	return nil
+}
+
+// Add packages the directory into an artifact, optionally signed, and stores it in the bundle under name.
+func (b *Bundle) Add(ctx context.Context, dir, name string, kind ArtifactKind, opts ArtifactOptions, signer *Signer) (ocispec.Descriptor, error) {
+	desc, err := PackArtifact(ctx, b.store, dir, kind, opts)
+	if err != nil {
+		return desc, err
+	}
+	if err := b.store.Tag(ctx, desc, name); err != nil {
+		return desc, ErrTaggingPackage(err)
+	}
+	if signer != nil {
+		if _, err := signer.Sign(ctx, b.store, name, desc); err != nil {
+			return desc, err
+		}
+	}
+	return desc, nil
+}
+
+// AddFromRegistry copies the artifact at reference, with its signatures and attestations, into the bundle under name.
+func (b *Bundle) AddFromRegistry(ctx context.Context, reference, name string, registryOpts RegistryOptions) (ocispec.Descriptor, error) {
+	repo, ref, err := newRemoteRepository(reference, registryOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc, err := copyWithSignatures(ctx, newRemoteTarget(repo, registryOpts), ref.ReferenceOrDefault(), registryOpts.withProgress(b.store), name, registryOpts)
+	if err != nil {
+		return desc, ErrGettingImage(err)
+	}
+	if err := registryOpts.Verification.Verify(ctx, b.store, desc); err != nil {
+		return desc, err
+	}
+	return desc, nil
+}
+
+// PushToRegistry copies the artifact stored under name, with its signatures and attestations, to reference.
+func (b *Bundle) PushToRegistry(ctx context.Context, name, reference string, registryOpts RegistryOptions) (ocispec.Descriptor, error) {
+	repo, ref, err := newRemoteRepository(reference, registryOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc, err := copyWithSignatures(ctx, b.store, name, registryOpts.withProgress(newRemoteTarget(repo, registryOpts)), ref.ReferenceOrDefault(), registryOpts)
+	if err != nil {
+		return desc, ErrPushingPackage(err)
+	}
+	return desc, nil
+}
+
+// Extract unpacks the artifact stored under name into dest, if its signatures satisfy the policy.
+func (b *Bundle) Extract(ctx context.Context, name, dest string, policy VerificationPolicy) (ArtifactKind, error) {
+	desc, err := b.store.Resolve(ctx, name)
+	if err != nil {
+		return "", ErrGettingImage(err)
+	}
+	if err := policy.Verify(ctx, b.store, desc); err != nil {
+		return "", err
+	}
+	return UnpackArtifact(ctx, b.store, desc, dest, b.MaxUnpackSize)
+}
+
+// Attestations returns the attestations attached to the artifact stored under name.
+// Only attestations of the artifactType are returned, unless it is empty.
+func (b *Bundle) Attestations(ctx context.Context, name, artifactType string) ([]Attestation, error) {
+	desc, err := b.store.Resolve(ctx, name)
+	if err != nil {
+		return nil, ErrGettingImage(err)
+	}
+	return FetchAttestations(ctx, b.store, desc, artifactType)
+}
+
+// Entries lists the Meshery artifacts of the bundle, sorted by name.
+// Signatures, attestations and artifacts of other types are not listed.
+func (b *Bundle) Entries(ctx context.Context) ([]BundleEntry, error) {
+	entries := make([]BundleEntry, 0)
+	err := b.store.Tags(ctx, "", func(tags []string) error {
+		for _, tag := range tags {
+			desc, err := b.store.Resolve(ctx, tag)
+			if err != nil {
+				return err
+			}
+			data, err := content.FetchAll(ctx, b.store, desc)
+			if err != nil {
+				return err
+			}
+			var manifest ocispec.Manifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return err
+			}
+			kind, err := artifactKindFromType(manifest.ArtifactType)
+			if err != nil {
+				continue
+			}
+			signatures, err := fetchSignatureLayers(ctx, b.store, desc)
+			if err != nil {
+				return err
+			}
+			referrers, err := registry.Referrers(ctx, b.store, desc, "")
+			if err != nil {
+				return err
+			}
+			var attestations []string
+			for _, referrer := range referrers {
+				attestations = append(attestations, referrer.ArtifactType)
+			}
+			entries = append(entries, BundleEntry{
+				Name:         tag,
+				Kind:         kind,
+				Digest:       desc.Digest.String(),
+				Signed:       len(signatures) > 0,
+				Attestations: attestations,
+				Annotations:  manifest.Annotations,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, ErrOpeningLayout(err, b.path)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// copyWithSignatures copies the artifact, its attestations and, if it is signed, its signatures.
+func copyWithSignatures(ctx context.Context, src oras.ReadOnlyGraphTarget, srcRef string, dst oras.Target, dstRef string, registryOpts RegistryOptions) (ocispec.Descriptor, error) {
+	desc, err := oras.Copy(ctx, src, srcRef, dst, dstRef, registryOpts.copyOptions())
+	if err != nil {
+		return desc, err
+	}
+	if err := copyReferrers(ctx, src, dst, desc, registryOpts); err != nil {
+		return desc, err
+	}
+	signatures, err := fetchSignatureLayers(ctx, src, desc)
+	if err != nil || len(signatures) == 0 {
+		return desc, err
+	}
+	sigTag := SignatureTag(desc.Digest)
+	if _, err := oras.Copy(ctx, src, sigTag, dst, sigTag, registryOpts.copyOptions()); err != nil {
+		return desc, err
+	}
+	return desc, nil
+}
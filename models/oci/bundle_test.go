@@ -0,0 +1,78 @@
+package oci
+
+import (
+	"context"
+	"crypto"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func TestBundle(t *testing.T) {
+	ctx := context.Background()
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "design.yaml"), []byte("name: test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	key, keyPEM := newTestKey(t)
+	signer, err := NewSigner(keyPEM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := NewBundle(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.Add(ctx, src, "design", ArtifactKindDesign, ArtifactOptions{Name: "design"}, signer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.Add(ctx, src, "model", ArtifactKindModel, ArtifactOptions{Name: "model"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "bundle.tgz")
+	if err := bundle.Save(archive); err != nil {
+		t.Fatal(err)
+	}
+
+	// what follows happens in the air-gapped environment
+	opened, err := OpenBundle(archive, t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := opened.Entries(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name != "design" || !entries[0].Signed || entries[1].Kind != ArtifactKindModel || entries[1].Signed {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	policy := VerificationPolicy{Mode: VerificationRequired, PublicKeys: []crypto.PublicKey{&key.PublicKey}}
+	dest := t.TempDir()
+	if _, err := opened.Extract(ctx, "design", dest, policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "design.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := opened.Extract(ctx, "model", t.TempDir(), policy); err == nil {
+		t.Error("extracting an unsigned artifact with a required verification policy should fail")
+	}
+
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+	reference := strings.TrimPrefix(server.URL, "http://") + "/meshery/design:v1"
+	if _, err := opened.PushToRegistry(ctx, "design", reference, RegistryOptions{PlainHTTP: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := PullArtifact(ctx, reference, t.TempDir(), RegistryOptions{PlainHTTP: true, Verification: policy}); err != nil {
+		t.Fatal(err)
+	}
+}
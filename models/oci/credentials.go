@@ -0,0 +1,169 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// DefaultCredentialsEnvPrefix is the prefix of the environment variables read by EnvCredentials
+const DefaultCredentialsEnvPrefix = "OCI_REGISTRY"
+
+// CredentialProvider resolves the credential of a registry, e.g. ghcr.io or localhost:5000.
+// auth.EmptyCredential means that the provider has no credential for the registry.
+type CredentialProvider interface {
+	Credential(ctx context.Context, registry string) (auth.Credential, error)
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context, registry string) (auth.Credential, error)
+
+func (f CredentialProviderFunc) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	return f(ctx, registry)
+}
+
+// CredentialChain returns the first credential found by its providers, in order.
+// A provider failing, e.g. on a malformed Docker config, has no credential: the next providers are tried, and the
+// registry is accessed anonymously if none has a credential, so that public artifacts can still be pulled.
+type CredentialChain []CredentialProvider
+
+func (c CredentialChain) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	return c.lookup(ctx, registry, nil), nil
+}
+
+// lookup returns the first credential found, warn, if set, is called with the errors of the providers.
+func (c CredentialChain) lookup(ctx context.Context, registry string, warn func(error)) auth.Credential {
+	registry = normalizeRegistry(registry)
+	for _, provider := range c {
+		cred, err := provider.Credential(ctx, registry)
+		if err != nil {
+			if warn != nil {
+				warn(fmt.Errorf("looking up the credential of %s: %w", registry, err))
+			}
+			continue
+		}
+		if cred != auth.EmptyCredential {
+			return cred
+		}
+	}
+	return auth.EmptyCredential
+}
+
+// StaticCredentials are per registry credentials, they are usually used to override the ones found by the other providers.
+type StaticCredentials map[string]auth.Credential
+
+func (s StaticCredentials) Credential(_ context.Context, registry string) (auth.Credential, error) {
+	return s[normalizeRegistry(registry)], nil
+}
+
+// EnvCredentials reads the credentials of a registry from the environment, from
+// <Prefix>_<REGISTRY>_USERNAME and <Prefix>_<REGISTRY>_PASSWORD, where REGISTRY is the uppercased registry with every
+// non alphanumeric character replaced by an underscore (e.g. OCI_REGISTRY_GHCR_IO_USERNAME).
+// <Prefix>_<REGISTRY>_TOKEN holds an identity token.
+type EnvCredentials struct {
+	// Prefix defaults to DefaultCredentialsEnvPrefix
+	Prefix string
+	// AllRegistries makes <Prefix>_USERNAME, <Prefix>_PASSWORD and <Prefix>_TOKEN the credentials of the registries
+	// without registry scoped variables. They are then sent to every registry contacted, including the ones named by
+	// untrusted references, so it should only be set on a provider placed last in a chain.
+	AllRegistries bool
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Z0-9]+`)
+
+func (e EnvCredentials) Credential(_ context.Context, registry string) (auth.Credential, error) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = DefaultCredentialsEnvPrefix
+	}
+	prefixes := []string{prefix + "_" + nonAlphanumeric.ReplaceAllString(strings.ToUpper(normalizeRegistry(registry)), "_")}
+	if e.AllRegistries {
+		prefixes = append(prefixes, prefix)
+	}
+	for _, p := range prefixes {
+		cred := auth.Credential{
+			Username:     os.Getenv(p + "_USERNAME"),
+			Password:     os.Getenv(p + "_PASSWORD"),
+			RefreshToken: os.Getenv(p + "_TOKEN"),
+		}
+		if cred != auth.EmptyCredential {
+			return cred, nil
+		}
+	}
+	return auth.EmptyCredential, nil
+}
+
+// DockerConfigCredentials reads the credentials from a Docker config.json, including the ones stored by the
+// credential helpers it configures (credsStore and credHelpers).
+type DockerConfigCredentials struct {
+	// ConfigPath defaults to $DOCKER_CONFIG/config.json or ~/.docker/config.json
+	ConfigPath string
+}
+
+func (d DockerConfigCredentials) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	var store credentials.Store
+	var err error
+	if d.ConfigPath == "" {
+		store, err = credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	} else {
+		store, err = credentials.NewStore(d.ConfigPath, credentials.StoreOptions{})
+	}
+	if err != nil {
+		return auth.EmptyCredential, ErrAuthenticatingToRegistry(err)
+	}
+	return store.Get(ctx, credentials.ServerAddressFromRegistry(registry))
+}
+
+// CredentialHelper runs the docker-credential-<Helper> binary for the registries matching one of Registries.
+// Patterns are matched with path.Match, e.g. *.azurecr.io.
+type CredentialHelper struct {
+	Helper     string
+	Registries []string
+}
+
+// DefaultCredentialHelpers are the helpers of the Amazon, Google and Azure registries.
+// They are only run for the matching registries, and their binaries must be in the PATH.
+var DefaultCredentialHelpers = []CredentialHelper{
+	{Helper: "ecr-login", Registries: []string{"*.dkr.ecr.*.amazonaws.com", "*.dkr.ecr.*.amazonaws.com.cn", "public.ecr.aws"}},
+	{Helper: "gcloud", Registries: []string{"gcr.io", "*.gcr.io", "*-docker.pkg.dev"}},
+	{Helper: "acr-env", Registries: []string{"*.azurecr.io", "*.azurecr.cn"}},
+}
+
+func (h CredentialHelper) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	for _, pattern := range h.Registries {
+		if ok, _ := path.Match(pattern, registry); ok {
+			cred, err := credentials.NewNativeStore(h.Helper).Get(ctx, registry)
+			if err != nil {
+				// the helper is not installed or not logged in, let the next providers try
+				return auth.EmptyCredential, nil
+			}
+			return cred, nil
+		}
+	}
+	return auth.EmptyCredential, nil
+}
+
+// NewDefaultCredentialChain returns the chain of the overrides, the registry scoped variables of the environment, the
+// Docker config and the default credential helpers.
+func NewDefaultCredentialChain(overrides StaticCredentials) CredentialChain {
+	chain := CredentialChain{overrides, EnvCredentials{}, DockerConfigCredentials{}}
+	for _, helper := range DefaultCredentialHelpers {
+		chain = append(chain, helper)
+	}
+	return chain
+}
+
+// normalizeRegistry maps the hosts of Docker Hub to docker.io
+func normalizeRegistry(registry string) string {
+	switch registry {
+	case "registry-1.docker.io", "index.docker.io", "https://index.docker.io/v1/":
+		return "docker.io"
+	}
+	return registry
+}
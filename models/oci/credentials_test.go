@@ -0,0 +1,99 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func failingProvider(ctx context.Context, registry string) (auth.Credential, error) {
+	return auth.EmptyCredential, fmt.Errorf("no credential helper")
+}
+
+func TestCredentialChain(t *testing.T) {
+	ctx := context.Background()
+	found := auth.Credential{Username: "meshery", Password: "secret"}
+	malformed := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(malformed, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		chain CredentialChain
+		want  auth.Credential
+		warns int
+	}{
+		{name: "found", chain: CredentialChain{StaticCredentials{}, StaticCredentials{"docker.io": found}}, want: found},
+		{name: "empty", chain: CredentialChain{StaticCredentials{}, EnvCredentials{Prefix: "MESHKIT_TEST_UNSET"}}, want: auth.EmptyCredential},
+		{name: "error falls through", chain: CredentialChain{CredentialProviderFunc(failingProvider), StaticCredentials{"docker.io": found}}, want: found, warns: 1},
+		{name: "malformed docker config", chain: CredentialChain{DockerConfigCredentials{ConfigPath: malformed}}, want: auth.EmptyCredential, warns: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := tt.chain.Credential(ctx, "index.docker.io")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cred != tt.want {
+				t.Errorf("got %+v, want %+v", cred, tt.want)
+			}
+			var warns int
+			tt.chain.lookup(ctx, "index.docker.io", func(error) { warns++ })
+			if warns != tt.warns {
+				t.Errorf("got %d warnings, want %d", warns, tt.warns)
+			}
+		})
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("MESHKIT_TEST_USERNAME", "shared")
+	t.Setenv("MESHKIT_TEST_PASSWORD", "shared-secret")
+	t.Setenv("MESHKIT_TEST_GHCR_IO_USERNAME", "meshery")
+	t.Setenv("MESHKIT_TEST_GHCR_IO_PASSWORD", "secret")
+
+	tests := []struct {
+		name     string
+		provider EnvCredentials
+		registry string
+		want     auth.Credential
+	}{
+		{name: "scoped", provider: EnvCredentials{Prefix: "MESHKIT_TEST"}, registry: "ghcr.io", want: auth.Credential{Username: "meshery", Password: "secret"}},
+		{name: "unscoped ignored", provider: EnvCredentials{Prefix: "MESHKIT_TEST"}, registry: "attacker.example.com", want: auth.EmptyCredential},
+		{name: "scoped over unscoped", provider: EnvCredentials{Prefix: "MESHKIT_TEST", AllRegistries: true}, registry: "ghcr.io", want: auth.Credential{Username: "meshery", Password: "secret"}},
+		{name: "unscoped opt-in", provider: EnvCredentials{Prefix: "MESHKIT_TEST", AllRegistries: true}, registry: "docker.io", want: auth.Credential{Username: "shared", Password: "shared-secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := tt.provider.Credential(ctx, tt.registry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cred != tt.want {
+				t.Errorf("got %+v, want %+v", cred, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteRepositoryFallsBackToAnonymous(t *testing.T) {
+	var warnings []error
+	opts := RegistryOptions{Credentials: CredentialProviderFunc(failingProvider), Warn: func(err error) { warnings = append(warnings, err) }}
+	repo, _, err := newRemoteRepository("ghcr.io/meshery/kubernetes:v1.0.0", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := repo.Client.(*auth.Client).Credential(context.Background(), "ghcr.io")
+	if err != nil || cred != auth.EmptyCredential {
+		t.Errorf("got %+v, %v, want anonymous access", cred, err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got %d warnings, want 1", len(warnings))
+	}
+}
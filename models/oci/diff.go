@@ -0,0 +1,263 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils/diff"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	oras "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// ArtifactInfo describes one of the artifacts compared by DiffArtifacts.
+type ArtifactInfo struct {
+	// Reference is the reference of the artifact, or the path of the local directory.
+	Reference    string            `json:"reference"`
+	Local        bool              `json:"local,omitempty"`
+	Digest       digest.Digest     `json:"digest"`
+	ArtifactType string            `json:"artifactType"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// LayerChange is a layer of the manifests differing between the artifacts. The layers are matched by media type, in order.
+type LayerChange struct {
+	Type      diff.ChangeType     `json:"type"`
+	MediaType string              `json:"mediaType"`
+	From      *ocispec.Descriptor `json:"from,omitempty"`
+	To        *ocispec.Descriptor `json:"to,omitempty"`
+}
+
+// FileChange is a file of the content of the artifacts differing between them.
+type FileChange struct {
+	Path       string          `json:"path"`
+	Type       diff.ChangeType `json:"type"`
+	FromDigest digest.Digest   `json:"fromDigest,omitempty"`
+	ToDigest   digest.Digest   `json:"toDigest,omitempty"`
+	// Changes are the differences of the values of the modified JSON and YAML documents, e.g. of a design.
+	Changes []diff.Change `json:"changes,omitempty"`
+}
+
+// ArtifactDiff is the difference between two artifacts, at the level of their manifests and of their content.
+type ArtifactDiff struct {
+	From ArtifactInfo `json:"from"`
+	To   ArtifactInfo `json:"to"`
+	// Annotations are the differences of the manifest annotations, by key. They are not compared with local directories.
+	Annotations []diff.Change `json:"annotations,omitempty"`
+	Layers      []LayerChange `json:"layers,omitempty"`
+	Files       []FileChange  `json:"files,omitempty"`
+}
+
+// Identical reports whether the artifacts are the same, or have the same annotations and content. The layers of
+// artifacts of the same content may still differ, as the tarballs of the content record the times of the files.
+func (d *ArtifactDiff) Identical() bool {
+	return d.From.Digest == d.To.Digest || (len(d.Annotations) == 0 && len(d.Files) == 0)
+}
+
+// DiffArtifacts compares two artifacts, e.g. the version of a shared design pulled before and its latest version,
+// before pulling it. Each of from and to is either a reference to an artifact in a registry, of the form
+// registry/repository[:tag|@digest], or a local directory, which is compared as if it was packaged with PackArtifact.
+// The differences of the layers are reported along with the files added, removed or modified in the content.
+func DiffArtifacts(ctx context.Context, from, to string, registryOpts RegistryOptions) (*ArtifactDiff, error) {
+	fromArtifact, err := loadDiffArtifact(ctx, from, registryOpts)
+	if err != nil {
+		return nil, err
+	}
+	toArtifact, err := loadDiffArtifact(ctx, to, registryOpts)
+	if err != nil {
+		return nil, err
+	}
+	// the kind of a local directory is the kind of the artifact it is compared to
+	if fromArtifact.info.Local && !toArtifact.info.Local {
+		fromArtifact.info.ArtifactType = toArtifact.info.ArtifactType
+	}
+	if toArtifact.info.Local && !fromArtifact.info.Local {
+		toArtifact.info.ArtifactType = fromArtifact.info.ArtifactType
+	}
+
+	d := &ArtifactDiff{From: fromArtifact.info, To: toArtifact.info}
+	if d.From.Digest == d.To.Digest {
+		return d, nil
+	}
+	if !d.From.Local && !d.To.Local {
+		d.Annotations = diffAnnotations(d.From.Annotations, d.To.Annotations)
+	}
+	d.Layers = diffLayers(fromArtifact.manifest.Layers, toArtifact.manifest.Layers)
+
+	fromFiles, err := fromArtifact.files(ctx)
+	if err != nil {
+		return nil, ErrDiffArtifacts(err, from)
+	}
+	toFiles, err := toArtifact.files(ctx)
+	if err != nil {
+		return nil, ErrDiffArtifacts(err, to)
+	}
+	d.Files = diffFiles(fromFiles, toFiles)
+	return d, nil
+}
+
+type diffArtifact struct {
+	info     ArtifactInfo
+	manifest ocispec.Manifest
+	store    content.Fetcher
+	desc     ocispec.Descriptor
+	dir      string
+	// maxUnpackSize is the MaxUnpackSize of the registry options the artifact is loaded with
+	maxUnpackSize int
+}
+
+func loadDiffArtifact(ctx context.Context, source string, registryOpts RegistryOptions) (*diffArtifact, error) {
+	store := memory.New()
+	a := &diffArtifact{store: store, info: ArtifactInfo{Reference: source}, maxUnpackSize: registryOpts.MaxUnpackSize}
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		desc, err := PackArtifact(ctx, store, source, ArtifactKindDesign, ArtifactOptions{})
+		if err != nil {
+			return nil, err
+		}
+		a.desc, a.dir, a.info.Local = desc, source, true
+	} else {
+		repo, ref, err := newRemoteRepository(source, registryOpts)
+		if err != nil {
+			return nil, err
+		}
+		src := ref.ReferenceOrDefault()
+		desc, err := oras.Copy(ctx, newRemoteTarget(repo, registryOpts), src, registryOpts.withProgress(store), src, registryOpts.copyOptions())
+		if err != nil {
+			return nil, ErrGettingImage(err)
+		}
+		a.desc = desc
+	}
+	manifest, err := fetchManifest(ctx, store, a.desc)
+	if err != nil {
+		return nil, ErrUnknownArtifact(err)
+	}
+	a.manifest = manifest
+	a.info.Digest = a.desc.Digest
+	a.info.ArtifactType = manifest.ArtifactType
+	a.info.Annotations = manifest.Annotations
+	return a, nil
+}
+
+// files returns the digests of the files of the content of the artifact, by path relative to its root.
+func (a *diffArtifact) files(ctx context.Context) (map[string]fileDigest, error) {
+	dir := a.dir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "oci-diff")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		if _, err := UnpackArtifact(ctx, a.store, a.desc, tmp, a.maxUnpackSize); err != nil {
+			return nil, err
+		}
+		dir = tmp
+	}
+	files := map[string]fileDigest{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = fileDigest{digest: digest.FromBytes(data), data: data}
+		return nil
+	})
+	return files, err
+}
+
+type fileDigest struct {
+	digest digest.Digest
+	data   []byte
+}
+
+func diffAnnotations(from, to map[string]string) []diff.Change {
+	var changes []diff.Change
+	for k, v := range from {
+		if toValue, ok := to[k]; !ok {
+			changes = append(changes, diff.Change{Path: k, Type: diff.Removed, From: v})
+		} else if toValue != v {
+			changes = append(changes, diff.Change{Path: k, Type: diff.Modified, From: v, To: toValue})
+		}
+	}
+	for k, v := range to {
+		if _, ok := from[k]; !ok {
+			changes = append(changes, diff.Change{Path: k, Type: diff.Added, To: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func diffLayers(from, to []ocispec.Descriptor) []LayerChange {
+	key := func(layers []ocispec.Descriptor) ([]string, map[string]ocispec.Descriptor) {
+		var keys []string
+		byKey := map[string]ocispec.Descriptor{}
+		counts := map[string]int{}
+		for _, l := range layers {
+			k := fmt.Sprintf("%s#%d", l.MediaType, counts[l.MediaType])
+			counts[l.MediaType]++
+			keys = append(keys, k)
+			byKey[k] = l
+		}
+		return keys, byKey
+	}
+	fromKeys, fromLayers := key(from)
+	toKeys, toLayers := key(to)
+	var changes []LayerChange
+	for _, k := range fromKeys {
+		f := fromLayers[k]
+		t, ok := toLayers[k]
+		switch {
+		case !ok:
+			changes = append(changes, LayerChange{Type: diff.Removed, MediaType: f.MediaType, From: &f})
+		case t.Digest != f.Digest:
+			changes = append(changes, LayerChange{Type: diff.Modified, MediaType: f.MediaType, From: &f, To: &t})
+		}
+	}
+	for _, k := range toKeys {
+		if _, ok := fromLayers[k]; !ok {
+			t := toLayers[k]
+			changes = append(changes, LayerChange{Type: diff.Added, MediaType: t.MediaType, To: &t})
+		}
+	}
+	return changes
+}
+
+func diffFiles(from, to map[string]fileDigest) []FileChange {
+	var changes []FileChange
+	for path, f := range from {
+		t, ok := to[path]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: path, Type: diff.Removed, FromDigest: f.digest})
+		case t.digest != f.digest:
+			change := FileChange{Path: path, Type: diff.Modified, FromDigest: f.digest, ToDigest: t.digest}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".json", ".yaml", ".yml":
+				// documents which can't be decoded are reported as modified only
+				change.Changes, _ = diff.CompareDocuments(f.data, t.data, diff.Options{})
+			}
+			changes = append(changes, change)
+		}
+	}
+	for path, t := range to {
+		if _, ok := from[path]; !ok {
+			changes = append(changes, FileChange{Path: path, Type: diff.Added, ToDigest: t.digest})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
@@ -0,0 +1,86 @@
+package oci
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/layer5io/meshkit/utils/diff"
+)
+
+func writeDesign(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, data := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiffArtifacts(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	ctx := context.Background()
+	opts := RegistryOptions{PlainHTTP: true}
+
+	v1, v2 := t.TempDir(), t.TempDir()
+	writeDesign(t, v1, map[string]string{"design.yaml": "name: app\nservices:\n  web:\n    replicas: 1\n", "README.md": "app"})
+	writeDesign(t, v2, map[string]string{"design.yaml": "name: app\nservices:\n  web:\n    replicas: 3\n", "notes/CHANGES.md": "scaled"})
+	for tag, dir := range map[string]string{"v1": v1, "v2": v2} {
+		if _, err := PushArtifact(ctx, dir, host+"/meshery/app:"+tag, ArtifactKindDesign, ArtifactOptions{Version: tag}, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := DiffArtifacts(ctx, host+"/meshery/app:v1", host+"/meshery/app:v2", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Identical() || d.From.Digest == d.To.Digest || d.From.ArtifactType != DesignArtifactType {
+		t.Fatalf("unexpected diff %+v", d)
+	}
+	if len(d.Layers) != 1 || d.Layers[0].Type != diff.Modified || d.Layers[0].MediaType != ContentLayerMediaType {
+		t.Errorf("unexpected layer changes %+v", d.Layers)
+	}
+	versions := map[string]bool{}
+	for _, c := range d.Annotations {
+		versions[c.Path] = true
+	}
+	if !versions["org.opencontainers.image.version"] {
+		t.Errorf("unexpected annotation changes %+v", d.Annotations)
+	}
+	if len(d.Files) != 3 {
+		t.Fatalf("unexpected file changes %+v", d.Files)
+	}
+	if f := d.Files[0]; f.Path != "README.md" || f.Type != diff.Removed {
+		t.Errorf("unexpected change %+v", f)
+	}
+	if f := d.Files[1]; f.Path != "design.yaml" || f.Type != diff.Modified || len(f.Changes) != 1 || f.Changes[0].Path != "services.web.replicas" {
+		t.Errorf("unexpected change %+v", f)
+	}
+	if f := d.Files[2]; f.Path != "notes/CHANGES.md" || f.Type != diff.Added {
+		t.Errorf("unexpected change %+v", f)
+	}
+
+	d, err = DiffArtifacts(ctx, v2, host+"/meshery/app:v2", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Identical() || !d.From.Local || len(d.Annotations) != 0 {
+		t.Errorf("expected the directory to have the content of the artifact, got %+v", d)
+	}
+
+	if _, err := DiffArtifacts(ctx, host+"/meshery/app:v3", v1, opts); err == nil {
+		t.Error("expected an error comparing a missing artifact")
+	}
+}
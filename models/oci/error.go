@@ -22,6 +22,15 @@ var (
 	ErrAddLayerCode                 = "meshkit-11247"
 	ErrTaggingPackageCode           = "meshkit-11248"
 	ErrPushingPackageCode           = "meshkit-11249"
+	ErrInvalidReferenceCode         = "meshkit-11263"
+	ErrUnknownArtifactCode          = "meshkit-11264"
+	ErrSigningArtifactCode          = "meshkit-11265"
+	ErrVerifyingSignatureCode       = "meshkit-11266"
+	ErrLoadingKeyCode               = "meshkit-11267"
+	ErrOpeningLayoutCode            = "meshkit-11268"
+	ErrAttachingReferrerCode        = "meshkit-11300"
+	ErrFetchingReferrersCode        = "meshkit-11301"
+	ErrDiffArtifactsCode            = "meshkit-11347"
 )
 
 func ErrAppendingLayer(err error) error {
@@ -83,3 +92,39 @@ func ErrTaggingPackage(err error) error {
 func ErrPushingPackage(err error) error {
 	return errors.New(ErrPushingPackageCode, errors.Alert, []string{"pushing package failed"}, []string{err.Error()}, []string{"failed to push the package"}, []string{"Try using a different tag", "check if package is not malformed"})
 }
+
+func ErrInvalidReference(err error, reference string) error {
+	return errors.New(ErrInvalidReferenceCode, errors.Alert, []string{"invalid artifact reference " + reference}, []string{err.Error()}, []string{"the reference is not of the form registry/repository[:tag|@digest]"}, []string{"Check the registry, repository, tag or digest of the reference"})
+}
+
+func ErrUnknownArtifact(err error) error {
+	return errors.New(ErrUnknownArtifactCode, errors.Alert, []string{"the artifact is not a Meshery design or model"}, []string{err.Error()}, []string{"the artifact was not packaged by Meshery", "the artifact is malformed"}, []string{"Make sure the reference points to a Meshery design or model artifact"})
+}
+
+func ErrSigningArtifact(err error) error {
+	return errors.New(ErrSigningArtifactCode, errors.Alert, []string{"signing artifact failed"}, []string{err.Error()}, []string{"the signature could not be created or pushed to the registry"}, []string{"Check that the signing key is valid", "check if write permissions are given on the repository"})
+}
+
+func ErrVerifyingSignature(err error, reference string) error {
+	return errors.New(ErrVerifyingSignatureCode, errors.Alert, []string{"signature verification failed for " + reference}, []string{err.Error()}, []string{"the artifact is not signed", "the artifact was signed by an untrusted key or identity", "the artifact was tampered with"}, []string{"Make sure the artifact is signed by one of the trusted keys or identities of the verification policy"})
+}
+
+func ErrLoadingKey(err error) error {
+	return errors.New(ErrLoadingKeyCode, errors.Alert, []string{"loading key failed"}, []string{err.Error()}, []string{"the key is not a PEM encoded ECDSA key", "the password of the encrypted key is wrong"}, []string{"Use a key generated by cosign generate-key-pair or a PEM encoded ECDSA P-256 key"})
+}
+
+func ErrOpeningLayout(err error, path string) error {
+	return errors.New(ErrOpeningLayoutCode, errors.Alert, []string{"opening OCI layout at " + path + " failed"}, []string{err.Error()}, []string{"the directory is not an OCI image layout", "the bundle is corrupted", "Insufficient permissions"}, []string{"Make sure the path points to an OCI image layout or a bundle created by Meshery", "check if appropriate read and write permissions are given to the path"})
+}
+
+func ErrAttachingReferrer(err error) error {
+	return errors.New(ErrAttachingReferrerCode, errors.Alert, []string{"attaching attestation failed"}, []string{err.Error()}, []string{"the artifact type of the attestation is not set", "the attestation could not be stored along with the artifact"}, []string{"Set the artifact type of the attestation, e.g. application/spdx+json for SPDX SBOMs", "check if write permissions are given on the repository"})
+}
+
+func ErrFetchingReferrers(err error, reference string) error {
+	return errors.New(ErrFetchingReferrersCode, errors.Alert, []string{"fetching the attestations of " + reference + " failed"}, []string{err.Error()}, []string{"the registry does not support the referrers API nor the referrers tag schema", "the attestation is malformed"}, []string{"Check the registry supports OCI referrers", "check if read permissions are given on the repository"})
+}
+
+func ErrDiffArtifacts(err error, reference string) error {
+	return errors.New(ErrDiffArtifactsCode, errors.Alert, []string{"comparing the content of " + reference + " failed"}, []string{err.Error()}, []string{"the content layer of the artifact could not be extracted", "the local directory is not readable"}, []string{"Check the artifact is a Meshery design or model artifact", "check if read permissions are given on the directory"})
+}
@@ -0,0 +1,134 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	oras "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Attestations, e.g. SBOMs and provenance, are attached to artifacts as OCI referrers: manifests whose subject is the
+// manifest of the artifact, see https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidelines-for-artifact-usage.
+// Registries without the referrers API are supported through the fallback tag schema.
+const (
+	// SPDXArtifactType is the artifactType of SBOMs in the SPDX JSON format
+	SPDXArtifactType = "application/spdx+json"
+	// CycloneDXArtifactType is the artifactType of SBOMs in the CycloneDX JSON format
+	CycloneDXArtifactType = "application/vnd.cyclonedx+json"
+	// InTotoArtifactType is the artifactType of in-toto attestations, e.g. SLSA provenance
+	InTotoArtifactType = "application/vnd.in-toto+json"
+)
+
+// Attestation is supply-chain metadata attached to an artifact.
+type Attestation struct {
+	// ArtifactType is the artifactType of the referrer manifest, and the media type of its only layer
+	ArtifactType string
+	Content      []byte
+	// Annotations are added to the referrer manifest annotations
+	Annotations map[string]string
+	// Descriptor is the descriptor of the referrer manifest, set for attached and fetched attestations
+	Descriptor ocispec.Descriptor
+}
+
+// Attach stores the attestation in target as a referrer of the manifest described by subject.
+// The returned descriptor is the descriptor of the referrer manifest.
+func Attach(ctx context.Context, target content.Storage, subject ocispec.Descriptor, attestation Attestation) (ocispec.Descriptor, error) {
+	if attestation.ArtifactType == "" {
+		return ocispec.Descriptor{}, ErrAttachingReferrer(fmt.Errorf("the artifact type of the attestation is not set"))
+	}
+	layer := content.NewDescriptorFromBytes(attestation.ArtifactType, attestation.Content)
+	if err := pushIfNotExists(ctx, target, layer, attestation.Content); err != nil {
+		return ocispec.Descriptor{}, ErrAttachingReferrer(err)
+	}
+	desc, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1_RC4, attestation.ArtifactType, oras.PackManifestOptions{
+		Subject:             &subject,
+		Layers:              []ocispec.Descriptor{layer},
+		ManifestAnnotations: attestation.Annotations,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, ErrAttachingReferrer(err)
+	}
+	return desc, nil
+}
+
+// Referrers returns the descriptors of the referrers of the manifest described by subject.
+// Only referrers of the artifactType are returned, unless it is empty.
+func Referrers(ctx context.Context, source content.ReadOnlyGraphStorage, subject ocispec.Descriptor, artifactType string) ([]ocispec.Descriptor, error) {
+	referrers, err := registry.Referrers(ctx, source, subject, artifactType)
+	if err != nil {
+		return nil, ErrFetchingReferrers(err, subject.Digest.String())
+	}
+	return referrers, nil
+}
+
+// FetchAttestations returns the attestations attached to the manifest described by subject.
+// Only attestations of the artifactType are returned, unless it is empty.
+func FetchAttestations(ctx context.Context, source content.ReadOnlyGraphStorage, subject ocispec.Descriptor, artifactType string) ([]Attestation, error) {
+	referrers, err := Referrers(ctx, source, subject, artifactType)
+	if err != nil {
+		return nil, err
+	}
+	attestations := make([]Attestation, 0, len(referrers))
+	for _, referrer := range referrers {
+		manifest, err := fetchManifest(ctx, source, referrer)
+		if err != nil {
+			return nil, ErrFetchingReferrers(err, subject.Digest.String())
+		}
+		if len(manifest.Layers) == 0 {
+			continue
+		}
+		data, err := content.FetchAll(ctx, source, manifest.Layers[0])
+		if err != nil {
+			return nil, ErrFetchingReferrers(err, subject.Digest.String())
+		}
+		attestations = append(attestations, Attestation{
+			ArtifactType: manifest.ArtifactType,
+			Content:      data,
+			Annotations:  manifest.Annotations,
+			Descriptor:   referrer,
+		})
+	}
+	return attestations, nil
+}
+
+// PullAttestations returns the attestations attached to the artifact at the reference, of the form registry/repository[:tag|@digest].
+// Only attestations of the artifactType are returned, unless it is empty.
+func PullAttestations(ctx context.Context, reference, artifactType string, registryOpts RegistryOptions) ([]Attestation, error) {
+	repo, ref, err := newRemoteRepository(reference, registryOpts)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := repo.Resolve(ctx, ref.ReferenceOrDefault())
+	if err != nil {
+		return nil, ErrGettingImage(err)
+	}
+	return FetchAttestations(ctx, repo, desc, artifactType)
+}
+
+// copyReferrers copies the referrers of the manifest described by subject from src to dst.
+func copyReferrers(ctx context.Context, src oras.ReadOnlyGraphTarget, dst oras.Target, subject ocispec.Descriptor, registryOpts RegistryOptions) error {
+	referrers, err := registry.Referrers(ctx, src, subject, "")
+	if err != nil {
+		return err
+	}
+	for _, referrer := range referrers {
+		if err := oras.CopyGraph(ctx, src, dst, referrer, registryOpts.copyGraphOptions()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchManifest(ctx context.Context, source content.Fetcher, desc ocispec.Descriptor) (ocispec.Manifest, error) {
+	var manifest ocispec.Manifest
+	data, err := content.FetchAll(ctx, source, desc)
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(data, &manifest)
+	return manifest, err
+}
@@ -0,0 +1,74 @@
+package oci
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func TestAttestations(t *testing.T) {
+	ctx := context.Background()
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "design.yaml"), []byte("name: test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sbom := Attestation{ArtifactType: SPDXArtifactType, Content: []byte(`{"spdxVersion":"SPDX-2.3"}`)}
+	provenance := Attestation{ArtifactType: InTotoArtifactType, Content: []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)}
+	opts := ArtifactOptions{Name: "design", Attestations: []Attestation{sbom, provenance}}
+
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+	reference := strings.TrimPrefix(server.URL, "http://") + "/meshery/design:v1"
+	if _, err := PushArtifact(ctx, src, reference, ArtifactKindDesign, opts, RegistryOptions{PlainHTTP: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	attestations, err := PullAttestations(ctx, reference, "", RegistryOptions{PlainHTTP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attestations) != 2 {
+		t.Fatalf("expected 2 attestations, got %d", len(attestations))
+	}
+	sboms, err := PullAttestations(ctx, reference, SPDXArtifactType, RegistryOptions{PlainHTTP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sboms) != 1 || string(sboms[0].Content) != string(sbom.Content) {
+		t.Fatalf("unexpected SBOMs %+v", sboms)
+	}
+
+	// attestations travel with the artifact into bundles
+	bundle, err := NewBundle(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.AddFromRegistry(ctx, reference, "design", RegistryOptions{PlainHTTP: true}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := bundle.Entries(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].Attestations) != 2 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	provenances, err := bundle.Attestations(ctx, "design", InTotoArtifactType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provenances) != 1 || string(provenances[0].Content) != string(provenance.Content) {
+		t.Fatalf("unexpected provenance %+v", provenances)
+	}
+
+	if _, err := bundle.Add(ctx, src, "invalid", ArtifactKindDesign, ArtifactOptions{Attestations: []Attestation{{Content: []byte("{}")}}}, nil); err == nil {
+		t.Error("attaching an attestation without artifact type should fail")
+	}
+}
@@ -0,0 +1,439 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+	oras "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Signatures follow the layout used by cosign, so that artifacts signed by MeshKit can be verified with
+// `cosign verify` and the other way around: the signatures of the manifest sha256:<hex> are the layers of the
+// manifest tagged sha256-<hex>.sig in the same repository.
+const (
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	SignatureAnnotation    = "dev.cosignproject.cosign/signature"
+	CertificateAnnotation  = "dev.sigstore.cosign/certificate"
+	ChainAnnotation        = "dev.sigstore.cosign/chain"
+
+	simpleSigningType = "cosign container image signature"
+)
+
+var (
+	// Fulcio certificate extensions holding the OIDC issuer of the identity
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+// SignatureTag returns the tag holding the signatures of the manifest with the given digest.
+func SignatureTag(manifestDigest digest.Digest) string {
+	return fmt.Sprintf("%s-%s.sig", manifestDigest.Algorithm(), manifestDigest.Encoded())
+}
+
+// Signer signs artifacts with an ECDSA key.
+// Keyless signatures are made with the short lived certificate issued for the key, e.g. by Fulcio,
+// which is attached to the signature so that the identity of the signer can be verified.
+type Signer struct {
+	key         *ecdsa.PrivateKey
+	certificate []byte
+	chain       []byte
+}
+
+// NewSigner returns a Signer for the PEM encoded private key.
+// Both keys generated by `cosign generate-key-pair`, encrypted with password, and unencrypted PKCS#8 or EC keys are supported.
+func NewSigner(keyPEM, password []byte) (*Signer, error) {
+	key, err := loadPrivateKey(keyPEM, password)
+	if err != nil {
+		return nil, ErrLoadingKey(err)
+	}
+	return &Signer{key: key}, nil
+}
+
+// NewKeylessSigner returns a Signer for the key and the PEM encoded certificate issued for it, and its optional chain.
+func NewKeylessSigner(keyPEM, password, certificatePEM, chainPEM []byte) (*Signer, error) {
+	signer, err := NewSigner(keyPEM, password)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := parseCertificate(certificatePEM)
+	if err != nil {
+		return nil, ErrLoadingKey(err)
+	}
+	if !signer.key.PublicKey.Equal(cert.PublicKey) {
+		return nil, ErrLoadingKey(fmt.Errorf("the certificate was not issued for the signing key"))
+	}
+	signer.certificate = certificatePEM
+	signer.chain = chainPEM
+	return signer, nil
+}
+
+// Sign signs the manifest described by desc and stores the signature in target.
+// repository is the name of the repository in the registry, e.g. docker.io/meshery/kubernetes, it is part of the signed payload.
+// Existing signatures of the manifest are kept.
+func (s *Signer) Sign(ctx context.Context, target oras.Target, repository string, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	var payload simpleSigningPayload
+	payload.Critical.Identity.DockerReference = repository
+	payload.Critical.Image.DockerManifestDigest = desc.Digest.String()
+	payload.Critical.Type = simpleSigningType
+	payloadData, err := json.Marshal(payload)
+	if err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+
+	hash := sha256.Sum256(payloadData)
+	signature, err := ecdsa.SignASN1(rand.Reader, s.key, hash[:])
+	if err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+
+	layer := content.NewDescriptorFromBytes(SimpleSigningMediaType, payloadData)
+	layer.Annotations = map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(signature)}
+	if s.certificate != nil {
+		layer.Annotations[CertificateAnnotation] = string(s.certificate)
+		if s.chain != nil {
+			layer.Annotations[ChainAnnotation] = string(s.chain)
+		}
+	}
+	if err := pushIfNotExists(ctx, target, layer, payloadData); err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+
+	layers, err := fetchSignatureLayers(ctx, target, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+	layers = append(layers, layer)
+
+	diffIDs := make([]digest.Digest, 0, len(layers))
+	for _, l := range layers {
+		diffIDs = append(diffIDs, l.Digest)
+	}
+	configData, err := json.Marshal(ocispec.Image{
+		RootFS: ocispec.RootFS{Type: "layers", DiffIDs: diffIDs},
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+	config := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, configData)
+	if err := pushIfNotExists(ctx, target, config, configData); err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+
+	manifest, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_0, "", oras.PackManifestOptions{
+		Layers:           layers,
+		ConfigDescriptor: &config,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+	if err := target.Tag(ctx, manifest, SignatureTag(desc.Digest)); err != nil {
+		return ocispec.Descriptor{}, ErrSigningArtifact(err)
+	}
+	return manifest, nil
+}
+
+// VerificationMode defines what happens when an artifact has no signature.
+type VerificationMode string
+
+const (
+	// VerificationDisabled skips the verification
+	VerificationDisabled VerificationMode = ""
+	// VerificationOptional accepts unsigned artifacts, but rejects artifacts with invalid signatures only
+	VerificationOptional VerificationMode = "optional"
+	// VerificationRequired rejects artifacts without at least one valid signature
+	VerificationRequired VerificationMode = "required"
+)
+
+// TrustedIdentity is an identity allowed to sign artifacts with a certificate.
+// Empty fields match any value.
+type TrustedIdentity struct {
+	// Issuer is the OIDC issuer recorded in the certificate, e.g. https://token.actions.githubusercontent.com
+	Issuer string
+	// Subject is the email or URI subject alternative name of the certificate
+	Subject string
+}
+
+// VerificationPolicy defines which signatures are trusted.
+// A signature is valid if it was made by one of PublicKeys, or by a certificate which chains up to Roots and
+// was issued to one of TrustedIdentities.
+// Transparency log entries are not verified.
+type VerificationPolicy struct {
+	Mode              VerificationMode
+	PublicKeys        []crypto.PublicKey
+	Roots             *x509.CertPool
+	Intermediates     *x509.CertPool
+	TrustedIdentities []TrustedIdentity
+}
+
+// LoadPublicKey parses a PEM encoded public key, as written by `cosign generate-key-pair`.
+func LoadPublicKey(keyPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, ErrLoadingKey(fmt.Errorf("no PEM block found"))
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, ErrLoadingKey(err)
+	}
+	return key, nil
+}
+
+// Verify checks the signatures stored in source for the manifest described by desc against the policy.
+func (p VerificationPolicy) Verify(ctx context.Context, source oras.ReadOnlyTarget, desc ocispec.Descriptor) error {
+	if p.Mode == VerificationDisabled {
+		return nil
+	}
+	layers, err := fetchSignatureLayers(ctx, source, desc)
+	if err != nil {
+		return ErrVerifyingSignature(err, desc.Digest.String())
+	}
+	if len(layers) == 0 {
+		if p.Mode == VerificationOptional {
+			return nil
+		}
+		return ErrVerifyingSignature(fmt.Errorf("no signature found"), desc.Digest.String())
+	}
+
+	errs := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		err := p.verifyLayer(ctx, source, desc, layer)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return ErrVerifyingSignature(fmt.Errorf("no valid signature found: %s", strings.Join(errs, "; ")), desc.Digest.String())
+}
+
+func (p VerificationPolicy) verifyLayer(ctx context.Context, source content.Fetcher, desc, layer ocispec.Descriptor) error {
+	if layer.MediaType != SimpleSigningMediaType {
+		return fmt.Errorf("unsupported signature media type %s", layer.MediaType)
+	}
+	payloadData, err := content.FetchAll(ctx, source, layer)
+	if err != nil {
+		return err
+	}
+	var payload simpleSigningPayload
+	if err := json.Unmarshal(payloadData, &payload); err != nil {
+		return err
+	}
+	if payload.Critical.Image.DockerManifestDigest != desc.Digest.String() {
+		return fmt.Errorf("the signature is for %s", payload.Critical.Image.DockerManifestDigest)
+	}
+	signature, err := base64.StdEncoding.DecodeString(layer.Annotations[SignatureAnnotation])
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(payloadData)
+
+	if certPEM, ok := layer.Annotations[CertificateAnnotation]; ok {
+		cert, err := p.verifyCertificate([]byte(certPEM), []byte(layer.Annotations[ChainAnnotation]))
+		if err != nil {
+			return err
+		}
+		key, ok := cert.PublicKey.(*ecdsa.PublicKey)
+		if !ok || !ecdsa.VerifyASN1(key, hash[:], signature) {
+			return fmt.Errorf("invalid signature for the certificate of %s", certificateSubjects(cert))
+		}
+		return nil
+	}
+
+	for _, pub := range p.PublicKeys {
+		if key, ok := pub.(*ecdsa.PublicKey); ok && ecdsa.VerifyASN1(key, hash[:], signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the signature was not made by a trusted key")
+}
+
+func (p VerificationPolicy) verifyCertificate(certPEM, chainPEM []byte) (*x509.Certificate, error) {
+	if p.Roots == nil {
+		return nil, fmt.Errorf("certificate based signatures are not trusted by the policy")
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	intermediates := x509.NewCertPool()
+	if p.Intermediates != nil {
+		intermediates = p.Intermediates.Clone()
+	}
+	intermediates.AppendCertsFromPEM(chainPEM)
+	// certificates issued for keyless signing are short lived, the signature is checked against the validity period at issuance
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         p.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   cert.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return nil, err
+	}
+
+	issuer := certificateIssuer(cert)
+	subjects := certificateSubjects(cert)
+	for _, identity := range p.TrustedIdentities {
+		if identity.Issuer != "" && identity.Issuer != issuer {
+			continue
+		}
+		if identity.Subject == "" {
+			return cert, nil
+		}
+		for _, subject := range subjects {
+			if subject == identity.Subject {
+				return cert, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("the identity %v issued by %q is not trusted", subjects, issuer)
+}
+
+func certificateSubjects(cert *x509.Certificate) []string {
+	subjects := append([]string{}, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		subjects = append(subjects, uri.String())
+	}
+	return subjects
+}
+
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuerV1):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// fetchSignatureLayers returns the signature layers of the manifest, none if it isn't signed.
+func fetchSignatureLayers(ctx context.Context, source oras.ReadOnlyTarget, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	sigDesc, err := source.Resolve(ctx, SignatureTag(desc.Digest))
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, err := content.FetchAll(ctx, source, sigDesc)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return manifest.Layers, nil
+}
+
+func pushIfNotExists(ctx context.Context, target content.Storage, desc ocispec.Descriptor, data []byte) error {
+	exists, err := target.Exists(ctx, desc)
+	if err != nil || exists {
+		return err
+	}
+	return target.Push(ctx, desc, bytes.NewReader(data))
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// encryptedKey is the JSON envelope of the private keys generated by cosign
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func loadPrivateKey(keyPEM, password []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	der := block.Bytes
+	switch block.Type {
+	case "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED COSIGN PRIVATE KEY":
+		var enc encryptedKey
+		if err := json.Unmarshal(block.Bytes, &enc); err != nil {
+			return nil, err
+		}
+		if enc.KDF.Name != "scrypt" || enc.Cipher.Name != "nacl/secretbox" || len(enc.Cipher.Nonce) != 24 {
+			return nil, fmt.Errorf("unsupported key encryption %s/%s", enc.KDF.Name, enc.Cipher.Name)
+		}
+		secret, err := scrypt.Key(password, enc.KDF.Salt, enc.KDF.Params.N, enc.KDF.Params.R, enc.KDF.Params.P, 32)
+		if err != nil {
+			return nil, err
+		}
+		var nonce [24]byte
+		var secretKey [32]byte
+		copy(nonce[:], enc.Cipher.Nonce)
+		copy(secretKey[:], secret)
+		plain, ok := secretbox.Open(nil, enc.Ciphertext, &nonce, &secretKey)
+		if !ok {
+			return nil, fmt.Errorf("decryption failed, the password may be wrong")
+		}
+		der = plain
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(der)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("only ECDSA keys are supported")
+	}
+	return ecKey, nil
+}
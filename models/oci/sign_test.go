@@ -0,0 +1,122 @@
+package oci
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func newTestCertificate(t *testing.T, key *ecdsa.PrivateKey, email string) ([]byte, *x509.CertPool) {
+	caKey, _ := newTestKey(t)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+	leaf := &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		NotBefore:      time.Now().Add(-time.Minute),
+		NotAfter:       time.Now().Add(10 * time.Minute),
+		EmailAddresses: []string{email},
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidIssuerV1, Value: []byte("https://issuer.example.com")},
+		},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}), roots
+}
+
+func TestSignAndVerifyArtifact(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "design.yaml"), []byte("name: test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key, keyPEM := newTestKey(t)
+	signer, err := NewSigner(keyPEM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _ := newTestKey(t)
+	certPEM, roots := newTestCertificate(t, key, "dev@example.com")
+	keylessSigner, err := NewKeylessSigner(keyPEM, nil, certPEM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	push := func(tag string, signer *Signer) {
+		if _, err := PushArtifact(ctx, src, host+"/meshery/design:"+tag, ArtifactKindDesign, ArtifactOptions{Name: tag}, RegistryOptions{PlainHTTP: true, Signer: signer}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	push("unsigned", nil)
+	push("keyed", signer)
+	push("keyless", keylessSigner)
+
+	tests := []struct {
+		tag     string
+		policy  VerificationPolicy
+		wantErr bool
+	}{
+		{"unsigned", VerificationPolicy{Mode: VerificationOptional}, false},
+		{"unsigned", VerificationPolicy{Mode: VerificationRequired, PublicKeys: []crypto.PublicKey{&key.PublicKey}}, true},
+		{"keyed", VerificationPolicy{Mode: VerificationRequired, PublicKeys: []crypto.PublicKey{&key.PublicKey}}, false},
+		{"keyed", VerificationPolicy{Mode: VerificationOptional, PublicKeys: []crypto.PublicKey{&otherKey.PublicKey}}, true},
+		{"keyless", VerificationPolicy{Mode: VerificationRequired, Roots: roots, TrustedIdentities: []TrustedIdentity{{Issuer: "https://issuer.example.com", Subject: "dev@example.com"}}}, false},
+		{"keyless", VerificationPolicy{Mode: VerificationRequired, Roots: roots, TrustedIdentities: []TrustedIdentity{{Subject: "someone@example.com"}}}, true},
+	}
+	for _, tt := range tests {
+		_, _, err := PullArtifact(ctx, host+"/meshery/design:"+tt.tag, t.TempDir(), RegistryOptions{PlainHTTP: true, Verification: tt.policy})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s with policy %+v: got error %v, want error %v", tt.tag, tt.policy.Mode, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils/cas"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	oras "oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// DefaultTransferRetries is the number of times a failed chunk upload or an interrupted download is resumed,
+// unless configured otherwise.
+const DefaultTransferRetries = 3
+
+// TransferStatus is the status of the transfer of a blob or manifest.
+type TransferStatus string
+
+const (
+	TransferStarted    TransferStatus = "started"
+	TransferInProgress TransferStatus = "in-progress"
+	TransferCompleted  TransferStatus = "completed"
+	// TransferSkipped content exists at the destination already
+	TransferSkipped TransferStatus = "skipped"
+)
+
+// Progress reports the transfer of a blob or manifest, e.g. a layer of an artifact.
+type Progress struct {
+	Descriptor  ocispec.Descriptor
+	Status      TransferStatus
+	Transferred int64
+	Total       int64
+}
+
+// ProgressFunc is called as the content of artifacts is transferred.
+// Blobs are transferred concurrently, so it can be called concurrently for different descriptors.
+type ProgressFunc func(Progress)
+
+// copyOptions returns the options of copying artifacts to or from a registry, reporting the progress to the ProgressFunc of opts.
+func (opts RegistryOptions) copyOptions() oras.CopyOptions {
+	copyOpts := oras.DefaultCopyOptions
+	opts.setCopyGraphOptions(&copyOpts.CopyGraphOptions)
+	return copyOpts
+}
+
+func (opts RegistryOptions) copyGraphOptions() oras.CopyGraphOptions {
+	copyOpts := oras.DefaultCopyGraphOptions
+	opts.setCopyGraphOptions(&copyOpts)
+	return copyOpts
+}
+
+func (opts RegistryOptions) setCopyGraphOptions(copyOpts *oras.CopyGraphOptions) {
+	report := opts.Progress
+	if report == nil {
+		return
+	}
+	copyOpts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		report(Progress{Descriptor: desc, Status: TransferStarted, Total: desc.Size})
+		return nil
+	}
+	copyOpts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		report(Progress{Descriptor: desc, Status: TransferCompleted, Transferred: desc.Size, Total: desc.Size})
+		return nil
+	}
+	copyOpts.OnCopySkipped = func(ctx context.Context, desc ocispec.Descriptor) error {
+		report(Progress{Descriptor: desc, Status: TransferSkipped, Transferred: desc.Size, Total: desc.Size})
+		return nil
+	}
+}
+
+// progressTarget reports the bytes pushed to the target.
+type progressTarget struct {
+	oras.Target
+	report ProgressFunc
+}
+
+// withProgress returns the target reporting the bytes pushed to it to the ProgressFunc of opts, if set.
+func (opts RegistryOptions) withProgress(target oras.Target) oras.Target {
+	if opts.Progress == nil {
+		return target
+	}
+	return &progressTarget{Target: target, report: opts.Progress}
+}
+
+func (t *progressTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	return t.Target.Push(ctx, expected, &progressReader{r: r, desc: expected, report: t.report})
+}
+
+type progressReader struct {
+	r           io.Reader
+	desc        ocispec.Descriptor
+	report      ProgressFunc
+	transferred int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.transferred += int64(n)
+		r.report(Progress{Descriptor: r.desc, Status: TransferInProgress, Transferred: r.transferred, Total: r.desc.Size})
+	}
+	return n, err
+}
+
+// remoteTarget is a repository uploading large blobs in chunks and resuming interrupted transfers.
+type remoteTarget struct {
+	*remote.Repository
+	chunkSize int64
+	retries   int
+	store     *cas.Store
+}
+
+func newRemoteTarget(repo *remote.Repository, opts RegistryOptions) *remoteTarget {
+	retries := opts.Retries
+	if retries == 0 {
+		retries = DefaultTransferRetries
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	return &remoteTarget{Repository: repo, chunkSize: opts.ChunkSize, retries: retries, store: opts.Store}
+}
+
+// Push uploads blobs larger than the chunk size in chunks, other blobs and manifests are pushed by the repository.
+// A chunk which fails to upload is resumed from the offset acknowledged by the registry.
+func (t *remoteTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if t.chunkSize <= 0 || expected.Size <= t.chunkSize || isManifest(expected) {
+		return t.Repository.Push(ctx, expected, r)
+	}
+	ctx = auth.AppendRepositoryScope(ctx, t.Reference, auth.ActionPull, auth.ActionPush)
+	start, err := url.Parse(fmt.Sprintf("%s://%s/v2/%s/blobs/uploads/", t.scheme(), t.Reference.Host(), t.Reference.Repository))
+	if err != nil {
+		return err
+	}
+	resp, err := t.do(ctx, http.MethodPost, start, nil, nil)
+	if err != nil {
+		return err
+	}
+	location, err := uploadLocation(resp)
+	if err != nil {
+		return err
+	}
+
+	chunk := make([]byte, t.chunkSize)
+	var offset int64
+	for offset < expected.Size {
+		n, err := io.ReadFull(r, chunk[:min(t.chunkSize, expected.Size-offset)])
+		if err != nil {
+			return err
+		}
+		if location, err = t.uploadChunk(ctx, location, chunk[:n], offset); err != nil {
+			return err
+		}
+		offset += int64(n)
+	}
+
+	query := location.Query()
+	query.Set("digest", expected.Digest.String())
+	location.RawQuery = query.Encode()
+	resp, err = t.do(ctx, http.MethodPut, location, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("completing the upload of %s: unexpected status %s", expected.Digest, resp.Status)
+	}
+	return nil
+}
+
+// uploadChunk uploads the chunk starting at offset, returning the location the next chunk is uploaded to.
+func (t *remoteTarget) uploadChunk(ctx context.Context, location *url.URL, chunk []byte, offset int64) (*url.URL, error) {
+	var sent int64
+	var err error
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if attempt > 0 {
+			// resume from the offset acknowledged by the registry, or resend the chunk if the registry can't tell
+			if received, current, statusErr := t.uploadStatus(ctx, location); statusErr == nil {
+				if received < offset || received > offset+int64(len(chunk)) {
+					return nil, fmt.Errorf("the registry received %d bytes, expected between %d and %d", received, offset, offset+int64(len(chunk)))
+				}
+				sent = received - offset
+				location = current
+			}
+			if sent == int64(len(chunk)) {
+				return location, nil
+			}
+		}
+		header := http.Header{
+			"Content-Type":  []string{"application/octet-stream"},
+			"Content-Range": []string{fmt.Sprintf("%d-%d", offset+sent, offset+int64(len(chunk))-1)},
+		}
+		var resp *http.Response
+		resp, err = t.do(ctx, http.MethodPatch, location, chunk[sent:], header)
+		if err != nil {
+			continue
+		}
+		var next *url.URL
+		if next, err = uploadLocation(resp); err == nil {
+			return next, nil
+		}
+	}
+	return nil, err
+}
+
+// uploadStatus returns the number of bytes of the upload received by the registry, and the location to upload to.
+func (t *remoteTarget) uploadStatus(ctx context.Context, location *url.URL) (int64, *url.URL, error) {
+	resp, err := t.do(ctx, http.MethodGet, location, nil, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	received, err := receivedBytes(resp.Header.Get("Range"))
+	if err != nil {
+		resp.Body.Close()
+		return 0, nil, err
+	}
+	current, err := uploadLocation(resp)
+	return received, current, err
+}
+
+// Fetch reads the blobs from the store, if set, fetching the missing ones from the repository.
+func (t *remoteTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if t.store == nil || isManifest(target) || target.Digest.Algorithm() != digest.SHA256 {
+		return t.fetch(ctx, target)
+	}
+	ref := fmt.Sprintf("oci://%s/%s@%s", t.Reference.Host(), t.Reference.Repository, target.Digest)
+	path, _, err := t.store.FetchWith(ctx, ref, cas.Digest(target.Digest.String()), func(ctx context.Context, _ string) (io.ReadCloser, error) {
+		return t.fetch(ctx, target)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// fetch resumes downloads of blobs interrupted before their end, using range requests.
+func (t *remoteTarget) fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if t.retries == 0 || isManifest(target) {
+		return t.Repository.Fetch(ctx, target)
+	}
+	ctx = auth.AppendRepositoryScope(ctx, t.Reference, auth.ActionPull)
+	u, err := url.Parse(fmt.Sprintf("%s://%s/v2/%s/blobs/%s", t.scheme(), t.Reference.Host(), t.Reference.Repository, target.Digest))
+	if err != nil {
+		return nil, err
+	}
+	r := &resumableReader{ctx: ctx, target: t, url: u, retries: t.retries, size: target.Size}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type resumableReader struct {
+	ctx     context.Context
+	target  *remoteTarget
+	url     *url.URL
+	body    io.ReadCloser
+	offset  int64
+	size    int64
+	retries int
+}
+
+// open requests the blob from the offset read so far.
+func (r *resumableReader) open() error {
+	var header http.Header
+	if r.offset > 0 {
+		header = http.Header{"Range": []string{fmt.Sprintf("bytes=%d-", r.offset)}}
+	}
+	resp, err := r.target.do(r.ctx, http.MethodGet, r.url, nil, header)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && r.offset > 0:
+	case resp.StatusCode == http.StatusOK:
+		// the range was ignored, the bytes read already are skipped
+		if _, err := io.CopyN(io.Discard, resp.Body, r.offset); err != nil {
+			resp.Body.Close()
+			return err
+		}
+	default:
+		resp.Body.Close()
+		return fmt.Errorf("fetching %s: unexpected status %s", r.url, resp.Status)
+	}
+	r.body = resp.Body
+	return nil
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if err == nil || err == io.EOF || r.offset >= r.size || r.retries == 0 || r.ctx.Err() != nil {
+		return n, err
+	}
+	// the download was interrupted, it is resumed from the offset read so far
+	r.retries--
+	r.body.Close()
+	if openErr := r.open(); openErr != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+func (r *resumableReader) Close() error {
+	return r.body.Close()
+}
+
+func (t *remoteTarget) scheme() string {
+	if t.PlainHTTP {
+		return "http"
+	}
+	return "https"
+}
+
+func (t *remoteTarget) do(ctx context.Context, method string, u *url.URL, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	client := t.Client
+	if client == nil {
+		client = auth.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// uploadLocation returns the location of the upload session of the response, resolved against the request URL.
+// Registries answer with 202 Accepted or 204 No Content, depending on the request and the registry.
+func uploadLocation(resp *http.Response) (*url.URL, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		return nil, fmt.Errorf("%s %s: unexpected status %s", resp.Request.Method, resp.Request.URL, resp.Status)
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// receivedBytes parses the Range header of upload responses, of the form 0-<offset of the last byte received>.
+// Registries report 0-0 before any byte is received.
+func receivedBytes(value string) (int64, error) {
+	_, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, fmt.Errorf("invalid range %q", value)
+	}
+	last, err := strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid range %q", value)
+	}
+	if last == 0 {
+		return 0, nil
+	}
+	return last + 1, nil
+}
+
+// mediaTypeArtifactManifest is the media type of the artifact manifests of the release candidates of image-spec v1.1,
+// which oras and some registries still produce. It isn't defined by the image-spec version in use.
+const mediaTypeArtifactManifest = "application/vnd.oci.artifact.manifest.v1+json"
+
+func isManifest(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex, mediaTypeArtifactManifest,
+		"application/vnd.docker.distribution.manifest.v2+json", "application/vnd.docker.distribution.manifest.list.v2+json":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package oci
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// flakyRegistry fails the second chunk upload and interrupts the first download of a blob halfway.
+type flakyRegistry struct {
+	handler     http.Handler
+	mu          sync.Mutex
+	patches     int
+	interrupted bool
+}
+
+func (f *flakyRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	if req.Method == http.MethodPatch {
+		f.patches++
+		if f.patches == 2 {
+			f.mu.Unlock()
+			http.Error(w, "upload interrupted", http.StatusBadRequest)
+			return
+		}
+	}
+	interrupt := req.Method == http.MethodGet && strings.Contains(req.URL.Path, "/blobs/sha256:") && !f.interrupted
+	if interrupt {
+		f.interrupted = true
+	}
+	f.mu.Unlock()
+	if !interrupt {
+		f.handler.ServeHTTP(w, req)
+		return
+	}
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	body := rec.Body.Bytes()
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(body[:len(body)/2])
+	panic(http.ErrAbortHandler)
+}
+
+func TestResumableTransfers(t *testing.T) {
+	ctx := context.Background()
+	src := t.TempDir()
+	data := make([]byte, 64*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "model.bin"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	flaky := &flakyRegistry{handler: registry.New(registry.Logger(log.New(io.Discard, "", 0)))}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+	reference := strings.TrimPrefix(server.URL, "http://") + "/meshery/model:v1"
+
+	var mu sync.Mutex
+	statuses := map[TransferStatus]int{}
+	var transferred int64
+	opts := RegistryOptions{
+		PlainHTTP: true,
+		ChunkSize: 16 * 1024,
+		Progress: func(p Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			statuses[p.Status]++
+			if p.Descriptor.MediaType == ContentLayerMediaType && p.Status == TransferInProgress {
+				transferred = p.Transferred
+			}
+		},
+	}
+	desc, err := PushArtifact(ctx, src, reference, ArtifactKindModel, ArtifactOptions{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flaky.patches < 5 {
+		t.Errorf("expected the layer to be uploaded in at least 5 chunks, got %d uploads", flaky.patches)
+	}
+	if statuses[TransferStarted] == 0 || statuses[TransferCompleted] != statuses[TransferStarted] || transferred == 0 {
+		t.Errorf("unexpected progress %v, %d bytes of the layer transferred", statuses, transferred)
+	}
+
+	dest := t.TempDir()
+	_, pulled, err := PullArtifact(ctx, reference, dest, RegistryOptions{PlainHTTP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flaky.interrupted || pulled.Digest != desc.Digest {
+		t.Fatalf("unexpected pull of %s", pulled.Digest)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "model.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Error("the pulled content differs from the pushed content")
+	}
+
+	if _, _, err := PullArtifact(ctx, reference, t.TempDir(), RegistryOptions{PlainHTTP: true, Retries: -1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsManifest(t *testing.T) {
+	for mediaType, want := range map[string]bool{
+		ocispec.MediaTypeImageManifest:                         true,
+		ocispec.MediaTypeImageIndex:                            true,
+		"application/vnd.oci.artifact.manifest.v1+json":        true,
+		"application/vnd.docker.distribution.manifest.v2+json": true,
+		ContentLayerMediaType:                                  false,
+		ocispec.MediaTypeImageConfig:                           false,
+	} {
+		if got := isManifest(ocispec.Descriptor{MediaType: mediaType}); got != want {
+			t.Errorf("isManifest(%s) = %v, want %v", mediaType, got, want)
+		}
+	}
+}
@@ -57,7 +57,7 @@ func UnCompressOCIArtifact(source, destination string) error {
 		return ErrCompressingLayer(err)
 	}
 
-	if err = tar.Untar(blob, destination, tar.WithMaxUntarSize(-1), tar.WithSkipSymlinks()); err != nil {
+	if err = tar.Untar(blob, destination, tar.WithMaxUntarSize(DefaultMaxUnpackSize), tar.WithSkipSymlinks()); err != nil {
 		return ErrUnTaringLayer(err)
 	}
 
@@ -0,0 +1,386 @@
+// Package cas stores downloaded artifacts, e.g. charts, CRDs or OCI layers, by the SHA-256 hash of their content,
+// so that repeated runs and air-gapped mirrors reuse verified content instead of downloading it again.
+//
+// The blobs are stored under <root>/blobs/sha256/<hash>, and the URLs they were fetched from under <root>/refs,
+// so that a store copied to a mirror serves the same URLs without network access.
+package cas
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshkit/utils"
+)
+
+const algorithm = "sha256"
+
+// Digest identifies content by its hash, e.g. sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824.
+type Digest string
+
+// ParseDigest parses the digest, the algorithm prefix being optional.
+func ParseDigest(s string) (Digest, error) {
+	hash := strings.TrimPrefix(strings.ToLower(s), algorithm+":")
+	if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+		return "", ErrInvalidDigest(s)
+	}
+	return Digest(algorithm + ":" + hash), nil
+}
+
+// Hash returns the hexadecimal hash of the digest.
+func (d Digest) Hash() string {
+	return strings.TrimPrefix(string(d), algorithm+":")
+}
+
+// FetchFunc fetches the content at the URL.
+type FetchFunc func(ctx context.Context, url string) (io.ReadCloser, error)
+
+// Blob is a content of the store.
+type Blob struct {
+	Digest Digest
+	Size   int64
+	// LastUsed is the last time the blob was stored or read
+	LastUsed time.Time
+}
+
+// GCPolicy selects the blobs to remove when the store is collected.
+type GCPolicy interface {
+	Collect(blobs []Blob, now time.Time) []Digest
+}
+
+// Options configure a Store.
+type Options struct {
+	// GC is applied by Store.GC, nothing is removed if nil
+	GC GCPolicy
+	// Fetch fetches the content missing from the store, HTTPFetch by default. A mirror sets a function failing
+	// every call, so that only the content of the store is served.
+	Fetch FetchFunc
+	// RefTTL is how long the content looked up by URL is served before the URL is fetched again, the content
+	// at a URL possibly changing, e.g. a chart released again with the same version. Refs never expire if 0.
+	RefTTL time.Duration
+}
+
+// Store is a content addressable store rooted in a directory. It is safe for concurrent use.
+type Store struct {
+	root   string
+	gc     GCPolicy
+	fetch  FetchFunc
+	refTTL time.Duration
+	// mu is held for reading by the lookups and writes of blobs, and for writing by GC,
+	// so that a blob is not removed between its lookup and its use being recorded
+	mu sync.RWMutex
+}
+
+// DefaultRoot is the root of the store shared by the MeshKit tools, ~/.meshery/cas.
+func DefaultRoot() string {
+	return filepath.Join(utils.GetHome(), ".meshery", "cas")
+}
+
+// New returns the store rooted in the directory, creating it if needed.
+func New(root string, opts Options) (*Store, error) {
+	for _, dir := range []string{filepath.Join(root, "blobs", algorithm), filepath.Join(root, "refs")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, ErrStoreContent(err, root)
+		}
+	}
+	if opts.Fetch == nil {
+		opts.Fetch = HTTPFetch(http.DefaultClient)
+	}
+	return &Store{root: root, gc: opts.GC, fetch: opts.Fetch, refTTL: opts.RefTTL}, nil
+}
+
+// Root returns the root directory of the store.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Path returns the path of the blob of the digest, whether it is stored or not.
+func (s *Store) Path(d Digest) string {
+	return filepath.Join(s.root, "blobs", algorithm, d.Hash())
+}
+
+// Has reports whether the content of the digest is stored.
+func (s *Store) Has(d Digest) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.has(d)
+}
+
+func (s *Store) has(d Digest) bool {
+	_, err := os.Stat(s.Path(d))
+	return err == nil
+}
+
+// Put stores the content and returns its digest. If expected is not empty, content not matching it is rejected.
+func (s *Store) Put(r io.Reader, expected Digest) (Digest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.put(r, expected)
+}
+
+func (s *Store) put(r io.Reader, expected Digest) (Digest, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "blobs"), ".put-")
+	if err != nil {
+		return "", ErrStoreContent(err, s.root)
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", ErrStoreContent(err, s.root)
+	}
+	d := Digest(algorithm + ":" + hex.EncodeToString(h.Sum(nil)))
+	if expected != "" && expected != d {
+		return "", ErrDigestMismatch(expected, d)
+	}
+	// the rename is atomic, concurrent writers of the same content write the same blob
+	if err := os.Rename(tmp.Name(), s.Path(d)); err != nil {
+		return "", ErrStoreContent(err, s.root)
+	}
+	return d, nil
+}
+
+// Open returns the content of the digest, and marks it as used.
+func (s *Store) Open(d Digest) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, err := os.Open(s.Path(d))
+	if err != nil {
+		return nil, ErrStoreContent(err, s.root)
+	}
+	s.touch(d)
+	return f, nil
+}
+
+// Verify hashes the stored content again, and removes it if it does not match its digest.
+func (s *Store) Verify(d Digest) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.verify(d)
+}
+
+func (s *Store) verify(d Digest) error {
+	f, err := os.Open(s.Path(d))
+	if err != nil {
+		return ErrStoreContent(err, s.root)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ErrStoreContent(err, s.root)
+	}
+	if actual := Digest(algorithm + ":" + hex.EncodeToString(h.Sum(nil))); actual != d {
+		_ = os.Remove(s.Path(d))
+		return ErrDigestMismatch(d, actual)
+	}
+	return nil
+}
+
+// Fetch returns the path of the content at the URL, fetching it only if it is not stored yet. If expected is empty,
+// the content is looked up by URL, so it is fetched once per store, or once per Options.RefTTL; if set, it is looked
+// up by digest, and fetched content not matching it is rejected. The stored content is hashed again before it is
+// served, and fetched again if it was corrupted. The returned path must not be modified.
+func (s *Store) Fetch(ctx context.Context, url string, expected Digest) (string, Digest, error) {
+	return s.FetchWith(ctx, url, expected, s.fetch)
+}
+
+// FetchWith is Fetch, the content missing from the store being fetched with fetch rather than with Options.Fetch,
+// e.g. to authenticate the requests. The url only identifies the content if expected is empty.
+func (s *Store) FetchWith(ctx context.Context, url string, expected Digest, fetch FetchFunc) (string, Digest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d := expected
+	if d == "" {
+		d = s.ref(url)
+	}
+	// the corrupted content is removed by verify
+	if d != "" && s.has(d) && s.verify(d) == nil {
+		s.touch(d)
+		return s.Path(d), d, nil
+	}
+
+	rc, err := fetch(ctx, url)
+	if err != nil {
+		return "", "", ErrFetchContent(err, url)
+	}
+	defer rc.Close()
+	if d, err = s.put(rc, expected); err != nil {
+		return "", "", err
+	}
+	if err := s.setRef(url, d); err != nil {
+		return "", "", err
+	}
+	return s.Path(d), d, nil
+}
+
+// Blobs lists the stored blobs.
+func (s *Store) Blobs() ([]Blob, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, "blobs", algorithm))
+	if err != nil {
+		return nil, ErrStoreContent(err, s.root)
+	}
+	blobs := make([]Blob, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		blobs = append(blobs, Blob{Digest: Digest(algorithm + ":" + e.Name()), Size: info.Size(), LastUsed: info.ModTime()})
+	}
+	return blobs, nil
+}
+
+// GC removes the blobs selected by the policy, and the refs to them. It returns the number of bytes freed.
+func (s *Store) GC() (int64, error) {
+	if s.gc == nil {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blobs, err := s.Blobs()
+	if err != nil {
+		return 0, err
+	}
+	sizes := make(map[Digest]int64, len(blobs))
+	for _, b := range blobs {
+		sizes[b.Digest] = b.Size
+	}
+	removed := make(map[Digest]bool)
+	var freed int64
+	for _, d := range s.gc.Collect(blobs, time.Now()) {
+		if err := os.Remove(s.Path(d)); err != nil && !os.IsNotExist(err) {
+			return freed, ErrStoreContent(err, s.root)
+		}
+		removed[d] = true
+		freed += sizes[d]
+	}
+
+	refs, err := os.ReadDir(filepath.Join(s.root, "refs"))
+	if err != nil {
+		return freed, ErrStoreContent(err, s.root)
+	}
+	for _, ref := range refs {
+		path := filepath.Join(s.root, "refs", ref.Name())
+		if data, err := os.ReadFile(path); err == nil && removed[Digest(strings.TrimSpace(string(data)))] {
+			_ = os.Remove(path)
+		}
+	}
+	return freed, nil
+}
+
+// touch marks the blob as used, for the GC policies
+func (s *Store) touch(d Digest) {
+	now := time.Now()
+	_ = os.Chtimes(s.Path(d), now, now)
+}
+
+func (s *Store) refPath(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return filepath.Join(s.root, "refs", hex.EncodeToString(h[:]))
+}
+
+// ref returns the digest of the content fetched from the URL, empty if it was not fetched or if the ref expired.
+func (s *Store) ref(url string) Digest {
+	path := s.refPath(url)
+	if s.refTTL > 0 {
+		if info, err := os.Stat(path); err != nil || time.Since(info.ModTime()) > s.refTTL {
+			return ""
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return Digest(strings.TrimSpace(string(data)))
+}
+
+func (s *Store) setRef(url string, d Digest) error {
+	if err := os.WriteFile(s.refPath(url), []byte(d), 0644); err != nil {
+		return ErrStoreContent(err, s.root)
+	}
+	return nil
+}
+
+// HTTPFetch fetches the content with a GET request.
+func HTTPFetch(client *http.Client) FetchFunc {
+	return func(ctx context.Context, url string) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return resp.Body, nil
+	}
+}
+
+// MaxAge removes the blobs unused for longer than the duration.
+type MaxAge time.Duration
+
+func (m MaxAge) Collect(blobs []Blob, now time.Time) []Digest {
+	var digests []Digest
+	for _, b := range blobs {
+		if now.Sub(b.LastUsed) > time.Duration(m) {
+			digests = append(digests, b.Digest)
+		}
+	}
+	return digests
+}
+
+// MaxSize removes the least recently used blobs until the store holds at most the number of bytes.
+type MaxSize int64
+
+func (m MaxSize) Collect(blobs []Blob, now time.Time) []Digest {
+	sorted := append([]Blob{}, blobs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].LastUsed.Before(sorted[j].LastUsed)
+	})
+	var total int64
+	for _, b := range sorted {
+		total += b.Size
+	}
+	var digests []Digest
+	for _, b := range sorted {
+		if total <= int64(m) {
+			break
+		}
+		digests = append(digests, b.Digest)
+		total -= b.Size
+	}
+	return digests
+}
+
+// Policies combines the policies, a blob is removed if any of them selects it.
+type Policies []GCPolicy
+
+func (p Policies) Collect(blobs []Blob, now time.Time) []Digest {
+	seen := make(map[Digest]bool)
+	var digests []Digest
+	for _, policy := range p {
+		for _, d := range policy.Collect(blobs, now) {
+			if !seen[d] {
+				seen[d] = true
+				digests = append(digests, d)
+			}
+		}
+	}
+	return digests
+}
@@ -0,0 +1,119 @@
+package cas
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreFetch(t *testing.T) {
+	fetches := 0
+	fetch := func(ctx context.Context, url string) (io.ReadCloser, error) {
+		fetches++
+		return io.NopCloser(strings.NewReader("chart of " + url)), nil
+	}
+	root := t.TempDir()
+	s, err := New(root, Options{Fetch: fetch})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, d, err := s.Fetch(context.Background(), "https://charts.example.com/web-0.1.0.tgz", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "chart of https://charts.example.com/web-0.1.0.tgz" {
+		t.Errorf("content = %q", data)
+	}
+
+	// a mirror of the store serves the URL without fetching it
+	mirror, err := New(root, Options{Fetch: func(ctx context.Context, url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("offline")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _, err := mirror.Fetch(context.Background(), "https://charts.example.com/web-0.1.0.tgz", ""); err != nil || again != path {
+		t.Errorf("expected the stored content to be reused, got %s, %v", again, err)
+	}
+	if _, _, err := mirror.Fetch(context.Background(), "https://charts.example.com/db-0.1.0.tgz", ""); err == nil {
+		t.Error("expected the missing content to fail on the mirror")
+	}
+	if _, _, err := s.Fetch(context.Background(), "https://charts.example.com/web-0.1.0.tgz", d); err != nil || fetches != 1 {
+		t.Errorf("expected the content to be looked up by digest, got %d fetches, %v", fetches, err)
+	}
+
+	other, _ := ParseDigest(strings.Repeat("0", 64))
+	if _, _, err := s.Fetch(context.Background(), "https://charts.example.com/db-0.1.0.tgz", other); err == nil {
+		t.Error("expected the content not matching the digest to be rejected")
+	}
+	// the tampered content is fetched again
+	if err := os.WriteFile(path, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if again, _, err := s.Fetch(context.Background(), "https://charts.example.com/web-0.1.0.tgz", ""); err != nil || fetches != 3 {
+		t.Errorf("expected the tampered content to be fetched again, got %d fetches, %v", fetches, err)
+	} else if data, _ := os.ReadFile(again); string(data) != "chart of https://charts.example.com/web-0.1.0.tgz" {
+		t.Errorf("content = %q", data)
+	}
+	if err := os.WriteFile(path, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Verify(d); err == nil || s.Has(d) {
+		t.Error("expected the tampered content to be removed")
+	}
+}
+
+func TestStoreGC(t *testing.T) {
+	s, err := New(t.TempDir(), Options{GC: Policies{MaxAge(time.Hour), MaxSize(5)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _ := s.Put(strings.NewReader("old"), "")
+	recent, _ := s.Put(strings.NewReader("recent"), "")
+	latest, _ := s.Put(strings.NewReader("new"), "")
+	now := time.Now()
+	_ = os.Chtimes(s.Path(old), now.Add(-2*time.Hour), now.Add(-2*time.Hour))
+	_ = os.Chtimes(s.Path(recent), now.Add(-time.Minute), now.Add(-time.Minute))
+
+	freed, err := s.GC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Has(old) || s.Has(recent) || !s.Has(latest) || freed != 9 {
+		t.Errorf("expected the old and the least recently used blobs to be removed, freed %d", freed)
+	}
+}
+
+func TestStoreRefTTL(t *testing.T) {
+	fetches := 0
+	fetch := func(ctx context.Context, url string) (io.ReadCloser, error) {
+		fetches++
+		return io.NopCloser(strings.NewReader(fmt.Sprintf("release %d of %s", fetches, url))), nil
+	}
+	s, err := New(t.TempDir(), Options{Fetch: fetch, RefTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "https://charts.example.com/web-0.1.0.tgz"
+	_, first, err := s.Fetch(context.Background(), url, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, d, err := s.Fetch(context.Background(), url, ""); err != nil || d != first || fetches != 1 {
+		t.Errorf("expected the ref to be served until it expires, got %d fetches, %v", fetches, err)
+	}
+
+	past := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(s.refPath(url), past, past)
+	_, d, err := s.Fetch(context.Background(), url, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d == first || fetches != 2 {
+		t.Errorf("expected the URL to be fetched again once the ref expired, got %d fetches", fetches)
+	}
+}
@@ -0,0 +1,30 @@
+package cas
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrStoreContentCode   = "meshkit-11324"
+	ErrDigestMismatchCode = "meshkit-11325"
+	ErrFetchContentCode   = "meshkit-11326"
+	ErrInvalidDigestCode  = "meshkit-11327"
+)
+
+func ErrStoreContent(err error, root string) error {
+	return errors.New(ErrStoreContentCode, errors.Alert, []string{fmt.Sprintf("Could not access the content store at %s", root)}, []string{err.Error()}, []string{"The root directory of the store is not writable", "The disk is full"}, []string{"Make sure the root directory of the store is writable and has free space"})
+}
+
+func ErrDigestMismatch(expected, actual Digest) error {
+	return errors.New(ErrDigestMismatchCode, errors.Alert, []string{"The content does not match its checksum"}, []string{fmt.Sprintf("expected %s, got %s", expected, actual)}, []string{"The content was corrupted or tampered with during the download", "The expected checksum is outdated"}, []string{"Retry the download, and make sure the expected checksum is the one published with the content"})
+}
+
+func ErrFetchContent(err error, url string) error {
+	return errors.New(ErrFetchContentCode, errors.Alert, []string{fmt.Sprintf("Could not fetch %s", url)}, []string{err.Error()}, []string{"The URL is unreachable", "The content is not in the store of an air-gapped mirror"}, []string{"Make sure the URL is reachable, or add the content to the store of the mirror"})
+}
+
+func ErrInvalidDigest(digest string) error {
+	return errors.New(ErrInvalidDigestCode, errors.Alert, []string{fmt.Sprintf("Invalid digest %q", digest)}, []string{"the digest is not of the form sha256:<64 hexadecimal characters>"}, []string{"The checksum was mistyped or uses another algorithm"}, []string{"Use the SHA-256 checksum of the content"})
+}
@@ -0,0 +1,170 @@
+package component
+
+import (
+	"encoding/json"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultsMetadataKey is the key of the default configuration of a component in its metadata,
+	// built from the defaults declared in its schema.
+	DefaultsMetadataKey = "defaults"
+	// PresetsMetadataKey is the key of the presets of a component in its metadata,
+	// the configurations of the custom resources created by the chart the component is generated from.
+	PresetsMetadataKey = "presets"
+)
+
+// Preset is a configuration of a component, as found in the manifests it is generated from.
+type Preset struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+// SetDefaults stores the defaults declared in the schema of the component in its metadata, if it declares any.
+func SetDefaults(comp *v1beta1.ComponentDefinition) error {
+	defaults, err := DefaultsFromSchema(comp.Component.Schema)
+	if err != nil {
+		return err
+	}
+	if len(defaults) == 0 {
+		return nil
+	}
+	if comp.Metadata == nil {
+		comp.Metadata = make(map[string]interface{})
+	}
+	comp.Metadata[DefaultsMetadataKey] = defaults
+	return nil
+}
+
+// SetDefaultsAndPresets stores the defaults and the presets of the component in its metadata, see SetDefaults and
+// SetPresets. The defaults are best effort: a component whose defaults can't be read from its schema is left without
+// them, the error being added to its schema warnings, see SchemaWarningsMetadataKey.
+func SetDefaultsAndPresets(comp *v1beta1.ComponentDefinition, resources []map[string]interface{}) {
+	if err := SetDefaults(comp); err != nil {
+		if comp.Metadata == nil {
+			comp.Metadata = make(map[string]interface{})
+		}
+		warnings, _ := comp.Metadata[SchemaWarningsMetadataKey].([]string)
+		comp.Metadata[SchemaWarningsMetadataKey] = append(warnings, err.Error())
+	}
+	SetPresets(comp, resources)
+}
+
+// DefaultsFromSchema returns the configuration made of the default values declared in the JSON schema.
+// Objects without defaults in any of their properties are left out, as are the items of arrays.
+func DefaultsFromSchema(schema string) (map[string]interface{}, error) {
+	if schema == "" {
+		return nil, nil
+	}
+	var s map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		return nil, ErrGetSchema(err)
+	}
+	defaults, _ := defaultOf(s).(map[string]interface{})
+	return defaults, nil
+}
+
+func defaultOf(schema map[string]interface{}) interface{} {
+	if value, ok := schema["default"]; ok {
+		return value
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	defaults := make(map[string]interface{})
+	for name, property := range properties {
+		p, ok := property.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value := defaultOf(p); value != nil {
+			defaults[name] = value
+		}
+	}
+	if len(defaults) == 0 {
+		return nil
+	}
+	return defaults
+}
+
+// SetPresets stores the configurations of the resources of the kind and API version of the component in its metadata.
+// The resources are typically the manifests rendered from a chart with its default values.
+func SetPresets(comp *v1beta1.ComponentDefinition, resources []map[string]interface{}) {
+	presets := make([]Preset, 0)
+	for _, resource := range resources {
+		apiVersion, _ := resource["apiVersion"].(string)
+		kind, _ := resource["kind"].(string)
+		if apiVersion != comp.Component.Version || kind != comp.Component.Kind {
+			continue
+		}
+		preset := Preset{Values: make(map[string]interface{})}
+		if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+			preset.Name, _ = metadata["name"].(string)
+			preset.Namespace, _ = metadata["namespace"].(string)
+		}
+		for k, v := range resource {
+			preset.Values[k] = v
+		}
+		for _, f := range fieldsToDelete {
+			delete(preset.Values, f)
+		}
+		presets = append(presets, preset)
+	}
+	if len(presets) == 0 {
+		return
+	}
+	if comp.Metadata == nil {
+		comp.Metadata = make(map[string]interface{})
+	}
+	comp.Metadata[PresetsMetadataKey] = presets
+}
+
+// DecodeResources decodes the objects of a multi document YAML, skipping the documents which are no objects.
+// The values are JSON compatible, so that they can be stored in the metadata of components.
+func DecodeResources(manifest string) []map[string]interface{} {
+	docs, err := kubernetes.SplitManifests([]byte(manifest))
+	if err != nil {
+		return nil
+	}
+	resources := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		var obj interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			continue
+		}
+		resource, ok := toJSONCompatible(obj).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if kind, _ := resource["kind"].(string); kind == "" {
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// toJSONCompatible converts the maps decoded by yaml.v2 to maps keyed by strings.
+func toJSONCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			if key, ok := k.(string); ok {
+				m[key] = toJSONCompatible(v)
+			}
+		}
+		return m
+	case []interface{}:
+		for i := range t {
+			t[i] = toJSONCompatible(t[i])
+		}
+		return t
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
+)
+
+func TestDefaultsFromSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"spec": {
+				"type": "object",
+				"properties": {
+					"replicas": {"type": "integer", "default": 1},
+					"url": {"type": "string"},
+					"tls": {
+						"type": "object",
+						"properties": {
+							"enabled": {"type": "boolean", "default": false},
+							"secretName": {"type": "string"}
+						}
+					},
+					"ports": {"type": "array", "items": {"type": "object", "properties": {"port": {"type": "integer", "default": 80}}}},
+					"mode": {"type": "object", "default": {"strict": true}}
+				}
+			}
+		}
+	}`
+	got, err := DefaultsFromSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": float64(1),
+			"tls":      map[string]interface{}{"enabled": false},
+			"mode":     map[string]interface{}{"strict": true},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got defaults %v, want %v", got, want)
+	}
+
+	got, err = DefaultsFromSchema(`{"type": "object", "properties": {"spec": {"type": "object"}}}`)
+	if err != nil || got != nil {
+		t.Errorf("expected no defaults, got %v, %v", got, err)
+	}
+	if _, err := DefaultsFromSchema("{"); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestSetPresets(t *testing.T) {
+	rendered := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: cert-manager.io/v1
+kind: ClusterIssuer
+metadata:
+  name: selfsigned
+spec:
+  selfSigned: {}
+---
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: webhook
+  namespace: cert-manager
+spec:
+  secretName: webhook-tls
+  dnsNames:
+  - webhook.cert-manager.svc
+status:
+  ready: true
+---
+# only a comment
+`
+	resources := DecodeResources(rendered)
+	if len(resources) != 3 {
+		t.Fatalf("expected 3 resources, got %d", len(resources))
+	}
+
+	comp := v1beta1.ComponentDefinition{Component: v1beta1.ComponentEntity{TypeMeta: v1beta1.TypeMeta{Kind: "Certificate", Version: "cert-manager.io/v1"}}}
+	SetPresets(&comp, resources)
+	presets, ok := comp.Metadata[PresetsMetadataKey].([]Preset)
+	if !ok || len(presets) != 1 {
+		t.Fatalf("expected a preset, got %v", comp.Metadata[PresetsMetadataKey])
+	}
+	want := Preset{
+		Name:      "webhook",
+		Namespace: "cert-manager",
+		Values: map[string]interface{}{
+			"spec": map[string]interface{}{"secretName": "webhook-tls", "dnsNames": []interface{}{"webhook.cert-manager.svc"}},
+		},
+	}
+	if !reflect.DeepEqual(presets[0], want) {
+		t.Errorf("got preset %+v, want %+v", presets[0], want)
+	}
+
+	other := v1beta1.ComponentDefinition{Component: v1beta1.ComponentEntity{TypeMeta: v1beta1.TypeMeta{Kind: "Issuer", Version: "cert-manager.io/v1"}}}
+	SetPresets(&other, resources)
+	if _, ok := other.Metadata[PresetsMetadataKey]; ok {
+		t.Error("expected no presets for a kind the chart doesn't create")
+	}
+}
+
+func TestSetDefaultsAndPresets(t *testing.T) {
+	comp := v1beta1.ComponentDefinition{}
+	comp.Component.Schema = "{"
+	SetDefaultsAndPresets(&comp, nil)
+	if warnings, _ := comp.Metadata[SchemaWarningsMetadataKey].([]string); len(warnings) != 1 {
+		t.Errorf("expected the invalid schema to be reported in the schema warnings, got %v", comp.Metadata)
+	}
+	if _, ok := comp.Metadata[DefaultsMetadataKey]; ok {
+		t.Error("expected no defaults for an invalid schema")
+	}
+}
@@ -1,6 +1,8 @@
 package component
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +15,10 @@ import (
 
 const ComponentMetaNameKey = "name"
 
+// SchemaWarningsMetadataKey is the key of the issues of the schema of a generated component in its metadata,
+// e.g. the references which can't be resolved and are kept as is.
+const SchemaWarningsMetadataKey = "schemaWarnings"
+
 // all paths should be a valid CUE expression
 type CuePathConfig struct {
 	NamePath       string
@@ -47,27 +53,96 @@ var DefaultPathConfig2 = CuePathConfig{
 var Configs = []CuePathConfig{DefaultPathConfig, DefaultPathConfig2}
 
 func Generate(crd string) (v1beta1.ComponentDefinition, error) {
-	component := v1beta1.ComponentDefinition{}
-	component.SchemaVersion = v1beta1.SchemaVersion
+	crdCue, err := utils.YamlToCue(crd)
+	if err != nil {
+		return newComponentDefinition(), err
+	}
+	comp, err := generateFromCue(crdCue, Configs)
+	if err == nil {
+		comp.SetProvenance(v1beta1.Provenance{CRDDigest: CRDDigest(crd)})
+	}
+	return comp, err
+}
 
-	component.Metadata = make(map[string]interface{})
+// CRDDigest returns the digest of the CRD recorded in the provenance of the components generated from it, sha256:<hex>.
+func CRDDigest(crd string) string {
+	sum := sha256.Sum256([]byte(crd))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// GenerateServedVersions generates a component for every version of the CRD which is marked as served.
+// CRDs which don't declare versions (or declare the schema under spec.validation) produce a single component, same as Generate.
+func GenerateServedVersions(crd string) ([]v1beta1.ComponentDefinition, error) {
 	crdCue, err := utils.YamlToCue(crd)
 	if err != nil {
-		return component, err
+		return nil, err
 	}
+	versions, err := utils.Lookup(crdCue, "spec.versions")
+	if err != nil {
+		comp, err := generateFromCue(crdCue, Configs)
+		if err != nil {
+			return nil, err
+		}
+		comp.SetProvenance(v1beta1.Provenance{CRDDigest: CRDDigest(crd)})
+		return []v1beta1.ComponentDefinition{comp}, nil
+	}
+	iter, err := versions.List()
+	if err != nil {
+		return nil, ErrCrdGenerate(err)
+	}
+
+	components := make([]v1beta1.ComponentDefinition, 0)
+	for index := 0; iter.Next(); index++ {
+		// A version which doesn't specify "served" is considered to be served.
+		served, err := iter.Value().LookupPath(cue.ParsePath("served")).Bool()
+		if err == nil && !served {
+			continue
+		}
+		versionCfg := DefaultPathConfig
+		versionCfg.VersionPath = fmt.Sprintf("spec.versions[%d].name", index)
+		versionCfg.SpecPath = fmt.Sprintf("spec.versions[%d].schema.openAPIV3Schema", index)
+
+		legacyCfg := DefaultPathConfig2
+		legacyCfg.VersionPath = versionCfg.VersionPath
+
+		comp, err := generateFromCue(crdCue, []CuePathConfig{versionCfg, legacyCfg})
+		if err != nil {
+			return nil, err
+		}
+		comp.SetProvenance(v1beta1.Provenance{CRDDigest: CRDDigest(crd)})
+		components = append(components, comp)
+	}
+	return components, nil
+}
+
+func newComponentDefinition() v1beta1.ComponentDefinition {
+	component := v1beta1.ComponentDefinition{}
+	component.SchemaVersion = v1beta1.SchemaVersion
+	component.Metadata = make(map[string]interface{})
+	return component
+}
+
+// generateFromCue uses the schema from the first of the configs which resolves, the version is always read from the first config.
+func generateFromCue(crdCue cue.Value, cfgs []CuePathConfig) (v1beta1.ComponentDefinition, error) {
+	component := newComponentDefinition()
 	var schema string
-	for _, cfg := range Configs {
-		schema, err = getSchema(crdCue, cfg)
+	var warnings []string
+	var err error
+	for _, cfg := range cfgs {
+		schema, warnings, err = getSchema(crdCue, cfg)
 		if err == nil {
 			break
 		}
 	}
 	component.Component.Schema = schema
+	if len(warnings) > 0 {
+		component.Metadata[SchemaWarningsMetadataKey] = warnings
+	}
 	name, err := extractCueValueFromPath(crdCue, DefaultPathConfig.NamePath)
 	if err != nil {
 		return component, err
 	}
-	version, err := extractCueValueFromPath(crdCue, DefaultPathConfig.VersionPath)
+	version, err := extractCueValueFromPath(crdCue, cfgs[0].VersionPath)
 	if err != nil {
 		return component, err
 	}
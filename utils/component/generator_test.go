@@ -1,6 +1,7 @@
 package component
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/layer5io/meshkit/models/meshmodel/core/v1beta1"
@@ -155,3 +156,91 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+var multiVersionCrd = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: backends.example.io
+spec:
+  group: example.io
+  names:
+    kind: Backend
+    plural: backends
+  scope: Cluster
+  versions:
+  - name: v1alpha1
+    served: false
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+  - name: v1beta1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              replicas:
+                type: integer
+`
+
+func TestGenerateServedVersions(t *testing.T) {
+	comps, err := GenerateServedVersions(multiVersionCrd)
+	if err != nil {
+		t.Fatalf("error generating components: %v", err)
+	}
+	want := []string{"example.io/v1beta1", "example.io/v1"}
+	if len(comps) != len(want) {
+		t.Fatalf("got %d components, want %d", len(comps), len(want))
+	}
+	for i, comp := range comps {
+		if comp.Component.Version != want[i] {
+			t.Errorf("got %v, want %v", comp.Component.Version, want[i])
+		}
+		if comp.Component.Kind != "Backend" {
+			t.Errorf("got %v, want %v", comp.Component.Kind, "Backend")
+		}
+		if comp.Component.Schema == "" {
+			t.Errorf("expected schema for version %v", want[i])
+		}
+	}
+	if !strings.Contains(comps[1].Component.Schema, "replicas") {
+		t.Errorf("schema of %v does not belong to the served version", want[1])
+	}
+}
+
+func TestGenerateKeepsUnresolvedRefs(t *testing.T) {
+	crd := strings.Replace(multiVersionCrd, `              replicas:
+                type: integer`, `              replicas:
+                type: integer
+              template:
+                $ref: "#/definitions/io.k8s.api.core.v1.PodTemplateSpec"`, 1)
+	comps, err := GenerateServedVersions(crd)
+	if err != nil {
+		t.Fatalf("expected an unresolved reference not to fail the generation: %v", err)
+	}
+	v1 := comps[len(comps)-1]
+	if !strings.Contains(v1.Component.Schema, "replicas") || !strings.Contains(v1.Component.Schema, "PodTemplateSpec") {
+		t.Errorf("expected the schema to be kept with the reference, got %s", v1.Component.Schema)
+	}
+	if warnings, _ := v1.Metadata[SchemaWarningsMetadataKey].([]string); len(warnings) != 1 {
+		t.Errorf("expected the unresolved reference to be reported, got %v", v1.Metadata)
+	}
+}
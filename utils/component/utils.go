@@ -13,42 +13,49 @@ import (
 // Remove the fields which is either not required by end user (like status) or is prefilled by system (like apiVersion, kind and metadata)
 var fieldsToDelete = [4]string{"apiVersion", "kind", "status", "metadata"}
 
-// extracts the JSONSCHEMA of the CRD and outputs the json encoded string of the schema
-func getSchema(parsedCrd cue.Value, pathConf CuePathConfig) (string, error) {
+// extracts the JSONSCHEMA of the CRD and outputs the json encoded string of the schema, along with the references
+// which can't be resolved, which are kept in the schema
+func getSchema(parsedCrd cue.Value, pathConf CuePathConfig) (string, []string, error) {
 	schema := map[string]interface{}{}
 	specCueVal, err := utils.Lookup(parsedCrd, pathConf.SpecPath)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	marshalledJson, err := specCueVal.MarshalJSON()
 	if err != nil {
-		return "", ErrGetSchema(err)
+		return "", nil, ErrGetSchema(err)
 	}
 	err = json.Unmarshal(marshalledJson, &schema)
 	if err != nil {
-		return "", ErrGetSchema(err)
+		return "", nil, ErrGetSchema(err)
 	}
 	resourceId, err := extractCueValueFromPath(parsedCrd, pathConf.IdentifierPath)
 	if err != nil {
-		return "", ErrGetSchema(err)
+		return "", nil, ErrGetSchema(err)
 	}
 
 	updatedProps, err := UpdateProperties(specCueVal, cue.ParsePath(pathConf.PropertiesPath), resourceId)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	schema = updatedProps
+	var warnings []string
+	schema, err = manifests.FlattenSchema(updatedProps, manifests.FlattenOptions{Warn: func(err error) {
+		warnings = append(warnings, err.Error())
+	}})
+	if err != nil {
+		return "", nil, ErrGetSchema(err)
+	}
 	DeleteFields(schema)
 
 	(schema)["title"] = manifests.FormatToReadableString(resourceId)
 	var output []byte
 	output, err = json.MarshalIndent(schema, "", " ")
 	if err != nil {
-		return "", ErrGetSchema(err)
+		return "", nil, ErrGetSchema(err)
 	}
-	return string(output), nil
+	return string(output), warnings, nil
 }
 
 func extractCueValueFromPath(crd cue.Value, pathConf string) (string, error) {
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
@@ -113,3 +114,146 @@ func Lookup(rootVal cue.Value, path string) (cue.Value, error) {
 
 	return res.Value(), nil
 }
+
+// CueValidationError is a violation of a CUE definition by a value
+type CueValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// CompileCue compiles the CUE source
+func CompileCue(src string) (cue.Value, error) {
+	out := cuecontext.New().CompileString(src)
+	if out.Err() != nil {
+		return out, ErrCompileCue(out.Err())
+	}
+	return out, nil
+}
+
+// LookupDefinition returns the definition of the schema at the path, eg. "#Deployment",
+// or the schema itself when the path is empty
+func LookupDefinition(schema cue.Value, definition string) (cue.Value, error) {
+	if definition == "" {
+		return schema, nil
+	}
+	def := schema.LookupPath(cue.ParsePath(definition))
+	if !def.Exists() {
+		return def, ErrCompileCue(fmt.Errorf("the definition %s does not exist", definition))
+	}
+	return def, nil
+}
+
+// ValidateJSONWithCue validates the JSON encoded value against the definition of the schema.
+// The value must be complete: every field of the definition has a value or a default.
+// The error is only returned when the value or the definition can't be read.
+func ValidateJSONWithCue(schema cue.Value, definition string, value []byte) ([]CueValidationError, error) {
+	def, err := LookupDefinition(schema, definition)
+	if err != nil {
+		return nil, err
+	}
+	val := schema.Context().CompileBytes(value)
+	if val.Err() != nil {
+		return nil, ErrJsonToCue(val.Err())
+	}
+	err = def.Unify(val).Validate(cue.Concrete(true))
+	if err == nil {
+		return nil, nil
+	}
+	violations := make([]CueValidationError, 0)
+	for _, e := range errors.Errors(err) {
+		format, args := e.Msg()
+		violations = append(violations, CueValidationError{
+			Path:    strings.Join(e.Path(), "."),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	return violations, nil
+}
+
+// ValidateJSONWithJSONSchema validates the JSON encoded value against the JSON schema through CUE,
+// an alternative to the JSON schema validators
+func ValidateJSONWithJSONSchema(jsonSchema string, value []byte) ([]CueValidationError, error) {
+	schema, err := JsonSchemaToCue(jsonSchema)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateJSONWithCue(schema, "", value)
+}
+
+// ExtractCueDefaults returns the defaults of the definition of the schema, keyed like the value.
+// The fields without default, and the optional ones, are left out.
+func ExtractCueDefaults(schema cue.Value, definition string) (map[string]interface{}, error) {
+	def, err := LookupDefinition(schema, definition)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := cueDefaults(def)
+	if err != nil {
+		return nil, ErrCueDefaults(err)
+	}
+	if defaults == nil {
+		return map[string]interface{}{}, nil
+	}
+	fields, ok := defaults.(map[string]interface{})
+	if !ok {
+		return nil, ErrCueDefaults(fmt.Errorf("the default of %s is a %T, not a struct", definition, defaults))
+	}
+	return fields, nil
+}
+
+func cueDefaults(v cue.Value) (interface{}, error) {
+	if def, ok := v.Default(); ok && def.IsConcrete() {
+		var out interface{}
+		if err := def.Decode(&out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	if v.IncompleteKind() != cue.StructKind {
+		return nil, nil
+	}
+	fields, err := v.Fields()
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	for fields.Next() {
+		fieldDefault, err := cueDefaults(fields.Value())
+		if err != nil {
+			return nil, err
+		}
+		if fieldDefault == nil {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{})
+		}
+		out[fields.Selector().Unquoted()] = fieldDefault
+	}
+	if out == nil {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// ApplyCueDefaults unifies the JSON encoded value with the definition of the schema and returns
+// the JSON encoded result, where the fields left out of the value take their default.
+func ApplyCueDefaults(schema cue.Value, definition string, value []byte) ([]byte, error) {
+	def, err := LookupDefinition(schema, definition)
+	if err != nil {
+		return nil, err
+	}
+	val := schema.Context().CompileBytes(value)
+	if val.Err() != nil {
+		return nil, ErrJsonToCue(val.Err())
+	}
+	unified := def.Unify(val)
+	if err := unified.Validate(cue.Concrete(true)); err != nil {
+		return nil, ErrCueDefaults(err)
+	}
+	out, err := unified.MarshalJSON()
+	if err != nil {
+		return nil, ErrCueDefaults(err)
+	}
+	return out, nil
+}
@@ -70,3 +70,75 @@ func TestGetNonConcreteFields(t *testing.T) {
 		})
 	}
 }
+
+const testCueDefinitions = `
+#Deployment: {
+	name:     string
+	image:    string
+	replicas: int & >=0 | *1
+	strategy: type: *"RollingUpdate" | "Recreate"
+	labels?: [string]: string
+}
+#Port:  int | *8080
+#Hosts: [...string] | *["localhost"]
+`
+
+func TestCueDefinitions(t *testing.T) {
+	schema, err := CompileCue(testCueDefinitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := ValidateJSONWithCue(schema, "#Deployment", []byte(`{"name": "web", "image": "nginx"}`))
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("expected the value to be valid, got %v %v", violations, err)
+	}
+	violations, err = ValidateJSONWithCue(schema, "#Deployment", []byte(`{"name": "web", "replicas": -1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) < 2 {
+		t.Fatalf("expected the negative replicas and the missing image to be reported, got %v", violations)
+	}
+	if _, err := ValidateJSONWithCue(schema, "#Missing", []byte(`{}`)); err == nil {
+		t.Fatal("expected a missing definition to fail")
+	}
+
+	defaults, err := ExtractCueDefaults(schema, "#Deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"replicas": int(1), "strategy": map[string]interface{}{"type": "RollingUpdate"}}
+	if fmt.Sprint(defaults) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, defaults)
+	}
+
+	for _, definition := range []string{"#Port", "#Hosts"} {
+		if _, err := ExtractCueDefaults(schema, definition); err == nil {
+			t.Errorf("expected an error extracting the non-struct default of %s", definition)
+		}
+	}
+
+	out, err := ApplyCueDefaults(schema, "#Deployment", []byte(`{"name": "web", "image": "nginx", "strategy": {"type": "Recreate"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"name":"web","image":"nginx","replicas":1,"strategy":{"type":"Recreate"}}` {
+		t.Fatalf("unexpected value %s", out)
+	}
+}
+
+func TestValidateJSONWithJSONSchema(t *testing.T) {
+	schema := `{"type": "object", "properties": {"port": {"type": "integer", "minimum": 1}}, "required": ["port"]}`
+	violations, err := ValidateJSONWithJSONSchema(schema, []byte(`{"port": 0}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) == 0 {
+		t.Fatal("expected the port to be reported")
+	}
+	violations, err = ValidateJSONWithJSONSchema(schema, []byte(`{"port": 8080}`))
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("expected the value to be valid, got %v %v", violations, err)
+	}
+}
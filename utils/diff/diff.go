@@ -0,0 +1,512 @@
+// Package diff computes structured differences of JSON and YAML documents, addressed by the paths of the values.
+//
+// Paths are made of the keys of maps, separated by dots, and of the indices or keys of list items in brackets,
+// e.g. spec.template.spec.containers[istio-proxy].image. Keys which are not made of letters, digits, - and _ only
+// are quoted in brackets, e.g. metadata.annotations["meshery.io/depends-on"].
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeType is the kind of difference between two values.
+type ChangeType string
+
+const (
+	// Added values exist in the new document only.
+	Added ChangeType = "added"
+	// Removed values exist in the old document only.
+	Removed ChangeType = "removed"
+	// Modified values differ between the documents.
+	Modified ChangeType = "modified"
+)
+
+// Change is a difference between the values at the path of two documents.
+type Change struct {
+	Path string      `json:"path"`
+	Type ChangeType  `json:"type"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// Options configures the comparison of documents.
+type Options struct {
+	// IgnorePaths are the paths of the values which are not compared, along with the values they contain.
+	// A * matches any key or item, e.g. spec.containers[*].image.
+	IgnorePaths []string
+	// IgnoreAdded reports only the values of the old document which changed, e.g. to compare a desired state,
+	// as old document, to a live state having fields set by other clients.
+	IgnoreAdded bool
+	// MissingEqualsEmpty considers missing values equal to empty strings, lists, maps and to false.
+	MissingEqualsEmpty bool
+	// ListKeys are the keys list items are matched by, instead of their position, e.g. name for containers.
+	// The first key held by all items of both lists, with unique string values, is used.
+	ListKeys []string
+	// Equal, if set, reports whether scalar values which differ otherwise are equal, e.g. quantities like 1000m and 1.
+	Equal func(from, to interface{}) bool
+}
+
+// Compare returns the differences between the values from and to, sorted by path.
+// Numbers are compared by their value regardless of their type.
+// Lists whose items are not matched by key, and whose lengths differ, are reported as a single modification.
+func Compare(from, to interface{}, opts Options) []Change {
+	c := comparer{opts: opts}
+	for _, path := range opts.IgnorePaths {
+		c.ignored = append(c.ignored, ParsePath(path))
+	}
+	c.compare(nil, from, to, true, true)
+	return c.changes
+}
+
+// CompareDocuments decodes the JSON or YAML documents and returns their differences.
+func CompareDocuments(from, to []byte, opts Options) ([]Change, error) {
+	fromValue, err := Decode(from)
+	if err != nil {
+		return nil, err
+	}
+	toValue, err := Decode(to)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(fromValue, toValue, opts), nil
+}
+
+// Decode decodes the JSON or YAML document into maps with string keys, lists and scalars.
+func Decode(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, ErrDecodeDocument(err)
+	}
+	return normalize(value), nil
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalize(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[stringKey(k)] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	}
+	return value
+}
+
+func stringKey(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	b, _ := yaml.Marshal(key)
+	return strings.TrimSpace(string(b))
+}
+
+// MergePatch returns the JSON merge patch turning from into to, see https://www.rfc-editor.org/rfc/rfc7386.
+// Lists are replaced as a whole and removed fields are set to null. It is not a strategic merge patch: applied as one,
+// e.g. by kubectl or kustomize, the lists with merge keys, such as containers, are merged and their removed items kept.
+// Use JSONPatch for those.
+func MergePatch(from, to map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for key, value := range to {
+		fromValue, ok := from[key]
+		if ok && reflect.DeepEqual(fromValue, value) {
+			continue
+		}
+		fromMap, fromIsMap := fromValue.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if fromIsMap && valueIsMap {
+			if nested := MergePatch(fromMap, valueMap); len(nested) > 0 {
+				patch[key] = nested
+			}
+			continue
+		}
+		patch[key] = value
+	}
+	for key := range from {
+		if _, ok := to[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
+
+// Operation is an operation of a JSON patch, see https://www.rfc-editor.org/rfc/rfc6902.
+// Path is a JSON pointer, e.g. /spec/template/spec/containers/0/image.
+type Operation struct {
+	Op    string      `json:"op" yaml:"op"`
+	Path  string      `json:"path" yaml:"path"`
+	Value interface{} `json:"value" yaml:"value"`
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.fields())
+}
+
+func (o Operation) MarshalYAML() (interface{}, error) {
+	return o.fields(), nil
+}
+
+// fields leaves the value out of the remove operations only, as the values of the other operations may be null.
+func (o Operation) fields() map[string]interface{} {
+	fields := map[string]interface{}{"op": o.Op, "path": o.Path}
+	if o.Op != "remove" {
+		fields["value"] = o.Value
+	}
+	return fields
+}
+
+// JSONPatch returns the operations of the JSON patch turning from into to. The items of lists are patched by index,
+// the items removed from the end of a list are removed last to first and the items added are appended, so that the
+// patch is exact whatever the merge keys of the lists, unlike a merge patch applied as a strategic merge patch.
+func JSONPatch(from, to map[string]interface{}) []Operation {
+	return jsonPatch("", from, to, nil)
+}
+
+func jsonPatch(pointer string, from, to interface{}, ops []Operation) []Operation {
+	if reflect.DeepEqual(from, to) {
+		return ops
+	}
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		toMap, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(fromValue) {
+			if _, ok := toMap[key]; !ok {
+				ops = append(ops, Operation{Op: "remove", Path: pointer + "/" + escapePointer(key)})
+			}
+		}
+		for _, key := range sortedKeys(toMap) {
+			child := pointer + "/" + escapePointer(key)
+			if value, ok := fromValue[key]; ok {
+				ops = jsonPatch(child, value, toMap[key], ops)
+			} else {
+				ops = append(ops, Operation{Op: "add", Path: child, Value: toMap[key]})
+			}
+		}
+		return ops
+	case []interface{}:
+		toList, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		common := len(fromValue)
+		if len(toList) < common {
+			common = len(toList)
+		}
+		for i := 0; i < common; i++ {
+			ops = jsonPatch(pointer+"/"+strconv.Itoa(i), fromValue[i], toList[i], ops)
+		}
+		for i := len(fromValue) - 1; i >= common; i-- {
+			ops = append(ops, Operation{Op: "remove", Path: pointer + "/" + strconv.Itoa(i)})
+		}
+		for _, value := range toList[common:] {
+			ops = append(ops, Operation{Op: "add", Path: pointer + "/-", Value: value})
+		}
+		return ops
+	}
+	return append(ops, Operation{Op: "replace", Path: pointer, Value: to})
+}
+
+// escapePointer escapes the key as a segment of a JSON pointer, see https://www.rfc-editor.org/rfc/rfc6901.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// JoinPath returns the path of the segments, keys of maps or indices and keys of list items.
+func JoinPath(segments ...string) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		switch {
+		case !isPlainKey(segment):
+			b.WriteString("[" + strconv.Quote(segment) + "]")
+		default:
+			if i > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(segment)
+		}
+	}
+	return b.String()
+}
+
+// ParsePath returns the segments of the path, the inverse of JoinPath and of the paths of changes.
+func ParsePath(path string) []string {
+	var segments []string
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if len(path) > 1 && path[1] == '"' {
+				if quoted, err := strconv.QuotedPrefix(path[1:]); err == nil {
+					segment, _ := strconv.Unquote(quoted)
+					segments = append(segments, segment)
+					path = strings.TrimPrefix(path[1+len(quoted):], "]")
+					continue
+				}
+			}
+			if end == -1 {
+				end = len(path)
+			}
+			segments = append(segments, path[1:end])
+			path = path[min(end+1, len(path)):]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end == -1 {
+				end = len(path)
+			}
+			segments = append(segments, path[:end])
+			path = path[end:]
+		}
+	}
+	return segments
+}
+
+func isPlainKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '*') {
+			return false
+		}
+	}
+	return true
+}
+
+type comparer struct {
+	opts    Options
+	ignored [][]string
+	changes []Change
+}
+
+// segment is a segment of the path of a value, with the format it is written in.
+type segment struct {
+	name string
+	item bool
+}
+
+func (c *comparer) compare(path []segment, from, to interface{}, hasFrom, hasTo bool) {
+	if c.isIgnored(path) {
+		return
+	}
+	if !hasTo || to == nil {
+		if hasFrom && from != nil && !(c.opts.MissingEqualsEmpty && isEmpty(from)) {
+			c.add(path, Removed, from, nil)
+		}
+		return
+	}
+	if !hasFrom || from == nil {
+		if !c.opts.IgnoreAdded && !(c.opts.MissingEqualsEmpty && isEmpty(to)) {
+			c.add(path, Added, nil, to)
+		}
+		return
+	}
+
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			c.add(path, Modified, from, to)
+			return
+		}
+		keys := make([]string, 0, len(f)+len(t))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		for k := range t {
+			if _, ok := f[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fv, inFrom := f[k]
+			tv, inTo := t[k]
+			c.compare(append(path, segment{name: k}), fv, tv, inFrom, inTo)
+		}
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok {
+			c.add(path, Modified, from, to)
+			return
+		}
+		c.compareLists(path, f, t)
+	default:
+		if !c.equalScalars(from, to) {
+			c.add(path, Modified, from, to)
+		}
+	}
+}
+
+func (c *comparer) compareLists(path []segment, from, to []interface{}) {
+	for _, key := range c.opts.ListKeys {
+		fromKeys, ok := itemKeys(from, key)
+		if !ok {
+			continue
+		}
+		toKeys, ok := itemKeys(to, key)
+		if !ok {
+			continue
+		}
+		byKey := make(map[string]interface{}, len(to))
+		for i, k := range toKeys {
+			byKey[k] = to[i]
+		}
+		inFrom := make(map[string]bool, len(from))
+		for i, k := range fromKeys {
+			inFrom[k] = true
+			tv, inTo := byKey[k]
+			c.compare(append(path, segment{name: k, item: true}), from[i], tv, true, inTo)
+		}
+		for i, k := range toKeys {
+			if !inFrom[k] {
+				c.compare(append(path, segment{name: k, item: true}), nil, to[i], false, true)
+			}
+		}
+		return
+	}
+	if len(from) != len(to) {
+		if !(c.opts.MissingEqualsEmpty && len(from) == 0 && len(to) == 0) {
+			c.add(path, Modified, from, to)
+		}
+		return
+	}
+	for i := range from {
+		c.compare(append(path, segment{name: strconv.Itoa(i), item: true}), from[i], to[i], true, true)
+	}
+}
+
+func (c *comparer) add(path []segment, changeType ChangeType, from, to interface{}) {
+	c.changes = append(c.changes, Change{Path: formatPath(path), Type: changeType, From: from, To: to})
+}
+
+func (c *comparer) isIgnored(path []segment) bool {
+	for _, ignored := range c.ignored {
+		if len(ignored) != len(path) {
+			continue
+		}
+		match := true
+		for i, s := range ignored {
+			if s != "*" && s != path[i].name {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *comparer) equalScalars(from, to interface{}) bool {
+	if reflect.DeepEqual(from, to) {
+		return true
+	}
+	if f, ok := toFloat(from); ok {
+		if t, ok := toFloat(to); ok && f == t {
+			return true
+		}
+	}
+	return c.opts.Equal != nil && c.opts.Equal(from, to)
+}
+
+func formatPath(path []segment) string {
+	var b strings.Builder
+	for i, s := range path {
+		switch {
+		case s.item && isPlainKey(s.name):
+			b.WriteString("[" + s.name + "]")
+		case !isPlainKey(s.name):
+			b.WriteString("[" + strconv.Quote(s.name) + "]")
+		default:
+			if i > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(s.name)
+		}
+	}
+	return b.String()
+}
+
+// itemKeys returns the values of the key of the items, if all items are maps with a unique string value for the key.
+func itemKeys(items []interface{}, key string) ([]string, bool) {
+	if len(items) == 0 {
+		return nil, false
+	}
+	keys := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		k, ok := m[key].(string)
+		if !ok || seen[k] {
+			return nil, false
+		}
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	return keys, true
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case uint64:
+		return float64(t), true
+	case float32:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
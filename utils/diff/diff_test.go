@@ -0,0 +1,124 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompareDocuments(t *testing.T) {
+	from := []byte(`
+metadata:
+  name: app
+  annotations:
+    meshery.io/depends-on: Namespace/istio-system
+spec:
+  replicas: 2
+  ports: [80, 443]
+  containers:
+  - name: app
+    image: nginx:1.25
+  - name: istio-proxy
+    image: istio/proxyv2:1.20
+status:
+  ready: true
+`)
+	to := []byte(`{
+  "metadata": {"name": "app", "annotations": {"meshery.io/depends-on": "Namespace/default"}, "labels": {"app": "app"}},
+  "spec": {
+    "replicas": 2.0,
+    "ports": [80],
+    "containers": [
+      {"name": "istio-proxy", "image": "istio/proxyv2:1.20"},
+      {"name": "app", "image": "nginx:1.24"}
+    ]
+  }
+}`)
+
+	changes, err := CompareDocuments(from, to, Options{ListKeys: []string{"name"}, IgnorePaths: []string{"spec.containers[*].resources"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Path: `metadata.annotations["meshery.io/depends-on"]`, Type: Modified, From: "Namespace/istio-system", To: "Namespace/default"},
+		{Path: "metadata.labels", Type: Added, To: map[string]interface{}{"app": "app"}},
+		{Path: "spec.containers[app].image", Type: Modified, From: "nginx:1.25", To: "nginx:1.24"},
+		{Path: "spec.ports", Type: Modified, From: []interface{}{80, 443}, To: []interface{}{80}},
+		{Path: "status", Type: Removed, From: map[string]interface{}{"ready": true}},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, changes)
+	}
+
+	changes, err = CompareDocuments(from, to, Options{IgnoreAdded: true, IgnorePaths: []string{"spec", "metadata.annotations"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "status" {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+
+	if _, err := CompareDocuments([]byte("a: [b"), to, Options{}); err == nil {
+		t.Error("comparing an invalid document should fail")
+	}
+}
+
+func TestPaths(t *testing.T) {
+	segments := []string{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"}
+	path := JoinPath(segments...)
+	if path != `metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]` {
+		t.Errorf("unexpected path %s", path)
+	}
+	if got := ParsePath(path); !reflect.DeepEqual(got, segments) {
+		t.Errorf("expected %v, got %v", segments, got)
+	}
+	if got := ParsePath("spec.containers[*].ports[0]"); !reflect.DeepEqual(got, []string{"spec", "containers", "*", "ports", "0"}) {
+		t.Errorf("unexpected segments %v", got)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	from := map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}, "e": []interface{}{1}}
+	to := map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 4}, "e": []interface{}{1, 2}}
+	expected := map[string]interface{}{"b": map[string]interface{}{"c": 4, "d": nil}, "e": []interface{}{1, 2}}
+	if patch := MergePatch(from, to); !reflect.DeepEqual(patch, expected) {
+		t.Errorf("expected %v, got %v", expected, patch)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	from := map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{"meshery.io/a": "1", "b": "2"}},
+		"spec": map[string]interface{}{"containers": []interface{}{
+			map[string]interface{}{"name": "web", "image": "nginx:1.24"},
+			map[string]interface{}{"name": "proxy", "image": "envoy"},
+			map[string]interface{}{"name": "logger", "image": "fluentbit"},
+		}, "paused": true},
+	}
+	to := map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{"meshery.io/a": "3"}},
+		"spec": map[string]interface{}{"containers": []interface{}{
+			map[string]interface{}{"name": "web", "image": "nginx:1.25"},
+		}, "paused": false},
+	}
+	ops := JSONPatch(from, to)
+	expected := []Operation{
+		{Op: "remove", Path: "/metadata/annotations/b"},
+		{Op: "replace", Path: "/metadata/annotations/meshery.io~1a", Value: "3"},
+		{Op: "replace", Path: "/spec/containers/0/image", Value: "nginx:1.25"},
+		{Op: "remove", Path: "/spec/containers/2"},
+		{Op: "remove", Path: "/spec/containers/1"},
+		{Op: "replace", Path: "/spec/paused", Value: false},
+	}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Fatalf("expected %v, got %v", expected, ops)
+	}
+
+	patch, err := json.Marshal(ops[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(patch) != `[{"op":"remove","path":"/metadata/annotations/b"},{"op":"replace","path":"/metadata/annotations/meshery.io~1a","value":"3"},{"op":"replace","path":"/spec/containers/0/image","value":"nginx:1.25"}]` {
+		t.Errorf("unexpected patch %s", patch)
+	}
+}
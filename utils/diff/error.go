@@ -0,0 +1,11 @@
+package diff
+
+import "github.com/layer5io/meshkit/errors"
+
+const (
+	ErrDecodeDocumentCode = "meshkit-11302"
+)
+
+func ErrDecodeDocument(err error) error {
+	return errors.New(ErrDecodeDocumentCode, errors.Alert, []string{"decoding the document failed"}, []string{err.Error()}, []string{"the document is not valid JSON or YAML"}, []string{"Make sure the documents compared are valid JSON or YAML"})
+}
@@ -40,12 +40,22 @@ var (
 	ErrExtractTarXZCode = "meshkit-11184"
 	ErrExtractZipCode   = "meshkit-11185"
 	ErrReadDirCode      = "meshkit-11186"
+	ErrCompileCueCode   = "meshkit-11281"
+	ErrCueDefaultsCode  = "meshkit-11282"
 )
 
 func ErrCueLookup(err error) error {
 	return errors.New(ErrCueLookupCode, errors.Alert, []string{"Could not lookup the given path in the CUE value"}, []string{err.Error()}, []string{""}, []string{"make sure that the path is a valid cue expression and is correct", "make sure that there exists a field with the given path", "make sure that the given root value is correct"})
 }
 
+func ErrCompileCue(err error) error {
+	return errors.New(ErrCompileCueCode, errors.Alert, []string{"Could not compile the CUE source"}, []string{err.Error()}, []string{"Invalid CUE syntax", "The definition referenced does not exist"}, []string{"Make sure that the given source is valid CUE and defines the referenced definition"})
+}
+
+func ErrCueDefaults(err error) error {
+	return errors.New(ErrCueDefaultsCode, errors.Alert, []string{"Could not apply the defaults of the CUE definition"}, []string{err.Error()}, []string{"The value conflicts with the definition", "Some fields of the definition have neither a value nor a default"}, []string{"Make sure that the value is valid against the definition"})
+}
+
 func ErrJsonSchemaToCue(err error) error {
 	return errors.New(ErrJsonSchemaToCueCode, errors.Alert, []string{"Could not convert given JsonSchema into a CUE Value"}, []string{err.Error()}, []string{"Invalid jsonschema"}, []string{"Make sure that the given value is a valid JSONSCHEMA"})
 }
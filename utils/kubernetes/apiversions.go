@@ -0,0 +1,348 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// deprecatedAPI is an API version of a kind removed from Kubernetes, and its replacement.
+type deprecatedAPI struct {
+	removedIn   string
+	replacement string
+	// convert converts the object to the replacement, apart from its API version, nil if only the API version changes.
+	// It returns why the object can't be converted, if it can't.
+	convert func(obj map[string]interface{}) string
+}
+
+// deprecatedAPIs are the API versions removed from Kubernetes, by API version and kind.
+var deprecatedAPIs = map[string]deprecatedAPI{
+	"extensions/v1beta1/Deployment":                                       {"1.16", "apps/v1", convertWorkloadSelector},
+	"extensions/v1beta1/DaemonSet":                                        {"1.16", "apps/v1", convertWorkloadSelector},
+	"extensions/v1beta1/ReplicaSet":                                       {"1.16", "apps/v1", convertWorkloadSelector},
+	"extensions/v1beta1/NetworkPolicy":                                    {"1.16", "networking.k8s.io/v1", nil},
+	"extensions/v1beta1/PodSecurityPolicy":                                {"1.16", "policy/v1beta1", nil},
+	"apps/v1beta1/Deployment":                                             {"1.16", "apps/v1", convertWorkloadSelector},
+	"apps/v1beta1/StatefulSet":                                            {"1.16", "apps/v1", convertWorkloadSelector},
+	"apps/v1beta2/Deployment":                                             {"1.16", "apps/v1", nil},
+	"apps/v1beta2/StatefulSet":                                            {"1.16", "apps/v1", nil},
+	"apps/v1beta2/DaemonSet":                                              {"1.16", "apps/v1", nil},
+	"apps/v1beta2/ReplicaSet":                                             {"1.16", "apps/v1", nil},
+	"extensions/v1beta1/Ingress":                                          {"1.22", "networking.k8s.io/v1", convertIngress},
+	"networking.k8s.io/v1beta1/Ingress":                                   {"1.22", "networking.k8s.io/v1", convertIngress},
+	"networking.k8s.io/v1beta1/IngressClass":                              {"1.22", "networking.k8s.io/v1", nil},
+	"apiextensions.k8s.io/v1beta1/CustomResourceDefinition":               {"1.22", "apiextensions.k8s.io/v1", unconvertible("the schemas of the versions have to be structural, regenerate the CRD, e.g. with controller-gen")},
+	"admissionregistration.k8s.io/v1beta1/MutatingWebhookConfiguration":   {"1.22", "admissionregistration.k8s.io/v1", convertWebhooks},
+	"admissionregistration.k8s.io/v1beta1/ValidatingWebhookConfiguration": {"1.22", "admissionregistration.k8s.io/v1", convertWebhooks},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRole":                       {"1.22", "rbac.authorization.k8s.io/v1", nil},
+	"rbac.authorization.k8s.io/v1beta1/ClusterRoleBinding":                {"1.22", "rbac.authorization.k8s.io/v1", nil},
+	"rbac.authorization.k8s.io/v1beta1/Role":                              {"1.22", "rbac.authorization.k8s.io/v1", nil},
+	"rbac.authorization.k8s.io/v1beta1/RoleBinding":                       {"1.22", "rbac.authorization.k8s.io/v1", nil},
+	"scheduling.k8s.io/v1beta1/PriorityClass":                             {"1.22", "scheduling.k8s.io/v1", nil},
+	"coordination.k8s.io/v1beta1/Lease":                                   {"1.22", "coordination.k8s.io/v1", nil},
+	"certificates.k8s.io/v1beta1/CertificateSigningRequest":               {"1.22", "certificates.k8s.io/v1", convertCertificateSigningRequest},
+	"batch/v1beta1/CronJob":                                               {"1.25", "batch/v1", nil},
+	"policy/v1beta1/PodDisruptionBudget":                                  {"1.25", "policy/v1", convertPodDisruptionBudget},
+	"policy/v1beta1/PodSecurityPolicy":                                    {"1.25", "the Pod Security Admission", unconvertible("PodSecurityPolicies were removed, label the namespaces with the pod security standards instead")},
+	"discovery.k8s.io/v1beta1/EndpointSlice":                              {"1.25", "discovery.k8s.io/v1", convertEndpointSlice},
+	"events.k8s.io/v1beta1/Event":                                         {"1.25", "events.k8s.io/v1", nil},
+	"autoscaling/v2beta1/HorizontalPodAutoscaler":                         {"1.25", "autoscaling/v2", convertHorizontalPodAutoscaler},
+	"autoscaling/v2beta2/HorizontalPodAutoscaler":                         {"1.26", "autoscaling/v2", nil},
+	"flowcontrol.apiserver.k8s.io/v1beta1/FlowSchema":                     {"1.26", "flowcontrol.apiserver.k8s.io/v1", nil},
+	"flowcontrol.apiserver.k8s.io/v1beta1/PriorityLevelConfiguration":     {"1.26", "flowcontrol.apiserver.k8s.io/v1", nil},
+	"storage.k8s.io/v1beta1/CSIStorageCapacity":                           {"1.27", "storage.k8s.io/v1", nil},
+	"flowcontrol.apiserver.k8s.io/v1beta2/FlowSchema":                     {"1.29", "flowcontrol.apiserver.k8s.io/v1", nil},
+	"flowcontrol.apiserver.k8s.io/v1beta2/PriorityLevelConfiguration":     {"1.29", "flowcontrol.apiserver.k8s.io/v1", nil},
+}
+
+// APIMigration is an object of a removed API version, migrated or not by MigrateAPIVersions.
+type APIMigration struct {
+	// Object is the object, as Kind/namespace/name or Kind/name.
+	Object    string `json:"object"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	RemovedIn string `json:"removedIn"`
+	// Migrated is set if the object was rewritten to the API version To.
+	Migrated bool `json:"migrated"`
+	// Reason is why the object can't be migrated, if it isn't.
+	Reason string `json:"reason,omitempty"`
+}
+
+func (m APIMigration) String() string {
+	if m.Migrated {
+		return fmt.Sprintf("%s: migrated from %s to %s", m.Object, m.From, m.To)
+	}
+	return fmt.Sprintf("%s: %s is removed in Kubernetes %s and can't be migrated to %s: %s", m.Object, m.From, m.RemovedIn, m.To, m.Reason)
+}
+
+// DetectRemovedAPIs returns the objects of the API versions removed in the Kubernetes version, e.g. v1.25.3,
+// or of all the API versions removed so far if the version is empty. The objects are not changed.
+func DetectRemovedAPIs(objects []*unstructured.Unstructured, kubernetesVersion string) []APIMigration {
+	var detected []APIMigration
+	for _, obj := range objects {
+		if api, ok := removedAPI(obj, kubernetesVersion); ok {
+			detected = append(detected, APIMigration{Object: objectRef(obj), From: obj.GetAPIVersion(), To: api.replacement, RemovedIn: api.removedIn})
+		}
+	}
+	return detected
+}
+
+// MigrateAPIVersions rewrites the objects of the API versions removed in the Kubernetes version, or of all the API
+// versions removed so far if the version is empty, to their replacements, e.g. policy/v1beta1 to policy/v1. The fields
+// which changed are converted, and the defaults which changed are made explicit, e.g. the failure policy of webhooks.
+// The objects which can't be converted without a decision of their owner are left unchanged, and reported with the
+// reason, e.g. PodDisruptionBudgets with an empty selector, which selects all the pods in policy/v1.
+func MigrateAPIVersions(objects []*unstructured.Unstructured, kubernetesVersion string) []APIMigration {
+	var migrations []APIMigration
+	for _, obj := range objects {
+		from := obj.GetAPIVersion()
+		// an API version may be replaced by an API version removed since, e.g. extensions/v1beta1 by policy/v1beta1
+		for api, ok := removedAPI(obj, kubernetesVersion); ok; api, ok = removedAPI(obj, kubernetesVersion) {
+			migration := APIMigration{Object: objectRef(obj), From: from, To: api.replacement, RemovedIn: api.removedIn}
+			converted := obj.DeepCopy()
+			if api.convert != nil {
+				migration.Reason = api.convert(converted.Object)
+			}
+			if migration.Reason != "" {
+				migrations = append(migrations, migration)
+				break
+			}
+			converted.SetAPIVersion(api.replacement)
+			obj.Object = converted.Object
+			if _, again := removedAPI(obj, kubernetesVersion); !again {
+				migration.Migrated = true
+				migrations = append(migrations, migration)
+			}
+		}
+	}
+	return migrations
+}
+
+// MigrateManifest migrates the objects of the manifest as MigrateAPIVersions, and returns the manifest of the objects.
+func MigrateManifest(manifest []byte, kubernetesVersion string) ([]byte, []APIMigration, error) {
+	objects, err := decodeManifests(bytes.NewReader(manifest), func(obj *unstructured.Unstructured, _ error) bool {
+		return len(obj.GetObjectKind().GroupVersionKind().Kind) < 1
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	migrations := MigrateAPIVersions(objects, kubernetesVersion)
+	var out bytes.Buffer
+	for i, obj := range objects {
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		b, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, migrations, ErrMigrateAPIVersions([]string{err.Error()})
+		}
+		out.Write(b)
+	}
+	return out.Bytes(), migrations, nil
+}
+
+// APIMigrationErrors returns the objects which can't be migrated combined in a MeshKit error, nil if there is none.
+func APIMigrationErrors(migrations []APIMigration) error {
+	var errs []string
+	for _, m := range migrations {
+		if !m.Migrated {
+			errs = append(errs, m.String())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return ErrMigrateAPIVersions(errs)
+}
+
+// removedAPI returns the removed API of the object, if it is removed in the Kubernetes version or before.
+func removedAPI(obj *unstructured.Unstructured, kubernetesVersion string) (deprecatedAPI, bool) {
+	api, ok := deprecatedAPIs[obj.GetAPIVersion()+"/"+obj.GetKind()]
+	if !ok || kubernetesVersion != "" && versionBefore(kubernetesVersion, api.removedIn) {
+		return deprecatedAPI{}, false
+	}
+	return api, true
+}
+
+func unconvertible(reason string) func(map[string]interface{}) string {
+	return func(map[string]interface{}) string { return reason }
+}
+
+// convertWorkloadSelector sets the selector of workloads, required by apps/v1, to the labels of their template, as defaulted before.
+func convertWorkloadSelector(obj map[string]interface{}) string {
+	if _, ok, _ := unstructured.NestedFieldNoCopy(obj, "spec", "selector"); ok {
+		return ""
+	}
+	labels, ok, _ := unstructured.NestedStringMap(obj, "spec", "template", "metadata", "labels")
+	if !ok || len(labels) == 0 {
+		return "the selector is required and the template has no labels to select"
+	}
+	matchLabels := make(map[string]interface{}, len(labels))
+	for k, v := range labels {
+		matchLabels[k] = v
+	}
+	_ = unstructured.SetNestedMap(obj, map[string]interface{}{"matchLabels": matchLabels}, "spec", "selector")
+	return ""
+}
+
+// convertIngress converts the backends to services of their name and port, and sets the type of the paths.
+func convertIngress(obj map[string]interface{}) string {
+	spec, _, _ := unstructured.NestedMap(obj, "spec")
+	if spec == nil {
+		return ""
+	}
+	if backend, ok := spec["backend"].(map[string]interface{}); ok {
+		delete(spec, "backend")
+		spec["defaultBackend"] = convertIngressBackend(backend)
+	}
+	rules, _ := spec["rules"].([]interface{})
+	for _, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for _, p := range paths {
+			path, _ := p.(map[string]interface{})
+			if path == nil {
+				continue
+			}
+			if backend, ok := path["backend"].(map[string]interface{}); ok {
+				path["backend"] = convertIngressBackend(backend)
+			}
+			if _, ok := path["pathType"]; !ok {
+				path["pathType"] = "ImplementationSpecific"
+			}
+		}
+		if len(paths) > 0 {
+			_ = unstructured.SetNestedSlice(rule, paths, "http", "paths")
+		}
+	}
+	_ = unstructured.SetNestedMap(obj, spec, "spec")
+	return ""
+}
+
+func convertIngressBackend(backend map[string]interface{}) map[string]interface{} {
+	name, ok := backend["serviceName"].(string)
+	if !ok {
+		// resource backends are unchanged
+		return backend
+	}
+	port := map[string]interface{}{}
+	switch p := backend["servicePort"].(type) {
+	case string:
+		port["name"] = p
+	case int64, float64, int:
+		port["number"] = p
+	}
+	return map[string]interface{}{"service": map[string]interface{}{"name": name, "port": port}}
+}
+
+// convertWebhooks makes the defaults of v1beta1 explicit, as they changed in v1, and sets the API versions of the reviews.
+func convertWebhooks(obj map[string]interface{}) string {
+	webhooks, _ := obj["webhooks"].([]interface{})
+	for _, w := range webhooks {
+		webhook, _ := w.(map[string]interface{})
+		if webhook == nil {
+			continue
+		}
+		name, _ := webhook["name"].(string)
+		switch webhook["sideEffects"] {
+		case "None", "NoneOnDryRun":
+		default:
+			return fmt.Sprintf("the sideEffects of the webhook %s have to be None or NoneOnDryRun", name)
+		}
+		defaults := map[string]interface{}{
+			"admissionReviewVersions": []interface{}{"v1beta1"},
+			"failurePolicy":           "Ignore",
+			"matchPolicy":             "Exact",
+			"timeoutSeconds":          int64(30),
+		}
+		for field, value := range defaults {
+			if _, ok := webhook[field]; !ok {
+				webhook[field] = value
+			}
+		}
+	}
+	return ""
+}
+
+func convertCertificateSigningRequest(obj map[string]interface{}) string {
+	if name, _, _ := unstructured.NestedString(obj, "spec", "signerName"); name == "" {
+		return "the signerName is required, e.g. kubernetes.io/kube-apiserver-client"
+	}
+	return ""
+}
+
+func convertPodDisruptionBudget(obj map[string]interface{}) string {
+	if selector, _, _ := unstructured.NestedMap(obj, "spec", "selector"); len(selector) == 0 {
+		return "an empty selector selects none of the pods in policy/v1beta1 but all the pods of the namespace in policy/v1"
+	}
+	return ""
+}
+
+// convertEndpointSlice moves the topology of the endpoints to their node name, zone and deprecated topology.
+func convertEndpointSlice(obj map[string]interface{}) string {
+	endpoints, _ := obj["endpoints"].([]interface{})
+	for _, e := range endpoints {
+		endpoint, _ := e.(map[string]interface{})
+		topology, ok := endpoint["topology"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		delete(endpoint, "topology")
+		if node, ok := topology["kubernetes.io/hostname"]; ok {
+			endpoint["nodeName"] = node
+			delete(topology, "kubernetes.io/hostname")
+		}
+		if zone, ok := topology["topology.kubernetes.io/zone"]; ok {
+			endpoint["zone"] = zone
+			delete(topology, "topology.kubernetes.io/zone")
+		}
+		if len(topology) > 0 {
+			endpoint["deprecatedTopology"] = topology
+		}
+	}
+	return ""
+}
+
+// convertHorizontalPodAutoscaler converts the targets of the resource and pods metrics to metric targets.
+func convertHorizontalPodAutoscaler(obj map[string]interface{}) string {
+	metrics, _, _ := unstructured.NestedSlice(obj, "spec", "metrics")
+	for _, m := range metrics {
+		metric, _ := m.(map[string]interface{})
+		metricType, _ := metric["type"].(string)
+		source, _ := metric[strings.ToLower(metricType)].(map[string]interface{})
+		switch metricType {
+		case "Resource":
+			if source == nil {
+				return "a resource metric has no resource"
+			}
+			if utilization, ok := source["targetAverageUtilization"]; ok {
+				delete(source, "targetAverageUtilization")
+				source["target"] = map[string]interface{}{"type": "Utilization", "averageUtilization": utilization}
+			} else if value, ok := source["targetAverageValue"]; ok {
+				delete(source, "targetAverageValue")
+				source["target"] = map[string]interface{}{"type": "AverageValue", "averageValue": value}
+			}
+		case "Pods":
+			if source == nil {
+				return "a pods metric has no pods"
+			}
+			identifier := map[string]interface{}{"name": source["metricName"]}
+			if selector, ok := source["selector"]; ok {
+				identifier["selector"] = selector
+			}
+			metric["pods"] = map[string]interface{}{
+				"metric": identifier,
+				"target": map[string]interface{}{"type": "AverageValue", "averageValue": source["targetAverageValue"]},
+			}
+		default:
+			return fmt.Sprintf("the %s metrics have to be converted by hand", metricType)
+		}
+	}
+	if len(metrics) > 0 {
+		_ = unstructured.SetNestedSlice(obj, metrics, "spec", "metrics")
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const removedAPIsManifest = `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: meshery
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /
+        backend:
+          serviceName: meshery
+          servicePort: 9081
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: meshery
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels: {app: meshery}
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: all
+spec:
+  minAvailable: 1
+---
+apiVersion: extensions/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: restricted
+---
+apiVersion: autoscaling/v2beta1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: meshery
+spec:
+  metrics:
+  - type: Resource
+    resource: {name: cpu, targetAverageUtilization: 80}
+`
+
+func TestMigrateManifest(t *testing.T) {
+	manifest, migrations, err := MigrateManifest([]byte(removedAPIsManifest), "v1.25.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated := map[string]bool{}
+	for _, m := range migrations {
+		migrated[m.Object] = m.Migrated
+	}
+	want := map[string]bool{
+		"Ingress/meshery":                 true,
+		"PodDisruptionBudget/meshery":     true,
+		"PodDisruptionBudget/all":         false,
+		"PodSecurityPolicy/restricted":    false,
+		"HorizontalPodAutoscaler/meshery": true,
+	}
+	if len(migrated) != len(want) {
+		t.Fatalf("migrations = %v", migrations)
+	}
+	for object, ok := range want {
+		if migrated[object] != ok {
+			t.Errorf("expected the migration of %s to be %v, got %v", object, ok, migrations)
+		}
+	}
+	if code := errors.GetCode(APIMigrationErrors(migrations)); code != ErrMigrateAPIVersionsCode {
+		t.Errorf("expected the unconvertible objects to be reported, got %q", code)
+	}
+
+	docs := strings.Split(string(manifest), "---\n")
+	ingress := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(docs[0]), &ingress); err != nil {
+		t.Fatal(err)
+	}
+	paths, _, _ := unstructured.NestedSlice(ingress, "spec", "rules")
+	path := paths[0].(map[string]interface{})["http"].(map[string]interface{})["paths"].([]interface{})[0].(map[string]interface{})
+	if ingress["apiVersion"] != "networking.k8s.io/v1" || path["pathType"] != "ImplementationSpecific" {
+		t.Errorf("unexpected Ingress %v", ingress)
+	}
+	if port, _, _ := unstructured.NestedFieldNoCopy(path, "backend", "service", "port", "number"); port != float64(9081) {
+		t.Errorf("expected the service port to be converted, got %v", path["backend"])
+	}
+	if !strings.Contains(docs[2], "apiVersion: policy/v1beta1") {
+		t.Errorf("expected the unconvertible PodDisruptionBudget to be unchanged, got %s", docs[2])
+	}
+	if !strings.Contains(docs[3], "apiVersion: policy/v1beta1") {
+		t.Errorf("expected the PodSecurityPolicy to be migrated as far as possible, got %s", docs[3])
+	}
+	if !strings.Contains(docs[4], "averageUtilization: 80") || !strings.Contains(docs[4], "apiVersion: autoscaling/v2") {
+		t.Errorf("expected the metrics to be converted, got %s", docs[4])
+	}
+
+	objects, err := decodeManifests(strings.NewReader(removedAPIsManifest), func(*unstructured.Unstructured, error) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detected := DetectRemovedAPIs(objects, "v1.21.0"); len(detected) != 1 || detected[0].Object != "PodSecurityPolicy/restricted" {
+		t.Errorf("expected only the API removed before 1.21 to be detected, got %v", detected)
+	}
+}
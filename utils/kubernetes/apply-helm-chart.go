@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/cas"
 	"gopkg.in/yaml.v2"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
@@ -78,6 +80,10 @@ type HelmEntryMetadata struct {
 	AppVersion string `yaml:"appVersion"`
 	Name       string `yaml:"name"`
 	Version    string `yaml:"version"`
+	// Digest is the SHA-256 hash of the chart archive, if the repository publishes it
+	Digest string `yaml:"digest"`
+	// URLs are the URLs of the chart archive, absolute or relative to the repository
+	URLs []string `yaml:"urls"`
 }
 
 // HelmChartLocation describes the structure for defining
@@ -180,6 +186,17 @@ type ApplyHelmChartConfig struct {
 	// DownloadLocation defines the location where the user wants to download the helm charts
 	// If this is not provided, the helm chart is downloaded to the "/tmp" folder
 	DownloadLocation string
+
+	// Store caches the downloaded charts by content, so that they are downloaded once across runs
+	//
+	// If this is provided, DownloadLocation is ignored
+	Store *cas.Store
+
+	// SkipSchemaValidation skips the validation of the OverrideValues against
+	// the values.schema.json of the chart before installing or upgrading it
+	//
+	// Defaults to false, see ValidateHelmValues
+	SkipSchemaValidation bool
 }
 
 // ApplyHelmChart takes in the url for the helm chart
@@ -260,6 +277,12 @@ func (client *Client) ApplyHelmChart(cfg ApplyHelmChartConfig) error {
 	if err = checkIfInstallable(helmChart); err != nil {
 		return ErrApplyHelmChart(err)
 	}
+	// Validate the values before installing, Helm fails mid-install otherwise
+	if cfg.Action != UNINSTALL && !cfg.SkipSchemaValidation {
+		if err := ValidateHelmValues(helmChart, cfg.OverrideValues); err != nil {
+			return err
+		}
+	}
 
 	actionConfig, err := createHelmActionConfig(client, cfg)
 	if err != nil {
@@ -347,14 +370,38 @@ func getHelmLocalPath(cfg ApplyHelmChartConfig) (string, error) {
 		return cfg.LocalPath, nil
 	}
 
+	if cfg.Store != nil {
+		url, digest, err := storedHelmChartSource(cfg)
+		if err != nil {
+			return "", ErrApplyHelmChart(err)
+		}
+		path, _, err := cfg.Store.Fetch(context.Background(), url, digest)
+		if err != nil {
+			return "", ErrApplyHelmChart(err)
+		}
+		return path, nil
+	}
+
 	url, err := getHelmChartURL(cfg)
 	if err != nil {
 		return "", ErrApplyHelmChart(err)
 	}
-
 	return fetchHelmChart(url, cfg.DownloadLocation)
 }
 
+// storedHelmChartSource returns the URL of the chart along with the digest published in the index of the repository,
+// from a single lookup of the index, so that the store looks the chart up by content rather than by URL. The digest
+// is empty if the chart is not located in a repository, or if the index has no digest for it.
+func storedHelmChartSource(cfg ApplyHelmChartConfig) (string, cas.Digest, error) {
+	if cfg.URL != "" {
+		return cfg.URL, "", nil
+	}
+	if cfg.ChartLocation.Chart == "" {
+		return "", "", fmt.Errorf("\"Chart\" cannot be empty")
+	}
+	return findHelmChart(http.DefaultClient, cfg.ChartLocation.Repository, cfg.ChartLocation.Chart, cfg.ChartLocation.Version)
+}
+
 // getHelmChartURL returns the chart url irrespective of the chosen method for
 // performing action
 func getHelmChartURL(cfg ApplyHelmChartConfig) (string, error) {
@@ -371,6 +418,11 @@ func getHelmChartURL(cfg ApplyHelmChartConfig) (string, error) {
 // if the chart is already present in the download location
 // then the download is skipped
 func fetchHelmChart(chartURL, downloadPath string) (string, error) {
+	return fetchHelmChartWithClient(http.DefaultClient, chartURL, downloadPath)
+}
+
+// fetchHelmChartWithClient is fetchHelmChart, the chart being downloaded with the client
+func fetchHelmChartWithClient(client *http.Client, chartURL, downloadPath string) (string, error) {
 	filename := filepath.Base(chartURL)
 
 	// This allows the caller of the function to use the perfered location to download the helm chart, e.g. "~/.meshery/manifests"
@@ -385,7 +437,7 @@ func fetchHelmChart(chartURL, downloadPath string) (string, error) {
 		return downloadPath, nil
 	}
 
-	if err := utils.DownloadFile(downloadPath, chartURL); err != nil {
+	if err := utils.DownloadFileWithClient(client, downloadPath, chartURL); err != nil {
 		return "", ErrApplyHelmChart(err)
 	}
 
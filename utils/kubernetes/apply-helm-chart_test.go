@@ -0,0 +1,58 @@
+package kubernetes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/utils/cas"
+)
+
+func TestGetHelmLocalPathStoreDigest(t *testing.T) {
+	chart := "chart archive"
+	sum := sha256.Sum256([]byte(chart))
+	digest := hex.EncodeToString(sum[:])
+	indexFetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			indexFetches++
+			// a digest made of digits only is quoted, it would be decoded as a number otherwise
+			fmt.Fprintf(w, "apiVersion: v1\nentries:\n  web:\n  - name: web\n    version: 0.1.0\n    digest: %s\n    urls: [web-0.1.0.tgz]\n  - name: web\n    version: 0.2.0\n    digest: \"%s\"\n    urls: [web-0.2.0.tgz]\n", digest, strings.Repeat("0", 64))
+		case "/web-0.1.0.tgz", "/web-0.2.0.tgz":
+			fmt.Fprint(w, chart)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	store, err := cas.New(t.TempDir(), cas.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := getHelmLocalPath(ApplyHelmChartConfig{
+		ChartLocation: HelmChartLocation{Repository: srv.URL, Chart: "web", Version: "0.1.0"},
+		Store:         store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := store.Path(cas.Digest("sha256:" + digest)); path != want {
+		t.Errorf("path = %s; want %s", path, want)
+	}
+	if indexFetches != 1 {
+		t.Errorf("expected the index to be fetched once, got %d", indexFetches)
+	}
+	// the chart not matching the digest of the index is rejected
+	if _, err := getHelmLocalPath(ApplyHelmChartConfig{
+		ChartLocation: HelmChartLocation{Repository: srv.URL, Chart: "web", Version: "0.2.0"},
+		Store:         store,
+	}); err == nil {
+		t.Error("expected the chart not matching the digest of the index to be rejected")
+	}
+}
@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"bytes"
 	"context"
 	"strings"
 
@@ -23,31 +24,78 @@ type ApplyOptions struct {
 	Update       bool
 	Delete       bool
 	IgnoreErrors bool
+	// Distribution adapts the resources to the distribution of the cluster if set, see DetectDistribution and AdaptToDistribution.
+	// The resources not supported by the distribution are skipped.
+	Distribution *Distribution
+	// Lint, if set, lints the resources before applying them, see LintObjects: nothing is applied if there are
+	// findings of the error level, and the warnings are reported to the Warn of the options. Deletes are not linted.
+	Lint *LintOptions
+	// MigrateAPIVersions migrates the resources of API versions removed from the Kubernetes version of the Distribution,
+	// or from any version if it is not set, to their replacements before applying them, see MigrateAPIVersions.
+	// Nothing is applied if a resource can't be migrated, unless errors are ignored.
+	MigrateAPIVersions bool
+	// Ownership, if set, stamps the resources applied with the labels and annotations of their owner, following the
+	// LabelPolicy, or the DefaultLabelPolicy if it is not set. Deletes are not stamped.
+	Ownership   *Ownership
+	LabelPolicy *LabelPolicy
 }
 
 // ApplyManifest applies, updates or deletes resources as specified in ApplyOptions.
 // The namespace specified in ApplyOptions is used, if no namespace is specified then
 // the namespace from manifest is used.
 // If the the namespace does not exists, it will be created.
+// The resources are applied in the order of SortForApply, and deleted in the order of SortForDelete.
 func (client *Client) ApplyManifest(contents []byte, recvOptions ApplyOptions) error {
-	manifests := strings.Split(string(contents), "\n---\n")
-	if len(manifests) > 0 && manifests[len(manifests)-1] == "\n" {
-		manifests = manifests[:len(manifests)-1]
+	objects, err := decodeManifests(bytes.NewReader(contents), func(obj *unstructured.Unstructured, _ error) bool {
+		return recvOptions.IgnoreErrors || len(obj.GetObjectKind().GroupVersionKind().Kind) < 1
+	})
+	if err != nil {
+		return err
 	}
 
-	for _, manifest := range manifests {
-		// create a fresh options var at each run
-		options := recvOptions
-
-		// get the runtime.Object
-		object, obj, err := GetObjectFromManifest(manifest)
-		if err != nil {
-			if recvOptions.IgnoreErrors || len(obj.GetObjectKind().GroupVersionKind().Kind) < 1 {
-				continue
+	// Namespaces, CRDs and RBAC are applied before the workloads using them, and deleted after them.
+	sortObjects := SortForApply
+	if recvOptions.Delete {
+		sortObjects = SortForDelete
+	}
+	sorted, err := sortObjects(objects)
+	if err != nil && !recvOptions.IgnoreErrors {
+		return err
+	}
+	if err == nil {
+		objects = sorted
+	}
+	if recvOptions.MigrateAPIVersions {
+		version := ""
+		if recvOptions.Distribution != nil {
+			version = recvOptions.Distribution.Version
+		}
+		if err := APIMigrationErrors(MigrateAPIVersions(objects, version)); err != nil && !recvOptions.IgnoreErrors {
+			return err
+		}
+	}
+	if recvOptions.Ownership != nil && !recvOptions.Delete {
+		policy := labelPolicyOrDefault(recvOptions.LabelPolicy)
+		ownership := appliedNow(*recvOptions.Ownership)
+		for _, obj := range objects {
+			if err := policy.Stamp(obj, ownership); err != nil {
+				return err
 			}
-
+		}
+	}
+	if recvOptions.Lint != nil && !recvOptions.Delete {
+		if err := lintBeforeApply(objects, *recvOptions.Lint); err != nil {
 			return err
 		}
+	}
+
+	for _, obj := range objects {
+		if recvOptions.Distribution != nil && !AdaptToDistribution(obj, *recvOptions.Distribution) {
+			continue
+		}
+		// create a fresh options var at each run
+		options := recvOptions
+		var object runtime.Object = obj
 
 		helper, err := constructObject(client.KubeClient, client.RestConfig, object)
 		if err != nil {
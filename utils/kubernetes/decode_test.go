@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDecodeManifests(t *testing.T) {
+	manifests := strings.Join([]string{
+		"---",
+		"# leading separator and comment",
+		"apiVersion: v1",
+		"kind: List",
+		"items:",
+		"- apiVersion: v1",
+		"  kind: ConfigMap",
+		"  metadata:",
+		"    name: first",
+		"- apiVersion: v1",
+		"  kind: List",
+		"  items:",
+		"  - apiVersion: v1",
+		"    kind: ConfigMap",
+		"    metadata:",
+		"      name: nested",
+		"---",
+		"---",
+		"apiVersion: apps/v1",
+		"kind: Deployment",
+		"metadata:",
+		"  name: anchored",
+		"  labels: &labels",
+		"    app: anchored",
+		"spec:",
+		"  selector:",
+		"    matchLabels: *labels",
+		"  template:",
+		"    metadata:",
+		"      labels:",
+		"        <<: *labels",
+		"        version: v1",
+		"--- # separator with comment",
+		`{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "json"}}`,
+	}, "\r\n")
+
+	objects, err := DecodeManifests(strings.NewReader(manifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	if want := []string{"ConfigMap/first", "ConfigMap/nested", "Deployment/anchored", "Service/json"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("DecodeManifests() = %v; want %v", names, want)
+	}
+	deployment := objects[2].Object
+	selector := deployment["spec"].(map[string]interface{})["selector"].(map[string]interface{})["matchLabels"]
+	if !reflect.DeepEqual(selector, map[string]interface{}{"app": "anchored"}) {
+		t.Errorf("alias not resolved: %v", selector)
+	}
+	labels := deployment["spec"].(map[string]interface{})["template"].(map[string]interface{})["metadata"].(map[string]interface{})["labels"]
+	if !reflect.DeepEqual(labels, map[string]interface{}{"app": "anchored", "version": "v1"}) {
+		t.Errorf("merge key not resolved: %v", labels)
+	}
+}
+
+func TestDecodeManifestsErrors(t *testing.T) {
+	invalid := "apiVersion: v1\nkind: ConfigMap\nmetadata: [\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: valid\n"
+	if _, err := DecodeManifests(strings.NewReader(invalid)); err == nil {
+		t.Error("decoding an invalid document succeeded")
+	}
+	objects, err := decodeManifests(strings.NewReader(invalid), func(*unstructured.Unstructured, error) bool { return true })
+	if err != nil || len(objects) != 1 || objects[0].GetName() != "valid" {
+		t.Errorf("lenient decoding = %v, %v", objects, err)
+	}
+}
+
+func TestDecodeManifestsLargeStream(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&sb, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-%d\ndata:\n  value: %q\n---\n", i, strings.Repeat("x", 4096))
+	}
+	objects, err := DecodeManifests(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2000 || objects[1999].GetName() != "cm-1999" {
+		t.Errorf("decoded %d objects", len(objects))
+	}
+}
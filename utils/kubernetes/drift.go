@@ -0,0 +1,171 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils/diff"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/restmapper"
+)
+
+// DriftType is the kind of difference between the desired and the live state of an object.
+type DriftType string
+
+const (
+	// InSync objects don't differ from their desired state.
+	InSync DriftType = "InSync"
+	// Missing objects don't exist in the cluster.
+	Missing DriftType = "Missing"
+	// Modified objects differ in at least one field from their desired state.
+	Modified DriftType = "Modified"
+)
+
+// ignoredDriftFields are set by the API server, or by clients other than the one applying the desired state.
+var ignoredDriftFields = [][]string{
+	{"status"},
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "managedFields"},
+	{"metadata", "selfLink"},
+	{"metadata", "ownerReferences"},
+	{"metadata", "finalizers"},
+	{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"},
+	{"metadata", "annotations", "deployment.kubernetes.io/revision"},
+}
+
+// DriftOptions configures DetectDrift.
+type DriftOptions struct {
+	// Namespace of namespaced objects without namespace, "default" if empty.
+	Namespace string
+	// IgnoreFields are additional paths of fields not compared, e.g. {"spec", "replicas"} for autoscaled workloads.
+	IgnoreFields [][]string
+	// Mapper maps the kinds of the objects to resources. If nil, it is built using the discovery of the client.
+	Mapper meta.RESTMapper
+}
+
+// FieldDrift is a field whose live value differs from the desired value.
+// The path contains the names of the fields, and the indices or names of the items of lists, e.g. spec.template.spec.containers[istio-proxy].image.
+type FieldDrift struct {
+	Path    string      `json:"path"`
+	Desired interface{} `json:"desired,omitempty"`
+	Live    interface{} `json:"live,omitempty"`
+}
+
+// Drift is the difference between the desired and the live state of an object.
+type Drift struct {
+	APIVersion string       `json:"apiVersion"`
+	Kind       string       `json:"kind"`
+	Namespace  string       `json:"namespace,omitempty"`
+	Name       string       `json:"name"`
+	Type       DriftType    `json:"type"`
+	Fields     []FieldDrift `json:"fields,omitempty"`
+}
+
+// DetectDrift fetches the live state of the desired objects and reports their differences, one Drift per object.
+//
+// Only the fields set in the desired state are compared, so that fields defaulted by the API server don't drift.
+// Values are compared semantically: numbers regardless of their type, and quantities like "1000m" and "1" by their value.
+// Items of lists having a name, like containers, ports and env, are compared by their name instead of their position.
+func (client *Client) DetectDrift(ctx context.Context, desired []unstructured.Unstructured, opts DriftOptions) ([]Drift, error) {
+	mapper := opts.Mapper
+	if mapper == nil {
+		groupResources, err := restmapper.GetAPIGroupResources(client.KubeClient.Discovery())
+		if err != nil {
+			return nil, ErrDetectDrift(err)
+		}
+		mapper = restmapper.NewDiscoveryRESTMapper(groupResources)
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	diffOpts := diff.Options{
+		// the namespace is compared as defaulted
+		IgnorePaths:        []string{diff.JoinPath("metadata", "namespace")},
+		IgnoreAdded:        true,
+		MissingEqualsEmpty: true,
+		ListKeys:           []string{"name"},
+		Equal:              equalQuantities,
+	}
+	for _, path := range append(append([][]string{}, ignoredDriftFields...), opts.IgnoreFields...) {
+		diffOpts.IgnorePaths = append(diffOpts.IgnorePaths, diff.JoinPath(path...))
+	}
+
+	drifts := make([]Drift, 0, len(desired))
+	for i := range desired {
+		obj := &desired[i]
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, ErrDetectDrift(err)
+		}
+		drift := Drift{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName(), Type: InSync}
+		resourceClient := client.DynamicKubeClient.Resource(mapping.Resource)
+		var live *unstructured.Unstructured
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			drift.Namespace = obj.GetNamespace()
+			if drift.Namespace == "" {
+				drift.Namespace = namespace
+			}
+			live, err = resourceClient.Namespace(drift.Namespace).Get(ctx, obj.GetName(), metav1.GetOptions{})
+		} else {
+			live, err = resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		}
+		if kerrors.IsNotFound(err) {
+			drift.Type = Missing
+			drifts = append(drifts, drift)
+			continue
+		}
+		if err != nil {
+			return nil, ErrDetectDrift(err)
+		}
+
+		for _, change := range diff.Compare(obj.Object, live.Object, diffOpts) {
+			drift.Fields = append(drift.Fields, FieldDrift{Path: change.Path, Desired: change.From, Live: change.To})
+		}
+		if len(drift.Fields) > 0 {
+			drift.Type = Modified
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, nil
+}
+
+// equalQuantities compares quantities, e.g. resource requests and limits, which are normalized by the API server.
+func equalQuantities(desired, live interface{}) bool {
+	ds, dok := quantityString(desired)
+	ls, lok := quantityString(live)
+	if !dok || !lok {
+		return false
+	}
+	dq, err := resource.ParseQuantity(ds)
+	if err != nil {
+		return false
+	}
+	lq, err := resource.ParseQuantity(ls)
+	if err != nil {
+		return false
+	}
+	return dq.Cmp(lq) == 0
+}
+
+func quantityString(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t), true
+	case int64, int32, int, float64:
+		return fmt.Sprint(t), true
+	}
+	return "", false
+}
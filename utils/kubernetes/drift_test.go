@@ -0,0 +1,82 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newDriftDeployment(name string, replicas interface{}, image, cpu string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name, "labels": map[string]interface{}{}},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": image, "resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": cpu}}},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestDetectDrift(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	inSync := newDriftDeployment("in-sync", int64(2), "nginx:1.25", "1")
+	modified := newDriftDeployment("modified", int64(2), "nginx:1.25", "500m")
+	for _, live := range []*unstructured.Unstructured{&inSync, &modified} {
+		live.SetNamespace("default")
+		live.SetUID("6b6f2a1e")
+		live.SetResourceVersion("42")
+		containers, _, _ := unstructured.NestedSlice(live.Object, "spec", "template", "spec", "containers")
+		// fields defaulted by the API server, and a sidecar injected by a webhook
+		containers[0].(map[string]interface{})["imagePullPolicy"] = "IfNotPresent"
+		containers = append([]interface{}{map[string]interface{}{"name": "istio-proxy", "image": "proxyv2"}}, containers...)
+		_ = unstructured.SetNestedSlice(live.Object, containers, "spec", "template", "spec", "containers")
+		live.Object["status"] = map[string]interface{}{"replicas": int64(2)}
+	}
+	containers, _, _ := unstructured.NestedSlice(modified.Object, "spec", "template", "spec", "containers")
+	containers[1].(map[string]interface{})["image"] = "nginx:1.24"
+	_ = unstructured.SetNestedSlice(modified.Object, containers, "spec", "template", "spec", "containers")
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "DeploymentList"}, &inSync, &modified)
+	client := &Client{DynamicKubeClient: dynamicClient}
+
+	desired := []unstructured.Unstructured{
+		// the desired state uses a float for replicas and another notation of the quantity
+		newDriftDeployment("in-sync", float64(2), "nginx:1.25", "1000m"),
+		newDriftDeployment("modified", int64(2), "nginx:1.25", "0.5"),
+		newDriftDeployment("missing", int64(1), "nginx:1.25", "1"),
+	}
+	drifts, err := client.DetectDrift(context.Background(), desired, DriftOptions{Mapper: mapper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 3 {
+		t.Fatalf("got %d drifts; want 3", len(drifts))
+	}
+	if drifts[0].Type != InSync || len(drifts[0].Fields) != 0 {
+		t.Errorf("drift of in-sync = %+v", drifts[0])
+	}
+	if drifts[1].Type != Modified || len(drifts[1].Fields) != 1 {
+		t.Fatalf("drift of modified = %+v", drifts[1])
+	}
+	if f := drifts[1].Fields[0]; f.Path != "spec.template.spec.containers[app].image" || f.Desired != "nginx:1.25" || f.Live != "nginx:1.24" {
+		t.Errorf("field drift = %+v", f)
+	}
+	if drifts[2].Type != Missing || drifts[2].Namespace != "default" {
+		t.Errorf("drift of missing = %+v", drifts[2])
+	}
+}
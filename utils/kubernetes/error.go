@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/layer5io/meshkit/errors"
 )
@@ -33,8 +34,30 @@ var (
 	// ErrEntryWithChartVersionNotExistsCode represents the error which is generated
 	// when no entry is found with specified name and app version
 	ErrEntryWithChartVersionNotExistsCode = "meshkit-11204"
-	ErrEndpointNotFound                   = errors.New(ErrEndpointNotFoundCode, errors.Alert, []string{"Unable to discover an endpoint"}, []string{}, []string{}, []string{})
-	ErrInvalidAPIServer                   = errors.New(ErrInvalidAPIServerCode, errors.Alert, []string{"Invalid API Server URL"}, []string{}, []string{}, []string{})
+
+	// ErrWaitForCode represents the error which is generated when a resource
+	// doesn't become ready in time
+	ErrWaitForCode     = "meshkit-11287"
+	ErrDetectDriftCode = "meshkit-11288"
+
+	ErrCreateServiceAccountCode      = "meshkit-11289"
+	ErrBindRoleCode                  = "meshkit-11290"
+	ErrCreateServiceAccountTokenCode = "meshkit-11291"
+	ErrDeleteServiceAccountCode      = "meshkit-11292"
+	ErrManifestDependencyCycleCode   = "meshkit-11293"
+	ErrHelmValuesSchemaCode          = "meshkit-11312"
+	ErrValidateHelmValuesCode        = "meshkit-11313"
+	ErrApplyImagePullSecretCode      = "meshkit-11314"
+	ErrSetImagePullSecretsCode       = "meshkit-11315"
+	ErrCollectSupportBundleCode      = "meshkit-11316"
+	ErrDetectDistributionCode        = "meshkit-11317"
+	ErrLintFindingCode               = "meshkit-11334"
+	ErrLintManifestCode              = "meshkit-11335"
+	ErrMigrateAPIVersionsCode        = "meshkit-11336"
+	ErrLabelPolicyCode               = "meshkit-11337"
+	ErrStreamRolloutStatusCode       = "meshkit-11338"
+	ErrEndpointNotFound              = errors.New(ErrEndpointNotFoundCode, errors.Alert, []string{"Unable to discover an endpoint"}, []string{}, []string{}, []string{})
+	ErrInvalidAPIServer              = errors.New(ErrInvalidAPIServerCode, errors.Alert, []string{"Invalid API Server URL"}, []string{}, []string{}, []string{})
 )
 
 func ErrApplyManifest(err error) error {
@@ -100,3 +123,111 @@ func ErrEntryWithChartVersionNotExists(entry, appVersion string) error {
 func ErrHelmRepositoryNotFound(repo string, err error) error {
 	return errors.New(ErrHelmRepositoryNotFoundCode, errors.Alert, []string{"Helm repo not found"}, []string{fmt.Sprintf("either the repo %s does not exists or is corrupt: %v", repo, err)}, []string{}, []string{})
 }
+
+// ErrWaitFor is the error when a resource doesn't become ready before the deadline
+func ErrWaitFor(err error, resource string, reason string) error {
+	return errors.New(ErrWaitForCode, errors.Alert, []string{"Resource did not become ready"}, []string{fmt.Sprintf("%s is not ready: %s", resource, reason), err.Error()}, []string{"The resource is still being reconciled", "The controller of the resource is not running or failed to reconcile it"}, []string{"Check the status and the events of the resource", "Increase the timeout"})
+}
+
+// ErrDetectDrift is the error when the live state of the objects can't be compared to the desired state
+func ErrDetectDrift(err error) error {
+	return errors.New(ErrDetectDriftCode, errors.Alert, []string{"Unable to detect drift"}, []string{err.Error()}, []string{"The cluster is not reachable", "The kind of an object is not served by the cluster"}, []string{"Make sure the cluster is reachable", "Make sure the CRDs of the objects are installed"})
+}
+
+// ErrCreateServiceAccount is the error when a ServiceAccount or its Role can't be created
+func ErrCreateServiceAccount(err error, namespace, name string) error {
+	return errors.New(ErrCreateServiceAccountCode, errors.Alert, []string{"Unable to create the ServiceAccount"}, []string{fmt.Sprintf("ServiceAccount %s/%s or its role can't be created", namespace, name), err.Error()}, []string{"The namespace doesn't exist", "Missing permissions to manage ServiceAccounts and roles"}, []string{"Make sure the namespace exists", "Make sure the credentials used are allowed to manage ServiceAccounts, roles and bindings"})
+}
+
+// ErrBindRole is the error when a role can't be bound to a ServiceAccount
+func ErrBindRole(err error, role, namespace, serviceAccount string) error {
+	return errors.New(ErrBindRoleCode, errors.Alert, []string{"Unable to bind the role"}, []string{fmt.Sprintf("role %s can't be bound to ServiceAccount %s/%s", role, namespace, serviceAccount), err.Error()}, []string{"Missing permissions to manage bindings", "The credentials used don't have all the permissions granted by the role"}, []string{"Make sure the credentials used are allowed to manage bindings and to grant the permissions of the role"})
+}
+
+// ErrCreateServiceAccountToken is the error when a token of a ServiceAccount can't be requested
+func ErrCreateServiceAccountToken(err error, namespace, serviceAccount string) error {
+	return errors.New(ErrCreateServiceAccountTokenCode, errors.Alert, []string{"Unable to create a token of the ServiceAccount"}, []string{fmt.Sprintf("token of ServiceAccount %s/%s can't be created", namespace, serviceAccount), err.Error()}, []string{"The ServiceAccount doesn't exist", "Missing permission to create serviceaccounts/token"}, []string{"Make sure the ServiceAccount exists", "Make sure the credentials used are allowed to create tokens of ServiceAccounts"})
+}
+
+// ErrDeleteServiceAccount is the error when a ServiceAccount or its Role can't be deleted
+func ErrDeleteServiceAccount(err error, namespace, name string) error {
+	return errors.New(ErrDeleteServiceAccountCode, errors.Alert, []string{"Unable to delete the ServiceAccount"}, []string{fmt.Sprintf("ServiceAccount %s/%s or its role can't be deleted", namespace, name), err.Error()}, []string{"Missing permissions to manage ServiceAccounts and roles"}, []string{"Make sure the credentials used are allowed to manage ServiceAccounts, roles and bindings"})
+}
+
+// ErrManifestDependencyCycle is the error when the dependencies declared between objects are cyclic
+func ErrManifestDependencyCycle(objects []string) error {
+	return errors.New(ErrManifestDependencyCycleCode, errors.Alert, []string{"Cyclic dependencies between the manifests"}, []string{fmt.Sprintf("the objects %s depend on each other", strings.Join(objects, ", "))}, []string{"The meshery.io/depends-on annotations of the objects form a cycle"}, []string{"Remove the dependency annotations forming the cycle"})
+}
+
+// ErrHelmValuesSchema is returned if the values of a chart violate the schema of the chart or of its subcharts,
+// the violations are listed by path in the long description.
+func ErrHelmValuesSchema(chart string, violations []HelmValuesViolation) error {
+	descriptions := make([]string, 0, len(violations))
+	for _, v := range violations {
+		descriptions = append(descriptions, v.String())
+	}
+	return errors.New(ErrHelmValuesSchemaCode, errors.Alert, []string{fmt.Sprintf("Invalid values for the chart %s", chart)}, descriptions, []string{"The values don't match the values.schema.json of the chart or of its subcharts"}, []string{"Fix the values at the paths listed", "Check the values.schema.json of the chart for the values expected"})
+}
+
+func ErrValidateHelmValues(err error, chart string) error {
+	return errors.New(ErrValidateHelmValuesCode, errors.Alert, []string{fmt.Sprintf("Unable to validate the values of the chart %s", chart)}, []string{err.Error()}, []string{"The values.schema.json of the chart is not a valid JSON schema", "The values can't be converted to JSON"}, []string{"Make sure the values.schema.json of the chart is valid", "Skip the schema validation using SkipSchemaValidation"})
+}
+
+func ErrApplyImagePullSecret(err error, namespace, name string) error {
+	return errors.New(ErrApplyImagePullSecretCode, errors.Alert, []string{"Unable to configure the image pull secret"}, []string{fmt.Sprintf("pull secret %s/%s can't be applied", namespace, name), err.Error()}, []string{"The namespace or the ServiceAccount doesn't exist", "A secret of another type has the same name", "Missing permissions to manage secrets and ServiceAccounts"}, []string{"Make sure the namespace and the ServiceAccount exist", "Use another name for the pull secret", "Make sure the credentials used are allowed to manage secrets and ServiceAccounts"})
+}
+
+func ErrSetImagePullSecrets(err error, kind, name string) error {
+	return errors.New(ErrSetImagePullSecretsCode, errors.Alert, []string{"Unable to set the image pull secrets"}, []string{fmt.Sprintf("image pull secrets of %s %s can't be set", kind, name), err.Error()}, []string{"The pod spec of the workload or the values are malformed"}, []string{"Make sure the imagePullSecrets are a list of objects with a name"})
+}
+
+func ErrCollectSupportBundle(err error) error {
+	return errors.New(ErrCollectSupportBundleCode, errors.Alert, []string{"Unable to collect the support bundle"}, []string{err.Error()}, []string{"The bundle can't be written", "A redaction pattern is not a valid regular expression"}, []string{"Make sure the destination of the bundle is writable and has enough space", "Fix the redaction patterns"})
+}
+
+func ErrDetectDistribution(err error) error {
+	return errors.New(ErrDetectDistributionCode, errors.Alert, []string{"Unable to detect the distribution of the cluster"}, []string{err.Error()}, []string{"The cluster is not reachable", "Missing permissions to discover the API groups of the cluster"}, []string{"Make sure the cluster is reachable", "Make sure the credentials used are allowed to discover the API groups"})
+}
+
+// ErrLintFinding is a finding of LintObjects, of the Alert severity for errors and of no severity for warnings.
+func ErrLintFinding(f LintFinding) error {
+	var severity errors.Severity = errors.None
+	if f.Level == LintError {
+		severity = errors.Alert
+	}
+	return errors.New(ErrLintFindingCode, severity, []string{fmt.Sprintf("%s of %s", f.Rule, f.Object)}, []string{f.String()}, []string{lintCauses[f.Rule]}, []string{lintRemedies[f.Rule]})
+}
+
+// ErrLintManifest is returned if the manifest applied has findings of the error level.
+func ErrLintManifest(findings []string) error {
+	return errors.New(ErrLintManifestCode, errors.Alert, []string{"The manifest doesn't pass the lint rules"}, findings, []string{"The manifest has issues at a level configured to prevent applying it"}, []string{"Fix the findings, or lower the level of their rules in the lint options"})
+}
+
+// ErrMigrateAPIVersions is returned if objects of removed API versions can't be migrated to their replacements.
+func ErrMigrateAPIVersions(reasons []string) error {
+	return errors.New(ErrMigrateAPIVersionsCode, errors.Alert, []string{"Unable to migrate the objects of removed API versions"}, reasons, []string{"The conversion of the objects to their replacement API versions depends on decisions of their owners", "The API version has no replacement"}, []string{"Convert the objects by hand, following the reasons given", "Upgrade the charts or operators shipping the objects"})
+}
+
+// ErrLabelPolicy is returned if the ownership labels can't be stamped on the resources.
+func ErrLabelPolicy(err error) error {
+	return errors.New(ErrLabelPolicyCode, errors.Alert, []string{"Unable to stamp the ownership labels"}, []string{err.Error()}, []string{"A label or a value of the label policy is not valid in Kubernetes", "The pod template of a workload is not an object"}, []string{"Use label keys and values of at most 63 alphanumeric characters, '-', '_' or '.', e.g. the IDs of the design and of the user"})
+}
+
+// ErrStreamRolloutStatus is returned if the rollout of a workload can't be watched.
+func ErrStreamRolloutStatus(err error, name string) error {
+	return errors.New(ErrStreamRolloutStatusCode, errors.Alert, []string{"Unable to watch the rollout of " + name}, []string{err.Error()}, []string{"The resource is not a Deployment, a StatefulSet or a DaemonSet", "The user is not allowed to watch the resource"}, []string{"Make sure the resource is a workload and the user can watch it"})
+}
+
+var lintCauses = map[string]string{
+	LintMissingResourceLimits: "The container can use all the CPU and memory of the node, starving the other pods",
+	LintLatestImageTag:        "The image of the tag changes over time, the pods of the workload may run different versions",
+	LintPrivilegedContainer:   "The container has the privileges of the host, escaping its isolation",
+	LintDeprecatedAPIVersion:  "The API version was removed from Kubernetes, the API server rejects it",
+}
+
+var lintRemedies = map[string]string{
+	LintMissingResourceLimits: "Set resources.limits.cpu and resources.limits.memory on the container",
+	LintLatestImageTag:        "Use an image of a version tag or of a digest",
+	LintPrivilegedContainer:   "Remove securityContext.privileged, and add the capabilities the container needs instead",
+	LintDeprecatedAPIVersion:  "Migrate the object to the replacement API version",
+}
@@ -0,0 +1,159 @@
+package kubernetes
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ClusterFlavor is the distribution of Kubernetes of a cluster.
+type ClusterFlavor string
+
+const (
+	// Vanilla is any distribution without specific behaviors known to MeshKit, e.g. kind, EKS or GKE.
+	Vanilla   ClusterFlavor = "kubernetes"
+	OpenShift ClusterFlavor = "openshift"
+	K3s       ClusterFlavor = "k3s"
+	K0s       ClusterFlavor = "k0s"
+)
+
+const defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+// defaultIngressClasses are the ingress classes installed by the distributions, used if no class is marked as default.
+var defaultIngressClasses = map[ClusterFlavor]string{
+	OpenShift: "openshift-default",
+	K3s:       "traefik",
+}
+
+// Distribution describes the behaviors of the distribution of a cluster which the manifests applied have to be adapted to.
+type Distribution struct {
+	Flavor ClusterFlavor `json:"flavor"`
+	// Version is the version of the API server, e.g. v1.28.3+k3s1.
+	Version string `json:"version"`
+	// SecurityContextConstraints is set if pods are admitted by the SecurityContextConstraints of OpenShift,
+	// which assign the user and group IDs of the pods from the range of their namespace.
+	SecurityContextConstraints bool `json:"securityContextConstraints"`
+	// PodSecurityPolicy is set if PodSecurityPolicies are served, they were removed in Kubernetes 1.25.
+	PodSecurityPolicy bool `json:"podSecurityPolicy"`
+	// PodSecurityAdmission is set if the pod security standards are enforced by the labels of namespaces, since Kubernetes 1.23.
+	PodSecurityAdmission bool `json:"podSecurityAdmission"`
+	// DefaultIngressClass is the IngressClass marked as default, or the class installed by the distribution, if any.
+	DefaultIngressClass string `json:"defaultIngressClass,omitempty"`
+}
+
+// DetectDistribution detects the distribution of the cluster from the versions and API groups it serves.
+func (client *Client) DetectDistribution(ctx context.Context) (*Distribution, error) {
+	return detectDistribution(ctx, client.KubeClient)
+}
+
+func detectDistribution(ctx context.Context, kubeClient kubernetes.Interface) (*Distribution, error) {
+	version, err := kubeClient.Discovery().ServerVersion()
+	if err != nil {
+		return nil, ErrDetectDistribution(err)
+	}
+	d := &Distribution{Flavor: Vanilla, Version: version.GitVersion}
+	switch {
+	case strings.Contains(version.GitVersion, "+k3s"):
+		d.Flavor = K3s
+	case strings.Contains(version.GitVersion, "+k0s"):
+		d.Flavor = K0s
+	}
+	groups, err := kubeClient.Discovery().ServerGroups()
+	if err != nil {
+		return nil, ErrDetectDistribution(err)
+	}
+	for _, g := range groups.Groups {
+		if g.Name == "security.openshift.io" {
+			d.Flavor = OpenShift
+			d.SecurityContextConstraints = true
+		}
+	}
+
+	resources, err := kubeClient.Discovery().ServerResourcesForGroupVersion("policy/v1beta1")
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, ErrDetectDistribution(err)
+	}
+	if resources != nil {
+		for _, r := range resources.APIResources {
+			if r.Name == "podsecuritypolicies" {
+				d.PodSecurityPolicy = true
+			}
+		}
+	}
+	major, _ := strconv.Atoi(version.Major)
+	// the minor version of some providers has a suffix, e.g. 27+ on EKS
+	minor, _ := strconv.Atoi(strings.TrimRight(version.Minor, "+"))
+	d.PodSecurityAdmission = major > 1 || major == 1 && minor >= 23
+
+	classes, err := kubeClient.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil && !kerrors.IsNotFound(err) && !kerrors.IsForbidden(err) {
+		return nil, ErrDetectDistribution(err)
+	}
+	if classes != nil {
+		for _, c := range classes.Items {
+			if c.Annotations[defaultIngressClassAnnotation] == "true" {
+				d.DefaultIngressClass = c.Name
+			}
+		}
+	}
+	if d.DefaultIngressClass == "" {
+		d.DefaultIngressClass = defaultIngressClasses[d.Flavor]
+	}
+	return d, nil
+}
+
+// AdaptToDistribution adapts the object to the distribution, and returns false if the object is not supported by it
+// and has to be skipped:
+//
+// - PodSecurityPolicies are skipped if they are not served.
+//
+// - The fixed user and group IDs of pods are removed if SecurityContextConstraints assign them, as the restricted SCC rejects them.
+//
+// - Ingresses without class get the default class of the distribution, e.g. traefik on k3s.
+func AdaptToDistribution(obj *unstructured.Unstructured, d Distribution) bool {
+	kind := obj.GetKind()
+	if kind == "PodSecurityPolicy" {
+		return d.PodSecurityPolicy
+	}
+	if kind == "Ingress" && d.DefaultIngressClass != "" {
+		className, _, _ := unstructured.NestedString(obj.Object, "spec", "ingressClassName")
+		if className == "" && obj.GetAnnotations()["kubernetes.io/ingress.class"] == "" {
+			_ = unstructured.SetNestedField(obj.Object, d.DefaultIngressClass, "spec", "ingressClassName")
+		}
+	}
+	if specPath, ok := podSpecPaths[kind]; ok && d.SecurityContextConstraints {
+		removeFixedIDs(obj.Object, specPath)
+	}
+	return true
+}
+
+// removeFixedIDs removes the user and group IDs from the security contexts of the pod spec at the path and of its containers.
+func removeFixedIDs(obj map[string]interface{}, specPath []string) {
+	spec, ok, _ := unstructured.NestedMap(obj, specPath...)
+	if !ok {
+		return
+	}
+	if sc, ok := spec["securityContext"].(map[string]interface{}); ok {
+		delete(sc, "runAsUser")
+		delete(sc, "runAsGroup")
+		delete(sc, "fsGroup")
+		// supplemental groups out of the range of the namespace are rejected as well
+		delete(sc, "supplementalGroups")
+	}
+	for _, field := range []string{"containers", "initContainers"} {
+		containers, _ := spec[field].([]interface{})
+		for _, c := range containers {
+			container, _ := c.(map[string]interface{})
+			if sc, ok := container["securityContext"].(map[string]interface{}); ok {
+				delete(sc, "runAsUser")
+				delete(sc, "runAsGroup")
+			}
+		}
+	}
+	_ = unstructured.SetNestedMap(obj, spec, specPath...)
+}
@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDetectDistribution(t *testing.T) {
+	k3s := fake.NewSimpleClientset()
+	k3s.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{Major: "1", Minor: "28", GitVersion: "v1.28.3+k3s1"}
+	d, err := detectDistribution(context.Background(), k3s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Flavor != K3s || d.SecurityContextConstraints || d.PodSecurityPolicy || !d.PodSecurityAdmission || d.DefaultIngressClass != "traefik" {
+		t.Errorf("distribution = %+v", d)
+	}
+
+	openShift := fake.NewSimpleClientset(&networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{
+		Name:        "nginx",
+		Annotations: map[string]string{defaultIngressClassAnnotation: "true"},
+	}})
+	discovery := openShift.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{Major: "1", Minor: "22+", GitVersion: "v1.22.0"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "security.openshift.io/v1", APIResources: []metav1.APIResource{{Name: "securitycontextconstraints"}}},
+		{GroupVersion: "policy/v1beta1", APIResources: []metav1.APIResource{{Name: "podsecuritypolicies"}}},
+	}
+	if d, err = detectDistribution(context.Background(), openShift); err != nil {
+		t.Fatal(err)
+	}
+	if d.Flavor != OpenShift || !d.SecurityContextConstraints || !d.PodSecurityPolicy || d.PodSecurityAdmission || d.DefaultIngressClass != "nginx" {
+		t.Errorf("distribution = %+v", d)
+	}
+}
+
+func TestAdaptToDistribution(t *testing.T) {
+	d := Distribution{Flavor: OpenShift, SecurityContextConstraints: true, DefaultIngressClass: "openshift-default"}
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"securityContext": map[string]interface{}{"runAsUser": int64(1000), "fsGroup": int64(2000), "runAsNonRoot": true},
+			"containers": []interface{}{map[string]interface{}{
+				"name":            "meshery",
+				"securityContext": map[string]interface{}{"runAsUser": int64(1000), "readOnlyRootFilesystem": true},
+			}},
+		}}},
+	}}
+	if !AdaptToDistribution(deployment, d) {
+		t.Fatal("deployment skipped")
+	}
+	sc, _, _ := unstructured.NestedMap(deployment.Object, "spec", "template", "spec", "securityContext")
+	if len(sc) != 1 || sc["runAsNonRoot"] != true {
+		t.Errorf("pod securityContext = %v", sc)
+	}
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	if c := containers[0].(map[string]interface{})["securityContext"].(map[string]interface{}); len(c) != 1 {
+		t.Errorf("container securityContext = %v", c)
+	}
+
+	ingress := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Ingress", "spec": map[string]interface{}{}}}
+	AdaptToDistribution(ingress, d)
+	if class, _, _ := unstructured.NestedString(ingress.Object, "spec", "ingressClassName"); class != "openshift-default" {
+		t.Errorf("ingressClassName = %q", class)
+	}
+	if AdaptToDistribution(&unstructured.Unstructured{Object: map[string]interface{}{"kind": "PodSecurityPolicy"}}, d) {
+		t.Error("PodSecurityPolicy not skipped")
+	}
+}
@@ -0,0 +1,232 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils"
+	"github.com/layer5io/meshkit/utils/cas"
+	"github.com/layer5io/meshkit/utils/helm"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/repo"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// HelmDependencyOptions controls how the dependencies of a chart are traversed
+type HelmDependencyOptions struct {
+	// MaxDepth restricts how many levels of the dependencies which are not vendored in the charts/ directory are downloaded.
+	// 0 means that none are downloaded and a negative value means no limit. The vendored subcharts are always kept.
+	MaxDepth int
+	// HTTPClient fetches the chart, its dependencies and the indexes of their repositories, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// Store, if set, caches the chart and its dependencies by content, the dependencies being looked up by the
+	// digests of the indexes of their repositories
+	Store *cas.Store
+}
+
+func (opts HelmDependencyOptions) httpClient() *http.Client {
+	if opts.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return opts.HTTPClient
+}
+
+// fetchChart returns the path of the chart archive at the URL, looked up in the store by the digest if it is set.
+func (opts HelmDependencyOptions) fetchChart(chartURL string, digest cas.Digest) (string, error) {
+	if opts.Store == nil {
+		return fetchHelmChartWithClient(opts.httpClient(), chartURL, "")
+	}
+	path, _, err := opts.Store.FetchWith(context.Background(), chartURL, digest, cas.HTTPFetch(opts.httpClient()))
+	return path, err
+}
+
+// GetCRDsFromHelmWithDependencies returns the CRDs shipped by the chart at the given url and by its dependencies.
+//
+// Dependencies declared in Chart.yaml which are not vendored in the charts/ directory are downloaded from their
+// repository, at the version pinned in Chart.lock when there is one. Dependencies which cannot be resolved
+// (unreachable repository, oci:// or file:// references) are skipped.
+// The chart is then rendered so that CRDs shipped as templates are found as well. If rendering fails, for
+// instance because of required values, only the CRDs from the crds/ directories are returned.
+func GetCRDsFromHelmWithDependencies(url string, opts HelmDependencyOptions) (string, error) {
+	manifests, err := GetManifestsFromHelmWithDependencies(url, opts)
+	if err != nil {
+		return "", err
+	}
+	return manifests.CRDs, nil
+}
+
+// HelmChartManifests are the manifests of a chart rendered with its default values.
+type HelmChartManifests struct {
+	// CRDs shipped by the chart and its dependencies, once per name, as a multi document YAML.
+	CRDs string
+	// Resources are the other objects rendered, e.g. the custom resources the chart creates, as a multi document YAML.
+	// Empty if the chart could not be rendered.
+	Resources string
+}
+
+// GetManifestsFromHelmWithDependencies renders the chart at the given url with its default values, like
+// GetCRDsFromHelmWithDependencies, and returns the CRDs along with the other objects rendered.
+func GetManifestsFromHelmWithDependencies(url string, opts HelmDependencyOptions) (HelmChartManifests, error) {
+	chartLocation, err := opts.fetchChart(url, "")
+	if err != nil {
+		return HelmChartManifests{}, ErrApplyHelmChart(err)
+	}
+
+	ch, err := loader.Load(chartLocation)
+	if err != nil {
+		return HelmChartManifests{}, ErrApplyHelmChart(err)
+	}
+	return getManifestsFromHelmChart(ch, opts), nil
+}
+
+func getCRDsFromHelmChart(ch *chart.Chart, opts HelmDependencyOptions) string {
+	return getManifestsFromHelmChart(ch, opts).CRDs
+}
+
+func getManifestsFromHelmChart(ch *chart.Chart, opts HelmDependencyOptions) HelmChartManifests {
+	resolveHelmDependencies(ch, 1, opts)
+
+	var manifests HelmChartManifests
+	var docs []string
+	rendered, err := helm.DryRunHelmChart(ch)
+	if err == nil {
+		docs = strings.Split(string(rendered), "\n---")
+		manifests.Resources = joinNonCRDs(docs)
+	} else {
+		for _, crd := range ch.CRDObjects() {
+			docs = append(docs, string(crd.File.Data))
+		}
+	}
+	manifests.CRDs = joinUniqueCRDs(docs)
+	return manifests
+}
+
+// resolveHelmDependencies attaches the missing dependencies of ch, down to opts.MaxDepth levels
+func resolveHelmDependencies(ch *chart.Chart, depth int, opts HelmDependencyOptions) {
+	if opts.MaxDepth >= 0 && depth > opts.MaxDepth {
+		return
+	}
+
+	if ch.Metadata != nil {
+		for _, dep := range ch.Metadata.Dependencies {
+			if hasHelmDependency(ch, dep) {
+				continue
+			}
+			subchart, err := fetchHelmDependency(opts, ch, dep)
+			if err != nil {
+				continue
+			}
+			ch.AddDependency(subchart)
+		}
+	}
+	for _, subchart := range ch.Dependencies() {
+		resolveHelmDependencies(subchart, depth+1, opts)
+	}
+}
+
+func hasHelmDependency(ch *chart.Chart, dep *chart.Dependency) bool {
+	for _, subchart := range ch.Dependencies() {
+		if subchart.Name() == dep.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func fetchHelmDependency(opts HelmDependencyOptions, ch *chart.Chart, dep *chart.Dependency) (*chart.Chart, error) {
+	version := dep.Version
+	if ch.Lock != nil {
+		for _, locked := range ch.Lock.Dependencies {
+			if locked.Name == dep.Name && locked.Repository == dep.Repository {
+				version = locked.Version
+				break
+			}
+		}
+	}
+	chartURL, digest, err := findHelmChart(opts.httpClient(), dep.Repository, dep.Name, version)
+	if err != nil {
+		return nil, err
+	}
+	location, err := opts.fetchChart(chartURL, digest)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(location)
+}
+
+// FindHelmChartURL returns the URL of the version of the chart in the index of the repository, fetched with the client.
+// The version can be a constraint, the latest version matching it is returned, as repo.FindChartInRepoURL does.
+func FindHelmChartURL(client *http.Client, repository, name, version string) (string, error) {
+	chartURL, _, err := findHelmChart(client, repository, name, version)
+	return chartURL, err
+}
+
+// findHelmChart is FindHelmChartURL, also returning the digest of the chart published in the index, empty if there's none.
+func findHelmChart(client *http.Client, repository, name, version string) (string, cas.Digest, error) {
+	data, err := utils.ReadRemoteFileWithClient(client, strings.TrimSuffix(repository, "/")+"/index.yaml")
+	if err != nil {
+		return "", "", err
+	}
+	var index repo.IndexFile
+	if err := k8syaml.Unmarshal([]byte(data), &index); err != nil {
+		return "", "", err
+	}
+	index.SortEntries()
+	cv, err := index.Get(name, version)
+	if err != nil {
+		return "", "", err
+	}
+	if len(cv.URLs) == 0 {
+		return "", "", fmt.Errorf("the chart %s %s of %s has no URL", name, version, repository)
+	}
+	chartURL, err := repo.ResolveReferenceURL(repository, cv.URLs[0])
+	if err != nil {
+		return "", "", err
+	}
+	digest, _ := cas.ParseDigest(cv.Digest)
+	return chartURL, digest, nil
+}
+
+// joinUniqueCRDs keeps the CustomResourceDefinitions among docs, once per name, as a multi document YAML
+func joinUniqueCRDs(docs []string) string {
+	seen := make(map[string]struct{})
+	var manifests strings.Builder
+	for _, doc := range docs {
+		var obj struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || obj.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		if _, ok := seen[obj.Metadata.Name]; ok {
+			continue
+		}
+		seen[obj.Metadata.Name] = struct{}{}
+		manifests.WriteString("\n---\n")
+		manifests.WriteString(strings.TrimPrefix(strings.TrimSpace(doc), "---"))
+	}
+	return manifests.String()
+}
+
+// joinNonCRDs keeps the objects among docs which are not CustomResourceDefinitions, as a multi document YAML
+func joinNonCRDs(docs []string) string {
+	var manifests strings.Builder
+	for _, doc := range docs {
+		var obj struct {
+			Kind string `yaml:"kind"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || obj.Kind == "" || obj.Kind == "CustomResourceDefinition" {
+			continue
+		}
+		manifests.WriteString("\n---\n")
+		manifests.WriteString(strings.TrimPrefix(strings.TrimSpace(doc), "---"))
+	}
+	return manifests.String()
+}
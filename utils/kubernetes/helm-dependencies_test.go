@@ -0,0 +1,155 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func newTestCRD(name string) []byte {
+	return []byte(fmt.Sprintf(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: %s
+`, name))
+}
+
+// newTestChart builds root -> sub -> subsub, each shipping one CRD.
+// The CRD of sub is a template, the others are in crds/. root creates a custom resource of its CRD.
+func newTestChart() *chart.Chart {
+	root := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "root", Version: "0.1.0", APIVersion: "v2"},
+		Files:    []*chart.File{{Name: "crds/roots.example.com.yaml", Data: newTestCRD("roots.example.com")}},
+		Templates: []*chart.File{{Name: "templates/root.yaml", Data: []byte(`apiVersion: example.com/v1
+kind: Root
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  size: {{ .Values.size }}
+`)}},
+		Values: map[string]interface{}{"size": 3},
+	}
+	sub := &chart.Chart{
+		Metadata:  &chart.Metadata{Name: "sub", Version: "0.1.0", APIVersion: "v2"},
+		Templates: []*chart.File{{Name: "templates/crd.yaml", Data: newTestCRD("subs.example.com")}},
+	}
+	subsub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "subsub", Version: "0.1.0", APIVersion: "v2"},
+		Files:    []*chart.File{{Name: "crds/subsubs.example.com.yaml", Data: newTestCRD("subsubs.example.com")}},
+	}
+	sub.AddDependency(subsub)
+	root.AddDependency(sub)
+	return root
+}
+
+func TestGetCRDsFromHelmChart(t *testing.T) {
+	// the vendored subcharts are kept whatever the depth
+	for _, maxDepth := range []int{0, 1, -1} {
+		manifests := getCRDsFromHelmChart(newTestChart(), HelmDependencyOptions{MaxDepth: maxDepth})
+		if got := strings.Count(manifests, "kind: CustomResourceDefinition"); got != 3 {
+			t.Errorf("max depth %d: got %d CRDs, want 3:\n%s", maxDepth, got, manifests)
+		}
+		for _, name := range []string{"roots.example.com", "subs.example.com", "subsubs.example.com"} {
+			if !strings.Contains(manifests, "name: "+name) {
+				t.Errorf("max depth %d: CRD %s not found", maxDepth, name)
+			}
+		}
+	}
+}
+
+func TestGetCRDsFromHelmChartDownloadedDependencies(t *testing.T) {
+	// remote -> remotesub, both served by the repository
+	dir := t.TempDir()
+	remotesub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "remotesub-depth-test", Version: "0.1.0", APIVersion: "v2"},
+		Files:    []*chart.File{{Name: "crds/remotesubs.example.com.yaml", Data: newTestCRD("remotesubs.example.com")}},
+	}
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+	remote := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "remote-depth-test", Version: "0.1.0", APIVersion: "v2",
+			Dependencies: []*chart.Dependency{{Name: remotesub.Name(), Version: "0.1.0", Repository: srv.URL}},
+		},
+		Files: []*chart.File{{Name: "crds/remotes.example.com.yaml", Data: newTestCRD("remotes.example.com")}},
+	}
+	index := "apiVersion: v1\nentries:\n"
+	for _, ch := range []*chart.Chart{remote, remotesub} {
+		archive, err := chartutil.Save(ch, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the charts are cached by file name
+		cached := filepath.Join(downloadLocation, filepath.Base(archive))
+		os.Remove(cached)
+		t.Cleanup(func() { os.Remove(cached) })
+		index += fmt.Sprintf("  %s:\n  - name: %s\n    version: 0.1.0\n    urls: [%s]\n", ch.Name(), ch.Name(), filepath.Base(archive))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		maxDepth int
+		want     []string
+	}{
+		{0, []string{"roots.example.com"}},
+		{1, []string{"roots.example.com", "remotes.example.com"}},
+		{-1, []string{"roots.example.com", "remotes.example.com", "remotesubs.example.com"}},
+	}
+	for _, tt := range tests {
+		root := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name: "root", Version: "0.1.0", APIVersion: "v2",
+				Dependencies: []*chart.Dependency{{Name: remote.Name(), Version: "0.1.0", Repository: srv.URL}},
+			},
+			Files: []*chart.File{{Name: "crds/roots.example.com.yaml", Data: newTestCRD("roots.example.com")}},
+		}
+		manifests := getCRDsFromHelmChart(root, HelmDependencyOptions{MaxDepth: tt.maxDepth, HTTPClient: srv.Client()})
+		if got := strings.Count(manifests, "kind: CustomResourceDefinition"); got != len(tt.want) {
+			t.Errorf("max depth %d: got %d CRDs, want %d:\n%s", tt.maxDepth, got, len(tt.want), manifests)
+		}
+		for _, name := range tt.want {
+			if !strings.Contains(manifests, "name: "+name) {
+				t.Errorf("max depth %d: CRD %s not found", tt.maxDepth, name)
+			}
+		}
+	}
+}
+
+func TestGetManifestsFromHelmChart(t *testing.T) {
+	manifests := getManifestsFromHelmChart(newTestChart(), HelmDependencyOptions{})
+	if strings.Contains(manifests.Resources, "CustomResourceDefinition") {
+		t.Errorf("expected the resources not to contain CRDs:\n%s", manifests.Resources)
+	}
+	if !strings.Contains(manifests.Resources, "kind: Root") || !strings.Contains(manifests.Resources, "size: 3") {
+		t.Errorf("expected the custom resource rendered with the default values:\n%s", manifests.Resources)
+	}
+}
+
+func TestFindHelmChartURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "apiVersion: v1\nentries:\n  sub:\n  - name: sub\n    version: 1.2.0\n    urls: [charts/sub-1.2.0.tgz]\n  - name: sub\n    version: 1.1.0\n    urls: [https://cdn.example.com/sub-1.1.0.tgz]\n")
+	}))
+	defer srv.Close()
+
+	for version, want := range map[string]string{
+		"":       srv.URL + "/charts/sub-1.2.0.tgz",
+		"~1.1.0": "https://cdn.example.com/sub-1.1.0.tgz",
+	} {
+		got, err := FindHelmChartURL(srv.Client(), srv.URL, "sub", version)
+		if err != nil || got != want {
+			t.Errorf("version %q: got %q, %v; want %q", version, got, err, want)
+		}
+	}
+	if _, err := FindHelmChartURL(srv.Client(), srv.URL, "sub", "2.0.0"); err == nil {
+		t.Error("expected an error finding a missing version")
+	}
+}
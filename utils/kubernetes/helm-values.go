@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+// HelmValuesViolation is a value violating the values.schema.json of a chart or of one of its subcharts.
+type HelmValuesViolation struct {
+	// Chart is the chart whose schema is violated, the names of its parent charts included, e.g. meshery/meshery-istio.
+	Chart string
+	// Path is the path of the value in the values of the chart installed, e.g. meshery-istio.image.tag,
+	// empty if the values themselves are violating the schema.
+	Path string
+	// Description describes the violation, e.g. "Invalid type. Expected: string, given: integer".
+	Description string
+}
+
+func (v HelmValuesViolation) String() string {
+	if v.Path == "" {
+		return v.Chart + ": " + v.Description
+	}
+	return v.Chart + ": " + v.Path + ": " + v.Description
+}
+
+// ValidateHelmValues validates the values, merged with the default values of the chart, against the values.schema.json
+// of the chart and of its enabled subcharts, if any. The error lists the violations by path, see ErrHelmValuesSchema.
+// As when installing the chart, the subcharts disabled by the values are removed from the dependencies of the chart.
+//
+// ApplyHelmChart validates the values before installing and upgrading charts, unless SkipSchemaValidation is set.
+func ValidateHelmValues(ch *chart.Chart, values map[string]interface{}) error {
+	if err := chartutil.ProcessDependencies(ch, values); err != nil {
+		return ErrValidateHelmValues(err, ch.Name())
+	}
+	coalesced, err := chartutil.CoalesceValues(ch, values)
+	if err != nil {
+		return ErrValidateHelmValues(err, ch.Name())
+	}
+	violations, err := schemaViolations(ch, coalesced, ch.Name(), "")
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return ErrHelmValuesSchema(ch.Name(), violations)
+	}
+	return nil
+}
+
+// schemaViolations returns the violations of the schemas of the chart and of its subcharts by the values of the chart.
+func schemaViolations(ch *chart.Chart, values map[string]interface{}, name, prefix string) ([]HelmValuesViolation, error) {
+	var violations []HelmValuesViolation
+	if len(ch.Schema) > 0 {
+		result, err := validateValues(ch.Schema, values)
+		if err != nil {
+			return nil, ErrValidateHelmValues(err, name)
+		}
+		for _, e := range result.Errors() {
+			path := prefix
+			if field := e.Field(); field != gojsonschema.STRING_CONTEXT_ROOT {
+				path += field
+			}
+			violations = append(violations, HelmValuesViolation{Chart: name, Path: strings.TrimSuffix(path, "."), Description: e.Description()})
+		}
+		sort.SliceStable(violations, func(i, j int) bool { return violations[i].Path < violations[j].Path })
+	}
+	for _, subchart := range ch.Dependencies() {
+		subchartValues, _ := values[subchart.Name()].(map[string]interface{})
+		subViolations, err := schemaViolations(subchart, subchartValues, name+"/"+subchart.Name(), prefix+subchart.Name()+".")
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, subViolations...)
+	}
+	return violations, nil
+}
+
+// validateValues validates the values against the schema, the values are converted to JSON as Helm does.
+func validateValues(schema []byte, values map[string]interface{}) (*gojsonschema.Result, error) {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	valuesJSON, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(valuesJSON, []byte("null")) {
+		valuesJSON = []byte("{}")
+	}
+	return gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(valuesJSON))
+}
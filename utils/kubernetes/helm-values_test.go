@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func newSchemaTestChart() *chart.Chart {
+	root := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "meshery", Version: "0.1.0", APIVersion: "v2"},
+		Values:   map[string]interface{}{"replicas": 1},
+		Schema: []byte(`{
+  "type": "object",
+  "properties": {
+    "replicas": {"type": "integer", "minimum": 1},
+    "image": {"type": "object", "required": ["tag"], "properties": {"tag": {"type": "string"}}}
+  }
+}`),
+	}
+	adapter := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "meshery-istio", Version: "0.1.0", APIVersion: "v2"},
+		Values:   map[string]interface{}{"port": 10000},
+		Schema:   []byte(`{"type": "object", "properties": {"port": {"type": "integer"}}}`),
+	}
+	root.AddDependency(adapter)
+	return root
+}
+
+func TestValidateHelmValues(t *testing.T) {
+	ch := newSchemaTestChart()
+	if err := ValidateHelmValues(ch, nil); err != nil {
+		t.Fatalf("the default values are valid, got %v", err)
+	}
+	if err := ValidateHelmValues(ch, map[string]interface{}{"image": map[string]interface{}{"tag": "stable"}}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err := ValidateHelmValues(ch, map[string]interface{}{
+		"replicas":      0,
+		"image":         map[string]interface{}{},
+		"meshery-istio": map[string]interface{}{"port": "10000"},
+	})
+	if errors.GetCode(err) != ErrHelmValuesSchemaCode {
+		t.Fatalf("err = %v; want %s", err, ErrHelmValuesSchemaCode)
+	}
+	want := []string{
+		"meshery: image: tag is required",
+		"meshery: replicas: Must be greater than or equal to 1",
+		"meshery/meshery-istio: meshery-istio.port: Invalid type. Expected: integer, given: string",
+	}
+	if got := err.(*errors.Error).LongDescription; !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %q; want %q", got, want)
+	}
+}
+
+func TestValidateHelmValuesDisabledSubchart(t *testing.T) {
+	ch := newSchemaTestChart()
+	ch.Metadata.Dependencies = []*chart.Dependency{{Name: "meshery-istio", Version: "0.1.0", Condition: "meshery-istio.enabled"}}
+	values := map[string]interface{}{"meshery-istio": map[string]interface{}{"enabled": false, "port": "10000"}}
+	if err := ValidateHelmValues(ch, values); err != nil {
+		t.Fatalf("the values of disabled subcharts are not validated, got %v", err)
+	}
+
+	ch = newSchemaTestChart()
+	ch.Metadata.Dependencies = []*chart.Dependency{{Name: "meshery-istio", Version: "0.1.0", Condition: "meshery-istio.enabled"}}
+	values = map[string]interface{}{"meshery-istio": map[string]interface{}{"enabled": true, "port": "10000"}}
+	if err := ValidateHelmValues(ch, values); errors.GetCode(err) != ErrHelmValuesSchemaCode {
+		t.Errorf("err = %v; want %s", err, ErrHelmValuesSchemaCode)
+	}
+}
+
+func TestValidateHelmValuesInvalidSchema(t *testing.T) {
+	ch := newSchemaTestChart()
+	ch.Schema = []byte(`{"type": "object", "properties": {"replicas": {"type": 1}}}`)
+	if err := ValidateHelmValues(ch, nil); errors.GetCode(err) != ErrValidateHelmValuesCode {
+		t.Errorf("err = %v; want %s", err, ErrValidateHelmValuesCode)
+	}
+}
+
+func TestHelmValuesViolation(t *testing.T) {
+	v := HelmValuesViolation{Chart: "meshery", Description: "Invalid type. Expected: object, given: array"}
+	if got, want := v.String(), "meshery: Invalid type. Expected: object, given: array"; got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultImagePullSecretsValuesKey is the key of the pull secrets in the values of most charts, the Meshery charts included.
+const DefaultImagePullSecretsValuesKey = "imagePullSecrets"
+
+// RegistryCredentials are the credentials of a private container registry.
+type RegistryCredentials struct {
+	// Server is the host of the registry, e.g. ghcr.io or registry.example.com:5000.
+	Server   string
+	Username string
+	Password string
+	Email    string
+}
+
+// ImagePullSecretOptions describes a Secret of type kubernetes.io/dockerconfigjson holding the credentials of registries.
+type ImagePullSecretOptions struct {
+	Name      string
+	Namespace string
+	// Labels are added to the labels of the Secret.
+	//
+	// Deprecated: set the Ownership instead, the extra labels being the ones of its LabelPolicy.
+	Labels     map[string]string
+	Registries []RegistryCredentials
+	// Ownership, if set, stamps the Secret with the labels and annotations of its owner, following the LabelPolicy,
+	// or the DefaultLabelPolicy if it is not set.
+	Ownership   *Ownership
+	LabelPolicy *LabelPolicy
+}
+
+// label adds the labels of the options and stamps the ownership on the metadata of the object.
+func (opts ImagePullSecretOptions) label(obj metav1.Object) error {
+	return labelObject(obj, opts.Labels, opts.Ownership, opts.LabelPolicy)
+}
+
+// ApplyImagePullSecret creates the pull Secret, or updates it so that it holds the credentials of the registries of the options.
+// The labels of the options and of the ownership are added to the labels of an existing Secret, which are kept.
+func ApplyImagePullSecret(ctx context.Context, client kubernetes.Interface, opts ImagePullSecretOptions) (*corev1.Secret, error) {
+	config, err := dockerConfigJSON(opts.Registries)
+	if err != nil {
+		return nil, ErrApplyImagePullSecret(err, opts.Namespace, opts.Name)
+	}
+	if opts.Ownership != nil {
+		ownership := appliedNow(*opts.Ownership)
+		opts.Ownership = &ownership
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: opts.Namespace},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: config},
+	}
+	if err := opts.label(secret); err != nil {
+		return nil, err
+	}
+	secrets := client.CoreV1().Secrets(opts.Namespace)
+	existing, err := secrets.Get(ctx, opts.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		created, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+		if err != nil {
+			return nil, ErrApplyImagePullSecret(err, opts.Namespace, opts.Name)
+		}
+		return created, nil
+	}
+	if err != nil {
+		return nil, ErrApplyImagePullSecret(err, opts.Namespace, opts.Name)
+	}
+	if existing.Type != corev1.SecretTypeDockerConfigJson {
+		return nil, ErrApplyImagePullSecret(fmt.Errorf("the existing secret is of type %s", existing.Type), opts.Namespace, opts.Name)
+	}
+	if err := opts.label(existing); err != nil {
+		return nil, err
+	}
+	existing.Data = secret.Data
+	updated, err := secrets.Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, ErrApplyImagePullSecret(err, opts.Namespace, opts.Name)
+	}
+	return updated, nil
+}
+
+// dockerConfigJSON returns the content of a .dockerconfigjson file holding the credentials.
+func dockerConfigJSON(registries []RegistryCredentials) ([]byte, error) {
+	type auth struct {
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+		Email    string `json:"email,omitempty"`
+		Auth     string `json:"auth,omitempty"`
+	}
+	auths := make(map[string]auth, len(registries))
+	for _, r := range registries {
+		if r.Server == "" {
+			return nil, fmt.Errorf("the server of a registry is empty")
+		}
+		auths[r.Server] = auth{
+			Username: r.Username,
+			Password: r.Password,
+			Email:    r.Email,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(r.Username + ":" + r.Password)),
+		}
+	}
+	return json.Marshal(map[string]interface{}{"auths": auths})
+}
+
+// AddImagePullSecretToServiceAccount adds the pull Secret to the ServiceAccount, so that the pods running as the ServiceAccount
+// pull their images using it. Secrets already referenced are not added again.
+func AddImagePullSecretToServiceAccount(ctx context.Context, client kubernetes.Interface, namespace, serviceAccount, secret string) error {
+	accounts := client.CoreV1().ServiceAccounts(namespace)
+	sa, err := accounts.Get(ctx, serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return ErrApplyImagePullSecret(err, namespace, secret)
+	}
+	for _, ref := range sa.ImagePullSecrets {
+		if ref.Name == secret {
+			return nil
+		}
+	}
+	sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	if _, err := accounts.Update(ctx, sa, metav1.UpdateOptions{}); err != nil {
+		return ErrApplyImagePullSecret(err, namespace, secret)
+	}
+	return nil
+}
+
+// podSpecPaths are the paths of the pod specs of the workloads, by kind.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// SetImagePullSecrets adds the pull Secrets to the pod spec of the workload, e.g. to the Deployments of a generated manifest.
+// Secrets already referenced are not added again. Objects which are not workloads are left unchanged, the error is only returned
+// if the pod spec of a workload is malformed.
+func SetImagePullSecrets(obj *unstructured.Unstructured, secrets ...string) error {
+	path, ok := podSpecPaths[obj.GetKind()]
+	if !ok {
+		return nil
+	}
+	fields := append(append([]string{}, path...), "imagePullSecrets")
+	refs, _, err := unstructured.NestedSlice(obj.Object, fields...)
+	if err != nil {
+		return ErrSetImagePullSecrets(err, obj.GetKind(), obj.GetName())
+	}
+	refs = appendSecretRefs(refs, secrets)
+	if err := unstructured.SetNestedSlice(obj.Object, refs, fields...); err != nil {
+		return ErrSetImagePullSecrets(err, obj.GetKind(), obj.GetName())
+	}
+	return nil
+}
+
+// SetImagePullSecretsValues adds the pull Secrets to the values of a chart, at the key given as a dotted path,
+// DefaultImagePullSecretsValuesKey if empty, e.g. global.imagePullSecrets. Secrets already referenced are not added again.
+// The values are modified in place and returned, a new map is returned if values is nil.
+func SetImagePullSecretsValues(values map[string]interface{}, key string, secrets ...string) (map[string]interface{}, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	if key == "" {
+		key = DefaultImagePullSecretsValuesKey
+	}
+	path := strings.Split(key, ".")
+	parent := values
+	for _, k := range path[:len(path)-1] {
+		next, ok := parent[k].(map[string]interface{})
+		if !ok {
+			if parent[k] != nil {
+				return nil, ErrSetImagePullSecrets(fmt.Errorf("%s is of type %T, not a map", k, parent[k]), "values", key)
+			}
+			next = map[string]interface{}{}
+			parent[k] = next
+		}
+		parent = next
+	}
+	last := path[len(path)-1]
+	existing, ok := parent[last].([]interface{})
+	if parent[last] != nil && !ok {
+		return nil, ErrSetImagePullSecrets(fmt.Errorf("%s is of type %T, not a list", key, parent[last]), "values", key)
+	}
+	parent[last] = appendSecretRefs(existing, secrets)
+	return values, nil
+}
+
+// appendSecretRefs appends the references to the secrets missing from refs, a list of objects with a name.
+// Charts also commonly accept secret names, which are matched too.
+func appendSecretRefs(refs []interface{}, secrets []string) []interface{} {
+	names := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		switch r := ref.(type) {
+		case map[string]interface{}:
+			if name, ok := r["name"].(string); ok {
+				names[name] = true
+			}
+		case string:
+			names[r] = true
+		}
+	}
+	for _, secret := range secrets {
+		if !names[secret] {
+			names[secret] = true
+			refs = append(refs, map[string]interface{}{"name": secret})
+		}
+	}
+	return refs
+}
@@ -0,0 +1,115 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyImagePullSecret(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "meshery-operator", Namespace: "meshery"}})
+	opts := ImagePullSecretOptions{
+		Name:       "registry",
+		Namespace:  "meshery",
+		Labels:     map[string]string{"app.kubernetes.io/managed-by": "meshery"},
+		Registries: []RegistryCredentials{{Server: "registry.example.com", Username: "meshery", Password: "old"}},
+		Ownership:  &Ownership{DesignID: "8a3b5e5e-4bd0-4d3c-a6b0-6e3f0d1c2a11", UserName: "Jane Doe"},
+	}
+	if _, err := ApplyImagePullSecret(ctx, client, opts); err != nil {
+		t.Fatal(err)
+	}
+	// labels set by others are kept
+	existing, err := client.CoreV1().Secrets("meshery").Get(ctx, "registry", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing.Labels["team"] = "platform"
+	if _, err := client.CoreV1().Secrets("meshery").Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// applying it again updates the credentials
+	opts.Registries[0].Password = "new"
+	secret, err := ApplyImagePullSecret(ctx, client, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Auths map[string]struct{ Password, Auth string }
+	}
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		t.Fatal(err)
+	}
+	if auth := config.Auths["registry.example.com"]; secret.Type != corev1.SecretTypeDockerConfigJson || auth.Password != "new" || auth.Auth != "bWVzaGVyeTpuZXc=" {
+		t.Errorf("secret = %+v", secret)
+	}
+	if secret.Labels["team"] != "platform" || secret.Labels["app.kubernetes.io/managed-by"] != "meshery" || secret.Labels[DesignIDLabel] != opts.Ownership.DesignID {
+		t.Errorf("labels = %v", secret.Labels)
+	}
+	if secret.Annotations[AppliedByAnnotation] != "Jane Doe" || secret.Annotations[AppliedAtAnnotation] == "" {
+		t.Errorf("annotations = %v", secret.Annotations)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := AddImagePullSecretToServiceAccount(ctx, client, "meshery", "meshery-operator", "registry"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sa, err := client.CoreV1().ServiceAccounts("meshery").Get(ctx, "meshery-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sa.ImagePullSecrets) != 1 || sa.ImagePullSecrets[0].Name != "registry" {
+		t.Errorf("imagePullSecrets = %+v", sa.ImagePullSecrets)
+	}
+
+	if _, err := ApplyImagePullSecret(ctx, client, ImagePullSecretOptions{Name: "empty", Namespace: "meshery", Registries: []RegistryCredentials{{}}}); err == nil {
+		t.Error("expected an error for a registry without server")
+	}
+}
+
+func TestSetImagePullSecrets(t *testing.T) {
+	cronJob := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata":   map[string]interface{}{"name": "meshsync"},
+		"spec": map[string]interface{}{"jobTemplate": map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"imagePullSecrets": []interface{}{map[string]interface{}{"name": "registry"}},
+		}}}}},
+	}}
+	if err := SetImagePullSecrets(cronJob, "registry", "mirror"); err != nil {
+		t.Fatal(err)
+	}
+	refs, _, _ := unstructured.NestedSlice(cronJob.Object, "spec", "jobTemplate", "spec", "template", "spec", "imagePullSecrets")
+	if want := []interface{}{map[string]interface{}{"name": "registry"}, map[string]interface{}{"name": "mirror"}}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("imagePullSecrets = %v; want %v", refs, want)
+	}
+
+	service := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Service", "spec": map[string]interface{}{}}}
+	if err := SetImagePullSecrets(service, "registry"); err != nil || len(service.Object["spec"].(map[string]interface{})) != 0 {
+		t.Errorf("service = %v, %v; want it unchanged", service.Object, err)
+	}
+}
+
+func TestSetImagePullSecretsValues(t *testing.T) {
+	values, err := SetImagePullSecretsValues(map[string]interface{}{"global": map[string]interface{}{"imagePullSecrets": []interface{}{"registry"}}}, "global.imagePullSecrets", "registry", "mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"global": map[string]interface{}{"imagePullSecrets": []interface{}{"registry", map[string]interface{}{"name": "mirror"}}}}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v; want %v", values, want)
+	}
+	if values, err = SetImagePullSecretsValues(nil, "", "registry"); err != nil || len(values[DefaultImagePullSecretsValuesKey].([]interface{})) != 1 {
+		t.Errorf("values = %v, %v", values, err)
+	}
+	if _, err := SetImagePullSecretsValues(map[string]interface{}{"imagePullSecrets": "registry"}, "", "registry"); err == nil {
+		t.Error("expected an error for values which are not a list")
+	}
+}
@@ -0,0 +1,221 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Rules of LintObjects.
+const (
+	LintMissingResourceLimits = "missing-resource-limits"
+	LintLatestImageTag        = "latest-image-tag"
+	LintPrivilegedContainer   = "privileged-container"
+	LintDeprecatedAPIVersion  = "deprecated-api-version"
+)
+
+// LintLevel is the level of the findings of a rule.
+type LintLevel int
+
+const (
+	// LintOff disables the rule.
+	LintOff LintLevel = iota
+	// LintWarning reports the findings of the rule, which don't prevent applying the manifest.
+	LintWarning
+	// LintError reports the findings of the rule, which prevent applying the manifest.
+	LintError
+)
+
+func (l LintLevel) String() string {
+	switch l {
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+	return "off"
+}
+
+// DefaultLintLevels are the levels of the rules unless configured otherwise.
+var DefaultLintLevels = map[string]LintLevel{
+	LintMissingResourceLimits: LintWarning,
+	LintLatestImageTag:        LintWarning,
+	LintPrivilegedContainer:   LintWarning,
+	LintDeprecatedAPIVersion:  LintWarning,
+}
+
+// LintOptions configures the rules of LintObjects.
+type LintOptions struct {
+	// Levels override the DefaultLintLevels of the rules, e.g. LintError for privileged containers.
+	Levels map[string]LintLevel
+	// KubernetesVersion is the version of the cluster, e.g. v1.28.3 as in Distribution.Version. If set, the API versions
+	// removed in this version are reported as errors, whatever the level of the rule, as applying them fails.
+	KubernetesVersion string
+	// Warn, if set, is called with the warnings found before applying a manifest, e.g. the Warn of a logger.Handler.
+	Warn func(error)
+}
+
+// LintFinding is an issue found in an object by a rule.
+type LintFinding struct {
+	Rule  string
+	Level LintLevel
+	// Object is the object of the finding, as Kind/namespace/name or Kind/name.
+	Object string
+	// Container is the name of the container of the finding, if any.
+	Container string
+	Message   string
+}
+
+func (f LintFinding) String() string {
+	subject := f.Object
+	if f.Container != "" {
+		subject += " container " + f.Container
+	}
+	return fmt.Sprintf("%s: %s (%s)", subject, f.Message, f.Rule)
+}
+
+// Err returns the finding as a MeshKit error, of the Alert severity for errors and of no severity for warnings.
+func (f LintFinding) Err() error {
+	return ErrLintFinding(f)
+}
+
+// LintManifest decodes the objects of the manifest and lints them, see LintObjects.
+func LintManifest(manifest []byte, opts LintOptions) ([]LintFinding, error) {
+	objects, err := decodeManifests(bytes.NewReader(manifest), func(obj *unstructured.Unstructured, _ error) bool {
+		return len(obj.GetObjectKind().GroupVersionKind().Kind) < 1
+	})
+	if err != nil {
+		return nil, err
+	}
+	return LintObjects(objects, opts), nil
+}
+
+// LintObjects reports the issues of the objects which are likely to break or weaken a deployment, before applying them:
+// containers without CPU or memory limits, images of the latest tag or of no tag, privileged containers and API versions
+// removed from Kubernetes. The findings are ordered by object, in the order of the objects.
+func LintObjects(objects []*unstructured.Unstructured, opts LintOptions) []LintFinding {
+	levels := make(map[string]LintLevel, len(DefaultLintLevels))
+	for rule, level := range DefaultLintLevels {
+		levels[rule] = level
+	}
+	for rule, level := range opts.Levels {
+		levels[rule] = level
+	}
+	var findings []LintFinding
+	for _, obj := range objects {
+		report := func(rule, container, message string) {
+			if levels[rule] == LintOff {
+				return
+			}
+			findings = append(findings, LintFinding{Rule: rule, Level: levels[rule], Object: objectRef(obj), Container: container, Message: message})
+		}
+		if api, ok := deprecatedAPIs[obj.GetAPIVersion()+"/"+obj.GetKind()]; ok && levels[LintDeprecatedAPIVersion] != LintOff {
+			finding := LintFinding{
+				Rule:    LintDeprecatedAPIVersion,
+				Level:   levels[LintDeprecatedAPIVersion],
+				Object:  objectRef(obj),
+				Message: fmt.Sprintf("%s is removed in Kubernetes %s, use %s", obj.GetAPIVersion(), api.removedIn, api.replacement),
+			}
+			if opts.KubernetesVersion != "" && !versionBefore(opts.KubernetesVersion, api.removedIn) {
+				finding.Level = LintError
+			}
+			findings = append(findings, finding)
+		}
+		specPath, ok := podSpecPaths[obj.GetKind()]
+		if !ok {
+			continue
+		}
+		spec, _, _ := unstructured.NestedMap(obj.Object, specPath...)
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, _ := spec[field].([]interface{})
+			for _, c := range containers {
+				container, _ := c.(map[string]interface{})
+				name, _ := container["name"].(string)
+				limits, _, _ := unstructured.NestedMap(container, "resources", "limits")
+				var missing []string
+				for _, resource := range []string{"cpu", "memory"} {
+					if _, ok := limits[resource]; !ok {
+						missing = append(missing, resource)
+					}
+				}
+				if len(missing) > 0 {
+					report(LintMissingResourceLimits, name, "no "+strings.Join(missing, " and ")+" limit")
+				}
+				if image, _ := container["image"].(string); latestTag(image) {
+					report(LintLatestImageTag, name, fmt.Sprintf("the image %q is not pinned to a version, pods may run different images", image))
+				}
+				if privileged, _, _ := unstructured.NestedBool(container, "securityContext", "privileged"); privileged {
+					report(LintPrivilegedContainer, name, "privileged, with access to all the devices of the node")
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// LintErrors returns the findings of the error level combined in a MeshKit error, nil if there is none.
+func LintErrors(findings []LintFinding) error {
+	var errs []string
+	for _, f := range findings {
+		if f.Level == LintError {
+			errs = append(errs, f.String())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return ErrLintManifest(errs)
+}
+
+// lintBeforeApply lints the objects before they are applied, reporting the warnings and failing on the errors.
+func lintBeforeApply(objects []*unstructured.Unstructured, opts LintOptions) error {
+	findings := LintObjects(objects, opts)
+	if opts.Warn != nil {
+		for _, f := range findings {
+			if f.Level == LintWarning {
+				opts.Warn(f.Err())
+			}
+		}
+	}
+	return LintErrors(findings)
+}
+
+// latestTag reports whether the image has the latest tag or no tag, and no digest.
+func latestTag(image string) bool {
+	if image == "" || strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i < 0 || name[i+1:] == "latest"
+}
+
+func objectRef(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() != "" {
+		return obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+	}
+	return obj.GetKind() + "/" + obj.GetName()
+}
+
+// versionBefore reports whether the version, e.g. v1.28.3+k3s1, is before the minor version, e.g. 1.22.
+// Unparsable versions are not before any version.
+func versionBefore(version, minor string) bool {
+	parse := func(v string) (int, int, bool) {
+		parts := strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3)
+		if len(parts) < 2 {
+			return 0, 0, false
+		}
+		major, err1 := strconv.Atoi(parts[0])
+		minorVersion, err2 := strconv.Atoi(strings.TrimRight(parts[1], "+"))
+		return major, minorVersion, err1 == nil && err2 == nil
+	}
+	major, minorVersion, ok := parse(version)
+	removedMajor, removedMinor, _ := parse(minor)
+	if !ok {
+		return false
+	}
+	return major < removedMajor || major == removedMajor && minorVersion < removedMinor
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const lintManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: meshery
+  namespace: meshery
+spec:
+  template:
+    spec:
+      containers:
+      - name: meshery
+        image: meshery/meshery:v0.7.0
+        resources:
+          limits: {cpu: 500m, memory: 1Gi}
+      - name: proxy
+        image: envoyproxy/envoy
+        securityContext:
+          privileged: true
+        resources:
+          limits: {memory: 256Mi}
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: meshery
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  containers:
+  - name: shell
+    image: localhost:5000/busybox@sha256:3fbc632167424a6d997e74f52b878d7cc478225cffac6bc977eedfe51c7f4e79
+    resources:
+      limits: {cpu: 100m, memory: 64Mi}
+`
+
+func TestLintManifest(t *testing.T) {
+	findings, err := LintManifest([]byte(lintManifest), LintOptions{Levels: map[string]LintLevel{LintPrivilegedContainer: LintError}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LintFinding{
+		{Rule: LintMissingResourceLimits, Level: LintWarning, Object: "Deployment/meshery/meshery", Container: "proxy"},
+		{Rule: LintLatestImageTag, Level: LintWarning, Object: "Deployment/meshery/meshery", Container: "proxy"},
+		{Rule: LintPrivilegedContainer, Level: LintError, Object: "Deployment/meshery/meshery", Container: "proxy"},
+		{Rule: LintDeprecatedAPIVersion, Level: LintWarning, Object: "Ingress/meshery"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d findings, got %v", len(want), findings)
+	}
+	for i, f := range findings {
+		f.Message = ""
+		if f != want[i] {
+			t.Errorf("finding %d = %+v; want %+v", i, f, want[i])
+		}
+	}
+	if code := errors.GetCode(LintErrors(findings)); code != ErrLintManifestCode {
+		t.Errorf("expected the privileged container to fail the manifest, got %q", code)
+	}
+	if errors.GetSeverity(findings[0].Err()) != errors.None {
+		t.Error("expected the warnings to have no severity")
+	}
+
+	findings, err = LintManifest([]byte(lintManifest), LintOptions{
+		KubernetesVersion: "v1.22.0",
+		Levels:            map[string]LintLevel{LintMissingResourceLimits: LintOff, LintLatestImageTag: LintOff, LintPrivilegedContainer: LintOff},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Level != LintError {
+		t.Errorf("expected the API version removed in the version of the cluster to be an error, got %v", findings)
+	}
+	if findings, _ := LintManifest([]byte(lintManifest), LintOptions{KubernetesVersion: "v1.21.5", Levels: map[string]LintLevel{LintMissingResourceLimits: LintOff}}); findings[len(findings)-1].Level != LintWarning {
+		t.Errorf("expected the API version still served to be a warning, got %v", findings)
+	}
+}
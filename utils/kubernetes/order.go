@@ -0,0 +1,243 @@
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// DependsOnAnnotation lists the objects an object depends on, separated by commas, as Kind/name or Kind/namespace/name,
+// e.g. "CustomResourceDefinition/gateways.gateway.networking.k8s.io, Deployment/istio-system/istiod".
+// The object is applied after, and deleted before, the objects it depends on.
+// Objects not part of the manifests are assumed to exist.
+const DependsOnAnnotation = "meshery.io/depends-on"
+
+// ApplyOrder is the order kinds are applied in. Kinds not listed, e.g. custom resources, are applied last.
+var ApplyOrder = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"PriorityClass",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+// SplitManifests splits a multi-document YAML into its documents, skipping empty documents and documents of comments only.
+func SplitManifests(contents []byte) ([]string, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(contents)))
+	var manifests []string
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, ErrApplyManifest(err)
+		}
+		if isEmptyManifest(doc) {
+			continue
+		}
+		manifests = append(manifests, string(bytes.ReplaceAll(doc, []byte("\r\n"), []byte("\n"))))
+	}
+}
+
+// DecodeManifests decodes the objects of a stream of YAML or JSON documents, reading one document at a time.
+// Documents can be separated by "---" lines, leading separators, empty documents and comments are ignored,
+// and CRLF line endings and YAML anchors are supported.
+// The items of List objects, e.g. as returned by kubectl get -o yaml, are returned instead of the lists.
+func DecodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	return decodeManifests(r, func(*unstructured.Unstructured, error) bool { return false })
+}
+
+// decodeManifests decodes the objects of the stream. Documents which can't be decoded are skipped if skip returns true,
+// otherwise the error is returned.
+func decodeManifests(r io.Reader, skip func(obj *unstructured.Unstructured, err error) bool) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReaderSize(r, 64*1024))
+	var objects []*unstructured.Unstructured
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, ErrApplyManifest(err)
+		}
+		if isEmptyManifest(doc) {
+			continue
+		}
+		_, obj, err := GetObjectFromManifest(string(bytes.ReplaceAll(doc, []byte("\r\n"), []byte("\n"))))
+		if err == nil {
+			objects, err = appendListItems(objects, obj)
+		}
+		if err != nil && !skip(obj, err) {
+			return nil, err
+		}
+	}
+}
+
+// appendListItems appends the object, or the items of the object if it is a list.
+func appendListItems(objects []*unstructured.Unstructured, obj *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if !obj.IsList() {
+		return append(objects, obj), nil
+	}
+	list, err := obj.ToList()
+	if err != nil {
+		return objects, ErrApplyManifest(err)
+	}
+	for i := range list.Items {
+		if objects, err = appendListItems(objects, &list.Items[i]); err != nil {
+			return objects, err
+		}
+	}
+	return objects, nil
+}
+
+func isEmptyManifest(doc []byte) bool {
+	for _, line := range strings.Split(string(doc), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line != "---" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// SortForApply orders the objects for applying them: by the kind as in ApplyOrder, then by their dependencies
+// declared using the DependsOnAnnotation. Objects of the same kind keep their order.
+// An error is returned if the dependencies are cyclic.
+func SortForApply(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	priority := make(map[string]int, len(ApplyOrder))
+	for i, kind := range ApplyOrder {
+		priority[kind] = i
+	}
+	rank := func(obj *unstructured.Unstructured) int {
+		if p, ok := priority[obj.GetKind()]; ok {
+			return p
+		}
+		return len(ApplyOrder)
+	}
+
+	// dependencies[i] are the indices of the objects object i depends on
+	dependencies := make([][]int, len(objects))
+	for i, obj := range objects {
+		for _, ref := range dependsOn(obj) {
+			for j, dep := range objects {
+				if i != j && ref.matches(dep) {
+					dependencies[i] = append(dependencies[i], j)
+				}
+			}
+		}
+	}
+
+	// Kahn's algorithm, picking the ready object of the lowest rank and index first
+	sorted := make([]*unstructured.Unstructured, 0, len(objects))
+	done := make([]bool, len(objects))
+	for len(sorted) < len(objects) {
+		next := -1
+		for i, obj := range objects {
+			if done[i] || !allDone(dependencies[i], done) {
+				continue
+			}
+			if next == -1 || rank(obj) < rank(objects[next]) {
+				next = i
+			}
+		}
+		if next == -1 {
+			var cyclic []string
+			for i, obj := range objects {
+				if !done[i] {
+					cyclic = append(cyclic, objectReference(obj))
+				}
+			}
+			return nil, ErrManifestDependencyCycle(cyclic)
+		}
+		done[next] = true
+		sorted = append(sorted, objects[next])
+	}
+	return sorted, nil
+}
+
+// SortForDelete orders the objects for deleting them, the reverse order of SortForApply.
+func SortForDelete(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	sorted, err := SortForApply(objects)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	}
+	return sorted, nil
+}
+
+func allDone(indices []int, done []bool) bool {
+	for _, i := range indices {
+		if !done[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type dependencyRef struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func (r dependencyRef) matches(obj *unstructured.Unstructured) bool {
+	return r.kind == obj.GetKind() && r.name == obj.GetName() && (r.namespace == "" || r.namespace == obj.GetNamespace())
+}
+
+func dependsOn(obj *unstructured.Unstructured) []dependencyRef {
+	value := obj.GetAnnotations()[DependsOnAnnotation]
+	var refs []dependencyRef
+	for _, ref := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(ref), "/")
+		switch len(parts) {
+		case 2:
+			refs = append(refs, dependencyRef{kind: parts[0], name: parts[1]})
+		case 3:
+			refs = append(refs, dependencyRef{kind: parts[0], namespace: parts[1], name: parts[2]})
+		}
+	}
+	return refs
+}
+
+func objectReference(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+}
@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const orderTestManifests = `# a comment only document
+---
+apiVersion: istio.io/v1alpha1
+kind: IstioOperator
+metadata:
+  name: control-plane
+  namespace: istio-system
+  annotations:
+    meshery.io/depends-on: Deployment/istio-operator/istio-operator
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istio-operator
+  namespace: istio-operator
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: istio-operator
+---
+
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: istiooperators.install.istio.io
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: istio-operator
+`
+
+func orderTestObjects(t *testing.T, contents string) []*unstructured.Unstructured {
+	t.Helper()
+	manifests, err := SplitManifests([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := make([]*unstructured.Unstructured, 0, len(manifests))
+	for _, manifest := range manifests {
+		_, obj, err := GetObjectFromManifest(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+func kinds(objects []*unstructured.Unstructured) []string {
+	var kinds []string
+	for _, obj := range objects {
+		kinds = append(kinds, obj.GetKind())
+	}
+	return kinds
+}
+
+func TestSortForApply(t *testing.T) {
+	objects := orderTestObjects(t, orderTestManifests)
+	if len(objects) != 5 {
+		t.Fatalf("got %d objects; want 5", len(objects))
+	}
+	sorted, err := SortForApply(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Namespace", "CustomResourceDefinition", "ClusterRoleBinding", "Deployment", "IstioOperator"}
+	if got := kinds(sorted); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortForApply() = %v; want %v", got, want)
+	}
+	sorted, err = SortForDelete(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"IstioOperator", "Deployment", "ClusterRoleBinding", "CustomResourceDefinition", "Namespace"}
+	if got := kinds(sorted); !reflect.DeepEqual(got, want) {
+		t.Errorf("SortForDelete() = %v; want %v", got, want)
+	}
+}
+
+func TestSortForApplyDependencies(t *testing.T) {
+	objects := orderTestObjects(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    meshery.io/depends-on: "ConfigMap/generated, Secret/unknown"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: generated
+  annotations:
+    meshery.io/depends-on: Service/backend
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+`)
+	sorted, err := SortForApply(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := kinds(sorted), []string{"Service", "ConfigMap", "Deployment"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortForApply() = %v; want %v", got, want)
+	}
+
+	objects[2].SetAnnotations(map[string]string{DependsOnAnnotation: "Deployment/app"})
+	if _, err := SortForApply(objects); err == nil || errors.GetCode(err) != ErrManifestDependencyCycleCode {
+		t.Errorf("err = %v; want %s", err, ErrManifestDependencyCycleCode)
+	}
+}
@@ -0,0 +1,193 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Labels and annotations of the resources applied by Meshery, see LabelPolicy.
+const (
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// DefaultManagedBy is the value of the ManagedByLabel unless configured otherwise.
+	DefaultManagedBy    = "meshery"
+	DesignIDLabel       = "meshery.io/design-id"
+	UserIDLabel         = "meshery.io/user-id"
+	AppliedByAnnotation = "meshery.io/applied-by"
+	AppliedAtAnnotation = "meshery.io/applied-at"
+)
+
+// Ownership describes who applied resources, and from which design. Empty fields are not stamped.
+type Ownership struct {
+	DesignID string
+	// UserID is the ID of the user, stamped as a label, and UserName the name of the user, stamped as an annotation
+	// as names may not be valid label values.
+	UserID   string
+	UserName string
+	// AppliedAt is the time the resources are applied, the current time if zero.
+	AppliedAt time.Time
+}
+
+// LabelPolicy stamps the resources applied with the labels and annotations of their Ownership, so that the resources
+// of a design or of a user can be queried later with the Selector of the policy, whoever applied them.
+type LabelPolicy struct {
+	// ManagedBy is the value of the ManagedByLabel, DefaultManagedBy if empty.
+	ManagedBy string
+	// Labels and Annotations are stamped on all the resources, in addition to the ones of the ownership.
+	Labels      map[string]string
+	Annotations map[string]string
+	// PodTemplates also stamps the labels on the pod templates of the workloads, so that their pods can be selected.
+	// The UserIDLabel is left out, so that a workload applied again by another user is not rolled out again.
+	PodTemplates bool
+}
+
+// DefaultLabelPolicy is the LabelPolicy used by ApplyManifest, ApplyImagePullSecret and CreateScopedServiceAccount
+// unless configured otherwise.
+var DefaultLabelPolicy = LabelPolicy{PodTemplates: true}
+
+// labelPolicyOrDefault returns the policy, DefaultLabelPolicy if it is nil.
+func labelPolicyOrDefault(p *LabelPolicy) LabelPolicy {
+	if p == nil {
+		return DefaultLabelPolicy
+	}
+	return *p
+}
+
+// labelObject adds the extra labels to the ones of the object, then stamps the ownership on it if it is set.
+func labelObject(obj metav1.Object, extra map[string]string, o *Ownership, p *LabelPolicy) error {
+	if len(extra) > 0 {
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = make(map[string]string, len(extra))
+		}
+		for k, v := range extra {
+			objLabels[k] = v
+		}
+		obj.SetLabels(objLabels)
+	}
+	if o == nil {
+		return nil
+	}
+	return labelPolicyOrDefault(p).StampObject(obj, *o)
+}
+
+// appliedNow returns the ownership with AppliedAt set to now if it is zero, so that the resources of an apply share its time.
+func appliedNow(o Ownership) Ownership {
+	if o.AppliedAt.IsZero() {
+		o.AppliedAt = time.Now()
+	}
+	return o
+}
+
+// OwnershipLabels returns the labels of the ownership, or an error if a value is not a valid label value.
+func (p LabelPolicy) OwnershipLabels(o Ownership) (map[string]string, error) {
+	managedBy := p.ManagedBy
+	if managedBy == "" {
+		managedBy = DefaultManagedBy
+	}
+	result := map[string]string{ManagedByLabel: managedBy}
+	for k, v := range p.Labels {
+		result[k] = v
+	}
+	if o.DesignID != "" {
+		result[DesignIDLabel] = o.DesignID
+	}
+	if o.UserID != "" {
+		result[UserIDLabel] = o.UserID
+	}
+	keys := make([]string, 0, len(result))
+	for k := range result {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return nil, ErrLabelPolicy(fmt.Errorf("invalid label %q: %s", k, strings.Join(errs, ", ")))
+		}
+		if errs := validation.IsValidLabelValue(result[k]); len(errs) > 0 {
+			return nil, ErrLabelPolicy(fmt.Errorf("invalid value %q of the label %s: %s", result[k], k, strings.Join(errs, ", ")))
+		}
+	}
+	return result, nil
+}
+
+// Stamp sets the labels and the annotations of the ownership on the object, overriding the values set by the manifest.
+// The pod templates of the workloads are stamped as well if the policy says so.
+func (p LabelPolicy) Stamp(obj *unstructured.Unstructured, o Ownership) error {
+	if err := p.StampObject(obj, o); err != nil {
+		return err
+	}
+	path, ok := podSpecPaths[obj.GetKind()]
+	if !ok || !p.PodTemplates || len(path) < 2 {
+		return nil
+	}
+	stamped, err := p.OwnershipLabels(o)
+	if err != nil {
+		return err
+	}
+	delete(stamped, UserIDLabel)
+	// the metadata of the template is next to its spec
+	metadataPath := append(append([]string{}, path[:len(path)-1]...), "metadata", "labels")
+	templateLabels, _, _ := unstructured.NestedStringMap(obj.Object, metadataPath...)
+	if templateLabels == nil {
+		templateLabels = make(map[string]string, len(stamped))
+	}
+	for k, v := range stamped {
+		templateLabels[k] = v
+	}
+	if err := unstructured.SetNestedStringMap(obj.Object, templateLabels, metadataPath...); err != nil {
+		return ErrLabelPolicy(err)
+	}
+	return nil
+}
+
+// StampObject sets the labels and the annotations of the ownership on the metadata of the object, e.g. of a typed
+// object created by a client, overriding the values already set.
+func (p LabelPolicy) StampObject(obj metav1.Object, o Ownership) error {
+	stamped, err := p.OwnershipLabels(o)
+	if err != nil {
+		return err
+	}
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = make(map[string]string, len(stamped))
+	}
+	for k, v := range stamped {
+		objLabels[k] = v
+	}
+	obj.SetLabels(objLabels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for k, v := range p.Annotations {
+		annotations[k] = v
+	}
+	if o.UserName != "" {
+		annotations[AppliedByAnnotation] = o.UserName
+	}
+	appliedAt := o.AppliedAt
+	if appliedAt.IsZero() {
+		appliedAt = time.Now()
+	}
+	annotations[AppliedAtAnnotation] = appliedAt.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// Selector returns the selector of the resources stamped with the ownership, e.g. as the LabelSelector of
+// metav1.ListOptions. The time and the user name are not selectable, empty fields select any value.
+func (p LabelPolicy) Selector(o Ownership) (labels.Selector, error) {
+	stamped, err := p.OwnershipLabels(o)
+	if err != nil {
+		return nil, err
+	}
+	return labels.SelectorFromSet(stamped), nil
+}
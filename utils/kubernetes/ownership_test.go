@@ -0,0 +1,55 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestLabelPolicy(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "meshery", "labels": map[string]interface{}{"app": "meshery"}},
+		"spec":     map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{}}},
+	}}
+	owner := Ownership{
+		DesignID:  "8a3b5e5e-4bd0-4d3c-a6b0-6e3f0d1c2a11",
+		UserID:    "5f8c1a8e-0f6b-4f1e-9d7e-2b1a3c4d5e6f",
+		UserName:  "Jane Doe <jane@example.com>",
+		AppliedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	policy := LabelPolicy{Labels: map[string]string{"meshery.io/environment": "staging"}, PodTemplates: true}
+	if err := policy.Stamp(deployment, owner); err != nil {
+		t.Fatal(err)
+	}
+
+	selector, err := policy.Selector(Ownership{DesignID: owner.DesignID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !selector.Matches(labels.Set(deployment.GetLabels())) || deployment.GetLabels()["app"] != "meshery" {
+		t.Errorf("expected the labels to be stamped, got %v", deployment.GetLabels())
+	}
+	if selector.Matches(labels.Set{ManagedByLabel: DefaultManagedBy, DesignIDLabel: "other"}) {
+		t.Error("expected the selector to select the design only")
+	}
+	templateLabels, _, _ := unstructured.NestedStringMap(deployment.Object, "spec", "template", "metadata", "labels")
+	if templateLabels[DesignIDLabel] != owner.DesignID {
+		t.Errorf("expected the pod template to be stamped, got %v", templateLabels)
+	}
+	// the user would roll the pods out whenever someone else applies the design
+	if _, ok := templateLabels[UserIDLabel]; ok {
+		t.Errorf("expected the pod template not to be stamped with the user, got %v", templateLabels)
+	}
+	annotations := deployment.GetAnnotations()
+	if annotations[AppliedByAnnotation] != owner.UserName || annotations[AppliedAtAnnotation] != "2024-01-02T03:04:05Z" {
+		t.Errorf("unexpected annotations %v", annotations)
+	}
+
+	if err := policy.Stamp(deployment, Ownership{UserID: "not a label value"}); errors.GetCode(err) != ErrLabelPolicyCode {
+		t.Errorf("expected an invalid label value to fail, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultWaitInterval is the interval resources are polled at by WaitFor, unless configured otherwise.
+const DefaultWaitInterval = 2 * time.Second
+
+// ReadinessChecker reports whether a resource is ready to be used. If not, the reason is returned.
+type ReadinessChecker func(obj *unstructured.Unstructured) (ready bool, reason string, err error)
+
+var (
+	readinessMu       sync.RWMutex
+	readinessCheckers = map[schema.GroupVersionKind]ReadinessChecker{}
+)
+
+func init() {
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}, ConditionReadinessChecker("Established", "NamesAccepted"))
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Kind: "Gateway"}, ConditionReadinessChecker("Accepted", "Programmed"))
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "cert-manager.io", Kind: "Certificate"}, ConditionReadinessChecker("Ready"))
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "cert-manager.io", Kind: "Issuer"}, ConditionReadinessChecker("Ready"))
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "cert-manager.io", Kind: "ClusterIssuer"}, ConditionReadinessChecker("Ready"))
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "install.istio.io", Kind: "IstioOperator"}, istioOperatorReady)
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "apps", Kind: "Deployment"}, deploymentReady)
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "apps", Kind: "StatefulSet"}, statefulSetReady)
+	RegisterReadinessChecker(schema.GroupVersionKind{Group: "apps", Kind: "DaemonSet"}, daemonSetReady)
+}
+
+// RegisterReadinessChecker registers the checker for resources of the GVK, replacing the checker registered before.
+// If the version is empty, the checker is used for all versions of the group and kind without a checker of their own.
+func RegisterReadinessChecker(gvk schema.GroupVersionKind, checker ReadinessChecker) {
+	readinessMu.Lock()
+	defer readinessMu.Unlock()
+	readinessCheckers[gvk] = checker
+}
+
+// ReadinessCheckerFor returns the checker registered for the GVK, or for its group and kind.
+// If none is registered, DefaultReadinessChecker is returned.
+func ReadinessCheckerFor(gvk schema.GroupVersionKind) ReadinessChecker {
+	readinessMu.RLock()
+	defer readinessMu.RUnlock()
+	if checker, ok := readinessCheckers[gvk]; ok {
+		return checker
+	}
+	if checker, ok := readinessCheckers[schema.GroupVersionKind{Group: gvk.Group, Kind: gvk.Kind}]; ok {
+		return checker
+	}
+	return DefaultReadinessChecker
+}
+
+// IsReady checks the readiness of the resource using the checker registered for its GVK.
+func IsReady(obj *unstructured.Unstructured) (bool, string, error) {
+	return ReadinessCheckerFor(obj.GroupVersionKind())(obj)
+}
+
+// DefaultReadinessChecker considers a resource ready if its Ready condition is true.
+// Resources without a Ready condition are ready as soon as they exist.
+func DefaultReadinessChecker(obj *unstructured.Unstructured) (bool, string, error) {
+	conditions, err := conditionsOf(obj)
+	if err != nil {
+		return false, "", err
+	}
+	if _, ok := conditions["Ready"]; !ok {
+		return true, "", nil
+	}
+	return ConditionReadinessChecker("Ready")(obj)
+}
+
+// ConditionReadinessChecker returns a checker considering a resource ready if all the conditions are true,
+// and observed for the current generation of the resource, if the conditions report the generation.
+func ConditionReadinessChecker(conditionTypes ...string) ReadinessChecker {
+	return func(obj *unstructured.Unstructured) (bool, string, error) {
+		conditions, err := conditionsOf(obj)
+		if err != nil {
+			return false, "", err
+		}
+		for _, conditionType := range conditionTypes {
+			c, ok := conditions[conditionType]
+			if !ok {
+				return false, fmt.Sprintf("condition %s not reported yet", conditionType), nil
+			}
+			if c.ObservedGeneration != 0 && c.ObservedGeneration < obj.GetGeneration() {
+				return false, fmt.Sprintf("condition %s not observed for generation %d yet", conditionType, obj.GetGeneration()), nil
+			}
+			if c.Status != metav1.ConditionTrue {
+				reason := fmt.Sprintf("condition %s is %s", conditionType, c.Status)
+				if c.Message != "" {
+					reason += ": " + c.Message
+				}
+				return false, reason, nil
+			}
+		}
+		return true, "", nil
+	}
+}
+
+func conditionsOf(obj *unstructured.Unstructured) (map[string]metav1.Condition, error) {
+	list, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return nil, err
+	}
+	conditions := make(map[string]metav1.Condition, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var c metav1.Condition
+		c.Type, _, _ = unstructured.NestedString(m, "type")
+		status, _, _ := unstructured.NestedString(m, "status")
+		c.Status = metav1.ConditionStatus(status)
+		c.Message, _, _ = unstructured.NestedString(m, "message")
+		c.ObservedGeneration, _, _ = unstructured.NestedInt64(m, "observedGeneration")
+		conditions[c.Type] = c
+	}
+	return conditions, nil
+}
+
+func istioOperatorReady(obj *unstructured.Unstructured) (bool, string, error) {
+	status, _, err := unstructured.NestedString(obj.Object, "status", "status")
+	if err != nil {
+		return false, "", err
+	}
+	if status != "HEALTHY" {
+		return false, fmt.Sprintf("status is %q", status), nil
+	}
+	return true, "", nil
+}
+
+func observedGeneration(obj *unstructured.Unstructured) (bool, string) {
+	observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if observed < obj.GetGeneration() {
+		return false, fmt.Sprintf("generation %d not observed yet", obj.GetGeneration())
+	}
+	return true, ""
+}
+
+func deploymentReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if ok, reason := observedGeneration(obj); !ok {
+		return false, reason, nil
+	}
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	if updated < replicas || available < replicas {
+		return false, fmt.Sprintf("%d of %d replicas updated, %d available", updated, replicas, available), nil
+	}
+	return true, "", nil
+}
+
+func statefulSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if ok, reason := observedGeneration(obj); !ok {
+		return false, reason, nil
+	}
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	if ready < replicas {
+		return false, fmt.Sprintf("%d of %d replicas ready", ready, replicas), nil
+	}
+	return true, "", nil
+}
+
+func daemonSetReady(obj *unstructured.Unstructured) (bool, string, error) {
+	if ok, reason := observedGeneration(obj); !ok {
+		return false, reason, nil
+	}
+	desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+	if ready < desired {
+		return false, fmt.Sprintf("%d of %d pods ready", ready, desired), nil
+	}
+	return true, "", nil
+}
+
+// WaitOptions configures WaitFor.
+type WaitOptions struct {
+	// Interval the resource is polled at, DefaultWaitInterval if zero.
+	Interval time.Duration
+	// Timeout of waiting, if not limited by the context already.
+	Timeout time.Duration
+}
+
+// WaitFor blocks until the resource exists and is ready according to the checker registered for its GVK,
+// until the context is done, or until the timeout of the options expires.
+func (client *Client) WaitFor(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, opts WaitOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWaitInterval
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	resource := client.DynamicKubeClient.Resource(gvr)
+	reason := "not found"
+	err := wait.PollUntilContextCancel(ctx, interval, true, func(ctx context.Context) (bool, error) {
+		var obj *unstructured.Unstructured
+		var err error
+		if namespace == "" {
+			obj, err = resource.Get(ctx, name, metav1.GetOptions{})
+		} else {
+			obj, err = resource.Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		}
+		if kerrors.IsNotFound(err) {
+			reason = "not found"
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		var ready bool
+		ready, reason, err = IsReady(obj)
+		return ready, err
+	})
+	if err != nil {
+		return ErrWaitFor(err, fmt.Sprintf("%s %s/%s", gvr.Resource, namespace, name), reason)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newReadinessObject(apiVersion, kind string, generation int64, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": "test", "namespace": "default", "generation": generation},
+	}}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	return obj
+}
+
+func condition(conditionType, status string, observedGeneration int64) interface{} {
+	return map[string]interface{}{"type": conditionType, "status": status, "observedGeneration": observedGeneration}
+}
+
+func TestIsReady(t *testing.T) {
+	tests := []struct {
+		name  string
+		obj   *unstructured.Unstructured
+		ready bool
+	}{
+		{"crd established", newReadinessObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", 1, map[string]interface{}{
+			"conditions": []interface{}{condition("Established", "True", 0), condition("NamesAccepted", "True", 0)},
+		}), true},
+		{"crd not established", newReadinessObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", 1, map[string]interface{}{
+			"conditions": []interface{}{condition("NamesAccepted", "True", 0)},
+		}), false},
+		{"gateway programmed", newReadinessObject("gateway.networking.k8s.io/v1", "Gateway", 2, map[string]interface{}{
+			"conditions": []interface{}{condition("Accepted", "True", 2), condition("Programmed", "True", 2)},
+		}), true},
+		{"gateway programmed for old generation", newReadinessObject("gateway.networking.k8s.io/v1beta1", "Gateway", 3, map[string]interface{}{
+			"conditions": []interface{}{condition("Accepted", "True", 2), condition("Programmed", "True", 2)},
+		}), false},
+		{"certificate not ready", newReadinessObject("cert-manager.io/v1", "Certificate", 1, map[string]interface{}{
+			"conditions": []interface{}{condition("Ready", "False", 1)},
+		}), false},
+		{"istio operator healthy", newReadinessObject("install.istio.io/v1alpha1", "IstioOperator", 1, map[string]interface{}{"status": "HEALTHY"}), true},
+		{"deployment rolling out", newReadinessObject("apps/v1", "Deployment", 1, map[string]interface{}{
+			"observedGeneration": int64(1), "updatedReplicas": int64(0), "availableReplicas": int64(1),
+		}), false},
+		{"unknown kind without conditions", newReadinessObject("example.com/v1", "Widget", 1, nil), true},
+		{"unknown kind with ready condition", newReadinessObject("example.com/v1", "Widget", 1, map[string]interface{}{
+			"conditions": []interface{}{condition("Ready", "False", 0)},
+		}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, reason, err := IsReady(tt.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ready != tt.ready {
+				t.Errorf("IsReady() = %v (%s); want %v", ready, reason, tt.ready)
+			}
+		})
+	}
+}
+
+func TestRegisterReadinessChecker(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v2", Kind: "Gadget"}
+	RegisterReadinessChecker(gvk, func(*unstructured.Unstructured) (bool, string, error) { return false, "never", nil })
+	if ready, reason, _ := IsReady(newReadinessObject("example.com/v2", "Gadget", 1, nil)); ready || reason != "never" {
+		t.Errorf("IsReady() = %v, %s; want the registered checker to be used", ready, reason)
+	}
+	if ready, _, _ := IsReady(newReadinessObject("example.com/v1", "Gadget", 1, nil)); !ready {
+		t.Error("the checker of v2 is used for v1")
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	obj := newReadinessObject("cert-manager.io/v1", "Certificate", 1, map[string]interface{}{
+		"conditions": []interface{}{condition("Ready", "False", 1)},
+	})
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "CertificateList"}, obj)
+	client := &Client{DynamicKubeClient: dynamicClient}
+
+	err := client.WaitFor(context.Background(), gvr, "default", "test", WaitOptions{Interval: 10 * time.Millisecond, Timeout: 50 * time.Millisecond})
+	if err == nil || errors.GetCode(err) != ErrWaitForCode {
+		t.Fatalf("err = %v; want %s", err, ErrWaitForCode)
+	}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		ready := obj.DeepCopy()
+		ready.Object["status"] = map[string]interface{}{"conditions": []interface{}{condition("Ready", "True", 1)}}
+		_, _ = dynamicClient.Resource(gvr).Namespace("default").Update(context.Background(), ready, metav1.UpdateOptions{})
+	}()
+	if err := client.WaitFor(context.Background(), gvr, "default", "test", WaitOptions{Interval: 10 * time.Millisecond, Timeout: 5 * time.Second}); err != nil {
+		t.Errorf("WaitFor() = %v", err)
+	}
+}
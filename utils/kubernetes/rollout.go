@@ -0,0 +1,184 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// RolloutStatus is the progress of the rollout of a Deployment, a StatefulSet or a DaemonSet.
+type RolloutStatus struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Replicas is the number of pods desired, the number of nodes scheduled for DaemonSets.
+	Replicas          int64 `json:"replicas"`
+	UpdatedReplicas   int64 `json:"updatedReplicas"`
+	ReadyReplicas     int64 `json:"readyReplicas"`
+	AvailableReplicas int64 `json:"availableReplicas"`
+	// Conditions are the conditions reported by the controller, e.g. Progressing and Available for Deployments.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Done is set once the rollout completed, Failed if it failed, e.g. as its progress deadline exceeded.
+	// The channel of StreamRolloutStatus is closed after a status with either of them set.
+	Done   bool `json:"done"`
+	Failed bool `json:"failed"`
+	// Message is why the rollout didn't complete yet, or why it failed.
+	Message string `json:"message,omitempty"`
+}
+
+// rolloutResources are the resources whose rollouts are streamed.
+var rolloutResources = map[string]bool{"deployments": true, "statefulsets": true, "daemonsets": true}
+
+// StreamRolloutStatus watches the rollout of the Deployment, StatefulSet or DaemonSet, and sends its status each time
+// it changes, e.g. to show the progress of the deployment of a design. The channel is closed once the rollout is done
+// or failed, if the resource is deleted, or once the context is done. The watch is resumed if the API server closes it,
+// if it can't be, a failed status with the reason is sent before the channel is closed.
+func (client *Client) StreamRolloutStatus(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (<-chan RolloutStatus, error) {
+	if !rolloutResources[gvr.Resource] {
+		return nil, ErrStreamRolloutStatus(fmt.Errorf("the rollouts of %s are not supported, only the ones of Deployments, StatefulSets and DaemonSets", gvr.Resource), name)
+	}
+	resource := client.DynamicKubeClient.Resource(gvr).Namespace(namespace)
+	opts := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()}
+	w, err := resource.Watch(ctx, opts)
+	if err != nil {
+		return nil, ErrStreamRolloutStatus(err, name)
+	}
+	statuses := make(chan RolloutStatus)
+	go func() {
+		defer close(statuses)
+		defer func() {
+			// w is nil if the watch couldn't be resumed
+			if w != nil {
+				w.Stop()
+			}
+		}()
+		var last *RolloutStatus
+		send := func(status RolloutStatus) bool {
+			if last != nil && reflect.DeepEqual(*last, status) {
+				return true
+			}
+			last = &status
+			select {
+			case statuses <- status:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					// the API server closes watches after a while, resume from the last version seen
+					w.Stop()
+					w, err = resource.Watch(ctx, opts)
+					if kerrors.IsGone(err) {
+						opts.ResourceVersion = ""
+						w, err = resource.Watch(ctx, opts)
+					}
+					if err != nil {
+						w = nil
+						send(RolloutStatus{Kind: kindOf(gvr), Namespace: namespace, Name: name, Failed: true, Message: "the watch couldn't be resumed: " + err.Error()})
+						return
+					}
+					continue
+				}
+				switch event.Type {
+				case watch.Error:
+					if status, ok := event.Object.(*metav1.Status); ok && status.Code == 410 {
+						// the version is too old, restart from the current state
+						opts.ResourceVersion = ""
+					}
+					continue
+				case watch.Deleted:
+					send(RolloutStatus{Kind: kindOf(gvr), Namespace: namespace, Name: name, Failed: true, Message: "deleted"})
+					return
+				}
+				obj, ok := event.Object.(*unstructured.Unstructured)
+				if !ok {
+					continue
+				}
+				opts.ResourceVersion = obj.GetResourceVersion()
+				status := rolloutStatus(obj)
+				if !send(status) || status.Done || status.Failed {
+					return
+				}
+			}
+		}
+	}()
+	return statuses, nil
+}
+
+// rolloutStatus returns the status of the rollout of the workload.
+func rolloutStatus(obj *unstructured.Unstructured) RolloutStatus {
+	status := RolloutStatus{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	count := func(fields ...string) int64 {
+		n, _, _ := unstructured.NestedInt64(obj.Object, fields...)
+		return n
+	}
+	if obj.GetKind() == "DaemonSet" {
+		status.Replicas = count("status", "desiredNumberScheduled")
+		status.UpdatedReplicas = count("status", "updatedNumberScheduled")
+		status.ReadyReplicas = count("status", "numberReady")
+		status.AvailableReplicas = count("status", "numberAvailable")
+	} else {
+		status.Replicas = 1
+		if replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+			status.Replicas = replicas
+		}
+		status.UpdatedReplicas = count("status", "updatedReplicas")
+		status.ReadyReplicas = count("status", "readyReplicas")
+		status.AvailableReplicas = count("status", "availableReplicas")
+	}
+
+	list, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range list {
+		m, _ := item.(map[string]interface{})
+		var c metav1.Condition
+		c.Type, _, _ = unstructured.NestedString(m, "type")
+		conditionStatus, _, _ := unstructured.NestedString(m, "status")
+		c.Status = metav1.ConditionStatus(conditionStatus)
+		c.Reason, _, _ = unstructured.NestedString(m, "reason")
+		c.Message, _, _ = unstructured.NestedString(m, "message")
+		status.Conditions = append(status.Conditions, c)
+		switch {
+		case c.Type == "Progressing" && c.Status == metav1.ConditionFalse && c.Reason == "ProgressDeadlineExceeded",
+			c.Type == "ReplicaFailure" && c.Status == metav1.ConditionTrue:
+			status.Failed = true
+			status.Message = c.Message
+			if status.Message == "" {
+				status.Message = c.Reason
+			}
+		}
+	}
+	if status.Failed {
+		return status
+	}
+	ready, reason, err := IsReady(obj)
+	if err != nil {
+		reason = err.Error()
+	}
+	status.Done, status.Message = ready, reason
+	return status
+}
+
+func kindOf(gvr schema.GroupVersionResource) string {
+	switch gvr.Resource {
+	case "deployments":
+		return "Deployment"
+	case "statefulsets":
+		return "StatefulSet"
+	case "daemonsets":
+		return "DaemonSet"
+	}
+	return gvr.Resource
+}
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestStreamRolloutStatus(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	obj := newReadinessObject("apps/v1", "Deployment", 1, map[string]interface{}{"observedGeneration": int64(1)})
+	obj.Object["spec"] = map[string]interface{}{"replicas": int64(2)}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "DeploymentList"})
+	client := &Client{DynamicKubeClient: dynamicClient}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statuses, err := client.StreamRolloutStatus(ctx, gvr, "default", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployments := dynamicClient.Resource(gvr).Namespace("default")
+	if _, err := deployments.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	status := <-statuses
+	if status.Done || status.Replicas != 2 || status.UpdatedReplicas != 0 {
+		t.Errorf("unexpected first status %+v", status)
+	}
+
+	for _, ready := range []int64{1, 2} {
+		obj.Object["status"] = map[string]interface{}{"observedGeneration": int64(1), "updatedReplicas": ready, "readyReplicas": ready, "availableReplicas": ready}
+		if _, err := deployments.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		status = <-statuses
+		if status.ReadyReplicas != ready || status.Done != (ready == 2) {
+			t.Errorf("unexpected status %+v", status)
+		}
+	}
+	if _, ok := <-statuses; ok {
+		t.Error("expected the channel to be closed once the rollout is done")
+	}
+
+	failed := newReadinessObject("apps/v1", "Deployment", 1, map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded", "message": "ReplicaSet test-1 has timed out progressing."}},
+	})
+	if status := rolloutStatus(failed); !status.Failed || status.Message != "ReplicaSet test-1 has timed out progressing." {
+		t.Errorf("expected the exceeded deadline to fail the rollout, got %+v", status)
+	}
+	if _, err := client.StreamRolloutStatus(ctx, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "default", "test"); errors.GetCode(err) != ErrStreamRolloutStatusCode {
+		t.Errorf("expected pods to be unsupported, got %v", err)
+	}
+}
+
+func TestStreamRolloutStatusResumeFails(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "DeploymentList"})
+	first := watch.NewFake()
+	watches := 0
+	dynamicClient.PrependWatchReactor("deployments", func(k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		if watches == 1 {
+			return true, first, nil
+		}
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	client := &Client{DynamicKubeClient: dynamicClient}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statuses, err := client.StreamRolloutStatus(ctx, gvr, "default", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the API server closes the watch, and resuming it fails
+	first.Stop()
+	status, ok := <-statuses
+	if !ok || !status.Failed || !strings.Contains(status.Message, "connection refused") {
+		t.Fatalf("expected a failed status, got %+v (open: %v)", status, ok)
+	}
+	if _, ok := <-statuses; ok {
+		t.Error("expected the channel to be closed once the watch can't be resumed")
+	}
+}
@@ -0,0 +1,252 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultTokenExpiration is the lifetime of tokens requested without expiration.
+const DefaultTokenExpiration = time.Hour
+
+// ServiceAccountOptions describes a ServiceAccount and the permissions granted to it.
+type ServiceAccountOptions struct {
+	Name      string
+	Namespace string
+	// Labels are set on the ServiceAccount and its Role or ClusterRole.
+	//
+	// Deprecated: set the Ownership instead, the extra labels being the ones of its LabelPolicy.
+	Labels map[string]string
+	// Ownership, if set, stamps the ServiceAccount and its Role or ClusterRole with the labels and annotations of their
+	// owner, following the LabelPolicy, or the DefaultLabelPolicy if it is not set.
+	Ownership   *Ownership
+	LabelPolicy *LabelPolicy
+	// Rules are granted by a Role of the same name as the ServiceAccount, bound to it.
+	// If ClusterScoped, a ClusterRole and a ClusterRoleBinding named <namespace>-<name> are used instead, so that the
+	// ServiceAccounts of the same name in different namespaces don't share them.
+	Rules         []rbacv1.PolicyRule
+	ClusterScoped bool
+}
+
+// TokenOptions configures a token requested for a ServiceAccount.
+type TokenOptions struct {
+	// Expiration is the requested lifetime of the token, DefaultTokenExpiration if zero.
+	// The API server can issue tokens with a shorter lifetime.
+	Expiration time.Duration
+	// Audiences of the token, the audiences of the API server if empty.
+	Audiences []string
+}
+
+// ServiceAccountToken is a bounded token of a ServiceAccount.
+type ServiceAccountToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// CreateScopedServiceAccount creates the ServiceAccount with a Role granting the rules, bound to it.
+// An existing ServiceAccount is reused, existing Roles and bindings are updated so that the permissions match the options.
+func CreateScopedServiceAccount(ctx context.Context, client kubernetes.Interface, opts ServiceAccountOptions) (*corev1.ServiceAccount, error) {
+	if opts.Ownership != nil {
+		ownership := appliedNow(*opts.Ownership)
+		opts.Ownership = &ownership
+	}
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: opts.Namespace},
+	}
+	if err := labelObject(sa, opts.Labels, opts.Ownership, opts.LabelPolicy); err != nil {
+		return nil, err
+	}
+	created, err := client.CoreV1().ServiceAccounts(opts.Namespace).Create(ctx, sa, metav1.CreateOptions{})
+	if kerrors.IsAlreadyExists(err) {
+		created, err = client.CoreV1().ServiceAccounts(opts.Namespace).Get(ctx, opts.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, ErrCreateServiceAccount(err, opts.Namespace, opts.Name)
+	}
+	if len(opts.Rules) == 0 {
+		return created, nil
+	}
+
+	meta := metav1.ObjectMeta{Name: opts.Name, Namespace: opts.Namespace}
+	if err := labelObject(&meta, opts.Labels, opts.Ownership, opts.LabelPolicy); err != nil {
+		return nil, err
+	}
+	roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: opts.Name}
+	if opts.ClusterScoped {
+		meta.Name = clusterScopedName(opts.Namespace, opts.Name)
+		meta.Namespace = ""
+		roleRef.Kind = "ClusterRole"
+		roleRef.Name = meta.Name
+		err = applyClusterRole(ctx, client, &rbacv1.ClusterRole{ObjectMeta: meta, Rules: opts.Rules})
+	} else {
+		err = applyRole(ctx, client, &rbacv1.Role{ObjectMeta: meta, Rules: opts.Rules})
+	}
+	if err != nil {
+		return nil, ErrCreateServiceAccount(err, opts.Namespace, opts.Name)
+	}
+	if err := BindRole(ctx, client, opts.Namespace, opts.Name, roleRef); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// BindRole binds the Role or ClusterRole to the ServiceAccount. The binding is named after the ServiceAccount.
+// A ClusterRole is bound cluster-wide by a ClusterRoleBinding named <namespace>-<ServiceAccount> if the kind of the
+// reference is ClusterRole, use a reference of kind Role to bind it within the namespace only.
+func BindRole(ctx context.Context, client kubernetes.Interface, namespace, serviceAccount string, roleRef rbacv1.RoleRef) error {
+	if roleRef.APIGroup == "" {
+		roleRef.APIGroup = rbacv1.GroupName
+	}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: serviceAccount, Namespace: namespace}}
+	var err error
+	if roleRef.Kind == "ClusterRole" {
+		err = applyClusterRoleBinding(ctx, client, &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterScopedName(namespace, serviceAccount)},
+			Subjects:   subjects,
+			RoleRef:    roleRef,
+		})
+	} else {
+		err = applyRoleBinding(ctx, client, &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: serviceAccount, Namespace: namespace},
+			Subjects:   subjects,
+			RoleRef:    roleRef,
+		})
+	}
+	if err != nil {
+		return ErrBindRole(err, roleRef.Name, namespace, serviceAccount)
+	}
+	return nil
+}
+
+// CreateServiceAccountToken requests a bounded token of the ServiceAccount using the TokenRequest API.
+func CreateServiceAccountToken(ctx context.Context, client kubernetes.Interface, namespace, serviceAccount string, opts TokenOptions) (*ServiceAccountToken, error) {
+	expiration := opts.Expiration
+	if expiration <= 0 {
+		expiration = DefaultTokenExpiration
+	}
+	seconds := int64(expiration.Seconds())
+	req := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         opts.Audiences,
+			ExpirationSeconds: &seconds,
+		},
+	}
+	resp, err := client.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, serviceAccount, req, metav1.CreateOptions{})
+	if err != nil {
+		return nil, ErrCreateServiceAccountToken(err, namespace, serviceAccount)
+	}
+	return &ServiceAccountToken{Token: resp.Status.Token, ExpiresAt: resp.Status.ExpirationTimestamp.Time}, nil
+}
+
+// DeleteScopedServiceAccount deletes the ServiceAccount and the Role or ClusterRole and binding created by CreateScopedServiceAccount.
+// Objects not found are ignored.
+func DeleteScopedServiceAccount(ctx context.Context, client kubernetes.Interface, opts ServiceAccountOptions) error {
+	var errs []error
+	collect := func(err error) {
+		if err != nil && !kerrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	if opts.ClusterScoped {
+		name := clusterScopedName(opts.Namespace, opts.Name)
+		collect(client.RbacV1().ClusterRoleBindings().Delete(ctx, name, metav1.DeleteOptions{}))
+		collect(client.RbacV1().ClusterRoles().Delete(ctx, name, metav1.DeleteOptions{}))
+	} else {
+		collect(client.RbacV1().RoleBindings(opts.Namespace).Delete(ctx, opts.Name, metav1.DeleteOptions{}))
+		collect(client.RbacV1().Roles(opts.Namespace).Delete(ctx, opts.Name, metav1.DeleteOptions{}))
+	}
+	collect(client.CoreV1().ServiceAccounts(opts.Namespace).Delete(ctx, opts.Name, metav1.DeleteOptions{}))
+	if len(errs) > 0 {
+		return ErrDeleteServiceAccount(errs[0], opts.Namespace, opts.Name)
+	}
+	return nil
+}
+
+// clusterScopedName is the name of the cluster scoped objects of the ServiceAccount, prefixed by its namespace.
+func clusterScopedName(namespace, serviceAccount string) string {
+	return namespace + "-" + serviceAccount
+}
+
+func applyRole(ctx context.Context, client kubernetes.Interface, role *rbacv1.Role) error {
+	roles := client.RbacV1().Roles(role.Namespace)
+	existing, err := roles.Get(ctx, role.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = roles.Create(ctx, role, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing.Labels = role.Labels
+	existing.Rules = role.Rules
+	_, err = roles.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func applyClusterRole(ctx context.Context, client kubernetes.Interface, role *rbacv1.ClusterRole) error {
+	roles := client.RbacV1().ClusterRoles()
+	existing, err := roles.Get(ctx, role.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = roles.Create(ctx, role, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing.Labels = role.Labels
+	existing.Rules = role.Rules
+	_, err = roles.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// applyRoleBinding creates the binding, or replaces it, as the role of a binding can't be changed.
+func applyRoleBinding(ctx context.Context, client kubernetes.Interface, binding *rbacv1.RoleBinding) error {
+	bindings := client.RbacV1().RoleBindings(binding.Namespace)
+	existing, err := bindings.Get(ctx, binding.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = bindings.Create(ctx, binding, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if existing.RoleRef != binding.RoleRef {
+		if err := bindings.Delete(ctx, binding.Name, metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		_, err = bindings.Create(ctx, binding, metav1.CreateOptions{})
+		return err
+	}
+	existing.Subjects = binding.Subjects
+	_, err = bindings.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// applyClusterRoleBinding creates the binding, or replaces it, as the role of a binding can't be changed.
+func applyClusterRoleBinding(ctx context.Context, client kubernetes.Interface, binding *rbacv1.ClusterRoleBinding) error {
+	bindings := client.RbacV1().ClusterRoleBindings()
+	existing, err := bindings.Get(ctx, binding.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		_, err = bindings.Create(ctx, binding, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if existing.RoleRef != binding.RoleRef {
+		if err := bindings.Delete(ctx, binding.Name, metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		_, err = bindings.Create(ctx, binding, metav1.CreateOptions{})
+		return err
+	}
+	existing.Subjects = binding.Subjects
+	_, err = bindings.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
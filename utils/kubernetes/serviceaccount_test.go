@@ -0,0 +1,139 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScopedServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	opts := ServiceAccountOptions{
+		Name:      "meshsync",
+		Namespace: "meshery",
+		Rules:     []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}}},
+		Ownership: &Ownership{DesignID: "8a3b5e5e-4bd0-4d3c-a6b0-6e3f0d1c2a11"},
+	}
+	sa, err := CreateScopedServiceAccount(ctx, client, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sa.Labels[DesignIDLabel] != opts.Ownership.DesignID || sa.Labels[ManagedByLabel] != DefaultManagedBy {
+		t.Errorf("labels = %v", sa.Labels)
+	}
+	// creating it again updates the rules
+	opts.Rules[0].Verbs = []string{"get"}
+	if _, err := CreateScopedServiceAccount(ctx, client, opts); err != nil {
+		t.Fatal(err)
+	}
+	role, err := client.RbacV1().Roles("meshery").Get(ctx, "meshsync", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(role.Rules) != 1 || len(role.Rules[0].Verbs) != 1 {
+		t.Errorf("rules = %+v", role.Rules)
+	}
+	if role.Labels[DesignIDLabel] != opts.Ownership.DesignID {
+		t.Errorf("role labels = %v", role.Labels)
+	}
+	binding, err := client.RbacV1().RoleBindings("meshery").Get(ctx, "meshsync", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binding.RoleRef.Kind != "Role" || binding.Subjects[0].Name != "meshsync" || binding.Subjects[0].Namespace != "meshery" {
+		t.Errorf("binding = %+v", binding)
+	}
+
+	// binding a ClusterRole within the namespace replaces the role of the binding
+	if err := BindRole(ctx, client, "meshery", "meshsync", rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Get(ctx, "meshery-meshsync", metav1.GetOptions{}); err != nil {
+		t.Errorf("ClusterRoleBinding not created: %v", err)
+	}
+
+	if err := DeleteScopedServiceAccount(ctx, client, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().ServiceAccounts("meshery").Get(ctx, "meshsync", metav1.GetOptions{}); !kerrors.IsNotFound(err) {
+		t.Errorf("ServiceAccount not deleted: %v", err)
+	}
+	if err := DeleteScopedServiceAccount(ctx, client, opts); err != nil {
+		t.Errorf("deleting again = %v; want nil", err)
+	}
+}
+
+func TestClusterScopedServiceAccounts(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	rules := map[string]string{"meshery": "pods", "monitoring": "services"}
+	for _, namespace := range []string{"meshery", "monitoring"} {
+		opts := ServiceAccountOptions{
+			Name:          "meshsync",
+			Namespace:     namespace,
+			ClusterScoped: true,
+			Rules:         []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{rules[namespace]}, Verbs: []string{"list"}}},
+		}
+		if _, err := CreateScopedServiceAccount(ctx, client, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, namespace := range []string{"meshery", "monitoring"} {
+		name := namespace + "-meshsync"
+		role, err := client.RbacV1().ClusterRoles().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if role.Rules[0].Resources[0] != rules[namespace] {
+			t.Errorf("the rules of %s were overwritten: %+v", name, role.Rules)
+		}
+		binding, err := client.RbacV1().ClusterRoleBindings().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if binding.RoleRef.Name != name || len(binding.Subjects) != 1 || binding.Subjects[0].Namespace != namespace {
+			t.Errorf("binding = %+v", binding)
+		}
+	}
+
+	if err := DeleteScopedServiceAccount(ctx, client, ServiceAccountOptions{Name: "meshsync", Namespace: "meshery", ClusterScoped: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Get(ctx, "monitoring-meshsync", metav1.GetOptions{}); err != nil {
+		t.Errorf("deleting the ServiceAccount of meshery deleted the binding of monitoring: %v", err)
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Get(ctx, "meshery-meshsync", metav1.GetOptions{}); !kerrors.IsNotFound(err) {
+		t.Errorf("ClusterRoleBinding not deleted: %v", err)
+	}
+}
+
+func TestCreateServiceAccountToken(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	expiresAt := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	var requested int64
+	client.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		req := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		requested = *req.Spec.ExpirationSeconds
+		req.Status = authenticationv1.TokenRequestStatus{Token: "token", ExpirationTimestamp: metav1.NewTime(expiresAt)}
+		return true, req, nil
+	})
+	token, err := CreateServiceAccountToken(context.Background(), client, "meshery", "meshsync", TokenOptions{Expiration: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Token != "token" || !token.ExpiresAt.Equal(expiresAt) || requested != 600 {
+		t.Errorf("token = %+v, requested expiration %d", token, requested)
+	}
+}
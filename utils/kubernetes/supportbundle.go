@@ -0,0 +1,302 @@
+package kubernetes
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshkit/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultSupportBundleTailLines is the number of lines of logs collected per container unless configured otherwise.
+const DefaultSupportBundleTailLines = 1000
+
+// DefaultSupportBundleNamespaces are the namespaces collected unless configured otherwise.
+var DefaultSupportBundleNamespaces = []string{"meshery"}
+
+// DefaultSupportBundleResources are the resources collected unless configured otherwise.
+var DefaultSupportBundleResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "pods"},
+	{Version: "v1", Resource: "services"},
+	{Version: "v1", Resource: "configmaps"},
+	{Version: "v1", Resource: "serviceaccounts"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "batch", Version: "v1", Resource: "jobs"},
+}
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// SupportBundleOptions configures the diagnostics collected by CollectSupportBundle.
+type SupportBundleOptions struct {
+	// Namespaces whose events, resources and logs are collected, DefaultSupportBundleNamespaces if empty.
+	Namespaces []string
+	// Resources collected in the namespaces, DefaultSupportBundleResources if empty.
+	Resources []schema.GroupVersionResource
+	// LabelSelector selects the resources and the pods whose logs are collected, e.g. app.kubernetes.io/part-of=meshery.
+	LabelSelector string
+	// TailLines is the number of lines of logs collected per container, DefaultSupportBundleTailLines if zero.
+	TailLines int64
+	// Redaction configures the redaction of the resources, events and logs, the defaults of logger.Redaction if nil.
+	// The data of Secrets, the values of sensitive environment variables and the last applied configurations are always redacted.
+	Redaction *logger.Redaction
+	// Files are additional files added to the bundle by name, e.g. the configuration of Meshery. They are redacted too.
+	Files map[string][]byte
+}
+
+// CollectSupportBundle writes a tar.gz archive of diagnostics of the cluster to w, to be attached to bug reports:
+// the versions of the API server and of the nodes, and the events, resources and container logs of the namespaces.
+//
+// The values of credentials are redacted, see SupportBundleOptions.Redaction. Diagnostics which can't be collected,
+// e.g. for lack of permissions, are listed in the errors.txt file of the bundle instead of failing.
+func (client *Client) CollectSupportBundle(ctx context.Context, w io.Writer, opts SupportBundleOptions) error {
+	return collectSupportBundle(ctx, client.KubeClient, client.DynamicKubeClient, w, opts)
+}
+
+func collectSupportBundle(ctx context.Context, kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, w io.Writer, opts SupportBundleOptions) error {
+	redaction := logger.Redaction{}
+	if opts.Redaction != nil {
+		redaction = *opts.Redaction
+	}
+	redact, err := redaction.Redactor()
+	if err != nil {
+		return ErrCollectSupportBundle(err)
+	}
+	replacement := redaction.Replacement
+	if replacement == "" {
+		replacement = logger.DefaultRedactionReplacement
+	}
+	b := &supportBundle{
+		opts:        opts,
+		redact:      redact,
+		replacement: replacement,
+		gz:          gzip.NewWriter(w),
+		now:         time.Now(),
+	}
+	if len(b.opts.Namespaces) == 0 {
+		b.opts.Namespaces = DefaultSupportBundleNamespaces
+	}
+	if len(b.opts.Resources) == 0 {
+		b.opts.Resources = DefaultSupportBundleResources
+	}
+	if b.opts.TailLines <= 0 {
+		b.opts.TailLines = DefaultSupportBundleTailLines
+	}
+	b.tw = tar.NewWriter(b.gz)
+
+	b.collectVersions(ctx, kubeClient)
+	for _, ns := range b.opts.Namespaces {
+		b.collectEvents(ctx, kubeClient, ns)
+		b.collectResources(ctx, dynamicClient, ns)
+		b.collectLogs(ctx, kubeClient, ns)
+	}
+	names := make([]string, 0, len(opts.Files))
+	for name := range opts.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.add(path.Join("files", name), []byte(b.redact(string(opts.Files[name]))))
+	}
+	if len(b.errors) > 0 {
+		b.add("errors.txt", []byte(strings.Join(b.errors, "\n")+"\n"))
+	}
+
+	if b.err == nil {
+		b.err = b.tw.Close()
+	}
+	if err := b.gz.Close(); b.err == nil {
+		b.err = err
+	}
+	if b.err != nil {
+		return ErrCollectSupportBundle(b.err)
+	}
+	return nil
+}
+
+type supportBundle struct {
+	opts        SupportBundleOptions
+	redact      func(string) string
+	replacement string
+	gz          *gzip.Writer
+	tw          *tar.Writer
+	now         time.Time
+	// errors are the diagnostics which couldn't be collected
+	errors []string
+	// err is the first error writing the bundle
+	err error
+}
+
+// add adds the file to the archive, once writing failed the files are skipped.
+func (b *supportBundle) add(name string, data []byte) {
+	if b.err != nil {
+		return
+	}
+	if b.err = b.tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: b.now}); b.err != nil {
+		return
+	}
+	_, b.err = b.tw.Write(data)
+}
+
+// addYAML adds the value as a redacted YAML file.
+func (b *supportBundle) addYAML(name string, value interface{}) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	b.add(name, []byte(b.redact(string(data))))
+}
+
+func (b *supportBundle) fail(what string, err error) {
+	b.errors = append(b.errors, fmt.Sprintf("%s: %v", what, err))
+}
+
+func (b *supportBundle) collectVersions(ctx context.Context, kubeClient kubernetes.Interface) {
+	if version, err := kubeClient.Discovery().ServerVersion(); err != nil {
+		b.fail("server version", err)
+	} else {
+		b.addYAML("cluster/version.yaml", version)
+	}
+	nodes, err := kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		b.fail("nodes", err)
+		return
+	}
+	type nodeVersion struct {
+		Name             string `json:"name"`
+		KubeletVersion   string `json:"kubeletVersion"`
+		ContainerRuntime string `json:"containerRuntime"`
+		OSImage          string `json:"osImage"`
+		Architecture     string `json:"architecture"`
+	}
+	versions := make([]nodeVersion, 0, len(nodes.Items))
+	for _, n := range nodes.Items {
+		info := n.Status.NodeInfo
+		versions = append(versions, nodeVersion{n.Name, info.KubeletVersion, info.ContainerRuntimeVersion, info.OSImage, info.Architecture})
+	}
+	b.addYAML("cluster/nodes.yaml", versions)
+}
+
+func (b *supportBundle) collectEvents(ctx context.Context, kubeClient kubernetes.Interface, ns string) {
+	events, err := kubeClient.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		b.fail("events of "+ns, err)
+		return
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return eventTime(events.Items[i]).Before(eventTime(events.Items[j]))
+	})
+	for i := range events.Items {
+		events.Items[i].ManagedFields = nil
+	}
+	b.addYAML(path.Join("namespaces", ns, "events.yaml"), events.Items)
+}
+
+func eventTime(e corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func (b *supportBundle) collectResources(ctx context.Context, dynamicClient dynamic.Interface, ns string) {
+	for _, gvr := range b.opts.Resources {
+		dir := gvr.Resource
+		if gvr.Group != "" {
+			dir += "." + gvr.Group
+		}
+		list, err := dynamicClient.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{LabelSelector: b.opts.LabelSelector})
+		if err != nil {
+			b.fail(fmt.Sprintf("%s of %s", dir, ns), err)
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			b.sanitize(obj)
+			b.addYAML(path.Join("namespaces", ns, dir, obj.GetName()+".yaml"), obj.Object)
+		}
+	}
+}
+
+// sanitize removes the credentials which the redaction of the YAML can't detect: the data of Secrets, the values of
+// sensitive environment variables and the last applied configurations, which may hold both. Managed fields are removed as noise.
+func (b *supportBundle) sanitize(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	if annotations := obj.GetAnnotations(); annotations[lastAppliedConfigAnnotation] != "" {
+		annotations[lastAppliedConfigAnnotation] = b.replacement
+		obj.SetAnnotations(annotations)
+	}
+	if obj.GetKind() == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			data, _, _ := unstructured.NestedMap(obj.Object, field)
+			for k := range data {
+				data[k] = b.replacement
+			}
+			if data != nil {
+				_ = unstructured.SetNestedMap(obj.Object, data, field)
+			}
+		}
+	}
+	specPath, ok := podSpecPaths[obj.GetKind()]
+	if !ok {
+		return
+	}
+	for _, containersField := range []string{"containers", "initContainers"} {
+		fields := append(append([]string{}, specPath...), containersField)
+		containers, _, _ := unstructured.NestedSlice(obj.Object, fields...)
+		for _, c := range containers {
+			container, _ := c.(map[string]interface{})
+			env, _ := container["env"].([]interface{})
+			for _, e := range env {
+				variable, _ := e.(map[string]interface{})
+				name, _ := variable["name"].(string)
+				value, ok := variable["value"].(string)
+				// the redaction of name=value tells whether the variable is sensitive
+				if ok && b.redact(name+"="+value) != name+"="+value {
+					variable["value"] = b.replacement
+				}
+			}
+		}
+		if containers != nil {
+			_ = unstructured.SetNestedSlice(obj.Object, containers, fields...)
+		}
+	}
+}
+
+func (b *supportBundle) collectLogs(ctx context.Context, kubeClient kubernetes.Interface, ns string) {
+	pods, err := kubeClient.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: b.opts.LabelSelector})
+	if err != nil {
+		b.fail("pods of "+ns, err)
+		return
+	}
+	for _, pod := range pods.Items {
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, c := range containers {
+			tailLines := b.opts.TailLines
+			logs, err := kubeClient.CoreV1().Pods(ns).GetLogs(pod.Name, &corev1.PodLogOptions{Container: c.Name, TailLines: &tailLines}).DoRaw(ctx)
+			if err != nil {
+				b.fail(fmt.Sprintf("logs of %s/%s/%s", ns, pod.Name, c.Name), err)
+				continue
+			}
+			b.add(path.Join("namespaces", ns, "logs", pod.Name, c.Name+".log"), []byte(b.redact(string(logs))))
+		}
+	}
+}
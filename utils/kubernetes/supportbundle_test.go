@@ -0,0 +1,107 @@
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func readTestBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[h.Name] = string(b)
+	}
+}
+
+func TestCollectSupportBundle(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "meshsync-0", Namespace: "meshery"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "meshsync"}}},
+		},
+		&corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "meshsync.1", Namespace: "meshery"}, Reason: "BackOff", Message: "Back-off restarting failed container"},
+	)
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":        "meshery",
+			"namespace":   "meshery",
+			"annotations": map[string]interface{}{lastAppliedConfigAnnotation: `{"env": "PROVIDER_TOKEN=abc"}`},
+		},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "meshery", "env": []interface{}{
+				map[string]interface{}{"name": "PROVIDER_TOKEN", "value": "abc"},
+				map[string]interface{}{"name": "PORT", "value": "9081"},
+			}}},
+		}}},
+	}}
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "registry", "namespace": "meshery"},
+		"data":       map[string]interface{}{".dockerconfigjson": "eyJhdXRocyI6e319"},
+	}}
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{secrets: "SecretList", deployments: "DeploymentList"}, deployment, secret)
+
+	var buf bytes.Buffer
+	err := collectSupportBundle(context.Background(), kubeClient, dynamicClient, &buf, SupportBundleOptions{
+		Resources: []schema.GroupVersionResource{deployments, secrets},
+		Files:     map[string][]byte{"meshery.yaml": []byte("provider: Meshery\npassword: hunter2\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := readTestBundle(t, buf.Bytes())
+
+	for _, name := range []string{"cluster/version.yaml", "cluster/nodes.yaml", "namespaces/meshery/events.yaml", "namespaces/meshery/logs/meshsync-0/meshsync.log"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s missing from the bundle, got %v", name, files)
+		}
+	}
+	if !strings.Contains(files["namespaces/meshery/events.yaml"], "BackOff") {
+		t.Errorf("events = %s", files["namespaces/meshery/events.yaml"])
+	}
+	d := files["namespaces/meshery/deployments.apps/meshery.yaml"]
+	if strings.Contains(d, "abc") || !strings.Contains(d, "9081") {
+		t.Errorf("deployment not sanitized:\n%s", d)
+	}
+	if s := files["namespaces/meshery/secrets/registry.yaml"]; strings.Contains(s, "eyJhdXRocyI6e319") || !strings.Contains(s, "[REDACTED]") {
+		t.Errorf("secret not sanitized:\n%s", s)
+	}
+	if f := files["files/meshery.yaml"]; strings.Contains(f, "hunter2") || !strings.Contains(f, "provider: Meshery") {
+		t.Errorf("file not redacted:\n%s", f)
+	}
+}
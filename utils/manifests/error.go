@@ -11,6 +11,7 @@ const (
 	ErrAbsentFilterCode          = "meshkit-11238"
 	ErrCreatingDirectoryCode     = "meshkit-11239"
 	ErrGetResourceIdentifierCode = "meshkit-11240"
+	ErrFlattenSchemaCode         = "meshkit-11280"
 )
 
 func ErrGetResourceIdentifier(err error) error {
@@ -40,3 +41,7 @@ func ErrAbsentFilter(err error) error {
 func ErrCreatingDirectory(err error) error {
 	return errors.New(ErrCreatingDirectoryCode, errors.Alert, []string{"could not create directory"}, []string{err.Error()}, []string{"proper file permissions were not set"}, []string{"check the appropriate file permissions"})
 }
+
+func ErrFlattenSchema(err error) error {
+	return errors.New(ErrFlattenSchemaCode, errors.Alert, []string{"Error flattening the schema"}, []string{err.Error()}, []string{"The schema references a definition which does not exist", "The schema is not a valid JSON schema"}, []string{"Make sure the definitions referenced by the schema are provided"})
+}
@@ -41,6 +41,33 @@ func GetCrdsFromHelm(url string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return splitCrds(manifest)
+}
+
+// GetCrdsFromHelmWithDependencies returns the CRDs of the chart and of its dependencies, see k8s.GetCRDsFromHelmWithDependencies
+func GetCrdsFromHelmWithDependencies(url string, opts k8s.HelmDependencyOptions) ([]string, error) {
+	manifest, err := k8s.GetCRDsFromHelmWithDependencies(url, opts)
+	if err != nil {
+		return nil, err
+	}
+	return splitCrds(manifest)
+}
+
+// GetCrdsAndResourcesFromHelmWithDependencies returns the CRDs like GetCrdsFromHelmWithDependencies,
+// along with the other objects rendered from the chart with its default values, as a multi document YAML.
+func GetCrdsAndResourcesFromHelmWithDependencies(url string, opts k8s.HelmDependencyOptions) ([]string, string, error) {
+	manifests, err := k8s.GetManifestsFromHelmWithDependencies(url, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	crds, err := splitCrds(manifests.CRDs)
+	if err != nil {
+		return nil, "", err
+	}
+	return crds, manifests.Resources, nil
+}
+
+func splitCrds(manifest string) ([]string, error) {
 	dec := yaml.NewDecoder(strings.NewReader(manifest))
 	var mans []string
 	for {
@@ -0,0 +1,309 @@
+package manifests
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxRefDepth is the number of nested references resolved by FlattenSchema,
+// unless FlattenOptions.MaxRefDepth is set
+const DefaultMaxRefDepth = 10
+
+// FlattenOptions configure FlattenSchema
+type FlattenOptions struct {
+	// Definitions resolve the references which don't point inside the schema,
+	// keyed by the last segment of the reference, eg. "io.k8s.api.core.v1.PodSpec".
+	Definitions map[string]interface{}
+	// MaxRefDepth caps the nesting of resolved references, 0 means DefaultMaxRefDepth.
+	MaxRefDepth int
+	// Warn, if set, is called with the errors of the references which can't be resolved, which are left in place
+	// instead of failing the flattening.
+	Warn func(error)
+}
+
+// valueKeys hold values as opposed to schemas, they are left as is
+var valueKeys = map[string]bool{
+	"default":  true,
+	"enum":     true,
+	"example":  true,
+	"examples": true,
+	"const":    true,
+}
+
+// schemaMapKeys hold schemas keyed by name, the names are not keywords
+var schemaMapKeys = map[string]bool{
+	"properties":        true,
+	"patternProperties": true,
+}
+
+// definitionKeys hold the definitions referenced in the schema, removed once resolved
+var definitionKeys = []string{"definitions", "$defs"}
+
+// FlattenSchema returns a self contained copy of the JSON schema:
+//   - $ref are replaced by the definitions referenced, local JSON pointers or opts.Definitions
+//   - allOf are merged into the schema holding them
+//   - x-kubernetes extensions are translated to JSON schema, x-kubernetes-preserve-unknown-fields is kept
+//
+// Self referential definitions, and references nested deeper than the max depth, are replaced by
+// a free form field, like the fields preserving unknown fields.
+func FlattenSchema(schema map[string]interface{}, opts FlattenOptions) (map[string]interface{}, error) {
+	if opts.MaxRefDepth <= 0 {
+		opts.MaxRefDepth = DefaultMaxRefDepth
+	}
+	f := &schemaFlattener{root: schema, opts: opts}
+	flattened, err := f.flatten(schema)
+	if err != nil {
+		return nil, ErrFlattenSchema(err)
+	}
+	out, _ := flattened.(map[string]interface{})
+	for _, key := range definitionKeys {
+		delete(out, key)
+	}
+	return out, nil
+}
+
+type schemaFlattener struct {
+	root  map[string]interface{}
+	opts  FlattenOptions
+	stack []string // references being resolved
+}
+
+func (f *schemaFlattener) flatten(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			flattened, err := f.flatten(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, flattened)
+		}
+		return out, nil
+	case map[string]interface{}:
+		return f.flattenSchema(v)
+	default:
+		return v, nil
+	}
+}
+
+func (f *schemaFlattener) flattenSchema(schema map[string]interface{}) (interface{}, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		return f.resolveRef(ref, schema)
+	}
+
+	out := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if valueKeys[key] || isDefinitionKey(key) {
+			out[key] = value
+			continue
+		}
+		if props, ok := value.(map[string]interface{}); ok && schemaMapKeys[key] {
+			flattenedProps := make(map[string]interface{}, len(props))
+			for name, prop := range props {
+				flattened, err := f.flatten(prop)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", key, name, err)
+				}
+				flattenedProps[name] = flattened
+			}
+			out[key] = flattenedProps
+			continue
+		}
+		flattened, err := f.flatten(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		out[key] = flattened
+	}
+
+	if allOf, ok := out["allOf"].([]interface{}); ok {
+		delete(out, "allOf")
+		for _, sub := range allOf {
+			if sub, ok := sub.(map[string]interface{}); ok {
+				mergeSchemas(out, sub)
+			}
+		}
+	}
+	translateKubernetesExtensions(out)
+	return out, nil
+}
+
+// resolveRef returns the flattened definition referenced, the keywords next to $ref,
+// like the description, take precedence over the ones of the definition
+func (f *schemaFlattener) resolveRef(ref string, schema map[string]interface{}) (interface{}, error) {
+	for _, resolving := range f.stack {
+		if resolving == ref {
+			return freeFormField(schema), nil
+		}
+	}
+	if len(f.stack) >= f.opts.MaxRefDepth {
+		return freeFormField(schema), nil
+	}
+
+	def, err := f.lookup(ref)
+	if err != nil {
+		if f.opts.Warn == nil {
+			return nil, err
+		}
+		f.opts.Warn(err)
+		return f.keepRef(ref, schema)
+	}
+	f.stack = append(f.stack, ref)
+	flattened, err := f.flatten(def)
+	f.stack = f.stack[:len(f.stack)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, ok := flattened.(map[string]interface{})
+	if !ok {
+		return flattened, nil
+	}
+	siblings := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if key != "$ref" {
+			siblings[key] = value
+		}
+	}
+	flattenedSiblings, err := f.flattenSchema(siblings)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range flattenedSiblings.(map[string]interface{}) {
+		resolved[key] = value
+	}
+	translateKubernetesExtensions(resolved)
+	return resolved, nil
+}
+
+// keepRef flattens the keywords next to the unresolved reference, which is kept.
+func (f *schemaFlattener) keepRef(ref string, schema map[string]interface{}) (interface{}, error) {
+	siblings := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if key != "$ref" {
+			siblings[key] = value
+		}
+	}
+	flattened, err := f.flattenSchema(siblings)
+	if err != nil {
+		return nil, err
+	}
+	kept := flattened.(map[string]interface{})
+	kept["$ref"] = ref
+	return kept, nil
+}
+
+func (f *schemaFlattener) lookup(ref string) (interface{}, error) {
+	if strings.HasPrefix(ref, "#/") {
+		var current interface{} = f.root
+		for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				current = nil
+				break
+			}
+			current = m[token]
+		}
+		if current != nil {
+			return current, nil
+		}
+	}
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if def, ok := f.opts.Definitions[name]; ok {
+		return def, nil
+	}
+	return nil, fmt.Errorf("the reference %s can't be resolved", ref)
+}
+
+func isDefinitionKey(key string) bool {
+	for _, k := range definitionKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// freeFormField replaces the schemas which can't be expanded, it is rendered like the
+// fields preserving unknown fields
+func freeFormField(schema map[string]interface{}) map[string]interface{} {
+	field := map[string]interface{}{
+		"type":   "string",
+		"format": "textarea",
+	}
+	if description, ok := schema["description"]; ok {
+		field["description"] = description
+	}
+	return field
+}
+
+// mergeSchemas merges src into dst: properties are merged recursively, required fields
+// are joined and the other keywords of dst take precedence
+func mergeSchemas(dst, src map[string]interface{}) {
+	for key, value := range src {
+		switch key {
+		case "properties":
+			srcProps, _ := value.(map[string]interface{})
+			dstProps, ok := dst[key].(map[string]interface{})
+			if !ok {
+				dstProps = make(map[string]interface{}, len(srcProps))
+				dst[key] = dstProps
+			}
+			for name, prop := range srcProps {
+				existing, exists := dstProps[name].(map[string]interface{})
+				propSchema, isSchema := prop.(map[string]interface{})
+				if exists && isSchema {
+					mergeSchemas(existing, propSchema)
+					continue
+				}
+				if _, exists := dstProps[name]; !exists {
+					dstProps[name] = prop
+				}
+			}
+		case "required":
+			required, _ := dst[key].([]interface{})
+			seen := make(map[interface{}]bool, len(required))
+			for _, r := range required {
+				seen[r] = true
+			}
+			srcRequired, _ := value.([]interface{})
+			for _, r := range srcRequired {
+				if !seen[r] {
+					seen[r] = true
+					required = append(required, r)
+				}
+			}
+			dst[key] = required
+		default:
+			if _, exists := dst[key]; !exists {
+				dst[key] = value
+			}
+		}
+	}
+}
+
+// translateKubernetesExtensions replaces the x-kubernetes extensions with their JSON schema
+// equivalent and drops the ones which only matter to the API server
+func translateKubernetesExtensions(schema map[string]interface{}) {
+	if intOrString, _ := schema["x-kubernetes-int-or-string"].(bool); intOrString {
+		delete(schema, "type")
+		if _, ok := schema["anyOf"]; !ok {
+			schema["anyOf"] = []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string"},
+			}
+		}
+	}
+	if embedded, _ := schema["x-kubernetes-embedded-resource"].(bool); embedded {
+		if _, ok := schema["type"]; !ok {
+			schema["type"] = "object"
+		}
+	}
+	for key := range schema {
+		if strings.HasPrefix(key, "x-kubernetes-") && key != "x-kubernetes-preserve-unknown-fields" {
+			delete(schema, key)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package manifests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testSchema = `{
+  "type": "object",
+  "definitions": {
+    "Node": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "children": {"type": "array", "items": {"$ref": "#/definitions/Node"}}
+      }
+    },
+    "Named": {"properties": {"name": {"type": "string"}}, "required": ["name"]}
+  },
+  "properties": {
+    "tree": {"$ref": "#/definitions/Node", "description": "The root of the tree"},
+    "port": {"x-kubernetes-int-or-string": true, "type": "string"},
+    "items": {"type": "array", "x-kubernetes-list-type": "atomic", "items": {"type": "string"}},
+    "template": {"x-kubernetes-embedded-resource": true, "x-kubernetes-preserve-unknown-fields": true},
+    "spec": {
+      "allOf": [
+        {"$ref": "#/definitions/Named"},
+        {"properties": {"replicas": {"type": "integer", "default": 1}}, "required": ["replicas"]}
+      ]
+    },
+    "pod": {"$ref": "#/definitions/io.k8s.api.core.v1.PodSpec"},
+    "default": {"$ref": "#/definitions/Named"}
+  }
+}`
+
+func TestFlattenSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(testSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	flattened, err := FlattenSchema(schema, FlattenOptions{
+		Definitions: map[string]interface{}{
+			"io.k8s.api.core.v1.PodSpec": map[string]interface{}{"type": "object", "properties": map[string]interface{}{"hostname": map[string]interface{}{"type": "string"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(flattened)
+	var got map[string]interface{}
+	_ = json.Unmarshal(data, &got)
+
+	expected := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(`{
+  "type": "object",
+  "properties": {
+    "tree": {
+      "type": "object",
+      "description": "The root of the tree",
+      "properties": {
+        "name": {"type": "string"},
+        "children": {"type": "array", "items": {"type": "string", "format": "textarea"}}
+      }
+    },
+    "port": {"anyOf": [{"type": "integer"}, {"type": "string"}]},
+    "items": {"type": "array", "items": {"type": "string"}},
+    "template": {"type": "object", "x-kubernetes-preserve-unknown-fields": true},
+    "spec": {
+      "properties": {"name": {"type": "string"}, "replicas": {"type": "integer", "default": 1}},
+      "required": ["name", "replicas"]
+    },
+    "pod": {"type": "object", "properties": {"hostname": {"type": "string"}}},
+    "default": {"properties": {"name": {"type": "string"}}, "required": ["name"]}
+  }
+}`), &expected)
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected schema %s", data)
+	}
+
+	schema["properties"].(map[string]interface{})["missing"] = map[string]interface{}{"$ref": "#/definitions/Missing"}
+	if _, err := FlattenSchema(schema, FlattenOptions{}); err == nil {
+		t.Fatal("expected an unresolved reference to fail")
+	}
+	var warnings []error
+	flattened, err = FlattenSchema(schema, FlattenOptions{
+		Definitions: map[string]interface{}{"io.k8s.api.core.v1.PodSpec": map[string]interface{}{"type": "object"}},
+		Warn:        func(err error) { warnings = append(warnings, err) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := flattened["properties"].(map[string]interface{})["missing"].(map[string]interface{})
+	if missing["$ref"] != "#/definitions/Missing" || len(warnings) != 1 {
+		t.Errorf("expected the unresolved reference to be kept and reported, got %v and %v", missing, warnings)
+	}
+}
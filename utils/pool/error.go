@@ -0,0 +1,20 @@
+package pool
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	ErrTasksFailedCode  = "meshkit-11303"
+	ErrTaskPanickedCode = "meshkit-11304"
+)
+
+func ErrTasksFailed(err error, count int) error {
+	return errors.New(ErrTasksFailedCode, errors.Alert, []string{fmt.Sprintf("%d tasks failed", count)}, []string{err.Error()}, []string{"the tasks failed for the reasons listed"}, []string{"Address the errors of the tasks and retry"})
+}
+
+func ErrTaskPanicked(err error) error {
+	return errors.New(ErrTaskPanickedCode, errors.Alert, []string{"a task panicked"}, []string{err.Error()}, []string{"a bug in the task"}, []string{"Report the issue along with the error"})
+}
@@ -0,0 +1,112 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Source returns a channel the items are sent to, closed once all items are sent or the context is done.
+func Source[T any](ctx context.Context, items []T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, item := range items {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- item:
+			}
+		}
+	}()
+	return out
+}
+
+// Stage starts workers goroutines calling fn for the values received from in, fanning them out, and sends the results
+// to the returned channel, fanning them in. The channel is closed once in is closed and its values are processed,
+// or once the context of the pool is done.
+// The errors of fn are recorded by the pool and reported by Wait, the values which failed are dropped.
+// The goroutines of stages don't take workers of the pool, so that stages can't starve each other.
+func Stage[In, Out any](p *Pool, in <-chan In, workers int, fn func(ctx context.Context, value In) (Out, error)) <-chan Out {
+	if workers <= 0 {
+		workers = 1
+	}
+	out := make(chan Out)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			defer wg.Done()
+			for {
+				var value In
+				var ok bool
+				select {
+				case <-p.ctx.Done():
+					return
+				case value, ok = <-in:
+					if !ok {
+						return
+					}
+				}
+				var result Out
+				failed := true
+				p.run(func(ctx context.Context) error {
+					var err error
+					if result, err = fn(ctx, value); err != nil {
+						return err
+					}
+					failed = false
+					return nil
+				})
+				if failed {
+					continue
+				}
+				select {
+				case <-p.ctx.Done():
+					return
+				case out <- result:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Merge fans in the channels: the values received from all of them are sent to the returned channel,
+// closed once all channels are closed or the context is done.
+func Merge[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+	for _, ch := range channels {
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for value := range ch {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- value:
+				}
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Collect returns the values received from the channel until it is closed.
+func Collect[T any](ch <-chan T) []T {
+	var values []T
+	for value := range ch {
+		values = append(values, value)
+	}
+	return values
+}
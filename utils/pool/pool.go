@@ -0,0 +1,162 @@
+// Package pool runs tasks concurrently on a bounded number of goroutines, and connects them into pipelines.
+//
+// A Pool collects the errors of its tasks, so that they are reported together by Wait, and cancels its context
+// on the first error if it fails fast. Panicking tasks are reported as errors instead of crashing the process.
+package pool
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/layer5io/meshkit/utils"
+)
+
+// Options configures a Pool.
+type Options struct {
+	// Workers is the maximum number of tasks run at the same time, the number of CPUs if zero.
+	Workers int
+	// FailFast cancels the context of the pool on the first error, Wait returns that error only.
+	FailFast bool
+}
+
+// Pool runs tasks on a bounded number of goroutines.
+type Pool struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	parent   context.Context
+	failFast bool
+	slots    chan struct{}
+	wg       sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// New returns a pool whose tasks run with a context derived from ctx.
+func New(ctx context.Context, opts Options) *Pool {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	poolCtx, cancel := context.WithCancel(ctx)
+	return &Pool{
+		ctx:      poolCtx,
+		cancel:   cancel,
+		parent:   ctx,
+		failFast: opts.FailFast,
+		slots:    make(chan struct{}, workers),
+	}
+}
+
+// Context returns the context of the tasks, done when the parent context is, or on the first error if the pool fails fast.
+func (p *Pool) Context() context.Context {
+	return p.ctx
+}
+
+// Go runs the task as soon as a worker is free. It blocks until then, or until the context of the pool is done,
+// in which case the task is not run and the error of the context is returned.
+func (p *Pool) Go(task func(ctx context.Context) error) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	case p.slots <- struct{}{}:
+	}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.wg.Done()
+		}()
+		p.run(task)
+	}()
+	return nil
+}
+
+// Wait waits for the tasks to complete and returns their errors combined, nil if none failed.
+// The error of the parent context is included if it is done.
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	errs := append([]error{}, p.errs...)
+	if p.failFast && len(errs) > 0 {
+		return errs[0]
+	}
+	if err := p.parent.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return ErrTasksFailed(utils.CombineErrors(errs, "\n"), len(errs))
+}
+
+// Errors returns the errors of the tasks completed so far.
+func (p *Pool) Errors() []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]error{}, p.errs...)
+}
+
+func (p *Pool) run(task func(ctx context.Context) error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.record(ErrTaskPanicked(fmt.Errorf("%v", r)))
+		}
+	}()
+	if err := task(p.ctx); err != nil {
+		p.record(err)
+	}
+}
+
+func (p *Pool) record(err error) {
+	p.mu.Lock()
+	p.errs = append(p.errs, err)
+	p.mu.Unlock()
+	if p.failFast {
+		p.cancel()
+	}
+}
+
+// ForEach calls fn for the items on a pool configured by opts, and returns the combined errors.
+func ForEach[T any](ctx context.Context, items []T, opts Options, fn func(ctx context.Context, item T) error) error {
+	p := New(ctx, opts)
+	for _, item := range items {
+		item := item
+		if p.Go(func(ctx context.Context) error { return fn(ctx, item) }) != nil {
+			break
+		}
+	}
+	return p.Wait()
+}
+
+// Map calls fn for the items on a pool configured by opts, and returns the results in the order of the items.
+// The results of the items which failed are the zero value.
+func Map[T, R any](ctx context.Context, items []T, opts Options, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	p := New(ctx, opts)
+	for i, item := range items {
+		i, item := i, item
+		err := p.Go(func(ctx context.Context) error {
+			result, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+		if err != nil {
+			break
+		}
+	}
+	return results, p.Wait()
+}
@@ -0,0 +1,98 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestPoolBoundsWorkers(t *testing.T) {
+	var running, peak int32
+	err := ForEach(context.Background(), make([]int, 20), Options{Workers: 3}, func(ctx context.Context, _ int) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak > 3 {
+		t.Errorf("expected at most 3 tasks running at the same time, got %d", peak)
+	}
+}
+
+func TestPoolErrors(t *testing.T) {
+	results, err := Map(context.Background(), []int{1, 2, 3, 4}, Options{Workers: 2}, func(ctx context.Context, i int) (string, error) {
+		switch i {
+		case 2:
+			return "", fmt.Errorf("task %d failed", i)
+		case 4:
+			panic("boom")
+		}
+		return strconv.Itoa(i), nil
+	})
+	if err == nil || errors.GetCode(err) != ErrTasksFailedCode {
+		t.Fatalf("expected the combined errors, got %v", err)
+	}
+	if results[0] != "1" || results[1] != "" || results[2] != "3" {
+		t.Errorf("unexpected results %v", results)
+	}
+
+	p := New(context.Background(), Options{Workers: 1, FailFast: true})
+	_ = p.Go(func(ctx context.Context) error { return fmt.Errorf("first") })
+	<-p.Context().Done()
+	if err := p.Go(func(ctx context.Context) error { return fmt.Errorf("second") }); err == nil {
+		t.Error("tasks should not start once a failing fast pool failed")
+	}
+	if err := p.Wait(); err == nil || err.Error() != "first" {
+		t.Errorf("expected the first error, got %v", err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	p := New(context.Background(), Options{})
+	numbers := Source(p.Context(), []int{1, 2, 3, 4, 5, 6})
+	squares := Stage(p, numbers, 3, func(ctx context.Context, i int) (int, error) {
+		if i == 5 {
+			return 0, fmt.Errorf("skipped %d", i)
+		}
+		return i * i, nil
+	})
+	odd, even := make(chan int), make(chan int)
+	go func() {
+		defer close(odd)
+		defer close(even)
+		for s := range squares {
+			if s%2 == 0 {
+				even <- s
+			} else {
+				odd <- s
+			}
+		}
+	}()
+	sum := 0
+	for s := range Merge(p.Context(), odd, even) {
+		sum += s
+	}
+	if err := p.Wait(); err == nil || err.Error() != "skipped 5" {
+		t.Errorf("expected the error of the stage, got %v", err)
+	}
+	if sum != 1+4+9+16+36 {
+		t.Errorf("unexpected sum %d", sum)
+	}
+	if got := Collect(Source(context.Background(), []string{"a", "b"})); len(got) != 2 {
+		t.Errorf("unexpected values %v", got)
+	}
+}
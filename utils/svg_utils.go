@@ -3,9 +3,12 @@ package utils
 import (
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 const XMLTAG = "<?xml version=\"1.0\" encoding=\"UTF-8\"?><!DOCTYPE svg>"
@@ -104,3 +107,250 @@ func UpdateSVGString(svgStr string, width, height int) (string, error) {
 	}
 	return svg, nil
 }
+
+// unsafeSVGElements are removed by SanitizeSVG along with their content.
+var unsafeSVGElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"audio":         true,
+	"video":         true,
+	"handler":       true,
+	"listener":      true,
+}
+
+var (
+	externalURLPattern = regexp.MustCompile(`(?i)url\(\s*['"]?\s*(?:[a-z][a-z0-9+.-]*:|//)`)
+	// embedded SVGs are not allowed, as they could contain scripts of their own
+	safeDataImagePattern = regexp.MustCompile(`(?i)^data:image/(?:png|jpe?g|gif|webp)[;,]`)
+	svgColorPattern      = regexp.MustCompile(`(?i)((?:^|;)\s*(?:fill|stroke|stop-color)\s*:\s*)([^;]+)`)
+	cssCommentPattern    = regexp.MustCompile(`(?s)/\*.*?(?:\*/|$)`)
+	cssEscapePattern     = regexp.MustCompile(`\\(?:([0-9a-fA-F]{1,6})(?:\r\n|[ \t\r\n\f])?|(\r\n|[\s\S]))`)
+)
+
+// unsafeCSS reports whether the CSS, of a style element or attribute, refers to external resources with url() or @import.
+// The comments are removed and the escapes decoded first, as u/**/rl( and \75rl( would hide url( otherwise.
+func unsafeCSS(css string) bool {
+	css = cssCommentPattern.ReplaceAllString(css, "")
+	css = cssEscapePattern.ReplaceAllStringFunc(css, func(escape string) string {
+		m := cssEscapePattern.FindStringSubmatch(escape)
+		if m[1] == "" {
+			// an escaped newline continues the line
+			if m[2] == "\n" || m[2] == "\r\n" || m[2] == "\r" || m[2] == "\f" {
+				return ""
+			}
+			return m[2]
+		}
+		r, err := strconv.ParseInt(m[1], 16, 32)
+		if err != nil || r == 0 || r > unicode.MaxRune {
+			return string(unicode.ReplacementChar)
+		}
+		return string(rune(r))
+	})
+	return externalURLPattern.MatchString(css) || strings.Contains(strings.ToLower(css), "@import")
+}
+
+// SanitizeSVG removes the scripts, event handlers and external references of the SVG, so that it can be inlined safely.
+// Elements which can embed active content (script, foreignObject, iframe, ...), on* attributes, links to anything but
+// fragments of the document or embedded images, and styles referring to external resources with url() or @import,
+// escaped or not, are removed, as are DOCTYPEs and entity declarations. An error is returned if the document is no SVG.
+func SanitizeSVG(svg string) (string, error) {
+	return transformSVG(svg, nil)
+}
+
+// WhiteSVG returns a white variant of the SVG, e.g. for dark backgrounds. The SVG is sanitized first.
+// Fill, stroke and stop colors are replaced by white, except for none and references to gradients or patterns.
+// The root element is filled white, so that shapes without a fill of their own are white too.
+func WhiteSVG(svg string) (string, error) {
+	root := true
+	return transformSVG(svg, func(se *xml.StartElement) {
+		hasFill := false
+		for i, a := range se.Attr {
+			switch strings.ToLower(a.Name.Local) {
+			case "fill", "stroke", "stop-color", "color":
+				if a.Name.Local == "fill" {
+					hasFill = true
+				}
+				se.Attr[i].Value = whiteColor(a.Value)
+			case "style":
+				se.Attr[i].Value = svgColorPattern.ReplaceAllStringFunc(a.Value, func(decl string) string {
+					m := svgColorPattern.FindStringSubmatch(decl)
+					return m[1] + whiteColor(m[2])
+				})
+			}
+		}
+		if root && !hasFill {
+			se.Attr = append(se.Attr, xml.Attr{Name: xml.Name{Local: "fill"}, Value: "#FFFFFF"})
+		}
+		root = false
+	})
+}
+
+func whiteColor(value string) string {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if v == "none" || v == "transparent" || strings.HasPrefix(v, "url(") {
+		return value
+	}
+	return "#FFFFFF"
+}
+
+// InitialLetterSVG returns a circular icon showing the first letter or digit of the name, in white on the color.
+// It is meant as a fallback for integrations without an icon of their own.
+func InitialLetterSVG(name, color string) string {
+	letter := "?"
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letter = strings.ToUpper(string(r))
+			break
+		}
+	}
+	if color == "" {
+		color = "#00B39F"
+	}
+	var escapedLetter, escapedColor bytes.Buffer
+	_ = xml.EscapeText(&escapedLetter, []byte(letter))
+	_ = xml.EscapeText(&escapedColor, []byte(color))
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="64" height="64" viewBox="0 0 64 64">`+
+		`<circle cx="32" cy="32" r="32" fill="%s"/>`+
+		`<text x="32" y="32" dy=".35em" text-anchor="middle" font-family="Arial, Helvetica, sans-serif" font-size="32" font-weight="bold" fill="#FFFFFF">%s</text>`+
+		`</svg>`, escapedColor.String(), escapedLetter.String())
+}
+
+// transformSVG sanitizes the SVG, calling transform, if not nil, for each element kept.
+// The raw tokens are used so that namespace prefixes are written back as they are.
+func transformSVG(svg string, transform func(se *xml.StartElement)) (string, error) {
+	d := xml.NewDecoder(strings.NewReader(svg))
+	var b bytes.Buffer
+	// names of the open elements, as RawToken doesn't check that the elements are closed
+	var open []xml.Name
+	// depth of the unsafe element being skipped, 0 if none
+	skipDepth := 0
+	hasRoot := false
+	// the text of a style element is checked as a whole, CDATA sections could split url( otherwise
+	inStyle := false
+	var style []byte
+	flushStyle := func() {
+		if inStyle && !unsafeCSS(string(style)) {
+			_ = xml.EscapeText(&b, style)
+		}
+		inStyle, style = false, nil
+	}
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch tok := t.(type) {
+		case xml.StartElement:
+			flushStyle()
+			open = append(open, tok.Name)
+			if len(open) == 1 {
+				if hasRoot {
+					return "", fmt.Errorf("more than one root element")
+				}
+				if strings.ToLower(tok.Name.Local) != "svg" {
+					return "", fmt.Errorf("the root element is %s, not svg", tok.Name.Local)
+				}
+				hasRoot = true
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if unsafeSVGElements[strings.ToLower(tok.Name.Local)] || animatesLink(tok) {
+				skipDepth = len(open)
+				continue
+			}
+			inStyle = strings.ToLower(tok.Name.Local) == "style"
+			tok.Attr = safeSVGAttributes(tok.Attr)
+			if transform != nil {
+				transform(&tok)
+			}
+			b.WriteString("<" + rawName(tok.Name))
+			for _, a := range tok.Attr {
+				b.WriteString(" " + rawName(a.Name) + `="`)
+				_ = xml.EscapeText(&b, []byte(a.Value))
+				b.WriteString(`"`)
+			}
+			b.WriteString(">")
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != tok.Name {
+				return "", fmt.Errorf("unexpected end element %s", rawName(tok.Name))
+			}
+			depth := len(open)
+			open = open[:depth-1]
+			if skipDepth > 0 {
+				if depth == skipDepth {
+					skipDepth = 0
+				}
+				continue
+			}
+			flushStyle()
+			b.WriteString("</" + rawName(tok.Name) + ">")
+		case xml.CharData:
+			if skipDepth > 0 || len(open) == 0 {
+				continue
+			}
+			if inStyle {
+				style = append(style, tok...)
+				continue
+			}
+			_ = xml.EscapeText(&b, tok)
+		}
+		// comments, processing instructions and directives (DOCTYPE, ENTITY) are dropped
+	}
+	if !hasRoot {
+		return "", fmt.Errorf("no svg element found")
+	}
+	if len(open) > 0 {
+		return "", fmt.Errorf("element %s is not closed", rawName(open[len(open)-1]))
+	}
+	return b.String(), nil
+}
+
+func safeSVGAttributes(attrs []xml.Attr) []xml.Attr {
+	safe := attrs[:0]
+	for _, a := range attrs {
+		name := strings.ToLower(a.Name.Local)
+		value := strings.TrimSpace(a.Value)
+		switch {
+		case strings.HasPrefix(name, "on"):
+			continue
+		case name == "href" || name == "src":
+			if !strings.HasPrefix(value, "#") && !safeDataImagePattern.MatchString(value) {
+				continue
+			}
+		case unsafeCSS(value):
+			continue
+		}
+		safe = append(safe, a)
+	}
+	return safe
+}
+
+// animatesLink reports whether the element is an animation of a link, which could change it to a script.
+func animatesLink(se xml.StartElement) bool {
+	switch strings.ToLower(se.Name.Local) {
+	case "animate", "set", "animatemotion", "animatetransform":
+	default:
+		return false
+	}
+	for _, a := range se.Attr {
+		if strings.ToLower(a.Name.Local) == "attributename" {
+			target := strings.ToLower(strings.TrimSpace(a.Value))
+			return target == "href" || strings.HasSuffix(target, ":href")
+		}
+	}
+	return false
+}
+
+func rawName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
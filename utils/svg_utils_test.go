@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeSVG(t *testing.T) {
+	svg := `<?xml version="1.0"?>
+<!DOCTYPE svg [<!ENTITY xxe SYSTEM "file:///etc/passwd">]>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10" onload="alert(1)">
+  <script>alert(2)</script>
+  <style>@import url(https://evil.example/x.css);</style>
+  <defs><linearGradient id="g"><stop offset="0" stop-color="#f00"/></linearGradient></defs>
+  <foreignObject><div>html</div></foreignObject>
+  <a xlink:href="javascript:alert(3)"><rect width="5" height="5" fill="url(#g)" onclick="alert(4)"/></a>
+  <use href="#g"/>
+  <image href="https://evil.example/track.png"/>
+  <image href="data:image/svg+xml;base64,PHN2Zz48L3N2Zz4="/>
+  <image href="data:image/png;base64,iVBORw0KGgo="/>
+  <rect style="fill: red; background: url('https://evil.example/bg.png')"/>
+  <set attributeName="href" to="javascript:alert(5)"/>
+  <!-- a comment -->
+  <circle r="2" fill="blue"/>
+</svg>`
+	got, err := SanitizeSVG(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unsafe := range []string{"script", "alert", "onload", "onclick", "evil.example", "foreignObject", "ENTITY", "@import", "svg+xml", "comment", "<set"} {
+		if strings.Contains(got, unsafe) {
+			t.Errorf("expected %q to be removed:\n%s", unsafe, got)
+		}
+	}
+	for _, safe := range []string{`fill="url(#g)"`, `<use href="#g">`, `data:image/png;base64`, `xmlns:xlink=`, `<circle r="2" fill="blue">`, `stop-color="#f00"`} {
+		if !strings.Contains(got, safe) {
+			t.Errorf("expected %q to be kept:\n%s", safe, got)
+		}
+	}
+
+	// the CSS is unescaped and its comments removed before it is checked
+	for _, bypass := range []string{
+		`<style>rect { background: \75rl(https://evil.example/bg.png) }</style>`,
+		`<style>@\69mport "https://evil.example/x.css";</style>`,
+		`<style>rect { background: u/**/rl(https://evil.example/bg.png) }</style>`,
+		`<style>rect { background: u<![CDATA[rl(https://evil.example/bg.png)]]> }</style>`,
+		`<rect style="background: \000075rl('https://evil.example/bg.png')"/>`,
+	} {
+		got, err := SanitizeSVG(`<svg xmlns="http://www.w3.org/2000/svg">` + bypass + `</svg>`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(got, "evil.example") {
+			t.Errorf("expected the external reference of %q to be removed:\n%s", bypass, got)
+		}
+	}
+	if got, _ := SanitizeSVG(`<svg><style>rect { fill: url(#g) }</style></svg>`); !strings.Contains(got, "url(#g)") {
+		t.Errorf("expected the local reference to be kept:\n%s", got)
+	}
+
+	for _, invalid := range []string{"", "<html><body/></html>", "<svg><path></svg>", "not an svg"} {
+		if _, err := SanitizeSVG(invalid); err == nil {
+			t.Errorf("expected an error sanitizing %q", invalid)
+		}
+	}
+}
+
+func TestWhiteSVG(t *testing.T) {
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"><path fill="#326CE5" stroke="none"/><path style="fill:#fff;stroke: rgb(1,2,3)"/><rect fill="url(#g)"/><path/></svg>`
+	got, err := WhiteSVG(svg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<svg xmlns="http://www.w3.org/2000/svg" fill="#FFFFFF">`, `<path fill="#FFFFFF" stroke="none">`, `style="fill:#FFFFFF;stroke: #FFFFFF"`, `fill="url(#g)"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "326CE5") {
+		t.Errorf("expected the colors to be replaced:\n%s", got)
+	}
+}
+
+func TestInitialLetterSVG(t *testing.T) {
+	got := InitialLetterSVG("  cert-manager", "")
+	if !strings.Contains(got, ">C</text>") || !strings.Contains(got, `fill="#00B39F"`) {
+		t.Errorf("unexpected icon %s", got)
+	}
+	if _, err := SanitizeSVG(got); err != nil {
+		t.Errorf("expected a valid SVG: %v", err)
+	}
+	got = InitialLetterSVG("", `"><script>`)
+	if !strings.Contains(got, ">?</text>") || strings.Contains(got, "<script>") {
+		t.Errorf("unexpected icon %s", got)
+	}
+}
@@ -95,8 +95,13 @@ func Filepath() string {
 }
 
 func DownloadFile(filepath string, url string) error {
+	return DownloadFileWithClient(http.DefaultClient, filepath, url)
+}
+
+// DownloadFileWithClient is DownloadFile, the file being downloaded with the client, e.g. one fetching from mirrors.
+func DownloadFileWithClient(client *http.Client, filepath string, url string) error {
 	// Get the data
-	resp, err := http.Get(url)
+	resp, err := client.Get(url)
 	if err != nil {
 		return err
 	}
@@ -164,7 +169,12 @@ func ReadFileSource(uri string) (string, error) {
 // and returns the content of the file if the location is valid and
 // no error occurs
 func ReadRemoteFile(url string) (string, error) {
-	response, err := http.Get(url)
+	return ReadRemoteFileWithClient(http.DefaultClient, url)
+}
+
+// ReadRemoteFileWithClient is ReadRemoteFile, the file being read with the client, e.g. one fetching from mirrors.
+func ReadRemoteFileWithClient(client *http.Client, url string) (string, error) {
+	response, err := client.Get(url)
 	if err != nil {
 		return " ", err
 	}
@@ -203,8 +213,13 @@ func ReadLocalFile(location string) (string, error) {
 
 // Gets the latest stable release tags from github for a given org name and repo name(in that org) in sorted order
 func GetLatestReleaseTagsSorted(org string, repo string) ([]string, error) {
+	return GetLatestReleaseTagsSortedWithClient(http.DefaultClient, org, repo)
+}
+
+// GetLatestReleaseTagsSortedWithClient is GetLatestReleaseTagsSorted, the releases being fetched with the client.
+func GetLatestReleaseTagsSortedWithClient(client *http.Client, org string, repo string) ([]string, error) {
 	var url string = "https://github.com/" + org + "/" + repo + "/releases"
-	resp, err := http.Get(url)
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, ErrGettingLatestReleaseTag(err)
 	}
@@ -1,9 +1,16 @@
 package walker
 
 import (
+	"context"
+	"errors"
 	"io"
+	"io/fs"
 	"os"
+	pathpkg "path"
 	"path/filepath"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils/pool"
 )
 
 func WalkLocalDirectory(path string) ([]*File, error) {
@@ -41,3 +48,246 @@ func WalkLocalDirectory(path string) ([]*File, error) {
 	return files, nil
 
 }
+
+// SymlinkPolicy defines how the Local walker treats symbolic links
+type SymlinkPolicy int
+
+const (
+	// SkipSymlinks ignores the symbolic links, the default
+	SkipSymlinks SymlinkPolicy = iota
+	// FollowSymlinksWithinRoot follows the symbolic links resolving inside the root directory
+	FollowSymlinksWithinRoot
+	// FollowSymlinks follows all the symbolic links
+	FollowSymlinks
+)
+
+// Local represents the walker of local directories
+type Local struct {
+	root               string
+	include            []string
+	exclude            []string
+	maxFileSizeInBytes int64 // 0 means no limit
+	symlinks           SymlinkPolicy
+	workers            int
+	fileInterceptor    FileInterceptor
+	dirInterceptor     DirInterceptor
+}
+
+// NewLocal returns a pointer to an instance of Local
+func NewLocal() *Local {
+	return &Local{
+		root:    ".",
+		workers: 1,
+	}
+}
+
+// Root sets the directory to walk and returns a pointer
+// to the same Local instance
+func (l *Local) Root(root string) *Local {
+	l.root = root
+	return l
+}
+
+// Include restricts the walk to the files matching one of the glob patterns and
+// returns a pointer to the same Local instance
+//
+// The patterns are matched against the slash separated path relative to the root,
+// "**" matches any number of directories. Patterns without "/" are matched against
+// the file name, eg. "*.go".
+func (l *Local) Include(patterns ...string) *Local {
+	l.include = append(l.include, patterns...)
+	return l
+}
+
+// Exclude skips the files and directories matching one of the glob patterns and
+// returns a pointer to the same Local instance
+//
+// The patterns are matched like the Include ones, exclusion takes precedence.
+func (l *Local) Exclude(patterns ...string) *Local {
+	l.exclude = append(l.exclude, patterns...)
+	return l
+}
+
+// MaxFileSize skips the files larger than size and returns a pointer
+// to the same Local instance
+func (l *Local) MaxFileSize(size int64) *Local {
+	l.maxFileSizeInBytes = size
+	return l
+}
+
+// Symlinks sets how symbolic links are treated and returns a pointer
+// to the same Local instance
+func (l *Local) Symlinks(policy SymlinkPolicy) *Local {
+	l.symlinks = policy
+	return l
+}
+
+// Workers sets the number of files read and intercepted in parallel and returns
+// a pointer to the same Local instance
+//
+// With more than one worker, the file interceptor must be safe for concurrent use.
+func (l *Local) Workers(workers int) *Local {
+	if workers < 1 {
+		workers = 1
+	}
+	l.workers = workers
+	return l
+}
+
+func (l *Local) RegisterFileInterceptor(i FileInterceptor) *Local {
+	l.fileInterceptor = i
+	return l
+}
+
+func (l *Local) RegisterDirInterceptor(i DirInterceptor) *Local {
+	l.dirInterceptor = i
+	return l
+}
+
+// Walk will initiate traversal process, it stops at the first error
+// returned by an interceptor
+func (l *Local) Walk() error {
+	root, err := filepath.Abs(l.root)
+	if err != nil {
+		return err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return err
+	}
+
+	p := pool.New(context.Background(), pool.Options{Workers: l.workers, FailFast: true})
+	walkErr := l.walkDir(root, root, filepath.Clean(l.root), map[string]bool{root: true}, p)
+	err = p.Wait()
+	if walkErr != nil && walkErr != errWalkStopped {
+		return walkErr
+	}
+	return err
+}
+
+var errWalkStopped = errors.New("walk stopped")
+
+// walkDir walks dir, found at the path displayed, sending the files to read.
+// The paths sent and intercepted are joined to the root, as set.
+// Directories reached through symbolic links are walked separately, visited holds
+// their real paths to walk them once.
+func (l *Local) walkDir(root, dir, display string, visited map[string]bool, p *pool.Pool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		displayPath := filepath.Join(display, strings.TrimPrefix(path, dir))
+		rel, _ := filepath.Rel(filepath.Clean(l.root), displayPath)
+		rel = filepath.ToSlash(rel)
+		if rel != "." && matchAny(l.exclude, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			if l.symlinks == SkipSymlinks {
+				return nil
+			}
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return nil
+			}
+			if l.symlinks == FollowSymlinksWithinRoot && target != root && !strings.HasPrefix(target, root+string(os.PathSeparator)) {
+				return nil
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				// links to an ancestor directory are cycles
+				parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+				if err != nil || visited[target] || parent == target || strings.HasPrefix(parent, target+string(os.PathSeparator)) {
+					return nil
+				}
+				visited[target] = true
+				return l.walkDir(root, target, displayPath, visited, p)
+			}
+			return l.sendFile(rel, displayPath, info, p)
+		}
+
+		if d.IsDir() {
+			if l.dirInterceptor != nil && rel != "." {
+				return l.dirInterceptor(Directory{Name: d.Name(), Path: displayPath})
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return l.sendFile(rel, displayPath, info, p)
+	})
+}
+
+func (l *Local) sendFile(rel, path string, info fs.FileInfo, p *pool.Pool) error {
+	if !info.Mode().IsRegular() || (len(l.include) > 0 && !matchAny(l.include, rel)) {
+		return nil
+	}
+	if l.maxFileSizeInBytes > 0 && info.Size() > l.maxFileSizeInBytes {
+		return nil
+	}
+	if err := p.Go(func(context.Context) error { return l.readFile(path) }); err != nil {
+		return errWalkStopped
+	}
+	return nil
+}
+
+func (l *Local) readFile(path string) error {
+	if l.fileInterceptor == nil {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return l.fileInterceptor(File{
+		Name:    filepath.Base(path),
+		Path:    path,
+		Content: string(content),
+	})
+}
+
+// matchAny reports whether the slash separated path matches one of the glob patterns
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := pathpkg.Match(pattern, pathpkg.Base(path)); ok {
+				return true
+			}
+			continue
+		}
+		if matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := pathpkg.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
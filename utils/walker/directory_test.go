@@ -0,0 +1,78 @@
+package walker
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestLocalWalk(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":             "package main",
+		"main_test.go":        "package main",
+		"pkg/a/a.go":          "package a",
+		"pkg/a/big.go":        "package a // padding to exceed the size limit",
+		"vendor/dep/dep.go":   "package dep",
+		"docs/readme.md":      "docs",
+		"outside/linked.go":   "package linked",
+		"pkg/b/deep/b.go":     "package b",
+		"pkg/b/deep/notes.md": "notes",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(root, "outside"), filepath.Join(root, "pkg", "link")); err != nil {
+		t.Skip("symbolic links are not supported")
+	}
+	// a cycle, which must not be followed forever
+	if err := os.Symlink(filepath.Join(root, "pkg"), filepath.Join(root, "pkg", "b", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	walk := func(l *Local) []string {
+		var mu sync.Mutex
+		var names []string
+		err := l.Root(root).RegisterFileInterceptor(func(f File) error {
+			mu.Lock()
+			defer mu.Unlock()
+			names = append(names, f.Name)
+			return nil
+		}).Walk()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	got := walk(NewLocal().Include("*.go").Exclude("*_test.go", "vendor", "outside").MaxFileSize(20).Workers(4))
+	expected := []string{"a.go", "b.go", "main.go"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+
+	got = walk(NewLocal().Include("pkg/**/*.go").Exclude("outside").Symlinks(FollowSymlinksWithinRoot))
+	expected = []string{"a.go", "b.go", "big.go", "linked.go"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
@@ -13,10 +13,16 @@ import (
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 )
 
 // Git represents the Git Walker
 type Git struct {
+	url                string // If set, it is used as the clone URL instead of baseURL/owner/repo
 	baseURL            string
 	owner              string
 	repo               string
@@ -28,6 +34,11 @@ type Git struct {
 	fileInterceptor    FileInterceptor
 	dirInterceptor     DirInterceptor
 	referenceName      plumbing.ReferenceName
+	tag                string
+	commit             string
+	sparsePaths        []string
+	auth               transport.AuthMethod
+	authErr            error
 }
 
 // NewGit returns a pointer to an instance of Git
@@ -58,7 +69,18 @@ func (g *Git) BaseURL(baseurl string) *Git {
 	return g
 }
 
-// BaseURL sets git repository base URL and returns a pointer
+// URL sets the clone URL of the git repository and returns a pointer
+// to the same Git instance
+//
+// Any URL supported by git can be used, eg. https://gitlab.com/group/project.git,
+// ssh://git@git.example.com/project.git or git@git.example.com:project.git.
+// When set, the base URL, owner and repo are ignored.
+func (g *Git) URL(url string) *Git {
+	g.url = url
+	return g
+}
+
+// MaxFileSize sets the size limit of the files read and returns a pointer
 // to the same Git instance
 func (g *Git) MaxFileSize(size int64) *Git {
 	g.maxFileSizeInBytes = size
@@ -121,6 +143,77 @@ func (g *Git) ReferenceName(refName string) *Git {
 	return g
 }
 
+// Tag pins the walk to the tag and returns a pointer
+// to the same Git instance
+func (g *Git) Tag(tag string) *Git {
+	g.tag = tag
+	return g
+}
+
+// Commit pins the walk to the commit, full or abbreviated hash, and returns
+// a pointer to the same Git instance
+//
+// The commit takes precedence over the tag and reference name. The whole history
+// is fetched, as commits can't be fetched individually.
+func (g *Git) Commit(commit string) *Git {
+	g.commit = commit
+	return g
+}
+
+// SparseCheckout restricts the checkout to the directories and returns a pointer
+// to the same Git instance
+//
+// It makes walking a directory of a large repository, like a monorepo, faster.
+func (g *Git) SparseCheckout(dirs ...string) *Git {
+	for _, dir := range dirs {
+		g.sparsePaths = append(g.sparsePaths, strings.Trim(dir, "/"))
+	}
+	return g
+}
+
+// Auth sets the credentials used to clone private repositories and returns
+// a pointer to the same Git instance
+//
+// For GitHub, the token is a personal access token and the username can be any non empty string.
+func (g *Git) Auth(username, token string) *Git {
+	g.auth = &githttp.BasicAuth{
+		Username: username,
+		Password: token,
+	}
+	return g
+}
+
+// TokenAuth sets the bearer token used to clone private repositories and returns
+// a pointer to the same Git instance
+//
+// For GitLab and most Git servers, use Auth with the token as password instead.
+func (g *Git) TokenAuth(token string) *Git {
+	g.auth = &githttp.TokenAuth{Token: token}
+	return g
+}
+
+// SSHAuth sets the private key, PEM encoded and optionally encrypted with the password,
+// used to clone over SSH and returns a pointer to the same Git instance
+//
+// The host keys are verified against the known_hosts files.
+func (g *Git) SSHAuth(user string, privateKey []byte, password string) *Git {
+	if user == "" {
+		user = gitssh.DefaultUsername
+	}
+	g.auth, g.authErr = gitssh.NewPublicKeys(user, privateKey, password)
+	return g
+}
+
+// SSHAgentAuth uses the keys of the SSH agent to clone over SSH and returns
+// a pointer to the same Git instance
+func (g *Git) SSHAgentAuth(user string) *Git {
+	if user == "" {
+		user = gitssh.DefaultUsername
+	}
+	g.auth, g.authErr = gitssh.NewSSHAgentAuth(user)
+	return g
+}
+
 // Walk will initiate traversal process
 func (g *Git) Walk() error {
 	return clonewalk(g)
@@ -138,31 +231,52 @@ func clonewalk(g *Git) error {
 	if g.maxFileSizeInBytes == 0 {
 		return ErrInvalidSizeFile(errors.New("Max file size passed as 0. Will not read any file"))
 	}
+	if g.authErr != nil {
+		return ErrCloningRepo(g.authErr)
+	}
 
 	path := filepath.Join(os.TempDir(), g.repo, strconv.FormatInt(time.Now().UTC().UnixNano(), 10))
 	defer os.RemoveAll(path)
 	var err error
+	cloneURL := g.url
+	if cloneURL == "" {
+		cloneURL = fmt.Sprintf("%s/%s/%s", g.baseURL, g.owner, g.repo)
+	}
 	cloneOptions := &git.CloneOptions{
-		URL:          fmt.Sprintf("%s/%s/%s", g.baseURL, g.owner, g.repo),
+		URL:          cloneURL,
 		SingleBranch: true,
 		Depth:        1,
+		NoCheckout:   g.commit != "" || len(g.sparsePaths) > 0,
 	}
 
-	if g.referenceName != "" {
+	switch {
+	case g.commit != "":
+		cloneOptions.SingleBranch = false
+		cloneOptions.Depth = 0
+	case g.tag != "":
+		cloneOptions.ReferenceName = plumbing.NewTagReferenceName(g.tag)
+	case g.referenceName != "":
 		cloneOptions.ReferenceName = g.referenceName
 	}
 
+	if g.auth != nil {
+		cloneOptions.Auth = g.auth
+	}
+
 	if g.showLogs {
 		cloneOptions.Progress = os.Stdout
-		_, err = git.PlainClone(path, false, cloneOptions)
-	} else {
-		_, err = git.PlainClone(path, false, cloneOptions)
 	}
-
+	repository, err := git.PlainClone(path, false, cloneOptions)
 	if err != nil {
 		return ErrCloningRepo(err)
 	}
 
+	if cloneOptions.NoCheckout {
+		if err = g.checkout(repository, path); err != nil {
+			return ErrCloningRepo(err)
+		}
+	}
+
 	rootPath := filepath.Join(path, g.root)
 	info, err := os.Stat(rootPath)
 	if err != nil {
@@ -179,6 +293,9 @@ func clonewalk(g *Git) error {
 	// If recurse mode is on, we will walk the tree
 	if g.recurse {
 		err = filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, er error) error {
+			if d.IsDir() && d.Name() == git.GitDirName {
+				return fs.SkipDir
+			}
 			if d.IsDir() && g.dirInterceptor != nil {
 				return g.dirInterceptor(Directory{
 					Name: d.Name(),
@@ -241,6 +358,89 @@ func clonewalk(g *Git) error {
 	return nil
 }
 
+// checkout checks out the pinned commit, or the cloned HEAD, restricted to the sparse checkout directories
+func (g *Git) checkout(repository *git.Repository, path string) error {
+	revision := plumbing.Revision(plumbing.HEAD)
+	if g.commit != "" {
+		revision = plumbing.Revision(g.commit)
+	}
+	hash, err := repository.ResolveRevision(revision)
+	if err != nil {
+		return err
+	}
+	if len(g.sparsePaths) == 0 {
+		worktree, err := repository.Worktree()
+		if err != nil {
+			return err
+		}
+		return worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
+	}
+
+	// The files outside of the sparse checkout directories are never written,
+	// the worktree of go-git doesn't apply them on fresh clones.
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	// The symlinks are created last, so that no file is written through them.
+	var symlinks []*object.File
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !g.inSparsePaths(f.Name) {
+			return nil
+		}
+		if !filepath.IsLocal(filepath.FromSlash(f.Name)) {
+			return fmt.Errorf("the tree entry %s escapes the worktree", f.Name)
+		}
+		if f.Mode == filemode.Symlink {
+			symlinks = append(symlinks, f)
+			return nil
+		}
+		perm := os.FileMode(0644)
+		if f.Mode == filemode.Executable {
+			perm = 0755
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		filePath := filepath.Join(path, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filePath, []byte(content), perm)
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range symlinks {
+		target, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		linkPath := filepath.Join(path, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+			return err
+		}
+		if err := os.Symlink(target, linkPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Git) inSparsePaths(name string) bool {
+	for _, dir := range g.sparsePaths {
+		if dir == "" || name == dir || strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *Git) readFile(f fs.FileInfo, path string) error {
 	if f.Size() > g.maxFileSizeInBytes {
 		return ErrInvalidSizeFile(errors.New("File exceeding size limit"))
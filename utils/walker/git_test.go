@@ -0,0 +1,203 @@
+package walker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string) plumbing.Hash {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := worktree.Commit("update", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func walkedFiles(t *testing.T, g *Git) map[string]string {
+	var mu sync.Mutex
+	files := make(map[string]string)
+	err := g.RegisterFileInterceptor(func(f File) error {
+		mu.Lock()
+		defer mu.Unlock()
+		files[f.Name] = f.Content
+		return nil
+	}).Walk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func keys(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestGitWalkURL(t *testing.T) {
+	if _, err := exec.LookPath("git-upload-pack"); err != nil {
+		t.Skip("git is required to clone local repositories")
+	}
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := commitFiles(t, repo, dir, map[string]string{
+		"charts/a.yaml":  "v1",
+		"docs/readme.md": "docs",
+	})
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, dir, map[string]string{"charts/a.yaml": "v2"})
+
+	files := walkedFiles(t, NewGit().URL(dir).Root("/**"))
+	if files["a.yaml"] != "v2" || files["readme.md"] != "docs" {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	files = walkedFiles(t, NewGit().URL(dir).Commit(first.String()[:10]).SparseCheckout("charts").Root("/**"))
+	if got := keys(files); len(got) != 1 || files["a.yaml"] != "v1" {
+		t.Fatalf("expected only charts/a.yaml at the first commit, got %v", files)
+	}
+
+	files = walkedFiles(t, NewGit().URL(dir).Tag("v1.0.0").Root("charts"))
+	if files["a.yaml"] != "v1" {
+		t.Fatalf("expected the tagged content, got %v", files)
+	}
+}
+
+// storeTree stores the tree of the entries and returns its hash, the values of the entries being either blob contents or nested trees.
+func storeTree(t *testing.T, repo *git.Repository, entries map[string]interface{}, modes map[string]filemode.FileMode) plumbing.Hash {
+	tree := &object.Tree{}
+	for name, value := range entries {
+		entry := object.TreeEntry{Name: name}
+		switch v := value.(type) {
+		case string:
+			obj := repo.Storer.NewEncodedObject()
+			obj.SetType(plumbing.BlobObject)
+			w, err := obj.Writer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write([]byte(v)); err != nil {
+				t.Fatal(err)
+			}
+			w.Close()
+			if entry.Hash, err = repo.Storer.SetEncodedObject(obj); err != nil {
+				t.Fatal(err)
+			}
+			entry.Mode = filemode.Regular
+			if mode, ok := modes[name]; ok {
+				entry.Mode = mode
+			}
+		case map[string]interface{}:
+			entry.Hash = storeTree(t, repo, v, modes)
+			entry.Mode = filemode.Dir
+		}
+		tree.Entries = append(tree.Entries, entry)
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool { return tree.Entries[i].Name < tree.Entries[j].Name })
+	obj := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func commitTree(t *testing.T, repo *git.Repository, tree plumbing.Hash) {
+	commit := &object.Commit{
+		Author:    object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Committer: object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message:   "update",
+		TreeHash:  tree,
+	}
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, hash)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitSparseCheckoutModes(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitTree(t, repo, storeTree(t, repo, map[string]interface{}{
+		"charts": map[string]interface{}{"a.yaml": "v1", "install.sh": "#!/bin/sh", "link.yaml": "a.yaml"},
+	}, map[string]filemode.FileMode{"install.sh": filemode.Executable, "link.yaml": filemode.Symlink}))
+
+	dir := t.TempDir()
+	if err := NewGit().SparseCheckout("charts").checkout(repo, dir); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "charts", "install.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("expected an executable file, got %v, %v", info, err)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "charts", "link.yaml")); err != nil || target != "a.yaml" {
+		t.Errorf("expected a symlink to a.yaml, got %q, %v", target, err)
+	}
+}
+
+func TestGitSparseCheckoutRejectsEscapingEntries(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitTree(t, repo, storeTree(t, repo, map[string]interface{}{
+		"..": map[string]interface{}{"escape.yaml": "pwned"},
+	}, nil))
+
+	dir := filepath.Join(t.TempDir(), "clone")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewGit().SparseCheckout("..").checkout(repo, dir); err == nil {
+		t.Fatal("expected an error checking out an entry outside of the worktree")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "escape.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected no file outside of the worktree, got %v", err)
+	}
+}